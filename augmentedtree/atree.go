@@ -16,7 +16,10 @@ limitations under the License.
 
 package augmentedtree
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 func intervalOverlaps(n *node, low, high int64, interval Interval, maxDimension uint64) bool {
 	if !overlaps(n.high, high, n.low, low) {
@@ -54,28 +57,137 @@ func compare(nodeLow, ivLow int64, nodeID, ivID uint64) int {
 	return intFromBool(ivID > nodeID)
 }
 
+// dimBounds holds an interval's range in one of the dimensions after
+// the first, along with the lowest low and highest high of that
+// dimension across the subtree.
+type dimBounds struct {
+	low, high, min, max int64
+}
+
+// dimsOf returns the bounds of the provided interval in every
+// dimension after the first, or nil for a single dimension tree.
+func dimsOf(interval Interval, maxDimension uint64) []dimBounds {
+	return appendDims(nil, interval, maxDimension)
+}
+
+// appendDims is dimsOf reusing the provided slice's storage.
+func appendDims(dims []dimBounds, interval Interval, maxDimension uint64) []dimBounds {
+	if maxDimension < 2 {
+		return nil
+	}
+
+	dims = dims[:0]
+	for d := uint64(2); d <= maxDimension; d++ {
+		low, high := interval.LowAtDimension(d), interval.HighAtDimension(d)
+		dims = append(dims, dimBounds{low: low, high: high, min: low, max: high})
+	}
+
+	return dims
+}
+
 type node struct {
 	interval            Interval
 	low, high, max, min int64    // max value held by children
 	children            [2]*node // array to hold left/right
 	red                 bool     // indicates if this node is red
 	id                  uint64   // we store the id locally to reduce the number of calls to the method on the interface
+	// dims augments the node for every dimension after the first so
+	// subtrees can be pruned in all dimensions at once, nil when the
+	// tree has a single dimension
+	dims []dimBounds
 }
 
-func (n *node) query(low, high int64, interval Interval, maxDimension uint64, fn func(node *node)) {
-	if n.children[0] != nil && overlaps(n.children[0].max, high, n.children[0].min, low) {
-		n.children[0].query(low, high, interval, maxDimension, fn)
+// overlapsDims returns a bool indicating if this subtree may hold
+// an interval overlapping the provided bounds in every dimension
+// after the first.
+func (n *node) overlapsDims(dims []dimBounds) bool {
+	for i := range dims {
+		if !overlaps(n.dims[i].max, dims[i].high, n.dims[i].min, dims[i].low) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (n *node) query(low, high int64, interval Interval, dims []dimBounds, maxDimension uint64, fn func(node *node)) {
+	if n.children[0] != nil && overlaps(n.children[0].max, high, n.children[0].min, low) &&
+		n.children[0].overlapsDims(dims) {
+		n.children[0].query(low, high, interval, dims, maxDimension, fn)
 	}
 
 	if intervalOverlaps(n, low, high, interval, maxDimension) {
 		fn(n)
 	}
 
-	if n.children[1] != nil && overlaps(n.children[1].max, high, n.children[1].min, low) {
-		n.children[1].query(low, high, interval, maxDimension, fn)
+	if n.children[1] != nil && overlaps(n.children[1].max, high, n.children[1].min, low) &&
+		n.children[1].overlapsDims(dims) {
+		n.children[1].query(low, high, interval, dims, maxDimension, fn)
 	}
 }
 
+// queryPoint calls fn, in order, with every node in this subtree
+// whose interval contains p in the first dimension.  The point is
+// checked directly rather than as the interval [p, p+1), which would
+// overflow at math.MaxInt64.
+func (n *node) queryPoint(p int64, fn func(node *node)) {
+	if n.children[0] != nil && n.children[0].min <= p && n.children[0].max > p {
+		n.children[0].queryPoint(p, fn)
+	}
+
+	if n.low <= p && n.high > p {
+		fn(n)
+	}
+
+	if n.children[1] != nil && n.children[1].min <= p && n.children[1].max > p {
+		n.children[1].queryPoint(p, fn)
+	}
+}
+
+// each performs an in-order traversal of this subtree, calling fn
+// with every interval.  Returns false if fn returned false.
+func (n *node) each(fn func(Interval) bool) bool {
+	if n.children[0] != nil && !n.children[0].each(fn) {
+		return false
+	}
+
+	if !fn(n.interval) {
+		return false
+	}
+
+	if n.children[1] != nil {
+		return n.children[1].each(fn)
+	}
+
+	return true
+}
+
+// nearestBefore returns the interval in this subtree with the greatest
+// high no greater than p if it's at least that of best, otherwise best.
+// Subtrees are visited in reverse order since intervals starting near p
+// are the likeliest to end near it, and those that only start after p
+// or can't beat best are skipped.  Visiting in reverse means an equal
+// high is earlier in tree order and so replaces best.
+func (n *node) nearestBefore(p int64, best *node) *node {
+	if n.min > p || best != nil && n.max < best.high {
+		return best
+	}
+
+	if n.children[1] != nil {
+		best = n.children[1].nearestBefore(p, best)
+	}
+
+	if n.high <= p && (best == nil || n.high >= best.high) {
+		best = n
+	}
+
+	if n.children[0] != nil {
+		best = n.children[0].nearestBefore(p, best)
+	}
+
+	return best
+}
+
 func (n *node) adjustRanges() {
 	for i := 0; i <= 1; i++ {
 		if n.children[i] != nil {
@@ -89,6 +201,19 @@ func (n *node) adjustRanges() {
 func (n *node) adjustRange() {
 	setMin(n)
 	setMax(n)
+	setDims(n)
+}
+
+// fixDims recomputes the bounds of every node on the path from this
+// node to the node with the provided low and id, deepest first.
+func (n *node) fixDims(low int64, id uint64) {
+	if n.id != id {
+		if child := n.children[compare(n.low, low, n.id, id)]; child != nil {
+			child.fixDims(low, id)
+		}
+	}
+
+	setDims(n)
 }
 
 func newDummy() node {
@@ -132,12 +257,14 @@ func (tree *tree) Len() uint64 {
 
 // add will add the provided interval to the tree.
 func (tree *tree) add(iv Interval) {
+	dims := dimsOf(iv, tree.maxDimension)
 	if tree.root == nil {
 		tree.root = newNode(
 			iv, iv.LowAtDimension(1),
 			iv.HighAtDimension(1),
 			1,
 		)
+		tree.root.dims = dims
 		tree.root.red = false
 		tree.number++
 		return
@@ -161,6 +288,7 @@ func (tree *tree) add(iv Interval) {
 	for {
 		if node == nil {
 			node = newNode(iv, ivLow, max, 1)
+			node.dims = dims
 			parent.children[dir] = node
 			tree.number++
 		} else if isRed(node.children[0]) && isRed(node.children[1]) {
@@ -202,6 +330,12 @@ func (tree *tree) add(iv Interval) {
 
 	tree.root = dummy.children[1]
 	tree.root.red = false
+	if dims != nil {
+		// rotations on the way down recompute bounds from children
+		// that don't yet hold the new interval, so the bounds of its
+		// ancestors are fixed once it is in place
+		tree.root.fixDims(ivLow, id)
+	}
 }
 
 // Add will add the provided intervals to this tree.
@@ -280,6 +414,7 @@ func (tree *tree) delete(iv Interval) {
 	if found != nil {
 		tree.number--
 		found.interval, found.max, found.min, found.low, found.high, found.id = node.interval, node.max, node.min, node.low, node.high, node.id
+		found.dims = node.dims
 		parentDir := intFromBool(parent.children[1] == node)
 		childDir := intFromBool(node.children[0] == nil)
 
@@ -313,6 +448,28 @@ func insertInterval(dimension uint64, interval Interval, index, count int64) int
 	return 1
 }
 
+// shiftRange shifts the endpoints of a range past index by count,
+// stopping at index, and returns a bool indicating if either moved.
+func shiftRange(low, high *int64, index, count int64) bool {
+	mod := false
+	if *high > index {
+		*high += count
+		if *high < index {
+			*high = index
+		}
+		mod = true
+	}
+	if *low > index {
+		*low += count
+		if *low < index {
+			*low = index
+		}
+		mod = true
+	}
+
+	return mod
+}
+
 // Insert will shift intervals in the tree based on the specified
 // index and the specified count.  Dimension specifies where to
 // apply the shift.  Returned is a list of intervals impacted and
@@ -330,11 +487,17 @@ func (tree *tree) Insert(dimension uint64,
 
 	modified, deleted := intervalsPool.Get().(Intervals), intervalsPool.Get().(Intervals)
 
-	tree.root.query(math.MinInt64, math.MaxInt64, nil, tree.maxDimension, func(n *node) {
+	tree.root.query(math.MinInt64, math.MaxInt64, nil, nil, tree.maxDimension, func(n *node) {
 		if dimension > 1 {
 			action := insertInterval(dimension, n.interval, index, count)
 			switch action {
 			case 1:
+				// the consumer shifts the interval itself after this,
+				// so the bounds are shifted the same way here and
+				// recomputed once the deleted intervals are gone
+				if i := int(dimension) - 2; i < len(n.dims) {
+					shiftRange(&n.dims[i].low, &n.dims[i].high, index, count)
+				}
 				modified = append(modified, n.interval)
 			case -1:
 				deleted = append(deleted, n.interval)
@@ -351,21 +514,7 @@ func (tree *tree) Insert(dimension uint64,
 			n.min += count
 		}
 
-		mod := false
-		if n.high > index {
-			n.high += count
-			if n.high < index {
-				n.high = index
-			}
-			mod = true
-		}
-		if n.low > index {
-			n.low += count
-			if n.low < index {
-				n.low = index
-			}
-			mod = true
-		}
+		mod := shiftRange(&n.low, &n.high, index, count)
 
 		if n.low >= n.high {
 			deleted = append(deleted, n.interval)
@@ -401,22 +550,259 @@ func (tree *tree) Query(interval Interval) Intervals {
 		Intervals = intervalsPool.Get().(Intervals)
 		ivLow     = interval.LowAtDimension(1)
 		ivHigh    = interval.HighAtDimension(1)
+		dims      = dimsOf(interval, tree.maxDimension)
 	)
 
-	tree.root.query(ivLow, ivHigh, interval, tree.maxDimension, func(node *node) {
+	tree.root.query(ivLow, ivHigh, interval, dims, tree.maxDimension, func(node *node) {
+		Intervals = append(Intervals, node.interval)
+	})
+
+	return Intervals
+}
+
+// QueryBatch finds the intervals that intersect each of the provided
+// intervals and stores them in results, replacing what it held.  The
+// results' storage is reused, so a batch allocates nothing once the
+// results have grown to fit.
+func (tree *tree) QueryBatch(intervals Intervals, results *Results) {
+	results.reset()
+
+	add := func(node *node) {
+		results.add(node.interval, node.id)
+	}
+	for _, interval := range intervals {
+		if tree.root != nil {
+			results.dims = appendDims(results.dims, interval, tree.maxDimension)
+			tree.root.query(interval.LowAtDimension(1), interval.HighAtDimension(1),
+				interval, results.dims, tree.maxDimension, add)
+		}
+		results.ends = append(results.ends, len(results.intervals))
+	}
+}
+
+// Each will call the provided function with every interval in
+// this tree, ordered by low value in the first dimension, until
+// the function returns false.
+func (tree *tree) Each(fn func(Interval) bool) {
+	if tree.root == nil {
+		return
+	}
+
+	tree.root.each(fn)
+}
+
+// QueryPoint will return a list of intervals that contain the
+// provided point in the first dimension.
+func (tree *tree) QueryPoint(p int64) Intervals {
+	if tree.root == nil {
+		return nil
+	}
+
+	Intervals := intervalsPool.Get().(Intervals)
+	tree.root.queryPoint(p, func(node *node) {
 		Intervals = append(Intervals, node.interval)
 	})
 
 	return Intervals
 }
 
+// NearestBefore returns the interval with the greatest high in the
+// first dimension that is no greater than p, or nil if there is none.
+func (tree *tree) NearestBefore(p int64) Interval {
+	if tree.root == nil {
+		return nil
+	}
+
+	best := tree.root.nearestBefore(p, nil)
+	if best == nil {
+		return nil
+	}
+
+	return best.interval
+}
+
+// NearestAfter returns the interval with the least low in the first
+// dimension that is no less than p, or nil if there is none.
+func (tree *tree) NearestAfter(p int64) Interval {
+	var best *node
+	for n := tree.root; n != nil; {
+		if n.low >= p {
+			best = n
+			n = n.children[0]
+		} else {
+			n = n.children[1]
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	return best.interval
+}
+
+// Iter returns an iterator over the intervals in this tree whose low
+// in the first dimension is no less than the provided low.
+func (tree *tree) Iter(low int64) *Iterator {
+	iter := &Iterator{}
+	for n := tree.root; n != nil; {
+		if n.low >= low {
+			iter.stack = append(iter.stack, n)
+			n = n.children[0]
+		} else {
+			n = n.children[1]
+		}
+	}
+
+	return iter
+}
+
+// validate checks the subtree rooted at n, which must not be nil,
+// and returns its black height and the number of intervals it holds.
+// prev is the node preceding this subtree in order, if any.
+func (tree *tree) validate(n *node, prev **node) (int, uint64, error) {
+	if n.interval == nil || n.id != n.interval.ID() {
+		return 0, 0, fmt.Errorf(`Node with id %d does not match its interval.`, n.id)
+	}
+
+	if n.red && (isRed(n.children[0]) || isRed(n.children[1])) {
+		return 0, 0, fmt.Errorf(`Red node with id %d has a red child.`, n.id)
+	}
+
+	heights, number := [2]int{1, 1}, uint64(1)
+	for i, child := range n.children {
+		if child == nil {
+			if i == 0 {
+				if *prev != nil && compare((*prev).low, n.low, (*prev).id, n.id) != 1 {
+					return 0, 0, fmt.Errorf(`Node with id %d is out of order.`, n.id)
+				}
+				*prev = n
+			}
+			continue
+		}
+
+		height, count, err := tree.validate(child, prev)
+		if err != nil {
+			return 0, 0, err
+		}
+		heights[i] = height
+		number += count
+
+		if i == 0 {
+			if compare((*prev).low, n.low, (*prev).id, n.id) != 1 {
+				return 0, 0, fmt.Errorf(`Node with id %d is out of order.`, n.id)
+			}
+			*prev = n
+		}
+	}
+
+	if heights[0] != heights[1] {
+		return 0, 0, fmt.Errorf(`Node with id %d has black heights %d and %d.`,
+			n.id, heights[0], heights[1])
+	}
+
+	min, max := n.low, n.high
+	for _, child := range n.children {
+		if child != nil && child.min < min {
+			min = child.min
+		}
+		if child != nil && child.max > max {
+			max = child.max
+		}
+	}
+	if n.min != min || n.max != max {
+		return 0, 0, fmt.Errorf(`Node with id %d has range %d-%d, expected %d-%d.`,
+			n.id, n.min, n.max, min, max)
+	}
+
+	if err := tree.validateDims(n); err != nil {
+		return 0, 0, err
+	}
+
+	if !n.red {
+		heights[0]++
+	}
+
+	return heights[0], number, nil
+}
+
+// validateDims checks the bounds n keeps for every dimension after
+// the first.
+func (tree *tree) validateDims(n *node) error {
+	if tree.maxDimension < 2 {
+		return nil
+	}
+
+	if uint64(len(n.dims)) != tree.maxDimension-1 {
+		return fmt.Errorf(`Node with id %d has bounds for %d dimensions, expected %d.`,
+			n.id, len(n.dims), tree.maxDimension-1)
+	}
+
+	for i, d := range n.dims {
+		dimension := uint64(i) + 2
+		if d.low != n.interval.LowAtDimension(dimension) ||
+			d.high != n.interval.HighAtDimension(dimension) {
+			return fmt.Errorf(`Node with id %d has a stale range at dimension %d.`,
+				n.id, dimension)
+		}
+
+		min, max := d.low, d.high
+		for _, child := range n.children {
+			if child != nil && child.dims[i].min < min {
+				min = child.dims[i].min
+			}
+			if child != nil && child.dims[i].max > max {
+				max = child.dims[i].max
+			}
+		}
+		if d.min != min || d.max != max {
+			return fmt.Errorf(`Node with id %d has range %d-%d at dimension %d, expected %d-%d.`,
+				n.id, d.min, d.max, dimension, min, max)
+		}
+	}
+
+	return nil
+}
+
+// Validate walks the entire tree and returns an error describing the
+// first violated invariant, such as intervals out of order, a red
+// node with a red child, unequal black heights, a max or min endpoint
+// that doesn't match the subtree below it, or a length that doesn't
+// match the number of intervals.  This is an O(n) operation intended
+// for tests and fuzzing.
+func (tree *tree) Validate() error {
+	if tree.root == nil {
+		if tree.number != 0 {
+			return fmt.Errorf(`Empty tree has length %d.`, tree.number)
+		}
+		return nil
+	}
+
+	if tree.root.red {
+		return fmt.Errorf(`Root is red.`)
+	}
+
+	var prev *node
+	_, number, err := tree.validate(tree.root, &prev)
+	if err != nil {
+		return err
+	}
+
+	if number != tree.number {
+		return fmt.Errorf(`Found %d intervals, expected %d.`, number, tree.number)
+	}
+
+	return nil
+}
+
 func (tree *tree) apply(interval Interval, fn func(*node)) {
 	if tree.root == nil {
 		return
 	}
 
 	low, high := interval.LowAtDimension(1), interval.HighAtDimension(1)
-	tree.root.query(low, high, interval, tree.maxDimension, fn)
+	dims := dimsOf(interval, tree.maxDimension)
+	tree.root.query(low, high, interval, dims, tree.maxDimension, fn)
 }
 
 func isRed(node *node) bool {
@@ -450,6 +836,25 @@ func setMin(parent *node) {
 	}
 }
 
+func setDims(parent *node) {
+	for i := range parent.dims {
+		d := &parent.dims[i]
+		d.min, d.max = d.low, d.high
+		for _, child := range parent.children {
+			if child == nil {
+				continue
+			}
+
+			if child.dims[i].min < d.min {
+				d.min = child.dims[i].min
+			}
+			if child.dims[i].max > d.max {
+				d.max = child.dims[i].max
+			}
+		}
+	}
+}
+
 func rotate(parent *node, dir int) *node {
 	otherDir := takeOpposite(dir)
 
@@ -463,6 +868,9 @@ func rotate(parent *node, dir int) *node {
 	setMax(parent)
 	setMin(child)
 	setMin(parent)
+	// parent is now child's child so must be fixed first
+	setDims(parent)
+	setDims(child)
 
 	return child
 }
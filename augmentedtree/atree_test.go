@@ -17,6 +17,8 @@ limitations under the License.
 package augmentedtree
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -771,3 +773,249 @@ func TestInsertDuplicateIntervalChildren(t *testing.T) {
 	result := tree.Query(constructSingleDimensionInterval(0, 10, 0))
 	assert.Contains(t, result, iv1)
 }
+
+func TestEach(t *testing.T) {
+	tree, ivs := constructSingleDimensionTestTree(100)
+
+	result := make(Intervals, 0, len(ivs))
+	tree.Each(func(iv Interval) bool {
+		result = append(result, iv)
+		return true
+	})
+	assert.Equal(t, ivs, result)
+
+	result = result[:0]
+	tree.Each(func(iv Interval) bool {
+		result = append(result, iv)
+		return len(result) < 3
+	})
+	assert.Equal(t, ivs[:3], result)
+
+	fn := func(Interval) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { tree.Each(fn) }))
+}
+
+func TestEachEmptyTree(t *testing.T) {
+	tree := newTree(1)
+	called := false
+	tree.Each(func(Interval) bool {
+		called = true
+		return true
+	})
+	assert.False(t, called)
+}
+
+func BenchmarkEach(b *testing.B) {
+	tree, _ := constructSingleDimensionTestTree(1000)
+	fn := func(Interval) bool { return true }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Each(fn)
+	}
+}
+
+// constructRandomTestTree returns a tree of intervals with random,
+// often nested, ranges along with the intervals in tree order.
+func constructRandomTestTree(number int) (*tree, Intervals) {
+	r := rand.New(rand.NewSource(0))
+	tree := newTree(1)
+	for i := 0; i < number; i++ {
+		low := r.Int63n(1000)
+		tree.Add(constructSingleDimensionInterval(low, low+1+r.Int63n(100), uint64(i)))
+	}
+
+	ivs := make(Intervals, 0, number)
+	tree.Each(func(iv Interval) bool {
+		ivs = append(ivs, iv)
+		return true
+	})
+	return tree, ivs
+}
+
+func TestQueryPoint(t *testing.T) {
+	tree, ivs := constructRandomTestTree(500)
+
+	for p := int64(-1); p <= 1101; p += 7 {
+		expected := Intervals{}
+		for _, iv := range ivs {
+			if iv.LowAtDimension(1) <= p && iv.HighAtDimension(1) > p {
+				expected = append(expected, iv)
+			}
+		}
+
+		result := tree.QueryPoint(p)
+		assert.Equal(t, expected, append(Intervals{}, result...))
+		result.Dispose()
+	}
+
+	assert.Len(t, tree.QueryPoint(math.MaxInt64), 0)
+	assert.Nil(t, newTree(1).QueryPoint(0))
+}
+
+func TestQueryPointEndpoints(t *testing.T) {
+	tree := newTree(1)
+	iv := constructSingleDimensionInterval(5, 10, 0)
+	tree.Add(iv)
+
+	assert.Equal(t, Intervals{iv}, tree.QueryPoint(5))
+	assert.Equal(t, Intervals{iv}, tree.QueryPoint(9))
+	assert.Len(t, tree.QueryPoint(10), 0)
+	assert.Len(t, tree.QueryPoint(4), 0)
+}
+
+func TestQueryPointLimits(t *testing.T) {
+	tree := newTree(1)
+	top := constructSingleDimensionInterval(math.MaxInt64-1, math.MaxInt64, 0)
+	bottom := constructSingleDimensionInterval(math.MinInt64, math.MinInt64+1, 1)
+	all := constructSingleDimensionInterval(math.MinInt64, math.MaxInt64, 2)
+	tree.Add(top, bottom, all)
+
+	assert.Equal(t, Intervals{all, top}, tree.QueryPoint(math.MaxInt64-1))
+	// intervals exclude their high, so nothing holds the largest point
+	assert.Len(t, tree.QueryPoint(math.MaxInt64), 0)
+	assert.Equal(t, Intervals{bottom, all}, tree.QueryPoint(math.MinInt64))
+	assert.Equal(t, Intervals{all}, tree.QueryPoint(math.MinInt64+1))
+}
+
+func TestNearestBefore(t *testing.T) {
+	tree, ivs := constructRandomTestTree(500)
+
+	for p := int64(-1); p <= 1101; p += 7 {
+		var expected Interval
+		for _, iv := range ivs {
+			high := iv.HighAtDimension(1)
+			if high <= p && (expected == nil || high > expected.HighAtDimension(1)) {
+				expected = iv
+			}
+		}
+
+		assert.Equal(t, expected, tree.NearestBefore(p))
+	}
+
+	assert.Nil(t, newTree(1).NearestBefore(0))
+}
+
+func TestNearestAfter(t *testing.T) {
+	tree, ivs := constructRandomTestTree(500)
+
+	for p := int64(-1); p <= 1101; p += 7 {
+		var expected Interval
+		for _, iv := range ivs {
+			if iv.LowAtDimension(1) >= p {
+				expected = iv
+				break
+			}
+		}
+
+		assert.Equal(t, expected, tree.NearestAfter(p))
+	}
+
+	assert.Nil(t, newTree(1).NearestAfter(0))
+}
+
+func TestNearestTies(t *testing.T) {
+	tree := newTree(1)
+	iv1 := constructSingleDimensionInterval(0, 10, 1)
+	iv2 := constructSingleDimensionInterval(5, 10, 2)
+	iv3 := constructSingleDimensionInterval(20, 30, 3)
+	iv4 := constructSingleDimensionInterval(20, 25, 4)
+	tree.Add(iv4, iv3, iv2, iv1)
+
+	assert.Equal(t, iv1, tree.NearestBefore(10))
+	assert.Equal(t, iv1, tree.NearestBefore(19))
+	assert.Equal(t, iv3, tree.NearestBefore(30))
+	assert.Nil(t, tree.NearestBefore(9))
+
+	assert.Equal(t, iv2, tree.NearestAfter(1))
+	assert.Equal(t, iv3, tree.NearestAfter(6))
+	assert.Nil(t, tree.NearestAfter(21))
+}
+
+func BenchmarkQueryPoint(b *testing.B) {
+	numItems := 1000
+	tree, _ := constructSingleDimensionTestTree(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		result := tree.QueryPoint(int64(i % numItems))
+		result.Dispose()
+	}
+}
+
+func BenchmarkNearestBefore(b *testing.B) {
+	tree, _ := constructRandomTestTree(10000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.NearestBefore(int64(i % 1100))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := newTree(1)
+	assert.Nil(t, tree.Validate())
+
+	tree, ivs := constructRandomTestTree(1000)
+	assert.Nil(t, tree.Validate())
+
+	tree.Delete(ivs[:500]...)
+	assert.Nil(t, tree.Validate())
+
+	tree.Insert(1, 500, 10)
+	assert.Nil(t, tree.Validate())
+	tree.Insert(1, 200, -150)
+	assert.Nil(t, tree.Validate())
+
+	tree.InsertBulk(ivs[:500]...)
+	assert.Nil(t, tree.Validate())
+	tree.Rebalance()
+	assert.Nil(t, tree.Validate())
+
+	multi := newTree(3)
+	mivs := make(Intervals, 0, 500)
+	for i := 0; i < 500; i++ {
+		mivs = append(mivs, randomMultiDimensionInterval(uint64(i), 3))
+	}
+	multi.Add(mivs...)
+	assert.Nil(t, multi.Validate())
+	multi.Delete(mivs[:250]...)
+	assert.Nil(t, multi.Validate())
+}
+
+func TestValidateCorruption(t *testing.T) {
+	tree, _ := constructRandomTestTree(100)
+	if !assert.Nil(t, tree.Validate()) {
+		return
+	}
+
+	tree.number++
+	assert.NotNil(t, tree.Validate())
+	tree.number--
+
+	tree.root.red = true
+	assert.NotNil(t, tree.Validate())
+	tree.root.red = false
+
+	leaf := tree.root
+	for leaf.children[0] != nil {
+		leaf = leaf.children[0]
+	}
+	leaf.max++
+	assert.NotNil(t, tree.Validate())
+	leaf.max--
+
+	tree.root.children[0], tree.root.children[1] = tree.root.children[1], tree.root.children[0]
+	assert.NotNil(t, tree.Validate())
+	tree.root.children[0], tree.root.children[1] = tree.root.children[1], tree.root.children[0]
+
+	child := tree.root.children[0]
+	tree.root.children[0] = nil
+	assert.NotNil(t, tree.Validate())
+	tree.root.children[0] = child
+	assert.Nil(t, tree.Validate())
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package augmentedtree
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// batchNode is a node to be added by InsertBulk along with its sort
+// key, copied so sorting doesn't follow pointers, and the position of
+// its interval in the batch.
+type batchNode struct {
+	low      int64
+	id       uint64
+	position int
+	node     *node
+}
+
+func (bn batchNode) less(other batchNode) bool {
+	if bn.low != other.low {
+		return bn.low < other.low
+	}
+	if bn.id != other.id {
+		return bn.id < other.id
+	}
+	return bn.position < other.position
+}
+
+// nodeLess returns a bool indicating if a comes before b in tree order.
+func nodeLess(a, b *node) bool {
+	if a.low == b.low {
+		return a.id < b.id
+	}
+
+	return a.low < b.low
+}
+
+// appendNodes appends every node of this subtree to the provided list
+// in order.
+func (n *node) appendNodes(nodes []*node) []*node {
+	if n.children[0] != nil {
+		nodes = n.children[0].appendNodes(nodes)
+	}
+
+	nodes = append(nodes, n)
+	if n.children[1] != nil {
+		nodes = n.children[1].appendNodes(nodes)
+	}
+
+	return nodes
+}
+
+// mergeNodes merges two sorted lists of nodes.  A node in added with
+// the same low and id as one before it is dropped, just as Add ignores
+// an interval already in the tree.
+func mergeNodes(existing, added []*node) []*node {
+	merged := make([]*node, 0, len(existing)+len(added))
+	push := func(n *node) {
+		if last := len(merged) - 1; last >= 0 && merged[last].low == n.low && merged[last].id == n.id {
+			return
+		}
+		merged = append(merged, n)
+	}
+
+	i, j := 0, 0
+	for i < len(existing) && j < len(added) {
+		if !nodeLess(added[j], existing[i]) {
+			push(existing[i])
+			i++
+		} else {
+			push(added[j])
+			j++
+		}
+	}
+	for ; i < len(existing); i++ {
+		push(existing[i])
+	}
+	for ; j < len(added); j++ {
+		push(added[j])
+	}
+
+	return merged
+}
+
+// build links the provided nodes, in order, into a subtree of minimal
+// height and returns its root.  Splitting at the middle leaves every
+// level full except the deepest, so coloring only the nodes at redDepth
+// red gives every path the same number of black nodes.
+func build(nodes []*node, depth, redDepth int) *node {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	mid := len(nodes) / 2
+	n := nodes[mid]
+	n.children[0] = build(nodes[:mid], depth+1, redDepth)
+	n.children[1] = build(nodes[mid+1:], depth+1, redDepth)
+	n.red = depth == redDepth
+	n.adjustRange()
+
+	return n
+}
+
+// rebuild replaces the tree with a balanced one built from the provided
+// nodes, which must be in order.
+func (tree *tree) rebuild(nodes []*node) {
+	tree.number = uint64(len(nodes))
+	tree.root = build(nodes, 0, bits.Len(uint(len(nodes)+1))-1)
+	if tree.root != nil {
+		tree.root.red = false
+	}
+}
+
+// InsertBulk will add the provided intervals to this tree by sorting
+// them and building the tree bottom-up, which is much faster than
+// adding a large number of intervals one at a time.  Every node in the
+// tree is relinked, so this costs O(n) even for a small batch.
+func (tree *tree) InsertBulk(intervals ...Interval) {
+	if len(intervals) == 0 {
+		return
+	}
+
+	// nodes are allocated together and sorted along with their
+	// position in the batch so the first of any duplicates is kept
+	slab := make([]node, len(intervals))
+	batch := make([]batchNode, len(intervals))
+	for i, iv := range intervals {
+		n := &slab[i]
+		n.interval, n.id = iv, iv.ID()
+		n.low, n.high = iv.LowAtDimension(1), iv.HighAtDimension(1)
+		n.min, n.max = n.low, n.high
+		n.dims = dimsOf(iv, tree.maxDimension)
+		batch[i] = batchNode{low: n.low, id: n.id, position: i, node: n}
+	}
+
+	sort.Slice(batch, func(i, j int) bool {
+		return batch[i].less(batch[j])
+	})
+
+	added := make([]*node, len(batch))
+	for i := range batch {
+		added[i] = batch[i].node
+	}
+
+	var existing []*node
+	if tree.root != nil {
+		existing = tree.root.appendNodes(make([]*node, 0, tree.number))
+	}
+
+	tree.rebuild(mergeNodes(existing, added))
+}
+
+// Rebalance will rebuild this tree to the minimal height for the
+// number of intervals it holds.
+func (tree *tree) Rebalance() {
+	if tree.root == nil {
+		return
+	}
+
+	tree.rebuild(tree.root.appendNodes(make([]*node, 0, tree.number)))
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package augmentedtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blackHeight returns the number of black nodes on every path from
+// this node to a leaf, failing if paths differ or ordering or the
+// bounds are wrong.
+func blackHeight(tb testing.TB, n *node) int {
+	if n == nil {
+		return 1
+	}
+
+	if isRed(n) && (isRed(n.children[0]) || isRed(n.children[1])) {
+		tb.Errorf(`Node is red and has red children: %+v`, n)
+	}
+
+	min, max := n.low, n.high
+	for _, child := range n.children {
+		if child == nil {
+			continue
+		}
+
+		if child.min < min {
+			min = child.min
+		}
+		if child.max > max {
+			max = child.max
+		}
+	}
+	if min != n.min || max != n.max {
+		tb.Errorf(`Bounds not set correctly: %+v`, n)
+	}
+
+	if n.children[0] != nil && !nodeLess(n.children[0], n) ||
+		n.children[1] != nil && !nodeLess(n, n.children[1]) {
+		tb.Errorf(`Node out of order: %+v`, n)
+	}
+
+	left, right := blackHeight(tb, n.children[0]), blackHeight(tb, n.children[1])
+	if left != right {
+		tb.Errorf(`Black violation: left: %d, right: %d`, left, right)
+	}
+
+	if isRed(n) {
+		return left
+	}
+
+	return left + 1
+}
+
+func randomIntervals(number int) Intervals {
+	r := rand.New(rand.NewSource(0))
+	ivs := make(Intervals, 0, number)
+	for i := 0; i < number; i++ {
+		low := r.Int63n(1000)
+		ivs = append(ivs, constructSingleDimensionInterval(low, low+1+r.Int63n(100), uint64(i)))
+	}
+
+	return ivs
+}
+
+func TestInsertBulk(t *testing.T) {
+	for _, number := range []int{1, 2, 3, 7, 8, 100, 1000} {
+		ivs := randomIntervals(number)
+		bulk, added := newTree(1), newTree(1)
+		bulk.InsertBulk(ivs...)
+		added.Add(ivs...)
+
+		assert.False(t, isRed(bulk.root))
+		blackHeight(t, bulk.root)
+		assert.Equal(t, added.Len(), bulk.Len())
+		assert.Equal(t, added.Iter(0).exhaust(), bulk.Iter(0).exhaust())
+
+		query := constructSingleDimensionInterval(100, 200, 0)
+		assert.Equal(t, added.Query(query), bulk.Query(query))
+	}
+}
+
+func TestInsertBulkNonEmpty(t *testing.T) {
+	ivs := randomIntervals(300)
+	bulk, added := newTree(1), newTree(1)
+	bulk.Add(ivs[:100]...)
+	bulk.InsertBulk(ivs[100:200]...)
+	bulk.InsertBulk(ivs[200:]...)
+	added.Add(ivs...)
+
+	blackHeight(t, bulk.root)
+	assert.Equal(t, uint64(300), bulk.Len())
+	assert.Equal(t, added.Iter(0).exhaust(), bulk.Iter(0).exhaust())
+
+	// the tree can still be added to and deleted from
+	bulk.Delete(ivs[:150]...)
+	bulk.Add(ivs[:10]...)
+	blackHeight(t, bulk.root)
+	assert.Equal(t, uint64(160), bulk.Len())
+}
+
+func TestInsertBulkDuplicates(t *testing.T) {
+	tree := newTree(1)
+	iv1 := constructSingleDimensionInterval(0, 10, 1)
+	iv2 := constructSingleDimensionInterval(0, 5, 1)
+	iv3 := constructSingleDimensionInterval(0, 10, 2)
+	tree.Add(iv1)
+	tree.InsertBulk(iv2, iv3, iv3)
+
+	assert.Equal(t, uint64(2), tree.Len())
+	assert.Equal(t, Intervals{iv1, iv3}, tree.Iter(0).exhaust())
+
+	tree.InsertBulk()
+	assert.Equal(t, uint64(2), tree.Len())
+}
+
+func TestInsertBulkMultiDimensions(t *testing.T) {
+	tree := newTree(2)
+	iv1 := constructMultiDimensionInterval(0, &dimension{low: 0, high: 10}, &dimension{low: 0, high: 10})
+	iv2 := constructMultiDimensionInterval(1, &dimension{low: 5, high: 15}, &dimension{low: 20, high: 30})
+	iv3 := constructMultiDimensionInterval(2, &dimension{low: 8, high: 12}, &dimension{low: 5, high: 25})
+	tree.InsertBulk(iv3, iv1, iv2)
+
+	result := tree.Query(constructMultiDimensionInterval(0, &dimension{low: 9, high: 10}, &dimension{low: 21, high: 22}))
+	assert.Equal(t, Intervals{iv2, iv3}, result)
+	assert.Equal(t, int64(0), tree.root.dims[0].min)
+	assert.Equal(t, int64(30), tree.root.dims[0].max)
+}
+
+func TestRebalance(t *testing.T) {
+	tree := newTree(1)
+	ivs := make(Intervals, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		ivs = append(ivs, constructSingleDimensionInterval(int64(i), int64(i)+10, uint64(i)))
+	}
+	tree.Add(ivs...)
+	tree.Delete(ivs[:900]...)
+
+	tree.Rebalance()
+	blackHeight(t, tree.root)
+	assert.Equal(t, uint64(100), tree.Len())
+	assert.Equal(t, ivs[900:], tree.Iter(0).exhaust())
+	assert.Equal(t, 7, height(tree.root))
+
+	newTree(1).Rebalance()
+}
+
+func height(n *node) int {
+	if n == nil {
+		return 0
+	}
+
+	left, right := height(n.children[0]), height(n.children[1])
+	if left > right {
+		return left + 1
+	}
+	return right + 1
+}
+
+func benchmarkIntervals(number int, sorted bool) Intervals {
+	ivs := make(Intervals, 0, number)
+	for i := 0; i < number; i++ {
+		ivs = append(ivs, constructSingleDimensionInterval(int64(i), int64(i)+10, uint64(i)))
+	}
+
+	if !sorted {
+		r := rand.New(rand.NewSource(0))
+		r.Shuffle(len(ivs), func(i, j int) { ivs[i], ivs[j] = ivs[j], ivs[i] })
+	}
+
+	return ivs
+}
+
+func BenchmarkAddSorted(b *testing.B) {
+	ivs := benchmarkIntervals(100000, true)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		newTree(1).Add(ivs...)
+	}
+}
+
+func BenchmarkAddRandom(b *testing.B) {
+	ivs := benchmarkIntervals(100000, false)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		newTree(1).Add(ivs...)
+	}
+}
+
+func BenchmarkInsertBulkSorted(b *testing.B) {
+	ivs := benchmarkIntervals(100000, true)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		newTree(1).InsertBulk(ivs...)
+	}
+}
+
+func BenchmarkInsertBulkRandom(b *testing.B) {
+	ivs := benchmarkIntervals(100000, false)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		newTree(1).InsertBulk(ivs...)
+	}
+}
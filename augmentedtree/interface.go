@@ -25,9 +25,27 @@ intersections against a point by constructing a range of encompassed
 solely if a single point.
 
 The current tree is a simple top-down red-black binary search tree.
+Nodes are ordered by their first dimension.  With more dimensions,
+every node also keeps the bounds of its subtree in each of the other
+dimensions, so a query prunes subtrees in every dimension at once
+rather than filtering what overlaps in the first.  The bounds are
+kept up to date by every write, so queries never modify the tree.
+
+The tree can also find the intervals containing a point, the nearest
+intervals ending before or starting after a point, and iterate over
+intervals in order of their low value, all in the first dimension.
+
+Large batches of intervals can be added with InsertBulk, which sorts
+them and links them into a balanced tree directly, and many intervals
+can be queried at once with QueryBatch, which stores the results of
+every query in a single reusable Results.
 
 TODO: Add a bottom-up implementation to assist with duplicate
 range handling.
+
+TODO: Add a fractional cascading or layered range tree query path for
+multiple dimensions.  The subtree bounds only prune; each dimension is
+still searched on its own.
 */
 
 package augmentedtree
@@ -57,6 +75,16 @@ type Interval interface {
 type Tree interface {
 	// Add will add the provided intervals to the tree.
 	Add(intervals ...Interval)
+	// InsertBulk will add the provided intervals to the tree by sorting
+	// them and building the tree bottom-up.  This is much faster than
+	// Add for large batches, or for sorted input, but rebuilds the
+	// whole tree so is slower for small batches.
+	InsertBulk(intervals ...Interval)
+	// Rebalance will rebuild the tree to the minimal height for the
+	// number of intervals it holds.  The tree stays balanced as
+	// intervals are added and deleted, so this is only needed to
+	// tighten it, for instance after deleting many intervals.
+	Rebalance()
 	// Len returns the number of intervals in the tree.
 	Len() uint64
 	// Delete will remove the provided intervals from the tree.
@@ -65,6 +93,31 @@ type Tree interface {
 	// interval.  The provided interval's ID method is ignored so the
 	// provided ID is irrelevant.
 	Query(interval Interval) Intervals
+	// QueryBatch queries the tree with each of the provided intervals
+	// and stores what each query found in results, replacing anything
+	// results held.  Reusing results across batches, or taking them
+	// from NewResults, avoids allocating a list per query.
+	QueryBatch(intervals Intervals, results *Results)
+	// Each will call the provided function with every interval in
+	// the tree, ordered by low value in the first dimension, until
+	// false is returned.  No intermediate list is allocated.
+	Each(fn func(Interval) bool)
+	// QueryPoint will return a list of intervals that contain the
+	// provided point in the first dimension, that is every interval
+	// whose low is at most p and whose high is greater than p.
+	QueryPoint(p int64) Intervals
+	// NearestBefore returns the interval with the greatest high in the
+	// first dimension that is no greater than p, or nil if every
+	// interval ends after p.  Ties are broken by tree order.
+	NearestBefore(p int64) Interval
+	// NearestAfter returns the interval with the least low in the
+	// first dimension that is no less than p, or nil if every interval
+	// starts before p.  Ties are broken by tree order.
+	NearestAfter(p int64) Interval
+	// Iter returns an iterator over the intervals in the tree whose low
+	// in the first dimension is no less than the provided low, ordered
+	// by low.  The iterator is invalidated by any change to the tree.
+	Iter(low int64) *Iterator
 	// Insert will shift intervals in the tree based on the specified
 	// index and the specified count.  Dimension specifies where to
 	// apply the shift.  Returned is a list of intervals impacted and
@@ -72,6 +125,12 @@ type Tree interface {
 	// makes the interval size zero or less, ie, min >= max.  These
 	// intervals are automatically removed from the tree.  The tree
 	// does not alter the ranges on the intervals themselves, the consumer
-	// is expected to do that.
+	// is expected to do that, moving each endpoint past index by count
+	// but no lower than index.
 	Insert(dimension uint64, index, count int64) (Intervals, Intervals)
+	// Validate walks the entire tree and returns an error describing
+	// the first structural inconsistency it finds, such as a max
+	// endpoint that doesn't match the subtree below it.  This is an
+	// O(n) operation intended for tests and fuzzing.
+	Validate() error
 }
@@ -0,0 +1,64 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package augmentedtree
+
+// Iterator will iterate over the intervals of a tree ordered by low
+// in the first dimension.
+type Iterator struct {
+	// stack holds the nodes still to be visited whose right subtrees
+	// haven't been started, with the next node on top
+	stack []*node
+	n     *node
+}
+
+// Next will move the iterator to the next interval and return a bool
+// indicating if there is one.
+func (iter *Iterator) Next() bool {
+	if len(iter.stack) == 0 {
+		iter.n = nil
+		return false
+	}
+
+	iter.n = iter.stack[len(iter.stack)-1]
+	iter.stack = iter.stack[:len(iter.stack)-1]
+	for n := iter.n.children[1]; n != nil; n = n.children[0] {
+		iter.stack = append(iter.stack, n)
+	}
+
+	return true
+}
+
+// Value will return the interval at the iterator's current position.
+// If the iterator is exhausted or has never been nexted, this method
+// will return nil.
+func (iter *Iterator) Value() Interval {
+	if iter.n == nil {
+		return nil
+	}
+
+	return iter.n.interval
+}
+
+// exhaust is a helper function that will exhaust this iterator and
+// return a list of intervals.  This is for internal use only.
+func (iter *Iterator) exhaust() Intervals {
+	intervals := make(Intervals, 0, 10)
+	for iter.Next() {
+		intervals = append(intervals, iter.Value())
+	}
+
+	return intervals
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package augmentedtree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIter(t *testing.T) {
+	tree, ivs := constructRandomTestTree(500)
+
+	assert.Equal(t, ivs, tree.Iter(math.MinInt64).exhaust())
+
+	for _, low := range []int64{-1, 0, 1, 250, 999, 1000} {
+		expected := Intervals{}
+		for _, iv := range ivs {
+			if iv.LowAtDimension(1) >= low {
+				expected = append(expected, iv)
+			}
+		}
+
+		assert.Equal(t, expected, tree.Iter(low).exhaust())
+	}
+}
+
+func TestIterValue(t *testing.T) {
+	tree, ivs := constructSingleDimensionTestTree(3)
+
+	iter := tree.Iter(1)
+	assert.Nil(t, iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, ivs[1], iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, ivs[2], iter.Value())
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+	assert.False(t, iter.Next())
+}
+
+func TestIterEmptyTree(t *testing.T) {
+	iter := newTree(1).Iter(math.MinInt64)
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+}
+
+func BenchmarkIter(b *testing.B) {
+	tree, _ := constructSingleDimensionTestTree(1000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for iter := tree.Iter(math.MinInt64); iter.Next(); {
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package augmentedtree
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -488,3 +489,169 @@ func TestInsertNegativeMultipleDimensions(t *testing.T) {
 
 	assert.Equal(t, uint64(2), it.Len())
 }
+
+// checkDims ensures every node's cached bounds match its subtree.
+func checkDims(t *testing.T, n *node, maxDimension uint64) ([]int64, []int64) {
+	mins := make([]int64, maxDimension-1)
+	maxs := make([]int64, maxDimension-1)
+	for i := range mins {
+		d := uint64(i) + 2
+		mins[i], maxs[i] = n.interval.LowAtDimension(d), n.interval.HighAtDimension(d)
+		assert.Equal(t, mins[i], n.dims[i].low)
+		assert.Equal(t, maxs[i], n.dims[i].high)
+	}
+
+	for _, child := range n.children {
+		if child == nil {
+			continue
+		}
+
+		childMins, childMaxs := checkDims(t, child, maxDimension)
+		for i := range mins {
+			if childMins[i] < mins[i] {
+				mins[i] = childMins[i]
+			}
+			if childMaxs[i] > maxs[i] {
+				maxs[i] = childMaxs[i]
+			}
+		}
+	}
+
+	for i := range mins {
+		assert.Equal(t, mins[i], n.dims[i].min)
+		assert.Equal(t, maxs[i], n.dims[i].max)
+	}
+
+	return mins, maxs
+}
+
+func randomMultiDimensionInterval(id uint64, dimensions int) *mockInterval {
+	dims := make([]*dimension, 0, dimensions)
+	for i := 0; i < dimensions; i++ {
+		low := rand.Int63n(1000)
+		dims = append(dims, &dimension{low: low, high: low + 1 + rand.Int63n(50)})
+	}
+
+	return constructMultiDimensionInterval(id, dims...)
+}
+
+func bruteForceQuery(intervals Intervals, query Interval, dimensions uint64) map[uint64]bool {
+	result := map[uint64]bool{}
+	for _, iv := range intervals {
+		matches := true
+		for d := uint64(1); d <= dimensions; d++ {
+			if !iv.OverlapsAtDimension(query, d) {
+				matches = false
+			}
+		}
+		if matches {
+			result[iv.ID()] = true
+		}
+	}
+
+	return result
+}
+
+func TestQueryPrunesAllDimensions(t *testing.T) {
+	for _, dimensions := range []int{2, 3} {
+		it := newTree(uint64(dimensions))
+		intervals := Intervals{}
+		for i := 0; i < 500; i++ {
+			iv := randomMultiDimensionInterval(uint64(i), dimensions)
+			intervals = append(intervals, iv)
+			it.Add(iv)
+		}
+		checkDims(t, it.root, uint64(dimensions))
+
+		// delete some to exercise rebalancing
+		for i := 0; i < 500; i += 3 {
+			it.Delete(intervals[i])
+		}
+		remaining := Intervals{}
+		for i, iv := range intervals {
+			if i%3 != 0 {
+				remaining = append(remaining, iv)
+			}
+		}
+		checkDims(t, it.root, uint64(dimensions))
+		checkRedBlack(t, it.root, 1)
+
+		for q := 0; q < 50; q++ {
+			query := randomMultiDimensionInterval(0, dimensions)
+			expected := bruteForceQuery(remaining, query, uint64(dimensions))
+
+			result := it.Query(query)
+			assert.Len(t, result, len(expected))
+			for _, iv := range result {
+				assert.True(t, expected[iv.ID()])
+			}
+		}
+	}
+}
+
+func TestQueryAfterInsertInLaterDimension(t *testing.T) {
+	it, iv1, iv2, iv3 := constructMultiDimensionQueryTestTree()
+
+	// shift everything in the second dimension up by 100, as a
+	// consumer would after Insert
+	modified, _ := it.Insert(2, 0, 100)
+	for _, iv := range modified {
+		d := iv.(*mockInterval).dimensions[1]
+		d.low += 100
+		d.high += 100
+	}
+	// the bounds were updated by Insert rather than the next query
+	checkDims(t, it.root, 2)
+
+	result := it.Query(
+		constructMultiDimensionInterval(
+			0, &dimension{0, 100}, &dimension{100, 200},
+		),
+	)
+	assert.Equal(t, Intervals{iv2, iv1, iv3}, result)
+	checkDims(t, it.root, 2)
+
+	result = it.Query(
+		constructMultiDimensionInterval(
+			0, &dimension{0, 100}, &dimension{0, 100},
+		),
+	)
+	assert.Len(t, result, 0)
+}
+
+func BenchmarkSelectiveQueryMultiDimensions(b *testing.B) {
+	numItems := int64(10000)
+	it := newTree(2)
+
+	// a grid of cells, so a query spanning every column but a single
+	// row only matches a sliver of the intervals it overlaps in the
+	// first dimension
+	for i := int64(0); i < numItems; i++ {
+		x, y := i%100, i/100
+		it.Add(constructMultiDimensionInterval(
+			uint64(i), &dimension{x, x + 1}, &dimension{y, y + 1},
+		))
+	}
+	query := constructMultiDimensionInterval(
+		0, &dimension{0, 100}, &dimension{50, 51},
+	)
+
+	b.Run(`pruned`, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			result := it.Query(query)
+			result.Dispose()
+		}
+	})
+
+	// without bounds the search only prunes in the first dimension
+	// and filters what it finds there, as it did before
+	b.Run(`baseline`, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			result := intervalsPool.Get().(Intervals)
+			it.root.query(0, 100, query, nil, it.maxDimension, func(n *node) {
+				result = append(result, n.interval)
+			})
+			result.Dispose()
+		}
+	})
+}
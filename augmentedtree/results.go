@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package augmentedtree
+
+import "sync"
+
+var resultsPool = sync.Pool{
+	New: func() interface{} {
+		return &Results{}
+	},
+}
+
+// Results holds the intervals found by each query of a batch passed to
+// QueryBatch.  The intervals of every query are kept in one list so a
+// batch of many queries doesn't allocate a list for each, and the
+// list's storage is kept when the results are reused for another
+// batch.  The zero value is ready to use.
+type Results struct {
+	// Dedupe, if set, keeps an interval found by more than one query
+	// of a batch only in the results of the first.
+	Dedupe bool
+	// intervals holds the results of every query in order and ends
+	// the index in intervals just past the results of each.
+	intervals Intervals
+	ends      []int
+	// seen holds the IDs of the intervals found by the batch so far
+	// when deduplicating.
+	seen map[uint64]struct{}
+	// dims is scratch space for the bounds of the query being run.
+	dims []dimBounds
+}
+
+func (r *Results) add(interval Interval, id uint64) {
+	if r.Dedupe {
+		if _, ok := r.seen[id]; ok {
+			return
+		}
+		if r.seen == nil {
+			r.seen = make(map[uint64]struct{})
+		}
+		r.seen[id] = struct{}{}
+	}
+
+	r.intervals = append(r.intervals, interval)
+}
+
+// reset empties the results while keeping their storage.
+func (r *Results) reset() {
+	clear(r.intervals)
+	r.intervals = r.intervals[:0]
+	r.ends = r.ends[:0]
+	clear(r.seen)
+}
+
+// Len returns the number of queries in the batch.
+func (r *Results) Len() int {
+	return len(r.ends)
+}
+
+// At returns the intervals found by the query at the provided index of
+// the batch.  The list shares storage with the results, so it is only
+// valid until the results are reused.
+func (r *Results) At(i int) Intervals {
+	start := 0
+	if i > 0 {
+		start = r.ends[i-1]
+	}
+
+	return r.intervals[start:r.ends[i]:r.ends[i]]
+}
+
+// Intervals returns the intervals found by every query of the batch in
+// query order.  As with At, the list is only valid until the results
+// are reused.
+func (r *Results) Intervals() Intervals {
+	return r.intervals[:len(r.intervals):len(r.intervals)]
+}
+
+// Dispose empties the results and returns them to the pool used by
+// NewResults.  The results must not be used afterward.
+func (r *Results) Dispose() {
+	r.reset()
+	r.Dedupe = false
+	resultsPool.Put(r)
+}
+
+// NewResults returns empty results, reusing the storage of disposed
+// results where possible.
+func NewResults(dedupe bool) *Results {
+	r := resultsPool.Get().(*Results)
+	r.Dedupe = dedupe
+	return r
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package augmentedtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBatch(t *testing.T) {
+	it := newTree(1)
+	for i := int64(0); i < 100; i++ {
+		it.Add(constructSingleDimensionInterval(i, i+10, uint64(i)))
+	}
+
+	queries := Intervals{
+		constructSingleDimensionInterval(0, 5, 0),
+		constructSingleDimensionInterval(200, 300, 0),
+		constructSingleDimensionInterval(50, 51, 0),
+		constructSingleDimensionInterval(3, 7, 0),
+	}
+
+	results := &Results{}
+	it.QueryBatch(queries, results)
+	assert.Equal(t, len(queries), results.Len())
+	total := 0
+	for i, query := range queries {
+		assert.Equal(t, it.Query(query), results.At(i))
+		total += len(results.At(i))
+	}
+	assert.Len(t, results.Intervals(), total)
+
+	// reusing results replaces what they held
+	it.QueryBatch(queries[1:2], results)
+	assert.Equal(t, 1, results.Len())
+	assert.Len(t, results.At(0), 0)
+	assert.Len(t, results.Intervals(), 0)
+}
+
+func TestQueryBatchDedupe(t *testing.T) {
+	it := newTree(1)
+	for i := int64(0); i < 10; i++ {
+		it.Add(constructSingleDimensionInterval(i*10, i*10+15, uint64(i)))
+	}
+
+	queries := Intervals{
+		constructSingleDimensionInterval(0, 12, 0),
+		constructSingleDimensionInterval(11, 25, 0),
+		constructSingleDimensionInterval(0, 100, 0),
+	}
+
+	results := NewResults(true)
+	defer results.Dispose()
+	it.QueryBatch(queries, results)
+
+	assert.Equal(t, Intervals{it.Query(queries[0])[0], it.Query(queries[0])[1]}, results.At(0))
+	assert.Len(t, results.At(1), 1)
+	assert.Equal(t, uint64(2), results.At(1)[0].ID())
+	assert.Len(t, results.At(2), 7)
+	assert.Len(t, results.Intervals(), 10)
+
+	// a second batch doesn't remember the IDs of the first
+	it.QueryBatch(queries[:1], results)
+	assert.Len(t, results.Intervals(), 2)
+}
+
+func TestQueryBatchMultipleDimensions(t *testing.T) {
+	it, iv1, _, iv3 := constructMultiDimensionQueryTestTree()
+
+	results := &Results{}
+	it.QueryBatch(Intervals{
+		constructMultiDimensionInterval(0, &dimension{low: 6, high: 7}, &dimension{low: 6, high: 7}),
+		constructMultiDimensionInterval(0, &dimension{low: 6, high: 7}, &dimension{low: 0, high: 1}),
+		constructMultiDimensionInterval(0, &dimension{low: 8, high: 9}, &dimension{low: 8, high: 9}),
+	}, results)
+
+	assert.Equal(t, Intervals{iv1}, results.At(0))
+	assert.Len(t, results.At(1), 0)
+	assert.Equal(t, Intervals{iv1, iv3}, results.At(2))
+}
+
+func TestQueryBatchEmptyTree(t *testing.T) {
+	it := newTree(1)
+	results := &Results{}
+	it.QueryBatch(Intervals{constructSingleDimensionInterval(0, 10, 0)}, results)
+
+	assert.Equal(t, 1, results.Len())
+	assert.Len(t, results.At(0), 0)
+}
+
+func TestQueryBatchReusesStorage(t *testing.T) {
+	it := newTree(2)
+	for i := int64(0); i < 100; i++ {
+		it.Add(constructMultiDimensionInterval(uint64(i),
+			&dimension{low: i, high: i + 10}, &dimension{low: i, high: i + 10}))
+	}
+
+	queries := make(Intervals, 0, 100)
+	for i := int64(0); i < 100; i++ {
+		queries = append(queries, constructMultiDimensionInterval(0,
+			&dimension{low: i, high: i + 3}, &dimension{low: i, high: i + 3}))
+	}
+
+	results := &Results{Dedupe: true}
+	it.QueryBatch(queries, results)
+	// only the closure passed to the tree is allocated once the
+	// results have grown
+	allocs := testing.AllocsPerRun(10, func() {
+		it.QueryBatch(queries, results)
+	})
+	assert.True(t, allocs <= 1)
+}
+
+func BenchmarkQueryBatch(b *testing.B) {
+	numItems := int64(1000)
+	it := newTree(1)
+	for i := int64(0); i < numItems; i++ {
+		it.Add(constructSingleDimensionInterval(i, i+10, uint64(i)))
+	}
+
+	queries := make(Intervals, 0, numItems)
+	for i := int64(0); i < numItems; i++ {
+		queries = append(queries, constructSingleDimensionInterval(i, i+1, 0))
+	}
+
+	results := NewResults(false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it.QueryBatch(queries, results)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package benchmarks runs the same ordered map workloads against the
+ordered structures of this library so they can be compared on equal
+terms.  Every structure is loaded with the same keys and then given
+the same sequence of inserts, gets and range scans, drawn from one of
+a few workloads:
+
+	insert-heavy  90% inserts, 10% gets
+	read-heavy    10% inserts, 90% gets
+	scan-heavy    10% inserts, 90% scans of ScanLength keys
+	zipfian       20% inserts, 80% gets of keys with a skewed
+	              popularity, so a few keys receive most operations
+
+Keys are uint64s and each structure is used through a small adapter
+implementing OrderedMap, so the cost of any boxing or key encoding a
+structure needs is part of what is measured, just as it would be for a
+user storing integer keys.  Structures of your own can be compared by
+adding a Structure to the list passed to Run.
+
+The results are best read as relative.  Running
+
+	go test -run TestComparisonTable -v ./benchmarks
+
+prints a table of nanoseconds per operation, and the benchmarks in
+this package run every structure and workload pair under go test
+-bench for use with benchstat.  Every structure must find the same
+keys, so Run doubles as a check that they agree.
+*/
+package benchmarks
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"text/tabwriter"
+	"time"
+)
+
+// OrderedMap is the interface each structure is adapted to for the
+// workloads.
+type OrderedMap interface {
+	// Insert adds the key, doing nothing if it is present.
+	Insert(key uint64)
+	// Get returns a bool indicating if the key is present.
+	Get(key uint64) bool
+	// Scan visits up to n keys equal to or greater than start in
+	// order and returns the number visited.
+	Scan(start uint64, n int) int
+}
+
+// Structure names a structure and constructs an empty OrderedMap
+// backed by it.
+type Structure struct {
+	Name string
+	New  func() OrderedMap
+}
+
+// Workload describes a mix of operations.  Inserts, Gets and Scans
+// are relative frequencies.
+type Workload struct {
+	Name                 string
+	Inserts, Gets, Scans int
+	// Zipf, if greater than 1, draws keys from a zipfian
+	// distribution with this exponent rather than uniformly.
+	Zipf float64
+}
+
+// Workloads are the standard workloads.
+var Workloads = []Workload{
+	{Name: `insert-heavy`, Inserts: 90, Gets: 10},
+	{Name: `read-heavy`, Inserts: 10, Gets: 90},
+	{Name: `scan-heavy`, Inserts: 10, Scans: 90},
+	{Name: `zipfian`, Inserts: 20, Gets: 80, Zipf: 1.1},
+}
+
+// Config sizes a run.
+type Config struct {
+	// Size is the number of keys loaded before a workload starts.
+	// Keys are drawn from twice as many possible keys, so about
+	// half of the gets find their key.
+	Size int
+	// Ops is the number of operations in each workload.
+	Ops int
+	// ScanLength is the number of keys visited by a scan.
+	ScanLength int
+	// Seed seeds the generation of keys and operations.
+	Seed int64
+}
+
+// DefaultConfig is a moderately sized run, taking a second or two per
+// structure.
+var DefaultConfig = Config{
+	Size:       100000,
+	Ops:        200000,
+	ScanLength: 100,
+	Seed:       1,
+}
+
+// Result is the outcome of running a workload against a structure.
+type Result struct {
+	Structure, Workload string
+	Ops                 int
+	Elapsed             time.Duration
+	// Found is the number of keys found by gets and visited by
+	// scans, which is the same for every structure given the same
+	// workload.
+	Found int
+}
+
+// NsPerOp returns the average time taken by an operation.
+func (r Result) NsPerOp() float64 {
+	if r.Ops == 0 {
+		return 0
+	}
+
+	return float64(r.Elapsed.Nanoseconds()) / float64(r.Ops)
+}
+
+type opKind uint8
+
+const (
+	opInsert opKind = iota
+	opGet
+	opScan
+)
+
+type op struct {
+	kind opKind
+	key  uint64
+}
+
+// workload holds the keys to load and the operations to run for a
+// Workload so every structure is given exactly the same ones.
+type workload struct {
+	load       []uint64
+	ops        []op
+	scanLength int
+}
+
+// generate builds the keys and operations of a workload.
+func generate(cfg Config, w Workload) *workload {
+	r := rand.New(rand.NewSource(cfg.Seed))
+	space := uint64(2 * max(cfg.Size, 1))
+
+	load := make([]uint64, cfg.Size)
+	for i := range load {
+		load[i] = uint64(i) * 2
+	}
+	r.Shuffle(len(load), func(i, j int) {
+		load[i], load[j] = load[j], load[i]
+	})
+
+	key := func() uint64 {
+		return uint64(r.Int63n(int64(space)))
+	}
+	if w.Zipf > 1 {
+		zipf := rand.NewZipf(r, w.Zipf, 1, space-1)
+		key = func() uint64 {
+			// scatter the popular ranks through the key space so
+			// they aren't also neighbors
+			return zipf.Uint64() * 0x9e3779b97f4a7c15 % space
+		}
+	}
+
+	total := w.Inserts + w.Gets + w.Scans
+	ops := make([]op, cfg.Ops)
+	for i := range ops {
+		n := r.Intn(total)
+		switch {
+		case n < w.Inserts:
+			ops[i].kind = opInsert
+		case n < w.Inserts+w.Gets:
+			ops[i].kind = opGet
+		default:
+			ops[i].kind = opScan
+		}
+		ops[i].key = key()
+	}
+
+	return &workload{load: load, ops: ops, scanLength: cfg.ScanLength}
+}
+
+// fill loads the workload's keys into the map.
+func (w *workload) fill(m OrderedMap) {
+	for _, key := range w.load {
+		m.Insert(key)
+	}
+}
+
+// apply runs the operation at index i, wrapping around the list of
+// operations, and returns the number of keys it found.
+func (w *workload) apply(m OrderedMap, i int) int {
+	op := w.ops[i%len(w.ops)]
+	switch op.kind {
+	case opInsert:
+		m.Insert(op.key)
+	case opGet:
+		if m.Get(op.key) {
+			return 1
+		}
+	case opScan:
+		return m.Scan(op.key, w.scanLength)
+	}
+
+	return 0
+}
+
+// Run runs every workload against every structure and returns the
+// results grouped by workload.
+func Run(cfg Config, structures []Structure, workloads []Workload) []Result {
+	results := make([]Result, 0, len(structures)*len(workloads))
+	for _, wl := range workloads {
+		w := generate(cfg, wl)
+		for _, s := range structures {
+			m := s.New()
+			w.fill(m)
+			runtime.GC()
+
+			found := 0
+			start := time.Now()
+			for i := range w.ops {
+				found += w.apply(m, i)
+			}
+			results = append(results, Result{
+				Structure: s.Name,
+				Workload:  wl.Name,
+				Ops:       len(w.ops),
+				Elapsed:   time.Since(start),
+				Found:     found,
+			})
+		}
+	}
+
+	return results
+}
+
+// WriteTable writes the results as a table of nanoseconds per
+// operation with a row for each workload and a column for each
+// structure.  The fastest structure for each workload is marked with
+// an asterisk.
+func WriteTable(w io.Writer, results []Result) error {
+	var structures, workloads []string
+	cells := make(map[[2]string]Result, len(results))
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if !seen[`s`+r.Structure] {
+			seen[`s`+r.Structure] = true
+			structures = append(structures, r.Structure)
+		}
+		if !seen[`w`+r.Workload] {
+			seen[`w`+r.Workload] = true
+			workloads = append(workloads, r.Workload)
+		}
+		cells[[2]string{r.Workload, r.Structure}] = r
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(tw, "ns/op\t")
+	for _, s := range structures {
+		fmt.Fprintf(tw, "%s\t", s)
+	}
+	fmt.Fprintln(tw)
+
+	for _, wl := range workloads {
+		best := ``
+		for _, s := range structures {
+			r, ok := cells[[2]string{wl, s}]
+			if ok && (best == `` || r.NsPerOp() < cells[[2]string{wl, best}].NsPerOp()) {
+				best = s
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t", wl)
+		for _, s := range structures {
+			r, ok := cells[[2]string{wl, s}]
+			switch {
+			case !ok:
+				fmt.Fprint(tw, "-\t")
+			case s == best:
+				fmt.Fprintf(tw, "*%.0f\t", r.NsPerOp())
+			default:
+				fmt.Fprintf(tw, "%.0f\t", r.NsPerOp())
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuresAgree(t *testing.T) {
+	cfg := Config{Size: 2000, Ops: 5000, ScanLength: 10, Seed: 2}
+	results := Run(cfg, Structures, Workloads)
+	assert.Len(t, results, len(Structures)*len(Workloads))
+
+	found := make(map[string]int)
+	for _, r := range results {
+		assert.Equal(t, cfg.Ops, r.Ops)
+		if expected, ok := found[r.Workload]; ok {
+			assert.Equal(t, expected, r.Found, `%s on %s`, r.Structure, r.Workload)
+		} else {
+			found[r.Workload] = r.Found
+			assert.True(t, r.Found > 0)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	cfg := Config{Size: 100, Ops: 10000, ScanLength: 10, Seed: 3}
+	w := generate(cfg, Workload{Inserts: 1, Gets: 3})
+	assert.Len(t, w.load, 100)
+
+	counts := make(map[opKind]int)
+	for _, op := range w.ops {
+		counts[op.kind]++
+		assert.True(t, op.key < 200)
+	}
+	assert.Equal(t, 0, counts[opScan])
+	assert.True(t, counts[opInsert] > 2250 && counts[opInsert] < 2750)
+
+	// the same seed gives the same operations
+	assert.Equal(t, w.ops, generate(cfg, Workload{Inserts: 1, Gets: 3}).ops)
+}
+
+func TestWriteTable(t *testing.T) {
+	var sb strings.Builder
+	err := WriteTable(&sb, []Result{
+		{Structure: `a`, Workload: `x`, Ops: 10, Elapsed: 100},
+		{Structure: `b`, Workload: `x`, Ops: 10, Elapsed: 50},
+		{Structure: `a`, Workload: `y`, Ops: 10, Elapsed: 30},
+	})
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if !assert.Len(t, lines, 3) {
+		return
+	}
+	assert.Equal(t, []string{`ns/op`, `a`, `b`}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{`x`, `10`, `*5`}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{`y`, `*3`, `-`}, strings.Fields(lines[2]))
+}
+
+// TestComparisonTable prints a comparison of every structure on every
+// workload when run with -v.
+func TestComparisonTable(t *testing.T) {
+	if testing.Short() {
+		t.Skip(`skipping comparison in short mode`)
+	}
+
+	cfg := DefaultConfig
+	if !testing.Verbose() {
+		// only check that a run completes
+		cfg.Size, cfg.Ops = 1000, 1000
+	}
+
+	var sb strings.Builder
+	assert.Nil(t, WriteTable(&sb, Run(cfg, Structures, Workloads)))
+	t.Logf("\n%s", sb.String())
+}
+
+func BenchmarkWorkloads(b *testing.B) {
+	cfg := DefaultConfig
+	for _, wl := range Workloads {
+		w := generate(cfg, wl)
+		for _, s := range Structures {
+			b.Run(wl.Name+`/`+s.Name, func(b *testing.B) {
+				m := s.New()
+				w.fill(m)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.apply(m, i)
+				}
+			})
+		}
+	}
+}
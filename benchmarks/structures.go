@@ -0,0 +1,239 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmarks
+
+import (
+	"encoding/binary"
+
+	"github.com/Workiva/go-datastructures/btree/plus"
+	"github.com/Workiva/go-datastructures/slice/skip"
+	"github.com/Workiva/go-datastructures/trie/art"
+	"github.com/Workiva/go-datastructures/trie/yfast"
+)
+
+// nodeSize is the node size given to the B+ trees.
+const nodeSize = 64
+
+// Structures are the ordered structures of this library compared by
+// default.
+var Structures = []Structure{
+	{Name: `skip`, New: newSkip},
+	{Name: `skip.Ordered`, New: newOrderedSkip},
+	{Name: `plus.BTree`, New: newPlus},
+	{Name: `plus.BTreeG`, New: newPlusG},
+	{Name: `yfast`, New: newYFast},
+	{Name: `art`, New: newART},
+}
+
+type skipEntry uint64
+
+func (se skipEntry) Compare(other skip.Entry) int {
+	o := other.(skipEntry)
+	switch {
+	case se < o:
+		return -1
+	case se > o:
+		return 1
+	}
+
+	return 0
+}
+
+type skipMap struct {
+	sl *skip.SkipList
+}
+
+func newSkip() OrderedMap {
+	return &skipMap{sl: skip.New(uint64(0))}
+}
+
+func (m *skipMap) Insert(key uint64) {
+	m.sl.Insert(skipEntry(key))
+}
+
+func (m *skipMap) Get(key uint64) bool {
+	return m.sl.Contains(skipEntry(key))
+}
+
+func (m *skipMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.sl.Iter(skipEntry(start)); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
+
+type orderedSkipMap struct {
+	sl *skip.OrderedSkipList[uint64, struct{}]
+}
+
+func newOrderedSkip() OrderedMap {
+	return &orderedSkipMap{sl: skip.NewOrdered[uint64, struct{}]()}
+}
+
+func (m *orderedSkipMap) Insert(key uint64) {
+	m.sl.Insert(key, struct{}{})
+}
+
+func (m *orderedSkipMap) Get(key uint64) bool {
+	_, ok := m.sl.Get(key)
+	return ok
+}
+
+func (m *orderedSkipMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.sl.Iter(start); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
+
+// plusKey follows the ordering plus.BTree searches by, in which a key
+// compares as 1 against greater keys.
+type plusKey uint64
+
+func (pk plusKey) Compare(other plus.Key) int {
+	o := other.(plusKey)
+	switch {
+	case pk < o:
+		return 1
+	case pk > o:
+		return -1
+	}
+
+	return 0
+}
+
+type plusMap struct {
+	tree *plus.BTree
+}
+
+func newPlus() OrderedMap {
+	return &plusMap{tree: plus.New(nodeSize)}
+}
+
+func (m *plusMap) Insert(key uint64) {
+	m.tree.Insert(plusKey(key))
+}
+
+func (m *plusMap) Get(key uint64) bool {
+	return m.tree.Get(plusKey(key))[0] != nil
+}
+
+func (m *plusMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.tree.Iter(plusKey(start)); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
+
+type plusGMap struct {
+	tree *plus.BTreeG[uint64]
+}
+
+func newPlusG() OrderedMap {
+	return &plusGMap{tree: plus.NewG(func(a, b uint64) bool {
+		return a < b
+	}, nodeSize)}
+}
+
+func (m *plusGMap) Insert(key uint64) {
+	m.tree.Insert(key)
+}
+
+func (m *plusGMap) Get(key uint64) bool {
+	_, ok := m.tree.Get(key)
+	return ok
+}
+
+func (m *plusGMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.tree.Iter(start); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
+
+type yfastEntry uint64
+
+func (ye yfastEntry) Key() uint64 {
+	return uint64(ye)
+}
+
+type yfastMap struct {
+	trie *yfast.YFastTrie
+}
+
+func newYFast() OrderedMap {
+	return &yfastMap{trie: yfast.New(uint64(0))}
+}
+
+func (m *yfastMap) Insert(key uint64) {
+	m.trie.Insert(yfastEntry(key))
+}
+
+func (m *yfastMap) Get(key uint64) bool {
+	return m.trie.Get(key) != nil
+}
+
+func (m *yfastMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.trie.Iter(start); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
+
+// artMap stores keys big-endian so that their byte order is their
+// numeric order.
+type artMap struct {
+	tree *art.Tree
+	buf  [8]byte
+}
+
+func newART() OrderedMap {
+	return &artMap{tree: art.New()}
+}
+
+func (m *artMap) key(key uint64) []byte {
+	binary.BigEndian.PutUint64(m.buf[:], key)
+	return m.buf[:]
+}
+
+func (m *artMap) Insert(key uint64) {
+	m.tree.Insert(m.key(key), nil)
+}
+
+func (m *artMap) Get(key uint64) bool {
+	_, ok := m.tree.Get(m.key(key))
+	return ok
+}
+
+func (m *artMap) Scan(start uint64, n int) int {
+	i := 0
+	for iter := m.tree.Iter(m.key(start)); i < n && iter.Next(); {
+		i++
+	}
+
+	return i
+}
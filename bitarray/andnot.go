@@ -0,0 +1,105 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitarray
+
+func andNotSparseWithSparseBitArray(sba, other *sparseBitArray) BitArray {
+	indices := make(uintSlice, 0, len(sba.indices))
+	blocks := make(blocks, 0, len(sba.indices))
+
+	otherIndex := 0
+	for selfIndex, selfValue := range sba.indices {
+		// skip past any blocks in `other` that come before this one,
+		// they have nothing to clear
+		for otherIndex < len(other.indices) && other.indices[otherIndex] < selfValue {
+			otherIndex++
+		}
+
+		block := sba.blocks[selfIndex]
+		if otherIndex < len(other.indices) && other.indices[otherIndex] == selfValue {
+			block = block.andNot(other.blocks[otherIndex])
+		}
+
+		// a sparse array only stores blocks with bits set
+		if block == 0 {
+			continue
+		}
+
+		indices = append(indices, selfValue)
+		blocks = append(blocks, block)
+	}
+
+	return &sparseBitArray{
+		indices: indices,
+		blocks:  blocks,
+	}
+}
+
+func andNotSparseWithDenseBitArray(sba *sparseBitArray, other *bitArray) BitArray {
+	indices := make(uintSlice, 0, len(sba.indices))
+	blocks := make(blocks, 0, len(sba.indices))
+
+	for selfIndex, selfValue := range sba.indices {
+		block := sba.blocks[selfIndex]
+		if selfValue < uint64(len(other.blocks)) {
+			block = block.andNot(other.blocks[selfValue])
+		}
+
+		if block == 0 {
+			continue
+		}
+
+		indices = append(indices, selfValue)
+		blocks = append(blocks, block)
+	}
+
+	return &sparseBitArray{
+		indices: indices,
+		blocks:  blocks,
+	}
+}
+
+func andNotDenseWithSparseBitArray(dba *bitArray, other *sparseBitArray) BitArray {
+	ba := dba.copy().(*bitArray)
+
+	for otherIndex, otherValue := range other.indices {
+		if otherValue >= uint64(len(ba.blocks)) {
+			// indices are sorted so there is nothing left to clear
+			break
+		}
+
+		ba.blocks[otherValue] = ba.blocks[otherValue].andNot(other.blocks[otherIndex])
+	}
+
+	ba.setLowest()
+	ba.setHighest()
+
+	return ba
+}
+
+func andNotDenseWithDenseBitArray(dba, other *bitArray) BitArray {
+	ba := dba.copy().(*bitArray)
+
+	min := minUint64(uint64(len(ba.blocks)), uint64(len(other.blocks)))
+	for i := uint64(0); i < min; i++ {
+		ba.blocks[i] = ba.blocks[i].andNot(other.blocks[i])
+	}
+
+	ba.setLowest()
+	ba.setHighest()
+
+	return ba
+}
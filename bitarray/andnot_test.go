@@ -0,0 +1,114 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAndNotSparseWithSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	other := newSparseBitArray()
+
+	// bits only set in sba survive
+	sba.SetBit(3)
+	sba.SetBit(280)
+	// bits set in both are cleared, emptying the block at 2680
+	sba.SetBit(1)
+	other.SetBit(1)
+	sba.SetBit(2680)
+	other.SetBit(2680)
+	// bits only set in other are ignored
+	other.SetBit(9)
+	other.SetBit(5000)
+
+	ba := andNotSparseWithSparseBitArray(sba, other)
+
+	assert.Equal(t, []uint64{3, 280}, ba.ToNums())
+	assert.Len(t, ba.(*sparseBitArray).indices, 2)
+	assert.Nil(t, ba.Validate())
+	// inputs are untouched
+	assert.Equal(t, []uint64{1, 3, 280, 2680}, sba.ToNums())
+}
+
+func TestAndNotSparseWithDenseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	other := newBitArray(200)
+
+	sba.SetBit(1)
+	other.SetBit(1)
+	sba.SetBit(150)
+	other.SetBit(151)
+	// beyond the dense array's capacity
+	sba.SetBit(500)
+
+	ba := andNotSparseWithDenseBitArray(sba, other)
+
+	assert.Equal(t, []uint64{150, 500}, ba.ToNums())
+	assert.Nil(t, ba.Validate())
+}
+
+func TestAndNotDenseWithSparseBitArray(t *testing.T) {
+	dba := newBitArray(300)
+	other := newSparseBitArray()
+
+	dba.SetBit(1)
+	dba.SetBit(2)
+	dba.SetBit(299)
+	other.SetBit(1)
+	other.SetBit(299)
+	other.SetBit(1000)
+
+	ba := andNotDenseWithSparseBitArray(dba, other)
+
+	assert.Equal(t, []uint64{2}, ba.ToNums())
+	assert.Equal(t, dba.Capacity(), ba.Capacity())
+	assert.Nil(t, ba.Validate())
+	assert.Equal(t, []uint64{1, 2, 299}, dba.ToNums())
+}
+
+func TestAndNotDenseWithDenseBitArray(t *testing.T) {
+	dba := newBitArray(300, true)
+	other := newBitArray(100, true)
+
+	ba := andNotDenseWithDenseBitArray(dba, other)
+
+	checkBit(t, ba, 0, false)
+	checkBit(t, ba, 127, false)
+	checkBit(t, ba, 128, true)
+	checkBit(t, ba, 300, true)
+	assert.Nil(t, ba.Validate())
+
+	ba = andNotDenseWithDenseBitArray(other, dba)
+	assert.Equal(t, []uint64{}, ba.ToNums())
+	assert.Nil(t, ba.Validate())
+}
+
+func TestAndNotDispatch(t *testing.T) {
+	sba := NewSparseBitArray()
+	dba := NewBitArray(100)
+	sba.SetBit(4)
+	sba.SetBit(5)
+	dba.SetBit(5)
+
+	assert.Equal(t, []uint64{4}, sba.AndNot(dba).ToNums())
+	assert.Equal(t, []uint64{}, dba.AndNot(sba).ToNums())
+	assert.Equal(t, []uint64{5}, dba.AndNot(NewSparseBitArray()).ToNums())
+	assert.Equal(t, []uint64{4, 5}, sba.AndNot(NewSparseBitArray()).ToNums())
+}
@@ -20,12 +20,21 @@ efficient way.  This is *NOT* a threadsafe package.
 */
 package bitarray
 
+import (
+	"fmt"
+	"iter"
+	"unsafe"
+)
+
 // bitArray is a struct that maintains state of a bit array.
 type bitArray struct {
 	blocks  []block
 	lowest  uint64
 	highest uint64
 	anyset  bool
+	// shared indicates that blocks is also referenced by a
+	// snapshot and must be copied before it is mutated.
+	shared bool
 }
 
 func getIndexAndRemainder(k uint64) (uint64, uint64) {
@@ -66,6 +75,12 @@ func (ba *bitArray) setHighest() {
 	ba.lowest = 0
 }
 
+// SizeOf returns an estimate of the number of bytes used by this
+// bit array, which is dominated by its blocks.
+func (ba *bitArray) SizeOf() uint64 {
+	return uint64(unsafe.Sizeof(*ba)) + uint64(cap(ba.blocks))*blockSize
+}
+
 // capacity returns the total capacity of the bit array.
 func (ba *bitArray) Capacity() uint64 {
 	return uint64(len(ba.blocks)) * s
@@ -99,6 +114,7 @@ func (ba *bitArray) SetBit(k uint64) error {
 		}
 	}
 
+	ba.unshare()
 	i, pos := getIndexAndRemainder(k)
 	ba.blocks[i] = ba.blocks[i].insert(pos)
 	return nil
@@ -126,6 +142,7 @@ func (ba *bitArray) ClearBit(k uint64) error {
 		return nil
 	}
 
+	ba.unshare()
 	i, pos := getIndexAndRemainder(k)
 	ba.blocks[i] &^= block(1 << pos)
 
@@ -137,9 +154,82 @@ func (ba *bitArray) ClearBit(k uint64) error {
 	return nil
 }
 
+// Rank returns the number of set bits at or before the given index.
+func (ba *bitArray) Rank(k uint64) uint64 {
+	return rank(ba.Blocks(), k)
+}
+
+// Select returns the index of the n-th set bit, counting from one.
+func (ba *bitArray) Select(n uint64) (uint64, error) {
+	return selectBit(ba.Blocks(), n)
+}
+
+// NextSet returns the first set bit at or after the given index.
+func (ba *bitArray) NextSet(k uint64) (uint64, bool) {
+	if !ba.anyset || k > ba.highest {
+		return 0, false
+	}
+
+	if k <= ba.lowest {
+		return ba.lowest, true
+	}
+
+	i, pos := getIndexAndRemainder(k)
+	for ; i < uint64(len(ba.blocks)); i++ {
+		if p, ok := ba.blocks[i].nextSet(pos); ok {
+			return i*s + p, true
+		}
+		pos = 0
+	}
+
+	return 0, false
+}
+
+// PrevSet returns the last set bit at or before the given index.
+func (ba *bitArray) PrevSet(k uint64) (uint64, bool) {
+	if !ba.anyset || k < ba.lowest {
+		return 0, false
+	}
+
+	if k >= ba.highest {
+		return ba.highest, true
+	}
+
+	i, pos := getIndexAndRemainder(k)
+	for {
+		if p, ok := ba.blocks[i].prevSet(pos); ok {
+			return i*s + p, true
+		}
+
+		if i == 0 {
+			return 0, false
+		}
+		i--
+		pos = s - 1
+	}
+}
+
+// Iterate calls fn with every set bit in ascending order until fn
+// returns false.
+func (ba *bitArray) Iterate(fn func(uint64) bool) {
+	if ba.anyset {
+		iterate(ba.Blocks(), fn)
+	}
+}
+
+// All returns a sequence of the position of every set bit in
+// ascending order for use with range.
+func (ba *bitArray) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		ba.Iterate(yield)
+	}
+}
+
 // Or will bitwise or two bit arrays and return a new bit array
 // representing the result.
 func (ba *bitArray) Or(other BitArray) BitArray {
+	other = fromRoaring(other)
+
 	if dba, ok := other.(*bitArray); ok {
 		return orDenseWithDenseBitArray(ba, dba)
 	}
@@ -150,6 +240,8 @@ func (ba *bitArray) Or(other BitArray) BitArray {
 // And will bitwise and two bit arrays and return a new bit array
 // representing the result.
 func (ba *bitArray) And(other BitArray) BitArray {
+	other = fromRoaring(other)
+
 	if dba, ok := other.(*bitArray); ok {
 		return andDenseWithDenseBitArray(ba, dba)
 	}
@@ -157,48 +249,239 @@ func (ba *bitArray) And(other BitArray) BitArray {
 	return andSparseWithDenseBitArray(other.(*sparseBitArray), ba)
 }
 
-// Reset clears out the bit array.
-func (ba *bitArray) Reset() {
-	for i := uint64(0); i < uint64(len(ba.blocks)); i++ {
-		ba.blocks[i] &= block(0)
+// AndNot will clear the bits set in the other bit array from a copy
+// of this bit array and return the result.
+func (ba *bitArray) AndNot(other BitArray) BitArray {
+	other = fromRoaring(other)
+
+	if dba, ok := other.(*bitArray); ok {
+		return andNotDenseWithDenseBitArray(ba, dba)
 	}
-	ba.anyset = false
+
+	return andNotDenseWithSparseBitArray(ba, other.(*sparseBitArray))
 }
 
-// Equals returns a bool indicating if these two bit arrays are equal.
-func (ba *bitArray) Equals(other BitArray) bool {
-	if other.Capacity() == 0 && ba.highest > 0 {
-		return false
+// Not returns a new bit array of capacity max with every bit in
+// [0, max) that is clear in this bit array set.
+func (ba *bitArray) Not(max uint64) BitArray {
+	result := newBitArray(max)
+	for i := range result.blocks {
+		block := maximumBlock
+		if i < len(ba.blocks) {
+			block = ^ba.blocks[i]
+		}
+		result.blocks[i] = block
+	}
+
+	if r := max % s; r > 0 {
+		result.blocks[len(result.blocks)-1] &= maximumBlock >> (s - r)
+	}
+
+	result.setLowest()
+	result.setHighest()
+	return result
+}
+
+// Xor will bitwise xor two bit arrays and return a new bit array
+// representing the result.
+func (ba *bitArray) Xor(other BitArray) BitArray {
+	other = fromRoaring(other)
+
+	if dba, ok := other.(*bitArray); ok {
+		return xorDenseWithDenseBitArray(ba, dba)
+	}
+
+	return xorSparseWithDenseBitArray(other.(*sparseBitArray), ba)
+}
+
+// Count returns the number of set bits.
+func (ba *bitArray) Count() uint64 {
+	count := uint64(0)
+	for _, block := range ba.blocks {
+		count += block.count()
 	}
 
-	if other.Capacity() == 0 && !ba.anyset {
-		return true
+	return count
+}
+
+// OrInPlace will bitwise or the other bit array into this one,
+// growing this bit array if needed.
+func (ba *bitArray) OrInPlace(other BitArray) {
+	ba.unshare()
+	for iter := other.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		if block == 0 {
+			continue
+		}
+
+		ba.grow(index + 1)
+		ba.blocks[index] = ba.blocks[index].or(block)
+	}
+
+	ba.resetBounds()
+}
+
+// AndInPlace will bitwise and the other bit array into this one.
+func (ba *bitArray) AndInPlace(other BitArray) {
+	ba.unshare()
+	next := uint64(0)
+	for iter := other.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		if index >= uint64(len(ba.blocks)) {
+			break
+		}
+
+		for ; next < index; next++ {
+			ba.blocks[next] = 0
+		}
+		ba.blocks[index] = ba.blocks[index].and(block)
+		next = index + 1
+	}
+
+	for ; next < uint64(len(ba.blocks)); next++ {
+		ba.blocks[next] = 0
+	}
+
+	ba.resetBounds()
+}
+
+// AndNotInPlace will clear every bit in this bit array that is set in
+// the other.
+func (ba *bitArray) AndNotInPlace(other BitArray) {
+	ba.unshare()
+	for iter := other.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		if index >= uint64(len(ba.blocks)) {
+			break
+		}
+
+		ba.blocks[index] = ba.blocks[index].andNot(block)
 	}
 
-	var selfIndex uint64
+	ba.resetBounds()
+}
+
+// XorInPlace will bitwise xor the other bit array into this one,
+// growing this bit array if needed.
+func (ba *bitArray) XorInPlace(other BitArray) {
+	ba.unshare()
 	for iter := other.Blocks(); iter.Next(); {
-		toIndex, otherBlock := iter.Value()
-		if toIndex > selfIndex {
-			for i := selfIndex; i < toIndex; i++ {
-				if ba.blocks[i] > 0 {
-					return false
-				}
+		index, block := iter.Value()
+		if block == 0 {
+			continue
+		}
+
+		ba.grow(index + 1)
+		ba.blocks[index] = ba.blocks[index].xor(block)
+	}
+
+	ba.resetBounds()
+}
+
+// SetRange sets every bit in [start, stop).
+func (ba *bitArray) SetRange(start, stop uint64) error {
+	if stop > ba.Capacity() {
+		return OutOfRangeError(stop - 1)
+	}
+
+	if start >= stop {
+		return nil
+	}
+
+	ba.unshare()
+	forRange(start, stop, func(index uint64, mask block) {
+		ba.blocks[index] = ba.blocks[index].or(mask)
+	})
+
+	if !ba.anyset || start < ba.lowest {
+		ba.lowest = start
+	}
+	if !ba.anyset || stop-1 > ba.highest {
+		ba.highest = stop - 1
+	}
+	ba.anyset = true
+	return nil
+}
+
+// ClearRange clears every bit in [start, stop).
+func (ba *bitArray) ClearRange(start, stop uint64) error {
+	if stop > ba.Capacity() {
+		return OutOfRangeError(stop - 1)
+	}
+
+	ba.unshare()
+	forRange(start, stop, func(index uint64, mask block) {
+		ba.blocks[index] = ba.blocks[index].andNot(mask)
+	})
+
+	ba.resetBounds()
+	return nil
+}
+
+// ShiftLeft moves every bit n positions higher, dropping bits moved
+// past the end of this bit array.
+func (ba *bitArray) ShiftLeft(n uint64) {
+	ba.unshare()
+	q, r := getIndexAndRemainder(n)
+	for i := uint64(len(ba.blocks)); i > 0; i-- {
+		dst := i - 1
+		b := block(0)
+		if dst >= q {
+			src := dst - q
+			b = ba.blocks[src] << r
+			if r > 0 && src > 0 {
+				b |= ba.blocks[src-1] >> (s - r)
 			}
 		}
+		ba.blocks[dst] = b
+	}
 
-		selfIndex = toIndex
-		if !ba.blocks[selfIndex].equals(otherBlock) {
-			return false
+	ba.resetBounds()
+}
+
+// ShiftRight moves every bit n positions lower, dropping bits moved
+// below zero.
+func (ba *bitArray) ShiftRight(n uint64) {
+	ba.unshare()
+	q, r := getIndexAndRemainder(n)
+	size := uint64(len(ba.blocks))
+	for dst := uint64(0); dst < size; dst++ {
+		b := block(0)
+		if q < size && dst < size-q {
+			src := dst + q
+			b = ba.blocks[src] >> r
+			if r > 0 && src+1 < size {
+				b |= ba.blocks[src+1] << (s - r)
+			}
 		}
-		selfIndex++
+		ba.blocks[dst] = b
 	}
 
-	lastIndex, _ := getIndexAndRemainder(ba.highest)
-	if lastIndex >= selfIndex {
-		return false
+	ba.resetBounds()
+}
+
+// Reset clears out the bit array.
+func (ba *bitArray) Reset() {
+	if ba.shared {
+		ba.blocks = make([]block, len(ba.blocks))
+		ba.shared = false
 	}
+	for i := uint64(0); i < uint64(len(ba.blocks)); i++ {
+		ba.blocks[i] &= block(0)
+	}
+	ba.anyset = false
+}
 
-	return true
+// Equals returns a bool indicating if these two bit arrays have the
+// same bits set.
+func (ba *bitArray) Equals(other BitArray) bool {
+	return blocksEqual(ba.Blocks(), other.Blocks())
+}
+
+// Hash returns a hash of the set bits that is the same for any equal
+// bit array.
+func (ba *bitArray) Hash() uint64 {
+	return hashBlocks(ba.Blocks())
 }
 
 // Intersects returns a bool indicating if the supplied bitarray intersects
@@ -206,6 +489,8 @@ func (ba *bitArray) Equals(other BitArray) bool {
 // bitarray.  If the supplied bitarray is longer than this bitarray, this
 // function returns false.
 func (ba *bitArray) Intersects(other BitArray) bool {
+	other = fromRoaring(other)
+
 	if other.Capacity() > ba.Capacity() {
 		return false
 	}
@@ -224,6 +509,7 @@ func (ba *bitArray) Blocks() Iterator {
 
 // complement flips all bits in this array.
 func (ba *bitArray) complement() {
+	ba.unshare()
 	for i := uint64(0); i < uint64(len(ba.blocks)); i++ {
 		ba.blocks[i] = ^ba.blocks[i]
 	}
@@ -265,6 +551,68 @@ func (ba *bitArray) copy() BitArray {
 	}
 }
 
+// unshare copies this bit array's blocks if they are referenced
+// by a snapshot.  Must be called before any mutation.
+func (ba *bitArray) unshare() {
+	if !ba.shared {
+		return
+	}
+
+	blocks := make([]block, len(ba.blocks))
+	copy(blocks, ba.blocks)
+	ba.blocks = blocks
+	ba.shared = false
+}
+
+// Snapshot returns a point-in-time copy of this bit array.  Blocks
+// are shared with the copy until either bit array is modified.
+func (ba *bitArray) Snapshot() BitArray {
+	ba.shared = true
+	return &bitArray{
+		blocks:  ba.blocks,
+		lowest:  ba.lowest,
+		highest: ba.highest,
+		anyset:  ba.anyset,
+		shared:  true,
+	}
+}
+
+// Validate checks that the cached lowest and highest set bits agree
+// with the blocks and returns an error describing any disagreement.
+func (ba *bitArray) Validate() error {
+	var lowest, highest uint64
+	anyset := false
+	for i, block := range ba.blocks {
+		if block == 0 {
+			continue
+		}
+
+		if !anyset {
+			lowest = uint64(i)*s + block.findRightPosition()
+			anyset = true
+		}
+		highest = uint64(i)*s + block.findLeftPosition()
+	}
+
+	if anyset != ba.anyset {
+		return fmt.Errorf(`Bit array reports anyset %t, blocks indicate %t.`, ba.anyset, anyset)
+	}
+
+	if !anyset {
+		return nil
+	}
+
+	if lowest != ba.lowest {
+		return fmt.Errorf(`Bit array reports lowest %d, blocks indicate %d.`, ba.lowest, lowest)
+	}
+
+	if highest != ba.highest {
+		return fmt.Errorf(`Bit array reports highest %d, blocks indicate %d.`, ba.highest, highest)
+	}
+
+	return nil
+}
+
 // newBitArray returns a new dense BitArray at the specified size. This is a
 // separate private constructor so unit tests don't have to constantly cast the
 // BitArray interface to the concrete type.
@@ -17,9 +17,12 @@ limitations under the License.
 package bitarray
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 func TestBitOperations(t *testing.T) {
@@ -132,6 +135,7 @@ func TestOutOfBounds(t *testing.T) {
 	if _, ok := err.(OutOfRangeError); !ok {
 		t.Errorf(`Expected out of range error.`)
 	}
+	assert.True(t, errors.Is(err, common.ErrOutOfRange))
 }
 
 func TestClear(t *testing.T) {
@@ -406,6 +410,44 @@ func TestBitArrayEqualsSparse(t *testing.T) {
 	assert.True(t, ba.Equals(cba))
 }
 
+func TestEqualsAndHashAcrossImplementations(t *testing.T) {
+	arrays := []BitArray{newBitArray(s * 4), newSparseBitArray(), newRoaringBitArray()}
+	for _, ba := range arrays {
+		ba.SetBit(3)
+		ba.SetBit(s * 2)
+	}
+
+	for _, ba := range arrays {
+		for _, other := range arrays {
+			assert.True(t, ba.Equals(other))
+			assert.Equal(t, ba.Hash(), other.Hash())
+		}
+	}
+
+	// bits past the end of the other array must not compare equal
+	longer := newSparseBitArray()
+	longer.SetBit(3)
+	longer.SetBit(s * 2)
+	longer.SetBit(s * 8)
+	for _, ba := range arrays {
+		assert.False(t, ba.Equals(longer))
+		assert.False(t, longer.Equals(ba))
+		assert.NotEqual(t, ba.Hash(), longer.Hash())
+	}
+
+	// a cleared block hashes as if it was never set
+	longer.ClearBit(s * 8)
+	assert.True(t, arrays[0].Equals(longer))
+	assert.Equal(t, arrays[0].Hash(), longer.Hash())
+
+	// the position of a block is part of the hash
+	moved := newSparseBitArray()
+	moved.SetBit(3 + s)
+	moved.SetBit(s * 3)
+	assert.NotEqual(t, arrays[1].Hash(), moved.Hash())
+	assert.Equal(t, newBitArray(10).Hash(), newRoaringBitArray().Hash())
+}
+
 func TestConstructorSetBitArray(t *testing.T) {
 	ba := newBitArray(8, true)
 
@@ -429,6 +471,92 @@ func TestCopyBitArray(t *testing.T) {
 	assert.Equal(t, ba.blocks, result.blocks)
 }
 
+func TestSnapshotBitArray(t *testing.T) {
+	ba := newBitArray(s * 2)
+	ba.SetBit(5)
+
+	snapshot := ba.Snapshot()
+	ba.SetBit(s + 1)
+	ba.ClearBit(5)
+
+	assert.Equal(t, []uint64{5}, snapshot.ToNums())
+	assert.Equal(t, []uint64{s + 1}, ba.ToNums())
+
+	snapshot.SetBit(6)
+	assert.Equal(t, []uint64{5, 6}, snapshot.ToNums())
+	assert.Equal(t, []uint64{s + 1}, ba.ToNums())
+}
+
+func TestSnapshotBitArrayReset(t *testing.T) {
+	ba := newBitArray(s)
+	ba.SetBit(5)
+
+	snapshot := ba.Snapshot()
+	ba.Reset()
+
+	result, err := snapshot.GetBit(5)
+	assert.Nil(t, err)
+	assert.True(t, result)
+	result, err = ba.GetBit(5)
+	assert.Nil(t, err)
+	assert.False(t, result)
+}
+
+func TestValidateBitArray(t *testing.T) {
+	ba := newBitArray(s * 4)
+	assert.Nil(t, ba.Validate())
+
+	ba.SetBit(s + 3)
+	ba.SetBit(s*3 + 1)
+	ba.SetBit(5)
+	assert.Nil(t, ba.Validate())
+
+	ba.ClearBit(5)
+	ba.ClearBit(s*3 + 1)
+	assert.Nil(t, ba.Validate())
+
+	ba.ClearBit(s + 3)
+	assert.Nil(t, ba.Validate())
+
+	ba.SetBit(7)
+	ba.Reset()
+	assert.Nil(t, ba.Validate())
+
+	assert.Nil(t, newBitArray(s*2, true).Validate())
+	ba.SetBit(2)
+	assert.Nil(t, ba.Or(newBitArray(s*3, true)).Validate())
+	assert.Nil(t, ba.And(newBitArray(s*3, true)).Validate())
+}
+
+func TestValidateBitArrayCorruption(t *testing.T) {
+	ba := newBitArray(s * 2)
+	ba.SetBit(3)
+	ba.SetBit(s + 3)
+
+	ba.lowest = 2
+	assert.NotNil(t, ba.Validate())
+	ba.lowest = 3
+
+	ba.highest = s
+	assert.NotNil(t, ba.Validate())
+	ba.highest = s + 3
+
+	ba.anyset = false
+	assert.NotNil(t, ba.Validate())
+	ba.anyset = true
+
+	assert.Nil(t, ba.Validate())
+}
+
+func TestSizeOfBitArray(t *testing.T) {
+	ba := newBitArray(s * 10)
+	empty := newBitArray(0).SizeOf()
+	assert.Equal(t, empty+10*blockSize, ba.SizeOf())
+
+	ba.SetBit(s*10 - 1)
+	assert.Equal(t, empty+10*blockSize, ba.SizeOf())
+}
+
 func BenchmarkDenseIntersectsCompressed(b *testing.B) {
 	numBits := uint64(162432)
 	ba := newBitArray(numBits)
@@ -472,3 +600,27 @@ func BenchmarkBitArrayToNums(b *testing.B) {
 		ba.ToNums()
 	}
 }
+
+func TestNotBitArray(t *testing.T) {
+	ba := newBitArray(s * 2)
+	ba.SetBit(0)
+	ba.SetBit(2)
+	ba.SetBit(s + 1)
+
+	result := ba.Not(5)
+	assert.Equal(t, []uint64{1, 3, 4}, result.ToNums())
+	assert.Equal(t, s, result.Capacity())
+	assert.Nil(t, result.Validate())
+
+	// the universe may be larger than the bit array
+	result = ba.Not(s*2 + 3)
+	assert.Equal(t, s*2+3-3, result.Count())
+	assert.True(t, result.Capacity() >= s*2+3)
+	assert.Nil(t, result.Validate())
+	assert.Equal(t, []uint64{0, 2, s + 1}, result.Not(s*2+3).ToNums())
+
+	assert.Equal(t, uint64(0), ba.Not(0).Count())
+	full := newBitArray(s, true)
+	assert.Equal(t, uint64(0), full.Not(s).Count())
+	assert.Nil(t, full.Not(s).Validate())
+}
@@ -18,6 +18,7 @@ package bitarray
 
 import (
 	"fmt"
+	"math/bits"
 	"unsafe"
 )
 
@@ -32,6 +33,9 @@ type block uint64
 // and so on...
 const s = uint64(unsafe.Sizeof(block(0)) * 8)
 
+// blockSize is the number of bytes used by a single block.
+const blockSize = uint64(unsafe.Sizeof(block(0)))
+
 // maximumBlock represents a block of all 1s and is used in the constructors.
 const maximumBlock = block(0) | ^block(0)
 
@@ -81,6 +85,14 @@ func (b block) and(other block) block {
 	return b & other
 }
 
+func (b block) andNot(other block) block {
+	return b &^ other
+}
+
+func (b block) xor(other block) block {
+	return b ^ other
+}
+
 func (b block) get(position uint64) bool {
 	return b&block(1<<position) != 0
 }
@@ -93,6 +105,49 @@ func (b block) intersects(other block) bool {
 	return b&other == other
 }
 
+// count returns the number of set bits in this block.
+func (b block) count() uint64 {
+	return uint64(bits.OnesCount64(uint64(b)))
+}
+
+// rank returns the number of set bits in this block at or below the
+// given position.
+func (b block) rank(position uint64) uint64 {
+	return (b & (maximumBlock >> (s - 1 - position))).count()
+}
+
+// selectBit returns the position of the n-th set bit in this block,
+// counting from one.  n must be between one and the block's count.
+func (b block) selectBit(n uint64) uint64 {
+	for ; n > 1; n-- {
+		b &= b - 1 // clear the lowest set bit
+	}
+
+	return uint64(bits.TrailingZeros64(uint64(b)))
+}
+
+// nextSet returns the lowest set bit at or above the given position
+// and a bool indicating if there is one.
+func (b block) nextSet(position uint64) (uint64, bool) {
+	b &= maximumBlock << position
+	if b == 0 {
+		return 0, false
+	}
+
+	return uint64(bits.TrailingZeros64(uint64(b))), true
+}
+
+// prevSet returns the highest set bit at or below the given position
+// and a bool indicating if there is one.
+func (b block) prevSet(position uint64) (uint64, bool) {
+	b &= maximumBlock >> (s - 1 - position)
+	if b == 0 {
+		return 0, false
+	}
+
+	return uint64(bits.Len64(uint64(b))) - 1, true
+}
+
 func (b block) String() string {
 	return fmt.Sprintf(fmt.Sprintf("%%0%db", s), uint64(b))
 }
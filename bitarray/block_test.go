@@ -35,6 +35,19 @@ func TestBlockToNums(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestBlockRankAndSelect(t *testing.T) {
+	b := block(0).insert(0).insert(5).insert(s - 1)
+
+	assert.Equal(t, uint64(3), b.count())
+	assert.Equal(t, uint64(1), b.rank(0))
+	assert.Equal(t, uint64(1), b.rank(4))
+	assert.Equal(t, uint64(2), b.rank(5))
+	assert.Equal(t, uint64(3), b.rank(s-1))
+	assert.Equal(t, uint64(0), b.selectBit(1))
+	assert.Equal(t, uint64(5), b.selectBit(2))
+	assert.Equal(t, s-1, b.selectBit(3))
+}
+
 func BenchmarkBlockToNums(b *testing.B) {
 	block := block(0)
 	for i := uint64(0); i < s; i++ {
@@ -0,0 +1,397 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// Bit arrays are serialized in a stable little-endian format.  Every
+// encoding starts with a 16 byte header: a version byte, a kind byte,
+// six reserved zero bytes and a uint64 count.  What follows depends on
+// the kind:
+//
+//	dense:   count blocks as uint64s.
+//	sparse:  count block indices as uint64s, then count blocks as
+//	         uint64s.
+//	roaring: count containers, each a uint64 key, a uint64 container
+//	         kind and a uint64 length followed by the container's
+//	         values (uint16s for an array, pairs of uint16s for runs
+//	         or the bitmap's blocks as uint64s) padded to 8 bytes.
+//
+// Everything is 8 byte aligned so dense and sparse encodings can be
+// viewed in place by FromMmap.
+
+// encodingVersion must be bumped whenever the block layout changes.
+const encodingVersion = 1
+
+// headerSize is the number of bytes before the encoded blocks.
+const headerSize = 16
+
+const (
+	denseEncoding byte = iota + 1
+	sparseEncoding
+	roaringEncoding
+)
+
+const (
+	arrayContainerEncoding uint64 = iota + 1
+	bitmapContainerEncoding
+	runContainerEncoding
+)
+
+// encoder writes little-endian values, remembering the first error
+// so callers can check once when they are done.
+type encoder struct {
+	w   *bufio.Writer
+	buf [8]byte
+	n   uint64
+	err error
+}
+
+func newEncoder(w io.Writer, kind byte, count uint64) *encoder {
+	e := &encoder{w: bufio.NewWriter(w)}
+	e.write([]byte{encodingVersion, kind, 0, 0, 0, 0, 0, 0})
+	e.uint64(count)
+	return e
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = e.w.Write(p)
+	e.n += uint64(len(p))
+}
+
+func (e *encoder) uint64(x uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:], x)
+	e.write(e.buf[:8])
+}
+
+func (e *encoder) uint16(x uint16) {
+	binary.LittleEndian.PutUint16(e.buf[:], x)
+	e.write(e.buf[:2])
+}
+
+func (e *encoder) blocks(blocks []block) {
+	for _, block := range blocks {
+		e.uint64(uint64(block))
+	}
+}
+
+// align pads the output to a multiple of 8 bytes.
+func (e *encoder) align() {
+	var zeros [8]byte
+	e.write(zeros[:(8-e.n%8)%8])
+}
+
+func (e *encoder) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+// decoder reads little-endian values, remembering the first error so
+// callers can check once when they are done.
+type decoder struct {
+	r   *bufio.Reader
+	buf [8]byte
+	n   uint64
+	err error
+}
+
+func (d *decoder) read(p []byte) {
+	if d.err != nil {
+		return
+	}
+
+	_, d.err = io.ReadFull(d.r, p)
+	d.n += uint64(len(p))
+}
+
+func (d *decoder) uint64() uint64 {
+	d.read(d.buf[:8])
+	return binary.LittleEndian.Uint64(d.buf[:])
+}
+
+func (d *decoder) uint16() uint16 {
+	d.read(d.buf[:2])
+	return binary.LittleEndian.Uint16(d.buf[:])
+}
+
+// initialCapacity bounds the space reserved up front for a decoded
+// count so corrupt input can't cause a huge allocation; slices grow
+// as values are actually read.
+func initialCapacity(count uint64) uint64 {
+	return minUint64(count, 1<<16)
+}
+
+func (d *decoder) uint64s(count uint64) []uint64 {
+	nums := make([]uint64, 0, initialCapacity(count))
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		nums = append(nums, d.uint64())
+	}
+
+	return nums
+}
+
+func (d *decoder) blocks(count uint64) []block {
+	blocks := make([]block, 0, initialCapacity(count))
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		blocks = append(blocks, block(d.uint64()))
+	}
+
+	return blocks
+}
+
+// align skips the padding written by encoder.align.
+func (d *decoder) align() {
+	var padding [8]byte
+	d.read(padding[:(8-d.n%8)%8])
+}
+
+// Serialize writes this bit array to the provided writer.
+func (ba *bitArray) Serialize(w io.Writer) error {
+	e := newEncoder(w, denseEncoding, uint64(len(ba.blocks)))
+	e.blocks(ba.blocks)
+	return e.flush()
+}
+
+// Serialize writes this bit array to the provided writer.
+func (sba *sparseBitArray) Serialize(w io.Writer) error {
+	e := newEncoder(w, sparseEncoding, uint64(len(sba.indices)))
+	for _, index := range sba.indices {
+		e.uint64(index)
+	}
+	e.blocks(sba.blocks)
+	return e.flush()
+}
+
+// Serialize writes this bit array to the provided writer, container
+// by container.
+func (rba *roaringBitArray) Serialize(w io.Writer) error {
+	e := newEncoder(w, roaringEncoding, uint64(len(rba.keys)))
+	for i, c := range rba.containers {
+		e.uint64(rba.keys[i])
+		switch c := c.(type) {
+		case arrayContainer:
+			e.uint64(arrayContainerEncoding)
+			e.uint64(uint64(len(c)))
+			for _, x := range c {
+				e.uint16(x)
+			}
+		case *bitmapContainer:
+			e.uint64(bitmapContainerEncoding)
+			e.uint64(uint64(len(c.blocks)))
+			e.blocks(c.blocks)
+		case runContainer:
+			e.uint64(runContainerEncoding)
+			e.uint64(uint64(len(c)))
+			for _, r := range c {
+				e.uint16(r.start)
+				e.uint16(r.last)
+			}
+		}
+		e.align()
+	}
+
+	return e.flush()
+}
+
+func decodeHeader(header []byte) (byte, error) {
+	if header[0] != encodingVersion {
+		return 0, fmt.Errorf(`Unknown encoding version %d.`, header[0])
+	}
+
+	if kind := header[1]; kind < denseEncoding || kind > roaringEncoding {
+		return 0, fmt.Errorf(`Unknown bit array kind %d.`, kind)
+	}
+
+	return header[1], nil
+}
+
+func (d *decoder) container() container {
+	kind, length := d.uint64(), d.uint64()
+	var c container
+	switch {
+	case d.err != nil:
+		return nil
+	case kind == arrayContainerEncoding && length <= arrayLimit:
+		ac := make(arrayContainer, 0, length)
+		for i := uint64(0); i < length; i++ {
+			ac = append(ac, d.uint16())
+		}
+		c = ac
+	case kind == bitmapContainerEncoding && length == containerBlocks:
+		bc := &bitmapContainer{blocks: d.blocks(length)}
+		for _, block := range bc.blocks {
+			bc.card += block.count()
+		}
+		c = bc
+	case kind == runContainerEncoding && length <= runLimit:
+		rc := make(runContainer, 0, length)
+		for i := uint64(0); i < length; i++ {
+			rc = append(rc, run{start: d.uint16(), last: d.uint16()})
+		}
+		c = rc
+	default:
+		d.err = fmt.Errorf(`Invalid container kind %d with length %d.`, kind, length)
+		return nil
+	}
+
+	d.align()
+	return c
+}
+
+// Deserialize reads a bit array written by Serialize.  The returned
+// bit array is of the same kind as the one that was serialized.
+func Deserialize(r io.Reader) (BitArray, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+	header := make([]byte, 8)
+	d.read(header)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	kind, err := decodeHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	count := d.uint64()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var ba BitArray
+	switch kind {
+	case denseEncoding:
+		dba := &bitArray{blocks: d.blocks(count)}
+		dba.setLowest()
+		dba.setHighest()
+		ba = dba
+	case sparseEncoding:
+		indices := d.uint64s(count)
+		ba = &sparseBitArray{indices: indices, blocks: d.blocks(count)}
+	case roaringEncoding:
+		rba := newRoaringBitArray()
+		for i := uint64(0); i < count && d.err == nil; i++ {
+			key := d.uint64()
+			rba.append(key, d.container())
+		}
+		ba = rba
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if err := ba.Validate(); err != nil {
+		return nil, err
+	}
+
+	return ba, nil
+}
+
+// FromBytes reads a bit array written by Serialize from the provided
+// bytes, which are copied.
+func FromBytes(data []byte) (BitArray, error) {
+	return Deserialize(bytes.NewReader(data))
+}
+
+// canView returns a bool indicating if the provided bytes can be
+// reinterpreted as blocks in place, which requires a little-endian
+// machine and 8 byte alignment.
+func canView(data []byte) bool {
+	one := uint16(1)
+	littleEndian := *(*byte)(unsafe.Pointer(&one)) == 1
+	return littleEndian && len(data) >= headerSize &&
+		uintptr(unsafe.Pointer(&data[0]))%8 == 0
+}
+
+func viewUint64s(data []byte, count uint64) []uint64 {
+	if count == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*uint64)(unsafe.Pointer(&data[0])), count)
+}
+
+func viewBlocks(data []byte, count uint64) []block {
+	if count == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*block)(unsafe.Pointer(&data[0])), count)
+}
+
+// FromMmap returns a bit array backed directly by the provided bytes,
+// typically a read-only memory mapping of a file written by
+// Serialize, so large dense and sparse bit arrays can be loaded
+// without copying.  The bytes must not be modified or unmapped while
+// the bit array is in use.  The bit array copies its blocks before
+// its first modification, so it never writes to the provided bytes.
+// Roaring bit arrays, unaligned bytes and big-endian machines fall
+// back to FromBytes.
+func FromMmap(data []byte) (BitArray, error) {
+	if !canView(data) {
+		return FromBytes(data)
+	}
+
+	kind, err := decodeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	count := binary.LittleEndian.Uint64(data[8:headerSize])
+	body := data[headerSize:]
+	switch kind {
+	case denseEncoding:
+		if count > uint64(len(body))/8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		ba := &bitArray{blocks: viewBlocks(body, count), shared: true}
+		ba.setLowest()
+		ba.setHighest()
+		return ba, nil
+	case sparseEncoding:
+		if count > uint64(len(body))/16 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		sba := &sparseBitArray{
+			indices: viewUint64s(body, count),
+			blocks:  viewBlocks(body[count*8:], count),
+			shared:  true,
+		}
+		if err := sba.Validate(); err != nil {
+			return nil, err
+		}
+		return sba, nil
+	}
+
+	return FromBytes(data)
+}
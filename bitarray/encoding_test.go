@@ -0,0 +1,178 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serialize(t *testing.T, ba BitArray) []byte {
+	var buf bytes.Buffer
+	assert.Nil(t, ba.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func encodingTestArrays() []BitArray {
+	r := rand.New(rand.NewSource(7))
+	roaring, sparse, _ := randomBitArrays(r, 20000)
+	dense := NewBitArray(1000)
+	for _, k := range []uint64{0, 63, 64, 500, 999} {
+		dense.SetBit(k)
+	}
+
+	return []BitArray{
+		dense, sparse, roaring,
+		NewBitArray(0), NewSparseBitArray(), NewRoaringBitArray(),
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	for _, ba := range encodingTestArrays() {
+		data := serialize(t, ba)
+		assert.Equal(t, 0, len(data)%8)
+
+		result, err := Deserialize(bytes.NewReader(data))
+		assert.Nil(t, err)
+		assert.IsType(t, ba, result)
+		assert.Equal(t, ba.ToNums(), result.ToNums())
+		assert.Equal(t, ba.Capacity(), result.Capacity())
+
+		result, err = FromBytes(data)
+		assert.Nil(t, err)
+		assert.True(t, ba.Equals(result))
+		assert.Nil(t, result.Validate())
+	}
+}
+
+func TestSerializeFormat(t *testing.T) {
+	ba := NewBitArray(64)
+	ba.SetBit(1)
+
+	assert.Equal(t, []byte{
+		encodingVersion, denseEncoding, 0, 0, 0, 0, 0, 0,
+		1, 0, 0, 0, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+	}, serialize(t, ba))
+}
+
+func TestDeserializeInvalid(t *testing.T) {
+	sba := NewSparseBitArray()
+	sba.SetBit(1)
+	sba.SetBit(1000)
+	data := serialize(t, sba)
+
+	_, err := FromBytes(data[:len(data)-1])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = FromBytes(nil)
+	assert.Equal(t, io.EOF, err)
+
+	bad := append([]byte{}, data...)
+	bad[0] = encodingVersion + 1
+	_, err = FromBytes(bad)
+	assert.NotNil(t, err)
+
+	bad = append([]byte{}, data...)
+	bad[1] = 0
+	_, err = FromBytes(bad)
+	assert.NotNil(t, err)
+
+	// swap the two indices so they are out of order
+	bad = append([]byte{}, data...)
+	copy(bad[headerSize:], data[headerSize+8:headerSize+16])
+	copy(bad[headerSize+8:], data[headerSize:headerSize+8])
+	_, err = FromBytes(bad)
+	assert.NotNil(t, err)
+	_, err = FromMmap(bad)
+	assert.NotNil(t, err)
+}
+
+func TestFromMmap(t *testing.T) {
+	for _, ba := range encodingTestArrays() {
+		data := serialize(t, ba)
+		original := append([]byte{}, data...)
+
+		result, err := FromMmap(data)
+		assert.Nil(t, err)
+		assert.Equal(t, ba.ToNums(), result.ToNums())
+
+		// writes copy the view rather than modifying the bytes; the
+		// empty dense bit array has no room and returns an error
+		result.SetBit(2)
+		result.ClearBit(0)
+		assert.Equal(t, original, data)
+		assert.Nil(t, result.Validate())
+	}
+}
+
+func TestFromMmapSharesBytes(t *testing.T) {
+	ba := newBitArray(128)
+	ba.SetBit(70)
+	data := serialize(t, ba)
+
+	result, err := FromMmap(data)
+	assert.Nil(t, err)
+	dba := result.(*bitArray)
+	assert.Equal(t, &data[headerSize], (*byte)(unsafe.Pointer(&dba.blocks[0])))
+	assert.Equal(t, uint64(70), dba.lowest)
+
+	_, err = FromMmap(data[:len(data)-8])
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFromMmapUnaligned(t *testing.T) {
+	ba := NewSparseBitArray()
+	ba.SetBit(12345)
+	data := append([]byte{0}, serialize(t, ba)...)
+
+	result, err := FromMmap(data[1:])
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{12345}, result.ToNums())
+}
+
+func BenchmarkDeserialize(b *testing.B) {
+	ba := NewBitArray(1 << 20)
+	for i := uint64(0); i < 1<<20; i += 7 {
+		ba.SetBit(i)
+	}
+	var buf bytes.Buffer
+	ba.Serialize(&buf)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		FromBytes(buf.Bytes())
+	}
+}
+
+func BenchmarkFromMmap(b *testing.B) {
+	ba := NewBitArray(1 << 20)
+	for i := uint64(0); i < 1<<20; i += 7 {
+		ba.SetBit(i)
+	}
+	var buf bytes.Buffer
+	ba.Serialize(&buf)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		FromMmap(buf.Bytes())
+	}
+}
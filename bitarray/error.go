@@ -16,7 +16,11 @@ limitations under the License.
 
 package bitarray
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/Workiva/go-datastructures/common"
+)
 
 // OutOfRangeError is an error caused by trying to access a bitarray past the end of its
 // capacity.
@@ -26,3 +30,9 @@ type OutOfRangeError uint64
 func (err OutOfRangeError) Error() string {
 	return fmt.Sprintf(`Index %d is out of range.`, err)
 }
+
+// Is reports whether target is common.ErrOutOfRange so that errors.Is
+// recognizes the error the same way for every structure.
+func (err OutOfRangeError) Is(target error) bool {
+	return target == common.ErrOutOfRange
+}
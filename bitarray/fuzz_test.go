@@ -0,0 +1,165 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitarray
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/fuzz"
+)
+
+const fuzzBits = 1 << 13
+
+// fuzzArray returns one of the bitarray implementations along with
+// the offset added to every position and the first position it
+// cannot hold.  The roaring offset straddles a container boundary.
+func fuzzArray(ops *fuzz.Ops) (BitArray, uint64, uint64) {
+	switch ops.Intn(3) {
+	case 0:
+		return NewBitArray(fuzzBits), 0, fuzzBits
+	case 1:
+		return NewSparseBitArray(), 0, math.MaxUint64
+	default:
+		return NewRoaringBitArray(), 1<<16 - fuzzBits/2, math.MaxUint64
+	}
+}
+
+func fuzzShift(model *fuzz.Sorted[uint64], shift func(uint64) (uint64, bool)) *fuzz.Sorted[uint64] {
+	shifted := &fuzz.Sorted[uint64]{}
+	for _, v := range model.Values() {
+		if v, ok := shift(v); ok {
+			shifted.Insert(v)
+		}
+	}
+
+	return shifted
+}
+
+// FuzzBitArray checks each of the bitarray implementations against a
+// sorted set of positions.
+func FuzzBitArray(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 1, 0, 2, 0, 3, 1, 2, 4, 0, 7, 1})
+	f.Add([]byte{2, 3, 0, 4, 5, 0, 6, 0, 7, 0, 8, 9, 10})
+	seed := make([]byte, 512)
+	rand.New(rand.NewSource(0)).Read(seed)
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := fuzz.NewOps(data)
+		ba, offset, limit := fuzzArray(ops)
+		model := &fuzz.Sorted[uint64]{}
+		var snapshot BitArray
+		var snapshotValues []uint64
+		for op, ok := ops.Next(12); ok; op, ok = ops.Next(12) {
+			k := offset + ops.Uint64n(fuzzBits)
+			switch op {
+			case 0:
+				model.Insert(k)
+				assert.Nil(t, ba.SetBit(k))
+			case 1:
+				model.Delete(k)
+				assert.Nil(t, ba.ClearBit(k))
+			case 2:
+				set, err := ba.GetBit(k)
+				assert.Nil(t, err)
+				assert.Equal(t, model.Contains(k), set)
+			case 3, 4:
+				stop := min(k+ops.Uint64n(256), offset+fuzzBits)
+				for i := k; i < stop; i++ {
+					if op == 3 {
+						model.Insert(i)
+					} else {
+						model.Delete(i)
+					}
+				}
+				if op == 3 {
+					assert.Nil(t, ba.SetRange(k, stop))
+				} else {
+					assert.Nil(t, ba.ClearRange(k, stop))
+				}
+			case 5:
+				assert.Equal(t, uint64(model.Rank(k+1)), ba.Rank(k))
+				n := ops.Uint64n(uint64(model.Len()) + 2)
+				position, err := ba.Select(n)
+				if n > 0 && n <= uint64(model.Len()) {
+					assert.Nil(t, err)
+					assert.Equal(t, model.At(int(n-1)), position)
+				} else {
+					assert.Error(t, err)
+				}
+			case 6:
+				floor, ok := model.Floor(k)
+				prev, pok := ba.PrevSet(k)
+				assert.Equal(t, ok, pok)
+				assert.Equal(t, floor, prev)
+				ceiling, ok := model.Ceiling(k)
+				next, nok := ba.NextSet(k)
+				assert.Equal(t, ok, nok)
+				assert.Equal(t, ceiling, next)
+			case 7:
+				n := ops.Uint64n(128)
+				model = fuzzShift(model, func(v uint64) (uint64, bool) {
+					return v + n, v+n < limit
+				})
+				ba.ShiftLeft(n)
+			case 8:
+				n := ops.Uint64n(128)
+				model = fuzzShift(model, func(v uint64) (uint64, bool) {
+					return v - n, v >= n
+				})
+				ba.ShiftRight(n)
+			case 9:
+				snapshot, snapshotValues = ba.Snapshot(), slices.Clone(model.Values())
+			case 10:
+				model = &fuzz.Sorted[uint64]{}
+				ba.Reset()
+			case 11:
+				not := ba.Not(k)
+				assert.Nil(t, not.Validate())
+				assert.Equal(t, k-uint64(model.Rank(k)), not.Count())
+				i := ops.Uint64n(k + 1)
+				set, err := not.GetBit(i)
+				if err == nil {
+					assert.Equal(t, i < k && !model.Contains(i), set)
+				}
+			}
+
+			if !assert.Nil(t, ba.Validate()) || !assert.Equal(t, uint64(model.Len()), ba.Count()) {
+				return
+			}
+		}
+
+		if model.Len() > 0 {
+			assert.Equal(t, model.Values(), ba.ToNums())
+		}
+		if len(snapshotValues) > 0 {
+			assert.Nil(t, snapshot.Validate())
+			assert.Equal(t, snapshotValues, snapshot.ToNums())
+			equal := slices.Equal(snapshotValues, model.Values())
+			assert.Equal(t, equal, snapshot.Equals(ba))
+			assert.Equal(t, equal, ba.Equals(snapshot))
+			if equal {
+				assert.Equal(t, ba.Hash(), snapshot.Hash())
+			}
+		}
+	})
+}
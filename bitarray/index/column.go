@@ -0,0 +1,80 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"sort"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+)
+
+type entry struct {
+	value Value
+	rows  bitarray.BitArray
+}
+
+// column keeps one entry per distinct value, sorted by value so
+// range predicates can find their bounds by binary search.
+type column struct {
+	entries []*entry
+}
+
+// search returns the position of the first entry not less than value.
+func (c *column) search(value Value) int {
+	return sort.Search(len(c.entries), func(i int) bool {
+		return c.entries[i].value.Compare(value) >= 0
+	})
+}
+
+func (c *column) add(value Value, id uint64) {
+	i := c.search(value)
+	if i == len(c.entries) || c.entries[i].value.Compare(value) != 0 {
+		c.entries = append(c.entries, nil)
+		copy(c.entries[i+1:], c.entries[i:])
+		c.entries[i] = &entry{value: value, rows: bitarray.NewSparseBitArray()}
+	}
+
+	c.entries[i].rows.SetBit(id)
+}
+
+func (c *column) get(value Value) bitarray.BitArray {
+	i := c.search(value)
+	if i == len(c.entries) || c.entries[i].value.Compare(value) != 0 {
+		return nil
+	}
+
+	return c.entries[i].rows
+}
+
+// between returns the union of the rows for every value in
+// [start, stop).  Either bound may be nil to leave it open.
+func (c *column) between(start, stop Value) bitarray.BitArray {
+	i, j := 0, len(c.entries)
+	if start != nil {
+		i = c.search(start)
+	}
+	if stop != nil {
+		j = c.search(stop)
+	}
+
+	result := bitarray.NewSparseBitArray()
+	for ; i < j; i++ {
+		result = result.Or(c.entries[i].rows)
+	}
+
+	return result
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package index implements a bitmap index over rows of attributes.  Each
+attribute is stored as a column mapping every distinct value to a
+compressed bit array of the row IDs holding that value.  Rows are
+appended incrementally and assigned IDs in order, starting from 0.
+
+Queries are built from equality and range predicates on columns and
+combined with And, Or and Not.  Each predicate evaluates to a bit array
+of matching row IDs, so combining predicates is a bitwise operation
+and never touches the rows themselves.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(v * r / 64) worst case for v distinct values over r rows,
+typically far less as sparse bit arrays only store set blocks
+Append: O(a log v) for a attributes
+Eq: O(log v)
+Range: O(log v + m) where m is the number of matching values
+*/
+package index
+
+import "github.com/Workiva/go-datastructures/bitarray"
+
+// Index is a bitmap index over appended rows.
+type Index struct {
+	columns map[string]*column
+	rows    bitarray.BitArray
+	len     uint64
+}
+
+// Append adds a row with the provided attribute values to the index
+// and returns the row's ID.  Attributes may differ between rows; a
+// row without a given attribute matches no predicate on that column.
+func (ix *Index) Append(attributes map[string]Value) uint64 {
+	id := ix.len
+	for name, value := range attributes {
+		col, ok := ix.columns[name]
+		if !ok {
+			col = &column{}
+			ix.columns[name] = col
+		}
+		col.add(value, id)
+	}
+
+	ix.rows.SetBit(id)
+	ix.len++
+	return id
+}
+
+// Len returns the number of rows in the index.
+func (ix *Index) Len() uint64 {
+	return ix.len
+}
+
+// Cardinality returns the number of distinct values in the named
+// column, or 0 if no row has that attribute.
+func (ix *Index) Cardinality(name string) int {
+	col, ok := ix.columns[name]
+	if !ok {
+		return 0
+	}
+
+	return len(col.entries)
+}
+
+// Query returns a bit array with a bit set for the ID of every row
+// matching the provided predicate.  The result is owned by the
+// caller and may be modified freely.
+func (ix *Index) Query(p Predicate) bitarray.BitArray {
+	return p.eval(ix).Snapshot()
+}
+
+// Rows returns the IDs of every row matching the provided predicate
+// in ascending order.
+func (ix *Index) Rows(p Predicate) []uint64 {
+	return p.eval(ix).ToNums()
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{
+		columns: make(map[string]*column),
+		rows:    bitarray.NewSparseBitArray(),
+	}
+}
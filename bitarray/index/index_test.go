@@ -0,0 +1,149 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() *Index {
+	ix := New()
+	rows := []map[string]Value{
+		{`color`: String(`red`), `size`: Int(3)},
+		{`color`: String(`blue`), `size`: Int(7)},
+		{`color`: String(`red`), `size`: Int(10)},
+		{`color`: String(`green`)},
+		{`size`: Int(7)},
+		{`color`: String(`blue`), `size`: Int(1)},
+	}
+	for _, row := range rows {
+		ix.Append(row)
+	}
+
+	return ix
+}
+
+func TestAppend(t *testing.T) {
+	ix := New()
+	assert.Equal(t, uint64(0), ix.Len())
+
+	assert.Equal(t, uint64(0), ix.Append(map[string]Value{`a`: Int(1)}))
+	assert.Equal(t, uint64(1), ix.Append(nil))
+	assert.Equal(t, uint64(2), ix.Append(map[string]Value{`a`: Int(1)}))
+	assert.Equal(t, uint64(3), ix.Len())
+	assert.Equal(t, 1, ix.Cardinality(`a`))
+	assert.Equal(t, 0, ix.Cardinality(`b`))
+}
+
+func TestEq(t *testing.T) {
+	ix := newTestIndex()
+
+	assert.Equal(t, []uint64{0, 2}, ix.Rows(Eq(`color`, String(`red`))))
+	assert.Equal(t, []uint64{1, 4}, ix.Rows(Eq(`size`, Int(7))))
+	assert.Len(t, ix.Rows(Eq(`color`, String(`pink`))), 0)
+	assert.Len(t, ix.Rows(Eq(`shape`, String(`round`))), 0)
+	assert.Equal(t, 3, ix.Cardinality(`color`))
+}
+
+func TestRange(t *testing.T) {
+	ix := newTestIndex()
+
+	assert.Equal(t, []uint64{0, 1, 4}, ix.Rows(Range(`size`, Int(3), Int(10))))
+	assert.Equal(t, []uint64{0, 1, 4, 5}, ix.Rows(Range(`size`, nil, Int(8))))
+	assert.Equal(t, []uint64{1, 2, 4}, ix.Rows(Range(`size`, Int(4), nil)))
+	assert.Equal(t, []uint64{0, 1, 2, 4, 5}, ix.Rows(Range(`size`, nil, nil)))
+	assert.Len(t, ix.Rows(Range(`size`, Int(11), nil)), 0)
+	assert.Equal(t, []uint64{1, 3, 5}, ix.Rows(Range(`color`, String(`b`), String(`h`))))
+	assert.Len(t, ix.Rows(Range(`shape`, nil, nil)), 0)
+}
+
+func TestCombinators(t *testing.T) {
+	ix := newTestIndex()
+
+	assert.Equal(t, []uint64{1}, ix.Rows(And(
+		Eq(`color`, String(`blue`)), Range(`size`, Int(5), nil),
+	)))
+	assert.Equal(t, []uint64{0, 2, 3}, ix.Rows(Or(
+		Eq(`color`, String(`red`)), Eq(`color`, String(`green`)),
+	)))
+	// rows without a color are included by Not
+	assert.Equal(t, []uint64{1, 3, 4, 5}, ix.Rows(Not(Eq(`color`, String(`red`)))))
+	assert.Equal(t, []uint64{4}, ix.Rows(And(
+		Not(Range(`color`, nil, nil)), Eq(`size`, Int(7)),
+	)))
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4, 5}, ix.Rows(And()))
+	assert.Len(t, ix.Rows(Or()), 0)
+	assert.Len(t, ix.Rows(Not(And())), 0)
+}
+
+func TestQueryIsIndependent(t *testing.T) {
+	ix := newTestIndex()
+
+	result := ix.Query(Eq(`color`, String(`red`)))
+	result.SetBit(1)
+	ix.Append(map[string]Value{`color`: String(`red`)})
+
+	assert.Equal(t, []uint64{0, 1, 2}, result.ToNums())
+	assert.Equal(t, []uint64{0, 2, 6}, ix.Rows(Eq(`color`, String(`red`))))
+}
+
+func TestRandom(t *testing.T) {
+	ix := New()
+	values := make([]int64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		value := rand.Int63n(50)
+		values = append(values, value)
+		ix.Append(map[string]Value{`value`: Int(value), `even`: Int(value % 2)})
+	}
+
+	p := And(Range(`value`, Int(10), Int(30)), Not(Eq(`even`, Int(0))))
+	expected := []uint64{}
+	for i, value := range values {
+		if value >= 10 && value < 30 && value%2 == 1 {
+			expected = append(expected, uint64(i))
+		}
+	}
+
+	assert.Equal(t, expected, ix.Rows(p))
+}
+
+func BenchmarkAppend(b *testing.B) {
+	ix := New()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ix.Append(map[string]Value{`value`: Int(i % 100)})
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	ix := New()
+	for i := 0; i < 100000; i++ {
+		ix.Append(map[string]Value{`value`: Int(i % 100), `even`: Int(i % 2)})
+	}
+	p := And(Range(`value`, Int(10), Int(30)), Not(Eq(`even`, Int(0))))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ix.Query(p)
+	}
+}
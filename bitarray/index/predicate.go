@@ -0,0 +1,123 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import "github.com/Workiva/go-datastructures/bitarray"
+
+// Predicate is a condition on rows of an index.  Predicates are
+// created with Eq and Range and combined with And, Or and Not.
+type Predicate interface {
+	// eval returns the rows of the index matching this predicate.
+	// The result may be shared with the index and must not be
+	// modified.
+	eval(ix *Index) bitarray.BitArray
+}
+
+type eq struct {
+	name  string
+	value Value
+}
+
+func (p eq) eval(ix *Index) bitarray.BitArray {
+	col, ok := ix.columns[p.name]
+	if !ok {
+		return bitarray.NewSparseBitArray()
+	}
+
+	if rows := col.get(p.value); rows != nil {
+		return rows
+	}
+
+	return bitarray.NewSparseBitArray()
+}
+
+// Eq matches rows whose named attribute is equal to value.
+func Eq(name string, value Value) Predicate {
+	return eq{name: name, value: value}
+}
+
+type between struct {
+	name        string
+	start, stop Value
+}
+
+func (p between) eval(ix *Index) bitarray.BitArray {
+	col, ok := ix.columns[p.name]
+	if !ok {
+		return bitarray.NewSparseBitArray()
+	}
+
+	return col.between(p.start, p.stop)
+}
+
+// Range matches rows whose named attribute is in [start, stop).  A
+// nil start or stop leaves that end of the range unbounded.
+func Range(name string, start, stop Value) Predicate {
+	return between{name: name, start: start, stop: stop}
+}
+
+type and []Predicate
+
+func (p and) eval(ix *Index) bitarray.BitArray {
+	if len(p) == 0 {
+		return ix.rows
+	}
+
+	result := p[0].eval(ix)
+	for _, pred := range p[1:] {
+		result = result.And(pred.eval(ix))
+	}
+
+	return result
+}
+
+// And matches rows matching every provided predicate.  With no
+// predicates, every row matches.
+func And(predicates ...Predicate) Predicate {
+	return and(predicates)
+}
+
+type or []Predicate
+
+func (p or) eval(ix *Index) bitarray.BitArray {
+	result := bitarray.NewSparseBitArray()
+	for _, pred := range p {
+		result = result.Or(pred.eval(ix))
+	}
+
+	return result
+}
+
+// Or matches rows matching any of the provided predicates.  With no
+// predicates, no row matches.
+func Or(predicates ...Predicate) Predicate {
+	return or(predicates)
+}
+
+type not struct {
+	predicate Predicate
+}
+
+func (p not) eval(ix *Index) bitarray.BitArray {
+	return ix.rows.AndNot(p.predicate.eval(ix))
+}
+
+// Not matches rows not matching the provided predicate.  This
+// includes rows without the attributes the predicate refers to.
+func Not(predicate Predicate) Predicate {
+	return not{predicate: predicate}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+// Value defines attribute values that can be indexed.  Values within
+// a column must be of the same type.
+type Value interface {
+	// Compare this value to the provided value.  Return a positive
+	// number if this value is greater than, 0 if equal, negative
+	// number if less than.
+	Compare(Value) int
+}
+
+// Int is an integer attribute value.
+type Int int64
+
+// Compare implements Value.
+func (i Int) Compare(other Value) int {
+	o := other.(Int)
+	switch {
+	case i < o:
+		return -1
+	case i > o:
+		return 1
+	}
+
+	return 0
+}
+
+// String is a string attribute value.
+type String string
+
+// Compare implements Value.
+func (s String) Compare(other Value) int {
+	o := other.(String)
+	switch {
+	case s < o:
+		return -1
+	case s > o:
+		return 1
+	}
+
+	return 0
+}
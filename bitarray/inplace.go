@@ -0,0 +1,222 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+// maxIndex is the index of the block holding the highest possible
+// position.
+const maxIndex = ^uint64(0) / s
+
+// rangeMask returns a block with the positions in [start, stop) set.
+// stop must be greater than start and at most s.
+func rangeMask(start, stop uint64) block {
+	return maximumBlock << start & (maximumBlock >> (s - stop))
+}
+
+// forRange calls fn with the index of every block overlapping
+// [start, stop) and a mask of the positions in that block which fall
+// within the range.
+func forRange(start, stop uint64, fn func(index uint64, mask block)) {
+	if start >= stop {
+		return
+	}
+
+	first, _ := getIndexAndRemainder(start)
+	last, _ := getIndexAndRemainder(stop - 1)
+	for i := first; i <= last; i++ {
+		lo, hi := uint64(0), s
+		if i == first {
+			lo = start % s
+		}
+		if i == last {
+			hi = (stop-1)%s + 1
+		}
+
+		fn(i, rangeMask(lo, hi))
+	}
+}
+
+// rangeBlocks returns the indices and blocks of a sparse bit array
+// with the positions in [start, stop) set.
+func rangeBlocks(start, stop uint64) (uintSlice, blocks) {
+	var indices uintSlice
+	var blocks blocks
+	forRange(start, stop, func(index uint64, mask block) {
+		indices = append(indices, index)
+		blocks = append(blocks, mask)
+	})
+
+	return indices, blocks
+}
+
+// sparseBlocks returns the indices and non-empty blocks of the
+// provided bit array, which are shared with it if it is sparse.
+func sparseBlocks(ba BitArray) (uintSlice, blocks) {
+	if sba, ok := ba.(*sparseBitArray); ok {
+		return sba.indices, sba.blocks
+	}
+
+	var indices uintSlice
+	var blocks blocks
+	for iter := ba.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		if block != 0 {
+			indices = append(indices, index)
+			blocks = append(blocks, block)
+		}
+	}
+
+	return indices, blocks
+}
+
+// shiftBlocks returns the indices and non-empty blocks produced by
+// moving every bit from the provided iterator n positions higher, if
+// left is true, or lower.  Bits moved past either end are dropped.
+func shiftBlocks(iter Iterator, n uint64, left bool) (uintSlice, blocks) {
+	q, r := getIndexAndRemainder(n)
+	var indices uintSlice
+	var blocks blocks
+	add := func(index uint64, b block) {
+		if b == 0 {
+			return
+		}
+
+		if last := len(indices) - 1; last >= 0 && indices[last] == index {
+			blocks[last] |= b
+			return
+		}
+
+		indices = append(indices, index)
+		blocks = append(blocks, b)
+	}
+
+	for iter.Next() {
+		index, b := iter.Value()
+		if left {
+			if q > maxIndex-index {
+				break
+			}
+
+			add(index+q, b<<r)
+			if r > 0 && index+q < maxIndex {
+				add(index+q+1, b>>(s-r))
+			}
+			continue
+		}
+
+		if r > 0 && index > q {
+			add(index-q-1, b<<(s-r))
+		}
+		if index >= q {
+			add(index-q, b>>r)
+		}
+	}
+
+	return indices, blocks
+}
+
+// merge combines the provided indices and blocks into this sparse bit
+// array with op, which must map an empty block to the other block.
+// The merge runs from the back so existing storage is reused.
+func (sba *sparseBitArray) merge(indices uintSlice, others blocks, op func(a, b block) block) {
+	sba.unshare()
+
+	missing := 0
+	for i, j := 0, 0; j < len(indices); j++ {
+		for i < len(sba.indices) && sba.indices[i] < indices[j] {
+			i++
+		}
+
+		if i == len(sba.indices) || sba.indices[i] != indices[j] {
+			missing++
+		}
+	}
+
+	i := len(sba.indices) - 1
+	for ; missing > 0; missing-- {
+		sba.indices = append(sba.indices, 0)
+		sba.blocks = append(sba.blocks, 0)
+	}
+
+	j := len(indices) - 1
+	for k := len(sba.indices) - 1; j >= 0; k-- {
+		switch {
+		case i >= 0 && sba.indices[i] > indices[j]:
+			sba.indices[k], sba.blocks[k] = sba.indices[i], sba.blocks[i]
+			i--
+		case i >= 0 && sba.indices[i] == indices[j]:
+			sba.indices[k], sba.blocks[k] = indices[j], op(sba.blocks[i], others[j])
+			i--
+			j--
+		default:
+			sba.indices[k], sba.blocks[k] = indices[j], op(0, others[j])
+			j--
+		}
+	}
+
+	sba.compact()
+}
+
+// intersect applies op to every block of this sparse bit array, with
+// the matching block from the provided indices and blocks or an empty
+// block if there is none.
+func (sba *sparseBitArray) intersect(indices uintSlice, others blocks, op func(a, b block) block) {
+	sba.unshare()
+
+	j := 0
+	for i, index := range sba.indices {
+		for j < len(indices) && indices[j] < index {
+			j++
+		}
+
+		other := block(0)
+		if j < len(indices) && indices[j] == index {
+			other = others[j]
+		}
+		sba.blocks[i] = op(sba.blocks[i], other)
+	}
+
+	sba.compact()
+}
+
+// compact removes empty blocks from this sparse bit array in place.
+func (sba *sparseBitArray) compact() {
+	k := 0
+	for i, block := range sba.blocks {
+		if block != 0 {
+			sba.indices[k], sba.blocks[k] = sba.indices[i], block
+			k++
+		}
+	}
+
+	sba.indices = sba.indices[:k]
+	sba.blocks = sba.blocks[:k]
+}
+
+// grow extends this bit array to hold at least the given number of
+// blocks.
+func (ba *bitArray) grow(numBlocks uint64) {
+	if numBlocks > uint64(len(ba.blocks)) {
+		ba.blocks = append(ba.blocks, make([]block, numBlocks-uint64(len(ba.blocks)))...)
+	}
+}
+
+// resetBounds recalculates the lowest and highest set bits.
+func (ba *bitArray) resetBounds() {
+	ba.setLowest()
+	if ba.anyset {
+		ba.setHighest()
+	}
+}
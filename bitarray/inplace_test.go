@@ -0,0 +1,201 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// inPlaceTestArrays returns a dense, sparse and roaring bit array each
+// holding the same randomly chosen bits below size.
+func inPlaceTestArrays(r *rand.Rand, size uint64, n int) []BitArray {
+	arrays := []BitArray{newBitArray(size), newSparseBitArray(), newRoaringBitArray()}
+	for i := 0; i < n; i++ {
+		k := uint64(r.Int63n(int64(size)))
+		if r.Intn(2) == 0 {
+			// clustered bits so roaring uses bitmaps and runs
+			k = size/2 + uint64(i)
+		}
+
+		for _, ba := range arrays {
+			ba.SetBit(k)
+		}
+	}
+
+	return arrays
+}
+
+func TestInPlaceOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	size := uint64(1 << 18)
+	for _, ba := range inPlaceTestArrays(r, size, 20000) {
+		for _, other := range inPlaceTestArrays(r, size, 20000) {
+			for _, test := range []struct {
+				inPlace  func(BitArray)
+				expected BitArray
+			}{
+				{ba.OrInPlace, ba.Or(other)},
+				{ba.AndInPlace, ba.And(other)},
+				{ba.AndNotInPlace, ba.AndNot(other)},
+				{ba.XorInPlace, ba.Xor(other)},
+			} {
+				snapshot := ba.Snapshot()
+				original := ba.ToNums()
+				test.inPlace(other)
+
+				assert.Equal(t, test.expected.ToNums(), ba.ToNums())
+				assert.Nil(t, ba.Validate())
+				assert.Equal(t, original, snapshot.ToNums())
+
+				// restore the original bits for the next operation
+				ba.XorInPlace(ba)
+				ba.OrInPlace(snapshot)
+				assert.Equal(t, original, ba.ToNums())
+			}
+		}
+	}
+}
+
+func TestInPlaceWithSelf(t *testing.T) {
+	for _, ba := range []BitArray{newBitArray(200), newSparseBitArray(), newRoaringBitArray()} {
+		ba.SetBit(3)
+		ba.SetBit(150)
+
+		ba.OrInPlace(ba)
+		ba.AndInPlace(ba)
+		assert.Equal(t, []uint64{3, 150}, ba.ToNums())
+		ba.AndNotInPlace(ba)
+		assert.Equal(t, uint64(0), ba.Count())
+		ba.SetBit(3)
+		ba.XorInPlace(ba)
+		assert.Equal(t, uint64(0), ba.Count())
+		assert.Nil(t, ba.Validate())
+	}
+}
+
+func TestDenseInPlaceGrows(t *testing.T) {
+	ba := newBitArray(64)
+	other := newSparseBitArray()
+	other.SetBit(1000)
+
+	ba.OrInPlace(other)
+	assert.Equal(t, []uint64{1000}, ba.ToNums())
+	assert.True(t, ba.Capacity() > 1000)
+
+	ba.XorInPlace(other)
+	assert.Equal(t, uint64(0), ba.Count())
+	assert.Nil(t, ba.Validate())
+}
+
+func TestRanges(t *testing.T) {
+	ranges := [][2]uint64{{0, 1}, {5, 5}, {10, 64}, {63, 65}, {100, 1000},
+		{65530, 65540}, {1 << 16, 3 << 16}, {199990, 200000}}
+	for _, ba := range []BitArray{newBitArray(200000), newSparseBitArray(), newRoaringBitArray()} {
+		model := make(map[uint64]bool)
+		for _, rng := range ranges {
+			assert.Nil(t, ba.SetRange(rng[0], rng[1]))
+			for k := rng[0]; k < rng[1]; k++ {
+				model[k] = true
+			}
+		}
+		assert.Equal(t, sortedNums(model), ba.ToNums())
+		assert.Nil(t, ba.Validate())
+
+		for _, rng := range [][2]uint64{{0, 11}, {500, 70000}, {199999, 200000}} {
+			assert.Nil(t, ba.ClearRange(rng[0], rng[1]))
+			for k := rng[0]; k < rng[1]; k++ {
+				delete(model, k)
+			}
+		}
+		assert.Equal(t, sortedNums(model), ba.ToNums())
+		assert.Nil(t, ba.Validate())
+	}
+}
+
+func TestDenseRangeOutOfRange(t *testing.T) {
+	ba := newBitArray(100)
+	assert.Equal(t, OutOfRangeError(128), ba.SetRange(10, 129))
+	assert.Equal(t, OutOfRangeError(128), ba.ClearRange(10, 129))
+	assert.Nil(t, ba.SetRange(10, 128))
+	assert.Equal(t, uint64(10), ba.lowest)
+	assert.Equal(t, uint64(127), ba.highest)
+}
+
+func shiftModel(nums []uint64, n uint64, left bool, limit uint64) []uint64 {
+	model := make(map[uint64]bool)
+	for _, k := range nums {
+		switch {
+		case left && k+n < limit && k+n >= k:
+			model[k+n] = true
+		case !left && k >= n:
+			model[k-n] = true
+		}
+	}
+
+	return sortedNums(model)
+}
+
+func TestShift(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	size := uint64(1 << 18)
+	for _, n := range []uint64{0, 1, 63, 64, 65, 1000, 1 << 16, 3 << 16, 1<<17 + 5, size} {
+		for _, ba := range inPlaceTestArrays(r, size, 5000) {
+			limit := ^uint64(0)
+			if _, ok := ba.(*bitArray); ok {
+				limit = size
+			}
+
+			nums := ba.ToNums()
+			ba.ShiftLeft(n)
+			expected := shiftModel(nums, n, true, limit)
+			assert.Equal(t, expected, ba.ToNums(), "ShiftLeft(%d) %T", n, ba)
+			assert.Nil(t, ba.Validate())
+
+			ba.ShiftRight(n)
+			expected = shiftModel(expected, n, false, limit)
+			assert.Equal(t, expected, ba.ToNums(), "ShiftRight(%d) %T", n, ba)
+			assert.Nil(t, ba.Validate())
+		}
+	}
+}
+
+func TestShiftPastEnd(t *testing.T) {
+	for _, ba := range []BitArray{newSparseBitArray(), newRoaringBitArray()} {
+		ba.SetBit(^uint64(0) - 1)
+		ba.SetBit(5)
+		ba.ShiftLeft(1)
+		assert.Equal(t, []uint64{6, ^uint64(0)}, ba.ToNums())
+		ba.ShiftLeft(1 << 16)
+		assert.Equal(t, []uint64{1<<16 + 6}, ba.ToNums())
+		ba.ShiftRight(1<<16 + 7)
+		assert.Equal(t, uint64(0), ba.Count())
+	}
+}
+
+func BenchmarkOrInPlace(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	rba, sba, _ := randomBitArrays(r, 100000)
+	other, otherSparse, _ := randomBitArrays(r, 100000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rba.OrInPlace(other)
+		sba.OrInPlace(otherSparse)
+	}
+}
@@ -25,6 +25,11 @@ to get trailing zeroes, the reconversion back into integers should be much faste
 
 package bitarray
 
+import (
+	"io"
+	"iter"
+)
+
 // BitArray represents a structure that can be used to
 // quickly check for existence when using a large number
 // of items in a very memory efficient way.
@@ -46,9 +51,15 @@ type BitArray interface {
 	// Blocks returns an iterator to be used to iterate
 	// over the bit array.
 	Blocks() Iterator
-	// Equals returns a bool indicating equality between the
-	// two bit arrays.
+	// Equals returns a bool indicating if the two bit arrays have
+	// the same bits set, whatever their kinds.  Blocks are compared
+	// a word at a time.
 	Equals(other BitArray) bool
+	// Hash returns a hash of the set bits.  Bit arrays that are
+	// Equal have the same hash whatever their kinds, and the hash
+	// is stable across processes, so it may be stored and used to
+	// bucket bit arrays before comparing them with Equals.
+	Hash() uint64
 	// Intersects returns a bool indicating if the other bit
 	// array intersects with this bit array.
 	Intersects(other BitArray) bool
@@ -62,9 +73,91 @@ type BitArray interface {
 	// And will bitwise and the two bitarrays and return a new bitarray
 	// representing the result.
 	And(other BitArray) BitArray
+	// AndNot will clear every bit in this bitarray that is set in
+	// the other and return a new bitarray representing the result.
+	AndNot(other BitArray) BitArray
+	// Xor will bitwise xor the two bitarrays and return a new bitarray
+	// representing the result.
+	Xor(other BitArray) BitArray
+	// Not returns a new bitarray with every bit in [0, max) that is
+	// clear in this bitarray set, which is the complement within a
+	// universe of max positions.  A sparse bitarray has no universe
+	// of its own, hence the bound.  The result is the same kind as
+	// this bitarray, so the complement of a sparse bitarray is only
+	// small if few blocks below max are full; a roaring bitarray
+	// stores long runs compactly.  To remove one set from another
+	// use AndNot, which never materializes a complement.
+	Not(max uint64) BitArray
+	// Count returns the number of set bits.
+	Count() uint64
+	// OrInPlace will bitwise or the other bitarray into this one,
+	// reusing this bitarray's storage where possible.  A dense
+	// bitarray grows to hold the other's bits.
+	OrInPlace(other BitArray)
+	// AndInPlace will bitwise and the other bitarray into this one,
+	// reusing this bitarray's storage where possible.
+	AndInPlace(other BitArray)
+	// AndNotInPlace will clear every bit in this bitarray that is
+	// set in the other, reusing this bitarray's storage where
+	// possible.
+	AndNotInPlace(other BitArray)
+	// XorInPlace will bitwise xor the other bitarray into this one,
+	// reusing this bitarray's storage where possible.  A dense
+	// bitarray grows to hold the other's bits.
+	XorInPlace(other BitArray)
+	// SetRange sets every bit in [start, stop).  This function
+	// returns an error if stop is past the end of a dense bitarray.
+	SetRange(start, stop uint64) error
+	// ClearRange clears every bit in [start, stop).  This function
+	// returns an error if stop is past the end of a dense bitarray.
+	ClearRange(start, stop uint64) error
+	// ShiftLeft moves every bit n positions higher.  Bits moved
+	// past the end of a dense bitarray or past the highest
+	// possible position are dropped.
+	ShiftLeft(n uint64)
+	// ShiftRight moves every bit n positions lower.  Bits moved
+	// below zero are dropped.
+	ShiftRight(n uint64)
 	// ToNums converts this bit array to the list of numbers contained
 	// within it.
 	ToNums() []uint64
+	// Snapshot returns a point-in-time copy of this bit array.  The
+	// copy shares storage with this bit array until either is
+	// modified, so taking a snapshot is an O(1) operation.
+	Snapshot() BitArray
+	// Validate checks the internal bookkeeping of this bit array
+	// and returns an error describing the first inconsistency
+	// found.  This is intended for tests and fuzzing.
+	Validate() error
+	// SizeOf returns an estimate of the number of bytes used
+	// by this bit array.
+	SizeOf() uint64
+	// Rank returns the number of set bits at or before the
+	// given position.
+	Rank(k uint64) uint64
+	// Select returns the position of the n-th set bit, counting
+	// from one, so Select(Rank(k)) == k for any set bit k.  An
+	// error is returned if fewer than n bits are set.  Use a
+	// RankIndex when many queries are made against the same bits.
+	Select(n uint64) (uint64, error)
+	// NextSet returns the position of the first set bit at or
+	// after the given position and a bool indicating if there
+	// is one.
+	NextSet(k uint64) (uint64, bool)
+	// PrevSet returns the position of the last set bit at or
+	// before the given position and a bool indicating if there
+	// is one.
+	PrevSet(k uint64) (uint64, bool)
+	// Iterate calls fn with the position of every set bit in
+	// ascending order until fn returns false.
+	Iterate(fn func(uint64) bool)
+	// All returns a sequence of the position of every set bit in
+	// ascending order for use with range.
+	All() iter.Seq[uint64]
+	// Serialize writes this bit array to the provided writer in
+	// a stable format that can be read back with Deserialize,
+	// FromBytes or FromMmap.
+	Serialize(w io.Writer) error
 }
 
 // Iterator defines methods used to iterate over a bit array.
@@ -16,6 +16,20 @@ limitations under the License.
 
 package bitarray
 
+// iterate calls fn with the position of every bit set in the blocks
+// produced by the provided iterator until fn returns false.
+func iterate(iter Iterator, fn func(uint64) bool) {
+	for iter.Next() {
+		index, block := iter.Value()
+		for ; block != 0; block &= block - 1 {
+			pos, _ := block.nextSet(0)
+			if !fn(index*s + pos) {
+				return
+			}
+		}
+	}
+}
+
 type sparseBitArrayIterator struct {
 	index int64
 	sba   *sparseBitArray
@@ -50,7 +64,8 @@ type bitArrayIterator struct {
 // items exist.
 func (iter *bitArrayIterator) Next() bool {
 	iter.index++
-	return uint64(iter.index) <= iter.stopIndex
+	// a bit array of no capacity has no block to stop at
+	return uint64(iter.index) <= iter.stopIndex && iter.index < int64(len(iter.ba.blocks))
 }
 
 // Value returns an index and the block at this index.
@@ -67,3 +82,44 @@ func newBitArrayIterator(ba *bitArray) *bitArrayIterator {
 		stopIndex: stop,
 	}
 }
+
+type roaringBitArrayIterator struct {
+	rba    *roaringBitArray
+	index  int
+	blocks []block
+	block  int
+}
+
+// Next moves to the next non-empty block and returns a bool
+// indicating if one exists.
+func (iter *roaringBitArrayIterator) Next() bool {
+	for {
+		iter.block++
+		for iter.block >= len(iter.blocks) {
+			iter.index++
+			if iter.index >= len(iter.rba.containers) {
+				return false
+			}
+
+			iter.blocks = iter.rba.containers[iter.index].asBlocks()
+			iter.block = 0
+		}
+
+		if iter.blocks[iter.block] != 0 {
+			return true
+		}
+	}
+}
+
+// Value returns the index and block at the current position.
+func (iter *roaringBitArrayIterator) Value() (uint64, block) {
+	index := iter.rba.keys[iter.index]*containerBlocks + uint64(iter.block)
+	return index, iter.blocks[iter.block]
+}
+
+func newRoaringBitArrayIterator(rba *roaringBitArray) *roaringBitArrayIterator {
+	return &roaringBitArrayIterator{
+		rba:   rba,
+		index: -1,
+	}
+}
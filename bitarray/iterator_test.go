@@ -0,0 +1,124 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkNextAndPrevSet compares NextSet, PrevSet and Iterate against the
+// positions reported by ToNums.
+func checkNextAndPrevSet(t *testing.T, ba BitArray, probes []uint64) {
+	nums := ba.ToNums()
+
+	var iterated []uint64
+	ba.Iterate(func(k uint64) bool {
+		iterated = append(iterated, k)
+		return true
+	})
+	if len(nums) == 0 {
+		assert.Len(t, iterated, 0)
+	} else {
+		assert.Equal(t, nums, iterated)
+	}
+
+	for _, k := range probes {
+		next, hasNext := uint64(0), false
+		prev, hasPrev := uint64(0), false
+		for _, num := range nums {
+			if num >= k && !hasNext {
+				next, hasNext = num, true
+			}
+			if num <= k {
+				prev, hasPrev = num, true
+			}
+		}
+
+		result, ok := ba.NextSet(k)
+		assert.Equal(t, hasNext, ok, "NextSet(%d)", k)
+		assert.Equal(t, next, result, "NextSet(%d)", k)
+		result, ok = ba.PrevSet(k)
+		assert.Equal(t, hasPrev, ok, "PrevSet(%d)", k)
+		assert.Equal(t, prev, result, "PrevSet(%d)", k)
+	}
+}
+
+func TestNextAndPrevSet(t *testing.T) {
+	positions := []uint64{3, 63, 64, 200, 4000, 4001, 4002, 9000}
+	probes := []uint64{0, 3, 4, 62, 63, 64, 65, 199, 201, 4001, 5000, 9000, 9999}
+	arrays := []BitArray{NewBitArray(10000), NewSparseBitArray(), NewRoaringBitArray()}
+	for _, ba := range arrays {
+		checkNextAndPrevSet(t, ba, probes)
+		for _, k := range positions {
+			ba.SetBit(k)
+		}
+		checkNextAndPrevSet(t, ba, probes)
+	}
+}
+
+func TestNextAndPrevSetRoaringContainers(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	rba, sba, _ := randomBitArrays(r, 50000)
+	probes := []uint64{0, 1 << 16, 1<<17 - 1, 1 << 17, 1<<17 + 777, 3 << 16,
+		3<<16 + 19999, 3<<16 + 20000, 1 << 21, 1<<22 + 1}
+	for i := 0; i < 200; i++ {
+		probes = append(probes, uint64(r.Int63n(1<<22)))
+	}
+
+	checkNextAndPrevSet(t, rba, probes)
+	checkNextAndPrevSet(t, sba, probes)
+}
+
+func TestIterateStops(t *testing.T) {
+	for _, ba := range []BitArray{NewBitArray(100), NewSparseBitArray(), NewRoaringBitArray()} {
+		ba.SetBit(1)
+		ba.SetBit(50)
+		ba.SetBit(99)
+
+		var iterated []uint64
+		ba.Iterate(func(k uint64) bool {
+			iterated = append(iterated, k)
+			return k < 50
+		})
+		assert.Equal(t, []uint64{1, 50}, iterated)
+	}
+}
+
+func TestAll(t *testing.T) {
+	for _, ba := range []BitArray{NewBitArray(100), NewSparseBitArray(), NewRoaringBitArray()} {
+		ba.SetBit(1)
+		ba.SetBit(50)
+		ba.SetBit(99)
+
+		var iterated []uint64
+		for k := range ba.All() {
+			iterated = append(iterated, k)
+		}
+		assert.Equal(t, ba.ToNums(), iterated)
+
+		iterated = iterated[:0]
+		for k := range ba.All() {
+			if k > 50 {
+				break
+			}
+			iterated = append(iterated, k)
+		}
+		assert.Equal(t, []uint64{1, 50}, iterated)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import "sort"
+
+// rank returns the number of set bits at or before position k in
+// the blocks produced by the provided iterator.
+func rank(iter Iterator, k uint64) uint64 {
+	i, position := getIndexAndRemainder(k)
+	count := uint64(0)
+	for iter.Next() {
+		index, block := iter.Value()
+		if index > i {
+			break
+		}
+
+		if index == i {
+			count += block.rank(position)
+			break
+		}
+
+		count += block.count()
+	}
+
+	return count
+}
+
+// selectBit returns the position of the n-th set bit, counting from
+// one, in the blocks produced by the provided iterator.
+func selectBit(iter Iterator, n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, OutOfRangeError(n)
+	}
+
+	remaining := n
+	for iter.Next() {
+		index, block := iter.Value()
+		count := block.count()
+		if remaining <= count {
+			return index*s + block.selectBit(remaining), nil
+		}
+
+		remaining -= count
+	}
+
+	return 0, OutOfRangeError(n)
+}
+
+// RankIndex is a precomputed index of the set bits in a bit array.
+// Rank and Select on a bit array scan its blocks, which is linear
+// in its size; a RankIndex answers both in time logarithmic in the
+// number of non-empty blocks.  The index reflects the bit array at
+// the time it was built and is not updated by later writes.
+type RankIndex struct {
+	indices []uint64
+	blocks  []block
+	// ranks[i] is the number of set bits in blocks[:i].
+	ranks []uint64
+}
+
+// Rank returns the number of set bits at or before position k.
+func (ri *RankIndex) Rank(k uint64) uint64 {
+	i, position := getIndexAndRemainder(k)
+	j := sort.Search(len(ri.indices), func(j int) bool {
+		return ri.indices[j] >= i
+	})
+
+	count := ri.ranks[j]
+	if j < len(ri.indices) && ri.indices[j] == i {
+		count += ri.blocks[j].rank(position)
+	}
+
+	return count
+}
+
+// Select returns the position of the n-th set bit, counting from
+// one.  An error is returned if fewer than n bits are set.
+func (ri *RankIndex) Select(n uint64) (uint64, error) {
+	if n == 0 || n > ri.Count() {
+		return 0, OutOfRangeError(n)
+	}
+
+	j := sort.Search(len(ri.blocks), func(j int) bool {
+		return ri.ranks[j+1] >= n
+	})
+
+	return ri.indices[j]*s + ri.blocks[j].selectBit(n-ri.ranks[j]), nil
+}
+
+// Count returns the total number of set bits.
+func (ri *RankIndex) Count() uint64 {
+	return ri.ranks[len(ri.ranks)-1]
+}
+
+// NewRankIndex builds a rank index over the set bits of the provided
+// bit array.
+func NewRankIndex(ba BitArray) *RankIndex {
+	ri := &RankIndex{ranks: []uint64{0}}
+	iter := ba.Blocks()
+	for iter.Next() {
+		index, block := iter.Value()
+		if block == 0 {
+			continue
+		}
+
+		ri.indices = append(ri.indices, index)
+		ri.blocks = append(ri.blocks, block)
+		ri.ranks = append(ri.ranks, ri.Count()+block.count())
+	}
+
+	return ri
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checkRankAndSelect(t *testing.T, ba BitArray, max uint64) {
+	nums := ba.ToNums()
+	ri := NewRankIndex(ba)
+	assert.Equal(t, uint64(len(nums)), ri.Count())
+
+	count := uint64(0)
+	for k := uint64(0); k < max; k++ {
+		if count < uint64(len(nums)) && nums[count] == k {
+			count++
+		}
+		assert.Equal(t, count, ba.Rank(k))
+		assert.Equal(t, count, ri.Rank(k))
+	}
+
+	for i, num := range nums {
+		n := uint64(i + 1)
+		result, err := ba.Select(n)
+		assert.Nil(t, err)
+		assert.Equal(t, num, result)
+		result, err = ri.Select(n)
+		assert.Nil(t, err)
+		assert.Equal(t, num, result)
+	}
+
+	for _, n := range []uint64{0, uint64(len(nums) + 1)} {
+		_, err := ba.Select(n)
+		assert.IsType(t, OutOfRangeError(0), err)
+		_, err = ri.Select(n)
+		assert.IsType(t, OutOfRangeError(0), err)
+	}
+}
+
+func TestRankAndSelect(t *testing.T) {
+	positions := []uint64{3, 63, 64, 65, 200, 201, 500, 999}
+	dense := NewBitArray(1000)
+	sparse := NewSparseBitArray()
+	for _, k := range positions {
+		dense.SetBit(k)
+		sparse.SetBit(k)
+	}
+
+	checkRankAndSelect(t, dense, 1100)
+	checkRankAndSelect(t, sparse, 1100)
+}
+
+func TestRankAndSelectEmpty(t *testing.T) {
+	checkRankAndSelect(t, NewBitArray(100), 100)
+	checkRankAndSelect(t, NewSparseBitArray(), 100)
+}
+
+func TestRankIndexIgnoresLaterWrites(t *testing.T) {
+	ba := NewSparseBitArray()
+	ba.SetBit(10)
+	ri := NewRankIndex(ba)
+	ba.SetBit(5)
+
+	assert.Equal(t, uint64(1), ri.Rank(10))
+	assert.Equal(t, uint64(2), ba.Rank(10))
+}
+
+func BenchmarkSelect(b *testing.B) {
+	numItems := uint64(100000)
+	ba := NewBitArray(numItems)
+	for i := uint64(0); i < numItems; i += 3 {
+		ba.SetBit(i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ba.Select(numItems / 4)
+	}
+}
+
+func BenchmarkRankIndexSelect(b *testing.B) {
+	numItems := uint64(100000)
+	ba := NewBitArray(numItems)
+	for i := uint64(0); i < numItems; i += 3 {
+		ba.SetBit(i)
+	}
+	ri := NewRankIndex(ba)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ri.Select(numItems / 4)
+	}
+}
@@ -0,0 +1,721 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+	"unsafe"
+)
+
+// maxKey is the key of the chunk holding the highest possible
+// position.
+const maxKey = ^uint64(0) >> containerBits
+
+// roaringBitArray is a compressed bit array in the style of roaring
+// bitmaps.  Positions are split into 2^16 chunks by their high bits
+// and the low bits of each non-empty chunk are held in a container
+// that is either a sorted array, a bitmap or a list of runs,
+// whichever is smallest.  This stays compact for data that is dense
+// but clustered, where a sparse bit array degrades.
+type roaringBitArray struct {
+	keys       []uint64
+	containers []container
+	// shared indicates that keys and containers are also referenced
+	// by a snapshot and must be copied before they are mutated.
+	shared bool
+}
+
+// search returns the index of the first container whose key is at
+// or after the provided key.
+func (rba *roaringBitArray) search(key uint64) int {
+	return sort.Search(len(rba.keys), func(i int) bool {
+		return rba.keys[i] >= key
+	})
+}
+
+func (rba *roaringBitArray) find(k uint64) (int, uint16, bool) {
+	key := k >> containerBits
+	i := rba.search(key)
+	return i, uint16(k), i < len(rba.keys) && rba.keys[i] == key
+}
+
+func (rba *roaringBitArray) append(key uint64, c container) {
+	rba.keys = append(rba.keys, key)
+	rba.containers = append(rba.containers, c)
+}
+
+// SetBit sets the bit at the given position.
+func (rba *roaringBitArray) SetBit(k uint64) error {
+	rba.unshare()
+	i, x, ok := rba.find(k)
+	if ok {
+		rba.containers[i] = rba.containers[i].set(x)
+		return nil
+	}
+
+	rba.keys = append(rba.keys, 0)
+	copy(rba.keys[i+1:], rba.keys[i:])
+	rba.keys[i] = k >> containerBits
+	rba.containers = append(rba.containers, nil)
+	copy(rba.containers[i+1:], rba.containers[i:])
+	rba.containers[i] = arrayContainer{x}
+	return nil
+}
+
+// GetBit gets the bit at the given position.
+func (rba *roaringBitArray) GetBit(k uint64) (bool, error) {
+	i, x, ok := rba.find(k)
+	if !ok {
+		return false, nil
+	}
+
+	return rba.containers[i].get(x), nil
+}
+
+// ClearBit clears the bit at the given position.
+func (rba *roaringBitArray) ClearBit(k uint64) error {
+	i, x, ok := rba.find(k)
+	if !ok {
+		return nil
+	}
+
+	rba.unshare()
+	c := rba.containers[i].clear(x)
+	if c.cardinality() > 0 {
+		rba.containers[i] = c
+		return nil
+	}
+
+	copy(rba.keys[i:], rba.keys[i+1:])
+	rba.keys = rba.keys[:len(rba.keys)-1]
+	copy(rba.containers[i:], rba.containers[i+1:])
+	rba.containers[len(rba.containers)-1] = nil
+	rba.containers = rba.containers[:len(rba.containers)-1]
+	return nil
+}
+
+// Reset clears out the bit array.
+func (rba *roaringBitArray) Reset() {
+	rba.keys = nil
+	rba.containers = nil
+	rba.shared = false
+}
+
+// Blocks returns an iterator over the non-empty blocks of this bit
+// array.
+func (rba *roaringBitArray) Blocks() Iterator {
+	return newRoaringBitArrayIterator(rba)
+}
+
+// Equals returns a bool indicating if the provided bit array has the
+// same bits set as this bit array.
+func (rba *roaringBitArray) Equals(other BitArray) bool {
+	return blocksEqual(rba.Blocks(), other.Blocks())
+}
+
+// Hash returns a hash of the set bits that is the same for any equal
+// bit array.
+func (rba *roaringBitArray) Hash() uint64 {
+	return hashBlocks(rba.Blocks())
+}
+
+// Intersects returns a bool indicating if every bit set in the
+// provided bit array is also set in this bit array.
+func (rba *roaringBitArray) Intersects(other BitArray) bool {
+	o := toRoaring(other)
+	for i, key := range o.keys {
+		j := rba.search(key)
+		if j == len(rba.keys) || rba.keys[j] != key {
+			return false
+		}
+
+		if applyOp(andNotOp, o.containers[i], rba.containers[j]) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Capacity returns the highest set position rounded up to the end of
+// its block, or zero if no bits are set.
+func (rba *roaringBitArray) Capacity() uint64 {
+	if len(rba.keys) == 0 {
+		return 0
+	}
+
+	last := len(rba.keys) - 1
+	c := rba.containers[last]
+	highest := rba.keys[last]<<containerBits | uint64(c.selectBit(c.cardinality()))
+	i, _ := getIndexAndRemainder(highest)
+	return (i + 1) * s
+}
+
+// NextSet returns the first set bit at or after the given position.
+func (rba *roaringBitArray) NextSet(k uint64) (uint64, bool) {
+	key, x := k>>containerBits, uint16(k)
+	for i := rba.search(key); i < len(rba.keys); i++ {
+		if rba.keys[i] != key {
+			x = 0
+		}
+
+		if y, ok := rba.containers[i].nextSet(x); ok {
+			return rba.keys[i]<<containerBits | uint64(y), true
+		}
+	}
+
+	return 0, false
+}
+
+// PrevSet returns the last set bit at or before the given position.
+func (rba *roaringBitArray) PrevSet(k uint64) (uint64, bool) {
+	key, x := k>>containerBits, uint16(k)
+	for i := rba.search(key+1) - 1; i >= 0; i-- {
+		if rba.keys[i] != key {
+			x = math.MaxUint16
+		}
+
+		if y, ok := rba.containers[i].prevSet(x); ok {
+			return rba.keys[i]<<containerBits | uint64(y), true
+		}
+	}
+
+	return 0, false
+}
+
+// Iterate calls fn with every set bit in ascending order until fn
+// returns false.
+func (rba *roaringBitArray) Iterate(fn func(uint64) bool) {
+	for i, c := range rba.containers {
+		if !c.each(rba.keys[i]<<containerBits, fn) {
+			return
+		}
+	}
+}
+
+// All returns a sequence of the position of every set bit in
+// ascending order for use with range.
+func (rba *roaringBitArray) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		rba.Iterate(yield)
+	}
+}
+
+// Or will bitwise or the two bit arrays container by container and
+// return a new bit array representing the result.
+func (rba *roaringBitArray) Or(other BitArray) BitArray {
+	return rba.apply(orOp, toRoaring(other))
+}
+
+// And will bitwise and the two bit arrays container by container and
+// return a new bit array representing the result.
+func (rba *roaringBitArray) And(other BitArray) BitArray {
+	return rba.apply(andOp, toRoaring(other))
+}
+
+// AndNot will clear the bits set in the provided bit array from a
+// copy of this bit array container by container and return the
+// result.
+func (rba *roaringBitArray) AndNot(other BitArray) BitArray {
+	return rba.apply(andNotOp, toRoaring(other))
+}
+
+// Not returns a new bit array with every bit in [0, max) that is
+// clear in this bit array set.  Chunks with no bits set in this bit
+// array become single runs.
+func (rba *roaringBitArray) Not(max uint64) BitArray {
+	return roaringRange(0, max).apply(andNotOp, rba)
+}
+
+// Xor will bitwise xor the two bit arrays container by container and
+// return a new bit array representing the result.
+func (rba *roaringBitArray) Xor(other BitArray) BitArray {
+	return rba.apply(xorOp, toRoaring(other))
+}
+
+func (rba *roaringBitArray) apply(op setOp, other *roaringBitArray) BitArray {
+	result := newRoaringBitArray()
+	i, j := 0, 0
+	for i < len(rba.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(rba.keys) && rba.keys[i] < other.keys[j]):
+			if op.keeps(true, false) {
+				result.append(rba.keys[i], rba.containers[i].clone())
+			}
+			i++
+		case i == len(rba.keys) || other.keys[j] < rba.keys[i]:
+			if op.keeps(false, true) {
+				result.append(other.keys[j], other.containers[j].clone())
+			}
+			j++
+		default:
+			if c := applyOp(op, rba.containers[i], other.containers[j]); c != nil {
+				result.append(rba.keys[i], c)
+			}
+			i++
+			j++
+		}
+	}
+
+	return result
+}
+
+// OrInPlace will bitwise or the other bit array into this one
+// container by container.
+func (rba *roaringBitArray) OrInPlace(other BitArray) {
+	rba.applyInPlace(orOp, other)
+}
+
+// AndInPlace will bitwise and the other bit array into this one
+// container by container.
+func (rba *roaringBitArray) AndInPlace(other BitArray) {
+	rba.applyInPlace(andOp, other)
+}
+
+// AndNotInPlace will clear the bits set in the other bit array from
+// this one container by container.
+func (rba *roaringBitArray) AndNotInPlace(other BitArray) {
+	rba.applyInPlace(andNotOp, other)
+}
+
+// XorInPlace will bitwise xor the other bit array into this one
+// container by container.
+func (rba *roaringBitArray) XorInPlace(other BitArray) {
+	rba.applyInPlace(xorOp, other)
+}
+
+// applyInPlace applies op to this bit array and the other, reusing
+// this bit array's keys, containers and bitmaps where possible.
+func (rba *roaringBitArray) applyInPlace(op setOp, ba BitArray) {
+	if ba == BitArray(rba) {
+		ba = rba.Snapshot()
+	}
+
+	other := toRoaring(ba)
+	rba.unshare()
+
+	if !op.keeps(false, true) {
+		j := 0
+		for i, key := range rba.keys {
+			for j < len(other.keys) && other.keys[j] < key {
+				j++
+			}
+
+			if j < len(other.keys) && other.keys[j] == key {
+				rba.containers[i] = applyOpInPlace(op, rba.containers[i], other.containers[j])
+			} else if !op.keeps(true, false) {
+				rba.containers[i] = nil
+			}
+		}
+
+		rba.compact()
+		return
+	}
+
+	missing := 0
+	for i, j := 0, 0; j < len(other.keys); j++ {
+		for i < len(rba.keys) && rba.keys[i] < other.keys[j] {
+			i++
+		}
+
+		if i == len(rba.keys) || rba.keys[i] != other.keys[j] {
+			missing++
+		}
+	}
+
+	i := len(rba.keys) - 1
+	for ; missing > 0; missing-- {
+		rba.append(0, nil)
+	}
+
+	// merge from the back so containers only move once
+	j := len(other.keys) - 1
+	for k := len(rba.keys) - 1; j >= 0; k-- {
+		switch {
+		case i >= 0 && rba.keys[i] > other.keys[j]:
+			rba.keys[k], rba.containers[k] = rba.keys[i], rba.containers[i]
+			i--
+		case i >= 0 && rba.keys[i] == other.keys[j]:
+			rba.keys[k] = rba.keys[i]
+			rba.containers[k] = applyOpInPlace(op, rba.containers[i], other.containers[j])
+			i--
+			j--
+		default:
+			rba.keys[k], rba.containers[k] = other.keys[j], other.containers[j].clone()
+			j--
+		}
+	}
+
+	rba.compact()
+}
+
+// compact removes the nil containers left by emptied chunks.
+func (rba *roaringBitArray) compact() {
+	k := 0
+	for i, c := range rba.containers {
+		if c != nil {
+			rba.keys[k], rba.containers[k] = rba.keys[i], c
+			k++
+		}
+	}
+
+	for i := k; i < len(rba.containers); i++ {
+		rba.containers[i] = nil
+	}
+	rba.keys = rba.keys[:k]
+	rba.containers = rba.containers[:k]
+}
+
+// SetRange sets every bit in [start, stop).
+func (rba *roaringBitArray) SetRange(start, stop uint64) error {
+	rba.applyInPlace(orOp, roaringRange(start, stop))
+	return nil
+}
+
+// ClearRange clears every bit in [start, stop).
+func (rba *roaringBitArray) ClearRange(start, stop uint64) error {
+	rba.applyInPlace(andNotOp, roaringRange(start, stop))
+	return nil
+}
+
+// ShiftLeft moves every bit n positions higher.
+func (rba *roaringBitArray) ShiftLeft(n uint64) {
+	rba.shift(n, true)
+}
+
+// ShiftRight moves every bit n positions lower, dropping bits moved
+// below zero.
+func (rba *roaringBitArray) ShiftRight(n uint64) {
+	rba.shift(n, false)
+}
+
+func (rba *roaringBitArray) shift(n uint64, left bool) {
+	if n%(1<<containerBits) != 0 {
+		indices, blocks := shiftBlocks(rba.Blocks(), n, left)
+		shifted := toRoaring(&sparseBitArray{indices: indices, blocks: blocks})
+		rba.keys, rba.containers = shifted.keys, shifted.containers
+		rba.shared = false
+		return
+	}
+
+	// whole chunks move by changing their keys alone
+	rba.unshare()
+	delta := n >> containerBits
+	for i, key := range rba.keys {
+		switch {
+		case left && key > maxKey-delta:
+			rba.containers[i] = nil
+		case left:
+			rba.keys[i] = key + delta
+		case key < delta:
+			rba.containers[i] = nil
+		default:
+			rba.keys[i] = key - delta
+		}
+	}
+
+	rba.compact()
+}
+
+// roaringRange returns a roaring bit array with the positions in
+// [start, stop) set.
+func roaringRange(start, stop uint64) *roaringBitArray {
+	rba := newRoaringBitArray()
+	if start >= stop {
+		return rba
+	}
+
+	first, last := start>>containerBits, (stop-1)>>containerBits
+	for key := first; key <= last; key++ {
+		lo, hi := uint16(0), uint16(math.MaxUint16)
+		if key == first {
+			lo = uint16(start)
+		}
+		if key == last {
+			hi = uint16(stop - 1)
+		}
+
+		rba.append(key, optimize(runContainer{{start: lo, last: hi}}))
+	}
+
+	return rba
+}
+
+// Count returns the number of set bits.
+func (rba *roaringBitArray) Count() uint64 {
+	count := uint64(0)
+	for _, c := range rba.containers {
+		count += c.cardinality()
+	}
+
+	return count
+}
+
+// ToNums converts this bit array to the list of numbers contained
+// within it.
+func (rba *roaringBitArray) ToNums() []uint64 {
+	nums := make([]uint64, 0, rba.Count())
+	for i, c := range rba.containers {
+		nums = c.appendTo(nums, rba.keys[i]<<containerBits)
+	}
+
+	return nums
+}
+
+// Rank returns the number of set bits at or before the given
+// position.
+func (rba *roaringBitArray) Rank(k uint64) uint64 {
+	key, x := k>>containerBits, uint16(k)
+	count := uint64(0)
+	for i, c := range rba.containers {
+		if rba.keys[i] > key {
+			break
+		}
+
+		if rba.keys[i] == key {
+			return count + c.rank(x)
+		}
+
+		count += c.cardinality()
+	}
+
+	return count
+}
+
+// Select returns the position of the n-th set bit, counting from one.
+func (rba *roaringBitArray) Select(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, OutOfRangeError(n)
+	}
+
+	remaining := n
+	for i, c := range rba.containers {
+		count := c.cardinality()
+		if remaining <= count {
+			return rba.keys[i]<<containerBits | uint64(c.selectBit(remaining)), nil
+		}
+		remaining -= count
+	}
+
+	return 0, OutOfRangeError(n)
+}
+
+func (rba *roaringBitArray) copy() *roaringBitArray {
+	cp := &roaringBitArray{
+		keys:       make([]uint64, len(rba.keys)),
+		containers: make([]container, len(rba.containers)),
+	}
+	copy(cp.keys, rba.keys)
+	for i, c := range rba.containers {
+		cp.containers[i] = c.clone()
+	}
+
+	return cp
+}
+
+// unshare copies this bit array's keys and containers if they are
+// referenced by a snapshot.  Must be called before any mutation.
+func (rba *roaringBitArray) unshare() {
+	if !rba.shared {
+		return
+	}
+
+	cp := rba.copy()
+	rba.keys, rba.containers = cp.keys, cp.containers
+	rba.shared = false
+}
+
+// Snapshot returns a point-in-time copy of this bit array.
+// Containers are shared with the copy until either bit array is
+// modified.
+func (rba *roaringBitArray) Snapshot() BitArray {
+	rba.shared = true
+	return &roaringBitArray{
+		keys:       rba.keys,
+		containers: rba.containers,
+		shared:     true,
+	}
+}
+
+// Validate checks that there is exactly one non-empty, well formed
+// container per key and that keys are strictly increasing.
+func (rba *roaringBitArray) Validate() error {
+	if len(rba.keys) != len(rba.containers) {
+		return fmt.Errorf(`Roaring bit array has %d keys and %d containers.`,
+			len(rba.keys), len(rba.containers))
+	}
+
+	for i, c := range rba.containers {
+		if i > 0 && rba.keys[i-1] >= rba.keys[i] {
+			return fmt.Errorf(`Roaring bit array keys out of order at %d.`, i)
+		}
+
+		if c.cardinality() == 0 {
+			return fmt.Errorf(`Roaring bit array container %d is empty.`, i)
+		}
+
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SizeOf returns an estimate of the number of bytes used by this bit
+// array.
+func (rba *roaringBitArray) SizeOf() uint64 {
+	size := uint64(unsafe.Sizeof(*rba)) +
+		uint64(cap(rba.keys))*uint64(unsafe.Sizeof(uint64(0))) +
+		uint64(cap(rba.containers))*uint64(unsafe.Sizeof(container(nil)))
+	for _, c := range rba.containers {
+		size += c.sizeOf()
+	}
+
+	return size
+}
+
+// toSparse converts this bit array to a sparse bit array.
+func (rba *roaringBitArray) toSparse() *sparseBitArray {
+	sba := newSparseBitArray()
+	for iter := rba.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		sba.indices = append(sba.indices, index)
+		sba.blocks = append(sba.blocks, block)
+	}
+
+	return sba
+}
+
+// fromRoaring converts a roaring bit array to a sparse bit array so it
+// can be combined with dense and sparse bit arrays.  Any other bit
+// array is returned as is.
+func fromRoaring(other BitArray) BitArray {
+	if rba, ok := other.(*roaringBitArray); ok {
+		return rba.toSparse()
+	}
+
+	return other
+}
+
+// toRoaring converts the provided bit array to a roaring bit array.
+// A roaring bit array is returned as is.
+func toRoaring(other BitArray) *roaringBitArray {
+	if rba, ok := other.(*roaringBitArray); ok {
+		return rba
+	}
+
+	rba := newRoaringBitArray()
+	for iter := other.Blocks(); iter.Next(); {
+		index, block := iter.Value()
+		if block == 0 {
+			continue
+		}
+
+		key := index / containerBlocks
+		if len(rba.keys) == 0 || rba.keys[len(rba.keys)-1] != key {
+			rba.append(key, newBitmapContainer())
+		}
+
+		bc := rba.containers[len(rba.containers)-1].(*bitmapContainer)
+		bc.blocks[index%containerBlocks] = block
+		bc.card += block.count()
+	}
+
+	for i, c := range rba.containers {
+		rba.containers[i] = optimize(c)
+	}
+
+	return rba
+}
+
+// nextBlock advances the iterator to its next non-empty block.
+func nextBlock(iter Iterator) (uint64, block, bool) {
+	for iter.Next() {
+		if index, block := iter.Value(); block != 0 {
+			return index, block, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// blocksEqual returns a bool indicating if the two iterators produce
+// the same non-empty blocks.
+func blocksEqual(iter, other Iterator) bool {
+	for {
+		index, block, ok := nextBlock(iter)
+		otherIndex, otherBlock, otherOK := nextBlock(other)
+		if ok != otherOK {
+			return false
+		}
+
+		if !ok {
+			return true
+		}
+
+		if index != otherIndex || block != otherBlock {
+			return false
+		}
+	}
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// fnvWord folds the bytes of w, lowest first, into the FNV-1a hash h.
+func fnvWord(h, w uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= w & 0xff
+		h *= fnvPrime
+		w >>= 8
+	}
+
+	return h
+}
+
+// hashBlocks returns the FNV-1a hash of the index and value of every
+// non-empty block the iterator produces.  Empty blocks are skipped as
+// blocksEqual skips them, so bit arrays of any kind that are equal
+// hash the same.
+func hashBlocks(iter Iterator) uint64 {
+	h := uint64(fnvOffset)
+	for {
+		index, block, ok := nextBlock(iter)
+		if !ok {
+			return h
+		}
+
+		h = fnvWord(fnvWord(h, index), uint64(block))
+	}
+}
+
+func newRoaringBitArray() *roaringBitArray {
+	return &roaringBitArray{}
+}
+
+// NewRoaringBitArray will create a compressed bit array in the style of
+// roaring bitmaps.  It uses far less memory than a sparse bit array
+// when set bits are clustered and performs bitwise operations chunk
+// by chunk.
+func NewRoaringBitArray() BitArray {
+	return newRoaringBitArray()
+}
@@ -0,0 +1,784 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"unsafe"
+)
+
+const (
+	// containerBits is the number of low bits of a position that are
+	// held by a single roaring container.
+	containerBits = 16
+	// containerBlocks is the number of blocks in a bitmap container.
+	containerBlocks = (1 << containerBits) / s
+	// bitmapBytes is the number of bytes used by a bitmap container.
+	bitmapBytes = containerBlocks * blockSize
+	// arrayLimit is the largest cardinality held in an array
+	// container; beyond it a bitmap container is smaller.
+	arrayLimit = bitmapBytes / 2
+	// runLimit is the largest number of runs held in a run container;
+	// beyond it a bitmap container is smaller.
+	runLimit = bitmapBytes / uint64(unsafe.Sizeof(run{}))
+)
+
+// container holds the low bits of the positions set within a single
+// 2^16 chunk of a roaring bit array.  Mutations return the container
+// to use from then on, which may be a different representation if
+// that one has become smaller.
+type container interface {
+	get(x uint16) bool
+	set(x uint16) container
+	clear(x uint16) container
+	cardinality() uint64
+	// rank returns the number of set bits at or below x.
+	rank(x uint16) uint64
+	// selectBit returns the n-th set bit, counting from one.
+	selectBit(n uint64) uint16
+	// numRuns returns the number of runs of consecutive set bits.
+	numRuns() uint64
+	// toBitmap returns a bitmap copy of this container.
+	toBitmap() *bitmapContainer
+	// asBlocks returns this container as bitmap blocks.  The result
+	// must not be modified.
+	asBlocks() []block
+	// nextSet returns the first set bit at or above x.
+	nextSet(x uint16) (uint16, bool)
+	// prevSet returns the last set bit at or below x.
+	prevSet(x uint16) (uint16, bool)
+	// each calls fn with every set bit, combined with the offset,
+	// until fn returns false and returns false if it did.
+	each(offset uint64, fn func(uint64) bool) bool
+	appendTo(nums []uint64, offset uint64) []uint64
+	clone() container
+	sizeOf() uint64
+	validate() error
+}
+
+// arrayContainer is a sorted list of the set bits in a chunk.
+type arrayContainer []uint16
+
+func (ac arrayContainer) search(x uint16) int {
+	return sort.Search(len(ac), func(i int) bool {
+		return ac[i] >= x
+	})
+}
+
+func (ac arrayContainer) get(x uint16) bool {
+	i := ac.search(x)
+	return i < len(ac) && ac[i] == x
+}
+
+func (ac arrayContainer) set(x uint16) container {
+	i := ac.search(x)
+	if i < len(ac) && ac[i] == x {
+		return ac
+	}
+
+	if uint64(len(ac)) == arrayLimit {
+		return optimize(ac.toBitmap().set(x))
+	}
+
+	ac = append(ac, 0)
+	copy(ac[i+1:], ac[i:])
+	ac[i] = x
+	return ac
+}
+
+func (ac arrayContainer) clear(x uint16) container {
+	i := ac.search(x)
+	if i == len(ac) || ac[i] != x {
+		return ac
+	}
+
+	copy(ac[i:], ac[i+1:])
+	return ac[:len(ac)-1]
+}
+
+func (ac arrayContainer) cardinality() uint64 {
+	return uint64(len(ac))
+}
+
+func (ac arrayContainer) rank(x uint16) uint64 {
+	return uint64(sort.Search(len(ac), func(i int) bool {
+		return ac[i] > x
+	}))
+}
+
+func (ac arrayContainer) selectBit(n uint64) uint16 {
+	return ac[n-1]
+}
+
+func (ac arrayContainer) numRuns() uint64 {
+	runs := uint64(0)
+	for i, x := range ac {
+		if i == 0 || ac[i-1]+1 != x {
+			runs++
+		}
+	}
+
+	return runs
+}
+
+func (ac arrayContainer) toBitmap() *bitmapContainer {
+	bc := newBitmapContainer()
+	for _, x := range ac {
+		i, pos := getIndexAndRemainder(uint64(x))
+		bc.blocks[i] = bc.blocks[i].insert(pos)
+	}
+	bc.card = uint64(len(ac))
+
+	return bc
+}
+
+func (ac arrayContainer) asBlocks() []block {
+	return ac.toBitmap().blocks
+}
+
+func (ac arrayContainer) nextSet(x uint16) (uint16, bool) {
+	i := ac.search(x)
+	if i == len(ac) {
+		return 0, false
+	}
+
+	return ac[i], true
+}
+
+func (ac arrayContainer) prevSet(x uint16) (uint16, bool) {
+	i := int(ac.rank(x)) - 1
+	if i < 0 {
+		return 0, false
+	}
+
+	return ac[i], true
+}
+
+func (ac arrayContainer) each(offset uint64, fn func(uint64) bool) bool {
+	for _, x := range ac {
+		if !fn(offset | uint64(x)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ac arrayContainer) appendTo(nums []uint64, offset uint64) []uint64 {
+	for _, x := range ac {
+		nums = append(nums, offset|uint64(x))
+	}
+
+	return nums
+}
+
+func (ac arrayContainer) clone() container {
+	cp := make(arrayContainer, len(ac))
+	copy(cp, ac)
+	return cp
+}
+
+func (ac arrayContainer) sizeOf() uint64 {
+	return uint64(unsafe.Sizeof(ac)) + uint64(cap(ac))*uint64(unsafe.Sizeof(uint16(0)))
+}
+
+func (ac arrayContainer) validate() error {
+	if uint64(len(ac)) > arrayLimit {
+		return fmt.Errorf(`Array container holds %d values.`, len(ac))
+	}
+
+	for i := 1; i < len(ac); i++ {
+		if ac[i-1] >= ac[i] {
+			return fmt.Errorf(`Array container out of order at %d.`, i)
+		}
+	}
+
+	return nil
+}
+
+// bitmapContainer holds one bit for every position in a chunk.
+type bitmapContainer struct {
+	blocks []block
+	card   uint64
+}
+
+func newBitmapContainer() *bitmapContainer {
+	return &bitmapContainer{blocks: make([]block, containerBlocks)}
+}
+
+func (bc *bitmapContainer) get(x uint16) bool {
+	i, pos := getIndexAndRemainder(uint64(x))
+	return bc.blocks[i].get(pos)
+}
+
+func (bc *bitmapContainer) set(x uint16) container {
+	i, pos := getIndexAndRemainder(uint64(x))
+	if !bc.blocks[i].get(pos) {
+		bc.blocks[i] = bc.blocks[i].insert(pos)
+		bc.card++
+	}
+
+	return bc
+}
+
+func (bc *bitmapContainer) clear(x uint16) container {
+	i, pos := getIndexAndRemainder(uint64(x))
+	if !bc.blocks[i].get(pos) {
+		return bc
+	}
+
+	bc.blocks[i] = bc.blocks[i].remove(pos)
+	bc.card--
+	if bc.card <= arrayLimit {
+		return optimize(bc)
+	}
+
+	return bc
+}
+
+func (bc *bitmapContainer) cardinality() uint64 {
+	return bc.card
+}
+
+func (bc *bitmapContainer) rank(x uint16) uint64 {
+	i, pos := getIndexAndRemainder(uint64(x))
+	count := uint64(0)
+	for _, block := range bc.blocks[:i] {
+		count += block.count()
+	}
+
+	return count + bc.blocks[i].rank(pos)
+}
+
+func (bc *bitmapContainer) selectBit(n uint64) uint16 {
+	for i, block := range bc.blocks {
+		count := block.count()
+		if n <= count {
+			return uint16(uint64(i)*s + block.selectBit(n))
+		}
+		n -= count
+	}
+
+	return 0
+}
+
+func (bc *bitmapContainer) numRuns() uint64 {
+	runs := uint64(0)
+	prev := block(0)
+	for _, block := range bc.blocks {
+		// a run starts at every set bit whose lower neighbor,
+		// possibly in the previous block, is unset
+		runs += (block &^ (block<<1 | prev>>(s-1))).count()
+		prev = block
+	}
+
+	return runs
+}
+
+func (bc *bitmapContainer) toBitmap() *bitmapContainer {
+	return bc.clone().(*bitmapContainer)
+}
+
+func (bc *bitmapContainer) asBlocks() []block {
+	return bc.blocks
+}
+
+func (bc *bitmapContainer) toArray() arrayContainer {
+	ac := make(arrayContainer, 0, bc.card)
+	for i, block := range bc.blocks {
+		for ; block != 0; block &= block - 1 {
+			pos := uint64(bits.TrailingZeros64(uint64(block)))
+			ac = append(ac, uint16(uint64(i)*s+pos))
+		}
+	}
+
+	return ac
+}
+
+func (bc *bitmapContainer) toRuns() runContainer {
+	var rc runContainer
+	for _, x := range bc.toArray() {
+		if len(rc) > 0 && rc[len(rc)-1].last+1 == x {
+			rc[len(rc)-1].last = x
+			continue
+		}
+		rc = append(rc, run{start: x, last: x})
+	}
+
+	return rc
+}
+
+func (bc *bitmapContainer) nextSet(x uint16) (uint16, bool) {
+	i, pos := getIndexAndRemainder(uint64(x))
+	for ; i < uint64(len(bc.blocks)); i++ {
+		if p, ok := bc.blocks[i].nextSet(pos); ok {
+			return uint16(i*s + p), true
+		}
+		pos = 0
+	}
+
+	return 0, false
+}
+
+func (bc *bitmapContainer) prevSet(x uint16) (uint16, bool) {
+	i, pos := getIndexAndRemainder(uint64(x))
+	for {
+		if p, ok := bc.blocks[i].prevSet(pos); ok {
+			return uint16(i*s + p), true
+		}
+
+		if i == 0 {
+			return 0, false
+		}
+		i--
+		pos = s - 1
+	}
+}
+
+func (bc *bitmapContainer) each(offset uint64, fn func(uint64) bool) bool {
+	for i, block := range bc.blocks {
+		for ; block != 0; block &= block - 1 {
+			pos, _ := block.nextSet(0)
+			if !fn(offset | (uint64(i)*s + pos)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (bc *bitmapContainer) appendTo(nums []uint64, offset uint64) []uint64 {
+	for i, block := range bc.blocks {
+		for ; block != 0; block &= block - 1 {
+			pos := uint64(bits.TrailingZeros64(uint64(block)))
+			nums = append(nums, offset|(uint64(i)*s+pos))
+		}
+	}
+
+	return nums
+}
+
+func (bc *bitmapContainer) clone() container {
+	cp := &bitmapContainer{blocks: make([]block, len(bc.blocks)), card: bc.card}
+	copy(cp.blocks, bc.blocks)
+	return cp
+}
+
+func (bc *bitmapContainer) sizeOf() uint64 {
+	return uint64(unsafe.Sizeof(*bc)) + uint64(cap(bc.blocks))*blockSize
+}
+
+func (bc *bitmapContainer) validate() error {
+	if uint64(len(bc.blocks)) != containerBlocks {
+		return fmt.Errorf(`Bitmap container has %d blocks.`, len(bc.blocks))
+	}
+
+	count := uint64(0)
+	for _, block := range bc.blocks {
+		count += block.count()
+	}
+
+	if count != bc.card {
+		return fmt.Errorf(`Bitmap container has cardinality %d but %d bits set.`,
+			bc.card, count)
+	}
+
+	if bc.card <= arrayLimit {
+		return fmt.Errorf(`Bitmap container holds only %d values.`, bc.card)
+	}
+
+	return nil
+}
+
+// run is an inclusive range of set bits.
+type run struct {
+	start, last uint16
+}
+
+// runContainer is a sorted list of the runs of set bits in a chunk,
+// which is compact when set bits are clustered together.
+type runContainer []run
+
+// search returns the index of the first run ending at or after x.
+func (rc runContainer) search(x uint16) int {
+	return sort.Search(len(rc), func(i int) bool {
+		return rc[i].last >= x
+	})
+}
+
+func (rc runContainer) insert(i int, r run) container {
+	rc = append(rc, run{})
+	copy(rc[i+1:], rc[i:])
+	rc[i] = r
+	if uint64(len(rc)) > runLimit {
+		return optimize(rc)
+	}
+
+	return rc
+}
+
+func (rc runContainer) get(x uint16) bool {
+	i := rc.search(x)
+	return i < len(rc) && rc[i].start <= x
+}
+
+func (rc runContainer) set(x uint16) container {
+	i := rc.search(x)
+	if i < len(rc) && rc[i].start <= x {
+		return rc
+	}
+
+	// x falls between rc[i-1] and rc[i], so neither bound overflows
+	joinsPrev := i > 0 && rc[i-1].last+1 == x
+	joinsNext := i < len(rc) && x+1 == rc[i].start
+	switch {
+	case joinsPrev && joinsNext:
+		rc[i-1].last = rc[i].last
+		copy(rc[i:], rc[i+1:])
+		return rc[:len(rc)-1]
+	case joinsPrev:
+		rc[i-1].last = x
+		return rc
+	case joinsNext:
+		rc[i].start = x
+		return rc
+	}
+
+	return rc.insert(i, run{start: x, last: x})
+}
+
+func (rc runContainer) clear(x uint16) container {
+	i := rc.search(x)
+	if i == len(rc) || rc[i].start > x {
+		return rc
+	}
+
+	r := rc[i]
+	switch {
+	case r.start == r.last:
+		copy(rc[i:], rc[i+1:])
+		return rc[:len(rc)-1]
+	case x == r.start:
+		rc[i].start++
+		return rc
+	case x == r.last:
+		rc[i].last--
+		return rc
+	}
+
+	rc[i].last = x - 1
+	return rc.insert(i+1, run{start: x + 1, last: r.last})
+}
+
+func (rc runContainer) cardinality() uint64 {
+	count := uint64(0)
+	for _, r := range rc {
+		count += uint64(r.last-r.start) + 1
+	}
+
+	return count
+}
+
+func (rc runContainer) rank(x uint16) uint64 {
+	count := uint64(0)
+	for _, r := range rc {
+		if r.start > x {
+			break
+		}
+
+		if r.last >= x {
+			return count + uint64(x-r.start) + 1
+		}
+
+		count += uint64(r.last-r.start) + 1
+	}
+
+	return count
+}
+
+func (rc runContainer) selectBit(n uint64) uint16 {
+	for _, r := range rc {
+		size := uint64(r.last-r.start) + 1
+		if n <= size {
+			return r.start + uint16(n-1)
+		}
+		n -= size
+	}
+
+	return 0
+}
+
+func (rc runContainer) numRuns() uint64 {
+	return uint64(len(rc))
+}
+
+func (rc runContainer) toBitmap() *bitmapContainer {
+	bc := newBitmapContainer()
+	for _, r := range rc {
+		for x := uint64(r.start); x <= uint64(r.last); x++ {
+			i, pos := getIndexAndRemainder(x)
+			bc.blocks[i] = bc.blocks[i].insert(pos)
+		}
+		bc.card += uint64(r.last-r.start) + 1
+	}
+
+	return bc
+}
+
+func (rc runContainer) asBlocks() []block {
+	return rc.toBitmap().blocks
+}
+
+func (rc runContainer) nextSet(x uint16) (uint16, bool) {
+	i := rc.search(x)
+	if i == len(rc) {
+		return 0, false
+	}
+
+	if rc[i].start > x {
+		return rc[i].start, true
+	}
+
+	return x, true
+}
+
+func (rc runContainer) prevSet(x uint16) (uint16, bool) {
+	i := sort.Search(len(rc), func(i int) bool {
+		return rc[i].start > x
+	}) - 1
+	if i < 0 {
+		return 0, false
+	}
+
+	if rc[i].last < x {
+		return rc[i].last, true
+	}
+
+	return x, true
+}
+
+func (rc runContainer) each(offset uint64, fn func(uint64) bool) bool {
+	for _, r := range rc {
+		for x := uint64(r.start); x <= uint64(r.last); x++ {
+			if !fn(offset | x) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (rc runContainer) appendTo(nums []uint64, offset uint64) []uint64 {
+	for _, r := range rc {
+		for x := uint64(r.start); x <= uint64(r.last); x++ {
+			nums = append(nums, offset|x)
+		}
+	}
+
+	return nums
+}
+
+func (rc runContainer) clone() container {
+	cp := make(runContainer, len(rc))
+	copy(cp, rc)
+	return cp
+}
+
+func (rc runContainer) sizeOf() uint64 {
+	return uint64(unsafe.Sizeof(rc)) + uint64(cap(rc))*uint64(unsafe.Sizeof(run{}))
+}
+
+func (rc runContainer) validate() error {
+	if uint64(len(rc)) > runLimit {
+		return fmt.Errorf(`Run container holds %d runs.`, len(rc))
+	}
+
+	for i, r := range rc {
+		if r.start > r.last {
+			return fmt.Errorf(`Run container has an inverted run at %d.`, i)
+		}
+
+		// runs must be ordered and separated by at least one unset bit
+		if i > 0 && uint32(rc[i-1].last)+1 >= uint32(r.start) {
+			return fmt.Errorf(`Run container runs overlap at %d.`, i)
+		}
+	}
+
+	return nil
+}
+
+// optimize returns the smallest representation of the provided
+// container: an array, a bitmap or a list of runs.
+func optimize(c container) container {
+	card := c.cardinality()
+	runBytes := c.numRuns() * uint64(unsafe.Sizeof(run{}))
+	arrayBytes := card * uint64(unsafe.Sizeof(uint16(0)))
+
+	switch {
+	case runBytes < arrayBytes && runBytes < bitmapBytes:
+		if rc, ok := c.(runContainer); ok {
+			return rc
+		}
+		return c.toBitmap().toRuns()
+	case card <= arrayLimit:
+		if ac, ok := c.(arrayContainer); ok {
+			return ac
+		}
+		return c.toBitmap().toArray()
+	}
+
+	if bc, ok := c.(*bitmapContainer); ok {
+		return bc
+	}
+
+	return c.toBitmap()
+}
+
+// setOp is a bitwise operation applied container by container.
+type setOp int
+
+const (
+	orOp setOp = iota
+	andOp
+	xorOp
+	andNotOp
+)
+
+// keeps returns whether a bit set in one or both operands is set in
+// the result of this operation.
+func (op setOp) keeps(inA, inB bool) bool {
+	switch op {
+	case orOp:
+		return inA || inB
+	case andOp:
+		return inA && inB
+	case xorOp:
+		return inA != inB
+	}
+
+	return inA && !inB
+}
+
+func (op setOp) apply(a, b block) block {
+	switch op {
+	case orOp:
+		return a.or(b)
+	case andOp:
+		return a.and(b)
+	case xorOp:
+		return a.xor(b)
+	}
+
+	return a.andNot(b)
+}
+
+// applyOp applies the operation to a pair of containers and returns
+// the result in its smallest representation, or nil if it is empty.
+// Neither operand is modified.
+func applyOp(op setOp, a, b container) container {
+	aa, aok := a.(arrayContainer)
+	ba, bok := b.(arrayContainer)
+	switch {
+	case aok && bok:
+		return mergeArrays(op, aa, ba)
+	case bok && op == andOp:
+		// and is symmetric, so filter the array against the other
+		return applyOp(op, b, a)
+	case aok && (op == andOp || op == andNotOp):
+		result := make(arrayContainer, 0, len(aa))
+		for _, x := range aa {
+			if b.get(x) == (op == andOp) {
+				result = append(result, x)
+			}
+		}
+
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	}
+
+	result := a.toBitmap()
+	other := b.asBlocks()
+	result.card = 0
+	for i := range result.blocks {
+		result.blocks[i] = op.apply(result.blocks[i], other[i])
+		result.card += result.blocks[i].count()
+	}
+
+	if result.card == 0 {
+		return nil
+	}
+
+	return optimize(result)
+}
+
+// applyOpInPlace is applyOp but reuses the storage of a when it is a
+// bitmap, which must not be shared.
+func applyOpInPlace(op setOp, a, b container) container {
+	bc, ok := a.(*bitmapContainer)
+	if _, isArray := b.(arrayContainer); !ok || (isArray && op == andOp) {
+		return applyOp(op, a, b)
+	}
+
+	other := b.asBlocks()
+	bc.card = 0
+	for i := range bc.blocks {
+		bc.blocks[i] = op.apply(bc.blocks[i], other[i])
+		bc.card += bc.blocks[i].count()
+	}
+
+	if bc.card == 0 {
+		return nil
+	}
+
+	return optimize(bc)
+}
+
+func mergeArrays(op setOp, a, b arrayContainer) container {
+	result := make(arrayContainer, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			if op.keeps(true, false) {
+				result = append(result, a[i])
+			}
+			i++
+		case i == len(a) || b[j] < a[i]:
+			if op.keeps(false, true) {
+				result = append(result, b[j])
+			}
+			j++
+		default:
+			if op.keeps(true, true) {
+				result = append(result, a[i])
+			}
+			i++
+			j++
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return optimize(result)
+}
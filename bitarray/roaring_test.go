@@ -0,0 +1,287 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoaringBitOperations(t *testing.T) {
+	rba := newRoaringBitArray()
+
+	for _, k := range []uint64{5, 1 << 40, 70000} {
+		assert.Nil(t, rba.SetBit(k))
+	}
+
+	result, err := rba.GetBit(70000)
+	assert.Nil(t, err)
+	assert.True(t, result)
+	result, err = rba.GetBit(6)
+	assert.Nil(t, err)
+	assert.False(t, result)
+
+	assert.Equal(t, []uint64{5, 70000, 1 << 40}, rba.ToNums())
+	assert.Equal(t, uint64(3), rba.Count())
+	assert.Equal(t, uint64(1<<40+s), rba.Capacity())
+
+	assert.Nil(t, rba.ClearBit(70000))
+	assert.Nil(t, rba.ClearBit(70001))
+	assert.Equal(t, []uint64{5, 1 << 40}, rba.ToNums())
+	assert.Len(t, rba.keys, 2)
+	assert.Nil(t, rba.Validate())
+
+	rba.Reset()
+	assert.Len(t, rba.ToNums(), 0)
+	assert.Equal(t, uint64(0), rba.Capacity())
+}
+
+func TestRoaringContainerConversions(t *testing.T) {
+	rba := newRoaringBitArray()
+
+	// every other bit stays an array until it outgrows one
+	for i := uint64(0); i <= 2*arrayLimit; i += 2 {
+		rba.SetBit(i)
+	}
+	assert.IsType(t, &bitmapContainer{}, rba.containers[0])
+	assert.Nil(t, rba.Validate())
+
+	rba.ClearBit(0)
+	assert.IsType(t, arrayContainer{}, rba.containers[0])
+	assert.Nil(t, rba.Validate())
+
+	// a long run of consecutive bits becomes a run container
+	rba.Reset()
+	for i := uint64(0); i <= arrayLimit; i++ {
+		rba.SetBit(i)
+	}
+	assert.IsType(t, runContainer{}, rba.containers[0])
+	assert.Nil(t, rba.Validate())
+
+	rba.ClearBit(100)
+	assert.Equal(t, runContainer{{0, 99}, {101, uint16(arrayLimit)}}, rba.containers[0])
+	rba.SetBit(100)
+	assert.Equal(t, runContainer{{0, uint16(arrayLimit)}}, rba.containers[0])
+	assert.Nil(t, rba.Validate())
+}
+
+func TestRoaringClustered(t *testing.T) {
+	rba := newRoaringBitArray()
+	sba := newSparseBitArray()
+	for i := uint64(0); i < 1<<20; i++ {
+		rba.SetBit(i)
+		sba.SetBit(i)
+	}
+
+	assert.Len(t, rba.containers, 16)
+	assert.True(t, rba.SizeOf() < sba.SizeOf()/100)
+	assert.True(t, rba.Equals(sba))
+	assert.True(t, sba.Equals(rba))
+}
+
+func randomBitArrays(r *rand.Rand, n int) (BitArray, BitArray, map[uint64]bool) {
+	rba := newRoaringBitArray()
+	sba := newSparseBitArray()
+	model := make(map[uint64]bool)
+	for i := 0; i < n; i++ {
+		// mix scattered bits, dense chunks and long runs
+		var k uint64
+		switch r.Intn(3) {
+		case 0:
+			k = uint64(r.Int63n(1 << 22))
+		case 1:
+			k = 1<<17 + uint64(r.Intn(1<<16))
+		default:
+			k = 3<<16 + uint64(i%20000)
+		}
+
+		if r.Intn(5) == 0 {
+			rba.ClearBit(k)
+			sba.ClearBit(k)
+			delete(model, k)
+			continue
+		}
+
+		rba.SetBit(k)
+		sba.SetBit(k)
+		model[k] = true
+	}
+
+	return rba, sba, model
+}
+
+func sortedNums(model map[uint64]bool) []uint64 {
+	nums := make([]uint64, 0, len(model))
+	for k := range model {
+		nums = append(nums, k)
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+
+	return nums
+}
+
+func TestRoaringMatchesSparse(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	rba, sba, model := randomBitArrays(r, 100000)
+	other, otherSparse, _ := randomBitArrays(r, 100000)
+
+	assert.Nil(t, rba.Validate())
+	assert.Equal(t, sortedNums(model), rba.ToNums())
+	assert.Equal(t, uint64(len(model)), rba.Count())
+	assert.True(t, rba.Equals(sba))
+
+	assert.Equal(t, sba.Or(otherSparse).ToNums(), rba.Or(other).ToNums())
+	assert.Equal(t, sba.And(otherSparse).ToNums(), rba.And(other).ToNums())
+	assert.Equal(t, sba.AndNot(otherSparse).ToNums(), rba.AndNot(other).ToNums())
+	assert.Equal(t, sba.Xor(otherSparse).ToNums(), rba.Xor(other).ToNums())
+	for _, result := range []BitArray{rba.Or(other), rba.And(other),
+		rba.AndNot(other), rba.Xor(other)} {
+		assert.Nil(t, result.Validate())
+	}
+
+	// mixed operands are converted on the fly
+	assert.True(t, rba.Or(otherSparse).Equals(sba.Or(other)))
+	assert.True(t, rba.Xor(otherSparse).Equals(sba.Xor(other)))
+
+	for _, k := range []uint64{0, 1 << 16, 1<<17 + 500, 3<<16 + 19999, 1 << 22} {
+		assert.Equal(t, sba.Rank(k), rba.Rank(k))
+	}
+	for _, n := range []uint64{1, 1000, rba.Count()} {
+		expected, _ := sba.Select(n)
+		result, err := rba.Select(n)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result)
+	}
+	_, err := rba.Select(rba.Count() + 1)
+	assert.IsType(t, OutOfRangeError(0), err)
+}
+
+func TestRoaringIntersects(t *testing.T) {
+	rba := newRoaringBitArray()
+	for i := uint64(0); i < 10000; i++ {
+		rba.SetBit(i * 3)
+	}
+
+	subset := newRoaringBitArray()
+	subset.SetBit(3)
+	subset.SetBit(29997)
+	assert.True(t, rba.Intersects(subset))
+	assert.True(t, rba.Intersects(newSparseBitArray()))
+
+	dense := newBitArray(100)
+	dense.SetBit(3)
+	assert.True(t, rba.Intersects(dense))
+	dense.SetBit(4)
+	assert.False(t, rba.Intersects(dense))
+
+	subset.SetBit(1 << 20)
+	assert.False(t, rba.Intersects(subset))
+}
+
+func TestRoaringSnapshot(t *testing.T) {
+	rba := newRoaringBitArray()
+	rba.SetBit(1)
+	rba.SetBit(1 << 20)
+
+	snapshot := rba.Snapshot()
+	rba.SetBit(2)
+	rba.ClearBit(1 << 20)
+
+	assert.Equal(t, []uint64{1, 2}, rba.ToNums())
+	assert.Equal(t, []uint64{1, 1 << 20}, snapshot.ToNums())
+	assert.Nil(t, snapshot.Validate())
+}
+
+func TestDenseWithRoaringBitArray(t *testing.T) {
+	dba := newBitArray(200)
+	dba.SetBit(1)
+	dba.SetBit(100)
+	rba := newRoaringBitArray()
+	rba.SetBit(100)
+	rba.SetBit(150)
+
+	assert.Equal(t, []uint64{1, 100, 150}, dba.Or(rba).ToNums())
+	assert.Equal(t, []uint64{100}, dba.And(rba).ToNums())
+	assert.Equal(t, []uint64{1}, dba.AndNot(rba).ToNums())
+	assert.Equal(t, []uint64{1, 150}, dba.Xor(rba).ToNums())
+	assert.True(t, dba.Or(rba).Equals(rba.Or(dba)))
+}
+
+func BenchmarkRoaringAnd(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	rba, _, _ := randomBitArrays(r, 100000)
+	other, _, _ := randomBitArrays(r, 100000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rba.And(other)
+	}
+}
+
+func BenchmarkSparseAnd(b *testing.B) {
+	r := rand.New(rand.NewSource(42))
+	_, sba, _ := randomBitArrays(r, 100000)
+	_, other, _ := randomBitArrays(r, 100000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sba.And(other)
+	}
+}
+
+func TestRoaringNot(t *testing.T) {
+	rba := newRoaringBitArray()
+	rba.SetBit(5)
+	rba.SetBit(1 << 20)
+
+	// a huge universe is held as runs
+	const max = 1 << 28
+	result := rba.Not(max)
+	assert.Nil(t, result.Validate())
+	assert.Equal(t, uint64(max-2), result.Count())
+	assert.True(t, result.SizeOf() < 1<<20)
+	assert.Equal(t, []uint64{5, 1 << 20}, result.Not(max).ToNums())
+
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4, 6}, rba.Not(7).ToNums())
+	assert.Equal(t, uint64(0), rba.Not(0).Count())
+}
+
+func TestNotMatchesAcrossImplementations(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 20; i++ {
+		dense, sparse, roaring := newBitArray(1000), newSparseBitArray(), newRoaringBitArray()
+		for j := 0; j < r.Intn(500); j++ {
+			k := uint64(r.Intn(1000))
+			dense.SetBit(k)
+			sparse.SetBit(k)
+			roaring.SetBit(k)
+		}
+
+		max := uint64(r.Intn(1200))
+		expected := dense.Not(max).ToNums()
+		assert.Equal(t, expected, sparse.Not(max).ToNums())
+		assert.Equal(t, expected, roaring.Not(max).ToNums())
+
+		below := uint64(0)
+		if max > 0 {
+			below = dense.Rank(max - 1)
+		}
+		assert.Equal(t, max-below, uint64(len(expected)))
+	}
+}
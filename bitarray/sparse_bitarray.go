@@ -16,7 +16,12 @@ limitations under the License.
 
 package bitarray
 
-import "sort"
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"unsafe"
+)
 
 // uintSlice is an alias for a slice of ints.  Len, Swap, and Less
 // are exported to fulfill an interface needed for the search
@@ -102,10 +107,14 @@ func (b *blocks) deleteAtIndex(i int64) {
 type sparseBitArray struct {
 	blocks  blocks
 	indices uintSlice
+	// shared indicates that blocks and indices are also referenced
+	// by a snapshot and must be copied before they are mutated.
+	shared bool
 }
 
 // SetBit sets the bit at the given position.
 func (sba *sparseBitArray) SetBit(k uint64) error {
+	sba.unshare()
 	index, position := getIndexAndRemainder(k)
 	i, inserted := sba.indices.insert(index)
 	if inserted {
@@ -143,6 +152,63 @@ func (sba *sparseBitArray) ToNums() []uint64 {
 	return nums
 }
 
+// Rank returns the number of set bits at or before the given
+// position.
+func (sba *sparseBitArray) Rank(k uint64) uint64 {
+	return rank(sba.Blocks(), k)
+}
+
+// Select returns the position of the n-th set bit, counting from one.
+func (sba *sparseBitArray) Select(n uint64) (uint64, error) {
+	return selectBit(sba.Blocks(), n)
+}
+
+// NextSet returns the first set bit at or after the given position.
+func (sba *sparseBitArray) NextSet(k uint64) (uint64, bool) {
+	index, position := getIndexAndRemainder(k)
+	for i := sba.indices.search(index); i < int64(len(sba.indices)); i++ {
+		if sba.indices[i] != index {
+			position = 0
+		}
+
+		if p, ok := sba.blocks[i].nextSet(position); ok {
+			return sba.indices[i]*s + p, true
+		}
+	}
+
+	return 0, false
+}
+
+// PrevSet returns the last set bit at or before the given position.
+func (sba *sparseBitArray) PrevSet(k uint64) (uint64, bool) {
+	index, position := getIndexAndRemainder(k)
+	for i := sba.indices.search(index+1) - 1; i >= 0; i-- {
+		if sba.indices[i] != index {
+			position = s - 1
+		}
+
+		if p, ok := sba.blocks[i].prevSet(position); ok {
+			return sba.indices[i]*s + p, true
+		}
+	}
+
+	return 0, false
+}
+
+// Iterate calls fn with every set bit in ascending order until fn
+// returns false.
+func (sba *sparseBitArray) Iterate(fn func(uint64) bool) {
+	iterate(sba.Blocks(), fn)
+}
+
+// All returns a sequence of the position of every set bit in
+// ascending order for use with range.
+func (sba *sparseBitArray) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		sba.Iterate(yield)
+	}
+}
+
 // ClearBit clears the bit at the given position.
 func (sba *sparseBitArray) ClearBit(k uint64) error {
 	index, position := getIndexAndRemainder(k)
@@ -151,6 +217,7 @@ func (sba *sparseBitArray) ClearBit(k uint64) error {
 		return nil
 	}
 
+	sba.unshare()
 	sba.blocks[i] = sba.blocks[i].remove(position)
 	if sba.blocks[i] == 0 {
 		sba.blocks.deleteAtIndex(i)
@@ -162,10 +229,23 @@ func (sba *sparseBitArray) ClearBit(k uint64) error {
 
 // Reset erases all values from this bitarray.
 func (sba *sparseBitArray) Reset() {
+	if sba.shared {
+		sba.blocks, sba.indices = nil, nil
+		sba.shared = false
+		return
+	}
+
 	sba.blocks = sba.blocks[:0]
 	sba.indices = sba.indices[:0]
 }
 
+// SizeOf returns an estimate of the number of bytes used by this
+// bit array, including both its blocks and their indices.
+func (sba *sparseBitArray) SizeOf() uint64 {
+	return uint64(unsafe.Sizeof(*sba)) + uint64(cap(sba.blocks))*blockSize +
+		uint64(cap(sba.indices))*uint64(unsafe.Sizeof(uint64(0)))
+}
+
 // Blocks returns an iterator to iterator of this bitarray's blocks.
 func (sba *sparseBitArray) Blocks() Iterator {
 	return newCompressedBitArrayIterator(sba)
@@ -182,51 +262,22 @@ func (sba *sparseBitArray) Capacity() uint64 {
 }
 
 // Equals returns a bool indicating if the provided bit array
-// equals this bitarray.
+// has the same bits set as this bitarray.
 func (sba *sparseBitArray) Equals(other BitArray) bool {
-	if other.Capacity() == 0 && sba.Capacity() > 0 {
-		return false
-	}
-
-	var selfIndex uint64
-	for iter := other.Blocks(); iter.Next(); {
-		otherIndex, otherBlock := iter.Value()
-		if len(sba.indices) == 0 {
-			if otherBlock > 0 {
-				return false
-			}
-
-			continue
-		}
-
-		if selfIndex >= uint64(len(sba.indices)) {
-			return false
-		}
-
-		if otherIndex < sba.indices[selfIndex] {
-			if otherBlock > 0 {
-				return false
-			}
-			continue
-		}
-
-		if otherIndex > sba.indices[selfIndex] {
-			return false
-		}
-
-		if !sba.blocks[selfIndex].equals(otherBlock) {
-			return false
-		}
-
-		selfIndex++
-	}
+	return blocksEqual(sba.Blocks(), other.Blocks())
+}
 
-	return true
+// Hash returns a hash of the set bits that is the same for any equal
+// bit array.
+func (sba *sparseBitArray) Hash() uint64 {
+	return hashBlocks(sba.Blocks())
 }
 
 // Or will perform a bitwise or operation with the provided bitarray and
 // return a new result bitarray.
 func (sba *sparseBitArray) Or(other BitArray) BitArray {
+	other = fromRoaring(other)
+
 	if ba, ok := other.(*sparseBitArray); ok {
 		return orSparseWithSparseBitArray(sba, ba)
 	}
@@ -237,6 +288,8 @@ func (sba *sparseBitArray) Or(other BitArray) BitArray {
 // And will perform a bitwise and operation with the provided bitarray and
 // return a new result bitarray.
 func (sba *sparseBitArray) And(other BitArray) BitArray {
+	other = fromRoaring(other)
+
 	if ba, ok := other.(*sparseBitArray); ok {
 		return andSparseWithSparseBitArray(sba, ba)
 	}
@@ -244,6 +297,138 @@ func (sba *sparseBitArray) And(other BitArray) BitArray {
 	return andSparseWithDenseBitArray(sba, other.(*bitArray))
 }
 
+// AndNot will clear the bits set in the provided bitarray from a
+// copy of this bitarray and return the result.
+func (sba *sparseBitArray) AndNot(other BitArray) BitArray {
+	other = fromRoaring(other)
+
+	if ba, ok := other.(*sparseBitArray); ok {
+		return andNotSparseWithSparseBitArray(sba, ba)
+	}
+
+	return andNotSparseWithDenseBitArray(sba, other.(*bitArray))
+}
+
+// Not returns a new sparse bitarray with every bit in [0, max) that
+// is clear in this bitarray set.  Every block below max that isn't
+// full in this bitarray is stored in the result.
+func (sba *sparseBitArray) Not(max uint64) BitArray {
+	n, r := getIndexAndRemainder(max)
+	if r > 0 {
+		n++
+	}
+
+	result := newSparseBitArray()
+	j := 0
+	for i := uint64(0); i < n; i++ {
+		block := maximumBlock
+		if j < len(sba.indices) && sba.indices[j] == i {
+			block = ^sba.blocks[j]
+			j++
+		}
+		if i == n-1 && r > 0 {
+			block &= maximumBlock >> (s - r)
+		}
+
+		if block != 0 {
+			result.indices = append(result.indices, i)
+			result.blocks = append(result.blocks, block)
+		}
+	}
+
+	return result
+}
+
+// Xor will perform a bitwise xor operation with the provided bitarray
+// and return a new result bitarray.
+func (sba *sparseBitArray) Xor(other BitArray) BitArray {
+	other = fromRoaring(other)
+
+	if ba, ok := other.(*sparseBitArray); ok {
+		return xorSparseWithSparseBitArray(sba, ba)
+	}
+
+	return xorSparseWithDenseBitArray(sba, other.(*bitArray))
+}
+
+// Count returns the number of set bits.
+func (sba *sparseBitArray) Count() uint64 {
+	count := uint64(0)
+	for _, block := range sba.blocks {
+		count += block.count()
+	}
+
+	return count
+}
+
+// OrInPlace will bitwise or the provided bitarray into this one.
+func (sba *sparseBitArray) OrInPlace(other BitArray) {
+	if other == BitArray(sba) {
+		other = sba.Snapshot()
+	}
+
+	indices, blocks := sparseBlocks(other)
+	sba.merge(indices, blocks, block.or)
+}
+
+// AndInPlace will bitwise and the provided bitarray into this one.
+func (sba *sparseBitArray) AndInPlace(other BitArray) {
+	if other == BitArray(sba) {
+		other = sba.Snapshot()
+	}
+
+	indices, blocks := sparseBlocks(other)
+	sba.intersect(indices, blocks, block.and)
+}
+
+// AndNotInPlace will clear the bits set in the provided bitarray from
+// this one.
+func (sba *sparseBitArray) AndNotInPlace(other BitArray) {
+	if other == BitArray(sba) {
+		other = sba.Snapshot()
+	}
+
+	indices, blocks := sparseBlocks(other)
+	sba.intersect(indices, blocks, block.andNot)
+}
+
+// XorInPlace will bitwise xor the provided bitarray into this one.
+func (sba *sparseBitArray) XorInPlace(other BitArray) {
+	if other == BitArray(sba) {
+		other = sba.Snapshot()
+	}
+
+	indices, blocks := sparseBlocks(other)
+	sba.merge(indices, blocks, block.xor)
+}
+
+// SetRange sets every bit in [start, stop).
+func (sba *sparseBitArray) SetRange(start, stop uint64) error {
+	indices, blocks := rangeBlocks(start, stop)
+	sba.merge(indices, blocks, block.or)
+	return nil
+}
+
+// ClearRange clears every bit in [start, stop).
+func (sba *sparseBitArray) ClearRange(start, stop uint64) error {
+	indices, blocks := rangeBlocks(start, stop)
+	sba.intersect(indices, blocks, block.andNot)
+	return nil
+}
+
+// ShiftLeft moves every bit n positions higher.
+func (sba *sparseBitArray) ShiftLeft(n uint64) {
+	sba.indices, sba.blocks = shiftBlocks(sba.Blocks(), n, true)
+	sba.shared = false
+}
+
+// ShiftRight moves every bit n positions lower, dropping bits moved
+// below zero.
+func (sba *sparseBitArray) ShiftRight(n uint64) {
+	sba.indices, sba.blocks = shiftBlocks(sba.Blocks(), n, false)
+	sba.shared = false
+}
+
 func (sba *sparseBitArray) copy() *sparseBitArray {
 	blocks := make(blocks, len(sba.blocks))
 	copy(blocks, sba.blocks)
@@ -255,9 +440,51 @@ func (sba *sparseBitArray) copy() *sparseBitArray {
 	}
 }
 
+// unshare copies this bit array's blocks and indices if they
+// are referenced by a snapshot.  Must be called before any mutation.
+func (sba *sparseBitArray) unshare() {
+	if !sba.shared {
+		return
+	}
+
+	cp := sba.copy()
+	sba.blocks, sba.indices = cp.blocks, cp.indices
+	sba.shared = false
+}
+
+// Snapshot returns a point-in-time copy of this bit array.  Blocks
+// are shared with the copy until either bit array is modified.
+func (sba *sparseBitArray) Snapshot() BitArray {
+	sba.shared = true
+	return &sparseBitArray{
+		blocks:  sba.blocks,
+		indices: sba.indices,
+		shared:  true,
+	}
+}
+
+// Validate checks that there is exactly one block per index and that
+// indices are strictly increasing, returning an error if not.
+func (sba *sparseBitArray) Validate() error {
+	if len(sba.indices) != len(sba.blocks) {
+		return fmt.Errorf(`Sparse bit array has %d indices and %d blocks.`,
+			len(sba.indices), len(sba.blocks))
+	}
+
+	for i := 1; i < len(sba.indices); i++ {
+		if sba.indices[i-1] >= sba.indices[i] {
+			return fmt.Errorf(`Sparse bit array indices out of order at %d.`, i)
+		}
+	}
+
+	return nil
+}
+
 // Intersects returns a bool indicating if the provided bit array
 // intersects with this bitarray.
 func (sba *sparseBitArray) Intersects(other BitArray) bool {
+	other = fromRoaring(other)
+
 	if other.Capacity() == 0 {
 		return true
 	}
@@ -295,6 +295,53 @@ func TestSparseBitArrayToNums(t *testing.T) {
 	assert.Equal(t, expected, results)
 }
 
+func TestSnapshotSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	sba.SetBit(s + 1)
+
+	snapshot := sba.Snapshot()
+	sba.SetBit(1)
+	sba.ClearBit(s + 1)
+
+	assert.Equal(t, []uint64{s + 1}, snapshot.ToNums())
+	assert.Equal(t, []uint64{1}, sba.ToNums())
+
+	sba.Reset()
+	snapshot.SetBit(s * 3)
+	assert.Equal(t, []uint64{s + 1, s * 3}, snapshot.ToNums())
+	assert.Nil(t, sba.ToNums())
+}
+
+func TestValidateSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	assert.Nil(t, sba.Validate())
+
+	sba.SetBit(s * 5)
+	sba.SetBit(3)
+	sba.SetBit(s*2 + 1)
+	assert.Nil(t, sba.Validate())
+
+	sba.ClearBit(3)
+	assert.Nil(t, sba.Validate())
+
+	sba.indices[0], sba.indices[1] = sba.indices[1], sba.indices[0]
+	assert.NotNil(t, sba.Validate())
+	sba.indices[0], sba.indices[1] = sba.indices[1], sba.indices[0]
+
+	sba.blocks = sba.blocks[:1]
+	assert.NotNil(t, sba.Validate())
+}
+
+func TestSizeOfSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	empty := sba.SizeOf()
+
+	sba.SetBit(s * 100)
+	sba.SetBit(1)
+	assert.True(t, sba.SizeOf() >= empty+2*(blockSize+8))
+	assert.True(t, sba.SizeOf() < newBitArray(s*100).SizeOf())
+}
+
 func BenchmarkSparseBitArrayToNums(b *testing.B) {
 	numItems := uint64(1000)
 	sba := newSparseBitArray()
@@ -308,3 +355,30 @@ func BenchmarkSparseBitArrayToNums(b *testing.B) {
 		sba.ToNums()
 	}
 }
+
+func TestNotSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	sba.SetBit(1)
+	sba.SetBit(s * 3)
+	assert.Nil(t, sba.SetRange(s, s*2))
+
+	result := sba.Not(s*3 + 2)
+	assert.Nil(t, result.Validate())
+	assert.Equal(t, s*3+2-(s+2), result.Count())
+	get := func(k uint64) bool {
+		set, _ := result.GetBit(k)
+		return set
+	}
+	assert.True(t, get(0))
+	assert.False(t, get(1))
+	assert.False(t, get(s))
+	assert.True(t, get(s*2))
+	assert.False(t, get(s*3))
+	assert.True(t, get(s*3+1))
+	assert.False(t, get(s*3+2))
+
+	// the full block is left out of the result
+	assert.Len(t, result.(*sparseBitArray).indices, 3)
+	assert.Equal(t, sba.ToNums(), result.Not(s*3+2).ToNums())
+	assert.Equal(t, uint64(0), sba.Not(0).Count())
+}
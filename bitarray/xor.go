@@ -0,0 +1,89 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+func xorSparseWithSparseBitArray(sba, other *sparseBitArray) BitArray {
+	max := len(sba.indices) + len(other.indices)
+	indices := make(uintSlice, 0, max)
+	blocks := make(blocks, 0, max)
+
+	selfIndex := 0
+	otherIndex := 0
+	for selfIndex < len(sba.indices) || otherIndex < len(other.indices) {
+		switch {
+		case otherIndex == len(other.indices) ||
+			(selfIndex < len(sba.indices) && sba.indices[selfIndex] < other.indices[otherIndex]):
+			indices = append(indices, sba.indices[selfIndex])
+			blocks = append(blocks, sba.blocks[selfIndex])
+			selfIndex++
+		case selfIndex == len(sba.indices) ||
+			other.indices[otherIndex] < sba.indices[selfIndex]:
+			indices = append(indices, other.indices[otherIndex])
+			blocks = append(blocks, other.blocks[otherIndex])
+			otherIndex++
+		default:
+			// Matching blocks that cancel out entirely are dropped
+			// so the result stays sparse.
+			result := sba.blocks[selfIndex].xor(other.blocks[otherIndex])
+			if result != 0 {
+				indices = append(indices, sba.indices[selfIndex])
+				blocks = append(blocks, result)
+			}
+			selfIndex++
+			otherIndex++
+		}
+	}
+
+	return &sparseBitArray{
+		indices: indices,
+		blocks:  blocks,
+	}
+}
+
+func xorSparseWithDenseBitArray(sba *sparseBitArray, other *bitArray) BitArray {
+	numBlocks := uint64(len(other.blocks))
+	if len(sba.indices) > 0 {
+		numBlocks = maxUint64(numBlocks, sba.indices[len(sba.indices)-1]+1)
+	}
+
+	ba := newBitArray(numBlocks * s)
+	copy(ba.blocks, other.blocks)
+	for i, index := range sba.indices {
+		ba.blocks[index] = ba.blocks[index].xor(sba.blocks[i])
+	}
+
+	ba.setLowest()
+	ba.setHighest()
+
+	return ba
+}
+
+func xorDenseWithDenseBitArray(dba, other *bitArray) BitArray {
+	if len(dba.blocks) < len(other.blocks) {
+		dba, other = other, dba
+	}
+
+	ba := newBitArray(uint64(len(dba.blocks)) * s)
+	copy(ba.blocks, dba.blocks)
+	for i, block := range other.blocks {
+		ba.blocks[i] = ba.blocks[i].xor(block)
+	}
+
+	ba.setLowest()
+	ba.setHighest()
+
+	return ba
+}
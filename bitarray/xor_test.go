@@ -0,0 +1,74 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXorSparseWithSparseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	other := newSparseBitArray()
+
+	sba.SetBit(3)
+	other.SetBit(4)
+	// bits set in both cancel out, emptying the block at 2680
+	sba.SetBit(2680)
+	other.SetBit(2680)
+	other.SetBit(5000)
+
+	ba := xorSparseWithSparseBitArray(sba, other)
+
+	assert.Equal(t, []uint64{3, 4, 5000}, ba.ToNums())
+	assert.Len(t, ba.(*sparseBitArray).indices, 2)
+	assert.Nil(t, ba.Validate())
+	assert.Equal(t, []uint64{3, 2680}, sba.ToNums())
+}
+
+func TestXorSparseWithDenseBitArray(t *testing.T) {
+	sba := newSparseBitArray()
+	other := newBitArray(200)
+
+	sba.SetBit(1)
+	other.SetBit(1)
+	other.SetBit(151)
+	// beyond the dense array's capacity
+	sba.SetBit(500)
+
+	ba := xorSparseWithDenseBitArray(sba, other)
+
+	assert.Equal(t, []uint64{151, 500}, ba.ToNums())
+	assert.Nil(t, ba.Validate())
+}
+
+func TestXorDenseWithDenseBitArray(t *testing.T) {
+	dba := newBitArray(100)
+	other := newBitArray(300)
+
+	dba.SetBit(1)
+	dba.SetBit(2)
+	other.SetBit(2)
+	other.SetBit(299)
+
+	ba := xorDenseWithDenseBitArray(dba, other)
+
+	assert.Equal(t, []uint64{1, 299}, ba.ToNums())
+	assert.Equal(t, uint64(2), ba.Count())
+	assert.Nil(t, ba.Validate())
+	assert.True(t, ba.Equals(other.Xor(dba)))
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/*
+Package bloom implements Bloom filters, approximate set membership
+structures that answer whether an item may have been added using a
+fixed number of bits regardless of the size of the items.  Each item
+sets k bits of an m bit array, chosen by double hashing, and an item
+is reported as present if all of its bits are set.  False positives
+are possible, false negatives are not.
+
+Filters are sized from the number of items expected and the desired
+false positive rate.  Adding more items than expected raises the false
+positive rate above the one requested; FalsePositiveRate estimates the
+current rate from the fraction of bits set.
+
+A counting filter keeps a small counter in place of every bit so items
+can be removed again.  Counters saturate at 255 and are then never
+decremented, so removal can't cause false negatives.
+
+Filters of the same size can be unioned, which makes them useful for
+aggregating membership across machines, and can be serialized.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: m = -n ln(p) / ln(2)^2 bits, or bytes for a counting filter
+Add: O(k)
+Contains: O(k)
+Remove: O(k)
+Union: O(m)
+*/
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+const filterSize = uint64(unsafe.Sizeof(Filter{}))
+
+// Filter is a Bloom filter.
+type Filter struct {
+	bits bitarray.BitArray
+	m, k uint64
+}
+
+// hashes returns the two hashes of the provided item from which its
+// bit positions are derived.
+func hashes(data []byte) (uint64, uint64) {
+	hash := fnv.New64a()
+	hash.Write(data)
+	h := hash.Sum64()
+
+	// the second hash must be odd so it can't cycle through a
+	// small subset of positions
+	return hashutil.Mix(h), hashutil.Mix(h^0x9e3779b97f4a7c15) | 1
+}
+
+// location returns the i-th of an item's k bit positions, given its
+// hashes, in a filter of m bits.
+func location(h1, h2, i, m uint64) uint64 {
+	return (h1 + i*h2) % m
+}
+
+// estimate returns the number of bits and hashes needed to hold n
+// items with a false positive rate of p.
+func estimate(n uint64, p float64) (uint64, uint64) {
+	if n == 0 || p <= 0 || p >= 1 {
+		panic(`Invalid number of items or false positive rate provided.`)
+	}
+
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(n)*math.Ln2))
+	return uint64(m), uint64(k)
+}
+
+// falsePositiveRate returns the chance that an item which was never
+// added is reported as present, given the number of bits set.
+func falsePositiveRate(set, m, k uint64) float64 {
+	return math.Pow(float64(set)/float64(m), float64(k))
+}
+
+// Add adds the provided item to the filter.
+func (f *Filter) Add(data []byte) {
+	h1, h2 := hashes(data)
+	for i := uint64(0); i < f.k; i++ {
+		f.bits.SetBit(location(h1, h2, i, f.m))
+	}
+}
+
+// Contains returns a bool indicating if the provided item may have
+// been added to this filter.  False positives are possible, false
+// negatives are not.
+func (f *Filter) Contains(data []byte) bool {
+	h1, h2 := hashes(data)
+	for i := uint64(0); i < f.k; i++ {
+		if ok, _ := f.bits.GetBit(location(h1, h2, i, f.m)); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Union adds every item in other to this filter.  Both filters must
+// have the same number of bits and hashes, which is the case for
+// filters created with the same parameters.
+func (f *Filter) Union(other *Filter) error {
+	if other.m != f.m || other.k != f.k {
+		return MismatchError{m: f.m, k: f.k, otherM: other.m, otherK: other.k}
+	}
+
+	f.bits.OrInPlace(other.bits)
+	return nil
+}
+
+// Reset removes every item from the filter.
+func (f *Filter) Reset() {
+	f.bits.Reset()
+}
+
+// FalsePositiveRate estimates the current false positive rate of this
+// filter from the fraction of its bits that are set.
+func (f *Filter) FalsePositiveRate() float64 {
+	return falsePositiveRate(f.bits.Count(), f.m, f.k)
+}
+
+// Cap returns the number of bits in the filter.
+func (f *Filter) Cap() uint64 {
+	return f.m
+}
+
+// HashCount returns the number of bits set by each item.
+func (f *Filter) HashCount() uint64 {
+	return f.k
+}
+
+// SizeOf returns an estimate of the number of bytes used by this
+// filter.
+func (f *Filter) SizeOf() uint64 {
+	return filterSize + f.bits.SizeOf()
+}
+
+func newFilter(m, k uint64) *Filter {
+	return &Filter{
+		bits: bitarray.NewBitArray(m),
+		m:    m,
+		k:    k,
+	}
+}
+
+// New returns a filter sized to hold n items with a false positive
+// rate of p.  n must be positive and p must be between 0 and 1.
+func New(n uint64, p float64) *Filter {
+	return newFilter(estimate(n, p))
+}
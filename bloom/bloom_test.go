@@ -0,0 +1,124 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddContains(t *testing.T) {
+	f := New(100, 0.01)
+
+	assert.False(t, f.Contains([]byte(`a`)))
+	f.Add([]byte(`a`))
+	f.Add([]byte(`b`))
+
+	assert.True(t, f.Contains([]byte(`a`)))
+	assert.True(t, f.Contains([]byte(`b`)))
+	assert.False(t, f.Contains([]byte(`c`)))
+
+	f.Reset()
+	assert.False(t, f.Contains([]byte(`a`)))
+}
+
+func TestEstimate(t *testing.T) {
+	m, k := estimate(1000, 0.01)
+	assert.Equal(t, uint64(9586), m)
+	assert.Equal(t, uint64(7), k)
+
+	f := New(1000, 0.01)
+	assert.Equal(t, m, f.Cap())
+	assert.Equal(t, k, f.HashCount())
+
+	assert.Panics(t, func() { New(0, 0.01) })
+	assert.Panics(t, func() { New(10, 0) })
+	assert.Panics(t, func() { New(10, 1) })
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Contains([]byte(strconv.Itoa(i))))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Contains([]byte(`miss` + strconv.Itoa(i))) {
+			falsePositives++
+		}
+	}
+
+	// expected rate is about 1%
+	assert.True(t, falsePositives < 200, `%d false positives`, falsePositives)
+	estimated := f.FalsePositiveRate()
+	assert.True(t, estimated > 0.005 && estimated < 0.02, `estimated %f`, estimated)
+}
+
+func TestUnion(t *testing.T) {
+	f := New(100, 0.01)
+	other := New(100, 0.01)
+	f.Add([]byte(`a`))
+	other.Add([]byte(`b`))
+
+	assert.Nil(t, f.Union(other))
+	assert.True(t, f.Contains([]byte(`a`)))
+	assert.True(t, f.Contains([]byte(`b`)))
+	assert.False(t, other.Contains([]byte(`a`)))
+
+	err := f.Union(New(1000, 0.01))
+	assert.IsType(t, MismatchError{}, err)
+}
+
+func TestSizeOf(t *testing.T) {
+	f := New(1000, 0.01)
+	assert.True(t, f.SizeOf() > f.Cap()/8)
+}
+
+func BenchmarkAdd(b *testing.B) {
+	f := New(1000000, 0.01)
+	keys := make([][]byte, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, []byte(strconv.Itoa(i)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.Add(keys[i])
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	numItems := 100000
+	f := New(uint64(numItems), 0.01)
+	for i := 0; i < numItems; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	key := []byte(`5000`)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.Contains(key)
+	}
+}
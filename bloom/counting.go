@@ -0,0 +1,165 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+)
+
+const countingFilterSize = uint64(unsafe.Sizeof(CountingFilter{}))
+
+// maxCount is the value at which counters saturate.
+const maxCount = math.MaxUint8
+
+// CountingFilter is a Bloom filter that supports removal.  Alongside
+// its bits it keeps a counter of the items that set each one.
+type CountingFilter struct {
+	counters []uint8
+	bits     bitarray.BitArray
+	m, k     uint64
+}
+
+// Add adds the provided item to the filter.
+func (cf *CountingFilter) Add(data []byte) {
+	h1, h2 := hashes(data)
+	for i := uint64(0); i < cf.k; i++ {
+		loc := location(h1, h2, i, cf.m)
+		if cf.counters[loc] < maxCount {
+			cf.counters[loc]++
+		}
+		cf.bits.SetBit(loc)
+	}
+}
+
+// Contains returns a bool indicating if the provided item may have
+// been added to this filter.  False positives are possible, false
+// negatives are not.
+func (cf *CountingFilter) Contains(data []byte) bool {
+	h1, h2 := hashes(data)
+	for i := uint64(0); i < cf.k; i++ {
+		if cf.counters[location(h1, h2, i, cf.m)] == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Remove removes one occurrence of the provided item from the filter
+// and returns a bool indicating if it may have been present.
+// Removing an item that was never added may remove a different item
+// that shares its bits.
+func (cf *CountingFilter) Remove(data []byte) bool {
+	if !cf.Contains(data) {
+		return false
+	}
+
+	h1, h2 := hashes(data)
+	for i := uint64(0); i < cf.k; i++ {
+		loc := location(h1, h2, i, cf.m)
+		// a saturated counter no longer knows how many items
+		// it holds, so it is left alone
+		if cf.counters[loc] == maxCount {
+			continue
+		}
+
+		cf.counters[loc]--
+		if cf.counters[loc] == 0 {
+			cf.bits.ClearBit(loc)
+		}
+	}
+
+	return true
+}
+
+// Union adds every item in other to this filter.  Both filters must
+// have the same number of counters and hashes.
+func (cf *CountingFilter) Union(other *CountingFilter) error {
+	if other.m != cf.m || other.k != cf.k {
+		return MismatchError{m: cf.m, k: cf.k, otherM: other.m, otherK: other.k}
+	}
+
+	for i, count := range other.counters {
+		if sum := uint64(cf.counters[i]) + uint64(count); sum < maxCount {
+			cf.counters[i] = uint8(sum)
+		} else {
+			cf.counters[i] = maxCount
+		}
+	}
+
+	cf.bits.OrInPlace(other.bits)
+	return nil
+}
+
+// Filter returns a standard filter holding the items currently in
+// this filter.  The bits are shared until either filter is modified,
+// so this is an O(1) operation.
+func (cf *CountingFilter) Filter() *Filter {
+	return &Filter{
+		bits: cf.bits.Snapshot(),
+		m:    cf.m,
+		k:    cf.k,
+	}
+}
+
+// Reset removes every item from the filter.
+func (cf *CountingFilter) Reset() {
+	for i := range cf.counters {
+		cf.counters[i] = 0
+	}
+	cf.bits.Reset()
+}
+
+// FalsePositiveRate estimates the current false positive rate of this
+// filter from the fraction of its counters that are non-zero.
+func (cf *CountingFilter) FalsePositiveRate() float64 {
+	return falsePositiveRate(cf.bits.Count(), cf.m, cf.k)
+}
+
+// Cap returns the number of counters in the filter.
+func (cf *CountingFilter) Cap() uint64 {
+	return cf.m
+}
+
+// HashCount returns the number of counters incremented by each item.
+func (cf *CountingFilter) HashCount() uint64 {
+	return cf.k
+}
+
+// SizeOf returns an estimate of the number of bytes used by this
+// filter.
+func (cf *CountingFilter) SizeOf() uint64 {
+	return countingFilterSize + uint64(cap(cf.counters)) + cf.bits.SizeOf()
+}
+
+func newCountingFilter(m, k uint64) *CountingFilter {
+	return &CountingFilter{
+		counters: make([]uint8, m),
+		bits:     bitarray.NewBitArray(m),
+		m:        m,
+		k:        k,
+	}
+}
+
+// NewCounting returns a counting filter sized to hold n items with a
+// false positive rate of p.  n must be positive and p must be between
+// 0 and 1.
+func NewCounting(n uint64, p float64) *CountingFilter {
+	return newCountingFilter(estimate(n, p))
+}
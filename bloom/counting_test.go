@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountingAddRemove(t *testing.T) {
+	cf := NewCounting(100, 0.01)
+
+	cf.Add([]byte(`a`))
+	cf.Add([]byte(`a`))
+	cf.Add([]byte(`b`))
+	assert.True(t, cf.Contains([]byte(`a`)))
+
+	assert.True(t, cf.Remove([]byte(`a`)))
+	assert.True(t, cf.Contains([]byte(`a`)))
+	assert.True(t, cf.Remove([]byte(`a`)))
+	assert.False(t, cf.Contains([]byte(`a`)))
+	assert.False(t, cf.Remove([]byte(`a`)))
+	assert.True(t, cf.Contains([]byte(`b`)))
+
+	cf.Reset()
+	assert.False(t, cf.Contains([]byte(`b`)))
+	assert.Equal(t, 0.0, cf.FalsePositiveRate())
+}
+
+func TestCountingRemoveMany(t *testing.T) {
+	cf := NewCounting(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		cf.Add([]byte(strconv.Itoa(i)))
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		assert.True(t, cf.Remove([]byte(strconv.Itoa(i))))
+	}
+
+	for i := 1; i < 1000; i += 2 {
+		assert.True(t, cf.Contains([]byte(strconv.Itoa(i))))
+	}
+
+	for i := 1; i < 1000; i += 2 {
+		cf.Remove([]byte(strconv.Itoa(i)))
+	}
+	assert.Equal(t, 0.0, cf.FalsePositiveRate())
+}
+
+func TestCountingSaturates(t *testing.T) {
+	cf := NewCounting(10, 0.1)
+	for i := 0; i < maxCount+10; i++ {
+		cf.Add([]byte(`a`))
+	}
+
+	for i := 0; i < maxCount+10; i++ {
+		cf.Remove([]byte(`a`))
+	}
+
+	// saturated counters are never decremented
+	assert.True(t, cf.Contains([]byte(`a`)))
+}
+
+func TestCountingUnion(t *testing.T) {
+	cf := NewCounting(100, 0.01)
+	other := NewCounting(100, 0.01)
+	cf.Add([]byte(`a`))
+	other.Add([]byte(`a`))
+	other.Add([]byte(`b`))
+
+	assert.Nil(t, cf.Union(other))
+	assert.True(t, cf.Contains([]byte(`b`)))
+	cf.Remove([]byte(`a`))
+	assert.True(t, cf.Contains([]byte(`a`)))
+
+	assert.IsType(t, MismatchError{}, cf.Union(NewCounting(100, 0.001)))
+}
+
+func TestCountingFilter(t *testing.T) {
+	cf := NewCounting(100, 0.01)
+	cf.Add([]byte(`a`))
+
+	f := cf.Filter()
+	cf.Remove([]byte(`a`))
+	cf.Add([]byte(`b`))
+
+	assert.True(t, f.Contains([]byte(`a`)))
+	assert.False(t, f.Contains([]byte(`b`)))
+	assert.Equal(t, cf.Cap(), f.Cap())
+	assert.Equal(t, cf.HashCount(), f.HashCount())
+	assert.True(t, cf.SizeOf() > cf.Cap())
+}
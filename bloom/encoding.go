@@ -0,0 +1,143 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+)
+
+// Filters are serialized as a 24 byte header, holding a version byte,
+// a kind byte, six reserved zero bytes and the number of bits and
+// hashes as little-endian uint64s, followed by the filter's bits in
+// the bitarray package's format or, for a counting filter, one byte
+// per counter.
+
+// encodingVersion is the first header byte of a serialized filter.
+const encodingVersion = 1
+
+const (
+	filterEncoding byte = iota + 1
+	countingFilterEncoding
+)
+
+func writeHeader(w io.Writer, kind byte, m, k uint64) error {
+	header := make([]byte, 24)
+	header[0], header[1] = encodingVersion, kind
+	binary.LittleEndian.PutUint64(header[8:], m)
+	binary.LittleEndian.PutUint64(header[16:], k)
+	_, err := w.Write(header)
+	return err
+}
+
+func readHeader(r io.Reader, kind byte) (uint64, uint64, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, err
+	}
+
+	if header[0] != encodingVersion {
+		return 0, 0, fmt.Errorf(`Unknown encoding version %d.`, header[0])
+	}
+
+	if header[1] != kind {
+		return 0, 0, fmt.Errorf(`Unexpected filter kind %d.`, header[1])
+	}
+
+	m := binary.LittleEndian.Uint64(header[8:])
+	k := binary.LittleEndian.Uint64(header[16:])
+	if m == 0 || k == 0 || m > math.MaxInt64 {
+		return 0, 0, fmt.Errorf(`Invalid filter of %d bits and %d hashes.`, m, k)
+	}
+
+	return m, k, nil
+}
+
+// Serialize writes this filter to the provided writer.
+func (f *Filter) Serialize(w io.Writer) error {
+	if err := writeHeader(w, filterEncoding, f.m, f.k); err != nil {
+		return err
+	}
+
+	return f.bits.Serialize(w)
+}
+
+// Deserialize reads a filter written by Filter.Serialize.
+func Deserialize(r io.Reader) (*Filter, error) {
+	m, k, err := readHeader(r, filterEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	bits, err := bitarray.Deserialize(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if bits.Capacity() < m {
+		return nil, fmt.Errorf(`Filter of %d bits holds only %d bits.`, m, bits.Capacity())
+	}
+
+	return &Filter{bits: bits, m: m, k: k}, nil
+}
+
+// Serialize writes this filter to the provided writer.
+func (cf *CountingFilter) Serialize(w io.Writer) error {
+	if err := writeHeader(w, countingFilterEncoding, cf.m, cf.k); err != nil {
+		return err
+	}
+
+	_, err := w.Write(cf.counters)
+	return err
+}
+
+// DeserializeCounting reads a filter written by
+// CountingFilter.Serialize.
+func DeserializeCounting(r io.Reader) (*CountingFilter, error) {
+	m, k, err := readHeader(r, countingFilterEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	// the buffer grows as counters are read so a corrupt size can't
+	// cause a huge allocation
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(m)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	cf := &CountingFilter{
+		counters: buf.Bytes(),
+		bits:     bitarray.NewBitArray(m),
+		m:        m,
+		k:        k,
+	}
+	for i, count := range cf.counters {
+		if count > 0 {
+			cf.bits.SetBit(uint64(i))
+		}
+	}
+
+	return cf, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeFilter(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, f.Serialize(&buf))
+	data := buf.Bytes()
+
+	result, err := Deserialize(bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.Equal(t, f.Cap(), result.Cap())
+	assert.Equal(t, f.HashCount(), result.HashCount())
+	for i := 0; i < 500; i++ {
+		assert.True(t, result.Contains([]byte(strconv.Itoa(i))))
+	}
+	assert.Equal(t, f.FalsePositiveRate(), result.FalsePositiveRate())
+	assert.Nil(t, result.Union(f))
+
+	_, err = Deserialize(bytes.NewReader(data[:30]))
+	assert.NotNil(t, err)
+	_, err = DeserializeCounting(bytes.NewReader(data))
+	assert.NotNil(t, err)
+
+	bad := append([]byte{}, data...)
+	bad[0]++
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+
+	// claims more bits than it holds
+	bad = append([]byte{}, data...)
+	bad[9]++
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+}
+
+func TestSerializeCountingFilter(t *testing.T) {
+	cf := NewCounting(1000, 0.01)
+	cf.Add([]byte(`a`))
+	cf.Add([]byte(`a`))
+
+	var buf bytes.Buffer
+	assert.Nil(t, cf.Serialize(&buf))
+	data := buf.Bytes()
+
+	result, err := DeserializeCounting(bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.True(t, result.Contains([]byte(`a`)))
+	assert.True(t, result.Remove([]byte(`a`)))
+	assert.True(t, result.Contains([]byte(`a`)))
+	assert.True(t, result.Remove([]byte(`a`)))
+	assert.False(t, result.Contains([]byte(`a`)))
+	assert.Equal(t, 0.0, result.FalsePositiveRate())
+
+	_, err = DeserializeCounting(bytes.NewReader(data[:len(data)-1]))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, err = Deserialize(bytes.NewReader(data))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package bloom
+
+import "fmt"
+
+// MismatchError is returned when combining filters with different
+// numbers of bits or hashes.
+type MismatchError struct {
+	m, k, otherM, otherK uint64
+}
+
+func (me MismatchError) Error() string {
+	return fmt.Sprintf(`Cannot union a filter of %d bits and %d hashes into a filter of %d bits and %d hashes.`,
+		me.otherM, me.otherK, me.m, me.k)
+}
@@ -0,0 +1,807 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package disk implements a B+ tree stored in a file, for use as the
+core of a small embedded key-value store.  Keys and values are byte
+slices and keys are ordered by bytes.Compare.  The tree offers the
+operations of plus.Map, Put, Get, Delete, ordered iteration and Len,
+but only the nodes being used are held in memory and changes are
+written page by page rather than by serializing the whole tree.
+
+The file is divided into fixed-size pages, 4KB by default, each
+holding one node.  Recently used nodes are kept in a buffer pool of
+a configurable number of pages, which is an LRU cache.
+
+Writes are crash safe without a journal.  A node is never modified
+in place: changing it writes a copy to a free page, which changes its
+parent and so on up to the root.  Committing writes the new pages,
+flushes them to stable storage and then writes a small meta record
+naming the new root, alternating between two meta records at the
+start of the file.  If the machine crashes part way through a commit
+the old meta record still names the intact old tree, so the tree
+reopens as it was at the last completed commit.  Pages freed by a
+commit are only reused after the next commit, and the free pages are
+found again on opening by walking the internal nodes of the tree.
+
+By default every Put and Delete is committed before it returns.
+WithCommitPolicy(CommitManual) instead gathers changes until Commit
+is called, which is much faster for batches of writes and makes the
+batch atomic, and Rollback discards them.
+
+Keys and values are limited so that at least four entries fit in a
+page: with 4KB pages a key and its value may take about 1000 bytes.
+The tree is not threadsafe.  Only one Tree may use a file at a time.
+
+Performance characteristics:
+Get: O(log n) page reads, fewer when the pages are cached
+Put/Delete: O(log n) page writes per commit
+Commit: O(d) page writes where d is the number of nodes changed
+Open: O(n/b) page reads where b is the number of keys per page
+Space: O(n) on disk, O(c) pages in memory for a cache of c pages
+*/
+package disk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/Workiva/go-datastructures/cache"
+)
+
+const (
+	defaultPageSize  = 4096
+	minPageSize      = 1024
+	maxPageSize      = 64 << 10
+	defaultCacheSize = 1024
+)
+
+var (
+	// ErrClosed is returned when a closed tree is used.
+	ErrClosed = errors.New(`Tree is closed.`)
+	// ErrTooLarge is returned when a key or value is too large for
+	// the tree's page size.
+	ErrTooLarge = errors.New(`Key or value is too large for the page size.`)
+	// ErrCorrupt is returned when a page of the file fails its
+	// checksum or cannot be decoded.
+	ErrCorrupt = errors.New(`Tree file is corrupt.`)
+)
+
+// CommitPolicy determines when changes to a Tree are made durable.
+type CommitPolicy int
+
+const (
+	// CommitAlways commits every Put and Delete before it returns,
+	// so no acknowledged change is lost if the machine crashes.
+	CommitAlways CommitPolicy = iota
+	// CommitManual gathers changes in memory until Commit or Close
+	// is called.  Uncommitted changes are lost if the process stops
+	// and may be discarded with Rollback.
+	CommitManual
+)
+
+type config struct {
+	pageSize  int
+	cacheSize int
+	commit    CommitPolicy
+}
+
+// Option configures a Tree.
+type Option func(*config)
+
+// WithPageSize sets the size of the pages of a new file, which must
+// be between 1KB and 64KB.  An existing file keeps the page size it
+// was created with.  The default is 4KB.
+func WithPageSize(size int) Option {
+	return func(c *config) {
+		c.pageSize = size
+	}
+}
+
+// WithCacheSize sets the number of clean pages the buffer pool keeps
+// in memory.  Changed pages are kept until they are committed
+// regardless.  The default is 1024.
+func WithCacheSize(pages int) Option {
+	return func(c *config) {
+		c.cacheSize = pages
+	}
+}
+
+// WithCommitPolicy sets when changes are committed.  The default is
+// CommitAlways.
+func WithCommitPolicy(policy CommitPolicy) Option {
+	return func(c *config) {
+		c.commit = policy
+	}
+}
+
+// Tree is a B+ tree of byte slice keys and values stored in a file.
+type Tree struct {
+	file   *os.File
+	config config
+	// committed is the meta of the last commit and root and count
+	// describe the tree including uncommitted changes.
+	committed   meta
+	root, count uint64
+	pages       uint64
+	// free holds pages that no committed tree uses.  pending holds
+	// pages the last committed tree uses that uncommitted changes
+	// have freed, which become free once the changes are committed.
+	free, pending []uint64
+	// dirty holds the nodes changed since the last commit by their
+	// newly allocated pages.
+	dirty  map[uint64]*node
+	pool   *cache.Cache[uint64, *node]
+	closed bool
+}
+
+// Open opens the tree stored in the file at path, creating the file
+// if it does not exist.
+func Open(path string, opts ...Option) (*Tree, error) {
+	t := &Tree{
+		config: config{
+			pageSize:  defaultPageSize,
+			cacheSize: defaultCacheSize,
+			commit:    CommitAlways,
+		},
+		dirty: make(map[uint64]*node),
+	}
+	for _, opt := range opts {
+		opt(&t.config)
+	}
+
+	if t.config.pageSize < minPageSize || t.config.pageSize > maxPageSize {
+		return nil, fmt.Errorf(`Page size must be between %d and %d.`, minPageSize, maxPageSize)
+	}
+	if t.config.cacheSize < 1 {
+		return nil, fmt.Errorf(`Cache size must be greater than 0.`)
+	}
+	t.pool = cache.New(cache.Config[uint64, *node]{Capacity: uint64(t.config.cacheSize)})
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	t.file = file
+
+	if err := t.recover(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// recover reads the meta of the last commit, or initializes an empty
+// file, and finds the free pages.
+func (t *Tree) recover() error {
+	info, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() == 0 {
+		t.committed = meta{pageSize: uint32(t.config.pageSize), pages: 1}
+		for slot := 0; slot < 2; slot++ {
+			if err := t.writeMeta(slot); err != nil {
+				return err
+			}
+		}
+		if err := t.file.Sync(); err != nil {
+			return err
+		}
+	} else {
+		var buf [2 * metaSlot]byte
+		if _, err := t.file.ReadAt(buf[:], 0); err != nil {
+			return ErrCorrupt
+		}
+
+		first, firstOK := decodeMeta(buf[:])
+		second, secondOK := decodeMeta(buf[metaSlot:])
+		switch {
+		case firstOK && (!secondOK || first.txid > second.txid):
+			t.committed = first
+		case secondOK:
+			t.committed = second
+		default:
+			return ErrCorrupt
+		}
+		t.config.pageSize = int(t.committed.pageSize)
+	}
+
+	t.root, t.count, t.pages = t.committed.root, t.committed.count, t.committed.pages
+	return t.findFree()
+}
+
+// findFree fills the free list with the pages the committed tree
+// does not use.  Every leaf is at the same depth, so only internal
+// nodes are read, as the pages of leaves are known from their
+// parents.
+func (t *Tree) findFree() error {
+	used := make([]bool, t.pages)
+	used[0] = true
+	if err := t.markUsed(used, t.root, -1); err != nil {
+		return err
+	}
+
+	for id := len(used) - 1; id > 0; id-- {
+		if !used[id] {
+			t.free = append(t.free, uint64(id))
+		}
+	}
+
+	return nil
+}
+
+// markUsed marks the page and, if it is not a leaf, the pages below
+// it as used.  height is the number of levels below the page, or -1
+// if it is not yet known.
+func (t *Tree) markUsed(used []bool, id uint64, height int) error {
+	if id == 0 {
+		return nil
+	}
+	if id >= uint64(len(used)) || used[id] {
+		return ErrCorrupt
+	}
+	used[id] = true
+	if height == 0 {
+		return nil
+	}
+
+	n, err := t.load(id)
+	if err != nil {
+		return err
+	}
+	if height < 0 {
+		height = 0
+		for c := n; !c.leaf(); height++ {
+			if c, err = t.load(c.children[0]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, child := range n.children {
+		if err := t.markUsed(used, child, height-1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Tree) writeMeta(slot int) error {
+	var buf [metaSize]byte
+	t.committed.encode(buf[:])
+	_, err := t.file.WriteAt(buf[:], int64(slot)*metaSlot)
+	return err
+}
+
+// load returns the node stored in the page, which must not be
+// modified unless it is dirty.
+func (t *Tree) load(id uint64) (*node, error) {
+	if n, ok := t.dirty[id]; ok {
+		return n, nil
+	}
+	if n, ok := t.pool.Get(id); ok {
+		return n, nil
+	}
+
+	page := make([]byte, t.config.pageSize)
+	if _, err := t.file.ReadAt(page, int64(id)*int64(t.config.pageSize)); err != nil {
+		return nil, fmt.Errorf(`Reading page %d: %w`, id, err)
+	}
+
+	n, ok := decodeNode(id, page)
+	if !ok {
+		return nil, ErrCorrupt
+	}
+
+	t.pool.Set(id, n)
+	return n, nil
+}
+
+// alloc returns a page that no committed tree uses.
+func (t *Tree) alloc() uint64 {
+	if len(t.free) > 0 {
+		id := t.free[len(t.free)-1]
+		t.free = t.free[:len(t.free)-1]
+		return id
+	}
+
+	t.pages++
+	return t.pages - 1
+}
+
+// release frees the page of a node that is no longer used.  A page
+// allocated since the last commit is free at once, while one the
+// committed tree uses only becomes free once the change is
+// committed.
+func (t *Tree) release(id uint64) {
+	t.pool.Delete(id)
+	if _, ok := t.dirty[id]; ok {
+		delete(t.dirty, id)
+		t.free = append(t.free, id)
+		return
+	}
+
+	t.pending = append(t.pending, id)
+}
+
+// writable returns a node with the contents of the one in the page
+// that may be modified.  A clean node is copied to a new page and its
+// old page is released, so the caller must store the returned node's
+// page in its parent.
+func (t *Tree) writable(id uint64) (*node, error) {
+	if n, ok := t.dirty[id]; ok {
+		return n, nil
+	}
+
+	n, err := t.load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := n.clone(t.alloc())
+	t.release(id)
+	t.dirty[cp.id] = cp
+	return cp, nil
+}
+
+func (t *Tree) newNode(leaf bool) *node {
+	n := &node{id: t.alloc()}
+	if leaf {
+		n.values = [][]byte{}
+	} else {
+		n.children = []uint64{}
+	}
+	t.dirty[n.id] = n
+	return n
+}
+
+// maxEntry returns the largest size of a leaf entry that lets four
+// fit in a page.  Separators take at most as much in internal nodes.
+func (t *Tree) maxEntry() int {
+	return (t.config.pageSize - pageHeaderSize - childSize) / 4
+}
+
+func lowerBound(keys [][]byte, key []byte) int {
+	return sort.Search(len(keys), func(i int) bool {
+		return bytes.Compare(keys[i], key) >= 0
+	})
+}
+
+// childIndex returns the index of the child whose subtree would
+// contain the provided key.
+func childIndex(n *node, key []byte) int {
+	return sort.Search(len(n.keys), func(i int) bool {
+		return bytes.Compare(key, n.keys[i]) < 0
+	})
+}
+
+// splitPoint returns the index at which to divide the node so that
+// both halves take about the same space.  The index is that of the
+// first key of the right half of a leaf, or of the key moving up to
+// the parent of an internal node.
+func splitPoint(n *node) int {
+	half := (n.size() - pageHeaderSize) / 2
+	acc, i := 0, 0
+	for ; i < len(n.keys)-1; i++ {
+		acc += n.entrySize(i)
+		if acc >= half {
+			break
+		}
+	}
+
+	if n.leaf() {
+		return max(i, 1)
+	}
+	return i
+}
+
+// divide moves the upper half of the node into right, replacing
+// anything in it, and returns the key that separates them.
+func divide(n, right *node) []byte {
+	i := splitPoint(n)
+	if n.leaf() {
+		right.keys = append(right.keys[:0], n.keys[i:]...)
+		right.values = append(right.values[:0], n.values[i:]...)
+		clear(n.keys[i:])
+		clear(n.values[i:])
+		n.keys, n.values = n.keys[:i], n.values[:i]
+		return right.keys[0]
+	}
+
+	separator := n.keys[i]
+	right.keys = append(right.keys[:0], n.keys[i+1:]...)
+	right.children = append(right.children[:0], n.children[i+1:]...)
+	clear(n.keys[i:])
+	n.keys, n.children = n.keys[:i], n.children[:i+1]
+	return separator
+}
+
+func (t *Tree) checkOpen() error {
+	if t.closed {
+		return ErrClosed
+	}
+
+	return nil
+}
+
+// Get returns a copy of the value stored with the key and a bool
+// indicating if the key is in the tree.
+func (t *Tree) Get(key []byte) ([]byte, bool, error) {
+	if err := t.checkOpen(); err != nil {
+		return nil, false, err
+	}
+
+	n, i, ok, err := t.find(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return bytes.Clone(n.values[i]), true, nil
+}
+
+// find returns the leaf that would hold the key, the index of the
+// first key in it equal to or greater than the key and a bool
+// indicating if that key is equal.
+func (t *Tree) find(key []byte) (*node, int, bool, error) {
+	if t.root == 0 {
+		return nil, 0, false, nil
+	}
+
+	n, err := t.load(t.root)
+	for err == nil && !n.leaf() {
+		n, err = t.load(n.children[childIndex(n, key)])
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	i := lowerBound(n.keys, key)
+	return n, i, i < len(n.keys) && bytes.Equal(n.keys[i], key), nil
+}
+
+// Put stores the value with the key, replacing any value already
+// stored with it.  The key and value are copied.  Returns
+// ErrTooLarge if together they take more than a quarter of a page.
+// An error reading or writing the file discards all uncommitted
+// changes, as they may be incomplete.
+func (t *Tree) Put(key, value []byte) error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+	if leafEntryHeader+len(key)+len(value) > t.maxEntry() {
+		return ErrTooLarge
+	}
+
+	key, value = bytes.Clone(key), bytes.Clone(value)
+	if key == nil {
+		key = []byte{}
+	}
+	if value == nil {
+		value = []byte{}
+	}
+
+	if t.root == 0 {
+		t.root = t.newNode(true).id
+	}
+
+	root, added, err := t.insert(t.root, key, value)
+	if err != nil {
+		return t.abort(err)
+	}
+
+	if root.size() > t.config.pageSize {
+		parent := t.newNode(false)
+		right := t.newNode(root.leaf())
+		parent.keys = append(parent.keys, divide(root, right))
+		parent.children = append(parent.children, root.id, right.id)
+		root = parent
+	}
+
+	t.root = root.id
+	if added {
+		t.count++
+	}
+
+	return t.autoCommit()
+}
+
+// insert adds the key to the subtree in the page and returns its
+// writable root, which may need splitting, and a bool indicating if
+// the key is new.
+func (t *Tree) insert(id uint64, key, value []byte) (*node, bool, error) {
+	n, err := t.writable(id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if n.leaf() {
+		i := lowerBound(n.keys, key)
+		if i < len(n.keys) && bytes.Equal(n.keys[i], key) {
+			n.values[i] = value
+			return n, false, nil
+		}
+
+		n.keys = slices.Insert(n.keys, i, key)
+		n.values = slices.Insert(n.values, i, value)
+		return n, true, nil
+	}
+
+	i := childIndex(n, key)
+	child, added, err := t.insert(n.children[i], key, value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	n.children[i] = child.id
+	if child.size() > t.config.pageSize {
+		right := t.newNode(child.leaf())
+		n.keys = slices.Insert(n.keys, i, divide(child, right))
+		n.children = slices.Insert(n.children, i+1, right.id)
+	}
+
+	return n, added, nil
+}
+
+// Delete removes the key from the tree and returns a bool indicating
+// if it was there.  As with Put, an error discards all uncommitted
+// changes.
+func (t *Tree) Delete(key []byte) (bool, error) {
+	if err := t.checkOpen(); err != nil {
+		return false, err
+	}
+
+	_, _, ok, err := t.find(key)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	root, err := t.delete(t.root, key)
+	if err != nil {
+		return false, t.abort(err)
+	}
+
+	t.root = root.id
+	switch {
+	case root.leaf() && len(root.keys) == 0:
+		t.release(root.id)
+		t.root = 0
+	case !root.leaf() && len(root.keys) == 0:
+		// the root's children were merged into one
+		t.root = root.children[0]
+		t.release(root.id)
+	}
+	t.count--
+
+	return true, t.autoCommit()
+}
+
+// delete removes the key, which must be present, from the subtree in
+// the page and returns its writable root, which may be underfull.
+func (t *Tree) delete(id uint64, key []byte) (*node, error) {
+	n, err := t.writable(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.leaf() {
+		i := lowerBound(n.keys, key)
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.values = slices.Delete(n.values, i, i+1)
+		return n, nil
+	}
+
+	i := childIndex(n, key)
+	child, err := t.delete(n.children[i], key)
+	if err != nil {
+		return nil, err
+	}
+
+	n.children[i] = child.id
+	if child.size() < t.config.pageSize/4 {
+		if err := t.rebalance(n, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// rebalance repairs the underfull i-th child of the node by merging
+// it with a sibling if both fit in one page, or otherwise by sharing
+// their entries evenly between them.
+func (t *Tree) rebalance(n *node, i int) error {
+	l := i
+	if l == len(n.children)-1 {
+		l--
+	}
+
+	left, err := t.writable(n.children[l])
+	if err != nil {
+		return err
+	}
+	n.children[l] = left.id
+	right, err := t.writable(n.children[l+1])
+	if err != nil {
+		return err
+	}
+	n.children[l+1] = right.id
+
+	if !left.leaf() {
+		left.keys = append(left.keys, n.keys[l])
+		left.children = append(left.children, right.children...)
+	} else {
+		left.values = append(left.values, right.values...)
+	}
+	left.keys = append(left.keys, right.keys...)
+
+	if left.size() <= t.config.pageSize {
+		t.release(right.id)
+		n.keys = slices.Delete(n.keys, l, l+1)
+		n.children = slices.Delete(n.children, l+1, l+2)
+		return nil
+	}
+
+	n.keys[l] = divide(left, right)
+	return nil
+}
+
+// abort discards uncommitted changes after an error left them
+// inconsistent.
+func (t *Tree) abort(err error) error {
+	t.Rollback()
+	return err
+}
+
+// autoCommit commits the change just made under CommitAlways,
+// discarding it if it cannot be committed so that the tree matches
+// the file.
+func (t *Tree) autoCommit() error {
+	if t.config.commit != CommitAlways {
+		return nil
+	}
+
+	if err := t.Commit(); err != nil {
+		return t.abort(err)
+	}
+
+	return nil
+}
+
+// Commit writes the changes made since the last commit to the file
+// and flushes it to stable storage.  Either all of the changes are
+// visible when the file is next opened or, if the machine crashes
+// before Commit returns, none are.
+func (t *Tree) Commit() error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+
+	if len(t.dirty) == 0 && t.root == t.committed.root && t.count == t.committed.count {
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(t.dirty))
+	for id := range t.dirty {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	page := make([]byte, t.config.pageSize)
+	for _, id := range ids {
+		clear(page)
+		t.dirty[id].encode(page)
+		if _, err := t.file.WriteAt(page, int64(id)*int64(t.config.pageSize)); err != nil {
+			return err
+		}
+	}
+	if err := t.file.Sync(); err != nil {
+		return err
+	}
+
+	next := t.committed
+	next.txid++
+	next.root, next.count, next.pages = t.root, t.count, t.pages
+	previous := t.committed
+	t.committed = next
+	if err := t.writeMeta(int(next.txid % 2)); err != nil {
+		t.committed = previous
+		return err
+	}
+	if err := t.file.Sync(); err != nil {
+		t.committed = previous
+		return err
+	}
+
+	for id, n := range t.dirty {
+		t.pool.Set(id, n)
+	}
+	clear(t.dirty)
+	t.free = append(t.free, t.pending...)
+	t.pending = t.pending[:0]
+	return nil
+}
+
+// Rollback discards the changes made since the last commit.
+func (t *Tree) Rollback() {
+	for id := range t.dirty {
+		t.free = append(t.free, id)
+	}
+	clear(t.dirty)
+	t.pending = t.pending[:0]
+	t.root, t.count = t.committed.root, t.committed.count
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tree) Len() uint64 {
+	return t.count
+}
+
+// PageSize returns the size of the tree's pages in bytes.
+func (t *Tree) PageSize() int {
+	return t.config.pageSize
+}
+
+// Pages returns the number of pages in the file, used or free.
+func (t *Tree) Pages() uint64 {
+	return t.pages
+}
+
+// Close commits any uncommitted changes and closes the file.
+func (t *Tree) Close() error {
+	if err := t.checkOpen(); err != nil {
+		return err
+	}
+
+	err := t.Commit()
+	if closeErr := t.file.Close(); err == nil {
+		err = closeErr
+	}
+	t.closed = true
+	t.pool.Clear()
+	return err
+}
+
+// Each calls the provided function with every key and value in the
+// tree in order until the function returns false.  The key and value
+// must not be modified.
+func (t *Tree) Each(fn func(key, value []byte) bool) error {
+	iter := t.Iter(nil)
+	for iter.Next() {
+		if !fn(iter.Key(), iter.Value()) {
+			break
+		}
+	}
+
+	return iter.Err()
+}
+
+// All returns a sequence of every key and value in the tree in order
+// for use with range.  A read error ends the sequence early; use Each
+// or an Iterator to see it.
+func (t *Tree) All() iter.Seq2[[]byte, []byte] {
+	return func(yield func([]byte, []byte) bool) {
+		t.Each(yield)
+	}
+}
@@ -0,0 +1,446 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func open(t *testing.T, path string, opts ...Option) *Tree {
+	tree, err := Open(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tree
+}
+
+func key(i int) []byte {
+	return []byte(fmt.Sprintf(`key%08d`, i))
+}
+
+// check walks the tree checking that keys are in order and within
+// their separators, every leaf is at the same depth, nodes fit in
+// their pages and the count is right.
+func check(t *testing.T, tree *Tree) {
+	var count uint64
+	depth := -1
+	var walk func(id uint64, lo, hi []byte, level int)
+	walk = func(id uint64, lo, hi []byte, level int) {
+		n, err := tree.load(id)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.True(t, n.size() <= tree.PageSize())
+		for i, k := range n.keys {
+			assert.True(t, lo == nil || bytes.Compare(k, lo) >= 0)
+			assert.True(t, hi == nil || bytes.Compare(k, hi) < 0)
+			if i > 0 {
+				assert.True(t, bytes.Compare(n.keys[i-1], k) < 0)
+			}
+		}
+
+		if n.leaf() {
+			if depth < 0 {
+				depth = level
+			}
+			assert.Equal(t, depth, level)
+			count += uint64(len(n.keys))
+			return
+		}
+
+		assert.Len(t, n.children, len(n.keys)+1)
+		for i, child := range n.children {
+			clo, chi := lo, hi
+			if i > 0 {
+				clo = n.keys[i-1]
+			}
+			if i < len(n.keys) {
+				chi = n.keys[i]
+			}
+			walk(child, clo, chi, level+1)
+		}
+	}
+
+	if tree.root != 0 {
+		walk(tree.root, nil, nil, 0)
+	}
+	assert.Equal(t, tree.Len(), count)
+}
+
+// contents returns the keys and values of the tree in order.
+func contents(t *testing.T, tree *Tree) map[string]string {
+	result := make(map[string]string)
+	var last []byte
+	assert.Nil(t, tree.Each(func(k, v []byte) bool {
+		assert.True(t, last == nil || bytes.Compare(last, k) < 0)
+		last = k
+		result[string(k)] = string(v)
+		return true
+	}))
+
+	return result
+}
+
+func TestPutGetDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	tree := open(t, path)
+
+	value, ok, err := tree.Get([]byte(`a`))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, value)
+
+	assert.Nil(t, tree.Put([]byte(`b`), []byte(`2`)))
+	assert.Nil(t, tree.Put([]byte(`a`), []byte(`1`)))
+	assert.Nil(t, tree.Put([]byte(`a`), []byte(`one`)))
+	assert.Equal(t, uint64(2), tree.Len())
+
+	value, ok, err = tree.Get([]byte(`a`))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`one`), value)
+
+	ok, err = tree.Delete([]byte(`c`))
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	ok, err = tree.Delete([]byte(`b`))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, tree.Close())
+
+	tree = open(t, path)
+	defer tree.Close()
+	assert.Equal(t, map[string]string{`a`: `one`}, contents(t, tree))
+
+	ok, err = tree.Delete([]byte(`a`))
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Len(t, contents(t, tree), 0)
+}
+
+func TestRandomOperations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	opts := []Option{WithPageSize(minPageSize), WithCacheSize(16), WithCommitPolicy(CommitManual)}
+	tree := open(t, path, opts...)
+	model := make(map[string]string)
+	r := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 500; i++ {
+			k := key(r.Intn(2000))
+			if r.Intn(3) == 0 {
+				ok, err := tree.Delete(k)
+				assert.Nil(t, err)
+				_, expected := model[string(k)]
+				assert.Equal(t, expected, ok)
+				delete(model, string(k))
+				continue
+			}
+
+			value := bytes.Repeat([]byte{byte(r.Intn(256))}, r.Intn(200))
+			assert.Nil(t, tree.Put(k, value))
+			model[string(k)] = string(value)
+		}
+
+		check(t, tree)
+		assert.Nil(t, tree.Commit())
+		if round%5 == 4 {
+			assert.Nil(t, tree.Close())
+			tree = open(t, path, opts...)
+		}
+		assert.Equal(t, model, contents(t, tree))
+	}
+
+	// deleting everything empties the tree and frees every page
+	for k := range model {
+		ok, err := tree.Delete([]byte(k))
+		assert.Nil(t, err)
+		assert.True(t, ok)
+	}
+	assert.Nil(t, tree.Close())
+
+	tree = open(t, path, opts...)
+	defer tree.Close()
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Equal(t, int(tree.Pages())-1, len(tree.free))
+}
+
+func TestIterRange(t *testing.T) {
+	tree := open(t, filepath.Join(t.TempDir(), `tree`),
+		WithPageSize(minPageSize), WithCommitPolicy(CommitManual))
+	defer tree.Close()
+
+	for i := 0; i < 1000; i += 2 {
+		assert.Nil(t, tree.Put(key(i), key(i*10)))
+	}
+
+	var keys []string
+	iter := tree.IterRange(key(101), key(121))
+	assert.Nil(t, iter.Key())
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	assert.Nil(t, iter.Err())
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+
+	var expected []string
+	for i := 102; i < 121; i += 2 {
+		expected = append(expected, string(key(i)))
+	}
+	assert.Equal(t, expected, keys)
+
+	iter = tree.Iter(key(997))
+	assert.True(t, iter.Next())
+	assert.Equal(t, key(998), iter.Key())
+	assert.Equal(t, key(9980), iter.Value())
+	assert.False(t, iter.Next())
+
+	var all [][]byte
+	for k := range tree.All() {
+		all = append(all, k)
+		if len(all) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, [][]byte{key(0), key(2), key(4)}, all)
+}
+
+func TestRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	tree := open(t, path, WithPageSize(minPageSize), WithCommitPolicy(CommitManual))
+	defer tree.Close()
+
+	for i := 0; i < 200; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i)))
+	}
+	assert.Nil(t, tree.Commit())
+	pages := tree.Pages()
+
+	for i := 0; i < 100; i++ {
+		_, err := tree.Delete(key(i))
+		assert.Nil(t, err)
+		assert.Nil(t, tree.Put(key(i+1000), key(i)))
+	}
+	tree.Rollback()
+
+	assert.Equal(t, uint64(200), tree.Len())
+	check(t, tree)
+	model := contents(t, tree)
+	assert.Len(t, model, 200)
+	assert.Equal(t, string(key(5)), model[string(key(5))])
+
+	// the pages of the discarded changes are reused
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i+1)))
+	}
+	assert.Nil(t, tree.Commit())
+	assert.True(t, tree.Pages() <= 2*pages)
+}
+
+func TestCrashKeepsLastCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	tree := open(t, path, WithPageSize(minPageSize), WithCommitPolicy(CommitManual))
+	for i := 0; i < 300; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i)))
+	}
+	assert.Nil(t, tree.Commit())
+	expected := contents(t, tree)
+
+	// write uncommitted pages over the free pages then stop without
+	// writing a meta record, as a crash part way through a commit
+	for i := 0; i < 300; i++ {
+		assert.Nil(t, tree.Put(key(i), []byte(`changed`)))
+	}
+	for id, n := range tree.dirty {
+		page := make([]byte, tree.PageSize())
+		n.encode(page)
+		_, err := tree.file.WriteAt(page, int64(id)*int64(tree.PageSize()))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tree.file.Close())
+
+	tree = open(t, path)
+	defer tree.Close()
+	assert.Equal(t, minPageSize, tree.PageSize())
+	assert.Equal(t, expected, contents(t, tree))
+	check(t, tree)
+}
+
+func TestTornMetaFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	tree := open(t, path, WithPageSize(minPageSize), WithCommitPolicy(CommitManual))
+	for i := 0; i < 300; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i)))
+	}
+	assert.Nil(t, tree.Commit())
+	expected := contents(t, tree)
+
+	for i := 0; i < 300; i += 3 {
+		_, err := tree.Delete(key(i))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tree.Close())
+	slot := tree.committed.txid % 2
+
+	// damage the newest meta record as a torn write would
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	assert.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff, 0xff}, int64(slot)*metaSlot+20)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	tree = open(t, path)
+	assert.Equal(t, expected, contents(t, tree))
+	check(t, tree)
+	assert.Nil(t, tree.Close())
+
+	// with both records damaged the file can't be opened
+	f, err = os.OpenFile(path, os.O_RDWR, 0644)
+	assert.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff}, int64(1-slot)*metaSlot+20)
+	assert.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff}, int64(slot)*metaSlot+20)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	_, err = Open(path)
+	assert.Equal(t, ErrCorrupt, err)
+}
+
+func TestCorruptPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	tree := open(t, path, WithPageSize(minPageSize), WithCommitPolicy(CommitManual))
+	for i := 0; i < 300; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i)))
+	}
+	root := tree.root
+	assert.Nil(t, tree.Close())
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	assert.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff}, int64(root)*minPageSize+100)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	_, err = Open(path)
+	assert.Equal(t, ErrCorrupt, err)
+}
+
+func TestSpaceIsReused(t *testing.T) {
+	tree := open(t, filepath.Join(t.TempDir(), `tree`), WithPageSize(minPageSize))
+	defer tree.Close()
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, tree.Put(key(i), key(i)))
+	}
+	pages := tree.Pages()
+
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, tree.Put(key(i), key(round)))
+		}
+	}
+
+	// each commit frees the pages of the last, so a few spare pages
+	// per level suffice
+	assert.True(t, tree.Pages() <= 3*pages, `%d pages grew to %d`, pages, tree.Pages())
+	check(t, tree)
+}
+
+func TestLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `tree`)
+	_, err := Open(path, WithPageSize(100))
+	assert.NotNil(t, err)
+	_, err = Open(path, WithCacheSize(0))
+	assert.NotNil(t, err)
+
+	tree := open(t, path, WithPageSize(minPageSize))
+	assert.Equal(t, ErrTooLarge, tree.Put(make([]byte, 200), make([]byte, 100)))
+	assert.Nil(t, tree.Put(make([]byte, 100), make([]byte, 100)))
+	assert.Nil(t, tree.Put(nil, nil))
+
+	value, ok, err := tree.Get([]byte{})
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Len(t, value, 0)
+
+	assert.Nil(t, tree.Close())
+	assert.Equal(t, ErrClosed, tree.Close())
+	assert.Equal(t, ErrClosed, tree.Put(nil, nil))
+	_, _, err = tree.Get(nil)
+	assert.Equal(t, ErrClosed, err)
+	_, err = tree.Delete(nil)
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, tree.Each(func(k, v []byte) bool { return true }))
+}
+
+func BenchmarkPut(b *testing.B) {
+	tree, err := Open(filepath.Join(b.TempDir(), `tree`), WithCommitPolicy(CommitManual))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer tree.Close()
+
+	keys := make([][]byte, b.N)
+	for i := range keys {
+		keys[i] = key(i)
+	}
+	rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+	b.ResetTimer()
+	for i, k := range keys {
+		tree.Put(k, k)
+		if i%1000 == 999 {
+			tree.Commit()
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	tree, err := Open(filepath.Join(b.TempDir(), `tree`), WithCommitPolicy(CommitManual))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer tree.Close()
+
+	const n = 100000
+	for i := 0; i < n; i++ {
+		tree.Put(key(i), key(i))
+	}
+	tree.Commit()
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = key(i)
+	}
+	slices.Reverse(keys)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(keys[i%n])
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import "bytes"
+
+// frame is a node on the path of an iterator and the index of the
+// child or, in a leaf, the key the iterator is at.
+type frame struct {
+	n *node
+	i int
+}
+
+// Iterator traverses the keys and values of a Tree in order.  The
+// tree must not be changed while an iterator is in use.
+type Iterator struct {
+	tree   *Tree
+	stack  []frame
+	stop   []byte
+	nexted bool
+	done   bool
+	err    error
+}
+
+// Iter returns an iterator positioned before the first key equal to
+// or greater than the provided key.
+func (t *Tree) Iter(key []byte) *Iterator {
+	iter := &Iterator{tree: t}
+	if err := t.checkOpen(); err != nil {
+		iter.err, iter.done = err, true
+		return iter
+	}
+	if t.root == 0 {
+		iter.done = true
+		return iter
+	}
+
+	n, err := t.load(t.root)
+	for err == nil && !n.leaf() {
+		i := childIndex(n, key)
+		iter.stack = append(iter.stack, frame{n: n, i: i})
+		n, err = t.load(n.children[i])
+	}
+	if err != nil {
+		iter.err, iter.done = err, true
+		return iter
+	}
+
+	iter.stack = append(iter.stack, frame{n: n, i: lowerBound(n.keys, key)})
+	return iter
+}
+
+// IterRange returns an iterator over the keys equal to or greater
+// than start and less than stop.  A nil stop leaves the range
+// unbounded above.
+func (t *Tree) IterRange(start, stop []byte) *Iterator {
+	iter := t.Iter(start)
+	iter.stop = stop
+	return iter
+}
+
+// settle moves the iterator from the end of a leaf on to the next key
+// and returns false if there is none.
+func (iter *Iterator) settle() bool {
+	for len(iter.stack) > 0 {
+		top := &iter.stack[len(iter.stack)-1]
+		if top.n.leaf() {
+			if top.i < len(top.n.keys) {
+				return true
+			}
+		} else if top.i < len(top.n.children) {
+			child, err := iter.tree.load(top.n.children[top.i])
+			if err != nil {
+				iter.err = err
+				return false
+			}
+			iter.stack = append(iter.stack, frame{n: child})
+			continue
+		}
+
+		iter.stack = iter.stack[:len(iter.stack)-1]
+		if len(iter.stack) > 0 {
+			iter.stack[len(iter.stack)-1].i++
+		}
+	}
+
+	return false
+}
+
+// Next moves the iterator to the next key and returns a bool
+// indicating if there is one.
+func (iter *Iterator) Next() bool {
+	if iter.done {
+		return false
+	}
+
+	if iter.nexted {
+		iter.stack[len(iter.stack)-1].i++
+	}
+	iter.nexted = true
+
+	if !iter.settle() || (iter.stop != nil && bytes.Compare(iter.Key(), iter.stop) >= 0) {
+		iter.done, iter.stack = true, nil
+		return false
+	}
+
+	return true
+}
+
+// Key returns the key at the current position, or nil if the
+// iterator hasn't been moved or is exhausted.  The key must not be
+// modified.
+func (iter *Iterator) Key() []byte {
+	if !iter.nexted || len(iter.stack) == 0 {
+		return nil
+	}
+
+	top := iter.stack[len(iter.stack)-1]
+	return top.n.keys[top.i]
+}
+
+// Value returns the value at the current position, or nil if the
+// iterator hasn't been moved or is exhausted.  The value must not be
+// modified.
+func (iter *Iterator) Value() []byte {
+	if !iter.nexted || len(iter.stack) == 0 {
+		return nil
+	}
+
+	top := iter.stack[len(iter.stack)-1]
+	return top.n.values[top.i]
+}
+
+// Err returns the error, if any, that ended the iteration early.
+func (iter *Iterator) Err() error {
+	return iter.err
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+)
+
+const (
+	// magic identifies a tree file.
+	magic   = 0x42545245
+	version = 1
+	// metaSlot is the spacing of the two meta records at the start of
+	// the file.  Each fits in a single disk sector so that a torn
+	// write damages at most one of them.
+	metaSlot = 512
+	metaSize = 48
+
+	// pageHeaderSize is the checksum, the kind of node and the
+	// number of keys at the start of every node page.
+	pageHeaderSize      = 8
+	leafEntryHeader     = 6
+	internalEntryHeader = 10
+	childSize           = 8
+
+	kindLeaf     = 1
+	kindInternal = 2
+)
+
+// meta is the record of a committed tree.  The meta with the higher
+// txid of the two that are intact describes the tree.
+type meta struct {
+	pageSize uint32
+	txid     uint64
+	// root is the page of the root node, or zero if the tree is
+	// empty.
+	root uint64
+	// pages is the number of pages in use, including the meta page,
+	// so every node page is below it.
+	pages uint64
+	count uint64
+}
+
+func (m *meta) encode(buf []byte) {
+	binary.LittleEndian.PutUint32(buf[0:], magic)
+	binary.LittleEndian.PutUint32(buf[4:], version)
+	binary.LittleEndian.PutUint32(buf[8:], m.pageSize)
+	binary.LittleEndian.PutUint64(buf[12:], m.txid)
+	binary.LittleEndian.PutUint64(buf[20:], m.root)
+	binary.LittleEndian.PutUint64(buf[28:], m.pages)
+	binary.LittleEndian.PutUint64(buf[36:], m.count)
+	binary.LittleEndian.PutUint32(buf[44:], crc32.ChecksumIEEE(buf[:44]))
+}
+
+// decodeMeta returns the meta encoded in buf and a bool indicating if
+// it is intact.
+func decodeMeta(buf []byte) (meta, bool) {
+	if len(buf) < metaSize ||
+		binary.LittleEndian.Uint32(buf[0:]) != magic ||
+		binary.LittleEndian.Uint32(buf[4:]) != version ||
+		binary.LittleEndian.Uint32(buf[44:]) != crc32.ChecksumIEEE(buf[:44]) {
+
+		return meta{}, false
+	}
+
+	return meta{
+		pageSize: binary.LittleEndian.Uint32(buf[8:]),
+		txid:     binary.LittleEndian.Uint64(buf[12:]),
+		root:     binary.LittleEndian.Uint64(buf[20:]),
+		pages:    binary.LittleEndian.Uint64(buf[28:]),
+		count:    binary.LittleEndian.Uint64(buf[36:]),
+	}, true
+}
+
+// node is a decoded node page.  Leaves hold keys and their values
+// while internal nodes hold separator keys and the pages of their
+// children, of which there is one more than keys.  Keys equal to a
+// separator belong to the right of it.  The byte slices of a node are
+// never modified, only replaced, so they may be shared between
+// copies of a node.
+type node struct {
+	id       uint64
+	keys     [][]byte
+	values   [][]byte
+	children []uint64
+}
+
+func (n *node) leaf() bool {
+	return n.children == nil
+}
+
+// entrySize returns the number of bytes the i-th key and what goes
+// with it take in the node's page.
+func (n *node) entrySize(i int) int {
+	if n.leaf() {
+		return leafEntryHeader + len(n.keys[i]) + len(n.values[i])
+	}
+
+	return internalEntryHeader + len(n.keys[i])
+}
+
+// size returns the number of bytes the node's page needs.
+func (n *node) size() int {
+	size := pageHeaderSize
+	if !n.leaf() {
+		size += childSize
+	}
+	for i := range n.keys {
+		size += n.entrySize(i)
+	}
+
+	return size
+}
+
+// clone returns a copy of the node with the provided page that can
+// be modified without affecting this one.
+func (n *node) clone(id uint64) *node {
+	cp := &node{
+		id:   id,
+		keys: append(make([][]byte, 0, len(n.keys)+1), n.keys...),
+	}
+	if n.leaf() {
+		cp.values = append(make([][]byte, 0, len(n.values)+1), n.values...)
+	} else {
+		cp.children = append(make([]uint64, 0, len(n.children)+1), n.children...)
+	}
+
+	return cp
+}
+
+// encode writes the node into page, which must be zeroed and as
+// large as a page.
+func (n *node) encode(page []byte) {
+	kind := byte(kindLeaf)
+	if !n.leaf() {
+		kind = kindInternal
+	}
+	page[4] = kind
+	binary.LittleEndian.PutUint16(page[6:], uint16(len(n.keys)))
+
+	offset := pageHeaderSize
+	if !n.leaf() {
+		binary.LittleEndian.PutUint64(page[offset:], n.children[0])
+		offset += childSize
+	}
+
+	for i, key := range n.keys {
+		binary.LittleEndian.PutUint16(page[offset:], uint16(len(key)))
+		if n.leaf() {
+			binary.LittleEndian.PutUint32(page[offset+2:], uint32(len(n.values[i])))
+			offset += leafEntryHeader
+			offset += copy(page[offset:], key)
+			offset += copy(page[offset:], n.values[i])
+		} else {
+			binary.LittleEndian.PutUint64(page[offset+2:], n.children[i+1])
+			offset += internalEntryHeader
+			offset += copy(page[offset:], key)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(page, crc32.ChecksumIEEE(page[4:]))
+}
+
+// decodeNode returns the node encoded in page, which it takes
+// ownership of, and a bool indicating if the page is intact.
+func decodeNode(id uint64, page []byte) (*node, bool) {
+	if binary.LittleEndian.Uint32(page) != crc32.ChecksumIEEE(page[4:]) {
+		return nil, false
+	}
+
+	kind := page[4]
+	if kind != kindLeaf && kind != kindInternal {
+		return nil, false
+	}
+
+	count := int(binary.LittleEndian.Uint16(page[6:]))
+	n := &node{id: id, keys: make([][]byte, 0, count+1)}
+	offset := pageHeaderSize
+	if kind == kindLeaf {
+		n.values = make([][]byte, 0, count+1)
+	} else {
+		if len(page) < offset+childSize {
+			return nil, false
+		}
+		n.children = make([]uint64, 0, count+2)
+		n.children = append(n.children, binary.LittleEndian.Uint64(page[offset:]))
+		offset += childSize
+	}
+
+	for i := 0; i < count; i++ {
+		if kind == kindLeaf {
+			if len(page) < offset+leafEntryHeader {
+				return nil, false
+			}
+			keyLen := int(binary.LittleEndian.Uint16(page[offset:]))
+			valueLen := int(binary.LittleEndian.Uint32(page[offset+2:]))
+			offset += leafEntryHeader
+			if len(page)-offset < keyLen+valueLen {
+				return nil, false
+			}
+			n.keys = append(n.keys, page[offset:offset+keyLen:offset+keyLen])
+			offset += keyLen
+			n.values = append(n.values, page[offset:offset+valueLen:offset+valueLen])
+			offset += valueLen
+		} else {
+			if len(page) < offset+internalEntryHeader {
+				return nil, false
+			}
+			keyLen := int(binary.LittleEndian.Uint16(page[offset:]))
+			child := binary.LittleEndian.Uint64(page[offset+2:])
+			offset += internalEntryHeader
+			if len(page)-offset < keyLen {
+				return nil, false
+			}
+			n.keys = append(n.keys, page[offset:offset+keyLen:offset+keyLen])
+			n.children = append(n.children, child)
+			offset += keyLen
+		}
+	}
+
+	return n, true
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package immutable implements a persistent B-tree.  Insert and Delete
+never modify a tree in place; they return a new tree that shares every
+node the operation did not touch with the original, so each operation
+copies only O(log n) nodes.  Any tree value can be held as a
+consistent snapshot for as long as it is needed while writers keep
+producing new versions, which suits MVCC-style read-heavy services.
+
+When many edits are made at once, Mutable returns a transient copy of
+the tree that is edited in place.  A transient only copies a node the
+first time it touches it, so a batch of edits costs far less than
+the same edits applied one persistent version at a time.  Immutable
+turns the transient back into a persistent tree.
+*/
+package immutable
+
+// Keys is a typed list of Key interfaces.
+type Keys []Key
+
+// Key defines items that can be inserted into or searched for
+// in the tree.
+type Key interface {
+	// Compare should return an int indicating how this key relates
+	// to the provided key.  -1 will indicate less than, 0 will indicate
+	// equality, and 1 will indicate greater than.
+	Compare(Key) int
+}
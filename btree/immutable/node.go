@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immutable
+
+import "sort"
+
+// owner identifies the transient that may modify a node in place.
+// It is never empty so that every allocation has a distinct address.
+type owner struct {
+	_ byte
+}
+
+type nodes []*node
+
+type node struct {
+	keys     Keys
+	children nodes
+	// owner is the transient allowed to modify this node in place.
+	// Nodes reachable from a persistent tree are never modified.
+	owner *owner
+}
+
+func (n *node) isLeaf() bool {
+	return len(n.children) == 0
+}
+
+// search returns the index of the first key equal to or greater
+// than the provided key and whether that key is equal.
+func (n *node) search(key Key) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool {
+		return n.keys[i].Compare(key) > -1
+	})
+
+	return i, i < len(n.keys) && n.keys[i].Compare(key) == 0
+}
+
+func (n *node) copy(owner *owner) *node {
+	cp := &node{
+		keys:  make(Keys, len(n.keys), cap(n.keys)),
+		owner: owner,
+	}
+	copy(cp.keys, n.keys)
+
+	if n.children != nil {
+		cp.children = make(nodes, len(n.children), cap(n.children))
+		copy(cp.children, n.children)
+	}
+
+	return cp
+}
+
+func (keys *Keys) insertAt(key Key, i int) {
+	*keys = append(*keys, nil)
+	copy((*keys)[i+1:], (*keys)[i:])
+	(*keys)[i] = key
+}
+
+func (keys *Keys) deleteAt(i int) Key {
+	key := (*keys)[i]
+	copy((*keys)[i:], (*keys)[i+1:])
+	(*keys)[len(*keys)-1] = nil // for garbage collection
+	*keys = (*keys)[:len(*keys)-1]
+	return key
+}
+
+// truncate drops every key from index i on.
+func (keys *Keys) truncate(i int) {
+	for j := i; j < len(*keys); j++ {
+		(*keys)[j] = nil
+	}
+	*keys = (*keys)[:i]
+}
+
+func (ns *nodes) insertAt(n *node, i int) {
+	*ns = append(*ns, nil)
+	copy((*ns)[i+1:], (*ns)[i:])
+	(*ns)[i] = n
+}
+
+func (ns *nodes) deleteAt(i int) *node {
+	n := (*ns)[i]
+	copy((*ns)[i:], (*ns)[i+1:])
+	(*ns)[len(*ns)-1] = nil
+	*ns = (*ns)[:len(*ns)-1]
+	return n
+}
+
+func (ns *nodes) truncate(i int) {
+	for j := i; j < len(*ns); j++ {
+		(*ns)[j] = nil
+	}
+	*ns = (*ns)[:i]
+}
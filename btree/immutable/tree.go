@@ -0,0 +1,381 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immutable
+
+// Tree is a persistent B-tree.  A Tree is never modified after it is
+// created, so it is safe to share between goroutines without locking.
+type Tree struct {
+	root     *node
+	number   uint64
+	nodeSize int
+}
+
+// New returns an empty tree whose nodes hold at most nodeSize keys.
+// The node size must be at least 3.
+func New(nodeSize int) *Tree {
+	if nodeSize < 3 {
+		panic(`Node size must be at least 3.`)
+	}
+
+	return &Tree{nodeSize: nodeSize}
+}
+
+// Insert returns a new tree holding the provided keys in addition to
+// the keys in this tree.  Keys equal to one already in the tree
+// replace it.  This tree is left unchanged.
+func (tree *Tree) Insert(keys ...Key) *Tree {
+	if len(keys) == 0 {
+		return tree
+	}
+
+	tr := tree.Mutable()
+	tr.Insert(keys...)
+	return tr.Immutable()
+}
+
+// Delete returns a new tree without the provided keys.  Keys that
+// are not in the tree are ignored.  This tree is left unchanged.
+func (tree *Tree) Delete(keys ...Key) *Tree {
+	if len(keys) == 0 {
+		return tree
+	}
+
+	tr := tree.Mutable()
+	tr.Delete(keys...)
+	return tr.Immutable()
+}
+
+// Get will retrieve any keys matching the provided keys in the tree.
+// Returns nil in any place of a key that couldn't be found.
+func (tree *Tree) Get(keys ...Key) Keys {
+	return get(tree.root, keys)
+}
+
+// Each will call the provided function with every key in the tree
+// in order until the function returns false.
+func (tree *Tree) Each(fn func(Key) bool) {
+	each(tree.root, fn)
+}
+
+// Len returns the number of items in this tree.
+func (tree *Tree) Len() uint64 {
+	return tree.number
+}
+
+// Mutable returns a transient tree holding the keys in this tree.
+// The transient can be edited in place without affecting this tree.
+func (tree *Tree) Mutable() *Transient {
+	return &Transient{
+		root:     tree.root,
+		number:   tree.number,
+		nodeSize: tree.nodeSize,
+		owner:    &owner{},
+	}
+}
+
+// Transient is a mutable version of a Tree used to apply a batch of
+// edits cheaply.  It shares nodes with the tree it came from and
+// copies each one the first time it is modified.  A Transient is not
+// safe for concurrent use.
+type Transient struct {
+	root     *node
+	number   uint64
+	nodeSize int
+	owner    *owner
+}
+
+// Immutable returns a persistent tree holding the keys currently in
+// this transient.  The transient remains usable afterwards, but any
+// further edits copy nodes again so they are not seen by the
+// returned tree.
+func (tr *Transient) Immutable() *Tree {
+	tree := &Tree{
+		root:     tr.root,
+		number:   tr.number,
+		nodeSize: tr.nodeSize,
+	}
+	tr.owner = &owner{}
+	return tree
+}
+
+// Insert will add the provided keys to the transient.  Keys equal to
+// one already in the tree replace it.
+func (tr *Transient) Insert(keys ...Key) {
+	for _, key := range keys {
+		if tr.root == nil {
+			tr.root = &node{
+				keys:  make(Keys, 0, tr.nodeSize+1),
+				owner: tr.owner,
+			}
+		}
+
+		tr.root = tr.mutable(tr.root)
+		if len(tr.root.keys) >= tr.nodeSize {
+			root := &node{
+				keys:     make(Keys, 0, tr.nodeSize+1),
+				children: make(nodes, 1, tr.nodeSize+2),
+				owner:    tr.owner,
+			}
+			root.children[0] = tr.root
+			tr.splitChild(root, 0)
+			tr.root = root
+		}
+
+		if tr.insert(tr.root, key) == nil {
+			tr.number++
+		}
+	}
+}
+
+// Delete will remove the provided keys from the transient.  Keys
+// that are not in the tree are ignored.
+func (tr *Transient) Delete(keys ...Key) {
+	for _, key := range keys {
+		// check first so a missing key doesn't copy or
+		// rebalance anything
+		if tr.root == nil || get(tr.root, Keys{key})[0] == nil {
+			continue
+		}
+
+		tr.root = tr.mutable(tr.root)
+		tr.remove(tr.root, key)
+		tr.number--
+
+		if len(tr.root.keys) == 0 {
+			if tr.root.isLeaf() {
+				tr.root = nil
+			} else {
+				tr.root = tr.root.children[0]
+			}
+		}
+	}
+}
+
+// Get will retrieve any keys matching the provided keys in the tree.
+// Returns nil in any place of a key that couldn't be found.
+func (tr *Transient) Get(keys ...Key) Keys {
+	return get(tr.root, keys)
+}
+
+// Each will call the provided function with every key in the tree
+// in order until the function returns false.
+func (tr *Transient) Each(fn func(Key) bool) {
+	each(tr.root, fn)
+}
+
+// Len returns the number of items in this transient.
+func (tr *Transient) Len() uint64 {
+	return tr.number
+}
+
+func (tr *Transient) minKeys() int {
+	return (tr.nodeSize - 1) / 2
+}
+
+// mutable returns a version of n that this transient may modify,
+// copying n if it is shared.
+func (tr *Transient) mutable(n *node) *node {
+	if n.owner == tr.owner {
+		return n
+	}
+
+	return n.copy(tr.owner)
+}
+
+// mutableChild returns a modifiable version of the ith child of n,
+// which must already be modifiable, and stores it back in n.
+func (tr *Transient) mutableChild(n *node, i int) *node {
+	child := tr.mutable(n.children[i])
+	n.children[i] = child
+	return child
+}
+
+// splitChild splits the ith child of n around its middle key, which
+// is moved up into n.
+func (tr *Transient) splitChild(n *node, i int) {
+	child := tr.mutableChild(n, i)
+	mid := len(child.keys) / 2
+	key := child.keys[mid]
+
+	right := &node{
+		keys:  make(Keys, len(child.keys)-mid-1, tr.nodeSize+1),
+		owner: tr.owner,
+	}
+	copy(right.keys, child.keys[mid+1:])
+	child.keys.truncate(mid)
+
+	if !child.isLeaf() {
+		right.children = make(nodes, len(child.children)-mid-1, tr.nodeSize+2)
+		copy(right.children, child.children[mid+1:])
+		child.children.truncate(mid + 1)
+	}
+
+	n.keys.insertAt(key, i)
+	n.children.insertAt(right, i+1)
+}
+
+// insert adds key to the subtree rooted at n, which must be
+// modifiable and not full, and returns any key it replaced.
+func (tr *Transient) insert(n *node, key Key) Key {
+	for {
+		i, found := n.search(key)
+		if found {
+			old := n.keys[i]
+			n.keys[i] = key
+			return old
+		}
+
+		if n.isLeaf() {
+			n.keys.insertAt(key, i)
+			return nil
+		}
+
+		if len(n.children[i].keys) >= tr.nodeSize {
+			tr.splitChild(n, i)
+			// the middle key moved up to i, so look at this
+			// node again
+			continue
+		}
+
+		n = tr.mutableChild(n, i)
+	}
+}
+
+// remove deletes key from the subtree rooted at n, which must be
+// modifiable and hold more than the minimum number of keys unless it
+// is the root.  A nil key removes the greatest key in the subtree.
+// The key must be in the subtree.
+func (tr *Transient) remove(n *node, key Key) Key {
+	for {
+		var i int
+		var found bool
+		if key == nil {
+			i = len(n.keys)
+			if n.isLeaf() {
+				return n.keys.deleteAt(i - 1)
+			}
+		} else {
+			i, found = n.search(key)
+			if n.isLeaf() {
+				return n.keys.deleteAt(i)
+			}
+		}
+
+		// make sure the child we move into can spare a key
+		if len(n.children[i].keys) <= tr.minKeys() {
+			tr.growChild(n, i)
+			continue
+		}
+
+		child := tr.mutableChild(n, i)
+		if found {
+			// replace the key with its predecessor
+			removed := n.keys[i]
+			n.keys[i] = tr.remove(child, nil)
+			return removed
+		}
+
+		n = child
+	}
+}
+
+// growChild gives the ith child of n an extra key, either by
+// borrowing one through n from a sibling or by merging the child
+// with a sibling.
+func (tr *Transient) growChild(n *node, i int) {
+	if i > 0 && len(n.children[i-1].keys) > tr.minKeys() {
+		left := tr.mutableChild(n, i-1)
+		child := tr.mutableChild(n, i)
+		child.keys.insertAt(n.keys[i-1], 0)
+		n.keys[i-1] = left.keys.deleteAt(len(left.keys) - 1)
+		if !left.isLeaf() {
+			child.children.insertAt(left.children.deleteAt(len(left.children)-1), 0)
+		}
+		return
+	}
+
+	if i < len(n.keys) && len(n.children[i+1].keys) > tr.minKeys() {
+		right := tr.mutableChild(n, i+1)
+		child := tr.mutableChild(n, i)
+		child.keys = append(child.keys, n.keys[i])
+		n.keys[i] = right.keys.deleteAt(0)
+		if !right.isLeaf() {
+			child.children = append(child.children, right.children.deleteAt(0))
+		}
+		return
+	}
+
+	if i >= len(n.keys) {
+		i--
+	}
+
+	// the right node is only read, so it needn't be copied
+	child := tr.mutableChild(n, i)
+	right := n.children[i+1]
+	child.keys = append(child.keys, n.keys.deleteAt(i))
+	child.keys = append(child.keys, right.keys...)
+	child.children = append(child.children, right.children...)
+	n.children.deleteAt(i + 1)
+}
+
+func get(root *node, keys Keys) Keys {
+	result := make(Keys, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, find(root, key))
+	}
+
+	return result
+}
+
+func find(n *node, key Key) Key {
+	for n != nil {
+		i, found := n.search(key)
+		if found {
+			return n.keys[i]
+		}
+
+		if n.isLeaf() {
+			return nil
+		}
+
+		n = n.children[i]
+	}
+
+	return nil
+}
+
+func each(n *node, fn func(Key) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for i, key := range n.keys {
+		if !n.isLeaf() && !each(n.children[i], fn) {
+			return false
+		}
+
+		if !fn(key) {
+			return false
+		}
+	}
+
+	if !n.isLeaf() {
+		return each(n.children[len(n.children)-1], fn)
+	}
+
+	return true
+}
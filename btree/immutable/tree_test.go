@@ -0,0 +1,338 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeys(num int) Keys {
+	keys := make(Keys, 0, num)
+	for i := 0; i < num; i++ {
+		keys = append(keys, mockKey(i))
+	}
+
+	return keys
+}
+
+func generateRandomKeys(num int) Keys {
+	keys := generateKeys(num)
+	for i := range keys {
+		j := rand.Intn(i + 1)
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+
+	return keys
+}
+
+func toKeys(tree *Tree) Keys {
+	keys := make(Keys, 0, tree.Len())
+	tree.Each(func(key Key) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
+}
+
+// checkNode verifies the ordering, occupancy and depth of the
+// subtree rooted at n and returns its depth and number of keys.
+func checkNode(t testing.TB, n *node, nodeSize int, root bool) (int, int) {
+	assert.True(t, len(n.keys) <= nodeSize)
+	if !root {
+		assert.True(t, len(n.keys) >= (nodeSize-1)/2)
+	}
+
+	for i := 1; i < len(n.keys); i++ {
+		assert.Equal(t, -1, n.keys[i-1].Compare(n.keys[i]))
+	}
+
+	if n.isLeaf() {
+		return 1, len(n.keys)
+	}
+
+	if !assert.Len(t, n.children, len(n.keys)+1) {
+		return 0, 0
+	}
+
+	depth, number := -1, len(n.keys)
+	for i, child := range n.children {
+		if i > 0 {
+			assert.Equal(t, 1, child.keys[0].Compare(n.keys[i-1]))
+		}
+		if i < len(n.keys) {
+			assert.Equal(t, -1, child.keys[len(child.keys)-1].Compare(n.keys[i]))
+		}
+
+		d, num := checkNode(t, child, nodeSize, false)
+		if depth == -1 {
+			depth = d
+		}
+		assert.Equal(t, depth, d)
+		number += num
+	}
+
+	return depth + 1, number
+}
+
+func checkTree(t testing.TB, tree *Tree) {
+	if tree.root == nil {
+		assert.Equal(t, uint64(0), tree.Len())
+		return
+	}
+
+	_, number := checkNode(t, tree.root, tree.nodeSize, true)
+	assert.Equal(t, tree.Len(), uint64(number))
+}
+
+// countNodes returns the number of nodes reachable from n that
+// are not in seen, adding them to seen.
+func countNodes(n *node, seen map[*node]bool) int {
+	if n == nil || seen[n] {
+		return 0
+	}
+
+	seen[n] = true
+	count := 1
+	for _, child := range n.children {
+		count += countNodes(child, seen)
+	}
+
+	return count
+}
+
+func TestNewPanicsOnSmallNodeSize(t *testing.T) {
+	assert.Panics(t, func() { New(2) })
+}
+
+func TestInsert(t *testing.T) {
+	for _, nodeSize := range []int{3, 4, 5, 32} {
+		tree := New(nodeSize)
+		keys := generateRandomKeys(1000)
+
+		for _, key := range keys {
+			tree = tree.Insert(key)
+		}
+
+		checkTree(t, tree)
+		assert.Equal(t, uint64(len(keys)), tree.Len())
+		assert.Equal(t, keys, tree.Get(keys...))
+		assert.Equal(t, generateKeys(1000), toKeys(tree))
+		assert.Equal(t, Keys{nil}, tree.Get(mockKey(1000)))
+	}
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	tree := New(3).Insert(generateKeys(10)...)
+	tree = tree.Insert(mockKey(5))
+
+	assert.Equal(t, uint64(10), tree.Len())
+	checkTree(t, tree)
+}
+
+func TestDelete(t *testing.T) {
+	for _, nodeSize := range []int{3, 4, 5, 32} {
+		keys := generateRandomKeys(1000)
+		tree := New(nodeSize).Insert(keys...)
+
+		for i, key := range keys {
+			tree = tree.Delete(key, mockKey(-1))
+			if i%100 == 0 {
+				checkTree(t, tree)
+			}
+			assert.Equal(t, uint64(len(keys)-i-1), tree.Len())
+			assert.Equal(t, Keys{nil}, tree.Get(key))
+		}
+
+		assert.Nil(t, tree.root)
+		assert.Len(t, toKeys(tree), 0)
+	}
+}
+
+func TestDeleteEmpty(t *testing.T) {
+	tree := New(3)
+	assert.Equal(t, tree, tree.Delete())
+	assert.Equal(t, uint64(0), tree.Delete(mockKey(1)).Len())
+}
+
+func TestPersistence(t *testing.T) {
+	keys := generateRandomKeys(500)
+	versions := make([]*Tree, 0, len(keys)+1)
+	versions = append(versions, New(4))
+	for _, key := range keys {
+		versions = append(versions, versions[len(versions)-1].Insert(key))
+	}
+
+	deleted := versions[len(versions)-1]
+	for _, key := range keys[:250] {
+		deleted = deleted.Delete(key)
+	}
+
+	for i, version := range versions {
+		assert.Equal(t, uint64(i), version.Len())
+		assert.Equal(t, keys[:i], version.Get(keys[:i]...))
+		if i < len(keys) {
+			assert.Equal(t, Keys{nil}, version.Get(keys[i]))
+		}
+	}
+	checkTree(t, versions[len(versions)-1])
+
+	checkTree(t, deleted)
+	assert.Equal(t, uint64(250), deleted.Len())
+	assert.Equal(t, make(Keys, 250), deleted.Get(keys[:250]...))
+	assert.Equal(t, keys[250:], deleted.Get(keys[250:]...))
+}
+
+func TestStructuralSharing(t *testing.T) {
+	tree := New(8).Insert(generateKeys(10000)...)
+	seen := make(map[*node]bool)
+	total := countNodes(tree.root, seen)
+
+	inserted := tree.Insert(mockKey(10000))
+	copied := countNodes(inserted.root, seen)
+	assert.True(t, copied > 0)
+	assert.True(t, copied < 10)
+
+	deleted := tree.Delete(mockKey(5000))
+	copied = countNodes(deleted.root, seen)
+	assert.True(t, copied > 0)
+	assert.True(t, copied < 10)
+	assert.True(t, total > 1000)
+}
+
+func TestMutable(t *testing.T) {
+	tree := New(5).Insert(generateKeys(100)...)
+
+	tr := tree.Mutable()
+	tr.Insert(generateKeys(200)[100:]...)
+	tr.Delete(generateKeys(50)...)
+	assert.Equal(t, uint64(150), tr.Len())
+	assert.Equal(t, Keys{nil, mockKey(150)}, tr.Get(mockKey(10), mockKey(150)))
+
+	assert.Equal(t, uint64(100), tree.Len())
+	assert.Equal(t, generateKeys(100), toKeys(tree))
+	checkTree(t, tree)
+
+	snapshot := tr.Immutable()
+	checkTree(t, snapshot)
+	assert.Equal(t, generateKeys(200)[50:], toKeys(snapshot))
+
+	// edits after Immutable must not leak into the snapshot
+	tr.Delete(generateKeys(200)[50:]...)
+	tr.Insert(mockKey(1000))
+	assert.Equal(t, uint64(1), tr.Len())
+	assert.Equal(t, generateKeys(200)[50:], toKeys(snapshot))
+	checkTree(t, snapshot)
+	checkTree(t, tr.Immutable())
+}
+
+func TestMutableCopiesOnce(t *testing.T) {
+	tree := New(8).Insert(generateKeys(1000)...)
+	seen := make(map[*node]bool)
+	countNodes(tree.root, seen)
+
+	tr := tree.Mutable()
+	for i := 0; i < 10; i++ {
+		tr.Insert(mockKey(1000 + i))
+	}
+
+	copied := countNodes(tr.Immutable().root, seen)
+	assert.True(t, copied < 10)
+}
+
+func TestEach(t *testing.T) {
+	tree := New(4)
+	tree.Each(func(Key) bool {
+		t.Errorf(`Empty tree called fn.`)
+		return true
+	})
+
+	tree = tree.Insert(generateKeys(100)...)
+	var result Keys
+	tree.Each(func(key Key) bool {
+		result = append(result, key)
+		return len(result) < 10
+	})
+	assert.Equal(t, generateKeys(10), result)
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	expected := make(map[mockKey]bool)
+	tree := New(4)
+
+	for i := 0; i < 5000; i++ {
+		key := mockKey(r.Intn(500))
+		if r.Intn(3) == 0 {
+			tree = tree.Delete(key)
+			delete(expected, key)
+		} else {
+			tree = tree.Insert(key)
+			expected[key] = true
+		}
+	}
+
+	checkTree(t, tree)
+	assert.Equal(t, uint64(len(expected)), tree.Len())
+	for key := range expected {
+		assert.Equal(t, Keys{key}, tree.Get(key))
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := New(32)
+		for _, key := range keys {
+			tree = tree.Insert(key)
+		}
+	}
+}
+
+func BenchmarkMutableInsert(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tr := New(32).Mutable()
+		for _, key := range keys {
+			tr.Insert(key)
+		}
+		tr.Immutable()
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	numItems := 10000
+	keys := generateRandomKeys(numItems)
+	tree := New(32).Insert(keys...)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Get(keys[i%numItems])
+	}
+}
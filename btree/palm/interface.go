@@ -31,6 +31,18 @@ package.
 
 BenchmarkBulkAddToExisting-8	200	   8690207 ns/op
 BenchmarkBulkAddToExisting-8    100   16778514 ns/op
+
+Every call to the tree waits for the next round of operations to be
+applied, so a caller that sends keys one at a time pays a full round
+of latency per key.  InsertBatch and DeleteBatch stage a whole batch
+in one round, trading a longer wait for that single call for much
+higher throughput.  Below, 1000 keys are applied individually and
+as one batch.
+
+BenchmarkInsertIndividually	150	   8586340 ns/op
+BenchmarkInsertBatch		609	   3155089 ns/op
+BenchmarkDeleteIndividually	 93	  13190269 ns/op
+BenchmarkDeleteBatch		3552	    323261 ns/op
 */
 package palm
 
@@ -51,6 +63,14 @@ type Key interface {
 type BTree interface {
 	// Insert will insert the provided keys into the tree.
 	Insert(...Key)
+	// InsertBatch will insert the provided keys into the tree in
+	// a single round.
+	InsertBatch(...Key)
+	// DeleteBatch will remove the provided keys from the tree in a
+	// single round.  Keys that are not in the tree are ignored.  If
+	// the same key is inserted and deleted in one round, the delete
+	// is applied last.
+	DeleteBatch(...Key)
 	// Get will return a key matching the associated provided
 	// key if it exists.
 	Get(...Key) Keys
@@ -47,6 +47,19 @@ func (keys *Keys) insertAt(key Key, i int) Key {
 	return nil
 }
 
+func (keys *Keys) delete(key Key) Key {
+	i := keys.search(key)
+	if i == len(*keys) || (*keys)[i].Compare(key) != 0 {
+		return nil
+	}
+
+	oldKey := (*keys)[i]
+	copy((*keys)[i:], (*keys)[i+1:])
+	(*keys)[len(*keys)-1] = nil // for garbage collection
+	*keys = (*keys)[:len(*keys)-1]
+	return oldKey
+}
+
 func (keys *Keys) splitAt(i int) (Keys, Keys) {
 	right := make(Keys, len(*keys)-i-1, cap(*keys))
 	copy(right, (*keys)[i+1:])
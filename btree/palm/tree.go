@@ -43,6 +43,7 @@ type recursiveBuild struct {
 type pending struct {
 	reads    actions
 	writes   Keys
+	deletes  Keys
 	number   uint64
 	signal   *futures.Future
 	signaler chan interface{}
@@ -116,42 +117,59 @@ func (ptree *ptree) runOperations() {
 	}
 
 	var wg sync.WaitGroup
-	var offset int
-	var inserts []*node
 	wg.Add(1) // for the gets
+	go ptree.runReads(toPerform.reads, &wg)
 
+	var writeOperations map[*node]Keys
 	if len(toPerform.writes) > 0 {
-		inserts = make([]*node, len(toPerform.writes))
-		chunks := chunkKeys(toPerform.writes, 8)
-		wg.Add(len(chunks)) // for the inserts
-		go ptree.runReads(toPerform.reads, &wg)
-
-		for _, chunk := range chunks {
-			go func(offset int, chunk Keys) {
-				for i, k := range chunk {
-					n := getParent(ptree.root, k)
-					inserts[offset+i] = n
-				}
-
-				wg.Done()
-			}(offset, chunk)
-			offset += len(chunk)
-		}
-	} else {
-		go ptree.runReads(toPerform.reads, &wg)
+		writeOperations = ptree.groupByLeaf(toPerform.writes)
 	}
 
 	wg.Wait()
-	if len(toPerform.writes) == 0 {
+	if len(toPerform.writes) == 0 && len(toPerform.deletes) == 0 {
 		return
 	}
-	writeOperations := make(map[*node]Keys)
-	for i, n := range inserts {
-		writeOperations[n] = append(writeOperations[n], toPerform.writes[i])
+
+	ptree.runAdds(writeOperations)
+
+	// deletes are applied after the adds in the same round, so the
+	// leaves have to be found against the tree the adds produced
+	if len(toPerform.deletes) > 0 {
+		ptree.runDeletes(ptree.groupByLeaf(toPerform.deletes))
 	}
 
+	// writers are released only once the round is applied so Len
+	// reflects their keys as soon as they return
 	toPerform.signaler <- true
-	ptree.runAdds(writeOperations)
+}
+
+// groupByLeaf finds the leaf each of the provided keys belongs in,
+// in parallel, and returns the keys grouped by those leaves.
+func (ptree *ptree) groupByLeaf(keys Keys) map[*node]Keys {
+	leaves := make([]*node, len(keys))
+	chunks := chunkKeys(keys, 8)
+
+	var wg sync.WaitGroup
+	var offset int
+	wg.Add(len(chunks))
+	for _, chunk := range chunks {
+		go func(offset int, chunk Keys) {
+			for i, k := range chunk {
+				leaves[offset+i] = getParent(ptree.root, k)
+			}
+
+			wg.Done()
+		}(offset, chunk)
+		offset += len(chunk)
+	}
+	wg.Wait()
+
+	operations := make(map[*node]Keys)
+	for i, n := range leaves {
+		operations[n] = append(operations[n], keys[i])
+	}
+
+	return operations
 }
 
 func (ptree *ptree) recursiveSplit(n, parent, left *node, nodes *nodes, keys *Keys) {
@@ -288,11 +306,44 @@ func (ptree *ptree) runAdds(addOperations map[*node]Keys) {
 	ptree.recursiveAdd(nextLayer, setRoot)
 }
 
-// Insert will add the provided keys to the tree.
-func (ptree *ptree) Insert(keys ...Key) {
+// runDeletes removes keys from the leaves they were grouped under.
+// Leaves are not merged as they empty out; the tree's separator keys
+// remain valid for searching, so underfull leaves are simply refilled
+// by later inserts.
+func (ptree *ptree) runDeletes(deleteOperations map[*node]Keys) {
+	if len(deleteOperations) == 0 {
+		return
+	}
+
+	q := queue.New(int64(len(deleteOperations)))
+	for n := range deleteOperations {
+		q.Put(n)
+	}
+
+	queue.ExecuteInParallel(q, func(ifc interface{}) {
+		n := ifc.(*node)
+		for _, key := range deleteOperations[n] {
+			if n.keys.delete(key) != nil {
+				atomic.AddUint64(&ptree.number, ^uint64(0))
+			}
+		}
+	})
+}
+
+// stage adds the provided keys to the pending round and blocks
+// until that round has been applied.
+func (ptree *ptree) stage(keys Keys, deletes bool) {
+	if len(keys) == 0 {
+		return
+	}
+
 	var signaler *futures.Future
 	ptree.lock.Lock()
-	ptree.pending.writes = append(ptree.pending.writes, keys...)
+	if deletes {
+		ptree.pending.deletes = append(ptree.pending.deletes, keys...)
+	} else {
+		ptree.pending.writes = append(ptree.pending.writes, keys...)
+	}
 	ptree.pending.number += uint64(len(keys))
 	signaler = ptree.pending.signal
 	ptree.lock.Unlock()
@@ -301,6 +352,23 @@ func (ptree *ptree) Insert(keys ...Key) {
 	signaler.GetResult()
 }
 
+// Insert will add the provided keys to the tree.
+func (ptree *ptree) Insert(keys ...Key) {
+	ptree.InsertBatch(keys...)
+}
+
+// InsertBatch stages every provided key in a single round so the
+// batch is applied with one traversal of the tree.
+func (ptree *ptree) InsertBatch(keys ...Key) {
+	ptree.stage(keys, false)
+}
+
+// DeleteBatch stages every provided key for removal in a single
+// round so the batch is applied with one traversal of the tree.
+func (ptree *ptree) DeleteBatch(keys ...Key) {
+	ptree.stage(keys, true)
+}
+
 // Get will retrieve a list of keys from the provided keys.
 func (ptree *ptree) Get(keys ...Key) Keys {
 	ga := newGetAction(keys)
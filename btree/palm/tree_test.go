@@ -352,3 +352,148 @@ func BenchmarkBulkGet(b *testing.B) {
 		tree.Get(keys...)
 	}
 }
+
+func TestInsertBatch(t *testing.T) {
+	tree := newTree(16)
+	defer tree.Dispose()
+	keys := generateRandomKeys(1000)
+
+	tree.InsertBatch(keys...)
+	tree.InsertBatch()
+	assert.Equal(t, keys, tree.Get(keys...))
+	assert.Equal(t, uint64(len(keys)), tree.Len())
+	checkTree(t, tree)
+}
+
+func TestDeleteBatch(t *testing.T) {
+	tree := newTree(16)
+	defer tree.Dispose()
+	keys := generateKeys(1000)
+	tree.InsertBatch(keys...)
+
+	deleted := make(Keys, 0, len(keys)/2)
+	for i := 0; i < len(keys); i += 2 {
+		deleted = append(deleted, keys[i])
+	}
+
+	tree.DeleteBatch(deleted...)
+	tree.DeleteBatch(mockKey(-1), mockKey(5000))
+	assert.Equal(t, uint64(len(keys)/2), tree.Len())
+	checkTree(t, tree)
+
+	result := tree.Get(keys...)
+	for i, key := range keys {
+		if i%2 == 0 {
+			assert.Nil(t, result[i])
+		} else {
+			assert.Equal(t, key, result[i])
+		}
+	}
+
+	tree.InsertBatch(deleted...)
+	assert.Equal(t, keys, tree.Get(keys...))
+	assert.Equal(t, uint64(len(keys)), tree.Len())
+
+	tree.DeleteBatch(keys...)
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Equal(t, make(Keys, len(keys)), tree.Get(keys...))
+
+	tree.InsertBatch(keys[:10]...)
+	assert.Equal(t, keys[:10], tree.Get(keys[:10]...))
+	assert.Equal(t, uint64(10), tree.Len())
+}
+
+func TestSimultaneousInsertAndDeleteBatches(t *testing.T) {
+	numLoops := 3
+	keys := make([]Keys, 0, numLoops)
+	for i := 0; i < numLoops; i++ {
+		keys = append(keys, generateKeys(1000))
+		for j := range keys[i] {
+			keys[i][j] = mockKey(i*1000 + j)
+		}
+	}
+
+	tree := newTree(16)
+	defer tree.Dispose()
+	var wg sync.WaitGroup
+	wg.Add(numLoops)
+	for i := 0; i < numLoops; i++ {
+		go func(i int) {
+			tree.InsertBatch(keys[i]...)
+			tree.DeleteBatch(keys[i][:500]...)
+			wg.Done()
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, uint64(numLoops*500), tree.Len())
+	for i := 0; i < numLoops; i++ {
+		assert.Equal(t, make(Keys, 500), tree.Get(keys[i][:500]...))
+		assert.Equal(t, keys[i][500:], tree.Get(keys[i][500:]...))
+	}
+}
+
+func BenchmarkInsertIndividually(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := newTree(16)
+		for _, key := range keys {
+			tree.Insert(key)
+		}
+		tree.Dispose()
+	}
+}
+
+func BenchmarkInsertBatch(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := newTree(16)
+		tree.InsertBatch(keys...)
+		tree.Dispose()
+	}
+}
+
+func BenchmarkDeleteIndividually(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree := newTree(16)
+		tree.InsertBatch(keys...)
+		b.StartTimer()
+
+		for _, key := range keys {
+			tree.DeleteBatch(key)
+		}
+		tree.Dispose()
+	}
+}
+
+func BenchmarkDeleteBatch(b *testing.B) {
+	numItems := 1000
+	keys := generateRandomKeys(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree := newTree(16)
+		tree.InsertBatch(keys...)
+		b.StartTimer()
+
+		tree.DeleteBatch(keys...)
+		tree.Dispose()
+	}
+}
@@ -15,24 +15,40 @@ limitations under the License.
 */
 
 /*
-Package btree/plus implements the ubiquitous B+ tree.  As of this writing,
-the tree is not quite finished.  The delete-node merge functionaly needs
-to be added.  There are also some performance improvements that can be
-made, with some possible concurrency mechanisms.
+Package btree/plus implements the ubiquitous B+ tree.  Deleted keys are
+removed immediately and any node left empty is repaired by borrowing
+from or merging with a sibling.  There are also some performance
+improvements that can be made, with some possible concurrency mechanisms.
 
 This is a mutable b-tree so it is not threadsafe.
 
+BTreeG, created with NewG, is a generic variant that stores keys of a
+single type ordered by a less function, avoiding the interface boxing
+and Compare calls that dominate the cost of BTree operations.
+
 Performance characteristics:
 Space: O(n)
 Insert: O(log n)
 Search: O(log n)
+Delete: O(log n)
+Rank: O(log n)
+ByPosition: O(log n)
 
 BenchmarkIteration-8	   	10000	   		 	109347 ns/op
 BenchmarkInsert-8	 		3000000	       		608 ns/op
 BenchmarkGet-8	 			3000000	       		627 ns/op
+BenchmarkGenericInsert-8	10000000	       		114 ns/op
+BenchmarkGenericGet-8		10000000	       		112 ns/op
 */
 package plus
 
+import (
+	"fmt"
+	"iter"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
 func keySearch(keys keys, key Key) int {
 	low, high := 0, len(keys)-1
 	var mid int
@@ -50,15 +66,30 @@ func keySearch(keys keys, key Key) int {
 	return low
 }
 
-type btree struct {
+// BTree is a B+ tree whose leaves are linked in key order.  It is
+// not threadsafe.
+type BTree struct {
 	root             node
 	nodeSize, number uint64
+	// free, if not nil, keeps merged away nodes so splits can
+	// reuse them.
+	free *freeList
+	// metrics, if not nil, receives counts of the operations
+	// performed on this tree.
+	metrics common.Metrics
+	// version is bumped by every insert and delete so iterators
+	// can tell that the leaves they point into may have changed.
+	version uint64
 }
 
-func (tree *btree) insert(key Key) {
+func (tree *BTree) insert(key Key) {
+	tree.report(common.MetricInserts, 1)
+	tree.version++
 	if tree.root == nil {
-		n := newLeafNode(tree.nodeSize)
+		n := tree.free.leaf(tree.nodeSize)
+		tree.report(common.MetricNodes, 1)
 		n.insert(tree, key)
+		tree.root = n
 		tree.number = 1
 		return
 	}
@@ -76,7 +107,7 @@ func (tree *btree) insert(key Key) {
 // Insert will insert the provided keys into the btree.  This is an
 // O(m*log n) operation where m is the number of keys to be inserted
 // and n is the number of items in the tree.
-func (tree *btree) Insert(keys ...Key) {
+func (tree *BTree) Insert(keys ...Key) {
 	for _, key := range keys {
 		tree.insert(key)
 	}
@@ -84,15 +115,62 @@ func (tree *btree) Insert(keys ...Key) {
 
 // Iter returns an iterator that can be used to traverse the b-tree
 // starting from the specified key or its successor.
-func (tree *btree) Iter(key Key) Iterator {
+func (tree *BTree) Iter(key Key) Iterator {
+	if tree.root == nil {
+		return nilIterator()
+	}
+
+	iter := tree.root.find(key)
+	iter.watch(tree, key)
+	return iter
+}
+
+// IterRange returns an iterator that traverses the keys equal to
+// or greater than start and less than stop.  A nil stop leaves the
+// range unbounded above.
+func (tree *BTree) IterRange(start, stop Key) Iterator {
 	if tree.root == nil {
 		return nilIterator()
 	}
 
-	return tree.root.find(key)
+	iter := tree.root.find(start)
+	iter.watch(tree, start)
+	iter.stop = stop
+	return iter
+}
+
+// SeekLast returns an iterator positioned after the last key in the
+// tree so that successive calls to Prev traverse the tree in reverse.
+func (tree *BTree) SeekLast() Iterator {
+	if tree.root == nil || tree.number == 0 {
+		return nilIterator()
+	}
+
+	iter := tree.last()
+	iter.watch(tree, nil)
+	return iter
+}
+
+// last returns an iterator parked after the last key in the tree.
+func (tree *BTree) last() *iterator {
+	n := tree.root
+	for {
+		in, ok := n.(*inode)
+		if !ok {
+			break
+		}
+		n = in.nodes[len(in.nodes)-1]
+	}
+
+	leaf := n.(*lnode)
+	return &iterator{
+		node:  leaf,
+		index: len(leaf.keys) - 1,
+	}
 }
 
-func (tree *btree) get(key Key) Key {
+func (tree *BTree) get(key Key) Key {
+	tree.report(common.MetricSearches, 1)
 	iter := tree.root.find(key)
 	if !iter.Next() {
 		return nil
@@ -108,7 +186,7 @@ func (tree *btree) get(key Key) Key {
 // Get will retrieve any keys matching the provided keys in the tree.
 // Returns nil in any place of a key that couldn't be found.  Each lookup
 // is an O(log n) operation.
-func (tree *btree) Get(keys ...Key) Keys {
+func (tree *BTree) Get(keys ...Key) Keys {
 	results := make(Keys, 0, len(keys))
 	for _, k := range keys {
 		results = append(results, tree.get(k))
@@ -117,14 +195,386 @@ func (tree *btree) Get(keys ...Key) Keys {
 	return results
 }
 
+// walk calls fn with the keys from the i'th key of the leaf onward in
+// order until fn returns false or a key equal to or greater than stop
+// is reached.  A nil stop leaves the walk unbounded above.  If fn
+// modifies the tree the walk continues after the last key passed to
+// it as an iterator would.
+func (tree *BTree) walk(leaf *lnode, i int, stop Key, fn func(Key) bool) {
+	version := tree.version
+	for ; leaf != nil; leaf, i = leaf.pointer, 0 {
+		for _, key := range leaf.keys[i:] {
+			// keys compare as 1 against greater keys so anything
+			// less than 1 is at or beyond stop
+			if stop != nil && key.Compare(stop) < 1 {
+				return
+			}
+
+			if !fn(key) {
+				return
+			}
+
+			if tree.version != version {
+				iter := &iterator{
+					tree:    tree,
+					version: version,
+					at:      key,
+					started: true,
+					stop:    stop,
+				}
+				for iter.Next() {
+					if !fn(iter.Value()) {
+						return
+					}
+				}
+				return
+			}
+		}
+	}
+}
+
+// Each will call the provided function with every key in the
+// tree in order until the function returns false.  This walks the
+// linked leaves directly and performs no allocations unless the
+// function modifies the tree, in which case the walk continues
+// after the last key passed to the function as an iterator would.
+func (tree *BTree) Each(fn func(Key) bool) {
+	tree.Range(nil, nil, fn)
+}
+
+// All returns a sequence of every key in the tree in order for use
+// with range.  Like Each, it walks the linked leaves directly.
+func (tree *BTree) All() iter.Seq[Key] {
+	return func(yield func(Key) bool) {
+		tree.Each(yield)
+	}
+}
+
+// Range calls the provided function with every key equal to or
+// greater than start and less than stop in order until the function
+// returns false.  A nil start begins at the least key and a nil stop
+// leaves the range unbounded above.  Like Each, this walks the linked
+// leaves directly and performs no allocations, making it cheaper than
+// IterRange for tight scans.
+func (tree *BTree) Range(start, stop Key, fn func(Key) bool) {
+	if tree.root == nil {
+		return
+	}
+
+	n := tree.root
+	for {
+		in, ok := n.(*inode)
+		if !ok {
+			break
+		}
+
+		i := 0
+		if start != nil {
+			i = in.childIndex(start)
+		}
+		n = in.nodes[i]
+	}
+
+	leaf, i := n.(*lnode), 0
+	if start != nil {
+		i = leaf.search(start)
+	}
+	tree.walk(leaf, i, stop, fn)
+}
+
+func (tree *BTree) delete(key Key) Key {
+	deleted := tree.root.delete(tree, key)
+	if deleted == nil {
+		return nil
+	}
+
+	tree.number--
+	tree.version++
+	tree.report(common.MetricDeletes, 1)
+	if in, ok := tree.root.(*inode); ok && len(in.keys) == 0 {
+		tree.root = in.nodes[0]
+		tree.release(in)
+	}
+
+	return deleted
+}
+
+// Delete will remove the provided keys from the tree and return the
+// removed keys in the same order.  A nil is returned in any place of
+// a key that couldn't be found.  Each delete is an O(log n) operation.
+func (tree *BTree) Delete(keys ...Key) Keys {
+	deleted := make(Keys, 0, len(keys))
+	for _, k := range keys {
+		deleted = append(deleted, tree.delete(k))
+	}
+
+	return deleted
+}
+
+// Floor returns the greatest key in the tree less than or equal to
+// the provided key, or nil if there is no such key.  This is an
+// O(log n) operation.
+func (tree *BTree) Floor(key Key) Key {
+	// prev is the nearest subtree whose keys are all less than key
+	var prev node
+	n := tree.root
+	for {
+		in, ok := n.(*inode)
+		if !ok {
+			break
+		}
+
+		i := in.childIndex(key)
+		if i > 0 {
+			prev = in.nodes[i-1]
+		}
+		n = in.nodes[i]
+	}
+
+	leaf := n.(*lnode)
+	i := leaf.search(key)
+	if i < len(leaf.keys) && leaf.keys[i].Compare(key) == 0 {
+		return leaf.keys[i]
+	}
+
+	if i > 0 {
+		return leaf.keys[i-1]
+	}
+
+	if prev == nil {
+		return nil
+	}
+
+	for {
+		in, ok := prev.(*inode)
+		if !ok {
+			break
+		}
+		prev = in.nodes[len(in.nodes)-1]
+	}
+
+	leaf = prev.(*lnode)
+	return leaf.keys[len(leaf.keys)-1]
+}
+
+// Rank returns the number of keys in the tree less than the provided
+// key, which is the position the key holds or would hold if it were
+// inserted.  This is an O(log n) operation.
+func (tree *BTree) Rank(key Key) uint64 {
+	if tree.root == nil {
+		return 0
+	}
+
+	var rank uint64
+	n := tree.root
+	for {
+		in, ok := n.(*inode)
+		if !ok {
+			break
+		}
+
+		i := in.childIndex(key)
+		for _, child := range in.nodes[:i] {
+			rank += child.count()
+		}
+		n = in.nodes[i]
+	}
+
+	return rank + uint64(n.(*lnode).search(key))
+}
+
+// ByPosition returns the key at the provided position in key order,
+// or nil if the position is out of bounds.  This is an O(log n)
+// operation.
+func (tree *BTree) ByPosition(position uint64) Key {
+	if position >= tree.number {
+		return nil
+	}
+
+	n := tree.root
+	for {
+		in, ok := n.(*inode)
+		if !ok {
+			break
+		}
+
+		i := 0
+		for ; position >= in.nodes[i].count(); i++ {
+			position -= in.nodes[i].count()
+		}
+		n = in.nodes[i]
+	}
+
+	return n.(*lnode).keys[position]
+}
+
 // Len returns the number of items in this tree.
-func (tree *btree) Len() uint64 {
+func (tree *BTree) Len() uint64 {
 	return tree.number
 }
 
-func newBTree(nodeSize uint64) *btree {
-	return &btree{
+// SizeOf returns an estimate of the number of bytes used by this tree,
+// including its nodes, any nodes kept for reuse, and key slices but not
+// the keys themselves.  This is an O(n) operation.
+func (tree *BTree) SizeOf() uint64 {
+	size := treeSize
+	if tree.free != nil {
+		for _, n := range tree.free.leaves {
+			size += n.sizeOf()
+		}
+		for _, n := range tree.free.internals {
+			size += n.sizeOf()
+		}
+	}
+
+	if tree.root == nil {
+		return size
+	}
+
+	return size + tree.root.sizeOf()
+}
+
+// validateNode checks the keys in n are ordered, within the bounds
+// given by the parent, and fit within the node size.  Leaves are
+// appended to leaves in key order.  A nil bound is unbounded.
+func (tree *BTree) validateNode(n node, lo, hi Key, depth int,
+	leaves *[]*lnode, leafDepth *int) error {
+
+	var ks keys
+	switch n := n.(type) {
+	case *inode:
+		ks = n.keys
+	case *lnode:
+		ks = n.keys
+	default:
+		return fmt.Errorf(`Unknown node type %T at depth %d.`, n, depth)
+	}
+
+	if uint64(len(ks)) >= tree.nodeSize {
+		return fmt.Errorf(`Node at depth %d has %d keys, node size is %d.`,
+			depth, len(ks), tree.nodeSize)
+	}
+
+	if depth > 0 && len(ks) == 0 {
+		return fmt.Errorf(`Non-root node at depth %d is empty.`, depth)
+	}
+
+	for i, k := range ks {
+		if i > 0 && ks[i-1].Compare(k) != 1 {
+			return fmt.Errorf(`Keys at depth %d are out of order at index %d.`, depth, i)
+		}
+		if lo != nil && lo.Compare(k) == -1 {
+			return fmt.Errorf(`Key at depth %d index %d is below its lower bound.`, depth, i)
+		}
+		if hi != nil && hi.Compare(k) != -1 {
+			return fmt.Errorf(`Key at depth %d index %d is not below its upper bound.`, depth, i)
+		}
+	}
+
+	in, ok := n.(*inode)
+	if !ok {
+		if *leafDepth == -1 {
+			*leafDepth = depth
+		} else if *leafDepth != depth {
+			return fmt.Errorf(`Leaf at depth %d, expected all leaves at depth %d.`,
+				depth, *leafDepth)
+		}
+		*leaves = append(*leaves, n.(*lnode))
+		return nil
+	}
+
+	if len(in.nodes) != len(in.keys)+1 {
+		return fmt.Errorf(`Internal node at depth %d has %d keys and %d children.`,
+			depth, len(in.keys), len(in.nodes))
+	}
+
+	var number uint64
+	for _, child := range in.nodes {
+		number += child.count()
+	}
+	if number != in.number {
+		return fmt.Errorf(`Internal node at depth %d counts %d keys, found %d.`,
+			depth, in.number, number)
+	}
+
+	for i, child := range in.nodes {
+		childLo, childHi := lo, hi
+		if i > 0 {
+			childLo = in.keys[i-1]
+		}
+		if i < len(in.keys) {
+			childHi = in.keys[i]
+		}
+
+		if err := tree.validateNode(child, childLo, childHi, depth+1, leaves, leafDepth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate walks the entire tree and returns an error describing the
+// first violated invariant, such as keys out of order, a node that
+// should have been split, leaves at differing depths, a broken leaf
+// chain, a stale subtree count, or a length that doesn't match the number of keys.  This is
+// an O(n) operation intended for tests and fuzzing.
+func (tree *BTree) Validate() error {
+	if tree.root == nil {
+		return fmt.Errorf(`Tree has no root.`)
+	}
+
+	leaves := make([]*lnode, 0, tree.number/tree.nodeSize+1)
+	leafDepth := -1
+	if err := tree.validateNode(tree.root, nil, nil, 0, &leaves, &leafDepth); err != nil {
+		return err
+	}
+
+	var number uint64
+	for i, leaf := range leaves {
+		number += uint64(len(leaf.keys))
+		var next, prev *lnode
+		if i < len(leaves)-1 {
+			next = leaves[i+1]
+		}
+		if i > 0 {
+			prev = leaves[i-1]
+		}
+		if leaf.pointer != next {
+			return fmt.Errorf(`Leaf %d does not point to its right sibling.`, i)
+		}
+		if leaf.prev != prev {
+			return fmt.Errorf(`Leaf %d does not point to its left sibling.`, i)
+		}
+	}
+
+	if number != tree.number {
+		return fmt.Errorf(`Found %d keys, expected %d.`, number, tree.number)
+	}
+
+	return nil
+}
+
+func newBTree(nodeSize uint64) *BTree {
+	return &BTree{
 		nodeSize: nodeSize,
 		root:     newLeafNode(nodeSize),
 	}
 }
+
+// New returns an empty B+ tree with nodes holding fewer than nodeSize
+// keys.  The node size must be at least 3.
+func New(nodeSize uint64) *BTree {
+	return newBTree(nodeSize)
+}
+
+// NewWithFreeList returns an empty B+ tree as New does that keeps up
+// to size nodes emptied by deletes and reuses them when nodes split.
+// This greatly reduces garbage in workloads that insert and delete
+// heavily.  As a node may be reused immediately, an iterator must not
+// be used after the tree has been modified.
+func NewWithFreeList(nodeSize uint64, size int) *BTree {
+	tree := newBTree(nodeSize)
+	tree.free = newFreeList(size)
+	return tree
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package plus
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 func TestSearchKeys(t *testing.T) {
@@ -391,3 +394,784 @@ func BenchmarkReadAndWrites(b *testing.B) {
 		tree.Get(ks[i]...)
 	}
 }
+
+func TestEach(t *testing.T) {
+	tree := newBTree(3)
+	tree.Insert(constructRandomMockKeys(100)...)
+	expected := tree.Iter(newMockKey(-1)).exhaust()
+
+	result := make(keys, 0, len(expected))
+	tree.Each(func(k Key) bool {
+		result = append(result, k)
+		return true
+	})
+	assert.Equal(t, expected, result)
+
+	result = result[:0]
+	tree.Each(func(k Key) bool {
+		result = append(result, k)
+		return len(result) < 10
+	})
+	assert.Equal(t, expected[:10], result)
+
+	fn := func(Key) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { tree.Each(fn) }))
+}
+
+func TestEachEmptyTree(t *testing.T) {
+	tree := newBTree(3)
+	called := false
+	tree.Each(func(Key) bool {
+		called = true
+		return true
+	})
+	assert.False(t, called)
+}
+
+func TestAll(t *testing.T) {
+	tree := newBTree(3)
+	tree.Insert(constructRandomMockKeys(100)...)
+	expected := tree.Iter(newMockKey(-1)).exhaust()
+
+	result := make(keys, 0, len(expected))
+	for k := range tree.All() {
+		result = append(result, k)
+	}
+	assert.Equal(t, expected, result)
+
+	result = result[:0]
+	for k := range tree.All() {
+		result = append(result, k)
+		if len(result) == 10 {
+			break
+		}
+	}
+	assert.Equal(t, expected[:10], result)
+
+	for range newBTree(3).All() {
+		assert.Fail(t, `empty tree yielded a key`)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tree := newBTree(3)
+	collect := func(start, stop Key) keys {
+		result := keys{}
+		tree.Range(start, stop, func(k Key) bool {
+			result = append(result, k)
+			return true
+		})
+		return result
+	}
+	assert.Len(t, collect(newMockKey(0), newMockKey(5)), 0)
+
+	ks := constructMockKeys(100)
+	tree.Insert(ks...)
+
+	assert.Equal(t, ks[10:20], collect(newMockKey(10), newMockKey(20)))
+	assert.Equal(t, ks[95:], collect(newMockKey(95), nil))
+	assert.Equal(t, ks[:5], collect(nil, newMockKey(5)))
+	assert.Equal(t, ks, collect(nil, nil))
+	assert.Len(t, collect(newMockKey(10), newMockKey(10)), 0)
+	assert.Len(t, collect(newMockKey(200), newMockKey(300)), 0)
+
+	result := keys{}
+	tree.Range(newMockKey(50), nil, func(k Key) bool {
+		result = append(result, k)
+		return len(result) < 3
+	})
+	assert.Equal(t, ks[50:53], result)
+
+	// deleting the key visited resumes after it
+	result = result[:0]
+	tree.Range(newMockKey(30), newMockKey(60), func(k Key) bool {
+		result = append(result, k)
+		tree.Delete(k)
+		return true
+	})
+	assert.Equal(t, ks[30:60], result)
+	assert.Equal(t, uint64(70), tree.Len())
+
+	fn := func(Key) bool { return true }
+	start, stop := newMockKey(10), newMockKey(90)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() {
+		tree.Range(start, stop, fn)
+	}))
+}
+
+func BenchmarkRange(b *testing.B) {
+	numItems := 1000
+	tree := newBTree(64)
+	tree.Insert(constructMockKeys(numItems)...)
+	fn := func(Key) bool { return true }
+	start, stop := newMockKey(numItems/4), newMockKey(numItems*3/4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Range(start, stop, fn)
+	}
+}
+
+func BenchmarkEach(b *testing.B) {
+	numItems := 1000
+	tree := newBTree(64)
+	tree.Insert(constructMockKeys(numItems)...)
+	fn := func(Key) bool { return true }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Each(fn)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tree := newBTree(3)
+	assert.Nil(t, tree.Validate())
+
+	tree.Insert(constructRandomMockKeys(1000)...)
+	assert.Nil(t, tree.Validate())
+
+	tree = newBTree(64)
+	tree.Insert(constructMockKeys(1000)...)
+	assert.Nil(t, tree.Validate())
+	reversed := constructMockKeys(2000)
+	reversed.reverse()
+	tree.Insert(reversed...)
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, uint64(2000), tree.Len())
+}
+
+func TestValidateCorruption(t *testing.T) {
+	tree := newBTree(3)
+	tree.Insert(constructMockKeys(20)...)
+	if !assert.Nil(t, tree.Validate()) {
+		return
+	}
+
+	tree.number++
+	assert.NotNil(t, tree.Validate())
+	tree.number--
+
+	n := tree.root
+	for in, ok := n.(*inode); ok; in, ok = n.(*inode) {
+		n = in.nodes[0]
+	}
+	leaf := n.(*lnode)
+
+	key := leaf.keys[0]
+	leaf.keys[0] = newMockKey(100)
+	assert.NotNil(t, tree.Validate())
+	leaf.keys[0] = key
+
+	pointer := leaf.pointer
+	leaf.pointer = nil
+	assert.NotNil(t, tree.Validate())
+	leaf.pointer = pointer
+
+	in := tree.root.(*inode)
+	in.number++
+	assert.NotNil(t, tree.Validate())
+	in.number--
+
+	assert.Nil(t, tree.Validate())
+}
+
+func TestMetrics(t *testing.T) {
+	tree := newBTree(3)
+	keys := constructRandomMockKeys(1000)
+	tree.Insert(keys[:10]...)
+	metrics := newMockMetrics()
+	tree.SetMetrics(metrics)
+	assert.Equal(t, countNodes(tree.root), metrics.counts[common.MetricNodes])
+
+	tree.Insert(keys...)
+	tree.Get(keys[:5]...)
+	assert.Equal(t, int64(1000), metrics.counts[common.MetricInserts])
+	assert.Equal(t, int64(5), metrics.counts[common.MetricSearches])
+	assert.Equal(t, countNodes(tree.root), metrics.counts[common.MetricNodes])
+
+	tree.Delete(keys[:900]...)
+	assert.Equal(t, int64(900), metrics.counts[common.MetricDeletes])
+	assert.Equal(t, countNodes(tree.root), metrics.counts[common.MetricNodes])
+
+	tree.Delete(keys...)
+	assert.Equal(t, int64(1000), metrics.counts[common.MetricDeletes])
+	assert.Equal(t, int64(1), metrics.counts[common.MetricNodes])
+}
+
+func TestFillFactor(t *testing.T) {
+	tree := newBTree(5)
+	assert.Equal(t, float64(0), tree.FillFactor())
+
+	tree.Insert(constructMockKeys(2)...)
+	assert.Equal(t, .5, tree.FillFactor())
+
+	tree.Insert(constructMockKeys(1000)...)
+	fill := tree.FillFactor()
+	assert.True(t, fill > 0 && fill <= 1)
+}
+
+func TestSizeOf(t *testing.T) {
+	tree := newBTree(8)
+	empty := treeSize + lnodeSize + 8*keySize
+	assert.Equal(t, empty, tree.SizeOf())
+
+	tree.Insert(newMockKey(1))
+	assert.Equal(t, empty, tree.SizeOf())
+
+	tree.Insert(constructMockKeys(1000)...)
+	size := tree.SizeOf()
+	assert.True(t, size > empty+1000*keySize)
+
+	tree.Insert(constructMockKeys(1000)...) // overwrites
+	assert.Equal(t, size, tree.SizeOf())
+}
+
+func TestDelete(t *testing.T) {
+	tree := newBTree(3)
+	keys := constructMockKeys(100)
+	tree.Insert(keys...)
+
+	deleted := tree.Delete(keys[50], newMockKey(1000))
+	assert.Equal(t, Keys{keys[50], nil}, deleted)
+	assert.Equal(t, uint64(99), tree.Len())
+	assert.Equal(t, Keys{nil, keys[49]}, tree.Get(keys[50], keys[49]))
+	assert.Nil(t, tree.Validate())
+
+	for i, key := range keys {
+		if i == 50 {
+			continue
+		}
+		tree.Delete(key)
+		if !assert.Nil(t, tree.Validate()) {
+			return
+		}
+	}
+
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Len(t, tree.Iter(newMockKey(-1)).exhaust(), 0)
+
+	tree.Insert(keys...)
+	assert.Equal(t, keys, tree.Iter(newMockKey(-1)).exhaust())
+}
+
+func TestDeleteRandom(t *testing.T) {
+	for _, nodeSize := range []uint64{3, 4, 7, 64} {
+		tree := newBTree(nodeSize)
+		keys := constructRandomMockKeys(1000)
+		tree.Insert(keys...)
+
+		for i := 0; i < len(keys); i += 2 {
+			tree.Delete(keys[i])
+		}
+		if !assert.Nil(t, tree.Validate()) {
+			return
+		}
+
+		for i, key := range keys {
+			if i%2 == 0 {
+				assert.Nil(t, tree.Get(key)[0])
+			} else {
+				assert.Equal(t, key, tree.Get(key)[0])
+			}
+		}
+
+		for i := 1; i < len(keys); i += 2 {
+			tree.Delete(keys[i])
+		}
+		assert.Nil(t, tree.Validate())
+		assert.Equal(t, uint64(0), tree.Len())
+	}
+}
+
+// buildLeaves returns linked leaves holding the provided values.
+func buildLeaves(values ...[]int) nodes {
+	leaves := make(nodes, 0, len(values))
+	for i, vs := range values {
+		leaf := newLeafNode(4)
+		for _, v := range vs {
+			leaf.keys = append(leaf.keys, newMockKey(v))
+		}
+		if i > 0 {
+			prev := leaves[i-1].(*lnode)
+			prev.pointer, leaf.prev = leaf, prev
+		}
+		leaves = append(leaves, leaf)
+	}
+
+	return leaves
+}
+
+// buildInternal returns an internal node over the provided children,
+// each separated by the first key below it.
+func buildInternal(children ...node) *inode {
+	in := newInternalNode(4)
+	for i, child := range children {
+		if i > 0 {
+			first := child
+			for n, ok := first.(*inode); ok; n, ok = first.(*inode) {
+				first = n.nodes[0]
+			}
+			in.keys = append(in.keys, first.(*lnode).keys[0])
+		}
+		in.nodes = append(in.nodes, child)
+	}
+	in.recount()
+	return in
+}
+
+// buildTree returns a tree of node size 4 with the provided root.
+func buildTree(root node) *BTree {
+	tree := newBTree(4)
+	tree.root = root
+	tree.number = root.count()
+	return tree
+}
+
+// leafValues returns the values in every leaf, following the leaf
+// chain from the leftmost leaf and checking the links back.
+func leafValues(t *testing.T, tree *BTree) [][]int {
+	n := tree.root
+	for in, ok := n.(*inode); ok; in, ok = n.(*inode) {
+		n = in.nodes[0]
+	}
+
+	var result [][]int
+	var prev *lnode
+	for leaf := n.(*lnode); leaf != nil; leaf = leaf.pointer {
+		assert.True(t, leaf.prev == prev)
+		values := []int{}
+		for _, key := range leaf.keys {
+			values = append(values, key.(*mockKey).value)
+		}
+		result = append(result, values)
+		prev = leaf
+	}
+
+	return result
+}
+
+func keyValues(ks keys) []int {
+	values := make([]int, 0, len(ks))
+	for _, key := range ks {
+		values = append(values, key.(*mockKey).value)
+	}
+
+	return values
+}
+
+func TestDeleteBorrowsFromLeftLeaf(t *testing.T) {
+	leaves := buildLeaves([]int{1, 2}, []int{3})
+	tree := buildTree(buildInternal(leaves...))
+
+	assert.Equal(t, newMockKey(3), tree.Delete(newMockKey(3))[0])
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{1}, {2}}, leafValues(t, tree))
+	assert.Equal(t, []int{2}, keyValues(tree.root.(*inode).keys))
+}
+
+func TestDeleteBorrowsFromRightLeaf(t *testing.T) {
+	leaves := buildLeaves([]int{1}, []int{2, 3})
+	tree := buildTree(buildInternal(leaves...))
+
+	tree.Delete(newMockKey(1))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{2}, {3}}, leafValues(t, tree))
+	assert.Equal(t, []int{3}, keyValues(tree.root.(*inode).keys))
+}
+
+func TestDeleteMergesLeaves(t *testing.T) {
+	// a middle leaf is merged into its left sibling
+	leaves := buildLeaves([]int{1}, []int{2}, []int{3})
+	tree := buildTree(buildInternal(leaves...))
+	tree.Delete(newMockKey(2))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{1}, {3}}, leafValues(t, tree))
+	assert.Equal(t, []int{3}, keyValues(tree.root.(*inode).keys))
+
+	// the first leaf takes the keys of its right sibling so the leaf
+	// before it, possibly under another parent, stays linked
+	leaves = buildLeaves([]int{1}, []int{2}, []int{3})
+	tree = buildTree(buildInternal(leaves...))
+	tree.Delete(newMockKey(1))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{2}, {3}}, leafValues(t, tree))
+	assert.True(t, tree.root.(*inode).nodes[0] == leaves[0])
+	assert.Equal(t, []int{3}, keyValues(tree.root.(*inode).keys))
+}
+
+func TestDeleteRotatesFromLeftInternal(t *testing.T) {
+	leaves := buildLeaves([]int{1}, []int{2}, []int{3}, []int{4}, []int{5})
+	left, right := buildInternal(leaves[:3]...), buildInternal(leaves[3:]...)
+	tree := buildTree(buildInternal(left, right))
+
+	// the right internal node is left with a single child and takes
+	// the last child of its left sibling
+	tree.Delete(newMockKey(5))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{1}, {2}, {3}, {4}}, leafValues(t, tree))
+	assert.Equal(t, []int{3}, keyValues(tree.root.(*inode).keys))
+	assert.Equal(t, []int{2}, keyValues(left.keys))
+	assert.Equal(t, []int{4}, keyValues(right.keys))
+	assert.Equal(t, uint64(2), right.number)
+}
+
+func TestDeleteRotatesFromRightInternal(t *testing.T) {
+	leaves := buildLeaves([]int{1}, []int{2}, []int{3}, []int{4}, []int{5})
+	left, right := buildInternal(leaves[:2]...), buildInternal(leaves[2:]...)
+	tree := buildTree(buildInternal(left, right))
+
+	tree.Delete(newMockKey(1))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, [][]int{{2}, {3}, {4}, {5}}, leafValues(t, tree))
+	assert.Equal(t, []int{4}, keyValues(tree.root.(*inode).keys))
+	assert.Equal(t, []int{3}, keyValues(left.keys))
+	assert.Equal(t, []int{5}, keyValues(right.keys))
+	assert.Equal(t, uint64(2), left.number)
+}
+
+func TestDeleteMergesInternalAndCollapsesRoot(t *testing.T) {
+	leaves := buildLeaves([]int{1}, []int{2}, []int{3}, []int{4})
+	left, right := buildInternal(leaves[:2]...), buildInternal(leaves[2:]...)
+	tree := buildTree(buildInternal(left, right))
+
+	// neither internal node can spare a child, so they merge and the
+	// root, left with a single child, is replaced by it
+	tree.Delete(newMockKey(4))
+	assert.Nil(t, tree.Validate())
+	assert.True(t, tree.root == node(left))
+	assert.Equal(t, []int{2, 3}, keyValues(left.keys))
+	assert.Equal(t, [][]int{{1}, {2}, {3}}, leafValues(t, tree))
+
+	// down to two leaves and then to a leaf root
+	tree.Delete(newMockKey(3))
+	tree.Delete(newMockKey(1))
+	assert.Nil(t, tree.Validate())
+	assert.IsType(t, &lnode{}, tree.root)
+	assert.Equal(t, [][]int{{2}}, leafValues(t, tree))
+
+	tree.Delete(newMockKey(2))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, uint64(0), tree.Len())
+}
+
+func TestFreeList(t *testing.T) {
+	for _, nodeSize := range []uint64{3, 4, 7, 64} {
+		tree := NewWithFreeList(nodeSize, 1000)
+		keys := constructRandomMockKeys(1000)
+		tree.Insert(keys...)
+
+		tree.Delete(keys[:990]...)
+		if !assert.Nil(t, tree.Validate()) {
+			return
+		}
+		assert.True(t, len(tree.free.leaves) > 0)
+		for _, leaf := range tree.free.leaves {
+			assert.Len(t, leaf.keys, 0)
+			assert.Nil(t, leaf.pointer)
+			assert.Nil(t, leaf.prev)
+		}
+		for _, in := range tree.free.internals {
+			assert.Len(t, in.nodes, 0)
+			assert.Equal(t, uint64(0), in.number)
+		}
+
+		tree.Insert(keys[:990]...)
+		if !assert.Nil(t, tree.Validate()) {
+			return
+		}
+		assert.Equal(t, Keys(keys), tree.Get(keys...))
+
+		tree.Delete(keys...)
+		assert.Nil(t, tree.Validate())
+		assert.Equal(t, uint64(0), tree.Len())
+	}
+}
+
+func TestFreeListLimit(t *testing.T) {
+	tree := NewWithFreeList(3, 10)
+	keys := constructMockKeys(1000)
+	tree.Insert(keys...)
+	tree.Delete(keys...)
+	assert.Equal(t, 10, len(tree.free.leaves)+len(tree.free.internals))
+	assert.Nil(t, New(3).free)
+}
+
+func TestFloor(t *testing.T) {
+	tree := newBTree(3)
+	assert.Nil(t, tree.Floor(newMockKey(5)))
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(newMockKey(i * 2))
+	}
+
+	assert.Nil(t, tree.Floor(newMockKey(-1)))
+	for i := 0; i < 200; i++ {
+		assert.Equal(t, newMockKey(i/2*2), tree.Floor(newMockKey(i)))
+	}
+	assert.Equal(t, newMockKey(198), tree.Floor(newMockKey(1000)))
+}
+
+func TestRankByPosition(t *testing.T) {
+	tree := newBTree(3)
+	assert.Equal(t, uint64(0), tree.Rank(newMockKey(5)))
+	assert.Nil(t, tree.ByPosition(0))
+
+	for _, i := range rand.Perm(500) {
+		tree.Insert(newMockKey(i * 2))
+	}
+
+	for i := 0; i < 500; i++ {
+		assert.Equal(t, newMockKey(i*2), tree.ByPosition(uint64(i)))
+		assert.Equal(t, uint64(i), tree.Rank(newMockKey(i*2)))
+		assert.Equal(t, uint64(i+1), tree.Rank(newMockKey(i*2+1)))
+	}
+	assert.Equal(t, uint64(0), tree.Rank(newMockKey(-1)))
+	assert.Nil(t, tree.ByPosition(500))
+
+	// overwriting a key leaves the counts alone
+	tree.Insert(newMockKey(10))
+	assert.Nil(t, tree.Validate())
+	assert.Equal(t, uint64(5), tree.Rank(newMockKey(10)))
+}
+
+func TestRankByPositionAfterDelete(t *testing.T) {
+	for _, nodeSize := range []uint64{3, 4, 7, 64} {
+		tree := newBTree(nodeSize)
+		for i := 0; i < 1000; i++ {
+			tree.Insert(newMockKey(i))
+		}
+
+		for _, i := range rand.Perm(1000)[:600] {
+			tree.Delete(newMockKey(i))
+		}
+		if !assert.Nil(t, tree.Validate()) {
+			return
+		}
+
+		var position uint64
+		tree.Each(func(k Key) bool {
+			assert.Equal(t, k, tree.ByPosition(position))
+			assert.Equal(t, position, tree.Rank(k))
+			position++
+			return true
+		})
+		assert.Equal(t, uint64(400), position)
+		assert.Nil(t, tree.ByPosition(400))
+	}
+}
+
+func TestIterRange(t *testing.T) {
+	tree := newBTree(3)
+	assert.Len(t, tree.IterRange(newMockKey(0), newMockKey(5)).exhaust(), 0)
+
+	keys := constructMockKeys(100)
+	tree.Insert(keys...)
+
+	assert.Equal(t, keys[10:20], tree.IterRange(newMockKey(10), newMockKey(20)).exhaust())
+	assert.Equal(t, keys[95:], tree.IterRange(newMockKey(95), nil).exhaust())
+	assert.Equal(t, keys[:1], tree.IterRange(newMockKey(-5), newMockKey(1)).exhaust())
+	assert.Len(t, tree.IterRange(newMockKey(10), newMockKey(10)).exhaust(), 0)
+	assert.Len(t, tree.IterRange(newMockKey(200), newMockKey(300)).exhaust(), 0)
+
+	iter := tree.IterRange(newMockKey(98), newMockKey(99))
+	assert.True(t, iter.Next())
+	assert.False(t, iter.Next())
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+}
+
+func BenchmarkDelete(b *testing.B) {
+	numItems := 1000
+	trees := make([]*BTree, 0, b.N)
+	keys := constructRandomMockKeys(numItems)
+	for i := 0; i < b.N; i++ {
+		tree := New(64)
+		tree.Insert(keys...)
+		trees = append(trees, tree)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		trees[i].Delete(keys...)
+	}
+}
+
+func BenchmarkChurn(b *testing.B) {
+	keys := constructRandomMockKeys(1000)
+	tree := New(16)
+	tree.Insert(keys...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Delete(keys...)
+		tree.Insert(keys...)
+	}
+}
+
+func BenchmarkChurnFreeList(b *testing.B) {
+	keys := constructRandomMockKeys(1000)
+	tree := NewWithFreeList(16, 100)
+	tree.Insert(keys...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Delete(keys...)
+		tree.Insert(keys...)
+	}
+}
+
+func TestPrev(t *testing.T) {
+	for _, nodeSize := range []uint64{3, 4, 64} {
+		tree := newBTree(nodeSize)
+		assert.False(t, tree.SeekLast().Prev())
+
+		keys := constructMockKeys(100)
+		tree.Insert(keys...)
+
+		iter := tree.SeekLast()
+		assert.Nil(t, iter.Value())
+		for i := len(keys) - 1; i >= 0; i-- {
+			if !assert.True(t, iter.Prev()) {
+				return
+			}
+			assert.Equal(t, keys[i], iter.Value())
+		}
+		assert.False(t, iter.Prev())
+		assert.False(t, iter.Next())
+		assert.Nil(t, iter.Value())
+
+		iter = tree.Iter(newMockKey(50))
+		assert.True(t, iter.Prev())
+		assert.Equal(t, keys[49], iter.Value())
+
+		iter = tree.Iter(newMockKey(50))
+		assert.True(t, iter.Next())
+		assert.True(t, iter.Next())
+		assert.Equal(t, keys[51], iter.Value())
+		assert.True(t, iter.Prev())
+		assert.Equal(t, keys[50], iter.Value())
+		assert.True(t, iter.Next())
+		assert.Equal(t, keys[51], iter.Value())
+
+		iter = tree.Iter(newMockKey(500))
+		assert.True(t, iter.Prev())
+		assert.Equal(t, keys[99], iter.Value())
+		assert.False(t, tree.Iter(newMockKey(500)).Next())
+	}
+}
+
+func TestPrevAfterDelete(t *testing.T) {
+	tree := newBTree(3)
+	keys := constructMockKeys(100)
+	tree.Insert(keys...)
+
+	for i := 0; i < len(keys); i += 3 {
+		tree.Delete(keys[i])
+	}
+	if !assert.Nil(t, tree.Validate()) {
+		return
+	}
+
+	var reversed Keys
+	for iter := tree.SeekLast(); iter.Prev(); {
+		reversed = append(reversed, iter.Value())
+	}
+
+	expected := tree.Iter(newMockKey(-1)).exhaust()
+	assert.Len(t, reversed, len(expected))
+	for i := range expected {
+		assert.Equal(t, expected[i], reversed[len(reversed)-1-i])
+	}
+}
+
+func TestIterateWhileModifying(t *testing.T) {
+	tree := NewWithFreeList(3, 16)
+	keys := constructMockKeys(100)
+	tree.Insert(keys...)
+
+	var visited Keys
+	iter := tree.Iter(keys[0])
+	for iter.Next() {
+		key := iter.Value().(*mockKey)
+		visited = append(visited, key)
+		switch {
+		case key.value%10 == 0 && key.value < 100:
+			// the current key and the two after it
+			tree.Delete(key, keys[key.value+1], keys[key.value+2])
+			assert.Equal(t, key, iter.Value())
+		case key.value == 55:
+			tree.Insert(newMockKey(150), newMockKey(-1))
+		}
+	}
+
+	if !assert.Nil(t, tree.Validate()) {
+		return
+	}
+
+	var expected Keys
+	for _, key := range keys {
+		if v := key.(*mockKey).value; v%10 != 1 && v%10 != 2 {
+			expected = append(expected, key)
+		}
+	}
+	expected = append(expected, newMockKey(150))
+	assert.Equal(t, expected, visited)
+}
+
+func TestIterateBackwardWhileModifying(t *testing.T) {
+	tree := newBTree(3)
+	keys := constructMockKeys(50)
+	tree.Insert(keys...)
+
+	var visited Keys
+	iter := tree.SeekLast()
+	for iter.Prev() {
+		key := iter.Value().(*mockKey)
+		visited = append(visited, key)
+		if key.value%5 == 0 && key.value > 0 {
+			tree.Delete(key, keys[key.value-1])
+			// ahead of the iterator only in the other direction
+			tree.Insert(newMockKey(key.value + 100))
+		}
+	}
+
+	assert.Nil(t, tree.Validate())
+	assert.Len(t, visited, 41)
+	assert.Equal(t, keys[49], visited[0])
+	assert.Equal(t, keys[0], visited[len(visited)-1])
+
+	// an iterator that hasn't moved searches from its start
+	iter = tree.Iter(newMockKey(10))
+	tree.Delete(keys[11], keys[12])
+	assert.True(t, iter.Next())
+	assert.Equal(t, keys[13], iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, keys[8], iter.Value())
+}
+
+func TestEachWhileDeleting(t *testing.T) {
+	tree := newBTree(3)
+	keys := constructMockKeys(100)
+	tree.Insert(keys...)
+
+	count := 0
+	tree.Each(func(key Key) bool {
+		count++
+		tree.Delete(key)
+		return true
+	})
+
+	assert.Equal(t, 100, count)
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Nil(t, tree.Validate())
+}
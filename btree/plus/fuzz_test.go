@@ -0,0 +1,105 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/fuzz"
+)
+
+// FuzzBTree checks the tree and its generic counterpart against a
+// sorted set.  Node sizes are kept small so that splits and the
+// repairs done by deletes happen often.
+func FuzzBTree(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 1, 0, 2, 0, 3, 1, 2, 4, 0, 7, 1})
+	seed := make([]byte, 512)
+	rand.New(rand.NewSource(0)).Read(seed)
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := fuzz.NewOps(data)
+		nodeSize := 3 + ops.Uint64n(6)
+		var tree *BTree
+		if ops.Bool() {
+			tree = NewWithFreeList(nodeSize, 8)
+		} else {
+			tree = New(nodeSize)
+		}
+		gtree := NewG(func(a, b int) bool { return a < b }, int(nodeSize))
+		model := &fuzz.Sorted[int]{}
+		for op, ok := ops.Next(6); ok; op, ok = ops.Next(6) {
+			key := ops.Intn(64)
+			switch op {
+			case 0:
+				model.Insert(key)
+				tree.Insert(newMockKey(key))
+				gtree.Insert(key)
+			case 1:
+				expected := model.Delete(key)
+				assert.Equal(t, expected, tree.Delete(newMockKey(key))[0] != nil)
+				_, ok := gtree.Delete(key)
+				assert.Equal(t, expected, ok)
+			case 2:
+				expected := model.Contains(key)
+				assert.Equal(t, expected, tree.Get(newMockKey(key))[0] != nil)
+				_, ok := gtree.Get(key)
+				assert.Equal(t, expected, ok)
+			case 3:
+				floor, ok := model.Floor(key)
+				if ok {
+					assert.Equal(t, newMockKey(floor), tree.Floor(newMockKey(key)))
+				} else {
+					assert.Nil(t, tree.Floor(newMockKey(key)))
+				}
+				gfloor, gok := gtree.Floor(key)
+				assert.Equal(t, ok, gok)
+				assert.Equal(t, floor, gfloor)
+			case 4:
+				assert.Equal(t, uint64(model.Rank(key)), tree.Rank(newMockKey(key)))
+			case 5:
+				if model.Len() > 0 {
+					i := key % model.Len()
+					assert.Equal(t, newMockKey(model.At(i)), tree.ByPosition(uint64(i)))
+				}
+			}
+
+			if !assert.Nil(t, tree.Validate()) ||
+				!assert.Equal(t, uint64(model.Len()), tree.Len()) ||
+				!assert.Equal(t, uint64(model.Len()), gtree.Len()) {
+				return
+			}
+		}
+
+		values := make([]int, 0, model.Len())
+		tree.Each(func(k Key) bool {
+			values = append(values, k.(*mockKey).value)
+			return true
+		})
+		gvalues := make([]int, 0, model.Len())
+		for k := range gtree.All() {
+			gvalues = append(gvalues, k)
+		}
+		if model.Len() > 0 {
+			assert.Equal(t, model.Values(), values)
+			assert.Equal(t, model.Values(), gvalues)
+		}
+	})
+}
@@ -0,0 +1,557 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import (
+	"iter"
+	"sort"
+)
+
+// gnode is a node of a BTreeG.  Leaves have no children and are linked
+// in key order through next.
+type gnode[K any] struct {
+	keys     []K
+	children []*gnode[K]
+	next     *gnode[K]
+}
+
+func (n *gnode[K]) leaf() bool {
+	return n.children == nil
+}
+
+// BTreeG is a B+ tree of keys of a single type ordered by a less
+// function.  It shares its algorithms with BTree but stores keys
+// directly, avoiding the interface boxing and dynamic Compare calls
+// that dominate the cost of BTree operations.  It is not threadsafe.
+type BTreeG[K any] struct {
+	less     func(a, b K) bool
+	root     *gnode[K]
+	nodeSize int
+	number   uint64
+	// version is bumped by every insert and delete so iterators
+	// can tell that the leaves they point into may have changed.
+	version uint64
+}
+
+func (tree *BTreeG[K]) equal(a, b K) bool {
+	return !tree.less(a, b) && !tree.less(b, a)
+}
+
+// lowerBound returns the index of the first key equal to or greater
+// than the provided key.
+func (tree *BTreeG[K]) lowerBound(keys []K, key K) int {
+	return sort.Search(len(keys), func(i int) bool {
+		return !tree.less(keys[i], key)
+	})
+}
+
+// childIndex returns the index of the child whose subtree would
+// contain the provided key.  Keys equal to a separator belong to
+// the right of it.
+func (tree *BTreeG[K]) childIndex(n *gnode[K], key K) int {
+	return sort.Search(len(n.keys), func(i int) bool {
+		return tree.less(key, n.keys[i])
+	})
+}
+
+func insertAt[T any](s []T, i int, v T) []T {
+	var zero T
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
+
+func deleteAt[T any](s []T, i int) []T {
+	var zero T
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = zero // for garbage collection
+	return s[:len(s)-1]
+}
+
+// split splits the child at index i of the provided node, which must
+// be full, and adds the new separator to the node.
+func (tree *BTreeG[K]) split(n *gnode[K], i int) {
+	child := n.children[i]
+	mid := len(child.keys) / 2
+	right := &gnode[K]{}
+	var key K
+
+	if child.leaf() {
+		right.keys = make([]K, len(child.keys)-mid, tree.nodeSize)
+		copy(right.keys, child.keys[mid:])
+		key = right.keys[0]
+		right.next = child.next
+		child.next = right
+	} else {
+		key = child.keys[mid]
+		right.keys = make([]K, len(child.keys)-mid-1, tree.nodeSize)
+		copy(right.keys, child.keys[mid+1:])
+		right.children = make([]*gnode[K], len(child.children)-mid-1, tree.nodeSize+1)
+		copy(right.children, child.children[mid+1:])
+		for j := mid + 1; j < len(child.children); j++ {
+			child.children[j] = nil
+		}
+		child.children = child.children[:mid+1]
+	}
+
+	// clear the moved keys so they can be collected
+	var zero K
+	for j := mid; j < len(child.keys); j++ {
+		child.keys[j] = zero
+	}
+	child.keys = child.keys[:mid]
+
+	n.keys = insertAt(n.keys, i, key)
+	n.children = insertAt(n.children, i+1, right)
+}
+
+func (tree *BTreeG[K]) insert(n *gnode[K], key K) bool {
+	if n.leaf() {
+		i := tree.lowerBound(n.keys, key)
+		if i < len(n.keys) && tree.equal(n.keys[i], key) {
+			n.keys[i] = key
+			return false
+		}
+
+		n.keys = insertAt(n.keys, i, key)
+		return true
+	}
+
+	i := tree.childIndex(n, key)
+	inserted := tree.insert(n.children[i], key)
+	if inserted && len(n.children[i].keys) >= tree.nodeSize {
+		tree.split(n, i)
+	}
+
+	return inserted
+}
+
+// Insert will insert the provided keys into the tree, replacing any
+// equal keys.  This is an O(m*log n) operation where m is the number
+// of keys to be inserted and n is the number of items in the tree.
+func (tree *BTreeG[K]) Insert(keys ...K) {
+	for _, key := range keys {
+		tree.version++
+		if !tree.insert(tree.root, key) {
+			continue
+		}
+
+		tree.number++
+		if len(tree.root.keys) >= tree.nodeSize {
+			root := &gnode[K]{
+				keys:     make([]K, 0, tree.nodeSize),
+				children: append(make([]*gnode[K], 0, tree.nodeSize+1), tree.root),
+			}
+			tree.split(root, 0)
+			tree.root = root
+		}
+	}
+}
+
+// findLeaf returns the leaf and index of the first key equal to or
+// greater than the provided key.  The returned leaf is nil if there
+// is no such key.
+func (tree *BTreeG[K]) findLeaf(key K) (*gnode[K], int) {
+	n := tree.root
+	for !n.leaf() {
+		n = n.children[tree.childIndex(n, key)]
+	}
+
+	i := tree.lowerBound(n.keys, key)
+	if i == len(n.keys) {
+		// every key that could match lies in later leaves, the
+		// next of which is non-empty
+		return n.next, 0
+	}
+
+	return n, i
+}
+
+// Get returns the key in the tree equal to the provided key and a
+// bool indicating if one was found.  This is an O(log n) operation.
+func (tree *BTreeG[K]) Get(key K) (K, bool) {
+	n, i := tree.findLeaf(key)
+	if n == nil || !tree.equal(n.keys[i], key) {
+		var zero K
+		return zero, false
+	}
+
+	return n.keys[i], true
+}
+
+// Floor returns the greatest key in the tree equal to or less than
+// the provided key and a bool indicating if one was found.
+func (tree *BTreeG[K]) Floor(key K) (K, bool) {
+	// prev is the nearest subtree whose keys are all less than key
+	var prev *gnode[K]
+	n := tree.root
+	for !n.leaf() {
+		i := tree.childIndex(n, key)
+		if i > 0 {
+			prev = n.children[i-1]
+		}
+		n = n.children[i]
+	}
+
+	if i := tree.childIndex(n, key); i > 0 {
+		return n.keys[i-1], true
+	}
+
+	if prev == nil {
+		var zero K
+		return zero, false
+	}
+
+	for !prev.leaf() {
+		prev = prev.children[len(prev.children)-1]
+	}
+
+	return prev.keys[len(prev.keys)-1], true
+}
+
+func (tree *BTreeG[K]) delete(n *gnode[K], key K) (K, bool) {
+	if n.leaf() {
+		i := tree.lowerBound(n.keys, key)
+		if i == len(n.keys) || !tree.equal(n.keys[i], key) {
+			var zero K
+			return zero, false
+		}
+
+		deleted := n.keys[i]
+		n.keys = deleteAt(n.keys, i)
+		return deleted, true
+	}
+
+	i := tree.childIndex(n, key)
+	deleted, ok := tree.delete(n.children[i], key)
+	if !ok {
+		return deleted, false
+	}
+
+	if child := n.children[i]; len(child.keys) == 0 {
+		if child.leaf() {
+			tree.repairLeaf(n, i)
+		} else {
+			tree.repairInternal(n, i)
+		}
+	}
+
+	return deleted, true
+}
+
+// repairLeaf fixes the empty leaf at index i by borrowing a key from
+// a sibling or, if neither can spare one, by merging with a sibling.
+// The left node of a merged pair is always kept so the leaf that
+// points to it, which may belong to another parent, remains correct.
+func (tree *BTreeG[K]) repairLeaf(n *gnode[K], i int) {
+	leaf := n.children[i]
+	if i > 0 {
+		left := n.children[i-1]
+		if len(left.keys) > 1 {
+			leaf.keys = append(leaf.keys, left.keys[len(left.keys)-1])
+			left.keys = deleteAt(left.keys, len(left.keys)-1)
+			n.keys[i-1] = leaf.keys[0]
+			return
+		}
+	}
+
+	if i < len(n.children)-1 {
+		right := n.children[i+1]
+		if len(right.keys) > 1 {
+			leaf.keys = append(leaf.keys, right.keys[0])
+			right.keys = deleteAt(right.keys, 0)
+			n.keys[i] = right.keys[0]
+			return
+		}
+	}
+
+	if i > 0 {
+		n.children[i-1].next = leaf.next
+		n.keys = deleteAt(n.keys, i-1)
+		n.children = deleteAt(n.children, i)
+		return
+	}
+
+	right := n.children[1]
+	leaf.keys = append(leaf.keys, right.keys...)
+	leaf.next = right.next
+	n.keys = deleteAt(n.keys, 0)
+	n.children = deleteAt(n.children, 1)
+}
+
+// repairInternal fixes the internal node at index i, which has no keys
+// and a single child, by rotating a key and child through this node
+// from a sibling or by merging with a sibling.
+func (tree *BTreeG[K]) repairInternal(n *gnode[K], i int) {
+	child := n.children[i]
+	if i > 0 {
+		left := n.children[i-1]
+		if len(left.keys) > 1 {
+			child.keys = insertAt(child.keys, 0, n.keys[i-1])
+			child.children = insertAt(child.children, 0, left.children[len(left.children)-1])
+			n.keys[i-1] = left.keys[len(left.keys)-1]
+			left.keys = deleteAt(left.keys, len(left.keys)-1)
+			left.children = deleteAt(left.children, len(left.children)-1)
+			return
+		}
+	}
+
+	if i < len(n.children)-1 {
+		right := n.children[i+1]
+		if len(right.keys) > 1 {
+			child.keys = append(child.keys, n.keys[i])
+			child.children = append(child.children, right.children[0])
+			n.keys[i] = right.keys[0]
+			right.keys = deleteAt(right.keys, 0)
+			right.children = deleteAt(right.children, 0)
+			return
+		}
+	}
+
+	if i > 0 {
+		left := n.children[i-1]
+		left.keys = append(left.keys, n.keys[i-1])
+		left.children = append(left.children, child.children...)
+		n.keys = deleteAt(n.keys, i-1)
+		n.children = deleteAt(n.children, i)
+		return
+	}
+
+	right := n.children[1]
+	child.keys = append(child.keys, n.keys[0])
+	child.keys = append(child.keys, right.keys...)
+	child.children = append(child.children, right.children...)
+	n.keys = deleteAt(n.keys, 0)
+	n.children = deleteAt(n.children, 1)
+}
+
+// Delete removes the key equal to the provided key from the tree and
+// returns it along with a bool indicating if it was found.  This is
+// an O(log n) operation.
+func (tree *BTreeG[K]) Delete(key K) (K, bool) {
+	deleted, ok := tree.delete(tree.root, key)
+	if !ok {
+		return deleted, false
+	}
+
+	tree.number--
+	tree.version++
+	if !tree.root.leaf() && len(tree.root.keys) == 0 {
+		tree.root = tree.root.children[0]
+	}
+
+	return deleted, true
+}
+
+// walk calls fn with the keys from the i'th key of the leaf onward in
+// order until fn returns false or stop, if not nil, returns true for a
+// key.  If fn modifies the tree the walk continues after the last key
+// passed to it as an iterator would.
+func (tree *BTreeG[K]) walk(n *gnode[K], i int, stop func(K) bool, fn func(K) bool) {
+	version := tree.version
+	for ; n != nil; n, i = n.next, 0 {
+		for _, key := range n.keys[i:] {
+			if stop != nil && stop(key) {
+				return
+			}
+
+			if !fn(key) {
+				return
+			}
+
+			if tree.version != version {
+				iter := &IteratorG[K]{
+					tree:    tree,
+					version: version,
+					at:      key,
+					nexted:  true,
+					stop:    stop,
+				}
+				for iter.Next() {
+					if !fn(iter.Value()) {
+						return
+					}
+				}
+				return
+			}
+		}
+	}
+}
+
+// Each will call the provided function with every key in the tree in
+// order until the function returns false.  If the function modifies
+// the tree the walk continues after the last key passed to it as an
+// iterator would.
+func (tree *BTreeG[K]) Each(fn func(K) bool) {
+	n := tree.root
+	for !n.leaf() {
+		n = n.children[0]
+	}
+
+	tree.walk(n, 0, nil, fn)
+}
+
+// Range calls the provided function with every key equal to or
+// greater than start and less than stop in order until the function
+// returns false.  Like Each, this walks the linked leaves directly,
+// making it cheaper than IterRange for tight scans.
+func (tree *BTreeG[K]) Range(start, stop K, fn func(K) bool) {
+	n, i := tree.findLeaf(start)
+	tree.walk(n, i, func(key K) bool {
+		return !tree.less(key, stop)
+	}, fn)
+}
+
+// All returns a sequence of every key in the tree in order for use
+// with range.
+func (tree *BTreeG[K]) All() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		tree.Each(yield)
+	}
+}
+
+// Iter returns an iterator that traverses the tree starting from the
+// provided key or its successor.
+func (tree *BTreeG[K]) Iter(key K) *IteratorG[K] {
+	n, i := tree.findLeaf(key)
+	return &IteratorG[K]{
+		node:    n,
+		index:   i - 1,
+		tree:    tree,
+		version: tree.version,
+		at:      key,
+	}
+}
+
+// IterRange returns an iterator that traverses the keys equal to or
+// greater than start and less than stop.
+func (tree *BTreeG[K]) IterRange(start, stop K) *IteratorG[K] {
+	iter := tree.Iter(start)
+	iter.stop = func(key K) bool {
+		return !tree.less(key, stop)
+	}
+	return iter
+}
+
+// Len returns the number of items in this tree.
+func (tree *BTreeG[K]) Len() uint64 {
+	return tree.number
+}
+
+// IteratorG traverses the keys of a BTreeG in order.  The tree may be
+// modified between calls to Next, after which the iterator continues
+// with the first key greater than the one it last returned.  Once
+// exhausted an iterator stays exhausted.
+type IteratorG[K any] struct {
+	node   *gnode[K]
+	index  int
+	nexted bool
+	// done is set once the iterator is exhausted.
+	done bool
+	// stop, if not nil, returns true for the first key beyond the
+	// end of the iteration.
+	stop func(K) bool
+	// tree is the tree being iterated and version its version when
+	// node and index were last known to be good.
+	tree    *BTreeG[K]
+	version uint64
+	// at is the key last returned or, before the iterator has been
+	// nexted, its starting point.
+	at K
+}
+
+// resync repositions an iterator whose tree has changed before the
+// first key equal to or greater than at, or after it if at is still
+// in the tree and has already been returned.
+func (iter *IteratorG[K]) resync() {
+	tree := iter.tree
+	iter.version = tree.version
+	iter.node, iter.index = tree.findLeaf(iter.at)
+	if iter.node == nil {
+		return
+	}
+
+	if !iter.nexted || !tree.equal(iter.node.keys[iter.index], iter.at) {
+		iter.index--
+	}
+}
+
+// Next will move the iterator to the next position and return a bool
+// indicating if there is a value.
+func (iter *IteratorG[K]) Next() bool {
+	if iter.done {
+		return false
+	}
+
+	if iter.tree != nil && iter.version != iter.tree.version {
+		iter.resync()
+	}
+
+	if iter.node == nil {
+		iter.done = true
+		return false
+	}
+
+	iter.nexted = true
+	iter.index++
+	if iter.index >= len(iter.node.keys) {
+		iter.node = iter.node.next
+		if iter.node == nil {
+			iter.done = true
+			return false
+		}
+		iter.index = 0
+	}
+
+	if iter.stop != nil && iter.stop(iter.node.keys[iter.index]) {
+		iter.node, iter.done = nil, true
+		return false
+	}
+
+	iter.at = iter.node.keys[iter.index]
+	return true
+}
+
+// Value returns the key at the iterator's present position.  Returns
+// the zero value if the iterator is exhausted or has never been
+// nexted.
+func (iter *IteratorG[K]) Value() K {
+	if !iter.nexted || iter.done {
+		var zero K
+		return zero
+	}
+
+	if iter.tree != nil && iter.version != iter.tree.version {
+		// the key may have moved or been deleted since it was
+		// returned
+		return iter.at
+	}
+
+	return iter.node.keys[iter.index]
+}
+
+// NewG returns an empty B+ tree of keys ordered by the provided less
+// function with nodes holding fewer than nodeSize keys.  The node size
+// must be at least 3.
+func NewG[K any](less func(a, b K) bool, nodeSize int) *BTreeG[K] {
+	return &BTreeG[K]{
+		less:     less,
+		nodeSize: nodeSize,
+		root:     &gnode[K]{keys: make([]K, 0, nodeSize)},
+	}
+}
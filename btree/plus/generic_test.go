@@ -0,0 +1,299 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intLess(a, b int) bool {
+	return a < b
+}
+
+func exhaustG[K any](iter *IteratorG[K]) []K {
+	result := []K{}
+	for iter.Next() {
+		result = append(result, iter.Value())
+	}
+	return result
+}
+
+func TestGenericInsertGet(t *testing.T) {
+	tree := NewG(intLess, 3)
+	_, ok := tree.Get(1)
+	assert.False(t, ok)
+
+	for i := 99; i >= 0; i-- {
+		tree.Insert(i)
+	}
+	tree.Insert(50)
+
+	assert.Equal(t, uint64(100), tree.Len())
+	for i := 0; i < 100; i++ {
+		key, ok := tree.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, key)
+	}
+	_, ok = tree.Get(100)
+	assert.False(t, ok)
+}
+
+type keyValue struct {
+	key   int
+	value string
+}
+
+func TestGenericInsertReplaces(t *testing.T) {
+	tree := NewG(func(a, b keyValue) bool { return a.key < b.key }, 4)
+	tree.Insert(keyValue{1, `a`}, keyValue{2, `b`}, keyValue{1, `c`})
+
+	assert.Equal(t, uint64(2), tree.Len())
+	kv, ok := tree.Get(keyValue{key: 1})
+	assert.True(t, ok)
+	assert.Equal(t, `c`, kv.value)
+}
+
+func TestGenericIter(t *testing.T) {
+	tree := NewG(intLess, 4)
+	assert.Len(t, exhaustG(tree.Iter(0)), 0)
+
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i)
+	}
+
+	result := exhaustG(tree.Iter(91))
+	assert.Equal(t, []int{92, 94, 96, 98}, result)
+	assert.Len(t, exhaustG(tree.Iter(-10)), 50)
+	assert.Len(t, exhaustG(tree.Iter(99)), 0)
+
+	assert.Equal(t, []int{10, 12, 14}, exhaustG(tree.IterRange(9, 16)))
+	assert.Equal(t, []int{10, 12, 14, 16}, exhaustG(tree.IterRange(10, 17)))
+	assert.Len(t, exhaustG(tree.IterRange(10, 10)), 0)
+
+	iter := tree.Iter(98)
+	assert.Equal(t, 0, iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, 98, iter.Value())
+	assert.False(t, iter.Next())
+	assert.False(t, iter.Next())
+}
+
+func TestGenericFloor(t *testing.T) {
+	tree := NewG(intLess, 3)
+	_, ok := tree.Floor(5)
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i++ {
+		tree.Insert(i * 2)
+	}
+
+	_, ok = tree.Floor(-1)
+	assert.False(t, ok)
+	for i := 0; i < 200; i++ {
+		key, ok := tree.Floor(i)
+		assert.True(t, ok)
+		assert.Equal(t, i/2*2, key)
+	}
+}
+
+func TestGenericEach(t *testing.T) {
+	tree := NewG(intLess, 3)
+	for i := 0; i < 20; i++ {
+		tree.Insert(i)
+	}
+
+	var keys []int
+	tree.Each(func(key int) bool {
+		keys = append(keys, key)
+		return key < 4
+	})
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, keys)
+}
+
+func TestGenericRange(t *testing.T) {
+	tree := NewG(intLess, 4)
+	collect := func(start, stop int) []int {
+		var keys []int
+		tree.Range(start, stop, func(key int) bool {
+			keys = append(keys, key)
+			return true
+		})
+		return keys
+	}
+	assert.Len(t, collect(0, 10), 0)
+
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i)
+	}
+
+	assert.Equal(t, []int{10, 12, 14}, collect(9, 16))
+	assert.Equal(t, []int{10, 12, 14, 16}, collect(10, 17))
+	assert.Equal(t, []int{0, 2}, collect(-10, 3))
+	assert.Len(t, collect(10, 10), 0)
+	assert.Len(t, collect(99, 200), 0)
+
+	var keys []int
+	tree.Range(20, 80, func(key int) bool {
+		keys = append(keys, key)
+		tree.Delete(key)
+		return key < 26
+	})
+	assert.Equal(t, []int{20, 22, 24, 26}, keys)
+	assert.Equal(t, []int{18, 28}, collect(17, 29))
+}
+
+func TestGenericAll(t *testing.T) {
+	tree := NewG(intLess, 3)
+	for i := 19; i >= 0; i-- {
+		tree.Insert(i)
+	}
+
+	var keys []int
+	for key := range tree.All() {
+		if key > 4 {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, keys)
+}
+
+func TestGenericRandomOperations(t *testing.T) {
+	for _, nodeSize := range []int{3, 4, 16} {
+		tree := NewG(intLess, nodeSize)
+		expected := map[int]bool{}
+
+		for i := 0; i < 5000; i++ {
+			key := rand.Intn(1000)
+			if rand.Intn(3) == 0 {
+				deleted, ok := tree.Delete(key)
+				assert.Equal(t, expected[key], ok)
+				if ok {
+					assert.Equal(t, key, deleted)
+				}
+				delete(expected, key)
+				continue
+			}
+
+			tree.Insert(key)
+			expected[key] = true
+		}
+
+		keys := make([]int, 0, len(expected))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		sort.Ints(keys)
+
+		assert.Equal(t, uint64(len(keys)), tree.Len())
+		assert.Equal(t, keys, exhaustG(tree.Iter(-1)))
+
+		for _, key := range keys {
+			tree.Delete(key)
+		}
+		assert.Equal(t, uint64(0), tree.Len())
+		assert.Len(t, exhaustG(tree.Iter(-1)), 0)
+	}
+}
+
+func BenchmarkGenericInsert(b *testing.B) {
+	numItems := b.N
+	tree := NewG(intLess, 16)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Insert(i % numItems)
+	}
+}
+
+func BenchmarkGenericGet(b *testing.B) {
+	numItems := b.N
+	tree := NewG(intLess, 16)
+	for i := 0; i < numItems; i++ {
+		tree.Insert(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(i % numItems)
+	}
+}
+
+func BenchmarkGenericIteration(b *testing.B) {
+	numItems := 1000
+	tree := NewG(intLess, 16)
+	for i := 0; i < numItems; i++ {
+		tree.Insert(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for iter := tree.Iter(0); iter.Next(); {
+		}
+	}
+}
+
+func TestGenericIterateWhileModifying(t *testing.T) {
+	tree := NewG(intLess, 3)
+	for i := 0; i < 100; i++ {
+		tree.Insert(i)
+	}
+
+	var visited []int
+	iter := tree.Iter(0)
+	for iter.Next() {
+		key := iter.Value()
+		visited = append(visited, key)
+		if key%10 == 0 && key < 1000 {
+			tree.Delete(key)
+			tree.Delete(key + 1)
+			tree.Insert(key + 1000)
+			assert.Equal(t, key, iter.Value())
+		}
+	}
+
+	var expected []int
+	for i := 0; i < 100; i++ {
+		if i%10 != 1 {
+			expected = append(expected, i)
+		}
+	}
+	for i := 0; i < 100; i += 10 {
+		expected = append(expected, i+1000)
+	}
+	assert.Equal(t, expected, visited)
+
+	// an iterator over an empty range sees keys inserted before it
+	// is first nexted
+	empty := NewG(intLess, 3)
+	iter = empty.Iter(5)
+	empty.Insert(4, 6)
+	assert.Equal(t, []int{6}, exhaustG(iter))
+
+	count := 0
+	tree.Each(func(key int) bool {
+		count++
+		tree.Delete(key)
+		return true
+	})
+	assert.Equal(t, len(expected)-10, count)
+	assert.Equal(t, uint64(0), tree.Len())
+}
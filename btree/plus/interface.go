@@ -28,11 +28,19 @@ type Key interface {
 }
 
 // Iterator will be called with matching keys until either false is
-// returned or we run out of keys to iterate.
+// returned or we run out of keys to iterate.  The tree may be modified
+// between moves of an iterator, after which the iterator continues
+// from the key it last returned: Next moves to the first key greater
+// than it and Prev to the greatest key less than it, whether or not
+// it was deleted.  Value keeps returning that key until the iterator
+// is moved again.  Once exhausted an iterator stays exhausted.
 type Iterator interface {
 	// Next will move the iterator to the next position and return
 	// a bool indicating if there is a value.
 	Next() bool
+	// Prev will move the iterator to the previous position and
+	// return a bool indicating if there is a value.
+	Prev() bool
 	// Value returns a Key at the associated iterator position.  Returns
 	// nil if the iterator is exhausted or has never been nexted.
 	Value() Key
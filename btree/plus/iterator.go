@@ -18,9 +18,78 @@ package plus
 
 const iteratorExhausted = -2
 
+// iterator walks the linked leaves of a tree.  The tree may be
+// modified between moves: the iterator notices that the tree's
+// version has changed and finds its place again by searching for
+// the key it last returned, or its starting point if it has not been
+// moved, so keys it has passed are never returned again and keys
+// inserted ahead of it are returned.
 type iterator struct {
 	node  *lnode
 	index int
+	// started is set once the iterator has been moved.  Before
+	// then it sits between index and index+1.
+	started bool
+	// stop, if not nil, ends the iteration at the first key
+	// equal to or greater than it.
+	stop Key
+	// tree, if not nil, is the tree being iterated and version
+	// its version when node and index were last known to be
+	// good.
+	tree    *BTree
+	version uint64
+	// at is the key last returned or, before the iterator has
+	// been moved, its starting point.  A nil at before the
+	// iterator has been moved starts after the last key.
+	at Key
+}
+
+// watch records the tree so the iterator can survive changes to it.
+func (iter *iterator) watch(tree *BTree, at Key) {
+	iter.tree = tree
+	iter.version = tree.version
+	iter.at = at
+}
+
+func (iter *iterator) stale() bool {
+	return iter.tree != nil && iter.version != iter.tree.version
+}
+
+// resync repositions a stale iterator around at in the tree as it
+// is now.  The leaf holding the first key equal to or greater than
+// at is found, and the iterator sits before that key unless it has
+// already returned it or is moving backward from it.
+func (iter *iterator) resync(backward bool) {
+	tree := iter.tree
+	iter.version = tree.version
+	if tree.root == nil || tree.number == 0 {
+		iter.index = iteratorExhausted
+		return
+	}
+
+	var fresh *iterator
+	if iter.at == nil {
+		fresh = tree.last()
+	} else {
+		fresh = tree.root.find(iter.at)
+	}
+	iter.node, iter.index = fresh.node, fresh.index
+
+	if !iter.started {
+		return
+	}
+
+	p := iter.index + 1
+	if backward || (p < len(iter.node.keys) && iter.node.keys[p].Compare(iter.at) == 0) {
+		iter.index = p
+	}
+}
+
+// land records the key the iterator has moved to.
+func (iter *iterator) land() {
+	if iter.tree != nil {
+		iter.at = iter.node.keys[iter.index]
+	}
 }
 
 func (iter *iterator) Next() bool {
@@ -28,6 +97,14 @@ func (iter *iterator) Next() bool {
 		return false
 	}
 
+	if iter.stale() {
+		iter.resync(false)
+		if iter.index == iteratorExhausted {
+			return false
+		}
+	}
+
+	iter.started = true
 	iter.index++
 	if iter.index >= len(iter.node.keys) {
 		iter.node = iter.node.pointer
@@ -38,11 +115,59 @@ func (iter *iterator) Next() bool {
 		iter.index = 0
 	}
 
+	// keys compare as 1 against greater keys so anything less
+	// than 1 is at or beyond stop
+	if iter.stop != nil && iter.node.keys[iter.index].Compare(iter.stop) < 1 {
+		iter.index = iteratorExhausted
+		return false
+	}
+
+	iter.land()
+	return true
+}
+
+// Prev moves the iterator to the previous key and returns a bool
+// indicating if there is one.  An iterator that has not been moved
+// yet moves to the greatest key before its starting point.  Once the
+// iterator is exhausted in either direction it cannot be moved again.
+func (iter *iterator) Prev() bool {
+	if iter.index == iteratorExhausted {
+		return false
+	}
+
+	if iter.stale() {
+		iter.resync(true)
+		if iter.index == iteratorExhausted {
+			return false
+		}
+	}
+
+	if iter.started {
+		iter.index--
+	}
+	iter.started = true
+
+	for iter.index < 0 {
+		iter.node = iter.node.prev
+		if iter.node == nil {
+			iter.index = iteratorExhausted
+			return false
+		}
+		iter.index = len(iter.node.keys) - 1
+	}
+
+	iter.land()
 	return true
 }
 
 func (iter *iterator) Value() Key {
-	if iter.index == iteratorExhausted ||
+	if iter.started && iter.index != iteratorExhausted && iter.stale() {
+		// the key may have moved or been deleted since it was
+		// returned
+		return iter.at
+	}
+
+	if !iter.started || iter.index == iteratorExhausted ||
 		iter.index < 0 || iter.index >= len(iter.node.keys) {
 
 		return nil
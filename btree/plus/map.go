@@ -0,0 +1,205 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import "iter"
+
+// mapEntry pairs a value with its key so a Map can store both in
+// the tree.  Entries are ordered by their keys alone.
+type mapEntry struct {
+	key   Key
+	value interface{}
+}
+
+func (me *mapEntry) Compare(other Key) int {
+	return me.key.Compare(other.(*mapEntry).key)
+}
+
+// Map is an ordered map from keys to values backed by a B+ tree.
+// Keys are ordered as they are in a BTree.  Map is not threadsafe.
+type Map struct {
+	tree *BTree
+}
+
+// NewMap returns an empty map whose tree has nodes holding fewer
+// than nodeSize keys.  The node size must be at least 3.
+func NewMap(nodeSize uint64) *Map {
+	return &Map{tree: New(nodeSize)}
+}
+
+// Put associates the value with the key and returns the value it
+// replaced and a bool indicating if there was one.  This is an
+// O(log n) operation.
+func (m *Map) Put(key Key, value interface{}) (interface{}, bool) {
+	old, ok := m.Get(key)
+	m.tree.Insert(&mapEntry{key: key, value: value})
+	return old, ok
+}
+
+// Get returns the value associated with the key and a bool
+// indicating if it was found.  This is an O(log n) operation.
+func (m *Map) Get(key Key) (interface{}, bool) {
+	e := m.tree.Get(&mapEntry{key: key})[0]
+	if e == nil {
+		return nil, false
+	}
+
+	return e.(*mapEntry).value, true
+}
+
+// Delete removes the key and returns its value and a bool
+// indicating if it was found.  This is an O(log n) operation.
+func (m *Map) Delete(key Key) (interface{}, bool) {
+	e := m.tree.Delete(&mapEntry{key: key})[0]
+	if e == nil {
+		return nil, false
+	}
+
+	return e.(*mapEntry).value, true
+}
+
+// Floor returns the greatest key less than or equal to the provided
+// key along with its value.  Returns false if there is no such key.
+func (m *Map) Floor(key Key) (Key, interface{}, bool) {
+	if m.tree.Len() == 0 {
+		return nil, nil, false
+	}
+
+	e := m.tree.Floor(&mapEntry{key: key})
+	if e == nil {
+		return nil, nil, false
+	}
+
+	return e.(*mapEntry).key, e.(*mapEntry).value, true
+}
+
+// Rank returns the number of keys in the map less than the provided
+// key.  This is an O(log n) operation.
+func (m *Map) Rank(key Key) uint64 {
+	return m.tree.Rank(&mapEntry{key: key})
+}
+
+// ByPosition returns the key and value at the provided position in
+// key order and a bool indicating if the position was in bounds.
+// This is an O(log n) operation.
+func (m *Map) ByPosition(position uint64) (Key, interface{}, bool) {
+	e := m.tree.ByPosition(position)
+	if e == nil {
+		return nil, nil, false
+	}
+
+	return e.(*mapEntry).key, e.(*mapEntry).value, true
+}
+
+// Each will call the provided function with every key and value in
+// the map in key order until the function returns false.
+func (m *Map) Each(fn func(Key, interface{}) bool) {
+	m.tree.Each(func(k Key) bool {
+		e := k.(*mapEntry)
+		return fn(e.key, e.value)
+	})
+}
+
+// All returns a sequence of every key and value in the map in key
+// order for use with range.
+func (m *Map) All() iter.Seq2[Key, interface{}] {
+	return func(yield func(Key, interface{}) bool) {
+		m.Each(yield)
+	}
+}
+
+// Keys returns a sequence of every key in the map in order for use
+// with range.
+func (m *Map) Keys() iter.Seq[Key] {
+	return func(yield func(Key) bool) {
+		m.Each(func(k Key, _ interface{}) bool {
+			return yield(k)
+		})
+	}
+}
+
+// Values returns a sequence of every value in the map in key order
+// for use with range.
+func (m *Map) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		m.Each(func(_ Key, v interface{}) bool {
+			return yield(v)
+		})
+	}
+}
+
+// Iter returns an iterator positioned before the first key equal
+// to or greater than the provided key.
+func (m *Map) Iter(key Key) *MapIterator {
+	return &MapIterator{iter: m.tree.Iter(&mapEntry{key: key})}
+}
+
+// IterRange returns an iterator over the keys equal to or greater
+// than start and less than stop.  A nil stop leaves the range
+// unbounded above.
+func (m *Map) IterRange(start, stop Key) *MapIterator {
+	var s Key
+	if stop != nil {
+		s = &mapEntry{key: stop}
+	}
+
+	return &MapIterator{iter: m.tree.IterRange(&mapEntry{key: start}, s)}
+}
+
+// Len returns the number of keys in the map.
+func (m *Map) Len() uint64 {
+	return m.tree.Len()
+}
+
+// MapIterator iterates over the keys and values of a Map.
+type MapIterator struct {
+	iter Iterator
+}
+
+// Next moves the iterator to the next key and returns a bool
+// indicating if there is one.
+func (mi *MapIterator) Next() bool {
+	return mi.iter.Next()
+}
+
+// Prev moves the iterator to the previous key and returns a bool
+// indicating if there is one.
+func (mi *MapIterator) Prev() bool {
+	return mi.iter.Prev()
+}
+
+// Key returns the key at the current position, or nil if the
+// iterator hasn't been moved or is exhausted.
+func (mi *MapIterator) Key() Key {
+	e := mi.iter.Value()
+	if e == nil {
+		return nil
+	}
+
+	return e.(*mapEntry).key
+}
+
+// Value returns the value at the current position, or nil if the
+// iterator hasn't been moved or is exhausted.
+func (mi *MapIterator) Value() interface{} {
+	e := mi.iter.Value()
+	if e == nil {
+		return nil
+	}
+
+	return e.(*mapEntry).value
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapPutGet(t *testing.T) {
+	m := NewMap(3)
+	v, ok := m.Get(newMockKey(1))
+	assert.Nil(t, v)
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i++ {
+		v, ok = m.Put(newMockKey(i), i*10)
+		assert.Nil(t, v)
+		assert.False(t, ok)
+	}
+	assert.Equal(t, uint64(100), m.Len())
+
+	for i := 0; i < 100; i++ {
+		v, ok = m.Get(newMockKey(i))
+		assert.True(t, ok)
+		assert.Equal(t, i*10, v)
+	}
+
+	v, ok = m.Put(newMockKey(5), `five`)
+	assert.True(t, ok)
+	assert.Equal(t, 50, v)
+	assert.Equal(t, uint64(100), m.Len())
+
+	v, _ = m.Get(newMockKey(5))
+	assert.Equal(t, `five`, v)
+
+	// nil is a valid value
+	m.Put(newMockKey(6), nil)
+	v, ok = m.Get(newMockKey(6))
+	assert.Nil(t, v)
+	assert.True(t, ok)
+}
+
+func TestMapDelete(t *testing.T) {
+	m := NewMap(4)
+	for i := 0; i < 100; i++ {
+		m.Put(newMockKey(i), i)
+	}
+
+	v, ok := m.Delete(newMockKey(50))
+	assert.True(t, ok)
+	assert.Equal(t, 50, v)
+	assert.Equal(t, uint64(99), m.Len())
+
+	v, ok = m.Delete(newMockKey(50))
+	assert.Nil(t, v)
+	assert.False(t, ok)
+
+	_, ok = m.Get(newMockKey(50))
+	assert.False(t, ok)
+	assert.Nil(t, m.tree.Validate())
+}
+
+func TestMapFloor(t *testing.T) {
+	m := NewMap(3)
+	_, _, ok := m.Floor(newMockKey(1))
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i += 10 {
+		m.Put(newMockKey(i), i)
+	}
+
+	k, v, ok := m.Floor(newMockKey(25))
+	assert.True(t, ok)
+	assert.Equal(t, newMockKey(20), k)
+	assert.Equal(t, 20, v)
+
+	_, _, ok = m.Floor(newMockKey(-1))
+	assert.False(t, ok)
+}
+
+func TestMapRankByPosition(t *testing.T) {
+	m := NewMap(3)
+	_, _, ok := m.ByPosition(0)
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i += 10 {
+		m.Put(newMockKey(i), i)
+	}
+
+	assert.Equal(t, uint64(3), m.Rank(newMockKey(30)))
+	assert.Equal(t, uint64(3), m.Rank(newMockKey(25)))
+
+	k, v, ok := m.ByPosition(3)
+	assert.True(t, ok)
+	assert.Equal(t, newMockKey(30), k)
+	assert.Equal(t, 30, v)
+
+	_, _, ok = m.ByPosition(10)
+	assert.False(t, ok)
+}
+
+func TestMapIteration(t *testing.T) {
+	m := NewMap(3)
+	for i := 0; i < 20; i++ {
+		m.Put(newMockKey(i), i)
+	}
+
+	var values []interface{}
+	m.Each(func(k Key, v interface{}) bool {
+		values = append(values, v)
+		return len(values) < 5
+	})
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, values)
+
+	values = values[:0]
+	for k, v := range m.All() {
+		assert.Equal(t, newMockKey(v.(int)), k)
+		values = append(values, v)
+	}
+	assert.Len(t, values, 20)
+
+	var keys []Key
+	for k := range m.Keys() {
+		keys = append(keys, k)
+		if len(keys) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []Key{newMockKey(0), newMockKey(1), newMockKey(2)}, keys)
+
+	values = values[:0]
+	for v := range m.Values() {
+		values = append(values, v)
+	}
+	assert.Equal(t, 19, values[19])
+
+	iter := m.IterRange(newMockKey(5), newMockKey(8))
+	assert.Nil(t, iter.Key())
+	for i := 5; i < 8; i++ {
+		assert.True(t, iter.Next())
+		assert.Equal(t, newMockKey(i), iter.Key())
+		assert.Equal(t, i, iter.Value())
+	}
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+
+	iter = m.Iter(newMockKey(18))
+	assert.True(t, iter.Next())
+	assert.True(t, iter.Next())
+	assert.Equal(t, 19, iter.Value())
+	assert.False(t, iter.Next())
+
+	iter = m.IterRange(newMockKey(10), nil)
+	assert.True(t, iter.Prev())
+	assert.Equal(t, 9, iter.Value())
+}
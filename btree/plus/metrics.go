@@ -0,0 +1,81 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import "github.com/Workiva/go-datastructures/common"
+
+// SetMetrics makes this tree report to the provided metrics, or stop
+// reporting if nil.  Reported are common.MetricInserts, MetricDeletes,
+// MetricSearches, and MetricNodes, which counts both leaf and internal
+// nodes.  The nodes gauge is raised by the number of nodes already in
+// the tree when metrics are set.
+func (tree *BTree) SetMetrics(metrics common.Metrics) {
+	tree.metrics = metrics
+	if tree.root != nil {
+		tree.report(common.MetricNodes, countNodes(tree.root))
+	}
+}
+
+// report adds delta to the named metric if metrics are set.
+func (tree *BTree) report(name string, delta int64) {
+	if tree.metrics != nil {
+		tree.metrics.Add(name, delta)
+	}
+}
+
+// release is called with every node removed from this tree.
+func (tree *BTree) release(n node) {
+	tree.report(common.MetricNodes, -1)
+	tree.free.put(n)
+}
+
+func countNodes(n node) int64 {
+	in, ok := n.(*inode)
+	if !ok {
+		return 1
+	}
+
+	count := int64(1)
+	for _, child := range in.nodes {
+		count += countNodes(child)
+	}
+
+	return count
+}
+
+// FillFactor returns the average fraction of each leaf's capacity
+// that is in use, between 0 and 1.  A low fill factor after many
+// deletes means the tree is using more memory than its keys need.
+// This is an O(n/m) operation where m is the node size.
+func (tree *BTree) FillFactor() float64 {
+	if tree.root == nil {
+		return 0
+	}
+
+	n := tree.root
+	for in, ok := n.(*inode); ok; in, ok = n.(*inode) {
+		n = in.nodes[0]
+	}
+
+	var leaves uint64
+	for leaf := n.(*lnode); leaf != nil; leaf = leaf.pointer {
+		leaves++
+	}
+
+	// a leaf splits once it reaches the node size
+	return float64(tree.number) / float64(leaves*(tree.nodeSize-1))
+}
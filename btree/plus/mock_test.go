@@ -16,6 +16,8 @@ limitations under the License.
 
 package plus
 
+import "sync"
+
 type mockKey struct {
 	value int
 }
@@ -35,3 +37,28 @@ func (mk *mockKey) Compare(other Key) int {
 func newMockKey(value int) *mockKey {
 	return &mockKey{value}
 }
+
+type mockMetrics struct {
+	lock         sync.Mutex
+	counts       map[string]int64
+	observations map[string][]float64
+}
+
+func (mm *mockMetrics) Add(name string, delta int64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.counts[name] += delta
+}
+
+func (mm *mockMetrics) Observe(name string, value float64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.observations[name] = append(mm.observations[name], value)
+}
+
+func newMockMetrics() *mockMetrics {
+	return &mockMetrics{
+		counts:       make(map[string]int64),
+		observations: make(map[string][]float64),
+	}
+}
@@ -16,29 +16,40 @@ limitations under the License.
 
 package plus
 
-func split(tree *btree, parent, child node) node {
+import (
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// SizeOf sums these per tree, inode and lnode.
+const (
+	treeSize      = uint64(unsafe.Sizeof(BTree{}))
+	inodeSize     = uint64(unsafe.Sizeof(inode{}))
+	lnodeSize     = uint64(unsafe.Sizeof(lnode{}))
+	keySize       = uint64(unsafe.Sizeof(Key(nil)))
+	nodeFieldSize = uint64(unsafe.Sizeof(node(nil)))
+)
+
+func split(tree *BTree, parent, child node) node {
 	if !child.needsSplit(tree.nodeSize) {
 		return parent
 	}
 
-	key, left, right := child.split()
+	key, left, right := child.split(tree.free)
+	tree.report(common.MetricNodes, 1)
 	if parent == nil {
-		in := newInternalNode(tree.nodeSize)
+		tree.report(common.MetricNodes, 1)
+		in := tree.free.internal(tree.nodeSize)
 		in.keys = append(in.keys, key)
 		in.nodes = append(in.nodes, left)
 		in.nodes = append(in.nodes, right)
+		in.number = left.count() + right.count()
 		return in
 	}
 
 	p := parent.(*inode)
 	i := p.search(key)
-	// we want to ensure if the children are leaves we set
-	// the left node's left sibling to point to left
-	if cr, ok := left.(*lnode); ok {
-		if i > 0 {
-			p.nodes[i-1].(*lnode).pointer = cr
-		}
-	}
 	p.keys.insertAt(i, key)
 	p.nodes[i] = left
 	p.nodes.insertAt(i+1, right)
@@ -47,13 +58,23 @@ func split(tree *btree, parent, child node) node {
 }
 
 type node interface {
-	insert(tree *btree, key Key) bool
+	insert(tree *BTree, key Key) bool
+	// sizeOf returns the estimated number of bytes used by this
+	// node and its children, not including the keys themselves.
+	sizeOf() uint64
 	needsSplit(nodeSize uint64) bool
 	// key is the median key while left and right nodes
-	// represent the left and right nodes respectively
-	split() (Key, node, node)
+	// represent the left and right nodes respectively.  The new
+	// node is taken from the provided free list.
+	split(free *freeList) (Key, node, node)
 	search(key Key) int
 	find(key Key) *iterator
+	// delete removes the key from this subtree and returns it, or
+	// nil if it wasn't found.  Nodes emptied by merging are released
+	// to the tree.
+	delete(tree *BTree, key Key) Key
+	// count returns the number of keys held in this subtree.
+	count() uint64
 }
 
 type nodes []node
@@ -69,17 +90,30 @@ func (nodes *nodes) insertAt(i int, node node) {
 	(*nodes)[i] = node
 }
 
-func (ns nodes) splitAt(i int) (nodes, nodes) {
-	left := make(nodes, i, cap(ns))
-	right := make(nodes, len(ns)-i, cap(ns))
-	copy(left, ns[:i])
-	copy(right, ns[i:])
-	return left, right
+func (nodes *nodes) deleteAt(i int) {
+	copy((*nodes)[i:], (*nodes)[i+1:])
+	(*nodes)[len(*nodes)-1] = nil // for garbage collection
+	*nodes = (*nodes)[:len(*nodes)-1]
 }
 
 type inode struct {
 	keys  keys
 	nodes nodes
+	// number is the count of keys in the leaves under this node,
+	// which allows positional lookups in O(log n).
+	number uint64
+}
+
+func (n *inode) count() uint64 {
+	return n.number
+}
+
+// recount sets the number of keys under this node from its children.
+func (n *inode) recount() {
+	n.number = 0
+	for _, child := range n.nodes {
+		n.number += child.count()
+	}
 }
 
 func (node *inode) search(key Key) int {
@@ -101,7 +135,150 @@ func (node *inode) find(key Key) *iterator {
 	}
 }
 
-func (n *inode) insert(tree *btree, key Key) bool {
+// childIndex returns the index of the child whose subtree would
+// contain the provided key.
+func (n *inode) childIndex(key Key) int {
+	i := n.search(key)
+	if i == len(n.keys) {
+		return len(n.nodes) - 1
+	}
+
+	if n.keys[i].Compare(key) == 0 {
+		return i + 1
+	}
+
+	return i
+}
+
+func (n *inode) delete(tree *BTree, key Key) Key {
+	i := n.childIndex(key)
+	deleted := n.nodes[i].delete(tree, key)
+	if deleted == nil {
+		return nil
+	}
+
+	n.number--
+	switch child := n.nodes[i].(type) {
+	case *lnode:
+		if len(child.keys) == 0 {
+			n.repairLeaf(tree, i)
+		}
+	case *inode:
+		if len(child.keys) == 0 {
+			n.repairInternal(tree, i)
+		}
+	}
+
+	return deleted
+}
+
+// repairLeaf fixes the empty leaf at index i by borrowing a key from
+// a sibling or, if neither can spare one, by merging with a sibling.
+// The left node of a merged pair is always kept so the leaf that
+// points to it, which may belong to another parent, remains correct,
+// and the right node is released to the tree.
+func (n *inode) repairLeaf(tree *BTree, i int) {
+	leaf := n.nodes[i].(*lnode)
+	if i > 0 {
+		left := n.nodes[i-1].(*lnode)
+		if len(left.keys) > 1 {
+			leaf.keys = append(leaf.keys, left.keys[len(left.keys)-1])
+			left.keys.deleteAt(len(left.keys) - 1)
+			n.keys[i-1] = leaf.keys[0]
+			return
+		}
+	}
+
+	if i < len(n.nodes)-1 {
+		right := n.nodes[i+1].(*lnode)
+		if len(right.keys) > 1 {
+			leaf.keys = append(leaf.keys, right.keys[0])
+			right.keys.deleteAt(0)
+			n.keys[i] = right.keys[0]
+			return
+		}
+	}
+
+	if i > 0 {
+		n.nodes[i-1].(*lnode).pointer = leaf.pointer
+		if leaf.pointer != nil {
+			leaf.pointer.prev = n.nodes[i-1].(*lnode)
+		}
+		n.keys.deleteAt(i - 1)
+		n.nodes.deleteAt(i)
+		tree.release(leaf)
+		return
+	}
+
+	right := n.nodes[1].(*lnode)
+	leaf.keys = append(leaf.keys, right.keys...)
+	leaf.pointer = right.pointer
+	if right.pointer != nil {
+		right.pointer.prev = leaf
+	}
+	n.keys.deleteAt(0)
+	n.nodes.deleteAt(1)
+	tree.release(right)
+}
+
+// repairInternal fixes the internal node at index i, which has no keys
+// and a single child, by rotating a key and child through this node
+// from a sibling or by merging with a sibling.  The right node of
+// a merged pair is released to the tree.
+func (n *inode) repairInternal(tree *BTree, i int) {
+	child := n.nodes[i].(*inode)
+	if i > 0 {
+		left := n.nodes[i-1].(*inode)
+		if len(left.keys) > 1 {
+			moved := left.nodes[len(left.nodes)-1]
+			child.keys.insertAt(0, n.keys[i-1])
+			child.nodes.insertAt(0, moved)
+			child.number += moved.count()
+			left.number -= moved.count()
+			n.keys[i-1] = left.keys[len(left.keys)-1]
+			left.keys.deleteAt(len(left.keys) - 1)
+			left.nodes.deleteAt(len(left.nodes) - 1)
+			return
+		}
+	}
+
+	if i < len(n.nodes)-1 {
+		right := n.nodes[i+1].(*inode)
+		if len(right.keys) > 1 {
+			moved := right.nodes[0]
+			child.keys = append(child.keys, n.keys[i])
+			child.nodes = append(child.nodes, moved)
+			child.number += moved.count()
+			right.number -= moved.count()
+			n.keys[i] = right.keys[0]
+			right.keys.deleteAt(0)
+			right.nodes.deleteAt(0)
+			return
+		}
+	}
+
+	if i > 0 {
+		left := n.nodes[i-1].(*inode)
+		left.keys = append(left.keys, n.keys[i-1])
+		left.nodes = append(left.nodes, child.nodes...)
+		left.number += child.number
+		n.keys.deleteAt(i - 1)
+		n.nodes.deleteAt(i)
+		tree.release(child)
+		return
+	}
+
+	right := n.nodes[1].(*inode)
+	child.keys = append(child.keys, n.keys[0])
+	child.keys = append(child.keys, right.keys...)
+	child.nodes = append(child.nodes, right.nodes...)
+	child.number += right.number
+	n.keys.deleteAt(0)
+	n.nodes.deleteAt(1)
+	tree.release(right)
+}
+
+func (n *inode) insert(tree *BTree, key Key) bool {
 	i := n.search(key)
 	var child node
 	if i == len(n.keys) { // we want the last child node in this case
@@ -121,6 +298,7 @@ func (n *inode) insert(tree *btree, key Key) bool {
 		return result
 	}
 
+	n.number++
 	if child.needsSplit(tree.nodeSize) {
 		split(tree, n, child)
 	}
@@ -128,11 +306,20 @@ func (n *inode) insert(tree *btree, key Key) bool {
 	return result
 }
 
+func (n *inode) sizeOf() uint64 {
+	size := inodeSize + uint64(cap(n.keys))*keySize + uint64(cap(n.nodes))*nodeFieldSize
+	for _, child := range n.nodes {
+		size += child.sizeOf()
+	}
+
+	return size
+}
+
 func (n *inode) needsSplit(nodeSize uint64) bool {
 	return uint64(len(n.keys)) >= nodeSize
 }
 
-func (n *inode) split() (Key, node, node) {
+func (n *inode) split(free *freeList) (Key, node, node) {
 	if len(n.keys) < 3 {
 		return nil, nil, nil
 	}
@@ -140,17 +327,20 @@ func (n *inode) split() (Key, node, node) {
 	i := len(n.keys) / 2
 	key := n.keys[i]
 
-	ourKeys := make(keys, len(n.keys)-i-1, cap(n.keys))
-	otherKeys := make(keys, i, cap(n.keys))
-	copy(ourKeys, n.keys[i+1:])
-	copy(otherKeys, n.keys[:i])
-	left, right := n.nodes.splitAt(i + 1)
-	otherNode := &inode{
-		keys:  otherKeys,
-		nodes: left,
-	}
-	n.keys = ourKeys
-	n.nodes = right
+	// the left half is copied to the other node and the right half
+	// is shifted down in place, so the two nodes never share an
+	// underlying array.
+	otherNode := free.internal(uint64(cap(n.keys)))
+	otherNode.keys = append(otherNode.keys, n.keys[:i]...)
+	otherNode.nodes = append(otherNode.nodes, n.nodes[:i+1]...)
+	m := copy(n.keys, n.keys[i+1:])
+	clear(n.keys[m:]) // for garbage collection
+	n.keys = n.keys[:m]
+	m = copy(n.nodes, n.nodes[i+1:])
+	clear(n.nodes[m:])
+	n.nodes = n.nodes[:m]
+	otherNode.recount()
+	n.number -= otherNode.number
 	return key, otherNode, n
 }
 
@@ -162,16 +352,18 @@ func newInternalNode(size uint64) *inode {
 }
 
 type lnode struct {
-	// points to the left leaf node is there is one
+	// points to the right leaf node if there is one
 	pointer *lnode
-	keys    keys
+	// points to the left leaf node if there is one
+	prev *lnode
+	keys keys
 }
 
 func (node *lnode) search(key Key) int {
 	return node.keys.search(key)
 }
 
-func (lnode *lnode) insert(tree *btree, key Key) bool {
+func (lnode *lnode) insert(tree *BTree, key Key) bool {
 	i := keySearch(lnode.keys, key)
 	var inserted bool
 	if i == len(lnode.keys) { // simple append will do
@@ -193,11 +385,26 @@ func (lnode *lnode) insert(tree *btree, key Key) bool {
 	return true
 }
 
+func (node *lnode) delete(tree *BTree, key Key) Key {
+	i := node.search(key)
+	if i == len(node.keys) || node.keys[i].Compare(key) != 0 {
+		return nil
+	}
+
+	deleted := node.keys[i]
+	node.keys.deleteAt(i)
+	return deleted
+}
+
 func (node *lnode) find(key Key) *iterator {
 	i := node.search(key)
 	if i == len(node.keys) {
 		if node.pointer == nil {
-			return nilIterator()
+			// park after the last key so Prev can still walk back
+			return &iterator{
+				node:  node,
+				index: len(node.keys) - 1,
+			}
 		}
 
 		return &iterator{
@@ -213,27 +420,38 @@ func (node *lnode) find(key Key) *iterator {
 	return iter
 }
 
-func (node *lnode) split() (Key, node, node) {
+func (node *lnode) split(free *freeList) (Key, node, node) {
 	if len(node.keys) < 2 {
 		return nil, nil, nil
 	}
 	i := len(node.keys) / 2
 	key := node.keys[i]
-	otherKeys := make(keys, i, cap(node.keys))
-	ourKeys := make(keys, len(node.keys)-i, cap(node.keys))
-	// we perform these copies so these slices don't all end up
-	// pointing to the same underlying array which may make
-	// for some very difficult to debug situations later.
-	copy(otherKeys, node.keys[:i])
-	copy(ourKeys, node.keys[i:])
-
-	// this should release the original array for GC
-	node.keys = ourKeys
-	otherNode := &lnode{
-		keys:    otherKeys,
-		pointer: node,
+	// we copy the right half into the other node's own array so
+	// these slices don't both end up pointing to the same underlying
+	// array which may make for some very difficult to debug
+	// situations later.
+	otherNode := free.leaf(uint64(cap(node.keys)))
+	otherNode.keys = append(otherNode.keys, node.keys[i:]...)
+	otherNode.pointer = node.pointer
+	otherNode.prev = node
+
+	// this node keeps the left half so that the leaf to its left,
+	// which may live under a different parent, still points to it.
+	clear(node.keys[i:]) // for garbage collection
+	node.keys = node.keys[:i]
+	if node.pointer != nil {
+		node.pointer.prev = otherNode
 	}
-	return key, otherNode, node
+	node.pointer = otherNode
+	return key, node, otherNode
+}
+
+func (lnode *lnode) count() uint64 {
+	return uint64(len(lnode.keys))
+}
+
+func (lnode *lnode) sizeOf() uint64 {
+	return lnodeSize + uint64(cap(lnode.keys))*keySize
 }
 
 func (lnode *lnode) needsSplit(nodeSize uint64) bool {
@@ -246,6 +464,67 @@ func newLeafNode(size uint64) *lnode {
 	}
 }
 
+// freeList holds nodes that have been merged away during deletes so
+// that later splits can reuse them, and their key and child lists,
+// rather than allocating.  A nil freeList recycles nothing and
+// allocates every node.
+type freeList struct {
+	leaves    []*lnode
+	internals []*inode
+	max       int
+}
+
+// leaf returns an empty leaf node, reusing a free one if available.
+func (fl *freeList) leaf(size uint64) *lnode {
+	if fl == nil || len(fl.leaves) == 0 {
+		return newLeafNode(size)
+	}
+
+	n := fl.leaves[len(fl.leaves)-1]
+	fl.leaves[len(fl.leaves)-1] = nil
+	fl.leaves = fl.leaves[:len(fl.leaves)-1]
+	return n
+}
+
+// internal returns an empty internal node, reusing a free one if
+// available.
+func (fl *freeList) internal(size uint64) *inode {
+	if fl == nil || len(fl.internals) == 0 {
+		return newInternalNode(size)
+	}
+
+	n := fl.internals[len(fl.internals)-1]
+	fl.internals[len(fl.internals)-1] = nil
+	fl.internals = fl.internals[:len(fl.internals)-1]
+	return n
+}
+
+// put clears the provided node and keeps it for reuse unless this list
+// is already full.  The node must no longer be reachable from a tree.
+func (fl *freeList) put(n node) {
+	if fl == nil || len(fl.leaves)+len(fl.internals) >= fl.max {
+		return
+	}
+
+	switch n := n.(type) {
+	case *lnode:
+		clear(n.keys)
+		n.keys = n.keys[:0]
+		n.pointer, n.prev = nil, nil
+		fl.leaves = append(fl.leaves, n)
+	case *inode:
+		clear(n.keys)
+		clear(n.nodes)
+		n.keys, n.nodes = n.keys[:0], n.nodes[:0]
+		n.number = 0
+		fl.internals = append(fl.internals, n)
+	}
+}
+
+func newFreeList(max int) *freeList {
+	return &freeList{max: max}
+}
+
 type keys []Key
 
 func (keys keys) search(key Key) int {
@@ -263,6 +542,12 @@ func (keys *keys) insertAt(i int, key Key) {
 	(*keys)[i] = key
 }
 
+func (keys *keys) deleteAt(i int) {
+	copy((*keys)[i:], (*keys)[i+1:])
+	(*keys)[len(*keys)-1] = nil // for garbage collection
+	*keys = (*keys)[:len(*keys)-1]
+}
+
 func (keys keys) reverse() {
 	for i := 0; i < len(keys)/2; i++ {
 		keys[i], keys[len(keys)-i-1] = keys[len(keys)-i-1], keys[i]
@@ -71,20 +71,21 @@ func constructMockNodes(num int) nodes {
 	return nodes
 }
 
-func constructMockInternalNode(nodes nodes) *inode {
-	if len(nodes) < 2 {
+func constructMockInternalNode(children nodes) *inode {
+	if len(children) < 2 {
 		return nil
 	}
 
-	keys := make(keys, 0, len(nodes)-1)
-	for i := 1; i < len(nodes); i++ {
-		keys = append(keys, nodes[i].(*lnode).keys[0])
+	keys := make(keys, 0, len(children)-1)
+	for i := 1; i < len(children); i++ {
+		keys = append(keys, children[i].(*lnode).keys[0])
 	}
 
 	in := &inode{
 		keys:  keys,
-		nodes: nodes,
+		nodes: append(make(nodes, 0, len(children)), children...),
 	}
+	in.recount()
 	return in
 }
 
@@ -134,10 +135,10 @@ func TestLeafNodeSplitEvenNumber(t *testing.T) {
 	keys := constructMockPayloads(4)
 
 	node := &lnode{
-		keys: keys,
+		keys: constructMockPayloads(4),
 	}
 
-	key, left, right := node.split()
+	key, left, right := node.split(nil)
 	assert.Equal(t, keys[2], key)
 	assert.Equal(t, left.(*lnode).keys, keys[:2])
 	assert.Equal(t, right.(*lnode).keys, keys[2:])
@@ -148,10 +149,10 @@ func TestLeafNodeSplitOddNumber(t *testing.T) {
 	keys := constructMockPayloads(3)
 
 	node := &lnode{
-		keys: keys,
+		keys: constructMockPayloads(3),
 	}
 
-	key, left, right := node.split()
+	key, left, right := node.split(nil)
 	assert.Equal(t, keys[1], key)
 	assert.Equal(t, left.(*lnode).keys, keys[:1])
 	assert.Equal(t, right.(*lnode).keys, keys[1:])
@@ -162,10 +163,10 @@ func TestTwoKeysLeafNodeSplit(t *testing.T) {
 	keys := constructMockPayloads(2)
 
 	node := &lnode{
-		keys: keys,
+		keys: constructMockPayloads(2),
 	}
 
-	key, left, right := node.split()
+	key, left, right := node.split(nil)
 	assert.Equal(t, keys[1], key)
 	assert.Equal(t, left.(*lnode).keys, keys[:1])
 	assert.Equal(t, right.(*lnode).keys, keys[1:])
@@ -173,13 +174,11 @@ func TestTwoKeysLeafNodeSplit(t *testing.T) {
 }
 
 func TestLessThanTwoKeysSplit(t *testing.T) {
-	keys := constructMockPayloads(1)
-
 	node := &lnode{
-		keys: keys,
+		keys: constructMockPayloads(1),
 	}
 
-	key, left, right := node.split()
+	key, left, right := node.split(nil)
 	assert.Nil(t, key)
 	assert.Nil(t, left)
 	assert.Nil(t, right)
@@ -189,7 +188,7 @@ func TestInternalNodeSplit2_3_4(t *testing.T) {
 	nodes := constructMockNodes(4)
 	in := constructMockInternalNode(nodes)
 
-	key, left, right := in.split()
+	key, left, right := in.split(nil)
 	assert.Equal(t, nodes[3].(*lnode).keys[0], key)
 	assert.Len(t, left.(*inode).keys, 1)
 	assert.Len(t, right.(*inode).keys, 1)
@@ -201,7 +200,7 @@ func TestInternalNodeSplit3_4_5(t *testing.T) {
 	nodes := constructMockNodes(5)
 	in := constructMockInternalNode(nodes)
 
-	key, left, right := in.split()
+	key, left, right := in.split(nil)
 	assert.Equal(t, nodes[4].(*lnode).keys[0], key)
 	assert.Len(t, left.(*inode).keys, 2)
 	assert.Len(t, right.(*inode).keys, 1)
@@ -213,7 +212,7 @@ func TestInternalNodeLessThan3Keys(t *testing.T) {
 	nodes := constructMockNodes(2)
 	in := constructMockInternalNode(nodes)
 
-	key, left, right := in.split()
+	key, left, right := in.split(nil)
 	assert.Nil(t, key)
 	assert.Nil(t, left)
 	assert.Nil(t, right)
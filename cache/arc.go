@@ -0,0 +1,133 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// arc is the adaptive replacement cache of Megiddo and Modha, which
+// balances recency against frequency.  Entries used once live in t1
+// and entries used again move to t2.  The keys of entries evicted
+// from each are remembered, without their values, in the ghost lists
+// b1 and b2.  A miss on a key in b1 means t1 was too small and grows
+// its target size, target, while a miss in b2 shrinks it.  Sizes are
+// measured in weight rather than entries so the policy works for
+// weighted caches too.
+type arc[K comparable, V any] struct {
+	capacity       uint64
+	target         uint64
+	t1, t2, b1, b2 list[K, V]
+	ghosts         map[K]*entry[K, V]
+}
+
+func (p *arc[K, V]) add(e *entry[K, V]) {
+	g, ok := p.ghosts[e.key]
+	if !ok {
+		p.t1.pushFront(e)
+		p.trim()
+		return
+	}
+
+	// a ghost hit adapts the target toward the list that would have
+	// kept this entry, faster the smaller that list's ghosts are.
+	if g.list == &p.b1 {
+		delta := max(p.b2.weight/max(p.b1.weight, 1), 1) * e.weight
+		p.target = min(p.target+delta, p.capacity)
+	} else {
+		delta := max(p.b1.weight/max(p.b2.weight, 1), 1) * e.weight
+		p.target -= min(delta, p.target)
+	}
+
+	g.list.remove(g)
+	delete(p.ghosts, e.key)
+	p.t2.pushFront(e)
+	p.trim()
+}
+
+func (p *arc[K, V]) hit(e *entry[K, V]) {
+	e.list.remove(e)
+	p.t2.pushFront(e)
+}
+
+func (p *arc[K, V]) remove(e *entry[K, V]) {
+	e.list.remove(e)
+}
+
+// evict removes an entry from t1 if it is over its target, or at it
+// when the entry being admitted is a ghost of t2, and otherwise from
+// t2.  The evicted entry's key is remembered as a ghost.
+func (p *arc[K, V]) evict(next *entry[K, V]) *entry[K, V] {
+	inB2 := false
+	if next != nil {
+		g, ok := p.ghosts[next.key]
+		inB2 = ok && g.list == &p.b2
+	}
+
+	from, ghosts := &p.t2, &p.b2
+	if p.t1.len > 0 && (p.t1.weight > p.target || (inB2 && p.t1.weight == p.target) || p.t2.len == 0) {
+		from, ghosts = &p.t1, &p.b1
+	}
+
+	e := from.back()
+	if e == nil {
+		return nil
+	}
+	from.remove(e)
+
+	g := &entry[K, V]{key: e.key, weight: e.weight}
+	ghosts.pushFront(g)
+	p.ghosts[e.key] = g
+	p.trim()
+	return e
+}
+
+// trim forgets the oldest ghosts so that t1 and b1 together, and all
+// four lists together, hold no more than the capacity and twice the
+// capacity respectively.
+func (p *arc[K, V]) trim() {
+	for p.b1.len > 0 && p.t1.weight+p.b1.weight > p.capacity {
+		p.forget(&p.b1)
+	}
+
+	for p.t1.weight+p.t2.weight+p.b1.weight+p.b2.weight > 2*p.capacity {
+		if p.b2.len > 0 {
+			p.forget(&p.b2)
+		} else if p.b1.len > 0 {
+			p.forget(&p.b1)
+		} else {
+			break
+		}
+	}
+}
+
+func (p *arc[K, V]) forget(ghosts *list[K, V]) {
+	g := ghosts.back()
+	ghosts.remove(g)
+	delete(p.ghosts, g.key)
+}
+
+func (p *arc[K, V]) clear() {
+	p.t1.init()
+	p.t2.init()
+	p.b1.init()
+	p.b2.init()
+	p.target = 0
+	p.ghosts = make(map[K]*entry[K, V])
+}
+
+func newARC[K comparable, V any](capacity uint64) *arc[K, V] {
+	p := &arc[K, V]{capacity: capacity}
+	p.clear()
+	return p
+}
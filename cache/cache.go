@@ -0,0 +1,350 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package cache provides a threadsafe, bounded key-value cache with a
+choice of eviction policy:
+
+	LRU  evicts the least recently used entry.
+	LFU  evicts the least frequently used entry, the least recently
+	     used of those on a tie.
+	ARC  the adaptive replacement cache, which keeps separate lists
+	     of entries used once and entries used more often and adapts
+	     the space given to each to the workload, resisting scans
+	     that would flush an LRU cache.
+
+A cache is bounded either by its number of entries or, given a weigher,
+by the total weight of its entries, such as their size in bytes.
+Entries may expire after a time to live, set for the whole cache or
+per entry.  Expired entries are dropped when next looked up or by
+calling Prune.  An optional callback is told of every entry leaving
+the cache along with the reason, and is called after the cache's lock
+is released so it may use the cache.
+
+A single cache is guarded by one lock, which Sharded avoids under
+heavy concurrent use by splitting keys across a number of caches.
+
+Performance characteristics:
+Get: O(1)
+Set: O(1) amortized, plus one eviction per entry evicted
+Delete: O(1)
+Prune: O(n)
+Space: O(n), for ARC plus the keys of up to n recently evicted entries
+*/
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Policy determines which entry a full cache evicts.
+type Policy int
+
+const (
+	// LRU evicts the least recently used entry.
+	LRU Policy = iota
+	// LFU evicts the least frequently used entry.
+	LFU
+	// ARC evicts using the adaptive replacement cache algorithm.
+	ARC
+)
+
+// Reason is why an entry left the cache.
+type Reason int
+
+const (
+	// Evicted entries were removed to make room for another.
+	Evicted Reason = iota
+	// Expired entries outlived their time to live.
+	Expired
+	// Removed entries were deleted, cleared or replaced by an entry
+	// too heavy to cache.
+	Removed
+)
+
+// Config configures a cache.
+type Config[K comparable, V any] struct {
+	// Policy determines which entry is evicted.  The default is LRU.
+	Policy Policy
+	// Capacity is the maximum number of entries or, if Weigher is
+	// set, the maximum total weight.  It must be greater than 0.
+	Capacity uint64
+	// TTL is how long entries live after being set, unless set with
+	// their own.  The default of 0 means entries never expire.
+	TTL time.Duration
+	// Weigher, if set, returns the weight of an entry.
+	Weigher func(key K, value V) uint64
+	// OnEvict, if set, is called with every entry leaving the cache.
+	OnEvict func(key K, value V, reason Reason)
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	weight  uint64
+	expires time.Time
+	// prev and next link the entry into the list it is in.
+	prev, next *entry[K, V]
+	list       *list[K, V]
+	bucket     *bucket[K, V]
+}
+
+// policy tracks resident entries and picks which to evict.
+type policy[K comparable, V any] interface {
+	// add starts tracking an entry admitted to the cache.
+	add(*entry[K, V])
+	// hit records a use of a tracked entry.
+	hit(*entry[K, V])
+	// remove stops tracking an entry.
+	remove(*entry[K, V])
+	// evict stops tracking and returns the entry to evict to make
+	// room for the provided one, which may be nil, or nil if there
+	// are no entries.
+	evict(next *entry[K, V]) *entry[K, V]
+	clear()
+}
+
+// departure is an entry leaving the cache, recorded so the callback
+// can be called after the lock is released.
+type departure[K comparable, V any] struct {
+	key    K
+	value  V
+	reason Reason
+}
+
+// Cache is a bounded, threadsafe key-value cache.
+type Cache[K comparable, V any] struct {
+	lock     sync.Mutex
+	config   Config[K, V]
+	items    map[K]*entry[K, V]
+	policy   policy[K, V]
+	weight   uint64
+	now      func() time.Time
+	departed []departure[K, V]
+}
+
+func (c *Cache[K, V]) expired(e *entry[K, V], now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// drop removes an untracked entry from the cache.
+func (c *Cache[K, V]) drop(e *entry[K, V], reason Reason) {
+	delete(c.items, e.key)
+	c.weight -= e.weight
+	if c.config.OnEvict != nil {
+		c.departed = append(c.departed, departure[K, V]{e.key, e.value, reason})
+	}
+}
+
+// unlock releases the lock and then tells the callback of any
+// entries that left the cache while it was held.
+func (c *Cache[K, V]) unlock() {
+	departed := c.departed
+	c.departed = nil
+	c.lock.Unlock()
+
+	for _, d := range departed {
+		c.config.OnEvict(d.key, d.value, d.reason)
+	}
+}
+
+// Get returns the value of the provided key, counting as a use of
+// it.  The returned bool is false if the key isn't cached or has
+// expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.lock.Lock()
+	defer c.unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	if c.expired(e, c.now()) {
+		c.policy.remove(e)
+		c.drop(e, Expired)
+		var zero V
+		return zero, false
+	}
+
+	c.policy.hit(e)
+	return e.value, true
+}
+
+// Set caches the value under the provided key, expiring after the
+// cache's time to live, evicting entries as needed to make room.
+// Returns false, without caching the value, if it is heavier than the
+// cache's capacity.
+func (c *Cache[K, V]) Set(key K, value V) bool {
+	return c.SetWithTTL(key, value, c.config.TTL)
+}
+
+// SetWithTTL caches the value under the provided key, expiring after
+// the provided time to live or never if it is 0, evicting entries as
+// needed to make room.  Returns false, without caching the value, if
+// it is heavier than the cache's capacity.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	weight := uint64(1)
+	if c.config.Weigher != nil {
+		weight = c.config.Weigher(key, value)
+	}
+
+	c.lock.Lock()
+	defer c.unlock()
+
+	e, ok := c.items[key]
+	if weight > c.config.Capacity {
+		if ok {
+			c.policy.remove(e)
+			c.drop(e, Removed)
+		}
+		return false
+	}
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	if ok && weight <= e.weight {
+		shrink := e.weight - weight
+		c.weight -= shrink
+		e.list.weight -= shrink
+		e.value, e.weight, e.expires = value, weight, expires
+		c.policy.hit(e)
+		return true
+	}
+
+	if ok {
+		// the entry is growing, so it is taken out while room is
+		// made for it rather than risk evicting it.
+		c.policy.remove(e)
+		c.weight -= e.weight
+	} else {
+		e = &entry[K, V]{key: key}
+	}
+	e.value, e.weight, e.expires = value, weight, expires
+
+	for c.weight+weight > c.config.Capacity {
+		victim := c.policy.evict(e)
+		if victim == nil {
+			break
+		}
+		c.drop(victim, Evicted)
+	}
+
+	c.items[key] = e
+	c.weight += weight
+	c.policy.add(e)
+	return true
+}
+
+// Delete removes the provided key from the cache.  Returns a bool
+// indicating if it was cached.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.lock.Lock()
+	defer c.unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	c.policy.remove(e)
+	c.drop(e, Removed)
+	return true
+}
+
+// Prune removes every expired entry from the cache and returns the
+// number removed.
+func (c *Cache[K, V]) Prune() int {
+	c.lock.Lock()
+	defer c.unlock()
+
+	now, pruned := c.now(), 0
+	for _, e := range c.items {
+		if c.expired(e, now) {
+			c.policy.remove(e)
+			c.drop(e, Expired)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+// Clear removes every entry from the cache.
+func (c *Cache[K, V]) Clear() {
+	c.lock.Lock()
+	defer c.unlock()
+
+	if c.config.OnEvict != nil {
+		for _, e := range c.items {
+			c.departed = append(c.departed, departure[K, V]{e.key, e.value, Removed})
+		}
+	}
+
+	c.items = make(map[K]*entry[K, V])
+	c.weight = 0
+	c.policy.clear()
+}
+
+// Len returns the number of entries in the cache, including any that
+// have expired but not yet been removed.
+func (c *Cache[K, V]) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.items)
+}
+
+// Weight returns the total weight of the entries in the cache, which
+// is the number of entries if there is no weigher.
+func (c *Cache[K, V]) Weight() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.weight
+}
+
+// New returns an empty cache with the provided configuration.  Panics
+// if the capacity is 0 or the policy is unknown.
+func New[K comparable, V any](config Config[K, V]) *Cache[K, V] {
+	if config.Capacity == 0 {
+		panic(`Invalid capacity provided.`)
+	}
+
+	c := &Cache[K, V]{
+		config: config,
+		items:  make(map[K]*entry[K, V]),
+		now:    time.Now,
+	}
+
+	switch config.Policy {
+	case LRU:
+		c.policy = newLRU[K, V]()
+	case LFU:
+		c.policy = newLFU[K, V]()
+	case ARC:
+		c.policy = newARC[K, V](config.Capacity)
+	default:
+		panic(`Invalid policy provided.`)
+	}
+
+	return c
+}
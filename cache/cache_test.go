@@ -0,0 +1,297 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var policies = []Policy{LRU, LFU, ARC}
+
+// clock is a manually advanced time source.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestCache(config Config[int, int]) (*Cache[int, int], *clock) {
+	c := New(config)
+	clk := &clock{now: time.Unix(0, 0)}
+	c.now = clk.Now
+	return c, clk
+}
+
+type departed struct {
+	key, value int
+	reason     Reason
+}
+
+func recorder() (func(int, int, Reason), *[]departed) {
+	var lock sync.Mutex
+	result := []departed{}
+	return func(key, value int, reason Reason) {
+		lock.Lock()
+		defer lock.Unlock()
+		result = append(result, departed{key, value, reason})
+	}, &result
+}
+
+func TestGetSet(t *testing.T) {
+	for _, policy := range policies {
+		c, _ := newTestCache(Config[int, int]{Policy: policy, Capacity: 10})
+		assert.True(t, c.Set(1, 10))
+		assert.True(t, c.Set(2, 20))
+
+		value, ok := c.Get(1)
+		assert.True(t, ok)
+		assert.Equal(t, 10, value)
+
+		_, ok = c.Get(3)
+		assert.False(t, ok)
+
+		assert.True(t, c.Set(1, 11))
+		value, _ = c.Get(1)
+		assert.Equal(t, 11, value)
+		assert.Equal(t, 2, c.Len())
+		assert.Equal(t, uint64(2), c.Weight())
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	for _, policy := range policies {
+		onEvict, result := recorder()
+		c, _ := newTestCache(Config[int, int]{Policy: policy, Capacity: 3, OnEvict: onEvict})
+		for i := 0; i < 10; i++ {
+			c.Set(i, i)
+			assert.True(t, c.Len() <= 3)
+		}
+
+		assert.Equal(t, 3, c.Len())
+		assert.Len(t, *result, 7)
+		for _, d := range *result {
+			assert.Equal(t, Evicted, d.reason)
+			_, ok := c.Get(d.key)
+			assert.False(t, ok)
+		}
+	}
+}
+
+func TestTTL(t *testing.T) {
+	for _, policy := range policies {
+		onEvict, result := recorder()
+		c, clk := newTestCache(Config[int, int]{
+			Policy: policy, Capacity: 10, TTL: time.Minute, OnEvict: onEvict,
+		})
+		c.Set(1, 1)
+		c.SetWithTTL(2, 2, time.Hour)
+		c.SetWithTTL(3, 3, 0)
+
+		clk.now = clk.now.Add(time.Minute - 1)
+		_, ok := c.Get(1)
+		assert.True(t, ok)
+
+		clk.now = clk.now.Add(1)
+		_, ok = c.Get(1)
+		assert.False(t, ok)
+		assert.Equal(t, []departed{{1, 1, Expired}}, *result)
+
+		clk.now = clk.now.Add(time.Hour)
+		assert.Equal(t, 1, c.Prune())
+		assert.Equal(t, 1, c.Len())
+		_, ok = c.Get(3)
+		assert.True(t, ok)
+		assert.Equal(t, departed{2, 2, Expired}, (*result)[1])
+	}
+}
+
+func TestSetRefreshesTTL(t *testing.T) {
+	c, clk := newTestCache(Config[int, int]{Capacity: 10, TTL: time.Minute})
+	c.Set(1, 1)
+	clk.now = clk.now.Add(time.Second * 30)
+	c.Set(1, 2)
+	clk.now = clk.now.Add(time.Second * 45)
+
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+}
+
+func TestWeight(t *testing.T) {
+	for _, policy := range policies {
+		onEvict, result := recorder()
+		c, _ := newTestCache(Config[int, int]{
+			Policy:   policy,
+			Capacity: 10,
+			Weigher:  func(key, value int) uint64 { return uint64(value) },
+			OnEvict:  onEvict,
+		})
+
+		assert.True(t, c.Set(1, 4))
+		assert.True(t, c.Set(2, 4))
+		assert.Equal(t, uint64(8), c.Weight())
+
+		// too heavy for the cache at all
+		assert.False(t, c.Set(3, 11))
+		assert.Equal(t, 2, c.Len())
+
+		assert.True(t, c.Set(3, 5))
+		assert.Equal(t, uint64(9), c.Weight())
+		assert.Len(t, *result, 1)
+
+		// shrinking in place evicts nothing
+		assert.True(t, c.Set(3, 1))
+		assert.Equal(t, uint64(5), c.Weight())
+		assert.Len(t, *result, 1)
+
+		// growing makes room without evicting the entry itself
+		assert.True(t, c.Set(3, 10))
+		assert.Equal(t, uint64(10), c.Weight())
+		value, ok := c.Get(3)
+		assert.True(t, ok)
+		assert.Equal(t, 10, value)
+		assert.Equal(t, 1, c.Len())
+
+		// replacing a value with one too heavy drops the old value
+		assert.False(t, c.Set(3, 11))
+		assert.Equal(t, 0, c.Len())
+		assert.Equal(t, uint64(0), c.Weight())
+		assert.Equal(t, departed{3, 10, Removed}, (*result)[len(*result)-1])
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for _, policy := range policies {
+		onEvict, result := recorder()
+		c, _ := newTestCache(Config[int, int]{Policy: policy, Capacity: 10, OnEvict: onEvict})
+		c.Set(1, 1)
+		c.Set(2, 2)
+
+		assert.True(t, c.Delete(1))
+		assert.False(t, c.Delete(1))
+		assert.Equal(t, 1, c.Len())
+		assert.Equal(t, []departed{{1, 1, Removed}}, *result)
+
+		// the freed space is usable
+		for i := 10; i < 19; i++ {
+			c.Set(i, i)
+		}
+		assert.Equal(t, 10, c.Len())
+		assert.Len(t, *result, 1)
+	}
+}
+
+func TestClear(t *testing.T) {
+	for _, policy := range policies {
+		onEvict, result := recorder()
+		c, _ := newTestCache(Config[int, int]{Policy: policy, Capacity: 10, OnEvict: onEvict})
+		c.Set(1, 1)
+		c.Set(2, 2)
+
+		c.Clear()
+		assert.Equal(t, 0, c.Len())
+		assert.Equal(t, uint64(0), c.Weight())
+		assert.Len(t, *result, 2)
+
+		c.Set(3, 3)
+		value, ok := c.Get(3)
+		assert.True(t, ok)
+		assert.Equal(t, 3, value)
+	}
+}
+
+func TestCallbackMayUseCache(t *testing.T) {
+	var c *Cache[int, int]
+	c = New(Config[int, int]{
+		Capacity: 1,
+		OnEvict: func(key, value int, reason Reason) {
+			c.Get(key)
+			c.Len()
+		},
+	})
+
+	c.Set(1, 1)
+	c.Set(2, 2)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestNewInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		New(Config[int, int]{})
+	})
+
+	assert.Panics(t, func() {
+		New(Config[int, int]{Capacity: 1, Policy: Policy(10)})
+	})
+}
+
+func TestConcurrent(t *testing.T) {
+	for _, policy := range policies {
+		c := New(Config[int, int]{Policy: policy, Capacity: 100})
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					key := (i*j + j) % 300
+					c.Set(key, j)
+					c.Get(key / 2)
+					if j%10 == 0 {
+						c.Delete(key)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		assert.True(t, c.Len() <= 100)
+		assert.Equal(t, uint64(c.Len()), c.Weight())
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	for _, policy := range policies {
+		c := New(Config[int, int]{Policy: policy, Capacity: 1000})
+		for i := 0; i < 1000; i++ {
+			c.Set(i, i)
+		}
+
+		b.Run([]string{`LRU`, `LFU`, `ARC`}[policy], func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c.Get(i % 1000)
+			}
+		})
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	for _, policy := range policies {
+		c := New(Config[int, int]{Policy: policy, Capacity: 1000})
+
+		b.Run([]string{`LRU`, `LFU`, `ARC`}[policy], func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c.Set(i%5000, i)
+			}
+		})
+	}
+}
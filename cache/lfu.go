@@ -0,0 +1,101 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// bucket holds the entries that have been used freq times, in least
+// recently used order.  Buckets form a ring in ascending frequency.
+type bucket[K comparable, V any] struct {
+	freq       uint64
+	entries    list[K, V]
+	prev, next *bucket[K, V]
+}
+
+// lfu evicts the least frequently used entry, breaking ties by
+// evicting the least recently used.  Every operation is O(1).
+type lfu[K comparable, V any] struct {
+	root bucket[K, V]
+}
+
+// bucketAfter returns the bucket for freq, creating it after b if
+// it doesn't exist.
+func (p *lfu[K, V]) bucketAfter(b *bucket[K, V], freq uint64) *bucket[K, V] {
+	if b.next != &p.root && b.next.freq == freq {
+		return b.next
+	}
+
+	n := &bucket[K, V]{freq: freq, prev: b, next: b.next}
+	n.entries.init()
+	b.next.prev = n
+	b.next = n
+	return n
+}
+
+// unlink removes the entry from its bucket, dropping the bucket if
+// it is left empty.
+func (p *lfu[K, V]) unlink(e *entry[K, V]) {
+	b := e.bucket
+	b.entries.remove(e)
+	e.bucket = nil
+	if b.entries.len == 0 {
+		b.prev.next = b.next
+		b.next.prev = b.prev
+	}
+}
+
+func (p *lfu[K, V]) add(e *entry[K, V]) {
+	b := p.bucketAfter(&p.root, 1)
+	b.entries.pushFront(e)
+	e.bucket = b
+}
+
+func (p *lfu[K, V]) hit(e *entry[K, V]) {
+	b := e.bucket
+	// the bucket for the new frequency goes after the current one,
+	// or in its place if it is about to be dropped.
+	prev := b
+	if b.entries.len == 1 {
+		prev = b.prev
+	}
+	p.unlink(e)
+	n := p.bucketAfter(prev, b.freq+1)
+	n.entries.pushFront(e)
+	e.bucket = n
+}
+
+func (p *lfu[K, V]) remove(e *entry[K, V]) {
+	p.unlink(e)
+}
+
+func (p *lfu[K, V]) evict(*entry[K, V]) *entry[K, V] {
+	if p.root.next == &p.root {
+		return nil
+	}
+
+	e := p.root.next.entries.back()
+	p.unlink(e)
+	return e
+}
+
+func (p *lfu[K, V]) clear() {
+	p.root.next, p.root.prev = &p.root, &p.root
+}
+
+func newLFU[K comparable, V any]() *lfu[K, V] {
+	p := &lfu[K, V]{}
+	p.clear()
+	return p
+}
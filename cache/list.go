@@ -0,0 +1,63 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// list is an intrusive doubly linked list of entries, most recently
+// used at the front, along with their total weight.
+type list[K comparable, V any] struct {
+	root   entry[K, V]
+	len    int
+	weight uint64
+}
+
+func (l *list[K, V]) init() *list[K, V] {
+	l.root.next, l.root.prev = &l.root, &l.root
+	l.len, l.weight = 0, 0
+	return l
+}
+
+func (l *list[K, V]) pushFront(e *entry[K, V]) {
+	e.prev, e.next = &l.root, l.root.next
+	l.root.next.prev = e
+	l.root.next = e
+	e.list = l
+	l.len++
+	l.weight += e.weight
+}
+
+func (l *list[K, V]) remove(e *entry[K, V]) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.prev, e.next, e.list = nil, nil, nil
+	l.len--
+	l.weight -= e.weight
+}
+
+func (l *list[K, V]) moveToFront(e *entry[K, V]) {
+	l.remove(e)
+	l.pushFront(e)
+}
+
+// back returns the least recently used entry or nil if the list is
+// empty.
+func (l *list[K, V]) back() *entry[K, V] {
+	if l.len == 0 {
+		return nil
+	}
+
+	return l.root.prev
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+// lru evicts the least recently used entry.
+type lru[K comparable, V any] struct {
+	entries list[K, V]
+}
+
+func (p *lru[K, V]) add(e *entry[K, V]) {
+	p.entries.pushFront(e)
+}
+
+func (p *lru[K, V]) hit(e *entry[K, V]) {
+	p.entries.moveToFront(e)
+}
+
+func (p *lru[K, V]) remove(e *entry[K, V]) {
+	p.entries.remove(e)
+}
+
+func (p *lru[K, V]) evict(*entry[K, V]) *entry[K, V] {
+	e := p.entries.back()
+	if e != nil {
+		p.entries.remove(e)
+	}
+
+	return e
+}
+
+func (p *lru[K, V]) clear() {
+	p.entries.init()
+}
+
+func newLRU[K comparable, V any]() *lru[K, V] {
+	p := &lru[K, V]{}
+	p.entries.init()
+	return p
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func keys(c *Cache[int, int]) map[int]bool {
+	result := map[int]bool{}
+	for key := range c.items {
+		result[key] = true
+	}
+
+	return result
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(Config[int, int]{Policy: LRU, Capacity: 3})
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3)
+	c.Get(1)
+	c.Set(4, 4)
+
+	assert.Equal(t, map[int]bool{1: true, 3: true, 4: true}, keys(c))
+
+	c.Set(3, 30)
+	c.Set(5, 5)
+	assert.Equal(t, map[int]bool{3: true, 4: true, 5: true}, keys(c))
+}
+
+func TestLFUEvictsLeastFrequentlyUsed(t *testing.T) {
+	c := New(Config[int, int]{Policy: LFU, Capacity: 3})
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Get(3)
+
+	// 2 and 3 are tied, 2 was used less recently
+	c.Set(4, 4)
+	assert.Equal(t, map[int]bool{1: true, 3: true, 4: true}, keys(c))
+
+	// the new entry has been used least
+	c.Set(5, 5)
+	assert.Equal(t, map[int]bool{1: true, 3: true, 5: true}, keys(c))
+}
+
+func TestLFUBuckets(t *testing.T) {
+	p := newLFU[int, int]()
+	entries := make([]*entry[int, int], 4)
+	for i := range entries {
+		entries[i] = &entry[int, int]{key: i, weight: 1}
+		p.add(entries[i])
+	}
+
+	p.hit(entries[0])
+	p.hit(entries[0])
+	p.hit(entries[1])
+
+	var freqs []uint64
+	for b := p.root.next; b != &p.root; b = b.next {
+		freqs = append(freqs, b.freq)
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, freqs)
+
+	assert.Equal(t, entries[2], p.evict(nil))
+	assert.Equal(t, entries[3], p.evict(nil))
+	assert.Equal(t, entries[1], p.evict(nil))
+	assert.Equal(t, entries[0], p.evict(nil))
+	assert.Nil(t, p.evict(nil))
+	assert.Equal(t, &p.root, p.root.next)
+}
+
+func TestARCResistsScans(t *testing.T) {
+	for _, policy := range []Policy{LRU, ARC} {
+		c := New(Config[int, int]{Policy: policy, Capacity: 100})
+		// a hot set used repeatedly
+		for j := 0; j < 3; j++ {
+			for i := 0; i < 50; i++ {
+				c.Set(i, i)
+				c.Get(i)
+			}
+		}
+
+		// a long scan of keys used once
+		for i := 1000; i < 1500; i++ {
+			c.Set(i, i)
+		}
+
+		hot := 0
+		for i := 0; i < 50; i++ {
+			if _, ok := c.Get(i); ok {
+				hot++
+			}
+		}
+
+		if policy == ARC {
+			assert.Equal(t, 50, hot)
+		} else {
+			assert.Equal(t, 0, hot)
+		}
+	}
+}
+
+func TestARCGhostHitAdapts(t *testing.T) {
+	c := New(Config[int, int]{Policy: ARC, Capacity: 4})
+	p := c.policy.(*arc[int, int])
+	c.Set(0, 0)
+	c.Set(1, 1)
+	c.Get(0)
+	c.Get(1)
+	for i := 2; i < 6; i++ {
+		c.Set(i, i)
+	}
+
+	// 0 and 1 are in t2 while 2 and 3 were evicted from t1 and are
+	// remembered in b1
+	assert.Equal(t, 2, p.t2.len)
+	assert.Equal(t, 2, p.b1.len)
+	assert.Equal(t, uint64(0), p.target)
+
+	c.Set(2, 2)
+	assert.True(t, p.target > 0)
+	assert.Equal(t, 3, p.t2.len)
+	_, ok := p.ghosts[2]
+	assert.False(t, ok)
+	assert.True(t, p.t1.weight+p.t2.weight <= 4)
+}
+
+func TestARCBoundsGhosts(t *testing.T) {
+	c := New(Config[int, int]{Policy: ARC, Capacity: 10})
+	p := c.policy.(*arc[int, int])
+	for i := 0; i < 1000; i++ {
+		c.Set(i%100, i)
+		c.Get(i % 7)
+		total := p.t1.weight + p.t2.weight + p.b1.weight + p.b2.weight
+		assert.True(t, p.t1.weight+p.b1.weight <= 10)
+		assert.True(t, total <= 20)
+		assert.Equal(t, p.b1.len+p.b2.len, len(p.ghosts))
+		assert.True(t, p.target <= 10)
+	}
+}
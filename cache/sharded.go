@@ -0,0 +1,122 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import "time"
+
+// Sharded is a cache split into a number of independently locked
+// shards, each holding the keys that hash to it, to reduce lock
+// contention.  Each shard is given an equal share of the capacity
+// and evicts on its own, so a skewed hash may evict entries before
+// the cache as a whole is full.
+type Sharded[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	hasher func(K) uint64
+}
+
+func (s *Sharded[K, V]) shard(key K) *Cache[K, V] {
+	return s.shards[s.hasher(key)%uint64(len(s.shards))]
+}
+
+// Get returns the value of the provided key, counting as a use of
+// it.  The returned bool is false if the key isn't cached or has
+// expired.
+func (s *Sharded[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+// Set caches the value under the provided key, expiring after the
+// cache's time to live.  Returns false, without caching the value,
+// if it is heavier than a shard's capacity.
+func (s *Sharded[K, V]) Set(key K, value V) bool {
+	return s.shard(key).Set(key, value)
+}
+
+// SetWithTTL caches the value under the provided key, expiring after
+// the provided time to live or never if it is 0.  Returns false,
+// without caching the value, if it is heavier than a shard's
+// capacity.
+func (s *Sharded[K, V]) SetWithTTL(key K, value V, ttl time.Duration) bool {
+	return s.shard(key).SetWithTTL(key, value, ttl)
+}
+
+// Delete removes the provided key from the cache.  Returns a bool
+// indicating if it was cached.
+func (s *Sharded[K, V]) Delete(key K) bool {
+	return s.shard(key).Delete(key)
+}
+
+// Prune removes every expired entry from the cache and returns the
+// number removed.
+func (s *Sharded[K, V]) Prune() int {
+	pruned := 0
+	for _, shard := range s.shards {
+		pruned += shard.Prune()
+	}
+
+	return pruned
+}
+
+// Clear removes every entry from the cache.
+func (s *Sharded[K, V]) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+// Len returns the number of entries in the cache, including any that
+// have expired but not yet been removed.
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		n += shard.Len()
+	}
+
+	return n
+}
+
+// Weight returns the total weight of the entries in the cache.
+func (s *Sharded[K, V]) Weight() uint64 {
+	var weight uint64
+	for _, shard := range s.shards {
+		weight += shard.Weight()
+	}
+
+	return weight
+}
+
+// NewSharded returns an empty cache split into the provided number
+// of shards, placing keys using the provided hasher.  The capacity is
+// divided between the shards, rounding up.  Panics if the number of
+// shards is less than 1 or the configuration is invalid.
+func NewSharded[K comparable, V any](shards int, hasher func(K) uint64, config Config[K, V]) *Sharded[K, V] {
+	if shards < 1 {
+		panic(`Invalid number of shards provided.`)
+	}
+
+	s := &Sharded[K, V]{
+		shards: make([]*Cache[K, V], shards),
+		hasher: hasher,
+	}
+
+	config.Capacity = (config.Capacity + uint64(shards) - 1) / uint64(shards)
+	for i := range s.shards {
+		s.shards[i] = New(config)
+	}
+
+	return s
+}
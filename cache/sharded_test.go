@@ -0,0 +1,113 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intHasher(key int) uint64 {
+	return uint64(key) * 0x9e3779b97f4a7c15
+}
+
+func TestSharded(t *testing.T) {
+	s := NewSharded(4, intHasher, Config[int, int]{Capacity: 100})
+	assert.Len(t, s.shards, 4)
+	assert.Equal(t, uint64(25), s.shards[0].config.Capacity)
+
+	for i := 0; i < 50; i++ {
+		assert.True(t, s.Set(i, i))
+	}
+	assert.Equal(t, 50, s.Len())
+	assert.Equal(t, uint64(50), s.Weight())
+
+	for i := 0; i < 50; i++ {
+		value, ok := s.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+
+	assert.True(t, s.Delete(1))
+	assert.False(t, s.Delete(1))
+	assert.Equal(t, 49, s.Len())
+
+	assert.True(t, s.SetWithTTL(1, 1, 1))
+	assert.Equal(t, 1, s.Prune())
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestShardedCapacity(t *testing.T) {
+	s := NewSharded(3, intHasher, Config[int, int]{Capacity: 10})
+	for i := 0; i < 1000; i++ {
+		s.Set(i, i)
+	}
+
+	// each shard holds ceil(10 / 3)
+	assert.True(t, s.Len() <= 12)
+}
+
+func TestShardedInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewSharded(0, intHasher, Config[int, int]{Capacity: 10})
+	})
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	s := NewSharded(8, intHasher, Config[int, int]{Policy: ARC, Capacity: 1000})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				s.Set(i*2000+j, j)
+				s.Get(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, s.Len() <= 1000)
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	s := NewSharded(16, intHasher, Config[int, int]{Capacity: 10000})
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			s.Set(i%20000, i)
+			s.Get(i % 20000)
+			i++
+		}
+	})
+}
+
+func BenchmarkUnshardedParallel(b *testing.B) {
+	c := New(Config[int, int]{Capacity: 10000})
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Set(i%20000, i)
+			c.Get(i % 20000)
+			i++
+		}
+	})
+}
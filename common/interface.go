@@ -0,0 +1,94 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package common holds the small set of interfaces and errors that are shared by
+the datastructures in this library.  Structures satisfy these
+interfaces implicitly; this package exists so consumers can write code
+against a behavior without importing every concrete package.
+*/
+package common
+
+import "errors"
+
+// ErrDisposed is matched, using errors.Is, by the error every
+// Disposable structure returns from calls made after it has been
+// disposed, including calls that were blocked when Dispose was called.
+var ErrDisposed = errors.New(`Structure has been disposed.`)
+
+// ErrOutOfRange is matched, using errors.Is, by the errors structures
+// return when given a position or index beyond their bounds, so that
+// callers can tell a bad position from an absent value.
+var ErrOutOfRange = errors.New(`Position is out of range.`)
+
+// ErrDimensionMismatch is matched, using errors.Is, by the errors
+// multidimensional structures return when given a dimension they
+// don't have.
+var ErrDimensionMismatch = errors.New(`Dimension does not match the structure.`)
+
+// Snapshotter defines structures that can produce a point-in-time
+// copy of themselves.  A snapshot is cheap to take: storage is shared
+// with the original until either side is mutated, at which point the
+// mutating side copies what it needs.  The snapshot and the original
+// are fully independent afterwards, so a snapshot may be read by one
+// goroutine while the original continues to be written by another.
+type Snapshotter[T any] interface {
+	// Snapshot returns a point-in-time copy of this structure.
+	Snapshot() T
+}
+
+// Disposable defines structures that can be shut down, such as queues
+// and futures that goroutines block on.  Dispose releases every
+// goroutine blocked on the structure, each returning an error matching
+// ErrDisposed, and causes later calls to fail the same way.  Dispose
+// may be called more than once.
+type Disposable interface {
+	// Dispose shuts down this structure and wakes all waiters.
+	Dispose()
+	// Disposed returns a bool indicating if Dispose has been called.
+	Disposed() bool
+}
+
+// Metrics receives the counts and observations reported by structures
+// it has been given to so they can be exported to a system such as
+// Prometheus.  Each structure documents which of the names below it
+// reports.  Implementations used with a structure that is shared
+// between goroutines must be safe for concurrent use.
+type Metrics interface {
+	// Add adds delta, which may be negative, to the named counter
+	// or gauge.
+	Add(name string, delta int64)
+	// Observe records a single value for the named histogram.
+	Observe(name string, value float64)
+}
+
+// These are the names reported to Metrics.
+const (
+	// MetricInserts counts inserted items, including those that
+	// replaced an equal item.
+	MetricInserts = `inserts`
+	// MetricDeletes counts removed items.
+	MetricDeletes = `deletes`
+	// MetricSearches counts lookups by key.
+	MetricSearches = `searches`
+	// MetricNodes is a gauge of the nodes currently in a structure.
+	MetricNodes = `nodes`
+	// MetricLevel observes the level of each new skiplist node.
+	MetricLevel = `level`
+	// MetricContention counts operations that had to retry because
+	// another goroutine got there first.
+	MetricContention = `contention`
+)
@@ -0,0 +1,179 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/*
+Package countmin implements count-min sketches, which estimate how many
+times each item has been added to a stream using a fixed amount of
+memory.  The sketch is a grid of counters, depth rows of width columns,
+and an item is counted in one column of every row chosen by hashing.
+An item's count is estimated by the smallest of its counters, which is
+never less than the true count and exceeds it by more than epsilon
+times the total of all counts only with probability delta, where
+width = e/epsilon and depth = ln(1/delta).
+
+Sketches use conservative update, which only raises an item's counters
+as far as needed to cover its new estimated count.  This can't break
+the guarantee above and greatly reduces overestimates for infrequent
+items.
+
+TopK pairs a sketch with a min-heap of the k items with the highest
+estimated counts seen so far, for finding heavy hitters in a stream
+without keeping every item.
+
+Sketches of the same size can be merged, for aggregating counts across
+machines.  Counts of merged sketches remain upper bounds, though they
+may overestimate more than a sketch that saw every item itself.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(width * depth), plus O(k) for TopK
+Add: O(depth), plus O(log k) for TopK
+Estimate: O(depth)
+Merge: O(width * depth)
+*/
+package countmin
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+// Sketch is a count-min sketch with conservative update.
+type Sketch struct {
+	width, depth uint64
+	counters     []uint64
+	total        uint64
+}
+
+// hashes returns the two hashes of the provided item from which its
+// column in each row is derived.
+func hashes(data []byte) (uint64, uint64) {
+	hash := fnv.New64a()
+	hash.Write(data)
+	h := hash.Sum64()
+	return hashutil.Mix(h), hashutil.Mix(h^0x9e3779b97f4a7c15) | 1
+}
+
+// index returns the position of the provided row's counter for an item
+// with the provided hashes.
+func (s *Sketch) index(row, h1, h2 uint64) uint64 {
+	return row*s.width + (h1+row*h2)%s.width
+}
+
+// estimate returns the smallest of an item's counters.
+func (s *Sketch) estimate(h1, h2 uint64) uint64 {
+	min := uint64(math.MaxUint64)
+	for row := uint64(0); row < s.depth; row++ {
+		if count := s.counters[s.index(row, h1, h2)]; count < min {
+			min = count
+		}
+	}
+
+	return min
+}
+
+// Add counts the provided item count more times and returns its new
+// estimated count.
+func (s *Sketch) Add(data []byte, count uint64) uint64 {
+	h1, h2 := hashes(data)
+	estimate := s.estimate(h1, h2) + count
+	for row := uint64(0); row < s.depth; row++ {
+		i := s.index(row, h1, h2)
+		if s.counters[i] < estimate {
+			s.counters[i] = estimate
+		}
+	}
+
+	s.total += count
+	return estimate
+}
+
+// Estimate returns the estimated number of times the provided item has
+// been added, which is never less than the true number.
+func (s *Sketch) Estimate(data []byte) uint64 {
+	return s.estimate(hashes(data))
+}
+
+// Merge adds every count in other to this sketch.  Both sketches must
+// have the same width and depth, which is the case for sketches
+// created with the same parameters.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.width != s.width || other.depth != s.depth {
+		return MismatchError{
+			width: s.width, depth: s.depth,
+			otherWidth: other.width, otherDepth: other.depth,
+		}
+	}
+
+	for i, count := range other.counters {
+		s.counters[i] += count
+	}
+	s.total += other.total
+	return nil
+}
+
+// Reset sets every count back to zero.
+func (s *Sketch) Reset() {
+	for i := range s.counters {
+		s.counters[i] = 0
+	}
+	s.total = 0
+}
+
+// Total returns the sum of all counts added to the sketch.
+func (s *Sketch) Total() uint64 {
+	return s.total
+}
+
+// Width returns the number of counters in each row.
+func (s *Sketch) Width() uint64 {
+	return s.width
+}
+
+// Depth returns the number of rows.
+func (s *Sketch) Depth() uint64 {
+	return s.depth
+}
+
+// dimensions returns the width and depth needed for estimates to
+// exceed true counts by at most epsilon times the total with
+// probability 1-delta.
+func dimensions(epsilon, delta float64) (uint64, uint64) {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		panic(`Invalid error or confidence provided.`)
+	}
+
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	return uint64(width), uint64(depth)
+}
+
+func newSketch(width, depth uint64) *Sketch {
+	return &Sketch{
+		width:    width,
+		depth:    depth,
+		counters: make([]uint64, width*depth),
+	}
+}
+
+// New returns a sketch whose estimates exceed true counts by at most
+// epsilon times the total of all counts with probability 1-delta.
+// Both must be between 0 and 1.
+func New(epsilon, delta float64) *Sketch {
+	return newSketch(dimensions(epsilon, delta))
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package countmin
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDimensions(t *testing.T) {
+	s := New(0.001, 0.01)
+	assert.Equal(t, uint64(2719), s.Width())
+	assert.Equal(t, uint64(5), s.Depth())
+
+	assert.Panics(t, func() { New(0, 0.01) })
+	assert.Panics(t, func() { New(0.01, 1) })
+}
+
+func TestAddEstimate(t *testing.T) {
+	s := New(0.01, 0.01)
+	assert.Equal(t, uint64(0), s.Estimate([]byte(`a`)))
+
+	assert.Equal(t, uint64(1), s.Add([]byte(`a`), 1))
+	assert.Equal(t, uint64(4), s.Add([]byte(`a`), 3))
+	s.Add([]byte(`b`), 2)
+
+	assert.Equal(t, uint64(4), s.Estimate([]byte(`a`)))
+	assert.Equal(t, uint64(2), s.Estimate([]byte(`b`)))
+	assert.Equal(t, uint64(6), s.Total())
+
+	s.Reset()
+	assert.Equal(t, uint64(0), s.Estimate([]byte(`a`)))
+	assert.Equal(t, uint64(0), s.Total())
+}
+
+func TestErrorBound(t *testing.T) {
+	epsilon := 0.001
+	s := New(epsilon, 0.001)
+	counts := make(map[string]uint64)
+	for i := 0; i < 10000; i++ {
+		// a skewed distribution with a few heavy items
+		item := strconv.Itoa(i % (1 + i%100))
+		counts[item]++
+		s.Add([]byte(item), 1)
+	}
+
+	bound := uint64(epsilon * float64(s.Total()))
+	for item, count := range counts {
+		estimate := s.Estimate([]byte(item))
+		assert.True(t, estimate >= count)
+		assert.True(t, estimate-count <= bound, `%s: %d vs %d`, item, estimate, count)
+	}
+}
+
+func TestConservativeUpdate(t *testing.T) {
+	// a single column forces every item to collide
+	s := newSketch(1, 2)
+	s.Add([]byte(`a`), 5)
+	s.Add([]byte(`b`), 5)
+
+	// a plain sketch would hold 10 in every counter, conservative
+	// update raises them only as far as b needs
+	assert.Equal(t, []uint64{10, 10}, s.counters)
+	assert.Equal(t, uint64(10), s.Total())
+}
+
+func TestMerge(t *testing.T) {
+	s := New(0.01, 0.01)
+	other := New(0.01, 0.01)
+	s.Add([]byte(`a`), 2)
+	other.Add([]byte(`a`), 3)
+	other.Add([]byte(`b`), 1)
+
+	assert.Nil(t, s.Merge(other))
+	assert.Equal(t, uint64(5), s.Estimate([]byte(`a`)))
+	assert.Equal(t, uint64(1), s.Estimate([]byte(`b`)))
+	assert.Equal(t, uint64(6), s.Total())
+	assert.Equal(t, uint64(3), other.Estimate([]byte(`a`)))
+
+	err := s.Merge(New(0.001, 0.01))
+	assert.IsType(t, MismatchError{}, err)
+}
+
+func BenchmarkAdd(b *testing.B) {
+	s := New(0.0001, 0.001)
+	keys := make([][]byte, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, []byte(strconv.Itoa(i%10000)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Add(keys[i], 1)
+	}
+}
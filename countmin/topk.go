@@ -0,0 +1,185 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package countmin
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// ItemCount is an item and its estimated count.
+type ItemCount struct {
+	Item  string
+	Count uint64
+}
+
+// ItemCounts is a typed list of ItemCount.
+type ItemCounts []ItemCount
+
+// TopK tracks the k items with the highest estimated counts in a
+// stream.  Every item is counted by a sketch and the heaviest are kept
+// in a min-heap, so an item that becomes heavy after being evicted can
+// return with its full estimated count.
+type TopK struct {
+	k      int
+	sketch *Sketch
+	top    minHeap
+}
+
+// Add counts the provided item count more times and returns its new
+// estimated count.
+func (tk *TopK) Add(data []byte, count uint64) uint64 {
+	estimate := tk.sketch.Add(data, count)
+	tk.offer(string(data), estimate)
+	return estimate
+}
+
+// offer puts the item in the heap if it's already there or is heavier
+// than the lightest item kept.
+func (tk *TopK) offer(item string, estimate uint64) {
+	if i, ok := tk.top.index[item]; ok {
+		tk.top.items[i].Count = estimate
+		heap.Fix(&tk.top, i)
+		return
+	}
+
+	ic := ItemCount{Item: item, Count: estimate}
+	if len(tk.top.items) < tk.k {
+		heap.Push(&tk.top, ic)
+		return
+	}
+
+	if tk.top.less(tk.top.items[0], ic) {
+		delete(tk.top.index, tk.top.items[0].Item)
+		tk.top.items[0] = ic
+		tk.top.index[item] = 0
+		heap.Fix(&tk.top, 0)
+	}
+}
+
+// Estimate returns the estimated number of times the provided item has
+// been added, whether or not it's in the top k.
+func (tk *TopK) Estimate(data []byte) uint64 {
+	return tk.sketch.Estimate(data)
+}
+
+// Top returns the items with the highest estimated counts in
+// descending order of count.  Ties are broken by item.
+func (tk *TopK) Top() ItemCounts {
+	result := make(ItemCounts, len(tk.top.items))
+	copy(result, tk.top.items)
+	sort.Slice(result, func(i, j int) bool {
+		return tk.top.less(result[j], result[i])
+	})
+
+	return result
+}
+
+// Merge adds every count in other to this structure.  Both sketches
+// must have the same width and depth.  The items kept by either are
+// estimated again from the merged sketch and the heaviest k are kept.
+func (tk *TopK) Merge(other *TopK) error {
+	if err := tk.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+
+	candidates := append(tk.Top(), other.Top()...)
+	tk.top = newMinHeap(tk.k)
+	for _, ic := range candidates {
+		if _, ok := tk.top.index[ic.Item]; ok {
+			continue
+		}
+		tk.offer(ic.Item, tk.sketch.Estimate([]byte(ic.Item)))
+	}
+
+	return nil
+}
+
+// Reset sets every count back to zero and forgets the top items.
+func (tk *TopK) Reset() {
+	tk.sketch.Reset()
+	tk.top = newMinHeap(tk.k)
+}
+
+// K returns the number of items tracked.
+func (tk *TopK) K() int {
+	return tk.k
+}
+
+// Sketch returns the sketch counting every item.
+func (tk *TopK) Sketch() *Sketch {
+	return tk.sketch
+}
+
+// NewTopK returns a structure tracking the k items with the highest
+// estimated counts, using a sketch created with New(epsilon, delta).
+func NewTopK(k int, epsilon, delta float64) *TopK {
+	if k < 1 {
+		panic(`K must be positive.`)
+	}
+
+	return &TopK{
+		k:      k,
+		sketch: New(epsilon, delta),
+		top:    newMinHeap(k),
+	}
+}
+
+// minHeap keeps the heaviest items seen so far with the lightest at
+// the root so it can be replaced.  index holds the position of each
+// item so its count can be updated in place.
+type minHeap struct {
+	items ItemCounts
+	index map[string]int
+}
+
+func (mh minHeap) less(a, b ItemCount) bool {
+	if a.Count == b.Count {
+		return a.Item > b.Item
+	}
+
+	return a.Count < b.Count
+}
+
+func (mh minHeap) Len() int           { return len(mh.items) }
+func (mh minHeap) Less(i, j int) bool { return mh.less(mh.items[i], mh.items[j]) }
+
+func (mh minHeap) Swap(i, j int) {
+	mh.items[i], mh.items[j] = mh.items[j], mh.items[i]
+	mh.index[mh.items[i].Item] = i
+	mh.index[mh.items[j].Item] = j
+}
+
+func (mh *minHeap) Push(x interface{}) {
+	ic := x.(ItemCount)
+	mh.index[ic.Item] = len(mh.items)
+	mh.items = append(mh.items, ic)
+}
+
+func (mh *minHeap) Pop() interface{} {
+	n := len(mh.items)
+	ic := mh.items[n-1]
+	mh.items = mh.items[:n-1]
+	delete(mh.index, ic.Item)
+	return ic
+}
+
+func newMinHeap(k int) minHeap {
+	return minHeap{
+		items: make(ItemCounts, 0, k),
+		index: make(map[string]int, k),
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package countmin
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopK(t *testing.T) {
+	tk := NewTopK(3, 0.001, 0.001)
+	assert.Equal(t, ItemCounts{}, tk.Top())
+
+	for i := 0; i < 100; i++ {
+		tk.Add([]byte(strconv.Itoa(i)), 1)
+	}
+	tk.Add([]byte(`heavy`), 50)
+	tk.Add([]byte(`medium`), 20)
+	tk.Add([]byte(`light`), 10)
+	tk.Add([]byte(`medium`), 20)
+
+	assert.Equal(t, ItemCounts{
+		{Item: `heavy`, Count: 50},
+		{Item: `medium`, Count: 40},
+		{Item: `light`, Count: 10},
+	}, tk.Top())
+	assert.Equal(t, uint64(1), tk.Estimate([]byte(`7`)))
+	assert.Equal(t, 3, tk.K())
+	assert.Equal(t, uint64(200), tk.Sketch().Total())
+}
+
+func TestTopKEvicted(t *testing.T) {
+	tk := NewTopK(2, 0.001, 0.001)
+	tk.Add([]byte(`a`), 10)
+	tk.Add([]byte(`b`), 10)
+	tk.Add([]byte(`c`), 5)
+	assert.Equal(t, ItemCounts{{Item: `a`, Count: 10}, {Item: `b`, Count: 10}}, tk.Top())
+
+	// c returns with every count the sketch has seen
+	tk.Add([]byte(`c`), 6)
+	assert.Equal(t, ItemCounts{{Item: `c`, Count: 11}, {Item: `a`, Count: 10}}, tk.Top())
+}
+
+func TestTopKMerge(t *testing.T) {
+	tk := NewTopK(2, 0.001, 0.001)
+	other := NewTopK(2, 0.001, 0.001)
+	tk.Add([]byte(`a`), 10)
+	tk.Add([]byte(`b`), 8)
+	tk.Add([]byte(`c`), 7)
+	other.Add([]byte(`c`), 7)
+	other.Add([]byte(`d`), 9)
+
+	assert.Nil(t, tk.Merge(other))
+	// c was only kept by other but its merged count is the highest
+	assert.Equal(t, ItemCounts{{Item: `c`, Count: 14}, {Item: `a`, Count: 10}}, tk.Top())
+
+	err := tk.Merge(NewTopK(2, 0.01, 0.001))
+	assert.IsType(t, MismatchError{}, err)
+
+	tk.Reset()
+	assert.Equal(t, ItemCounts{}, tk.Top())
+	assert.Equal(t, uint64(0), tk.Estimate([]byte(`a`)))
+}
+
+func TestNewTopKPanics(t *testing.T) {
+	assert.Panics(t, func() { NewTopK(0, 0.01, 0.01) })
+}
+
+func BenchmarkTopKAdd(b *testing.B) {
+	tk := NewTopK(100, 0.0001, 0.001)
+	keys := make([][]byte, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, []byte(strconv.Itoa(i%(1+i%1000))))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tk.Add(keys[i], 1)
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deque
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// Bounded is a threadsafe deque that holds at most a fixed number of
+// items.  Offer and Poll never block, while Push waits for room and
+// Pop waits for an item until the provided context is done or the
+// deque is disposed.
+type Bounded[T any] struct {
+	lock     sync.Mutex
+	deque    Deque[T]
+	size     int
+	disposed bool
+	// changed, if not nil, is closed to wake every waiter the next
+	// time an item is added or removed.
+	changed chan struct{}
+}
+
+// signal wakes any goroutines waiting on a change.  It must be called
+// with the lock held.
+func (b *Bounded[T]) signal() {
+	if b.changed != nil {
+		close(b.changed)
+		b.changed = nil
+	}
+}
+
+// wait blocks until ready returns true, which it is called with the
+// lock held to check, and returns with the lock still held.  Returns
+// an error, without the lock, if the deque is disposed or the context
+// is done first.
+func (b *Bounded[T]) wait(ctx context.Context, ready func() bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b.lock.Lock()
+		if b.disposed {
+			b.lock.Unlock()
+			return common.ErrDisposed
+		}
+
+		if ready() {
+			return nil
+		}
+
+		if b.changed == nil {
+			b.changed = make(chan struct{})
+		}
+		changed := b.changed
+		b.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Bounded[T]) full() bool {
+	return b.deque.Len() >= b.size
+}
+
+func (b *Bounded[T]) empty() bool {
+	return b.deque.Len() == 0
+}
+
+// offer adds the item to the deque if there is room.
+func (b *Bounded[T]) offer(item T, front bool) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed || b.full() {
+		return false
+	}
+
+	b.push(item, front)
+	return true
+}
+
+// push adds the item to the deque.  It must be called with the lock
+// held.
+func (b *Bounded[T]) push(item T, front bool) {
+	if front {
+		b.deque.PushFront(item)
+	} else {
+		b.deque.PushBack(item)
+	}
+	b.signal()
+}
+
+// pop removes an item from the deque, returning false if it is
+// empty.  It must be called with the lock held.
+func (b *Bounded[T]) pop(front bool) (T, bool) {
+	var item T
+	var ok bool
+	if front {
+		item, ok = b.deque.PopFront()
+	} else {
+		item, ok = b.deque.PopBack()
+	}
+	if ok {
+		b.signal()
+	}
+
+	return item, ok
+}
+
+func (b *Bounded[T]) poll(front bool) (T, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		var zero T
+		return zero, false
+	}
+
+	return b.pop(front)
+}
+
+func (b *Bounded[T]) pushWait(ctx context.Context, item T, front bool) error {
+	if err := b.wait(ctx, func() bool { return !b.full() }); err != nil {
+		return err
+	}
+
+	b.push(item, front)
+	b.lock.Unlock()
+	return nil
+}
+
+func (b *Bounded[T]) popWait(ctx context.Context, front bool) (T, error) {
+	if err := b.wait(ctx, func() bool { return !b.empty() }); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	item, _ := b.pop(front)
+	b.lock.Unlock()
+	return item, nil
+}
+
+// OfferFront adds the item to the front of the deque without
+// blocking.  Returns false if the deque is full or has been disposed.
+func (b *Bounded[T]) OfferFront(item T) bool {
+	return b.offer(item, true)
+}
+
+// OfferBack adds the item to the back of the deque without blocking.
+// Returns false if the deque is full or has been disposed.
+func (b *Bounded[T]) OfferBack(item T) bool {
+	return b.offer(item, false)
+}
+
+// PollFront removes and returns the item at the front of the deque
+// without blocking.  Returns false if the deque is empty or has been
+// disposed.
+func (b *Bounded[T]) PollFront() (T, bool) {
+	return b.poll(true)
+}
+
+// PollBack removes and returns the item at the back of the deque
+// without blocking.  Returns false if the deque is empty or has been
+// disposed.
+func (b *Bounded[T]) PollBack() (T, bool) {
+	return b.poll(false)
+}
+
+// PushFront adds the item to the front of the deque, waiting for room
+// if it is full.  Returns the context's error if it is done first and
+// an error matching common.ErrDisposed if the deque is disposed.
+func (b *Bounded[T]) PushFront(ctx context.Context, item T) error {
+	return b.pushWait(ctx, item, true)
+}
+
+// PushBack adds the item to the back of the deque, waiting for room
+// if it is full.  Returns the context's error if it is done first and
+// an error matching common.ErrDisposed if the deque is disposed.
+func (b *Bounded[T]) PushBack(ctx context.Context, item T) error {
+	return b.pushWait(ctx, item, false)
+}
+
+// PopFront removes and returns the item at the front of the deque,
+// waiting for one if it is empty.  Returns the context's error if it
+// is done first and an error matching common.ErrDisposed if the deque
+// is disposed.
+func (b *Bounded[T]) PopFront(ctx context.Context) (T, error) {
+	return b.popWait(ctx, true)
+}
+
+// PopBack removes and returns the item at the back of the deque,
+// waiting for one if it is empty.  Returns the context's error if it
+// is done first and an error matching common.ErrDisposed if the deque
+// is disposed.
+func (b *Bounded[T]) PopBack(ctx context.Context) (T, error) {
+	return b.popWait(ctx, false)
+}
+
+// Len returns the number of items in the deque.
+func (b *Bounded[T]) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.deque.Len()
+}
+
+// Cap returns the number of items the deque can hold.
+func (b *Bounded[T]) Cap() int {
+	return b.size
+}
+
+// Disposed returns a bool indicating if this deque has been disposed.
+func (b *Bounded[T]) Disposed() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.disposed
+}
+
+// Dispose releases the items in the deque and every goroutine waiting
+// in Push or Pop, which return an error matching common.ErrDisposed,
+// and causes subsequent calls to fail.
+func (b *Bounded[T]) Dispose() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.disposed = true
+	b.deque.Clear()
+	b.signal()
+}
+
+// NewBounded returns an empty threadsafe deque that holds at most
+// size items.  The size must be greater than 0.
+func NewBounded[T any](size int) *Bounded[T] {
+	if size <= 0 {
+		panic(`Bounded deque size must be greater than 0.`)
+	}
+
+	return &Bounded[T]{size: size}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deque
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+func TestBoundedOfferPoll(t *testing.T) {
+	b := NewBounded[int](3)
+	assert.Equal(t, 3, b.Cap())
+
+	assert.True(t, b.OfferBack(2))
+	assert.True(t, b.OfferFront(1))
+	assert.True(t, b.OfferBack(3))
+	assert.False(t, b.OfferBack(4))
+	assert.False(t, b.OfferFront(0))
+	assert.Equal(t, 3, b.Len())
+
+	item, ok := b.PollFront()
+	assert.True(t, ok)
+	assert.Equal(t, 1, item)
+	item, ok = b.PollBack()
+	assert.True(t, ok)
+	assert.Equal(t, 3, item)
+	item, ok = b.PollBack()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	_, ok = b.PollFront()
+	assert.False(t, ok)
+}
+
+func TestBoundedPushWaitsForRoom(t *testing.T) {
+	b := NewBounded[int](1)
+	assert.Nil(t, b.PushBack(context.Background(), 1))
+
+	done := make(chan error)
+	go func() {
+		done <- b.PushFront(context.Background(), 2)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal(`push should wait while the deque is full`)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	item, err := b.PopBack(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, item)
+	assert.Nil(t, <-done)
+
+	item, ok := b.PollFront()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+}
+
+func TestBoundedPopWaitsForItem(t *testing.T) {
+	b := NewBounded[int](1)
+	done := make(chan int)
+	go func() {
+		item, err := b.PopFront(context.Background())
+		assert.Nil(t, err)
+		done <- item
+	}()
+
+	select {
+	case <-done:
+		t.Fatal(`pop should wait while the deque is empty`)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	assert.True(t, b.OfferBack(5))
+	assert.Equal(t, 5, <-done)
+}
+
+func TestBoundedContext(t *testing.T) {
+	b := NewBounded[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := b.PopBack(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	assert.True(t, b.OfferBack(1))
+	assert.Equal(t, context.DeadlineExceeded, b.PushBack(ctx, 2))
+	assert.Equal(t, 1, b.Len())
+}
+
+func TestBoundedDispose(t *testing.T) {
+	b := NewBounded[int](1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := b.PopFront(context.Background())
+		assert.True(t, errors.Is(err, common.ErrDisposed))
+	}()
+	go func() {
+		defer wg.Done()
+		_, err := b.PopBack(context.Background())
+		assert.True(t, errors.Is(err, common.ErrDisposed))
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	b.Dispose()
+	wg.Wait()
+
+	assert.True(t, b.Disposed())
+	assert.False(t, b.OfferBack(1))
+	_, ok := b.PollFront()
+	assert.False(t, ok)
+	assert.True(t, errors.Is(b.PushFront(context.Background(), 1), common.ErrDisposed))
+}
+
+func TestBoundedConcurrent(t *testing.T) {
+	b := NewBounded[int](8)
+	const producers, items = 4, 1000
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < items; i++ {
+				var err error
+				if i%2 == 0 {
+					err = b.PushBack(context.Background(), 1)
+				} else {
+					err = b.PushFront(context.Background(), 1)
+				}
+				assert.Nil(t, err)
+			}
+		}(p)
+	}
+
+	sum := 0
+	for i := 0; i < producers*items; i++ {
+		var item int
+		var err error
+		if i%2 == 0 {
+			item, err = b.PopFront(context.Background())
+		} else {
+			item, err = b.PopBack(context.Background())
+		}
+		assert.Nil(t, err)
+		sum += item
+	}
+
+	wg.Wait()
+	assert.Equal(t, producers*items, sum)
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestNewBoundedPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		NewBounded[int](0)
+	})
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package deque implements a double-ended queue, which can be used as a
+FIFO queue, a stack or both at once.  Items are kept in a ring buffer
+that doubles when full and halves when a quarter full, so pushes and
+pops at either end are O(1) amortized and any item can be read or
+replaced by its index in O(1).
+
+Deque is not threadsafe.  Bounded wraps a deque of fixed capacity with
+a lock and adds pushes that wait for room and pops that wait for items,
+for use between producers and consumers.
+
+Performance characteristics:
+PushFront/PushBack: O(1) amortized
+PopFront/PopBack: O(1) amortized
+At/Set: O(1)
+Space: O(n)
+*/
+package deque
+
+import "iter"
+
+// minCapacity is the smallest ring a deque allocates.  It must be a
+// power of two.
+const minCapacity = 16
+
+// Deque is a double-ended queue backed by a ring buffer.  The zero
+// value is an empty deque ready to use.
+type Deque[T any] struct {
+	// items is the ring, whose length is zero or a power of two.
+	items []T
+	// head is the index in items of the front of the deque.
+	head, count int
+}
+
+// index returns the index in the ring of the item at position i.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) & (len(d.items) - 1)
+}
+
+// resize moves the items into a new ring of the provided size.
+func (d *Deque[T]) resize(size int) {
+	items := make([]T, size)
+	if d.head+d.count <= len(d.items) {
+		copy(items, d.items[d.head:d.head+d.count])
+	} else {
+		n := copy(items, d.items[d.head:])
+		copy(items[n:], d.items[:d.count-n])
+	}
+
+	d.items, d.head = items, 0
+}
+
+// grow makes room for one more item.
+func (d *Deque[T]) grow() {
+	if d.count < len(d.items) {
+		return
+	}
+
+	d.resize(max(minCapacity, len(d.items)*2))
+}
+
+// shrink halves the ring once it is a quarter full.
+func (d *Deque[T]) shrink() {
+	if len(d.items) > minCapacity && d.count <= len(d.items)/4 {
+		d.resize(len(d.items) / 2)
+	}
+}
+
+// PushFront adds the item to the front of the deque.
+func (d *Deque[T]) PushFront(item T) {
+	d.grow()
+	d.head = d.index(len(d.items) - 1)
+	d.items[d.head] = item
+	d.count++
+}
+
+// PushBack adds the item to the back of the deque.
+func (d *Deque[T]) PushBack(item T) {
+	d.grow()
+	d.items[d.index(d.count)] = item
+	d.count++
+}
+
+// PopFront removes and returns the item at the front of the deque.
+// Returns false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+
+	item := d.items[d.head]
+	// clear the slot so the item can be garbage collected
+	d.items[d.head] = zero
+	d.head = d.index(1)
+	d.count--
+	d.shrink()
+	return item, true
+}
+
+// PopBack removes and returns the item at the back of the deque.
+// Returns false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.count == 0 {
+		return zero, false
+	}
+
+	i := d.index(d.count - 1)
+	item := d.items[i]
+	d.items[i] = zero
+	d.count--
+	d.shrink()
+	return item, true
+}
+
+// Front returns the item at the front of the deque without removing
+// it.  Returns false if the deque is empty.
+func (d *Deque[T]) Front() (T, bool) {
+	return d.At(0)
+}
+
+// Back returns the item at the back of the deque without removing
+// it.  Returns false if the deque is empty.
+func (d *Deque[T]) Back() (T, bool) {
+	return d.At(d.count - 1)
+}
+
+// At returns the item at position i, counting from zero at the
+// front.  Returns false if i is out of bounds.
+func (d *Deque[T]) At(i int) (T, bool) {
+	if i < 0 || i >= d.count {
+		var zero T
+		return zero, false
+	}
+
+	return d.items[d.index(i)], true
+}
+
+// Set replaces the item at position i, counting from zero at the
+// front.  Returns false, leaving the deque unchanged, if i is out of
+// bounds.
+func (d *Deque[T]) Set(i int, item T) bool {
+	if i < 0 || i >= d.count {
+		return false
+	}
+
+	d.items[d.index(i)] = item
+	return true
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque[T]) Len() int {
+	return d.count
+}
+
+// Cap returns the number of items the deque can hold before it next
+// grows.
+func (d *Deque[T]) Cap() int {
+	return len(d.items)
+}
+
+// Clear removes every item from the deque and releases its storage.
+func (d *Deque[T]) Clear() {
+	d.items, d.head, d.count = nil, 0, 0
+}
+
+// All returns a sequence of the items from front to back.  The deque
+// must not be modified during iteration.
+func (d *Deque[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < d.count; i++ {
+			if !yield(d.items[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns a sequence of the items from back to front.  The
+// deque must not be modified during iteration.
+func (d *Deque[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := d.count - 1; i >= 0; i-- {
+			if !yield(d.items[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// New returns an empty deque with room for at least hint items before
+// it grows.
+func New[T any](hint int) *Deque[T] {
+	d := &Deque[T]{}
+	if hint > 0 {
+		size := minCapacity
+		for size < hint {
+			size *= 2
+		}
+		d.items = make([]T, size)
+	}
+
+	return d
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deque
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmpty(t *testing.T) {
+	var d Deque[int]
+	assert.Equal(t, 0, d.Len())
+
+	_, ok := d.PopFront()
+	assert.False(t, ok)
+	_, ok = d.PopBack()
+	assert.False(t, ok)
+	_, ok = d.Front()
+	assert.False(t, ok)
+	_, ok = d.Back()
+	assert.False(t, ok)
+	_, ok = d.At(0)
+	assert.False(t, ok)
+	assert.False(t, d.Set(0, 1))
+}
+
+func TestQueue(t *testing.T) {
+	d := New[int](0)
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+	}
+
+	assert.Equal(t, 100, d.Len())
+	for i := 0; i < 100; i++ {
+		item, ok := d.PopFront()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	assert.Equal(t, 0, d.Len())
+}
+
+func TestStack(t *testing.T) {
+	d := New[int](0)
+	for i := 0; i < 100; i++ {
+		d.PushFront(i)
+	}
+
+	for i := 99; i >= 0; i-- {
+		item, ok := d.PopFront()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+	}
+
+	for i := 99; i >= 0; i-- {
+		item, ok := d.PopBack()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+}
+
+func TestFrontBack(t *testing.T) {
+	d := New[string](0)
+	d.PushBack(`b`)
+	d.PushFront(`a`)
+	d.PushBack(`c`)
+
+	front, ok := d.Front()
+	assert.True(t, ok)
+	assert.Equal(t, `a`, front)
+	back, ok := d.Back()
+	assert.True(t, ok)
+	assert.Equal(t, `c`, back)
+	assert.Equal(t, 3, d.Len())
+}
+
+func TestAtSet(t *testing.T) {
+	d := New[int](0)
+	// push from both ends so the items wrap around the ring
+	for i := 0; i < 10; i++ {
+		d.PushFront(-i - 1)
+		d.PushBack(i)
+	}
+
+	for i := 0; i < 20; i++ {
+		item, ok := d.At(i)
+		assert.True(t, ok)
+		assert.Equal(t, i-10, item)
+	}
+	_, ok := d.At(-1)
+	assert.False(t, ok)
+	_, ok = d.At(20)
+	assert.False(t, ok)
+
+	assert.True(t, d.Set(0, 100))
+	assert.True(t, d.Set(19, 200))
+	assert.False(t, d.Set(20, 300))
+	front, _ := d.Front()
+	back, _ := d.Back()
+	assert.Equal(t, 100, front)
+	assert.Equal(t, 200, back)
+}
+
+func TestGrowShrink(t *testing.T) {
+	d := New[int](0)
+	assert.Equal(t, 0, d.Cap())
+
+	for i := 0; i < 1000; i++ {
+		d.PushBack(i)
+	}
+	assert.Equal(t, 1024, d.Cap())
+
+	for i := 0; i < 990; i++ {
+		d.PopFront()
+	}
+	assert.Equal(t, 32, d.Cap())
+	assert.Equal(t, []int{990, 991, 992, 993, 994, 995, 996, 997, 998, 999},
+		slices.Collect(d.All()))
+}
+
+func TestNewHint(t *testing.T) {
+	d := New[int](100)
+	assert.Equal(t, 128, d.Cap())
+
+	for i := 0; i < 128; i++ {
+		d.PushBack(i)
+	}
+	assert.Equal(t, 128, d.Cap())
+}
+
+func TestClear(t *testing.T) {
+	d := New[int](0)
+	for i := 0; i < 100; i++ {
+		d.PushBack(i)
+	}
+
+	d.Clear()
+	assert.Equal(t, 0, d.Len())
+	assert.Equal(t, 0, d.Cap())
+
+	d.PushFront(1)
+	item, ok := d.PopBack()
+	assert.True(t, ok)
+	assert.Equal(t, 1, item)
+}
+
+func TestIterators(t *testing.T) {
+	d := New[int](0)
+	for i := 0; i < 5; i++ {
+		d.PushFront(i)
+	}
+
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, slices.Collect(d.All()))
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, slices.Collect(d.Backward()))
+
+	var items []int
+	for item := range d.All() {
+		if item == 2 {
+			break
+		}
+		items = append(items, item)
+	}
+	assert.Equal(t, []int{4, 3}, items)
+}
+
+func TestPopReleasesItems(t *testing.T) {
+	d := New[*int](0)
+	x := 1
+	d.PushBack(&x)
+	d.PushFront(&x)
+	d.PopBack()
+	d.PopFront()
+
+	for _, item := range d.items {
+		assert.Nil(t, item)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	d := New[int](0)
+	var model []int
+	for i := 0; i < 10000; i++ {
+		switch r.Intn(5) {
+		case 0:
+			d.PushFront(i)
+			model = slices.Insert(model, 0, i)
+		case 1:
+			d.PushBack(i)
+			model = append(model, i)
+		case 2:
+			item, ok := d.PopFront()
+			assert.Equal(t, len(model) > 0, ok)
+			if ok {
+				assert.Equal(t, model[0], item)
+				model = model[1:]
+			}
+		case 3:
+			item, ok := d.PopBack()
+			assert.Equal(t, len(model) > 0, ok)
+			if ok {
+				assert.Equal(t, model[len(model)-1], item)
+				model = model[:len(model)-1]
+			}
+		case 4:
+			if len(model) > 0 {
+				j := r.Intn(len(model))
+				item, ok := d.At(j)
+				assert.True(t, ok)
+				assert.Equal(t, model[j], item)
+			}
+		}
+
+		if !assert.Equal(t, len(model), d.Len()) {
+			return
+		}
+	}
+
+	assert.Equal(t, model, slices.Collect(d.All()))
+}
+
+func BenchmarkPushPopBack(b *testing.B) {
+	d := New[int](0)
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.PopBack()
+	}
+}
+
+func BenchmarkPushBackPopFront(b *testing.B) {
+	d := New[int](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		if d.Len() > 100 {
+			d.PopFront()
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package dsu implements a disjoint set union, also known as union-find,
+over the integers [0, n).  Every element starts in a set of its own and
+Union merges the sets of two elements.  Sets are joined by rank, and
+Find compresses the paths it walks, so any sequence of operations runs
+in near constant amortized time per operation.
+
+A DSU created with NewRollback instead records every successful Union
+so that the structure can be rolled back to an earlier Checkpoint.
+Path compression cannot be undone cheaply, so it is disabled in that
+mode and Find runs in O(log n).  This is the usual building block for
+offline dynamic connectivity and similar divide and conquer
+algorithms.
+
+This structure is not threadsafe.
+
+Performance characteristics:
+Find: O(α(n)) amortized, O(log n) with rollback
+Union: O(α(n)) amortized, O(log n) with rollback
+Connected: O(α(n)) amortized, O(log n) with rollback
+SetCount: O(1)
+Rollback: O(k) where k is the number of unions undone
+Space: O(n)
+*/
+package dsu
+
+// merge records a successful union so that it can be undone.
+type merge struct {
+	child, parent int
+	promoted      bool
+}
+
+// DSU is a disjoint set union over the integers [0, n).
+type DSU struct {
+	parent   []int
+	rank     []uint8
+	size     []int
+	count    int
+	rollback bool
+	history  []merge
+}
+
+// Find returns the representative of the set holding x.  Two elements
+// are in the same set exactly when they have the same representative.
+func (dsu *DSU) Find(x int) int {
+	root := x
+	for dsu.parent[root] != root {
+		root = dsu.parent[root]
+	}
+
+	if dsu.rollback {
+		return root
+	}
+
+	for dsu.parent[x] != root {
+		next := dsu.parent[x]
+		dsu.parent[x] = root
+		x = next
+	}
+
+	return root
+}
+
+// Union merges the sets holding x and y.  It returns false if they
+// were already in the same set.
+func (dsu *DSU) Union(x, y int) bool {
+	x, y = dsu.Find(x), dsu.Find(y)
+	if x == y {
+		return false
+	}
+
+	if dsu.rank[x] < dsu.rank[y] {
+		x, y = y, x
+	}
+
+	promoted := dsu.rank[x] == dsu.rank[y]
+	dsu.parent[y] = x
+	dsu.size[x] += dsu.size[y]
+	if promoted {
+		dsu.rank[x]++
+	}
+	dsu.count--
+
+	if dsu.rollback {
+		dsu.history = append(dsu.history, merge{child: y, parent: x, promoted: promoted})
+	}
+	return true
+}
+
+// Connected returns a bool indicating if x and y are in the same set.
+func (dsu *DSU) Connected(x, y int) bool {
+	return dsu.Find(x) == dsu.Find(y)
+}
+
+// Size returns the number of elements in the set holding x.
+func (dsu *DSU) Size(x int) int {
+	return dsu.size[dsu.Find(x)]
+}
+
+// SetCount returns the number of disjoint sets.
+func (dsu *DSU) SetCount() int {
+	return dsu.count
+}
+
+// Len returns the number of elements.
+func (dsu *DSU) Len() int {
+	return len(dsu.parent)
+}
+
+// Checkpoint returns a marker for the current state that can later be
+// passed to Rollback.  Panics if this DSU was not created with
+// NewRollback.
+func (dsu *DSU) Checkpoint() int {
+	if !dsu.rollback {
+		panic(`Checkpoint requires a DSU created with NewRollback.`)
+	}
+
+	return len(dsu.history)
+}
+
+// Rollback undoes every union made since the provided checkpoint was
+// taken.  Checkpoints taken after that one are invalidated.  Panics
+// if this DSU was not created with NewRollback or if the checkpoint
+// has already been rolled back past.
+func (dsu *DSU) Rollback(checkpoint int) {
+	if !dsu.rollback {
+		panic(`Rollback requires a DSU created with NewRollback.`)
+	}
+	if checkpoint < 0 || checkpoint > len(dsu.history) {
+		panic(`Invalid checkpoint provided.`)
+	}
+
+	for i := len(dsu.history) - 1; i >= checkpoint; i-- {
+		m := dsu.history[i]
+		dsu.parent[m.child] = m.child
+		dsu.size[m.parent] -= dsu.size[m.child]
+		if m.promoted {
+			dsu.rank[m.parent]--
+		}
+		dsu.count++
+	}
+	dsu.history = dsu.history[:checkpoint]
+}
+
+func newDSU(n int, rollback bool) *DSU {
+	if n < 0 {
+		panic(`Invalid size provided.`)
+	}
+
+	dsu := &DSU{
+		parent:   make([]int, n),
+		rank:     make([]uint8, n),
+		size:     make([]int, n),
+		count:    n,
+		rollback: rollback,
+	}
+	for i := range dsu.parent {
+		dsu.parent[i] = i
+		dsu.size[i] = 1
+	}
+
+	return dsu
+}
+
+// New returns a DSU holding the elements [0, n), each in a set of its
+// own.  Panics if n is negative.
+func New(n int) *DSU {
+	return newDSU(n, false)
+}
+
+// NewRollback returns a DSU like New that can be rolled back to any
+// checkpoint.  Find does not compress paths in this mode.
+func NewRollback(n int) *DSU {
+	return newDSU(n, true)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsu
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naive is a reference implementation that labels every element with
+// its set.
+type naive []int
+
+func newNaive(n int) naive {
+	labels := make(naive, n)
+	for i := range labels {
+		labels[i] = i
+	}
+	return labels
+}
+
+func (labels naive) union(x, y int) {
+	from, to := labels[y], labels[x]
+	for i, label := range labels {
+		if label == from {
+			labels[i] = to
+		}
+	}
+}
+
+func (labels naive) count() int {
+	seen := make(map[int]struct{})
+	for _, label := range labels {
+		seen[label] = struct{}{}
+	}
+	return len(seen)
+}
+
+func checkDSU(t *testing.T, dsu *DSU, labels naive) {
+	assert.Equal(t, labels.count(), dsu.SetCount())
+	for x := range labels {
+		size := 0
+		for y := range labels {
+			connected := labels[x] == labels[y]
+			assert.Equal(t, connected, dsu.Connected(x, y))
+			if connected {
+				size++
+			}
+		}
+		assert.Equal(t, size, dsu.Size(x))
+	}
+}
+
+func TestNew(t *testing.T) {
+	dsu := New(5)
+	assert.Equal(t, 5, dsu.Len())
+	assert.Equal(t, 5, dsu.SetCount())
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, i, dsu.Find(i))
+		assert.Equal(t, 1, dsu.Size(i))
+	}
+
+	assert.Equal(t, 0, New(0).SetCount())
+	assert.Panics(t, func() { New(-1) })
+}
+
+func TestUnion(t *testing.T) {
+	dsu := New(6)
+
+	assert.True(t, dsu.Union(0, 1))
+	assert.True(t, dsu.Union(2, 3))
+	assert.False(t, dsu.Union(1, 0))
+	assert.Equal(t, 4, dsu.SetCount())
+	assert.True(t, dsu.Connected(0, 1))
+	assert.False(t, dsu.Connected(1, 2))
+
+	assert.True(t, dsu.Union(1, 3))
+	assert.True(t, dsu.Connected(0, 2))
+	assert.Equal(t, 4, dsu.Size(3))
+	assert.Equal(t, 3, dsu.SetCount())
+	assert.False(t, dsu.Connected(4, 5))
+}
+
+func TestPathCompression(t *testing.T) {
+	dsu := New(1000)
+	for i := 1; i < 1000; i++ {
+		dsu.Union(i-1, i)
+	}
+
+	root := dsu.Find(0)
+	for i := 0; i < 1000; i++ {
+		dsu.Find(i)
+		assert.Equal(t, root, dsu.parent[i])
+	}
+	assert.Equal(t, 1, dsu.SetCount())
+	assert.Equal(t, 1000, dsu.Size(500))
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, dsu := range []*DSU{New(40), NewRollback(40)} {
+		labels := newNaive(40)
+		for i := 0; i < 60; i++ {
+			x, y := r.Intn(40), r.Intn(40)
+			assert.Equal(t, labels[x] != labels[y], dsu.Union(x, y))
+			labels.union(x, y)
+		}
+		checkDSU(t, dsu, labels)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	dsu := NewRollback(6)
+	dsu.Union(0, 1)
+
+	checkpoint := dsu.Checkpoint()
+	dsu.Union(2, 3)
+	dsu.Union(1, 3)
+	assert.False(t, dsu.Union(0, 2))
+	assert.True(t, dsu.Connected(0, 3))
+	assert.Equal(t, 3, dsu.SetCount())
+
+	dsu.Rollback(checkpoint)
+	assert.Equal(t, 5, dsu.SetCount())
+	assert.True(t, dsu.Connected(0, 1))
+	assert.False(t, dsu.Connected(0, 3))
+	assert.False(t, dsu.Connected(2, 3))
+	assert.Equal(t, 2, dsu.Size(1))
+	assert.Equal(t, 1, dsu.Size(2))
+
+	dsu.Rollback(0)
+	assert.Equal(t, 6, dsu.SetCount())
+	assert.False(t, dsu.Connected(0, 1))
+}
+
+func TestRollbackNested(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+	dsu := NewRollback(30)
+	labels := newNaive(30)
+
+	checkpoints := []int{}
+	states := []naive{}
+	for round := 0; round < 5; round++ {
+		checkpoints = append(checkpoints, dsu.Checkpoint())
+		states = append(states, append(naive(nil), labels...))
+		for i := 0; i < 8; i++ {
+			x, y := r.Intn(30), r.Intn(30)
+			dsu.Union(x, y)
+			labels.union(x, y)
+		}
+	}
+	checkDSU(t, dsu, labels)
+
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		dsu.Rollback(checkpoints[i])
+		checkDSU(t, dsu, states[i])
+		for x := range dsu.rank {
+			assert.True(t, dsu.parent[x] != x || dsu.size[x] >= 1<<dsu.rank[x])
+		}
+	}
+}
+
+func TestRollbackPanics(t *testing.T) {
+	dsu := New(3)
+	assert.Panics(t, func() { dsu.Checkpoint() })
+	assert.Panics(t, func() { dsu.Rollback(0) })
+
+	dsu = NewRollback(3)
+	dsu.Union(0, 1)
+	assert.Panics(t, func() { dsu.Rollback(2) })
+	assert.Panics(t, func() { dsu.Rollback(-1) })
+}
+
+func BenchmarkUnionFind(b *testing.B) {
+	numItems := 100000
+	r := rand.New(rand.NewSource(1))
+	pairs := make([][2]int, numItems)
+	for i := range pairs {
+		pairs[i] = [2]int{r.Intn(numItems), r.Intn(numItems)}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dsu := New(numItems)
+		for _, pair := range pairs {
+			dsu.Union(pair[0], pair[1])
+		}
+		for _, pair := range pairs {
+			dsu.Connected(pair[0], pair[1])
+		}
+	}
+}
+
+func BenchmarkRollback(b *testing.B) {
+	numItems := 100000
+	r := rand.New(rand.NewSource(1))
+	pairs := make([][2]int, numItems)
+	for i := range pairs {
+		pairs[i] = [2]int{r.Intn(numItems), r.Intn(numItems)}
+	}
+	dsu := NewRollback(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		checkpoint := dsu.Checkpoint()
+		for _, pair := range pairs {
+			dsu.Union(pair[0], pair[1])
+		}
+		dsu.Rollback(checkpoint)
+	}
+}
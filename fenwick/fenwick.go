@@ -0,0 +1,183 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package fenwick implements Fenwick trees, also known as binary indexed
+trees, which hold a number at every position of a range and answer
+prefix sums while values change.  Slot i of the tree holds the sum of
+a run of values ending at position i whose length is the lowest set
+bit of i, so both an update and a prefix sum touch one slot per bit of
+the position, O(log n) in all.  Next to a segment tree a Fenwick tree
+is smaller and faster but can only sum, and is the usual tool for
+counting and rank problems such as counting inversions or finding the
+k-th member of a set of small integers.
+
+Tree adds to single positions and sums ranges.  Range instead adds to
+whole ranges at once, keeping two trees so that sums stay O(log n).
+Tree2D adds to single cells of a grid and sums rectangles.
+
+Ranges are half open, so [l, r) covers l through r-1, and positions
+outside a tree are ignored.  None of the trees are threadsafe.
+
+Performance characteristics:
+Add/Set/Get: O(log n), O(log r log c) for Tree2D
+PrefixSum/Sum: O(log n), O(log r log c) for Tree2D
+Search: O(log n)
+NewFromSlice: O(n)
+Space: O(n), O(rc) for Tree2D
+*/
+package fenwick
+
+// Number is the set of types a Fenwick tree can hold.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+func lowbit(i int) int {
+	return i & -i
+}
+
+// Tree is a Fenwick tree over the positions [0, n).
+type Tree[T Number] struct {
+	// tree is one based, so tree[i] holds the sum of the values at
+	// the positions [i-lowbit(i), i).
+	tree []T
+}
+
+// Len returns the number of positions in the tree.
+func (t *Tree[T]) Len() int {
+	return len(t.tree) - 1
+}
+
+// Add adds delta to the value at position i.  Positions outside the
+// tree are ignored.
+func (t *Tree[T]) Add(i int, delta T) {
+	if i < 0 {
+		return
+	}
+
+	for i++; i < len(t.tree); i += lowbit(i) {
+		t.tree[i] += delta
+	}
+}
+
+// Get returns the value at position i, or zero if i is out of bounds.
+func (t *Tree[T]) Get(i int) T {
+	if i < 0 || i >= t.Len() {
+		return 0
+	}
+
+	// the slot for i holds the value less the sums of the runs that
+	// make up the rest of its own run
+	i++
+	v := t.tree[i]
+	for j, stop := i-1, i-lowbit(i); j > stop; j -= lowbit(j) {
+		v -= t.tree[j]
+	}
+
+	return v
+}
+
+// Set sets the value at position i.  Positions outside the tree are
+// ignored.
+func (t *Tree[T]) Set(i int, v T) {
+	t.Add(i, v-t.Get(i))
+}
+
+// PrefixSum returns the sum of the values in [0, i).
+func (t *Tree[T]) PrefixSum(i int) T {
+	var sum T
+	for i = min(i, t.Len()); i > 0; i -= lowbit(i) {
+		sum += t.tree[i]
+	}
+
+	return sum
+}
+
+// Sum returns the sum of the values in [l, r), which is zero if the
+// range is empty.
+func (t *Tree[T]) Sum(l, r int) T {
+	l, r = max(l, 0), min(r, t.Len())
+	if l >= r {
+		return 0
+	}
+
+	return t.PrefixSum(r) - t.PrefixSum(l)
+}
+
+// Search returns the first position i at which PrefixSum(i+1) exceeds
+// target, or Len if there is none.  Every value must be non-negative.
+// Laying the values end to end, this is the position covering target,
+// which makes Search the basis of weighted sampling, and with values
+// of one marking the members of a set, Search(k-1) is the position of
+// its k-th member.
+func (t *Tree[T]) Search(target T) int {
+	n := t.Len()
+	step := 1
+	for step*2 <= n {
+		step *= 2
+	}
+
+	i := 0
+	for ; step > 0; step /= 2 {
+		if i+step <= n && t.tree[i+step] <= target {
+			i += step
+			target -= t.tree[i]
+		}
+	}
+
+	return i
+}
+
+// Append adds a position holding v to the end of the tree.
+func (t *Tree[T]) Append(v T) {
+	i := len(t.tree)
+	for j, stop := i-1, i-lowbit(i); j > stop; j -= lowbit(j) {
+		v += t.tree[j]
+	}
+
+	t.tree = append(t.tree, v)
+}
+
+// Truncate removes every position from n on.  This is an O(1)
+// operation.
+func (t *Tree[T]) Truncate(n int) {
+	if n >= 0 && n < t.Len() {
+		t.tree = t.tree[:n+1]
+	}
+}
+
+// New returns a Fenwick tree over the positions [0, n), each holding
+// zero.
+func New[T Number](n int) *Tree[T] {
+	return &Tree[T]{tree: make([]T, max(n, 0)+1)}
+}
+
+// NewFromSlice returns a Fenwick tree holding the provided values at
+// the positions [0, len(values)).  This is an O(n) operation.
+func NewFromSlice[T Number](values []T) *Tree[T] {
+	t := &Tree[T]{tree: make([]T, len(values)+1)}
+	copy(t.tree[1:], values)
+	for i := 1; i < len(t.tree); i++ {
+		if parent := i + lowbit(i); parent < len(t.tree) {
+			t.tree[parent] += t.tree[i]
+		}
+	}
+
+	return t
+}
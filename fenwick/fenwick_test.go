@@ -0,0 +1,158 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fenwick
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveSum is a reference implementation that scans every position.
+func naiveSum(values []int, l, r int) int {
+	sum := 0
+	for _, v := range values[l:r] {
+		sum += v
+	}
+
+	return sum
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := New[int](0)
+	assert.Equal(t, 0, tree.Len())
+
+	tree.Add(0, 5)
+	tree.Set(0, 5)
+	assert.Equal(t, 0, tree.Get(0))
+	assert.Equal(t, 0, tree.PrefixSum(10))
+	assert.Equal(t, 0, tree.Sum(0, 10))
+	assert.Equal(t, 0, tree.Search(3))
+}
+
+func TestTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]int, 100)
+	for i := range values {
+		values[i] = r.Intn(100) - 50
+	}
+	tree := NewFromSlice(values)
+
+	for n := 0; n < 2000; n++ {
+		i := r.Intn(len(values))
+		v := r.Intn(100) - 50
+		switch r.Intn(3) {
+		case 0:
+			tree.Add(i, v)
+			values[i] += v
+		case 1:
+			tree.Set(i, v)
+			values[i] = v
+		case 2:
+			assert.Equal(t, values[i], tree.Get(i))
+		}
+
+		l, h := r.Intn(len(values)+1), r.Intn(len(values)+1)
+		l, h = min(l, h), max(l, h)
+		assert.Equal(t, naiveSum(values, l, h), tree.Sum(l, h))
+		assert.Equal(t, naiveSum(values, 0, h), tree.PrefixSum(h))
+	}
+}
+
+func TestOutOfBounds(t *testing.T) {
+	tree := NewFromSlice([]int{1, 2, 3})
+	tree.Add(-1, 10)
+	tree.Add(3, 10)
+	tree.Set(5, 10)
+
+	assert.Equal(t, 0, tree.Get(-1))
+	assert.Equal(t, 0, tree.Get(3))
+	assert.Equal(t, 6, tree.Sum(-5, 10))
+	assert.Equal(t, 0, tree.Sum(2, 1))
+	assert.Equal(t, 6, tree.PrefixSum(10))
+	assert.Equal(t, 0, tree.PrefixSum(-1))
+}
+
+func TestSearch(t *testing.T) {
+	tree := NewFromSlice([]float64{1, 0, 2.5, 0, 0, 1.5})
+
+	assert.Equal(t, 0, tree.Search(0))
+	assert.Equal(t, 0, tree.Search(.99))
+	assert.Equal(t, 2, tree.Search(1))
+	assert.Equal(t, 2, tree.Search(3.49))
+	assert.Equal(t, 5, tree.Search(3.5))
+	assert.Equal(t, 5, tree.Search(4.99))
+	assert.Equal(t, 6, tree.Search(5))
+}
+
+func TestSearchRank(t *testing.T) {
+	members := []int{3, 17, 18, 40, 63}
+	tree := New[int](64)
+	for _, m := range members {
+		tree.Add(m, 1)
+	}
+
+	for k, m := range members {
+		assert.Equal(t, m, tree.Search(k))
+	}
+	assert.Equal(t, 64, tree.Search(len(members)))
+}
+
+func TestAppendTruncate(t *testing.T) {
+	var values []int
+	tree := New[int](0)
+	for i := 0; i < 50; i++ {
+		values = append(values, i*3-20)
+		tree.Append(i*3 - 20)
+		assert.Equal(t, len(values), tree.Len())
+		for j := 0; j <= len(values); j++ {
+			assert.Equal(t, naiveSum(values, 0, j), tree.PrefixSum(j))
+		}
+	}
+
+	tree.Truncate(60)
+	assert.Equal(t, 50, tree.Len())
+	tree.Truncate(13)
+	values = values[:13]
+	assert.Equal(t, 13, tree.Len())
+	tree.Append(7)
+	values = append(values, 7)
+	for j := 0; j <= len(values); j++ {
+		assert.Equal(t, naiveSum(values, 0, j), tree.PrefixSum(j))
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	numItems := 1 << 16
+	tree := New[int](numItems)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Add(i%numItems, i)
+	}
+}
+
+func BenchmarkPrefixSum(b *testing.B) {
+	numItems := 1 << 16
+	tree := New[int](numItems)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.PrefixSum(i % numItems)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fenwick
+
+// Range is a Fenwick tree over the positions [0, n) that adds to whole
+// ranges of positions at once.
+//
+// Adding d to the positions from p on is recorded as d at p in deltas
+// and as d*p at p in weighted.  The sum of [0, i) then counts each
+// such d once for every position in [p, i), which is
+// i*deltas.PrefixSum(i) - weighted.PrefixSum(i).
+type Range[T Number] struct {
+	deltas, weighted *Tree[T]
+}
+
+// Len returns the number of positions in the tree.
+func (rt *Range[T]) Len() int {
+	return rt.deltas.Len()
+}
+
+// addFrom adds v to every position from i on.
+func (rt *Range[T]) addFrom(i int, v T) {
+	rt.deltas.Add(i, v)
+	rt.weighted.Add(i, v*T(i))
+}
+
+// Add adds v to every position in [l, r).  Positions outside the tree
+// are ignored.
+func (rt *Range[T]) Add(l, r int, v T) {
+	l, r = max(l, 0), min(r, rt.Len())
+	if l >= r {
+		return
+	}
+
+	rt.addFrom(l, v)
+	rt.addFrom(r, -v)
+}
+
+// PrefixSum returns the sum of the values in [0, i).
+func (rt *Range[T]) PrefixSum(i int) T {
+	i = max(min(i, rt.Len()), 0)
+	return T(i)*rt.deltas.PrefixSum(i) - rt.weighted.PrefixSum(i)
+}
+
+// Sum returns the sum of the values in [l, r), which is zero if the
+// range is empty.
+func (rt *Range[T]) Sum(l, r int) T {
+	l, r = max(l, 0), min(r, rt.Len())
+	if l >= r {
+		return 0
+	}
+
+	return rt.PrefixSum(r) - rt.PrefixSum(l)
+}
+
+// Get returns the value at position i, or zero if i is out of bounds.
+func (rt *Range[T]) Get(i int) T {
+	if i < 0 || i >= rt.Len() {
+		return 0
+	}
+
+	return rt.deltas.PrefixSum(i + 1)
+}
+
+// Set sets the value at position i.  Positions outside the tree are
+// ignored.
+func (rt *Range[T]) Set(i int, v T) {
+	rt.Add(i, i+1, v-rt.Get(i))
+}
+
+// NewRange returns a range Fenwick tree over the positions [0, n),
+// each holding zero.
+func NewRange[T Number](n int) *Range[T] {
+	return &Range[T]{deltas: New[T](n), weighted: New[T](n)}
+}
+
+// NewRangeFromSlice returns a range Fenwick tree holding the provided
+// values at the positions [0, len(values)).  This is an O(n)
+// operation.
+func NewRangeFromSlice[T Number](values []T) *Range[T] {
+	deltas := make([]T, len(values))
+	weighted := make([]T, len(values))
+	var last T
+	for i, v := range values {
+		deltas[i] = v - last
+		weighted[i] = deltas[i] * T(i)
+		last = v
+	}
+
+	return &Range[T]{
+		deltas:   NewFromSlice(deltas),
+		weighted: NewFromSlice(weighted),
+	}
+}
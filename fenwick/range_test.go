@@ -0,0 +1,84 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fenwick
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeEmpty(t *testing.T) {
+	rt := NewRange[int](0)
+	rt.Add(0, 10, 5)
+	rt.Set(0, 5)
+	assert.Equal(t, 0, rt.Len())
+	assert.Equal(t, 0, rt.Sum(0, 10))
+	assert.Equal(t, 0, rt.Get(0))
+}
+
+func TestRangeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]int, 100)
+	for i := range values {
+		values[i] = r.Intn(100) - 50
+	}
+	rt := NewRangeFromSlice(values)
+	assert.Equal(t, len(values), rt.Len())
+
+	for n := 0; n < 2000; n++ {
+		l, h := r.Intn(len(values)+1), r.Intn(len(values)+1)
+		l, h = min(l, h), max(l, h)
+		v := r.Intn(100) - 50
+		switch r.Intn(3) {
+		case 0:
+			rt.Add(l, h, v)
+			for i := l; i < h; i++ {
+				values[i] += v
+			}
+		case 1:
+			if l < len(values) {
+				rt.Set(l, v)
+				values[l] = v
+			}
+		case 2:
+			if l < len(values) {
+				assert.Equal(t, values[l], rt.Get(l))
+			}
+		}
+
+		l, h = r.Intn(len(values)+1), r.Intn(len(values)+1)
+		l, h = min(l, h), max(l, h)
+		assert.Equal(t, naiveSum(values, l, h), rt.Sum(l, h))
+		assert.Equal(t, naiveSum(values, 0, h), rt.PrefixSum(h))
+	}
+}
+
+func TestRangeOutOfBounds(t *testing.T) {
+	rt := NewRange[uint](5)
+	rt.Add(-3, 2, 4)
+	rt.Add(3, 10, 1)
+	rt.Add(4, 2, 100)
+
+	assert.Equal(t, []uint{4, 4, 0, 1, 1}, []uint{
+		rt.Get(0), rt.Get(1), rt.Get(2), rt.Get(3), rt.Get(4),
+	})
+	assert.Equal(t, uint(10), rt.Sum(-1, 20))
+	assert.Equal(t, uint(0), rt.Get(5))
+	assert.Equal(t, uint(0), rt.PrefixSum(-1))
+}
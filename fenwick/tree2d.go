@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fenwick
+
+// Tree2D is a Fenwick tree over the cells of a grid of rows by
+// columns.  It is a Fenwick tree of rows in which every slot is itself
+// a Fenwick tree of columns.
+type Tree2D[T Number] struct {
+	rows, cols int
+	// tree holds the one based slots of every row, (cols+1) to a
+	// row.
+	tree []T
+}
+
+// Rows returns the number of rows in the grid.
+func (t *Tree2D[T]) Rows() int {
+	return t.rows
+}
+
+// Cols returns the number of columns in the grid.
+func (t *Tree2D[T]) Cols() int {
+	return t.cols
+}
+
+func (t *Tree2D[T]) contains(r, c int) bool {
+	return r >= 0 && r < t.rows && c >= 0 && c < t.cols
+}
+
+// Add adds delta to the value in cell (r, c).  Cells outside the grid
+// are ignored.
+func (t *Tree2D[T]) Add(r, c int, delta T) {
+	if !t.contains(r, c) {
+		return
+	}
+
+	for i := r + 1; i <= t.rows; i += lowbit(i) {
+		row := t.tree[i*(t.cols+1):]
+		for j := c + 1; j <= t.cols; j += lowbit(j) {
+			row[j] += delta
+		}
+	}
+}
+
+// PrefixSum returns the sum of the values in the rows [0, r) and
+// columns [0, c).
+func (t *Tree2D[T]) PrefixSum(r, c int) T {
+	var sum T
+	c = min(c, t.cols)
+	for i := min(r, t.rows); i > 0; i -= lowbit(i) {
+		row := t.tree[i*(t.cols+1):]
+		for j := c; j > 0; j -= lowbit(j) {
+			sum += row[j]
+		}
+	}
+
+	return sum
+}
+
+// Sum returns the sum of the values in the rows [r1, r2) and columns
+// [c1, c2), which is zero if the rectangle is empty.
+func (t *Tree2D[T]) Sum(r1, c1, r2, c2 int) T {
+	r1, c1 = max(r1, 0), max(c1, 0)
+	r2, c2 = min(r2, t.rows), min(c2, t.cols)
+	if r1 >= r2 || c1 >= c2 {
+		return 0
+	}
+
+	return t.PrefixSum(r2, c2) - t.PrefixSum(r1, c2) -
+		t.PrefixSum(r2, c1) + t.PrefixSum(r1, c1)
+}
+
+// Get returns the value in cell (r, c), or zero if the cell is outside
+// the grid.
+func (t *Tree2D[T]) Get(r, c int) T {
+	return t.Sum(r, c, r+1, c+1)
+}
+
+// Set sets the value in cell (r, c).  Cells outside the grid are
+// ignored.
+func (t *Tree2D[T]) Set(r, c int, v T) {
+	t.Add(r, c, v-t.Get(r, c))
+}
+
+// New2D returns a Fenwick tree over a grid of rows by cols cells,
+// each holding zero.
+func New2D[T Number](rows, cols int) *Tree2D[T] {
+	rows, cols = max(rows, 0), max(cols, 0)
+	return &Tree2D[T]{
+		rows: rows,
+		cols: cols,
+		tree: make([]T, (rows+1)*(cols+1)),
+	}
+}
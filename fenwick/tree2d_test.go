@@ -0,0 +1,76 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fenwick
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree2DRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	rows, cols := 13, 20
+	grid := make([][]int, rows)
+	for i := range grid {
+		grid[i] = make([]int, cols)
+	}
+	tree := New2D[int](rows, cols)
+	assert.Equal(t, rows, tree.Rows())
+	assert.Equal(t, cols, tree.Cols())
+
+	for n := 0; n < 1000; n++ {
+		i, j := r.Intn(rows), r.Intn(cols)
+		v := r.Intn(100) - 50
+		if r.Intn(2) == 0 {
+			tree.Add(i, j, v)
+			grid[i][j] += v
+		} else {
+			tree.Set(i, j, v)
+			grid[i][j] = v
+		}
+		assert.Equal(t, grid[i][j], tree.Get(i, j))
+
+		r1, r2 := r.Intn(rows+1), r.Intn(rows+1)
+		c1, c2 := r.Intn(cols+1), r.Intn(cols+1)
+		r1, r2 = min(r1, r2), max(r1, r2)
+		c1, c2 = min(c1, c2), max(c1, c2)
+		expected := 0
+		for i := r1; i < r2; i++ {
+			expected += naiveSum(grid[i], c1, c2)
+		}
+		assert.Equal(t, expected, tree.Sum(r1, c1, r2, c2))
+	}
+}
+
+func TestTree2DOutOfBounds(t *testing.T) {
+	tree := New2D[float64](2, 3)
+	tree.Add(-1, 0, 1)
+	tree.Add(0, 3, 1)
+	tree.Add(2, 0, 1)
+	tree.Set(1, 2, 2.5)
+
+	assert.Equal(t, 2.5, tree.Sum(-5, -5, 10, 10))
+	assert.Equal(t, 2.5, tree.PrefixSum(10, 10))
+	assert.Equal(t, 0.0, tree.Sum(1, 2, 1, 3))
+	assert.Equal(t, 0.0, tree.Get(2, 2))
+
+	empty := New2D[int](0, 5)
+	empty.Add(0, 0, 1)
+	assert.Equal(t, 0, empty.Sum(0, 0, 5, 5))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fmindex
+
+import "math/bits"
+
+// bitVector is an immutable list of bits supporting constant time rank.
+type bitVector struct {
+	words []uint64
+	// ranks holds the number of set bits before each word.
+	ranks []uint64
+	size  uint64
+}
+
+func (bv *bitVector) get(i uint64) bool {
+	return bv.words[i/64]&(1<<(i%64)) != 0
+}
+
+// rank1 returns the number of set bits in [0, i).
+func (bv *bitVector) rank1(i uint64) uint64 {
+	word, offset := i/64, i%64
+	if offset == 0 {
+		return bv.ranks[word]
+	}
+
+	return bv.ranks[word] + uint64(bits.OnesCount64(bv.words[word]<<(64-offset)))
+}
+
+// rank0 returns the number of unset bits in [0, i).
+func (bv *bitVector) rank0(i uint64) uint64 {
+	return i - bv.rank1(i)
+}
+
+func newBitVector(size uint64, isSet func(uint64) bool) *bitVector {
+	numWords := size/64 + 1
+	bv := &bitVector{
+		words: make([]uint64, numWords),
+		ranks: make([]uint64, numWords+1),
+		size:  size,
+	}
+
+	for i := uint64(0); i < size; i++ {
+		if isSet(i) {
+			bv.words[i/64] |= 1 << (i % 64)
+		}
+	}
+
+	for i, word := range bv.words {
+		bv.ranks[i+1] = bv.ranks[i] + uint64(bits.OnesCount64(word))
+	}
+
+	return bv
+}
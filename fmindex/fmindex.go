@@ -0,0 +1,220 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package fmindex implements an FM-index, a compressed full-text index
+over a static byte corpus that can count and locate every occurrence
+of a pattern without scanning the corpus.
+
+The index stores the Burrows-Wheeler transform of the corpus in a
+wavelet tree, which answers the rank queries needed by backward search,
+along with every k-th entry of the suffix array to recover positions.
+The corpus itself is not retained.  A larger sample rate uses less
+memory at the expense of slower locates.
+
+Performance characteristics, where m is the length of the pattern,
+occ is the number of occurrences, and k is the sample rate:
+Build: O(n log^2 n)
+Count: O(m)
+Locate: O(m + occ*k)
+Space: O(n) bits plus n/k samples
+*/
+package fmindex
+
+import "sort"
+
+// suffixArray returns the suffix array of text by prefix doubling.
+// The empty suffix, at position len(text), sorts first.
+func suffixArray(text []byte) []uint64 {
+	n := len(text) + 1
+	sa := make([]uint64, n)
+	rank := make([]int, n)
+	tmp := make([]int, n)
+	for i := range sa {
+		sa[i] = uint64(i)
+		if i < len(text) {
+			rank[i] = int(text[i]) + 1
+		}
+	}
+
+	for k := 1; ; k *= 2 {
+		second := func(i uint64) int {
+			if int(i)+k < n {
+				return rank[int(i)+k]
+			}
+			return -1
+		}
+		less := func(a, b uint64) bool {
+			if rank[a] != rank[b] {
+				return rank[a] < rank[b]
+			}
+			return second(a) < second(b)
+		}
+		sort.Slice(sa, func(i, j int) bool { return less(sa[i], sa[j]) })
+
+		tmp[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			tmp[sa[i]] = tmp[sa[i-1]]
+			if less(sa[i-1], sa[i]) {
+				tmp[sa[i]]++
+			}
+		}
+		copy(rank, tmp)
+
+		if rank[sa[n-1]] == n-1 {
+			return sa
+		}
+	}
+}
+
+// Index is an FM-index over a static corpus.  It is immutable and
+// safe to query concurrently.
+type Index struct {
+	bwt *waveletTree
+	// c[b] is the number of rows whose suffix starts with a byte
+	// less than b, counting the empty suffix.
+	c [257]uint64
+	// primary is the row holding the end of text marker in the
+	// BWT, which is stored as a zero byte and must be discounted.
+	primary uint64
+	// sampled marks rows whose suffix array entry was kept in
+	// samples, in row order.
+	sampled    *bitVector
+	samples    []uint64
+	sampleRate uint64
+	number     uint64
+}
+
+// occ returns the number of times b occurs in the BWT before row i.
+func (idx *Index) occ(b byte, i uint64) uint64 {
+	count := idx.bwt.rank(b, i)
+	if b == 0 && i > idx.primary {
+		count-- // the end of text marker isn't a real zero byte
+	}
+
+	return count
+}
+
+// lf maps a row to the row of the suffix starting one byte earlier.
+func (idx *Index) lf(i uint64) uint64 {
+	b := idx.bwt.access(i)
+	return idx.c[b] + idx.occ(b, i)
+}
+
+// search returns the half open range of rows prefixed by pattern.
+func (idx *Index) search(pattern []byte) (uint64, uint64) {
+	sp, ep := uint64(0), idx.number+1
+	for i := len(pattern) - 1; i >= 0 && sp < ep; i-- {
+		b := pattern[i]
+		sp = idx.c[b] + idx.occ(b, sp)
+		ep = idx.c[b] + idx.occ(b, ep)
+	}
+
+	return sp, ep
+}
+
+// Count returns the number of times pattern occurs in the corpus,
+// including overlapping occurrences.  An empty pattern occurs nowhere.
+func (idx *Index) Count(pattern []byte) uint64 {
+	if len(pattern) == 0 {
+		return 0
+	}
+
+	sp, ep := idx.search(pattern)
+	if sp >= ep {
+		return 0
+	}
+
+	return ep - sp
+}
+
+// Locate returns the positions in the corpus at which pattern occurs
+// in ascending order.  An empty pattern occurs nowhere.
+func (idx *Index) Locate(pattern []byte) []uint64 {
+	if len(pattern) == 0 {
+		return []uint64{}
+	}
+
+	sp, ep := idx.search(pattern)
+	if sp >= ep {
+		return []uint64{}
+	}
+
+	positions := make([]uint64, 0, ep-sp)
+	for row := sp; row < ep; row++ {
+		var steps uint64
+		i := row
+		for !idx.sampled.get(i) {
+			i = idx.lf(i)
+			steps++
+		}
+		positions = append(positions, idx.samples[idx.sampled.rank1(i)]+steps)
+	}
+
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	return positions
+}
+
+// Len returns the length of the indexed corpus.
+func (idx *Index) Len() uint64 {
+	return idx.number
+}
+
+// New builds an index over text, keeping one in every sampleRate
+// suffix array entries to locate matches.  The text may be modified
+// or discarded once this returns.
+func New(text []byte, sampleRate uint64) *Index {
+	if sampleRate == 0 {
+		sampleRate = 1
+	}
+
+	sa := suffixArray(text)
+	idx := &Index{
+		sampleRate: sampleRate,
+		number:     uint64(len(text)),
+	}
+
+	bwt := make([]byte, len(sa))
+	for i, pos := range sa {
+		if pos == 0 {
+			idx.primary = uint64(i)
+			continue
+		}
+		bwt[i] = text[pos-1]
+	}
+	idx.bwt = newWaveletTree(bwt)
+
+	idx.c[0] = 1 // the empty suffix sorts before everything
+	var counts [256]uint64
+	for _, b := range text {
+		counts[b]++
+	}
+	for b := 0; b < 256; b++ {
+		idx.c[b+1] = idx.c[b] + counts[b]
+	}
+
+	idx.sampled = newBitVector(uint64(len(sa)), func(i uint64) bool {
+		return sa[i]%sampleRate == 0
+	})
+	idx.samples = make([]uint64, 0, idx.sampled.rank1(uint64(len(sa))))
+	for _, pos := range sa {
+		if pos%sampleRate == 0 {
+			idx.samples = append(idx.samples, pos)
+		}
+	}
+
+	return idx
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fmindex
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateText(num int, alphabet string) []byte {
+	text := make([]byte, num)
+	for i := range text {
+		text[i] = alphabet[rand.Intn(len(alphabet))]
+	}
+
+	return text
+}
+
+func bruteLocate(text, pattern []byte) []uint64 {
+	positions := []uint64{}
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if bytes.Equal(text[i:i+len(pattern)], pattern) {
+			positions = append(positions, uint64(i))
+		}
+	}
+
+	return positions
+}
+
+func TestSuffixArray(t *testing.T) {
+	assert.Equal(t, []uint64{6, 5, 3, 1, 0, 4, 2}, suffixArray([]byte(`banana`)))
+	assert.Equal(t, []uint64{0}, suffixArray(nil))
+	assert.Equal(t, []uint64{3, 2, 1, 0}, suffixArray([]byte(`aaa`)))
+}
+
+func TestCountAndLocate(t *testing.T) {
+	text := []byte(`abracadabra`)
+	idx := New(text, 3)
+
+	assert.Equal(t, uint64(11), idx.Len())
+	assert.Equal(t, uint64(2), idx.Count([]byte(`abra`)))
+	assert.Equal(t, []uint64{0, 7}, idx.Locate([]byte(`abra`)))
+	assert.Equal(t, uint64(5), idx.Count([]byte(`a`)))
+	assert.Equal(t, []uint64{0, 3, 5, 7, 10}, idx.Locate([]byte(`a`)))
+	assert.Equal(t, []uint64{4}, idx.Locate([]byte(`cad`)))
+
+	assert.Equal(t, uint64(0), idx.Count([]byte(`abrac!`)))
+	assert.Equal(t, []uint64{}, idx.Locate([]byte(`z`)))
+	assert.Equal(t, uint64(0), idx.Count(nil))
+	assert.Equal(t, []uint64{}, idx.Locate(nil))
+}
+
+func TestZeroBytes(t *testing.T) {
+	text := []byte{0, 1, 0, 0, 2, 0}
+	idx := New(text, 2)
+
+	assert.Equal(t, uint64(4), idx.Count([]byte{0}))
+	assert.Equal(t, []uint64{2}, idx.Locate([]byte{0, 0}))
+	assert.Equal(t, []uint64{4}, idx.Locate([]byte{2, 0}))
+	assert.Equal(t, []uint64{}, idx.Locate([]byte{0, 0, 0}))
+}
+
+func TestEmptyCorpus(t *testing.T) {
+	idx := New(nil, 4)
+	assert.Equal(t, uint64(0), idx.Len())
+	assert.Equal(t, uint64(0), idx.Count([]byte(`a`)))
+	assert.Equal(t, []uint64{}, idx.Locate([]byte(`a`)))
+}
+
+func TestRandomAgainstBruteForce(t *testing.T) {
+	for _, rate := range []uint64{1, 4, 32} {
+		text := generateText(2000, `acgt`)
+		idx := New(text, rate)
+		for i := 0; i < 100; i++ {
+			start := rand.Intn(len(text) - 8)
+			pattern := text[start : start+1+rand.Intn(8)]
+			expected := bruteLocate(text, pattern)
+
+			assert.Equal(t, uint64(len(expected)), idx.Count(pattern))
+			assert.Equal(t, expected, idx.Locate(pattern))
+		}
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	text := generateText(100000, `acgt`)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		New(text, 32)
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	text := generateText(100000, `acgt`)
+	idx := New(text, 32)
+	pattern := text[500:510]
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		idx.Count(pattern)
+	}
+}
+
+func BenchmarkLocate(b *testing.B) {
+	text := generateText(100000, `acgt`)
+	idx := New(text, 32)
+	pattern := text[500:510]
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		idx.Locate(pattern)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fmindex
+
+// levels is the number of bits in a symbol.
+const levels = 8
+
+// waveletTree answers rank queries for byte symbols in O(levels) time
+// using levels bit vectors.  It is laid out level by level, with every
+// node at a level concatenated, which is sometimes called a wavelet
+// matrix.
+type waveletTree struct {
+	bitVectors [levels]*bitVector
+	// zeros is the number of unset bits at each level, which is
+	// where the ones are moved to at the next level.
+	zeros [levels]uint64
+}
+
+func bitAt(b byte, level int) bool {
+	return b&(1<<uint(levels-1-level)) != 0
+}
+
+// access returns the symbol at position i.
+func (wt *waveletTree) access(i uint64) byte {
+	var b byte
+	for level := 0; level < levels; level++ {
+		bv := wt.bitVectors[level]
+		if bv.get(i) {
+			b |= 1 << uint(levels-1-level)
+			i = wt.zeros[level] + bv.rank1(i)
+		} else {
+			i = bv.rank0(i)
+		}
+	}
+
+	return b
+}
+
+// rank returns the number of times b occurs in [0, i).
+func (wt *waveletTree) rank(b byte, i uint64) uint64 {
+	var start uint64
+	for level := 0; level < levels; level++ {
+		bv := wt.bitVectors[level]
+		if bitAt(b, level) {
+			start = wt.zeros[level] + bv.rank1(start)
+			i = wt.zeros[level] + bv.rank1(i)
+		} else {
+			start = bv.rank0(start)
+			i = bv.rank0(i)
+		}
+	}
+
+	return i - start
+}
+
+func newWaveletTree(symbols []byte) *waveletTree {
+	wt := &waveletTree{}
+	current := make([]byte, len(symbols))
+	copy(current, symbols)
+	next := make([]byte, len(symbols))
+
+	for level := 0; level < levels; level++ {
+		wt.bitVectors[level] = newBitVector(uint64(len(current)), func(i uint64) bool {
+			return bitAt(current[i], level)
+		})
+
+		// stable partition so this level's zeros precede its ones
+		next = next[:0]
+		for _, b := range current {
+			if !bitAt(b, level) {
+				next = append(next, b)
+			}
+		}
+		wt.zeros[level] = uint64(len(next))
+		for _, b := range current {
+			if bitAt(b, level) {
+				next = append(next, b)
+			}
+		}
+
+		current, next = next, current
+	}
+
+	return wt
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fmindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitVectorRank(t *testing.T) {
+	bv := newBitVector(200, func(i uint64) bool { return i%3 == 0 })
+
+	for i := uint64(0); i <= 200; i++ {
+		assert.Equal(t, (i+2)/3, bv.rank1(i))
+		assert.Equal(t, i-(i+2)/3, bv.rank0(i))
+	}
+
+	assert.True(t, bv.get(63))
+	assert.False(t, bv.get(64))
+}
+
+func TestWaveletTree(t *testing.T) {
+	symbols := []byte(`mississippi river`)
+	wt := newWaveletTree(symbols)
+
+	for i, b := range symbols {
+		assert.Equal(t, b, wt.access(uint64(i)))
+	}
+
+	counts := make(map[byte]uint64)
+	for i := 0; i <= len(symbols); i++ {
+		for _, b := range []byte(`misp rv`) {
+			assert.Equal(t, counts[b], wt.rank(b, uint64(i)))
+		}
+		if i < len(symbols) {
+			counts[symbols[i]]++
+		}
+	}
+	assert.Equal(t, uint64(0), wt.rank('z', uint64(len(symbols))))
+}
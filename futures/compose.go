@@ -0,0 +1,128 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"errors"
+	"sync"
+)
+
+// waitFor waits for f to complete and returns true, or returns false
+// if derived, the future waiting on f, completes first, in which case
+// f's result is no longer needed.
+func waitFor(f, derived *Future) bool {
+	select {
+	case <-f.Done():
+		return true
+	case <-derived.Done():
+		return false
+	}
+}
+
+// Then returns a future completed with the result of calling fn
+// with this future's result.  If this future completes with an
+// error, fn is not called and the returned future gets the error.
+// Disposing the returned future stops it waiting on this one.
+func (f *Future) Then(fn func(interface{}) interface{}) *Future {
+	next := newFuture()
+	go func() {
+		if !waitFor(f, next) {
+			return
+		}
+		item, err := f.GetResult()
+		if err != nil {
+			next.setItem(nil, err)
+			return
+		}
+
+		next.setItem(fn(item), nil)
+	}()
+
+	return next
+}
+
+// All returns a future completed with a slice of the results of the
+// provided futures, in the order provided, once they have all
+// completed.  If any of them completes with an error, the returned
+// future gets that error without waiting on the rest.  Once the
+// returned future completes, including by being disposed, nothing is
+// left waiting on the provided futures.
+func All(futures ...*Future) *Future {
+	all := newFuture()
+	results := make([]interface{}, len(futures))
+	var wg sync.WaitGroup
+	wg.Add(len(futures))
+
+	for i, f := range futures {
+		go func(i int, f *Future) {
+			defer wg.Done()
+			if !waitFor(f, all) {
+				return
+			}
+			item, err := f.GetResult()
+			if err != nil {
+				all.setItem(nil, err)
+				return
+			}
+			results[i] = item
+		}(i, f)
+	}
+
+	go func() {
+		wg.Wait()
+		all.setItem(results, nil)
+	}()
+
+	return all
+}
+
+// Any returns a future completed with the result of the first of
+// the provided futures to complete without an error.  If they all
+// complete with errors, the returned future gets the last error.
+// Once the returned future completes, including by being disposed,
+// nothing is left waiting on the provided futures.
+func Any(futures ...*Future) *Future {
+	first := newFuture()
+	if len(futures) == 0 {
+		first.setItem(nil, errors.New(`No futures were provided.`))
+		return first
+	}
+
+	var lock sync.Mutex
+	remaining := len(futures)
+	for _, f := range futures {
+		go func(f *Future) {
+			if !waitFor(f, first) {
+				return
+			}
+			item, err := f.GetResult()
+			if err == nil {
+				first.setItem(item, nil)
+				return
+			}
+
+			lock.Lock()
+			remaining--
+			if remaining == 0 {
+				first.setItem(nil, err)
+			}
+			lock.Unlock()
+		}(f)
+	}
+
+	return first
+}
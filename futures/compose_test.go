@@ -0,0 +1,140 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThen(t *testing.T) {
+	p := NewPromise()
+	doubled := p.Future().Then(func(item interface{}) interface{} {
+		return item.(int) * 2
+	})
+	plusOne := doubled.Then(func(item interface{}) interface{} {
+		return item.(int) + 1
+	})
+
+	p.Resolve(5)
+	result, err := plusOne.GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, 11, result)
+}
+
+func TestThenPropagatesError(t *testing.T) {
+	p := NewPromise()
+	called := false
+	next := p.Future().Then(func(item interface{}) interface{} {
+		called = true
+		return item
+	})
+
+	testErr := errors.New(`test`)
+	p.Reject(testErr)
+	result, err := next.GetResult()
+	assert.Nil(t, result)
+	assert.Equal(t, testErr, err)
+	assert.False(t, called)
+}
+
+func TestAll(t *testing.T) {
+	promises := []*Promise{NewPromise(), NewPromise(), NewPromise()}
+	all := All(promises[0].Future(), promises[1].Future(), promises[2].Future())
+
+	promises[2].Resolve(`c`)
+	promises[0].Resolve(`a`)
+	promises[1].Resolve(`b`)
+
+	result, err := all.GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`, `b`, `c`}, result)
+
+	result, err = All().GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{}, result)
+}
+
+func TestAllFailsFast(t *testing.T) {
+	never := NewPromise()
+	failed := NewPromise()
+	all := All(never.Future(), failed.Future())
+
+	testErr := errors.New(`test`)
+	failed.Reject(testErr)
+
+	_, err := all.GetResult()
+	assert.Equal(t, testErr, err)
+}
+
+func TestAny(t *testing.T) {
+	never := NewPromise()
+	failed := NewPromise()
+	succeeded := NewPromise()
+	future := Any(never.Future(), failed.Future(), succeeded.Future())
+
+	failed.Reject(errors.New(`test`))
+	succeeded.Resolve(`ok`)
+
+	result, err := future.GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, `ok`, result)
+}
+
+func TestAnyAllFail(t *testing.T) {
+	first, second := NewPromise(), NewPromise()
+	future := Any(first.Future(), second.Future())
+
+	first.Reject(errors.New(`first`))
+	second.Reject(errors.New(`second`))
+
+	_, err := future.GetResult()
+	assert.NotNil(t, err)
+
+	_, err = Any().GetResult()
+	assert.NotNil(t, err)
+}
+
+func TestDisposeReleasesComposed(t *testing.T) {
+	before := runtime.NumGoroutine()
+	never := NewPromise()
+
+	next := never.Future().Then(func(item interface{}) interface{} {
+		return item
+	})
+	all := All(never.Future(), NewPromise().Future())
+	first := Any(never.Future(), NewPromise().Future())
+
+	next.Dispose()
+	all.Dispose()
+	first.Dispose()
+	checkGoroutines(t, before)
+}
+
+func TestAllFailureReleasesRest(t *testing.T) {
+	before := runtime.NumGoroutine()
+	never, failed := NewPromise(), NewPromise()
+	all := All(never.Future(), never.Future(), failed.Future())
+
+	failed.Reject(errors.New(`test`))
+	_, err := all.GetResult()
+	assert.NotNil(t, err)
+	checkGoroutines(t, before)
+}
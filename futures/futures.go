@@ -20,13 +20,20 @@ of listeners as opposed to channels which will choose a listener at random
 if multiple listeners are listening to the same channel.  The future will
 also cache the result so any future interest will be immediately returned
 to the consumer.
+
+A future that will never complete can be disposed, which completes it
+with common.ErrDisposed.  Every listener is released and the goroutine
+waiting on its completer, if any, exits.
 */
 package futures
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 // Completer is a channel that the future expects to receive
@@ -41,42 +48,132 @@ type Completer <-chan interface{}
 // of listeners.
 type Future struct {
 	triggered bool // because item can technically be nil and still be valid
+	disposed  bool
 	item      interface{}
 	err       error
 	lock      sync.Mutex
-	wg        sync.WaitGroup
+	// done is closed once the result is set.
+	done chan struct{}
+	// listeners are the channels handed out by AsChan before the
+	// result was set.
+	listeners []chan interface{}
 }
 
 // GetResult will immediately fetch the result if it exists
 // or wait on the result until it is ready.
 func (f *Future) GetResult() (interface{}, error) {
-	f.lock.Lock()
-	if f.triggered {
-		f.lock.Unlock()
+	<-f.done
+	return f.item, f.err
+}
+
+// GetResultCtx is like GetResult except that it stops waiting and
+// returns the context's error once the context is done.  The future
+// itself is unaffected and may still complete later.
+func (f *Future) GetResultCtx(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
 		return f.item, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	f.lock.Unlock()
+}
 
-	f.wg.Wait()
-	return f.item, f.err
+// Done returns a channel that is closed once the future has a
+// result, for use in select statements.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
 }
 
-func (f *Future) setItem(item interface{}, err error) {
+// AsChan returns a channel that receives this future's item once it
+// completes and is then closed.  If the future completes with an
+// error, the channel is closed without receiving anything and the
+// error can be fetched with GetResult.  Each call returns a new
+// buffered channel, so a reader that never receives doesn't block
+// the future, and no goroutine is started.
+func (f *Future) AsChan() <-chan interface{} {
+	ch := make(chan interface{}, 1)
+
 	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.triggered {
+		f.listeners = append(f.listeners, ch)
+		return ch
+	}
+
+	notify(ch, f.item, f.err)
+	return ch
+}
+
+func notify(ch chan interface{}, item interface{}, err error) {
+	if err == nil {
+		ch <- item
+	}
+	close(ch)
+}
+
+// setItem completes the future and returns a bool indicating if it
+// wasn't already complete.  Only the first result is kept.
+func (f *Future) setItem(item interface{}, err error) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.complete(item, err)
+}
+
+// complete is setItem without the lock, which must be held.
+func (f *Future) complete(item interface{}, err error) bool {
+	if f.triggered {
+		return false
+	}
+
 	f.triggered = true
 	f.item = item
 	f.err = err
-	f.lock.Unlock()
-	f.wg.Done()
+	close(f.done)
+	for _, ch := range f.listeners {
+		notify(ch, item, err)
+	}
+	f.listeners = nil
+	return true
+}
+
+// Dispose completes the future with common.ErrDisposed if it hasn't
+// already completed, releasing every listener.  A completed future is
+// unaffected.
+func (f *Future) Dispose() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.complete(nil, common.ErrDisposed) {
+		f.disposed = true
+	}
+}
+
+// Disposed returns a bool indicating if this future was completed by
+// Dispose.
+func (f *Future) Disposed() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.disposed
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
 }
 
 func listenForResult(f *Future, ch Completer, timeout time.Duration, wg *sync.WaitGroup) {
 	wg.Done()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
 	select {
 	case item := <-ch:
 		f.setItem(item, nil)
-	case <-time.After(timeout):
+	case <-timer.C:
 		f.setItem(nil, fmt.Errorf(`Timeout after %f seconds.`, timeout.Seconds()))
+	case <-f.done: // disposed
 	}
 }
 
@@ -85,11 +182,57 @@ func listenForResult(f *Future, ch Completer, timeout time.Duration, wg *sync.Wa
 // notified.  If timeout is hit before toComplete is called,
 // any listeners will get passed an error.
 func New(completer Completer, timeout time.Duration) *Future {
-	f := &Future{}
-	f.wg.Add(1)
+	f := newFuture()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go listenForResult(f, completer, timeout, &wg)
 	wg.Wait()
 	return f
 }
+
+// NewWithContext returns a future completed by the item passed to
+// completer, or with the context's error if the context is done
+// first.
+func NewWithContext(ctx context.Context, completer Completer) *Future {
+	f := newFuture()
+	go func() {
+		select {
+		case item := <-completer:
+			f.setItem(item, nil)
+		case <-ctx.Done():
+			f.setItem(nil, ctx.Err())
+		case <-f.done: // disposed
+		}
+	}()
+
+	return f
+}
+
+// Promise is the writable side of a future.  It completes its
+// future with either a result or an error, as opposed to a channel
+// which can only carry a result.
+type Promise struct {
+	future *Future
+}
+
+// Future returns the future completed by this promise.
+func (p *Promise) Future() *Future {
+	return p.future
+}
+
+// Resolve completes the future with the provided item.  Returns a
+// bool indicating if the future wasn't already complete.
+func (p *Promise) Resolve(item interface{}) bool {
+	return p.future.setItem(item, nil)
+}
+
+// Reject completes the future with the provided error.  Returns a
+// bool indicating if the future wasn't already complete.
+func (p *Promise) Reject(err error) bool {
+	return p.future.setItem(nil, err)
+}
+
+// NewPromise returns a promise whose future is not yet complete.
+func NewPromise() *Promise {
+	return &Promise{future: newFuture()}
+}
@@ -17,13 +17,33 @@ limitations under the License.
 package futures
 
 import (
+	"context"
+	"errors"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
+var _ common.Disposable = (*Future)(nil)
+
+// checkGoroutines fails the test if the number of goroutines doesn't
+// return to expected shortly.
+func checkGoroutines(t *testing.T, expected int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > expected {
+		if time.Now().After(deadline) {
+			t.Errorf(`Leaked %d goroutines.`, runtime.NumGoroutine()-expected)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestWaitOnGetResult(t *testing.T) {
 	completer := make(chan interface{})
 	f := New(completer, time.Duration(30*time.Minute))
@@ -78,3 +98,127 @@ func BenchmarkFuture(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+func TestPromise(t *testing.T) {
+	p := NewPromise()
+	select {
+	case <-p.Future().Done():
+		t.Errorf(`Future completed before its promise.`)
+	default:
+	}
+
+	assert.True(t, p.Resolve(`a`))
+	assert.False(t, p.Resolve(`b`))
+	assert.False(t, p.Reject(errors.New(`test`)))
+
+	result, err := p.Future().GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, `a`, result)
+
+	p = NewPromise()
+	testErr := errors.New(`test`)
+	assert.True(t, p.Reject(testErr))
+	_, err = p.Future().GetResult()
+	assert.Equal(t, testErr, err)
+}
+
+func TestGetResultCtx(t *testing.T) {
+	p := NewPromise()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.Future().GetResultCtx(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	p.Resolve(`a`)
+	result, err := p.Future().GetResultCtx(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, `a`, result)
+}
+
+func TestNewWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := NewWithContext(ctx, make(chan interface{}))
+	cancel()
+
+	_, err := f.GetResult()
+	assert.Equal(t, context.Canceled, err)
+
+	completer := make(chan interface{}, 1)
+	completer <- `a`
+	result, err := NewWithContext(context.Background(), completer).GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, `a`, result)
+}
+
+func TestAsChan(t *testing.T) {
+	p := NewPromise()
+	before := p.Future().AsChan()
+
+	p.Resolve(`a`)
+	after := p.Future().AsChan()
+
+	for _, ch := range []<-chan interface{}{before, after} {
+		item, ok := <-ch
+		assert.True(t, ok)
+		assert.Equal(t, `a`, item)
+		_, ok = <-ch
+		assert.False(t, ok)
+	}
+
+	p = NewPromise()
+	ch := p.Future().AsChan()
+	p.Reject(errors.New(`test`))
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestDispose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	f := New(make(chan interface{}), 30*time.Minute)
+	ch := f.AsChan()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	wg.Add(len(errs))
+	for i := range errs {
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = f.GetResult()
+		}(i)
+	}
+
+	f.Dispose()
+	f.Dispose()
+	wg.Wait()
+
+	assert.True(t, f.Disposed())
+	for _, err := range errs {
+		assert.Equal(t, common.ErrDisposed, err)
+	}
+	_, ok := <-ch
+	assert.False(t, ok)
+	checkGoroutines(t, before)
+}
+
+func TestDisposeCompleted(t *testing.T) {
+	p := NewPromise()
+	p.Resolve(`a`)
+	p.Future().Dispose()
+
+	assert.False(t, p.Future().Disposed())
+	result, err := p.Future().GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, `a`, result)
+	assert.False(t, p.Reject(errors.New(`test`)))
+}
+
+func TestDisposeNewWithContext(t *testing.T) {
+	before := runtime.NumGoroutine()
+	f := NewWithContext(context.Background(), make(chan interface{}))
+
+	f.Dispose()
+	_, err := f.GetResult()
+	assert.Equal(t, common.ErrDisposed, err)
+	checkGoroutines(t, before)
+}
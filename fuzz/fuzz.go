@@ -0,0 +1,277 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package fuzz holds the pieces shared by the fuzz targets in this
+library.  Ops decodes the bytes generated by go test -fuzz into a
+sequence of operations and their arguments, and Sorted and List are
+deliberately simple reference models, a sorted set and a positional
+list kept in plain slices, that a structure's results can be checked
+against after every operation:
+
+	func FuzzList(f *testing.F) {
+		f.Fuzz(func(t *testing.T, data []byte) {
+			ops, model := fuzz.NewOps(data), &fuzz.Sorted[uint64]{}
+			for op, ok := ops.Next(2); ok; op, ok = ops.Next(2) {
+				key := ops.Uint64n(256)
+				...
+			}
+		})
+	}
+
+Keys should be drawn from a small range so that operations frequently
+hit existing values.
+*/
+package fuzz
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Ops decodes a fuzzer's input into operations.  Each call consumes
+// bytes from the front of the input and once it is exhausted every
+// call returns zero, so any input is a valid, finite sequence.
+type Ops struct {
+	data []byte
+}
+
+// Next returns the next operation, which is in [0, n), and false once
+// the input is exhausted.
+func (o *Ops) Next(n int) (int, bool) {
+	if len(o.data) == 0 {
+		return 0, false
+	}
+
+	return o.Intn(n), true
+}
+
+// Intn returns an argument in [0, n), or zero if the input is
+// exhausted or n is not positive.
+func (o *Ops) Intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	return int(o.Uint64n(uint64(n)))
+}
+
+// Uint64n returns an argument in [0, n), or zero if the input is
+// exhausted or n is zero.  Only as many bytes as are needed to cover
+// n are consumed.
+func (o *Ops) Uint64n(n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+
+	var v uint64
+	for max := n - 1; max > 0 && len(o.data) > 0; max >>= 8 {
+		v = v<<8 | uint64(o.data[0])
+		o.data = o.data[1:]
+	}
+
+	return v % n
+}
+
+// Bool returns the next argument as a bool.
+func (o *Ops) Bool() bool {
+	return o.Uint64n(2) == 1
+}
+
+// Len returns the number of bytes of input left.
+func (o *Ops) Len() int {
+	return len(o.data)
+}
+
+// NewOps returns an Ops that decodes the provided input.
+func NewOps(data []byte) *Ops {
+	return &Ops{data: data}
+}
+
+// Sorted is a reference model of an ordered set.  Every operation is
+// O(n) or worse, which is fine for the small inputs a fuzzer produces.
+// The zero value is an empty set.
+type Sorted[T cmp.Ordered] struct {
+	values []T
+}
+
+// Insert adds v to the set and returns a bool indicating if it was
+// not already present.
+func (s *Sorted[T]) Insert(v T) bool {
+	i, found := slices.BinarySearch(s.values, v)
+	if found {
+		return false
+	}
+
+	s.values = slices.Insert(s.values, i, v)
+	return true
+}
+
+// Delete removes v from the set and returns a bool indicating if it
+// was present.
+func (s *Sorted[T]) Delete(v T) bool {
+	i, found := slices.BinarySearch(s.values, v)
+	if found {
+		s.values = slices.Delete(s.values, i, i+1)
+	}
+
+	return found
+}
+
+// Contains returns a bool indicating if v is in the set.
+func (s *Sorted[T]) Contains(v T) bool {
+	_, found := slices.BinarySearch(s.values, v)
+	return found
+}
+
+// Rank returns the number of values in the set less than v.
+func (s *Sorted[T]) Rank(v T) int {
+	i, _ := slices.BinarySearch(s.values, v)
+	return i
+}
+
+// At returns the value at position i in sorted order.
+func (s *Sorted[T]) At(i int) T {
+	return s.values[i]
+}
+
+// Floor returns the greatest value less than or equal to v and a bool
+// indicating if there is one.
+func (s *Sorted[T]) Floor(v T) (T, bool) {
+	i, found := slices.BinarySearch(s.values, v)
+	if found {
+		return s.values[i], true
+	}
+
+	if i == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return s.values[i-1], true
+}
+
+// Ceiling returns the least value greater than or equal to v and a
+// bool indicating if there is one.
+func (s *Sorted[T]) Ceiling(v T) (T, bool) {
+	i, _ := slices.BinarySearch(s.values, v)
+	if i == len(s.values) {
+		var zero T
+		return zero, false
+	}
+
+	return s.values[i], true
+}
+
+// Len returns the number of values in the set.
+func (s *Sorted[T]) Len() int {
+	return len(s.values)
+}
+
+// Values returns the values in the set in sorted order.  The returned
+// slice must not be modified.
+func (s *Sorted[T]) Values() []T {
+	return s.values
+}
+
+// List is a reference model of a list addressed by position, such as
+// a skiplist used through InsertAtPosition.  The zero value is an
+// empty list.
+type List[T any] struct {
+	values []T
+}
+
+// InsertAt inserts v at position i, appending it if i is past the
+// end of the list.
+func (l *List[T]) InsertAt(i int, v T) {
+	if i > len(l.values) {
+		i = len(l.values)
+	}
+
+	l.values = slices.Insert(l.values, i, v)
+}
+
+// DeleteAt removes and returns the value at position i and a bool
+// indicating if the position exists.
+func (l *List[T]) DeleteAt(i int) (T, bool) {
+	if i < 0 || i >= len(l.values) {
+		var zero T
+		return zero, false
+	}
+
+	v := l.values[i]
+	l.values = slices.Delete(l.values, i, i+1)
+	return v, true
+}
+
+// DeleteRange removes and returns the values from position start up
+// to, but not including, stop.  Positions past the end are ignored.
+func (l *List[T]) DeleteRange(start, stop int) []T {
+	stop = min(stop, len(l.values))
+	if start >= stop {
+		return nil
+	}
+
+	deleted := slices.Clone(l.values[start:stop])
+	l.values = slices.Delete(l.values, start, stop)
+	return deleted
+}
+
+// ReplaceAt replaces the value at position i, doing nothing if the
+// position does not exist.
+func (l *List[T]) ReplaceAt(i int, v T) {
+	if i >= 0 && i < len(l.values) {
+		l.values[i] = v
+	}
+}
+
+// At returns the value at position i and a bool indicating if the
+// position exists.
+func (l *List[T]) At(i int) (T, bool) {
+	if i < 0 || i >= len(l.values) {
+		var zero T
+		return zero, false
+	}
+
+	return l.values[i], true
+}
+
+// SplitAt keeps the first n values in this list and returns a new
+// list holding the rest.
+func (l *List[T]) SplitAt(n int) *List[T] {
+	n = min(n, len(l.values))
+	right := &List[T]{values: slices.Clone(l.values[n:])}
+	l.values = l.values[:n:n]
+	return right
+}
+
+// Append adds every value in other to the end of this list and
+// empties other.
+func (l *List[T]) Append(other *List[T]) {
+	l.values = append(l.values, other.values...)
+	other.values = nil
+}
+
+// Len returns the number of values in the list.
+func (l *List[T]) Len() int {
+	return len(l.values)
+}
+
+// Values returns the values in the list in order.  The returned slice
+// must not be modified.
+func (l *List[T]) Values() []T {
+	return l.values
+}
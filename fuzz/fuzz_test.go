@@ -0,0 +1,113 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fuzz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOps(t *testing.T) {
+	ops := NewOps([]byte{5, 1, 2, 200, 7})
+
+	op, ok := ops.Next(3)
+	assert.True(t, ok)
+	assert.Equal(t, 2, op)
+	assert.Equal(t, uint64(1), ops.Uint64n(256))
+	assert.Equal(t, uint64(2<<8|200), ops.Uint64n(1000))
+	assert.Equal(t, 1, ops.Len())
+	assert.True(t, ops.Bool())
+	assert.Equal(t, 0, ops.Len())
+
+	_, ok = ops.Next(3)
+	assert.False(t, ok)
+	assert.Equal(t, 0, ops.Intn(10))
+	assert.Equal(t, uint64(0), ops.Uint64n(0))
+	assert.Equal(t, 0, ops.Intn(-1))
+}
+
+func TestSorted(t *testing.T) {
+	var s Sorted[int]
+	assert.True(t, s.Insert(5))
+	assert.True(t, s.Insert(1))
+	assert.True(t, s.Insert(3))
+	assert.False(t, s.Insert(3))
+	assert.Equal(t, []int{1, 3, 5}, s.Values())
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, 3, s.At(1))
+
+	assert.True(t, s.Contains(5))
+	assert.False(t, s.Contains(4))
+	assert.Equal(t, 2, s.Rank(4))
+	assert.Equal(t, 2, s.Rank(5))
+
+	v, ok := s.Floor(4)
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	_, ok = s.Floor(0)
+	assert.False(t, ok)
+	v, ok = s.Ceiling(4)
+	assert.True(t, ok)
+	assert.Equal(t, 5, v)
+	_, ok = s.Ceiling(6)
+	assert.False(t, ok)
+
+	assert.True(t, s.Delete(3))
+	assert.False(t, s.Delete(3))
+	assert.Equal(t, []int{1, 5}, s.Values())
+}
+
+func TestList(t *testing.T) {
+	var l List[int]
+	l.InsertAt(0, 1)
+	l.InsertAt(5, 3)
+	l.InsertAt(1, 2)
+	l.InsertAt(0, 0)
+	assert.Equal(t, []int{0, 1, 2, 3}, l.Values())
+
+	v, ok := l.At(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = l.At(4)
+	assert.False(t, ok)
+
+	l.ReplaceAt(1, 5)
+	l.ReplaceAt(4, 6)
+	assert.Equal(t, []int{0, 5, 2, 3}, l.Values())
+
+	v, ok = l.DeleteAt(1)
+	assert.True(t, ok)
+	assert.Equal(t, 5, v)
+	_, ok = l.DeleteAt(3)
+	assert.False(t, ok)
+
+	right := l.SplitAt(1)
+	assert.Equal(t, []int{0}, l.Values())
+	assert.Equal(t, []int{2, 3}, right.Values())
+	l.InsertAt(1, 4)
+	assert.Equal(t, []int{2, 3}, right.Values())
+
+	l.Append(right)
+	assert.Equal(t, []int{0, 4, 2, 3}, l.Values())
+	assert.Equal(t, 0, right.Len())
+
+	assert.Equal(t, []int{4, 2}, l.DeleteRange(1, 3))
+	assert.Nil(t, l.DeleteRange(2, 5))
+	assert.Equal(t, []int{3}, l.DeleteRange(1, 5))
+	assert.Equal(t, []int{0}, l.Values())
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package hashmap implements a general purpose, thread-safe hash map
+with striped locking.  Keys are spread across a number of shards, each
+a builtin map protected by its own lock, so operations on keys in
+different shards never contend.  Each shard grows on its own, under
+its own lock, so growing the map never stalls operations on the other
+shards.  The sub-packages hold more specialized maps.
+
+The map mirrors sync.Map, including atomic LoadOrStore, Swap,
+CompareAndSwap and CompareAndDelete operations, but is typed and
+performs well under write heavy workloads where sync.Map does not.
+
+Performance characteristics:
+Space: O(n)
+Load: O(1)
+Store: O(1) amortized
+Delete: O(1)
+Range: O(n)
+*/
+package hashmap
+
+import "sync"
+
+// shard is a lock-protected portion of the map, padded so that
+// neighboring shards don't share a cache line.
+type shard[K comparable, V any] struct {
+	lock  sync.RWMutex
+	items map[K]V
+	_     [32]byte
+}
+
+// Concurrent is a thread-safe map from keys of type K to values of
+// type V.
+type Concurrent[K comparable, V any] struct {
+	shards []shard[K, V]
+	hasher hasher[K]
+}
+
+func (c *Concurrent[K, V]) shard(key K) *shard[K, V] {
+	return &c.shards[c.hasher.hash(key)&uint64(len(c.shards)-1)]
+}
+
+// Load returns the value of the provided key.  The returned bool is
+// false if the key isn't in the map.
+func (c *Concurrent[K, V]) Load(key K) (V, bool) {
+	s := c.shard(key)
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	value, ok := s.items[key]
+	return value, ok
+}
+
+// Store sets the value of the provided key.
+func (c *Concurrent[K, V]) Store(key K, value V) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.items[key] = value
+}
+
+// LoadOrStore returns the value of the provided key if it is in the
+// map.  Otherwise it stores and returns the provided value.  The
+// returned bool is true if the value was loaded.
+func (c *Concurrent[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if actual, ok := s.items[key]; ok {
+		return actual, true
+	}
+
+	s.items[key] = value
+	return value, false
+}
+
+// LoadAndDelete removes the provided key from the map and returns its
+// value.  The returned bool is false if the key wasn't in the map.
+func (c *Concurrent[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, ok := s.items[key]
+	if ok {
+		delete(s.items, key)
+	}
+
+	return value, ok
+}
+
+// Delete removes the provided key from the map.
+func (c *Concurrent[K, V]) Delete(key K) {
+	c.LoadAndDelete(key)
+}
+
+// Swap sets the value of the provided key and returns its previous
+// value.  The returned bool is false if the key wasn't in the map.
+func (c *Concurrent[K, V]) Swap(key K, value V) (V, bool) {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	previous, ok := s.items[key]
+	s.items[key] = value
+	return previous, ok
+}
+
+// CompareAndSwap sets the value of the provided key to new if its
+// current value is equal to old.  Returns a bool indicating if the
+// value was swapped.  Panics, like sync.Map, if the values are not
+// comparable.
+func (c *Concurrent[K, V]) CompareAndSwap(key K, old, new V) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, ok := s.items[key]
+	if !ok || any(current) != any(old) {
+		return false
+	}
+
+	s.items[key] = new
+	return true
+}
+
+// CompareAndDelete removes the provided key if its value is equal to
+// old.  Returns a bool indicating if the key was removed.  Panics,
+// like sync.Map, if the values are not comparable.
+func (c *Concurrent[K, V]) CompareAndDelete(key K, old V) bool {
+	s := c.shard(key)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, ok := s.items[key]
+	if !ok || any(current) != any(old) {
+		return false
+	}
+
+	delete(s.items, key)
+	return true
+}
+
+// Range calls the provided function with every key and value in the
+// map, in no particular order, until the function returns false.
+// Each shard is copied before its entries are visited, so the
+// function may modify the map.  Entries stored or deleted during the
+// call may or may not be visited.
+func (c *Concurrent[K, V]) Range(fn func(key K, value V) bool) {
+	var keys []K
+	var values []V
+	for i := range c.shards {
+		s := &c.shards[i]
+		keys, values = keys[:0], values[:0]
+
+		s.lock.RLock()
+		for key, value := range s.items {
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+		s.lock.RUnlock()
+
+		for j, key := range keys {
+			if !fn(key, values[j]) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of keys in the map.  Under concurrent
+// modification this is only a snapshot as shards are counted one at
+// a time.
+func (c *Concurrent[K, V]) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.RLock()
+		n += len(s.items)
+		s.lock.RUnlock()
+	}
+
+	return n
+}
+
+// Clear removes every key from the map.
+func (c *Concurrent[K, V]) Clear() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.lock.Lock()
+		s.items = make(map[K]V)
+		s.lock.Unlock()
+	}
+}
+
+// NewConcurrent returns an empty map split into the provided number
+// of shards, rounded up to a power of two.  A good choice is a small
+// multiple of the number of goroutines using the map.  Panics if the
+// number of shards is less than 1.
+func NewConcurrent[K comparable, V any](shards int) *Concurrent[K, V] {
+	if shards < 1 {
+		panic(`Invalid number of shards provided.`)
+	}
+
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+
+	c := &Concurrent[K, V]{
+		shards: make([]shard[K, V], n),
+		hasher: newHasher[K](),
+	}
+	for i := range c.shards {
+		c.shards[i].items = make(map[K]V)
+	}
+
+	return c
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashmap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConcurrent(t *testing.T) {
+	assert.Len(t, NewConcurrent[int, int](1).shards, 1)
+	assert.Len(t, NewConcurrent[int, int](5).shards, 8)
+	assert.Len(t, NewConcurrent[int, int](16).shards, 16)
+
+	assert.Panics(t, func() {
+		NewConcurrent[int, int](0)
+	})
+}
+
+func TestLoadStore(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	_, ok := c.Load(`a`)
+	assert.False(t, ok)
+
+	c.Store(`a`, 1)
+	c.Store(`b`, 2)
+	c.Store(`a`, 3)
+
+	value, ok := c.Load(`a`)
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	assert.Equal(t, 2, c.Len())
+
+	c.Delete(`a`)
+	_, ok = c.Load(`a`)
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestLoadOrStore(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	value, loaded := c.LoadOrStore(`a`, 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, value)
+
+	value, loaded = c.LoadOrStore(`a`, 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, value)
+}
+
+func TestLoadAndDelete(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	c.Store(`a`, 1)
+
+	value, ok := c.LoadAndDelete(`a`)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	_, ok = c.LoadAndDelete(`a`)
+	assert.False(t, ok)
+}
+
+func TestSwap(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	_, loaded := c.Swap(`a`, 1)
+	assert.False(t, loaded)
+
+	previous, loaded := c.Swap(`a`, 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, previous)
+
+	value, _ := c.Load(`a`)
+	assert.Equal(t, 2, value)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	assert.False(t, c.CompareAndSwap(`a`, 0, 1))
+	_, ok := c.Load(`a`)
+	assert.False(t, ok)
+
+	c.Store(`a`, 1)
+	assert.False(t, c.CompareAndSwap(`a`, 2, 3))
+	assert.True(t, c.CompareAndSwap(`a`, 1, 3))
+
+	value, _ := c.Load(`a`)
+	assert.Equal(t, 3, value)
+}
+
+func TestCompareAndSwapIncomparable(t *testing.T) {
+	c := NewConcurrent[string, []int](4)
+	c.Store(`a`, []int{1})
+	assert.Panics(t, func() {
+		c.CompareAndSwap(`a`, []int{1}, nil)
+	})
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	c := NewConcurrent[string, int](4)
+	assert.False(t, c.CompareAndDelete(`a`, 0))
+
+	c.Store(`a`, 1)
+	assert.False(t, c.CompareAndDelete(`a`, 2))
+	assert.True(t, c.CompareAndDelete(`a`, 1))
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestRange(t *testing.T) {
+	c := NewConcurrent[int, int](4)
+	for i := 0; i < 100; i++ {
+		c.Store(i, i*2)
+	}
+
+	seen := map[int]int{}
+	c.Range(func(key, value int) bool {
+		seen[key] = value
+		// modifying the map while ranging must not deadlock
+		c.Delete(key)
+		return true
+	})
+	assert.Len(t, seen, 100)
+	assert.Equal(t, 0, c.Len())
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i*2, seen[i])
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Store(i, i)
+	}
+
+	count := 0
+	c.Range(func(key, value int) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+}
+
+func TestClear(t *testing.T) {
+	c := NewConcurrent[int, int](4)
+	for i := 0; i < 100; i++ {
+		c.Store(i, i)
+	}
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+	c.Store(1, 1)
+	assert.Equal(t, 1, c.Len())
+}
+
+func TestStructKeys(t *testing.T) {
+	c := NewConcurrent[point, string](8)
+	for i := 0; i < 100; i++ {
+		c.Store(point{i, -i, strconv.Itoa(i)}, strconv.Itoa(i))
+	}
+
+	for i := 0; i < 100; i++ {
+		value, ok := c.Load(point{i, -i, strconv.Itoa(i)})
+		assert.True(t, ok)
+		assert.Equal(t, strconv.Itoa(i), value)
+	}
+	assert.Equal(t, 100, c.Len())
+}
+
+func TestConcurrentLoadOrStore(t *testing.T) {
+	c := NewConcurrent[int, int](8)
+	var wg sync.WaitGroup
+	stored := make([]int, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if _, loaded := c.LoadOrStore(i, g); !loaded {
+					stored[g]++
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// every key is stored exactly once
+	total := 0
+	for _, n := range stored {
+		total += n
+	}
+	assert.Equal(t, 1000, total)
+	assert.Equal(t, 1000, c.Len())
+}
+
+func TestConcurrentCompareAndSwap(t *testing.T) {
+	c := NewConcurrent[string, int](8)
+	c.Store(`counter`, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				for {
+					value, _ := c.Load(`counter`)
+					if c.CompareAndSwap(`counter`, value, value+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, _ := c.Load(`counter`)
+	assert.Equal(t, 8000, value)
+}
+
+func BenchmarkConcurrentStoreParallel(b *testing.B) {
+	c := NewConcurrent[int, int](64)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Store(i%100000, i)
+			i++
+		}
+	})
+}
+
+func BenchmarkConcurrentLoadParallel(b *testing.B) {
+	c := NewConcurrent[int, int](64)
+	for i := 0; i < 100000; i++ {
+		c.Store(i, i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Load(i % 100000)
+			i++
+		}
+	})
+}
+
+// benchmarked against sync.Map
+func BenchmarkSyncMapStoreParallel(b *testing.B) {
+	var m sync.Map
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Store(i%100000, i)
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncMapLoadParallel(b *testing.B) {
+	var m sync.Map
+	for i := 0; i < 100000; i++ {
+		m.Store(i, i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			m.Load(i % 100000)
+			i++
+		}
+	})
+}
+
+func BenchmarkConcurrentStructKey(b *testing.B) {
+	c := NewConcurrent[point, int](64)
+	for i := 0; i < b.N; i++ {
+		c.Store(point{i % 1000, i % 7, ``}, i)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package counter implements a sharded, thread-safe map that counts
+occurrences by key.  Keys are spread across a number of shards, each
+an open-addressing table protected by its own lock, so concurrent
+increments of different keys rarely contend.
+
+An approximate counter can also be created which caps the number of
+keys tracked exactly.  Once the cap is reached, counts for any new keys
+are kept in a count-min sketch, which uses constant memory but may
+overestimate.  Keys counted exactly are never moved to the sketch.
+
+Performance characteristics:
+Space: O(n), or O(maxKeys + sketch) when approximate
+Increment: O(1)
+Get: O(1)
+TopN: O(n log k) where k is the number requested
+*/
+package counter
+
+import (
+	"container/heap"
+	"runtime"
+	"sort"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+// KeyCount is a key and the number of times it has been counted.
+type KeyCount struct {
+	Key   string
+	Count uint64
+}
+
+// KeyCounts is a typed list of KeyCount.
+type KeyCounts []KeyCount
+
+// Counter counts occurrences by key and is safe to use concurrently.
+type Counter struct {
+	shards []*shard
+	mask   uint64
+	sketch *sketch
+}
+
+func (c *Counter) shard(h uint64) *shard {
+	// the low bits are used for probing within a shard
+	return c.shards[(h>>32)&c.mask]
+}
+
+// Increment adds delta to the count of the provided key and returns
+// the new count.  If the key is being counted by the sketch, the
+// returned count is an estimate.
+func (c *Counter) Increment(key string, delta uint64) uint64 {
+	h := hash(key)
+	count, ok := c.shard(h).increment(key, h, delta)
+	if ok {
+		return count
+	}
+
+	return c.sketch.add(h, delta)
+}
+
+// Get returns the count of the provided key, or zero if the key has
+// never been incremented.
+func (c *Counter) Get(key string) uint64 {
+	h := hash(key)
+	count, ok := c.shard(h).get(key, h)
+	if ok || c.sketch == nil {
+		return count
+	}
+
+	return c.sketch.estimate(h)
+}
+
+// TopN returns up to n keys with the highest counts in descending
+// order of count.  Ties are broken by key.  Keys that are only held
+// by the sketch of an approximate counter are not included.
+func (c *Counter) TopN(n int) KeyCounts {
+	if n < 1 {
+		return KeyCounts{}
+	}
+
+	top := make(minHeap, 0, n+1)
+	for _, s := range c.shards {
+		s.each(func(kc KeyCount) {
+			if len(top) < n {
+				heap.Push(&top, kc)
+				return
+			}
+
+			if top.less(top[0], kc) {
+				top[0] = kc
+				heap.Fix(&top, 0)
+			}
+		})
+	}
+
+	result := KeyCounts(top)
+	sort.Slice(result, func(i, j int) bool {
+		return top.less(result[j], result[i])
+	})
+
+	return result
+}
+
+// Len returns the number of keys counted exactly.
+func (c *Counter) Len() uint64 {
+	var number uint64
+	for _, s := range c.shards {
+		number += s.len()
+	}
+
+	return number
+}
+
+// Reset sets every count back to zero.
+func (c *Counter) Reset() {
+	for _, s := range c.shards {
+		s.reset()
+	}
+
+	if c.sketch != nil {
+		c.sketch.reset()
+	}
+}
+
+func newCounter(hint, maxKeys uint64) *Counter {
+	number := hashutil.RoundUp(uint64(runtime.NumCPU()) * 4)
+	c := &Counter{
+		shards: make([]*shard, number),
+		mask:   number - 1,
+	}
+
+	perShard := hint / number
+	limit := uint64(0)
+	if maxKeys > 0 {
+		limit = maxKeys/number + 1
+		if perShard > limit {
+			perShard = limit
+		}
+	}
+
+	for i := range c.shards {
+		c.shards[i] = newShard(perShard, limit)
+	}
+
+	return c
+}
+
+// New returns a counter that counts every key exactly.  The hint is
+// the expected number of distinct keys.
+func New(hint uint64) *Counter {
+	return newCounter(hint, 0)
+}
+
+// NewApproximate returns a counter that counts up to roughly maxKeys
+// distinct keys exactly and estimates the counts of any others with a
+// count-min sketch of the provided width and depth.  Estimates exceed
+// the true count by at most e/width of the total of all sketched counts
+// with probability 1-e^-depth.
+func NewApproximate(hint, maxKeys, width, depth uint64) *Counter {
+	c := newCounter(hint, maxKeys)
+	c.sketch = newSketch(width, depth)
+	return c
+}
+
+// minHeap keeps the highest counts seen so far with the lowest at
+// the root so it can be replaced.
+type minHeap KeyCounts
+
+func (mh minHeap) less(a, b KeyCount) bool {
+	if a.Count == b.Count {
+		return a.Key > b.Key
+	}
+
+	return a.Count < b.Count
+}
+
+func (mh minHeap) Len() int           { return len(mh) }
+func (mh minHeap) Less(i, j int) bool { return mh.less(mh[i], mh[j]) }
+func (mh minHeap) Swap(i, j int)      { mh[i], mh[j] = mh[j], mh[i] }
+
+func (mh *minHeap) Push(x interface{}) {
+	*mh = append(*mh, x.(KeyCount))
+}
+
+func (mh *minHeap) Pop() interface{} {
+	old := *mh
+	n := len(old)
+	kc := old[n-1]
+	*mh = old[:n-1]
+	return kc
+}
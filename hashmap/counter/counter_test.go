@@ -0,0 +1,176 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package counter
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeys(num int) []string {
+	keys := make([]string, 0, num)
+	for i := 0; i < num; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+
+	return keys
+}
+
+func TestIncrement(t *testing.T) {
+	c := New(10)
+
+	assert.Equal(t, uint64(1), c.Increment(`a`, 1))
+	assert.Equal(t, uint64(3), c.Increment(`a`, 2))
+	assert.Equal(t, uint64(5), c.Increment(`b`, 5))
+
+	assert.Equal(t, uint64(3), c.Get(`a`))
+	assert.Equal(t, uint64(5), c.Get(`b`))
+	assert.Equal(t, uint64(0), c.Get(`c`))
+	assert.Equal(t, uint64(2), c.Len())
+}
+
+func TestIncrementRebuild(t *testing.T) {
+	c := New(0)
+	keys := generateKeys(10000)
+
+	for i, key := range keys {
+		c.Increment(key, uint64(i))
+	}
+
+	for i, key := range keys {
+		assert.Equal(t, uint64(i), c.Get(key))
+	}
+	assert.Equal(t, uint64(10000), c.Len())
+}
+
+func TestIncrementConcurrent(t *testing.T) {
+	c := New(100)
+	keys := generateKeys(100)
+
+	var wg sync.WaitGroup
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			defer wg.Done()
+			for _, key := range keys {
+				c.Increment(key, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, key := range keys {
+		assert.Equal(t, uint64(10), c.Get(key))
+	}
+}
+
+func TestTopN(t *testing.T) {
+	c := New(10)
+	c.Increment(`a`, 5)
+	c.Increment(`b`, 10)
+	c.Increment(`c`, 1)
+	c.Increment(`d`, 10)
+
+	assert.Equal(t, KeyCounts{{`b`, 10}, {`d`, 10}, {`a`, 5}}, c.TopN(3))
+	assert.Equal(t, KeyCounts{{`b`, 10}, {`d`, 10}, {`a`, 5}, {`c`, 1}}, c.TopN(10))
+	assert.Equal(t, KeyCounts{}, c.TopN(0))
+}
+
+func TestReset(t *testing.T) {
+	c := New(10)
+	c.Increment(`a`, 5)
+	c.Reset()
+
+	assert.Equal(t, uint64(0), c.Get(`a`))
+	assert.Equal(t, uint64(0), c.Len())
+	assert.Equal(t, KeyCounts{}, c.TopN(1))
+
+	c.Increment(`a`, 1)
+	assert.Equal(t, uint64(1), c.Get(`a`))
+}
+
+func TestApproximate(t *testing.T) {
+	c := NewApproximate(10, 10, 1024, 4)
+	keys := generateKeys(1000)
+
+	for i, key := range keys {
+		c.Increment(key, uint64(i+1))
+	}
+
+	assert.True(t, c.Len() < 1000)
+	for i, key := range keys {
+		assert.True(t, c.Get(key) >= uint64(i+1))
+	}
+
+	exact := c.TopN(1000)
+	assert.Len(t, exact, int(c.Len()))
+	for _, kc := range exact {
+		assert.Equal(t, kc.Count, c.Get(kc.Key))
+	}
+
+	c.Reset()
+	for _, key := range keys {
+		assert.Equal(t, uint64(0), c.Get(key))
+	}
+}
+
+func BenchmarkIncrement(b *testing.B) {
+	c := New(1000)
+	keys := generateKeys(1000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Increment(keys[i%len(keys)], 1)
+	}
+}
+
+func BenchmarkIncrementParallel(b *testing.B) {
+	c := New(1000)
+	keys := generateKeys(1000)
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Increment(keys[i%len(keys)], 1)
+			i++
+		}
+	})
+}
+
+func BenchmarkMutexMapIncrementParallel(b *testing.B) {
+	var lock sync.Mutex
+	m := make(map[string]uint64, 1000)
+	keys := generateKeys(1000)
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			lock.Lock()
+			m[keys[i%len(keys)]]++
+			lock.Unlock()
+			i++
+		}
+	})
+}
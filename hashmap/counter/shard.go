@@ -0,0 +1,147 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package counter
+
+import (
+	"sync"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+const ratio = .75 // ratio sets the capacity a shard has to be at before it expands
+
+// hash returns the 64-bit FNV-1a hash of the provided key.  This is
+// done inline rather than with hash/fnv to avoid allocating.
+func hash(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+
+	return h
+}
+
+type slot struct {
+	key   string
+	hash  uint64
+	count uint64
+	used  bool
+}
+
+type slots []slot
+
+func (slots slots) find(key string, h uint64) uint64 {
+	i := h & (uint64(len(slots)) - 1)
+	for slots[i].used && (slots[i].hash != h || slots[i].key != key) {
+		i = (i + 1) & (uint64(len(slots)) - 1)
+	}
+
+	return i
+}
+
+// shard is a single lock-protected, open-addressing table of counts.
+type shard struct {
+	lock sync.Mutex
+	// limit is the maximum number of keys this shard will count
+	// exactly, or zero if there is no limit.
+	limit, number uint64
+	slots         slots
+}
+
+func (s *shard) rebuild() {
+	slots := make(slots, len(s.slots)*2)
+	for _, sl := range s.slots {
+		if sl.used {
+			slots[slots.find(sl.key, sl.hash)] = sl
+		}
+	}
+
+	s.slots = slots
+}
+
+// increment adds delta to the count of key and returns the new count.
+// Returns false if the key isn't present and the shard is at its limit.
+func (s *shard) increment(key string, h, delta uint64) (uint64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := s.slots.find(key, h)
+	if s.slots[i].used {
+		s.slots[i].count += delta
+		return s.slots[i].count, true
+	}
+
+	if s.limit > 0 && s.number >= s.limit {
+		return 0, false
+	}
+
+	s.slots[i] = slot{key: key, hash: h, count: delta, used: true}
+	s.number++
+	if float64(s.number)/float64(len(s.slots)) > ratio {
+		s.rebuild()
+	}
+
+	return delta, true
+}
+
+func (s *shard) get(key string, h uint64) (uint64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := s.slots.find(key, h)
+	return s.slots[i].count, s.slots[i].used
+}
+
+func (s *shard) each(fn func(KeyCount)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, sl := range s.slots {
+		if sl.used {
+			fn(KeyCount{Key: sl.key, Count: sl.count})
+		}
+	}
+}
+
+func (s *shard) len() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.number
+}
+
+func (s *shard) reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := range s.slots {
+		s.slots[i] = slot{}
+	}
+	s.number = 0
+}
+
+func newShard(hint, limit uint64) *shard {
+	if hint < 8 {
+		hint = 8
+	}
+
+	return &shard{
+		limit: limit,
+		slots: make(slots, hashutil.RoundUp(uint64(float64(hint)/ratio)+1)),
+	}
+}
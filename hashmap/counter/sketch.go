@@ -0,0 +1,86 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package counter
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// sketch is a count-min sketch whose counters are updated atomically
+// so it can be shared by every shard without a lock.
+type sketch struct {
+	width, depth uint64
+	counters     []uint64
+}
+
+// index returns the counter used by the provided row for a hash.
+// Row hashes are derived from the two halves of the key's hash.
+func (s *sketch) index(row, h uint64) uint64 {
+	h1, h2 := h&math.MaxUint32, h>>32
+	return row*s.width + (h1+row*h2)%s.width
+}
+
+// add increments every row's counter for the hash and returns the new
+// estimate.
+func (s *sketch) add(h, delta uint64) uint64 {
+	min := uint64(math.MaxUint64)
+	for row := uint64(0); row < s.depth; row++ {
+		count := atomic.AddUint64(&s.counters[s.index(row, h)], delta)
+		if count < min {
+			min = count
+		}
+	}
+
+	return min
+}
+
+// estimate returns the smallest counter for the hash, which is never
+// less than the true count.
+func (s *sketch) estimate(h uint64) uint64 {
+	min := uint64(math.MaxUint64)
+	for row := uint64(0); row < s.depth; row++ {
+		count := atomic.LoadUint64(&s.counters[s.index(row, h)])
+		if count < min {
+			min = count
+		}
+	}
+
+	return min
+}
+
+func (s *sketch) reset() {
+	for i := range s.counters {
+		atomic.StoreUint64(&s.counters[i], 0)
+	}
+}
+
+func newSketch(width, depth uint64) *sketch {
+	if width == 0 {
+		width = 1
+	}
+
+	if depth == 0 {
+		depth = 1
+	}
+
+	return &sketch{
+		width:    width,
+		depth:    depth,
+		counters: make([]uint64, width*depth),
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package counter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch(t *testing.T) {
+	s := newSketch(64, 4)
+
+	assert.Equal(t, uint64(3), s.add(hash(`a`), 3))
+	assert.True(t, s.add(hash(`a`), 2) >= 5)
+	assert.True(t, s.estimate(hash(`a`)) >= 5)
+
+	s.reset()
+	assert.Equal(t, uint64(0), s.estimate(hash(`a`)))
+}
+
+func TestSketchNeverUnderestimates(t *testing.T) {
+	s := newSketch(16, 2)
+	keys := generateKeys(500)
+	for i, key := range keys {
+		s.add(hash(key), uint64(i))
+	}
+
+	for i, key := range keys {
+		assert.True(t, s.estimate(hash(key)) >= uint64(i))
+	}
+}
+
+func TestHash(t *testing.T) {
+	// FNV-1a test vectors
+	assert.Equal(t, uint64(0xcbf29ce484222325), hash(``))
+	assert.Equal(t, uint64(0xaf63dc4c8601ec8c), hash(`a`))
+	assert.Equal(t, uint64(0x85944171f73967e8), hash(`foobar`))
+}
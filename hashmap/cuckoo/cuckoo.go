@@ -0,0 +1,325 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package cuckoo implements a hash map for unsigned integer keys and
+values using bucketized cuckoo hashing.  Every key has two candidate
+buckets, chosen by independent hash functions, of four slots each.  A
+lookup examines at most those eight slots and a small stash, so unlike
+a linear probing map such as fastinteger, lookups have no long tail of
+probe lengths.
+
+Inserting into two full buckets evicts a resident key to its other
+bucket, which may evict another, and so on.  Four slot buckets let the
+table reach high load before these chains get long.  A key that cannot
+be placed after a bounded number of evictions goes to the stash, and
+only once the stash fills does the table grow.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(n)
+Get: O(1) worst case
+Set: O(1) amortized expected
+Delete: O(1) worst case
+*/
+package cuckoo
+
+import (
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+const (
+	// slotsPerBucket is the number of keys held by each bucket.
+	slotsPerBucket = 4
+	// maxLoad is the fraction of slots that may be filled before the
+	// table grows.
+	maxLoad = .9
+	// maxKicks bounds the number of evictions made by one insert
+	// before the homeless key is moved to the stash.
+	maxKicks = 500
+	// stashSize is the number of keys the stash may hold before the
+	// table is forced to grow.
+	stashSize = 8
+)
+
+// Struct footprints SizeOf charges for the map and its buckets.
+const (
+	mapSize    = uint64(unsafe.Sizeof(Map{}))
+	bucketSize = uint64(unsafe.Sizeof(bucket{}))
+	entrySize  = uint64(unsafe.Sizeof(entry{}))
+)
+
+// hash will convert the uint64 key into a hash based on Murmur3's 64-bit
+// integer finalizer.
+func hash(key uint64) uint64 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+	return key
+}
+
+// altHash is the second, independent hash function.
+func altHash(key uint64) uint64 {
+	return hash(key ^ 0x9e3779b97f4a7c15)
+}
+
+type entry struct {
+	key, value uint64
+}
+
+type bucket struct {
+	entries [slotsPerBucket]entry
+	// used has a bit set for every slot holding an entry.
+	used uint8
+}
+
+func (b *bucket) find(key uint64) int {
+	for i := 0; i < slotsPerBucket; i++ {
+		if b.used&(1<<uint(i)) != 0 && b.entries[i].key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (b *bucket) free() int {
+	for i := 0; i < slotsPerBucket; i++ {
+		if b.used&(1<<uint(i)) == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Map is a cuckoo hash map for integer keys and values.
+type Map struct {
+	buckets []bucket
+	mask    uint64
+	stash   []entry
+	count   uint64
+	// rand is the state of a xorshift generator used to pick which
+	// key to evict.
+	rand uint64
+}
+
+func (m *Map) random() uint64 {
+	m.rand ^= m.rand << 13
+	m.rand ^= m.rand >> 7
+	m.rand ^= m.rand << 17
+	return m.rand
+}
+
+// locate returns the bucket and slot holding key, or a nil bucket.
+func (m *Map) locate(key uint64) (*bucket, int) {
+	b := &m.buckets[hash(key)&m.mask]
+	if i := b.find(key); i >= 0 {
+		return b, i
+	}
+
+	b = &m.buckets[altHash(key)&m.mask]
+	if i := b.find(key); i >= 0 {
+		return b, i
+	}
+
+	return nil, -1
+}
+
+func (m *Map) stashIndex(key uint64) int {
+	for i, e := range m.stash {
+		if e.key == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// place stores an entry known not to be in the map.  If no slot can
+// be found for it, the entry is stashed and false is returned when
+// the stash has overflowed, in which case the map must be rebuilt.
+func (m *Map) place(e entry) bool {
+	i1 := hash(e.key) & m.mask
+	i2 := altHash(e.key) & m.mask
+	for _, i := range [2]uint64{i1, i2} {
+		b := &m.buckets[i]
+		if slot := b.free(); slot >= 0 {
+			b.entries[slot] = e
+			b.used |= 1 << uint(slot)
+			return true
+		}
+	}
+
+	// both buckets are full, evict until something finds room
+	i := i1
+	if m.random()&1 == 1 {
+		i = i2
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		b := &m.buckets[i]
+		slot := int(m.random() % slotsPerBucket)
+		e, b.entries[slot] = b.entries[slot], e
+
+		// move the evicted entry to its other bucket
+		if alt := hash(e.key) & m.mask; alt != i {
+			i = alt
+		} else {
+			i = altHash(e.key) & m.mask
+		}
+
+		b = &m.buckets[i]
+		if slot := b.free(); slot >= 0 {
+			b.entries[slot] = e
+			b.used |= 1 << uint(slot)
+			return true
+		}
+	}
+
+	// the entry in hand is likely not the one we started with
+	m.stash = append(m.stash, e)
+	return len(m.stash) <= stashSize
+}
+
+func (m *Map) entries() []entry {
+	entries := make([]entry, 0, m.count)
+	for i := range m.buckets {
+		b := &m.buckets[i]
+		for slot := 0; slot < slotsPerBucket; slot++ {
+			if b.used&(1<<uint(slot)) != 0 {
+				entries = append(entries, b.entries[slot])
+			}
+		}
+	}
+
+	return append(entries, m.stash...)
+}
+
+// rebuild moves every entry into a table of at least numBuckets
+// buckets, doubling again in the unlikely case everything doesn't fit.
+func (m *Map) rebuild(numBuckets uint64) {
+	entries := m.entries()
+	for {
+		m.buckets = make([]bucket, numBuckets)
+		m.mask = numBuckets - 1
+		m.stash = make([]entry, 0, stashSize+1)
+
+		ok := true
+		for _, e := range entries {
+			if !m.place(e) {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			return
+		}
+		numBuckets *= 2
+	}
+}
+
+// Get returns an item from the map if it exists.  Otherwise,
+// returns false for the second argument.
+func (m *Map) Get(key uint64) (uint64, bool) {
+	if b, i := m.locate(key); b != nil {
+		return b.entries[i].value, true
+	}
+
+	if i := m.stashIndex(key); i >= 0 {
+		return m.stash[i].value, true
+	}
+
+	return 0, false
+}
+
+// Set will set the provided key with the provided value.
+func (m *Map) Set(key, value uint64) {
+	if b, i := m.locate(key); b != nil {
+		b.entries[i].value = value
+		return
+	}
+
+	if i := m.stashIndex(key); i >= 0 {
+		m.stash[i].value = value
+		return
+	}
+
+	if float64(m.count+1) > maxLoad*float64(m.Cap()) {
+		m.rebuild(uint64(len(m.buckets)) * 2)
+	}
+
+	m.count++
+	if !m.place(entry{key: key, value: value}) {
+		m.rebuild(uint64(len(m.buckets)) * 2)
+	}
+}
+
+// Exists will return a bool indicating if the provided key
+// exists in the map.
+func (m *Map) Exists(key uint64) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Delete will remove the provided key from the map.  If the key
+// cannot be found, this is a no-op.
+func (m *Map) Delete(key uint64) {
+	if b, i := m.locate(key); b != nil {
+		b.used &^= 1 << uint(i)
+		m.count--
+		return
+	}
+
+	if i := m.stashIndex(key); i >= 0 {
+		last := len(m.stash) - 1
+		m.stash[i] = m.stash[last]
+		m.stash = m.stash[:last]
+		m.count--
+	}
+}
+
+// Len returns the number of items in the map.
+func (m *Map) Len() uint64 {
+	return m.count
+}
+
+// Cap returns the number of slots in the map, not counting the stash.
+func (m *Map) Cap() uint64 {
+	return uint64(len(m.buckets)) * slotsPerBucket
+}
+
+// SizeOf returns an estimate of the number of bytes used by this map.
+func (m *Map) SizeOf() uint64 {
+	return mapSize + uint64(len(m.buckets))*bucketSize + uint64(cap(m.stash))*entrySize
+}
+
+// New returns a new map with room for at least hint items before
+// growing.
+func New(hint uint64) *Map {
+	numBuckets := hashutil.RoundUp(uint64(float64(hint)/maxLoad)/slotsPerBucket + 1)
+	return &Map{
+		buckets: make([]bucket, numBuckets),
+		mask:    numBuckets - 1,
+		stash:   make([]entry, 0, stashSize+1),
+		rand:    0x2545f4914f6cdd1d,
+	}
+}
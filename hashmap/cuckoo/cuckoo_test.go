@@ -0,0 +1,214 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cuckoo
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeys(num int) []uint64 {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	keys := make([]uint64, 0, num)
+	for i := 0; i < num; i++ {
+		key := uint64(r.Int63())
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func TestInsert(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+
+	assert.True(t, m.Exists(5))
+	value, ok := m.Get(5)
+	assert.Equal(t, uint64(5), value)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), m.Len())
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+	m.Set(5, 10)
+
+	value, ok := m.Get(5)
+	assert.Equal(t, uint64(10), value)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), m.Len())
+}
+
+func TestGet(t *testing.T) {
+	m := New(10)
+
+	value, ok := m.Get(5)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), value)
+}
+
+func TestDelete(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+	m.Set(6, 6)
+
+	m.Delete(5)
+	assert.Equal(t, uint64(1), m.Len())
+	assert.False(t, m.Exists(5))
+
+	m.Delete(5)
+	assert.Equal(t, uint64(1), m.Len())
+
+	m.Delete(6)
+	assert.Equal(t, uint64(0), m.Len())
+	assert.False(t, m.Exists(6))
+}
+
+func TestRebuild(t *testing.T) {
+	numItems := uint64(10000)
+
+	m := New(0)
+	for i := uint64(0); i < numItems; i++ {
+		m.Set(i, i)
+	}
+
+	assert.Equal(t, numItems, m.Len())
+	assert.True(t, float64(m.Len()) <= maxLoad*float64(m.Cap()))
+	for i := uint64(0); i < numItems; i++ {
+		value, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+}
+
+func TestStash(t *testing.T) {
+	m := New(8)
+	// stashing only happens after long eviction chains, so put an
+	// entry there directly
+	m.stash = append(m.stash, entry{key: 1 << 40, value: 1})
+	m.count++
+
+	value, ok := m.Get(1 << 40)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), value)
+
+	m.Set(1<<40, 2)
+	value, _ = m.Get(1 << 40)
+	assert.Equal(t, uint64(2), value)
+	assert.Len(t, m.stash, 1)
+
+	// rebuilding moves stashed entries into the table
+	m.rebuild(uint64(len(m.buckets)) * 2)
+	assert.Len(t, m.stash, 0)
+	value, _ = m.Get(1 << 40)
+	assert.Equal(t, uint64(2), value)
+
+	m.stash = append(m.stash, entry{key: 7, value: 7})
+	m.count++
+	m.Delete(7)
+	assert.Len(t, m.stash, 0)
+	assert.Equal(t, uint64(1), m.Len())
+}
+
+func TestRandomOperations(t *testing.T) {
+	m := New(0)
+	expected := map[uint64]uint64{}
+	for i := 0; i < 100000; i++ {
+		key := uint64(rand.Intn(5000))
+		if rand.Intn(3) == 0 {
+			m.Delete(key)
+			delete(expected, key)
+			continue
+		}
+
+		value := rand.Uint64()
+		m.Set(key, value)
+		expected[key] = value
+	}
+
+	assert.Equal(t, uint64(len(expected)), m.Len())
+	for key, value := range expected {
+		result, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, value, result)
+	}
+	assert.Equal(t, len(expected), len(m.entries()))
+}
+
+func TestSizeOf(t *testing.T) {
+	m := New(10)
+	assert.Equal(t, mapSize+uint64(len(m.buckets))*bucketSize+(stashSize+1)*entrySize, m.SizeOf())
+}
+
+func BenchmarkInsert(b *testing.B) {
+	numItems := uint64(1000)
+
+	keys := generateKeys(int(numItems))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := New(numItems) // so we don't rebuild
+		for _, k := range keys {
+			m.Set(k, k)
+		}
+	}
+}
+
+func BenchmarkExists(b *testing.B) {
+	numItems := uint64(1000)
+
+	keys := generateKeys(int(numItems))
+	m := New(numItems)
+	for _, key := range keys {
+		m.Set(key, key)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			m.Exists(key)
+		}
+	}
+}
+
+func BenchmarkInsertWithExpand(b *testing.B) {
+	numItems := uint64(1000)
+
+	ms := make([]*Map, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		ms = append(ms, New(10))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := ms[i]
+		for j := uint64(0); j < numItems; j++ {
+			m.Set(j, j)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fastinteger
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/fuzz"
+)
+
+// FuzzHashMap checks the hashmap, whose deletes leave tombstones that
+// are later reused or rehashed away, against a map.
+func FuzzHashMap(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 0, 2, 1, 1, 0, 3, 2, 1, 4, 8, 1, 2})
+	seed := make([]byte, 512)
+	rand.New(rand.NewSource(0)).Read(seed)
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := fuzz.NewOps(data)
+		fi, model := New(ops.Uint64n(64)), map[uint64]uint64{}
+		var snapshot *FastIntegerHashMap
+		var snapshotModel map[uint64]uint64
+		for op, ok := ops.Next(5); ok; op, ok = ops.Next(5) {
+			key := ops.Uint64n(256)
+			switch op {
+			case 0:
+				value := ops.Uint64n(256)
+				model[key] = value
+				fi.Set(key, value)
+			case 1:
+				delete(model, key)
+				fi.Delete(key)
+			case 2:
+				expected, ok := model[key]
+				value, found := fi.Get(key)
+				assert.Equal(t, ok, found)
+				assert.Equal(t, expected, value)
+				assert.Equal(t, ok, fi.Exists(key))
+			case 3:
+				fi.Reserve(key)
+				assert.True(t, fi.Cap() > fi.Len())
+			case 4:
+				snapshot, snapshotModel = fi.Snapshot(), maps.Clone(model)
+			}
+
+			if !assert.Equal(t, uint64(len(model)), fi.Len()) {
+				return
+			}
+		}
+
+		for _, m := range []struct {
+			fi    *FastIntegerHashMap
+			model map[uint64]uint64
+		}{{fi, model}, {snapshot, snapshotModel}} {
+			if m.fi == nil {
+				continue
+			}
+			values := map[uint64]uint64{}
+			m.fi.ForEach(func(key, value uint64) bool {
+				values[key] = value
+				return true
+			})
+			assert.Equal(t, m.model, values)
+		}
+	})
+}
@@ -21,8 +21,16 @@
 
 package fastinteger
 
+import "unsafe"
+
 const ratio = .75 // ratio sets the capacity the hashmap has to be at before it expands
 
+const (
+	mapSize     = uint64(unsafe.Sizeof(FastIntegerHashMap{}))
+	packetSize  = uint64(unsafe.Sizeof(packet{}))
+	pointerSize = uint64(unsafe.Sizeof((*packet)(nil)))
+)
+
 // roundUp takes a uint64 greater than 0 and rounds it up to the next
 // power of 2.
 func roundUp(v uint64) uint64 {
@@ -43,29 +51,56 @@ type packet struct {
 
 type packets []*packet
 
+// tombstone marks a slot whose packet has been deleted.  Probes
+// continue past a tombstone so that keys inserted after a
+// collision remain reachable, but Set may reuse the slot.
+var tombstone = &packet{}
+
+// find returns the index of the slot holding key.  If key is not
+// present, the returned index is the slot it should be inserted
+// into, which is either the first tombstone encountered or the
+// empty slot that ended the probe.
 func (packets packets) find(key uint64) uint64 {
-	h := hash(key)
-	i := h & (uint64(len(packets)) - 1)
-	for packets[i] != nil && packets[i].key != key {
-		i = (i + 1) & (uint64(len(packets)) - 1)
+	mask := uint64(len(packets)) - 1
+	i := hash(key) & mask
+	free, freeFound := uint64(0), false
+	for packets[i] != nil {
+		if packets[i] == tombstone {
+			if !freeFound {
+				free, freeFound = i, true
+			}
+		} else if packets[i].key == key {
+			return i
+		}
+		i = (i + 1) & mask
 	}
 
+	if freeFound {
+		return free
+	}
 	return i
 }
 
-func (packets packets) set(packet *packet) {
+// set inserts or updates the provided packet and reports whether
+// a new key was added and whether a tombstone was reused to do so.
+func (packets packets) set(packet *packet) (added, reused bool) {
 	i := packets.find(packet.key)
-	if packets[i] == nil {
+	switch packets[i] {
+	case nil:
 		packets[i] = packet
-		return
+		return true, false
+	case tombstone:
+		packets[i] = packet
+		return true, true
 	}
 
 	packets[i].value = packet.value
+	return false, false
 }
 
 func (packets packets) get(key uint64) (uint64, bool) {
 	i := packets.find(key)
-	if packets[i] == nil {
+	if packets[i] == nil || packets[i] == tombstone {
 		return 0, false
 	}
 
@@ -74,16 +109,16 @@ func (packets packets) get(key uint64) (uint64, bool) {
 
 func (packets packets) delete(key uint64) bool {
 	i := packets.find(key)
-	if packets[i] == nil {
+	if packets[i] == nil || packets[i] == tombstone {
 		return false
 	}
-	packets[i] = nil
+	packets[i] = tombstone
 	return true
 }
 
 func (packets packets) exists(key uint64) bool {
 	i := packets.find(key)
-	return packets[i] != nil // technically, they can store nil
+	return packets[i] != nil && packets[i] != tombstone
 }
 
 // FastIntegerHashMap is a simple hashmap to be used with
@@ -92,24 +127,62 @@ func (packets packets) exists(key uint64) bool {
 // datastructure to set and check for existence of integer
 // keys over a sparse range.
 type FastIntegerHashMap struct {
-	count   uint64
-	packets packets
+	count uint64
+	// tombstones is the number of slots holding deleted packets.
+	// They occupy the table like live packets until a rebuild.
+	tombstones uint64
+	packets    packets
+	// shared indicates that packets are also referenced by a
+	// snapshot and must be copied before they are mutated.
+	shared bool
+}
+
+// unshare copies this map's packets if they are referenced by
+// a snapshot.  Must be called before any mutation.
+func (fi *FastIntegerHashMap) unshare() {
+	if !fi.shared {
+		return
+	}
+
+	packets := make(packets, len(fi.packets))
+	for i, p := range fi.packets {
+		if p == nil || p == tombstone {
+			packets[i] = p
+			continue
+		}
+
+		cp := *p
+		packets[i] = &cp
+	}
+	fi.packets = packets
+	fi.shared = false
 }
 
 // rebuild is an expensive operation which requires us to iterate
 // over the current bucket and rehash the keys for insertion into
-// the new bucket.  The new bucket is twice as large as the old
-// bucket by default.
-func (fi *FastIntegerHashMap) rebuild() {
-	packets := make(packets, roundUp(uint64(len(fi.packets))+1))
+// a new bucket of the provided size.  Tombstones are discarded.
+func (fi *FastIntegerHashMap) rebuild(size uint64) {
+	packets := make(packets, size)
 	for _, packet := range fi.packets {
-		if packet == nil {
+		if packet == nil || packet == tombstone {
 			continue
 		}
 
 		packets.set(packet)
 	}
 	fi.packets = packets
+	fi.tombstones = 0
+}
+
+// grow makes room for one more packet.  If most occupied slots are
+// tombstones the bucket is rehashed at its current size, otherwise
+// it doubles.
+func (fi *FastIntegerHashMap) grow() {
+	size := uint64(len(fi.packets))
+	if fi.tombstones <= fi.count {
+		size = roundUp(size + 1)
+	}
+	fi.rebuild(size)
 }
 
 // Get returns an item from the map if it exists.  Otherwise,
@@ -120,12 +193,18 @@ func (fi *FastIntegerHashMap) Get(key uint64) (uint64, bool) {
 
 // Set will set the provided key with the provided value.
 func (fi *FastIntegerHashMap) Set(key, value uint64) {
-	if float64(fi.count+1)/float64(len(fi.packets)) > ratio {
-		fi.rebuild()
+	fi.unshare()
+	if float64(fi.count+fi.tombstones+1)/float64(len(fi.packets)) > ratio {
+		fi.grow()
 	}
 
-	fi.packets.set(&packet{key: key, value: value})
-	fi.count++
+	added, reused := fi.packets.set(&packet{key: key, value: value})
+	if added {
+		fi.count++
+	}
+	if reused {
+		fi.tombstones--
+	}
 }
 
 // Exists will return a bool indicating if the provided key
@@ -135,13 +214,49 @@ func (fi *FastIntegerHashMap) Exists(key uint64) bool {
 }
 
 // Delete will remove the provided key from the hashmap.  If
-// the key cannot be found, this is a no-op.
+// the key cannot be found, this is a no-op.  Once tombstones
+// outnumber live keys and fill a quarter of the bucket, the
+// bucket is rehashed in place so probes stay short.
 func (fi *FastIntegerHashMap) Delete(key uint64) {
-	if fi.packets.delete(key) {
-		fi.count--
+	fi.unshare()
+	if !fi.packets.delete(key) {
+		return
+	}
+
+	fi.count--
+	fi.tombstones++
+	if fi.tombstones > fi.count && fi.tombstones > uint64(len(fi.packets))/4 {
+		fi.rebuild(uint64(len(fi.packets)))
+	}
+}
+
+// ForEach calls fn for every key and value in the hashmap in
+// no particular order.  Iteration stops if fn returns false.
+// fn must not modify the hashmap.
+func (fi *FastIntegerHashMap) ForEach(fn func(key, value uint64) bool) {
+	for _, packet := range fi.packets {
+		if packet == nil || packet == tombstone {
+			continue
+		}
+
+		if !fn(packet.key, packet.value) {
+			return
+		}
 	}
 }
 
+// Reserve grows the bucket so that at least n keys can be held
+// without a further rebuild.  It never shrinks the hashmap.
+func (fi *FastIntegerHashMap) Reserve(n uint64) {
+	size := roundUp(uint64(float64(n)/ratio) + 1)
+	if size <= uint64(len(fi.packets)) {
+		return
+	}
+
+	fi.unshare()
+	fi.rebuild(size)
+}
+
 // Len returns the number of items in the hashmap.
 func (fi *FastIntegerHashMap) Len() uint64 {
 	return fi.count
@@ -152,6 +267,26 @@ func (fi *FastIntegerHashMap) Cap() uint64 {
 	return uint64(len(fi.packets))
 }
 
+// SizeOf returns an estimate of the number of bytes used by this
+// hashmap, including empty buckets.  Packets shared with a snapshot
+// are counted in both hashmaps.
+func (fi *FastIntegerHashMap) SizeOf() uint64 {
+	return mapSize + uint64(cap(fi.packets))*pointerSize + fi.count*packetSize
+}
+
+// Snapshot returns a point-in-time copy of this hashmap.  The copy
+// shares storage with this hashmap until either is modified, so
+// taking a snapshot is an O(1) operation.
+func (fi *FastIntegerHashMap) Snapshot() *FastIntegerHashMap {
+	fi.shared = true
+	return &FastIntegerHashMap{
+		count:      fi.count,
+		tombstones: fi.tombstones,
+		packets:    fi.packets,
+		shared:     true,
+	}
+}
+
 // New returns a new FastIntegerHashMap with a bucket size specified
 // by hint.
 func New(hint uint64) *FastIntegerHashMap {
@@ -121,6 +121,187 @@ func TestDeleteAll(t *testing.T) {
 	}
 }
 
+func TestSnapshot(t *testing.T) {
+	hm := New(10)
+	hm.Set(1, 1)
+	hm.Set(2, 2)
+
+	snapshot := hm.Snapshot()
+	hm.Set(1, 10)
+	hm.Delete(2)
+	for i := uint64(3); i < 100; i++ {
+		hm.Set(i, i)
+	}
+
+	value, ok := snapshot.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), value)
+	assert.True(t, snapshot.Exists(2))
+	assert.False(t, snapshot.Exists(3))
+	assert.Equal(t, uint64(2), snapshot.Len())
+
+	value, ok = hm.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), value)
+	assert.False(t, hm.Exists(2))
+
+	snapshot.Set(2, 20)
+	value, _ = snapshot.Get(2)
+	assert.Equal(t, uint64(20), value)
+	assert.False(t, hm.Exists(2))
+}
+
+func TestSizeOf(t *testing.T) {
+	hm := New(10)
+	assert.Equal(t, mapSize+16*pointerSize, hm.SizeOf())
+
+	hm.Set(1, 1)
+	hm.Set(2, 2)
+	assert.Equal(t, mapSize+16*pointerSize+2*packetSize, hm.SizeOf())
+
+	for i := uint64(3); i < 100; i++ {
+		hm.Set(i, i)
+	}
+	assert.Equal(t, mapSize+hm.Cap()*pointerSize+99*packetSize, hm.SizeOf())
+
+	hm.Delete(50)
+	assert.Equal(t, mapSize+hm.Cap()*pointerSize+98*packetSize, hm.SizeOf())
+}
+
+func TestInsertOverwriteLen(t *testing.T) {
+	hm := New(10)
+
+	hm.Set(5, 5)
+	hm.Set(5, 10)
+
+	assert.Equal(t, uint64(1), hm.Len())
+}
+
+func TestDeleteKeepsProbeChain(t *testing.T) {
+	hm := New(16)
+	// fill most of the bucket so that probe chains form
+	for i := uint64(0); i < 12; i++ {
+		hm.Set(i, i)
+	}
+
+	for i := uint64(0); i < 12; i += 2 {
+		hm.Delete(i)
+	}
+
+	for i := uint64(0); i < 12; i++ {
+		value, ok := hm.Get(i)
+		if i%2 == 0 {
+			assert.False(t, ok)
+			continue
+		}
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+}
+
+func TestChurn(t *testing.T) {
+	hm := New(64)
+	live := make(map[uint64]uint64)
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := uint64(0); i < 20000; i++ {
+		key := uint64(r.Int63n(40))
+		if r.Intn(2) == 0 {
+			hm.Set(key, i)
+			live[key] = i
+		} else {
+			hm.Delete(key)
+			delete(live, key)
+		}
+
+		assert.Equal(t, uint64(len(live)), hm.Len())
+		assert.True(t, float64(hm.count+hm.tombstones)/float64(hm.Cap()) <= ratio)
+	}
+
+	// a bounded key space should never force the bucket to grow
+	assert.Equal(t, uint64(64), hm.Cap())
+	for key, value := range live {
+		result, ok := hm.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, value, result)
+	}
+}
+
+func TestDeleteCompacts(t *testing.T) {
+	hm := New(64)
+	for i := uint64(0); i < 40; i++ {
+		hm.Set(i, i)
+	}
+
+	for i := uint64(0); i < 40; i++ {
+		hm.Delete(i)
+	}
+
+	assert.Equal(t, uint64(0), hm.Len())
+	assert.True(t, hm.tombstones <= hm.Cap()/4)
+	assert.Equal(t, uint64(64), hm.Cap())
+}
+
+func TestForEach(t *testing.T) {
+	hm := New(10)
+	for i := uint64(0); i < 100; i++ {
+		hm.Set(i, i*2)
+	}
+	for i := uint64(0); i < 100; i += 3 {
+		hm.Delete(i)
+	}
+
+	seen := make(map[uint64]uint64)
+	hm.ForEach(func(key, value uint64) bool {
+		seen[key] = value
+		return true
+	})
+
+	assert.Len(t, seen, int(hm.Len()))
+	for key, value := range seen {
+		assert.NotEqual(t, uint64(0), key%3)
+		assert.Equal(t, key*2, value)
+	}
+
+	count := 0
+	hm.ForEach(func(key, value uint64) bool {
+		count++
+		return count < 5
+	})
+	assert.Equal(t, 5, count)
+}
+
+func TestReserve(t *testing.T) {
+	hm := New(10)
+	hm.Set(1, 1)
+
+	hm.Reserve(1000)
+	cp := hm.Cap()
+	assert.True(t, float64(1000)/float64(cp) <= ratio)
+
+	for i := uint64(0); i < 1000; i++ {
+		hm.Set(i, i)
+	}
+	assert.Equal(t, cp, hm.Cap())
+	assert.Equal(t, uint64(1000), hm.Len())
+
+	hm.Reserve(10)
+	assert.Equal(t, cp, hm.Cap())
+}
+
+func TestReserveSnapshot(t *testing.T) {
+	hm := New(10)
+	hm.Set(1, 1)
+	snapshot := hm.Snapshot()
+
+	hm.Reserve(1000)
+	hm.Set(2, 2)
+
+	assert.Equal(t, uint64(16), snapshot.Cap())
+	assert.False(t, snapshot.Exists(2))
+	assert.True(t, hm.Exists(1))
+}
+
 func BenchmarkInsert(b *testing.B) {
 	numItems := uint64(1000)
 
@@ -273,3 +454,22 @@ func BenchmarkGoInsertWithExpand(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkChurn(b *testing.B) {
+	numItems := uint64(1000)
+
+	hm := New(numItems * 2)
+	for j := uint64(0); j < numItems; j++ {
+		hm.Set(j, j)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// retire the oldest key and add a new one
+		key := uint64(i)
+		hm.Delete(key)
+		hm.Set(key+numItems, key)
+		hm.Exists(key + numItems/2)
+	}
+}
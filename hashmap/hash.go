@@ -0,0 +1,123 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashmap
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+// hasher hashes keys of any comparable type with a random seed.
+// Strings and integers are hashed directly, anything else by walking
+// its value with reflection.  Keys that are equal hash equally, which
+// includes treating -0 and +0 as the same float.
+type hasher[K comparable] struct {
+	seed maphash.Seed
+	// salt is mixed into integers so their hashes are also seeded.
+	salt uint64
+}
+
+func newHasher[K comparable]() hasher[K] {
+	seed := maphash.MakeSeed()
+	return hasher[K]{seed: seed, salt: maphash.String(seed, ``)}
+}
+
+func (h hasher[K]) hash(key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(h.seed, k)
+	case int:
+		return hashutil.Mix(uint64(k) ^ h.salt)
+	case int64:
+		return hashutil.Mix(uint64(k) ^ h.salt)
+	case int32:
+		return hashutil.Mix(uint64(k) ^ h.salt)
+	case uint:
+		return hashutil.Mix(uint64(k) ^ h.salt)
+	case uint64:
+		return hashutil.Mix(k ^ h.salt)
+	case uint32:
+		return hashutil.Mix(uint64(k) ^ h.salt)
+	}
+
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	writeValue(&mh, reflect.ValueOf(&key).Elem())
+	return mh.Sum64()
+}
+
+func writeUint64(mh *maphash.Hash, x uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], x)
+	mh.Write(buf[:])
+}
+
+func writeFloat(mh *maphash.Hash, f float64) {
+	if f == 0 {
+		f = 0 // -0 equals +0 so must hash the same
+	}
+	writeUint64(mh, math.Float64bits(f))
+}
+
+func writeValue(mh *maphash.Hash, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			mh.WriteByte(1)
+		} else {
+			mh.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(mh, uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(mh, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeFloat(mh, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeFloat(mh, real(c))
+		writeFloat(mh, imag(c))
+	case reflect.String:
+		// the length keeps adjacent strings in a struct or array
+		// from running together.
+		writeUint64(mh, uint64(v.Len()))
+		mh.WriteString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		writeUint64(mh, uint64(v.Pointer()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(mh, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeValue(mh, v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			mh.WriteByte(0)
+			return
+		}
+		mh.WriteString(v.Elem().Type().String())
+		writeValue(mh, v.Elem())
+	default:
+		panic(`Unhashable key provided.`)
+	}
+}
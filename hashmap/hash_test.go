@@ -0,0 +1,92 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashmap
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	x, y int
+	name string
+}
+
+type named int
+
+func TestHashEqualKeys(t *testing.T) {
+	s := newHasher[string]()
+	assert.Equal(t, s.hash(`a`), s.hash(`a`))
+	assert.NotEqual(t, s.hash(`a`), s.hash(`b`))
+
+	i := newHasher[int]()
+	assert.Equal(t, i.hash(5), i.hash(5))
+	assert.NotEqual(t, i.hash(5), i.hash(6))
+
+	p := newHasher[point]()
+	assert.Equal(t, p.hash(point{1, 2, `a`}), p.hash(point{1, 2, `a`}))
+	assert.NotEqual(t, p.hash(point{1, 2, `a`}), p.hash(point{2, 1, `a`}))
+
+	f := newHasher[float64]()
+	assert.Equal(t, f.hash(0), f.hash(math.Copysign(0, -1)))
+
+	n := newHasher[named]()
+	assert.Equal(t, n.hash(3), n.hash(3))
+}
+
+func TestHashAdjacentStrings(t *testing.T) {
+	h := newHasher[[2]string]()
+	assert.NotEqual(t, h.hash([2]string{`ab`, `c`}), h.hash([2]string{`a`, `bc`}))
+}
+
+func TestHashInterfaces(t *testing.T) {
+	h := newHasher[interface{}]()
+	assert.Equal(t, h.hash(int8(1)), h.hash(int8(1)))
+	assert.NotEqual(t, h.hash(int8(1)), h.hash(uint8(1)))
+	assert.Equal(t, h.hash(nil), h.hash(nil))
+	assert.Equal(t, h.hash(`a`), h.hash(`a`))
+
+	type wrapper struct {
+		value interface{}
+	}
+	w := newHasher[wrapper]()
+	assert.Equal(t, w.hash(wrapper{nil}), w.hash(wrapper{nil}))
+	assert.Equal(t, w.hash(wrapper{1.5}), w.hash(wrapper{1.5}))
+}
+
+func TestHashPointers(t *testing.T) {
+	a, b := new(int), new(int)
+	h := newHasher[*int]()
+	assert.Equal(t, h.hash(a), h.hash(a))
+	assert.NotEqual(t, h.hash(a), h.hash(b))
+}
+
+func TestHashSeeded(t *testing.T) {
+	// two hashers agree with themselves but not, almost certainly,
+	// with each other.
+	a, b := newHasher[int](), newHasher[int]()
+	assert.NotEqual(t, a.hash(1), b.hash(1))
+}
+
+func TestHashUnhashable(t *testing.T) {
+	h := newHasher[interface{}]()
+	assert.Panics(t, func() {
+		h.hash([]int{1})
+	})
+}
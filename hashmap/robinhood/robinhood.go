@@ -0,0 +1,251 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package robinhood implements an open addressing hash map for unsigned
+integer keys and values using Robin Hood hashing.  Every slot records
+how far its key is from the slot the key hashed to, its probe length.
+An insert that meets a key closer to home than itself takes that
+slot and carries the displaced key onward, so probe lengths stay
+short and, more importantly, similar to one another.  Lookups stop as
+soon as they pass a key closer to home than the one they are after.
+
+Deletes shift the following keys back one slot instead of leaving a
+tombstone, so a map with heavy churn does not degrade over time.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(n)
+Get: O(1) expected, with low variance
+Set: O(1) amortized expected
+Delete: O(1) expected
+*/
+package robinhood
+
+import (
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+// DefaultMaxLoad is the fraction of slots that may be filled before
+// a map created with New grows.
+const DefaultMaxLoad = .9
+
+const (
+	mapSize  = uint64(unsafe.Sizeof(Map{}))
+	slotSize = uint64(unsafe.Sizeof(slot{}))
+)
+
+// hash will convert the uint64 key into a hash based on Murmur3's 64-bit
+// integer finalizer.
+func hash(key uint64) uint64 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+	return key
+}
+
+type slot struct {
+	key, value uint64
+	// dist is one more than the key's probe length so that the zero
+	// value marks an empty slot.
+	dist uint64
+}
+
+// Map is a Robin Hood hash map for integer keys and values.
+type Map struct {
+	slots   []slot
+	mask    uint64
+	count   uint64
+	maxLoad float64
+}
+
+// find returns the index of the slot holding key, or -1.
+func (m *Map) find(key uint64) int64 {
+	i := hash(key) & m.mask
+	for dist := uint64(1); m.slots[i].dist >= dist; dist++ {
+		if m.slots[i].key == key {
+			return int64(i)
+		}
+		i = (i + 1) & m.mask
+	}
+
+	return -1
+}
+
+// insert stores an entry known not to be in the map.
+func (m *Map) insert(s slot) {
+	i := hash(s.key) & m.mask
+	s.dist = 1
+	for {
+		if m.slots[i].dist == 0 {
+			m.slots[i] = s
+			return
+		}
+
+		// take from the rich, keys nearer home, and give to the poor
+		if m.slots[i].dist < s.dist {
+			s, m.slots[i] = m.slots[i], s
+		}
+
+		i = (i + 1) & m.mask
+		s.dist++
+	}
+}
+
+func (m *Map) rebuild(size uint64) {
+	old := m.slots
+	m.slots = make([]slot, size)
+	m.mask = size - 1
+	for _, s := range old {
+		if s.dist != 0 {
+			m.insert(s)
+		}
+	}
+}
+
+// Get returns an item from the map if it exists.  Otherwise,
+// returns false for the second argument.
+func (m *Map) Get(key uint64) (uint64, bool) {
+	i := m.find(key)
+	if i < 0 {
+		return 0, false
+	}
+
+	return m.slots[i].value, true
+}
+
+// Set will set the provided key with the provided value.
+func (m *Map) Set(key, value uint64) {
+	if i := m.find(key); i >= 0 {
+		m.slots[i].value = value
+		return
+	}
+
+	if float64(m.count+1) > m.maxLoad*float64(len(m.slots)) {
+		m.rebuild(uint64(len(m.slots)) * 2)
+	}
+
+	m.insert(slot{key: key, value: value})
+	m.count++
+}
+
+// Exists will return a bool indicating if the provided key
+// exists in the map.
+func (m *Map) Exists(key uint64) bool {
+	return m.find(key) >= 0
+}
+
+// Delete will remove the provided key from the map.  If the key
+// cannot be found, this is a no-op.
+func (m *Map) Delete(key uint64) {
+	found := m.find(key)
+	if found < 0 {
+		return
+	}
+
+	// shift back every following key that isn't already home
+	i := uint64(found)
+	next := (i + 1) & m.mask
+	for m.slots[next].dist > 1 {
+		m.slots[i] = m.slots[next]
+		m.slots[i].dist--
+		i, next = next, (next+1)&m.mask
+	}
+
+	m.slots[i] = slot{}
+	m.count--
+}
+
+// Each calls fn with every key and value in the map, in no particular
+// order, stopping early if fn returns false.  The map must not be
+// modified during iteration.
+func (m *Map) Each(fn func(key, value uint64) bool) {
+	for _, s := range m.slots {
+		if s.dist != 0 && !fn(s.key, s.value) {
+			return
+		}
+	}
+}
+
+// ProbeLengths returns the mean and maximum probe length of the keys
+// in the map, where a key in the slot it hashed to has a probe length
+// of 0.  This scans the whole table.
+func (m *Map) ProbeLengths() (float64, uint64) {
+	if m.count == 0 {
+		return 0, 0
+	}
+
+	var total, max uint64
+	for _, s := range m.slots {
+		if s.dist == 0 {
+			continue
+		}
+
+		total += s.dist - 1
+		if s.dist-1 > max {
+			max = s.dist - 1
+		}
+	}
+
+	return float64(total) / float64(m.count), max
+}
+
+// Len returns the number of items in the map.
+func (m *Map) Len() uint64 {
+	return m.count
+}
+
+// Cap returns the number of slots in the map.
+func (m *Map) Cap() uint64 {
+	return uint64(len(m.slots))
+}
+
+// SizeOf returns an estimate of the number of bytes used by this map.
+func (m *Map) SizeOf() uint64 {
+	return mapSize + uint64(len(m.slots))*slotSize
+}
+
+// NewWithMaxLoad returns a new map with room for at least hint items
+// which grows once more than maxLoad of its slots are filled.  maxLoad
+// must be in (0, 1).  Higher loads save memory at the cost of longer
+// probes.
+func NewWithMaxLoad(hint uint64, maxLoad float64) *Map {
+	if maxLoad <= 0 || maxLoad >= 1 {
+		panic(`Invalid max load provided.`)
+	}
+
+	size := hashutil.RoundUp(uint64(float64(hint)/maxLoad) + 1)
+	if size < 2 {
+		size = 2
+	}
+
+	return &Map{
+		slots:   make([]slot, size),
+		mask:    size - 1,
+		maxLoad: maxLoad,
+	}
+}
+
+// New returns a new map with room for at least hint items before
+// growing, using DefaultMaxLoad.
+func New(hint uint64) *Map {
+	return NewWithMaxLoad(hint, DefaultMaxLoad)
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package robinhood
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKeys(num int) []uint64 {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	keys := make([]uint64, 0, num)
+	for i := 0; i < num; i++ {
+		key := uint64(r.Int63())
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// checkInvariants ensures every slot's recorded probe length matches
+// its position and that no key could be moved closer to home.
+func checkInvariants(t *testing.T, m *Map) {
+	count := uint64(0)
+	for i, s := range m.slots {
+		if s.dist == 0 {
+			continue
+		}
+		count++
+
+		home := hash(s.key) & m.mask
+		assert.Equal(t, (uint64(i)-home)&m.mask, s.dist-1)
+
+		next := m.slots[(uint64(i)+1)&m.mask]
+		assert.True(t, next.dist <= s.dist+1)
+	}
+	assert.Equal(t, m.count, count)
+}
+
+func TestInsert(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+
+	assert.True(t, m.Exists(5))
+	value, ok := m.Get(5)
+	assert.Equal(t, uint64(5), value)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(16), m.Cap())
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+	m.Set(5, 10)
+
+	value, ok := m.Get(5)
+	assert.Equal(t, uint64(10), value)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), m.Len())
+}
+
+func TestGet(t *testing.T) {
+	m := New(10)
+
+	value, ok := m.Get(5)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), value)
+}
+
+func TestDelete(t *testing.T) {
+	m := New(10)
+
+	m.Set(5, 5)
+	m.Set(6, 6)
+
+	m.Delete(5)
+	assert.Equal(t, uint64(1), m.Len())
+	assert.False(t, m.Exists(5))
+
+	m.Delete(5)
+	assert.Equal(t, uint64(1), m.Len())
+
+	m.Delete(6)
+	assert.Equal(t, uint64(0), m.Len())
+	assert.False(t, m.Exists(6))
+	for _, s := range m.slots {
+		assert.Equal(t, slot{}, s)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	numItems := uint64(10000)
+
+	m := New(0)
+	for i := uint64(0); i < numItems; i++ {
+		m.Set(i, i)
+	}
+
+	assert.Equal(t, numItems, m.Len())
+	checkInvariants(t, m)
+	for i := uint64(0); i < numItems; i++ {
+		value, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	m := NewWithMaxLoad(0, .95)
+	expected := map[uint64]uint64{}
+	for i := 0; i < 100000; i++ {
+		key := uint64(rand.Intn(5000))
+		if rand.Intn(3) == 0 {
+			m.Delete(key)
+			delete(expected, key)
+			continue
+		}
+
+		value := rand.Uint64()
+		m.Set(key, value)
+		expected[key] = value
+	}
+
+	checkInvariants(t, m)
+	assert.Equal(t, uint64(len(expected)), m.Len())
+	for key, value := range expected {
+		result, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, value, result)
+	}
+}
+
+func TestEach(t *testing.T) {
+	m := New(10)
+	for i := uint64(0); i < 100; i++ {
+		m.Set(i, i*2)
+	}
+
+	seen := map[uint64]uint64{}
+	m.Each(func(key, value uint64) bool {
+		seen[key] = value
+		return true
+	})
+	assert.Len(t, seen, 100)
+	assert.Equal(t, uint64(20), seen[10])
+
+	count := 0
+	m.Each(func(key, value uint64) bool {
+		count++
+		return count < 5
+	})
+	assert.Equal(t, 5, count)
+}
+
+func TestProbeLengths(t *testing.T) {
+	m := New(10)
+	mean, max := m.ProbeLengths()
+	assert.Equal(t, float64(0), mean)
+	assert.Equal(t, uint64(0), max)
+
+	// force three keys to the same home slot
+	keys := []uint64{}
+	for key := uint64(0); len(keys) < 3; key++ {
+		if hash(key)&m.mask == 0 {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		m.Set(key, key)
+	}
+
+	mean, max = m.ProbeLengths()
+	assert.Equal(t, float64(1), mean)
+	assert.Equal(t, uint64(2), max)
+}
+
+func TestNewWithMaxLoad(t *testing.T) {
+	m := NewWithMaxLoad(100, .5)
+	assert.Equal(t, uint64(256), m.Cap())
+
+	for i := uint64(0); i < 129; i++ {
+		m.Set(i, i)
+	}
+	assert.Equal(t, uint64(512), m.Cap())
+
+	assert.Panics(t, func() { NewWithMaxLoad(10, 1) })
+}
+
+func TestSizeOf(t *testing.T) {
+	m := New(10)
+	assert.Equal(t, mapSize+16*slotSize, m.SizeOf())
+}
+
+func BenchmarkInsert(b *testing.B) {
+	numItems := uint64(1000)
+
+	keys := generateKeys(int(numItems))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := New(numItems) // so we don't rebuild
+		for _, k := range keys {
+			m.Set(k, k)
+		}
+	}
+}
+
+func BenchmarkExists(b *testing.B) {
+	numItems := uint64(1000)
+
+	keys := generateKeys(int(numItems))
+	m := New(numItems)
+	for _, key := range keys {
+		m.Set(key, key)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			m.Exists(key)
+		}
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	numItems := uint64(1000)
+
+	ms := make([]*Map, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		m := New(numItems)
+		for j := uint64(0); j < numItems; j++ {
+			m.Set(j, j)
+		}
+		ms = append(ms, m)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := ms[i]
+		for j := uint64(0); j < numItems; j++ {
+			m.Delete(j)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swiss
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+const (
+	groupSize = 8
+
+	// control bytes of slots not holding a key, full slots hold the
+	// low 7 bits of their key's hash and so never have the high bit set
+	empty   = 0x80
+	deleted = 0xfe
+
+	lsbs = 0x0101010101010101
+	msbs = 0x8080808080808080
+
+	allEmpty = empty * lsbs
+)
+
+// ctrl holds the control bytes of a group's eight slots, one per byte
+// with slot 0 in the lowest byte, so a whole group can be searched
+// with a handful of integer operations.
+type ctrl uint64
+
+// match returns a bitmask with the high bit of each byte set where
+// the control byte may equal h2.  False positives are possible when a
+// byte is one more than h2 and follows a match, keys must be compared.
+func (c ctrl) match(h2 uint8) bitmask {
+	x := uint64(c) ^ (lsbs * uint64(h2))
+	return bitmask((x - lsbs) &^ x & msbs)
+}
+
+// matchEmpty returns a bitmask of the empty slots.  Only empty slots
+// have the high bit set without the second lowest bit.
+func (c ctrl) matchEmpty() bitmask {
+	return bitmask(uint64(c) &^ (uint64(c) << 6) & msbs)
+}
+
+// matchEmptyOrDeleted returns a bitmask of the slots without a key.
+func (c ctrl) matchEmptyOrDeleted() bitmask {
+	return bitmask(uint64(c) & msbs)
+}
+
+func (c *ctrl) set(i int, b uint8) {
+	shift := uint(i) * 8
+	*c = ctrl(uint64(*c)&^(0xff<<shift) | uint64(b)<<shift)
+}
+
+// bitmask has the high bit of a byte set for each selected slot.
+type bitmask uint64
+
+func (b bitmask) first() int {
+	return bits.TrailingZeros64(uint64(b)) / 8
+}
+
+func (b bitmask) removeFirst() bitmask {
+	return b & (b - 1)
+}
+
+// Hasher hashes keys of type K.
+type Hasher[K comparable] func(K) uint64
+
+// StringHasher returns a randomly seeded hasher for string keys.
+func StringHasher() Hasher[string] {
+	seed := maphash.MakeSeed()
+	return func(key string) uint64 {
+		return maphash.String(seed, key)
+	}
+}
+
+// Uint64Hasher hashes uint64 keys using Murmur3's 64-bit integer
+// finalizer.
+func Uint64Hasher(key uint64) uint64 {
+	key ^= key >> 33
+	key *= 0xff51afd7ed558ccd
+	key ^= key >> 33
+	key *= 0xc4ceb9fe1a85ec53
+	key ^= key >> 33
+	return key
+}
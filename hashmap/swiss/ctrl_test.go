@@ -0,0 +1,52 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swiss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtrlMatch(t *testing.T) {
+	c := ctrl(allEmpty)
+	c.set(0, 5)
+	c.set(3, 5)
+	c.set(4, 7)
+	c.set(6, deleted)
+
+	assert.Equal(t, bitmask(0x80|0x80<<24), c.match(5))
+	assert.Equal(t, bitmask(0x80<<32), c.match(7))
+	assert.Equal(t, bitmask(0), c.match(9))
+
+	assert.Equal(t, bitmask(0x80<<8|0x80<<16|0x80<<40|0x80<<56), c.matchEmpty())
+	assert.Equal(t, bitmask(0x80<<8|0x80<<16|0x80<<40|0x80<<48|0x80<<56), c.matchEmptyOrDeleted())
+}
+
+func TestBitmask(t *testing.T) {
+	b := bitmask(0x80<<16 | 0x80<<40)
+	assert.Equal(t, 2, b.first())
+	b = b.removeFirst()
+	assert.Equal(t, 5, b.first())
+	assert.Equal(t, bitmask(0), b.removeFirst())
+}
+
+func TestStringHasher(t *testing.T) {
+	h := StringHasher()
+	assert.Equal(t, h(`a`), h(`a`))
+	assert.NotEqual(t, h(`a`), h(`b`))
+}
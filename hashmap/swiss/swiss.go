@@ -0,0 +1,267 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package swiss implements a flat hash map in the style of Google's
+Swiss tables.  Slots are arranged in groups of eight, each group having
+a word of eight control bytes recording which slots are empty, which
+held a since deleted key and, for full slots, seven bits of their key's
+hash.  A lookup compares all eight control bytes of a group against
+its hash at once with integer arithmetic, the portable equivalent of
+the SIMD instructions used by the original, and only compares keys
+whose control bytes match, so most probes touch a single word.
+
+Keys and values are stored inline in their groups rather than behind
+pointers.  Probing moves between groups quadratically and stops at
+the first group with an empty slot.  Deleting from a group that has
+never been full leaves an empty slot, otherwise a tombstone which is
+cleaned up the next time the table is rehashed.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(n), at most 8 / 7 slots per key plus one byte per slot
+Get: O(1) expected
+Set: O(1) amortized expected
+Delete: O(1) expected
+*/
+package swiss
+
+// maxLoad is the fraction of slots, counting tombstones, that may be
+// used before the table is rehashed.
+const maxLoad = 7.0 / 8.0
+
+type group[K comparable, V any] struct {
+	ctrl   ctrl
+	keys   [groupSize]K
+	values [groupSize]V
+}
+
+// Map is a Swiss table hash map.
+type Map[K comparable, V any] struct {
+	groups []group[K, V]
+	mask   uint64
+	hasher Hasher[K]
+	count  uint64
+	// growthLeft is the number of empty slots that may be filled
+	// before the table must be rehashed.
+	growthLeft uint64
+}
+
+func splitHash(h uint64) (uint64, uint8) {
+	return h >> 7, uint8(h & 0x7f)
+}
+
+// find returns the group and slot holding key.
+func (m *Map[K, V]) find(key K) (*group[K, V], int, bool) {
+	h1, h2 := splitHash(m.hasher(key))
+	g := h1 & m.mask
+	for step := uint64(1); ; step++ {
+		grp := &m.groups[g]
+		for match := grp.ctrl.match(h2); match != 0; match = match.removeFirst() {
+			i := match.first()
+			if grp.keys[i] == key {
+				return grp, i, true
+			}
+		}
+
+		if grp.ctrl.matchEmpty() != 0 {
+			return nil, 0, false
+		}
+
+		g = (g + step) & m.mask
+	}
+}
+
+// insert stores a key known not to be in the map and returns true if
+// it filled an empty slot rather than a tombstone.
+func (m *Map[K, V]) insert(key K, value V) bool {
+	h1, h2 := splitHash(m.hasher(key))
+	g := h1 & m.mask
+	for step := uint64(1); ; step++ {
+		grp := &m.groups[g]
+		if match := grp.ctrl.matchEmptyOrDeleted(); match != 0 {
+			i := match.first()
+			wasEmpty := grp.ctrl.matchEmpty()&(0x80<<(uint(i)*8)) != 0
+			grp.ctrl.set(i, h2)
+			grp.keys[i] = key
+			grp.values[i] = value
+			return wasEmpty
+		}
+
+		g = (g + step) & m.mask
+	}
+}
+
+// rehash moves every key into a table of numGroups groups, dropping
+// any tombstones.
+func (m *Map[K, V]) rehash(numGroups uint64) {
+	old := m.groups
+	m.groups = make([]group[K, V], numGroups)
+	m.mask = numGroups - 1
+	for i := range m.groups {
+		m.groups[i].ctrl = allEmpty
+	}
+	m.growthLeft = capacityFor(numGroups) - m.count
+
+	for g := range old {
+		grp := &old[g]
+		for full := ^grp.ctrl.matchEmptyOrDeleted() & msbs; full != 0; full = full.removeFirst() {
+			i := full.first()
+			m.insert(grp.keys[i], grp.values[i])
+		}
+	}
+}
+
+// capacityFor returns the number of keys a table of numGroups
+// groups can hold.
+func capacityFor(numGroups uint64) uint64 {
+	return uint64(float64(numGroups*groupSize) * maxLoad)
+}
+
+// groupsFor returns the number of groups needed to hold n keys.
+func groupsFor(n uint64) uint64 {
+	numGroups := uint64(1)
+	for capacityFor(numGroups) < n {
+		numGroups *= 2
+	}
+
+	return numGroups
+}
+
+// Get returns the value for the provided key if it exists.
+// Otherwise, returns false for the second argument.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	grp, i, ok := m.find(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return grp.values[i], true
+}
+
+// Exists returns a bool indicating if the provided key exists in
+// the map.
+func (m *Map[K, V]) Exists(key K) bool {
+	_, _, ok := m.find(key)
+	return ok
+}
+
+// Set will set the provided key with the provided value.
+func (m *Map[K, V]) Set(key K, value V) {
+	if grp, i, ok := m.find(key); ok {
+		grp.values[i] = value
+		return
+	}
+
+	if m.growthLeft == 0 {
+		// if tombstones are using much of the table, rehashing in
+		// place is enough to reclaim them
+		numGroups := uint64(len(m.groups))
+		if m.count+1 > capacityFor(numGroups)/2 {
+			numGroups *= 2
+		}
+		m.rehash(numGroups)
+	}
+
+	if m.insert(key, value) {
+		m.growthLeft--
+	}
+	m.count++
+}
+
+// Delete removes the provided key from the map and returns a bool
+// indicating if it was found.
+func (m *Map[K, V]) Delete(key K) bool {
+	grp, i, ok := m.find(key)
+	if !ok {
+		return false
+	}
+
+	// a group with an empty slot has never been full, so no probe
+	// has ever moved past it and it needs no tombstone
+	if grp.ctrl.matchEmpty() != 0 {
+		grp.ctrl.set(i, empty)
+		m.growthLeft++
+	} else {
+		grp.ctrl.set(i, deleted)
+	}
+
+	var zeroKey K
+	var zeroValue V
+	grp.keys[i] = zeroKey
+	grp.values[i] = zeroValue
+	m.count--
+	return true
+}
+
+// Each calls fn with every key and value in the map, in no particular
+// order, stopping early if fn returns false.  The map must not be
+// modified during iteration.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	for g := range m.groups {
+		grp := &m.groups[g]
+		for full := ^grp.ctrl.matchEmptyOrDeleted() & msbs; full != 0; full = full.removeFirst() {
+			i := full.first()
+			if !fn(grp.keys[i], grp.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Reserve grows the map, if needed, so that it can hold n keys
+// without rehashing.
+func (m *Map[K, V]) Reserve(n uint64) {
+	if n <= m.count+m.growthLeft {
+		return
+	}
+
+	m.rehash(groupsFor(n))
+}
+
+// Clear removes every key from the map while keeping its memory for
+// reuse.
+func (m *Map[K, V]) Clear() {
+	var zero group[K, V]
+	for i := range m.groups {
+		m.groups[i] = zero
+		m.groups[i].ctrl = allEmpty
+	}
+
+	m.count = 0
+	m.growthLeft = capacityFor(uint64(len(m.groups)))
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[K, V]) Len() uint64 {
+	return m.count
+}
+
+// Cap returns the number of keys the map can hold before rehashing,
+// including keys already in the map.
+func (m *Map[K, V]) Cap() uint64 {
+	return m.count + m.growthLeft
+}
+
+// New returns a map with room for at least hint keys, hashing keys
+// with hasher.
+func New[K comparable, V any](hint uint64, hasher Hasher[K]) *Map[K, V] {
+	m := &Map[K, V]{hasher: hasher}
+	m.rehash(groupsFor(hint))
+	return m
+}
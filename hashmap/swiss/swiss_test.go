@@ -0,0 +1,249 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package swiss
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collidingHasher sends every key to the same group and control
+// byte so probing and key comparison are exercised.
+func collidingHasher(key uint64) uint64 {
+	return 0
+}
+
+func TestSetGet(t *testing.T) {
+	m := New[string, int](0, StringHasher())
+
+	m.Set(`a`, 1)
+	m.Set(`b`, 2)
+	m.Set(`a`, 3)
+
+	value, ok := m.Get(`a`)
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	assert.True(t, m.Exists(`b`))
+
+	value, ok = m.Get(`c`)
+	assert.False(t, ok)
+	assert.Equal(t, 0, value)
+	assert.Equal(t, uint64(2), m.Len())
+}
+
+func TestDelete(t *testing.T) {
+	m := New[uint64, uint64](0, Uint64Hasher)
+	m.Set(1, 1)
+	m.Set(2, 2)
+
+	assert.True(t, m.Delete(1))
+	assert.False(t, m.Delete(1))
+	assert.False(t, m.Exists(1))
+	assert.True(t, m.Exists(2))
+	assert.Equal(t, uint64(1), m.Len())
+}
+
+func TestGrow(t *testing.T) {
+	numItems := uint64(10000)
+	m := New[uint64, uint64](0, Uint64Hasher)
+	for i := uint64(0); i < numItems; i++ {
+		m.Set(i, i*2)
+	}
+
+	assert.Equal(t, numItems, m.Len())
+	assert.True(t, m.Cap() >= numItems)
+	for i := uint64(0); i < numItems; i++ {
+		value, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i*2, value)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	m := New[uint64, uint64](0, collidingHasher)
+	for i := uint64(0); i < 100; i++ {
+		m.Set(i, i)
+	}
+	for i := uint64(0); i < 100; i += 2 {
+		assert.True(t, m.Delete(i))
+	}
+
+	for i := uint64(0); i < 100; i++ {
+		assert.Equal(t, i%2 == 1, m.Exists(i))
+	}
+}
+
+func TestTombstonesReclaimed(t *testing.T) {
+	m := New[uint64, uint64](56, Uint64Hasher)
+	groups := len(m.groups)
+
+	// churn through many keys while never holding more than a few,
+	// which must not grow the table
+	for i := uint64(0); i < 10000; i++ {
+		m.Set(i, i)
+		if i >= 4 {
+			assert.True(t, m.Delete(i-4))
+		}
+	}
+
+	assert.Equal(t, uint64(4), m.Len())
+	assert.Equal(t, groups, len(m.groups))
+}
+
+func TestRandomOperations(t *testing.T) {
+	m := New[uint64, uint64](0, Uint64Hasher)
+	expected := map[uint64]uint64{}
+	for i := 0; i < 100000; i++ {
+		key := uint64(rand.Intn(5000))
+		if rand.Intn(3) == 0 {
+			_, exists := expected[key]
+			assert.Equal(t, exists, m.Delete(key))
+			delete(expected, key)
+			continue
+		}
+
+		value := rand.Uint64()
+		m.Set(key, value)
+		expected[key] = value
+	}
+
+	assert.Equal(t, uint64(len(expected)), m.Len())
+	seen := 0
+	m.Each(func(key, value uint64) bool {
+		seen++
+		assert.Equal(t, expected[key], value)
+		return true
+	})
+	assert.Equal(t, len(expected), seen)
+}
+
+func TestEachStops(t *testing.T) {
+	m := New[uint64, uint64](0, Uint64Hasher)
+	for i := uint64(0); i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	count := 0
+	m.Each(func(key, value uint64) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+}
+
+func TestReserve(t *testing.T) {
+	m := New[uint64, uint64](0, Uint64Hasher)
+	m.Set(1, 1)
+
+	m.Reserve(1000)
+	assert.True(t, m.Cap() >= 1000)
+	groups := len(m.groups)
+	assert.True(t, m.Exists(1))
+
+	for i := uint64(0); i < 1000; i++ {
+		m.Set(i, i)
+	}
+	assert.Equal(t, groups, len(m.groups))
+
+	m.Reserve(10)
+	assert.Equal(t, groups, len(m.groups))
+}
+
+func TestClear(t *testing.T) {
+	m := New[string, *int](0, StringHasher())
+	for i := 0; i < 100; i++ {
+		value := i
+		m.Set(strconv.Itoa(i), &value)
+	}
+	capacity := m.Cap()
+
+	m.Clear()
+	assert.Equal(t, uint64(0), m.Len())
+	assert.Equal(t, capacity, m.Cap())
+	assert.False(t, m.Exists(`1`))
+	for _, grp := range m.groups {
+		for _, value := range grp.values {
+			assert.Nil(t, value)
+		}
+	}
+
+	m.Set(`1`, nil)
+	assert.True(t, m.Exists(`1`))
+}
+
+func BenchmarkSet(b *testing.B) {
+	keys := make([]uint64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, rand.Uint64())
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := New[uint64, uint64](1000, Uint64Hasher)
+		for _, key := range keys {
+			m.Set(key, key)
+		}
+	}
+}
+
+func BenchmarkGoMapSet(b *testing.B) {
+	keys := make([]uint64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, rand.Uint64())
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := make(map[uint64]uint64, 1000)
+		for _, key := range keys {
+			m[key] = key
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	numItems := 1000000
+	m := New[uint64, uint64](uint64(numItems), Uint64Hasher)
+	for i := 0; i < numItems; i++ {
+		m.Set(uint64(i), uint64(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Get(uint64(i % numItems))
+	}
+}
+
+func BenchmarkGoMapGet(b *testing.B) {
+	numItems := 1000000
+	m := make(map[uint64]uint64, numItems)
+	for i := 0; i < numItems; i++ {
+		m[uint64(i)] = uint64(i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = m[uint64(i%numItems)]
+	}
+}
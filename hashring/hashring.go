@@ -0,0 +1,258 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package hashring implements consistent hashing, which assigns keys to
+a changing set of members, such as the servers of a cache or the
+shards of a database, so that adding or removing a member only moves
+the keys that it gains or loses.
+
+Every member is hashed to a number of points on a ring of 64 bit
+hashes, its virtual nodes, and a key belongs to the member owning the
+first point at or after the key's hash.  Many virtual nodes per member
+even out the share of keys each member receives, and a member's weight
+multiplies its virtual nodes so that it receives a proportionally
+larger share.  GetN continues around the ring to pick distinct
+members for replicas.
+
+The points are kept in a skiplist, so lookups are O(log n) in the
+number of points and adding or removing a member touches only its own
+points.  A ring is threadsafe and lookups only take a read lock.
+
+Performance characteristics:
+Get: O(log n)
+GetN: O(log n + m) where m is the number of points passed over
+Add/Remove: O(v log n) where v is the member's virtual nodes
+Space: O(n)
+*/
+package hashring
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// point is a virtual node on the ring.  Points are ordered by their
+// hash, and colliding hashes are ordered by member and index so that
+// every point is distinct.
+type point struct {
+	hash   uint64
+	member string
+	index  int
+}
+
+func (p *point) Compare(other skip.Entry) int {
+	o := other.(*point)
+	switch {
+	case p.hash < o.hash:
+		return -1
+	case p.hash > o.hash:
+		return 1
+	case p.member < o.member:
+		return -1
+	case p.member > o.member:
+		return 1
+	}
+
+	return p.index - o.index
+}
+
+// Hash is the default hash function of a ring, 64 bit FNV-1a with its
+// bits mixed.
+func Hash(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return hashutil.Mix(h.Sum64())
+}
+
+// Ring is a consistent hash ring.
+type Ring struct {
+	lock     sync.RWMutex
+	hash     func([]byte) uint64
+	replicas int
+	// members maps every member to its weight.
+	members map[string]int
+	points  *skip.SkipList
+}
+
+// pointsOf returns the virtual nodes of a member with the provided
+// weight.
+func (r *Ring) pointsOf(member string, weight int) []skip.Entry {
+	points := make([]skip.Entry, 0, r.replicas*weight)
+	buf := make([]byte, 0, len(member)+8)
+	for i := 0; i < r.replicas*weight; i++ {
+		buf = append(buf[:0], member...)
+		buf = append(buf, '#')
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		points = append(points, &point{hash: r.hash(buf), member: member, index: i})
+	}
+
+	return points
+}
+
+func (r *Ring) remove(member string) {
+	weight, ok := r.members[member]
+	if !ok {
+		return
+	}
+
+	r.points.Delete(r.pointsOf(member, weight)...)
+	delete(r.members, member)
+}
+
+// Add adds the member to the ring with the provided weight, which
+// multiplies the number of its virtual nodes.  Adding a member already
+// on the ring changes its weight.  A weight less than 1 removes the
+// member.
+func (r *Ring) Add(member string, weight int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.remove(member)
+	if weight < 1 {
+		return
+	}
+
+	r.points.Insert(r.pointsOf(member, weight)...)
+	r.members[member] = weight
+}
+
+// Remove removes the member from the ring.  Its keys move to the
+// members following its virtual nodes.
+func (r *Ring) Remove(member string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.remove(member)
+}
+
+// Get returns the member the provided key belongs to.  Returns false
+// if the ring has no members.
+func (r *Ring) Get(key []byte) (string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.points.Len() == 0 {
+		return ``, false
+	}
+
+	p := r.points.Ceiling(r.probe(key))
+	if p == nil {
+		// past the last point, so wrap around to the first
+		p = r.points.ByPosition(0)
+	}
+
+	return p.(*point).member, true
+}
+
+// GetN returns up to n distinct members for the provided key in ring
+// order, starting with the member returned by Get.  Fewer than n are
+// returned if the ring has fewer members.  This is the usual way to
+// pick the replicas of a key.
+func (r *Ring) GetN(key []byte, n int) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	n = min(n, len(r.members))
+	if n <= 0 {
+		return nil
+	}
+
+	result := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	visit := func(e skip.Entry) bool {
+		member := e.(*point).member
+		if _, ok := seen[member]; !ok {
+			seen[member] = struct{}{}
+			result = append(result, member)
+		}
+		return len(result) < n
+	}
+
+	iter := r.points.Iter(r.probe(key))
+	for iter.Next() {
+		if !visit(iter.Value()) {
+			return result
+		}
+	}
+
+	// wrap around, every member is found before the start is reached
+	r.points.Each(visit)
+	return result
+}
+
+// probe returns an entry ordered before any point with the key's hash.
+func (r *Ring) probe(key []byte) *point {
+	return &point{hash: r.hash(key), index: -1}
+}
+
+// Members returns the members of the ring in sorted order.
+func (r *Ring) Members() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	members := make([]string, 0, len(r.members))
+	for member := range r.members {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Weight returns the weight of the member, or 0 if it is not on the
+// ring.
+func (r *Ring) Weight(member string) int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.members[member]
+}
+
+// Len returns the number of members on the ring.
+func (r *Ring) Len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return len(r.members)
+}
+
+// New returns an empty ring that gives each member replicas virtual
+// nodes per unit of weight.  More replicas even out the members'
+// shares of keys at the cost of memory, and a hundred or more is
+// typical.
+func New(replicas int) *Ring {
+	return NewWithHash(replicas, Hash)
+}
+
+// NewWithHash returns an empty ring as New does that hashes keys and
+// virtual nodes with the provided function.
+func NewWithHash(replicas int, hash func([]byte) uint64) *Ring {
+	if replicas < 1 {
+		panic(`Ring replicas must be greater than 0.`)
+	}
+
+	return &Ring{
+		hash:     hash,
+		replicas: replicas,
+		members:  make(map[string]int),
+		points:   skip.New(uint64(0)),
+	}
+}
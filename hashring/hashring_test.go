@@ -0,0 +1,211 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashring
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+func key(i int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(i))
+}
+
+func TestEmptyRing(t *testing.T) {
+	r := New(10)
+	_, ok := r.Get(key(1))
+	assert.False(t, ok)
+	assert.Nil(t, r.GetN(key(1), 3))
+	assert.Equal(t, 0, r.Len())
+	assert.Equal(t, []string{}, r.Members())
+}
+
+func TestMembers(t *testing.T) {
+	r := New(10)
+	r.Add(`b`, 1)
+	r.Add(`a`, 2)
+	r.Add(`c`, 0)
+	assert.Equal(t, []string{`a`, `b`}, r.Members())
+	assert.Equal(t, 2, r.Weight(`a`))
+	assert.Equal(t, 0, r.Weight(`c`))
+	assert.Equal(t, uint64(30), r.points.Len())
+
+	r.Add(`a`, 1)
+	assert.Equal(t, uint64(20), r.points.Len())
+	r.Remove(`a`)
+	r.Remove(`missing`)
+	assert.Equal(t, []string{`b`}, r.Members())
+	assert.Equal(t, uint64(10), r.points.Len())
+
+	for i := 0; i < 100; i++ {
+		member, ok := r.Get(key(i))
+		assert.True(t, ok)
+		assert.Equal(t, `b`, member)
+	}
+}
+
+func TestGetMatchesLinearScan(t *testing.T) {
+	r := New(20)
+	for i := 0; i < 5; i++ {
+		r.Add(strconv.Itoa(i), 1)
+	}
+
+	var points []*point
+	r.points.Each(func(e skip.Entry) bool {
+		points = append(points, e.(*point))
+		return true
+	})
+
+	for i := 0; i < 1000; i++ {
+		h := Hash(key(i))
+		expected := points[0].member
+		for _, p := range points {
+			if p.hash >= h {
+				expected = p.member
+				break
+			}
+		}
+
+		member, _ := r.Get(key(i))
+		assert.Equal(t, expected, member)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	r := New(200)
+	r.Add(`a`, 1)
+	r.Add(`b`, 1)
+	r.Add(`c`, 2)
+
+	counts := map[string]int{}
+	for i := 0; i < 40000; i++ {
+		member, _ := r.Get(key(i))
+		counts[member]++
+	}
+
+	// c has twice the weight, so should get about half of the keys
+	assert.True(t, counts[`a`] > 8000 && counts[`a`] < 12000)
+	assert.True(t, counts[`b`] > 8000 && counts[`b`] < 12000)
+	assert.True(t, counts[`c`] > 17000 && counts[`c`] < 23000)
+}
+
+func TestMinimalMovement(t *testing.T) {
+	r := New(100)
+	for i := 0; i < 10; i++ {
+		r.Add(strconv.Itoa(i), 1)
+	}
+
+	before := make([]string, 10000)
+	for i := range before {
+		before[i], _ = r.Get(key(i))
+	}
+
+	r.Remove(`3`)
+	for i := range before {
+		member, _ := r.Get(key(i))
+		if before[i] != `3` {
+			// only the removed member's keys move
+			assert.Equal(t, before[i], member)
+		} else {
+			assert.NotEqual(t, `3`, member)
+		}
+	}
+
+	r.Add(`3`, 1)
+	for i := range before {
+		member, _ := r.Get(key(i))
+		assert.Equal(t, before[i], member)
+	}
+}
+
+func TestGetN(t *testing.T) {
+	r := New(50)
+	for i := 0; i < 5; i++ {
+		r.Add(strconv.Itoa(i), 1)
+	}
+
+	for i := 0; i < 1000; i++ {
+		replicas := r.GetN(key(i), 3)
+		assert.Len(t, replicas, 3)
+		first, _ := r.Get(key(i))
+		assert.Equal(t, first, replicas[0])
+
+		seen := map[string]bool{}
+		for _, member := range replicas {
+			assert.False(t, seen[member])
+			seen[member] = true
+		}
+	}
+
+	assert.Len(t, r.GetN(key(1), 10), 5)
+	assert.Nil(t, r.GetN(key(1), 0))
+}
+
+func TestCustomHash(t *testing.T) {
+	// every key and point hashes to zero, so keys go to the lowest
+	// ordered point
+	r := NewWithHash(3, func([]byte) uint64 { return 0 })
+	r.Add(`b`, 1)
+	r.Add(`a`, 1)
+	member, _ := r.Get(key(1))
+	assert.Equal(t, `a`, member)
+	assert.Equal(t, []string{`a`, `b`}, r.GetN(key(1), 2))
+
+	assert.Panics(t, func() { New(0) })
+}
+
+func TestConcurrent(t *testing.T) {
+	r := New(50)
+	r.Add(`a`, 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_, ok := r.Get(key(j))
+				assert.True(t, ok)
+				r.GetN(key(j), 2)
+			}
+		}(i)
+	}
+
+	for i := 0; i < 20; i++ {
+		r.Add(strconv.Itoa(i), 1)
+		r.Remove(strconv.Itoa(i))
+	}
+	wg.Wait()
+}
+
+func BenchmarkGet(b *testing.B) {
+	r := New(100)
+	for i := 0; i < 100; i++ {
+		r.Add(strconv.Itoa(i), 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(key(i))
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Sketches are serialized as an 8 byte header, holding a version byte,
+// a representation byte, the precision, a reserved zero byte and the
+// length of the payload as a little-endian uint32, followed by the
+// payload.  A sparse sketch's payload is its sorted entries, each
+// written as the uvarint difference from the one before.  A dense
+// sketch's payload is its registers packed six bits apiece.
+
+// Deserialize rejects sketches whose encodingVersion differs.
+const encodingVersion = 1
+
+const (
+	sparseEncoding byte = iota + 1
+	denseEncoding
+)
+
+const (
+	headerSize   = 8
+	registerBits = 6
+)
+
+// Serialize writes this sketch to the provided writer.
+func (h *HyperLogLog) Serialize(w io.Writer) error {
+	var kind byte
+	var payload []byte
+	if h.registers == nil {
+		h.flush()
+	}
+	if h.registers == nil {
+		kind, payload = sparseEncoding, encodeSparse(h.sparse)
+	} else {
+		kind, payload = denseEncoding, encodeDense(h.registers)
+	}
+
+	header := make([]byte, headerSize)
+	header[0], header[1], header[2] = encodingVersion, kind, h.p
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+}
+
+func encodeSparse(sparse []uint32) []byte {
+	payload := make([]byte, 0, len(sparse)*2)
+	buf := make([]byte, binary.MaxVarintLen32)
+	prev := uint32(0)
+	for _, e := range sparse {
+		n := binary.PutUvarint(buf, uint64(e-prev))
+		payload = append(payload, buf[:n]...)
+		prev = e
+	}
+	return payload
+}
+
+func encodeDense(registers []uint8) []byte {
+	payload := make([]byte, (len(registers)*registerBits+7)/8)
+	for i, r := range registers {
+		bit := i * registerBits
+		word := uint16(r) << uint(bit%8)
+		payload[bit/8] |= byte(word)
+		if b := bit/8 + 1; b < len(payload) {
+			payload[b] |= byte(word >> 8)
+		}
+	}
+	return payload
+}
+
+// Deserialize reads a sketch written by HyperLogLog.Serialize.
+func Deserialize(r io.Reader) (*HyperLogLog, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if header[0] != encodingVersion {
+		return nil, fmt.Errorf(`Unknown encoding version %d.`, header[0])
+	}
+
+	p := header[2]
+	if p < MinPrecision || p > MaxPrecision {
+		return nil, fmt.Errorf(`Invalid precision %d.`, p)
+	}
+
+	h := New(p)
+	size := binary.LittleEndian.Uint32(header[4:])
+	switch header[1] {
+	case sparseEncoding:
+		// each entry takes at least a byte, and there can't be more
+		// entries than the sparse limit
+		if int(size) > h.sparseLimit()*binary.MaxVarintLen32 {
+			return nil, fmt.Errorf(`Sparse sketch of %d bytes is too large.`, size)
+		}
+	case denseEncoding:
+		if int(size) != (h.m()*registerBits+7)/8 {
+			return nil, fmt.Errorf(`Dense sketch of %d bytes doesn't match precision %d.`, size, p)
+		}
+	default:
+		return nil, fmt.Errorf(`Unknown sketch representation %d.`, header[1])
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	var err error
+	if header[1] == sparseEncoding {
+		h.sparse, err = decodeSparse(payload, h.sparseLimit())
+	} else {
+		h.registers, err = decodeDense(payload, h.m(), p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func decodeSparse(payload []byte, limit int) ([]uint32, error) {
+	var sparse []uint32
+	prev := uint64(0)
+	for len(payload) > 0 {
+		delta, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return nil, fmt.Errorf(`Invalid sparse entry.`)
+		}
+		payload = payload[n:]
+
+		e := prev + delta
+		if e >= 1<<(sparsePrecision+rankBits) {
+			return nil, fmt.Errorf(`Sparse entry %d is out of range.`, e)
+		}
+		if r := e & rankMask; r == 0 || r > maxSparseRank {
+			return nil, fmt.Errorf(`Sparse entry %d has invalid rank %d.`, e, r)
+		}
+		if len(sparse) > 0 && sparseIndex(uint32(e)) <= sparseIndex(sparse[len(sparse)-1]) {
+			return nil, fmt.Errorf(`Sparse entries are not sorted.`)
+		}
+		if len(sparse) == limit {
+			return nil, fmt.Errorf(`Sparse sketch has more than %d entries.`, limit)
+		}
+
+		sparse = append(sparse, uint32(e))
+		prev = e
+	}
+
+	return sparse, nil
+}
+
+func decodeDense(payload []byte, m int, p uint8) ([]uint8, error) {
+	registers := make([]uint8, m)
+	for i := range registers {
+		bit := i * registerBits
+		word := uint16(payload[bit/8])
+		if b := bit/8 + 1; b < len(payload) {
+			word |= uint16(payload[b]) << 8
+		}
+
+		r := uint8(word>>uint(bit%8)) & (1<<registerBits - 1)
+		if r > 64-p+1 {
+			return nil, fmt.Errorf(`Register %d has invalid rank %d.`, i, r)
+		}
+		registers[i] = r
+	}
+
+	return registers, nil
+}
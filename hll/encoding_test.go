@@ -0,0 +1,95 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func roundTrip(t *testing.T, h *HyperLogLog) ([]byte, *HyperLogLog) {
+	var buf bytes.Buffer
+	assert.Nil(t, h.Serialize(&buf))
+	data := buf.Bytes()
+
+	result, err := Deserialize(bytes.NewReader(data))
+	assert.Nil(t, err)
+	return data, result
+}
+
+func TestSerializeSparse(t *testing.T) {
+	h := New(14)
+	addRange(h, 0, 100)
+
+	data, result := roundTrip(t, h)
+	assert.Nil(t, result.registers)
+	assert.Equal(t, h.sparse, result.sparse)
+	assert.Equal(t, h.Count(), result.Count())
+	assert.Equal(t, h.Precision(), result.Precision())
+	// far smaller than the registers
+	assert.True(t, len(data) < 500, `%d bytes`, len(data))
+
+	_, result = roundTrip(t, New(4))
+	assert.Equal(t, uint64(0), result.Count())
+
+	_, err := Deserialize(bytes.NewReader(data[:len(data)-1]))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	bad := append([]byte{}, data...)
+	bad[0]++
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+
+	bad = append([]byte{}, data...)
+	bad[2] = MaxPrecision + 1
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+
+	// a zero delta repeats an entry
+	bad = append(append([]byte{}, data...), 0)
+	bad[4]++
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+}
+
+func TestSerializeDense(t *testing.T) {
+	h := New(10)
+	addRange(h, 0, 10000)
+
+	data, result := roundTrip(t, h)
+	assert.Equal(t, h.registers, result.registers)
+	assert.Equal(t, h.Count(), result.Count())
+	assert.Len(t, data, headerSize+1024*registerBits/8)
+
+	bad := append([]byte{}, data...)
+	bad[1] = 9
+	_, err := Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+
+	bad = append([]byte{}, data...)
+	bad[4]++
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+
+	// a register holding the largest six bit value
+	bad = append([]byte{}, data...)
+	bad[headerSize] |= 1<<registerBits - 1
+	_, err = Deserialize(bytes.NewReader(bad))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import "fmt"
+
+// MismatchError is returned when merging sketches with different
+// precisions.
+type MismatchError struct {
+	p, otherP uint8
+}
+
+func (me MismatchError) Error() string {
+	return fmt.Sprintf(`Cannot merge a sketch of precision %d into a sketch of precision %d.`,
+		me.otherP, me.p)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import "math"
+
+// linearCount estimates the number of distinct items hashed into m
+// buckets from the number of buckets left empty.
+func linearCount(m, empty int) uint64 {
+	if empty == 0 {
+		// the estimate is infinite; this can't happen with the sparse
+		// list since it switches to registers long before
+		return uint64(m)
+	}
+
+	return uint64(math.Round(float64(m) * math.Log(float64(m)/float64(empty))))
+}
+
+// estimate returns the estimated cardinality of the provided
+// registers using the improved estimator from Ertl's "New cardinality
+// estimation algorithms for HyperLogLog sketches", which corrects the
+// raw estimate for both empty and saturated registers.
+func estimate(registers []uint8, p uint8) float64 {
+	q := 64 - int(p)
+	counts := make([]int, q+2)
+	for _, r := range registers {
+		counts[r]++
+	}
+
+	m := float64(len(registers))
+	z := m * tau(1-float64(counts[q+1])/m)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + float64(counts[k]))
+	}
+	z += m * sigma(float64(counts[0])/m)
+
+	return m * m / (2 * math.Ln2 * z)
+}
+
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
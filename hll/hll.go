@@ -0,0 +1,223 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/*
+Package hll implements HyperLogLog++, a sketch that estimates the number
+of distinct items added to it in a small, fixed amount of memory.  Each
+item is hashed to 64 bits; the top p bits pick one of m = 2^p registers
+and the register keeps the longest run of leading zeros seen in the
+remaining bits.  The standard error of the estimate is about
+1.04 / sqrt(m), so a precision of 14 uses 16KB and is accurate to
+within a percent or so.
+
+Following HyperLogLog++, hashes are 64 bits wide so no large range
+correction is needed, and small sketches start out in a sparse
+representation that keeps 25 bits of each hash, giving near exact
+counts for small cardinalities in far less memory than the registers.
+The sketch switches to registers once the sparse list would be larger.
+In place of HyperLogLog++'s empirically derived bias correction tables,
+dense sketches are estimated with Ertl's improved estimator, which is
+unbiased across the whole range of cardinalities without tables.
+
+Sketches of the same precision can be merged, which gives the number of
+distinct items in the union of their inputs, and can be serialized.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: O(m)
+Add: O(1) amortized
+Count: O(m)
+Merge: O(m)
+*/
+package hll
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+const (
+	// MinPrecision is the smallest precision a sketch can have.
+	MinPrecision = 4
+	// MaxPrecision is the largest precision a sketch can have.
+	MaxPrecision = 18
+)
+
+// HyperLogLog is a sketch of the number of distinct items added to it.
+type HyperLogLog struct {
+	p uint8
+	// registers is nil while the sketch is sparse.
+	registers []uint8
+	// sparse holds sorted entries with one per sparse index while tmp
+	// buffers new entries until they're merged in.
+	sparse, tmp []uint32
+}
+
+func hash(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return hashutil.Mix(h.Sum64())
+}
+
+// rank returns one more than the number of leading zeros in w, whose
+// low p bits are ignored.
+func rank(w uint64, p uint8) uint8 {
+	return uint8(bits.LeadingZeros64(w|1<<(p-1))) + 1
+}
+
+// Add adds the provided item to the sketch.
+func (h *HyperLogLog) Add(data []byte) {
+	x := hash(data)
+	if h.registers != nil {
+		h.set(uint32(x>>(64-h.p)), rank(x<<h.p, h.p))
+		return
+	}
+
+	h.addEntry(encodeEntry(x))
+}
+
+func (h *HyperLogLog) set(index uint32, r uint8) {
+	if r > h.registers[index] {
+		h.registers[index] = r
+	}
+}
+
+// addEntry adds a sparse entry to the sketch, whichever representation
+// it is in.
+func (h *HyperLogLog) addEntry(e uint32) {
+	if h.registers != nil {
+		h.set(decodeEntry(e, h.p))
+		return
+	}
+
+	h.tmp = append(h.tmp, e)
+	if len(h.tmp) >= h.tmpLimit() {
+		h.flush()
+	}
+}
+
+func (h *HyperLogLog) m() int {
+	return 1 << h.p
+}
+
+// tmpLimit is the number of entries buffered before they're merged
+// into the sparse list.
+func (h *HyperLogLog) tmpLimit() int {
+	return h.m()/16 + 1
+}
+
+// sparseLimit is the number of sparse entries, four bytes each, at
+// which the registers take up less memory.
+func (h *HyperLogLog) sparseLimit() int {
+	return h.m() / 4
+}
+
+// flush merges buffered entries into the sparse list, switching to
+// registers if the list has grown too large.
+func (h *HyperLogLog) flush() {
+	if len(h.tmp) == 0 {
+		return
+	}
+
+	h.sparse = mergeEntries(h.sparse, h.tmp)
+	h.tmp = h.tmp[:0]
+	if len(h.sparse) > h.sparseLimit() {
+		h.toDense()
+	}
+}
+
+// toDense switches the sketch to registers.
+func (h *HyperLogLog) toDense() {
+	if h.registers != nil {
+		return
+	}
+
+	h.registers = make([]uint8, h.m())
+	for _, e := range h.sparse {
+		h.set(decodeEntry(e, h.p))
+	}
+	for _, e := range h.tmp {
+		h.set(decodeEntry(e, h.p))
+	}
+	h.sparse, h.tmp = nil, nil
+}
+
+// Count returns the estimated number of distinct items added to the
+// sketch.
+func (h *HyperLogLog) Count() uint64 {
+	if h.registers == nil {
+		h.flush()
+		if h.registers == nil {
+			return linearCount(1<<sparsePrecision, 1<<sparsePrecision-len(h.sparse))
+		}
+	}
+
+	return uint64(math.Round(estimate(h.registers, h.p)))
+}
+
+// Merge adds every item added to other to this sketch.  Both sketches
+// must have the same precision.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if other.p != h.p {
+		return MismatchError{p: h.p, otherP: other.p}
+	}
+
+	if other == h {
+		return nil
+	}
+
+	if other.registers == nil {
+		for _, e := range other.sparse {
+			h.addEntry(e)
+		}
+		for _, e := range other.tmp {
+			h.addEntry(e)
+		}
+		return nil
+	}
+
+	h.toDense()
+	for i, r := range other.registers {
+		h.set(uint32(i), r)
+	}
+	return nil
+}
+
+// Reset removes every item from the sketch, returning it to the sparse
+// representation.
+func (h *HyperLogLog) Reset() {
+	h.registers = nil
+	h.sparse = h.sparse[:0]
+	h.tmp = h.tmp[:0]
+}
+
+// Precision returns the number of hash bits used to pick a register.
+func (h *HyperLogLog) Precision() uint8 {
+	return h.p
+}
+
+// New returns an empty sketch with 2^precision registers.  precision
+// must be between MinPrecision and MaxPrecision.
+func New(precision uint8) *HyperLogLog {
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic(`Precision must be between 4 and 18.`)
+	}
+
+	return &HyperLogLog{p: precision}
+}
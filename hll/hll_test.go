@@ -0,0 +1,151 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addRange(h *HyperLogLog, start, stop int) {
+	for i := start; i < stop; i++ {
+		h.Add([]byte(strconv.Itoa(i)))
+	}
+}
+
+// assertWithin checks that count is within the provided relative error
+// of expected.
+func assertWithin(t *testing.T, expected int, count uint64, relative float64) {
+	err := math.Abs(float64(count)-float64(expected)) / float64(expected)
+	assert.True(t, err <= relative, `expected %d, counted %d`, expected, count)
+}
+
+func TestEmpty(t *testing.T) {
+	h := New(14)
+	assert.Equal(t, uint64(0), h.Count())
+
+	h.toDense()
+	assert.Equal(t, uint64(0), h.Count())
+}
+
+func TestSmallCounts(t *testing.T) {
+	h := New(14)
+	h.Add([]byte(`a`))
+	h.Add([]byte(`a`))
+	assert.Equal(t, uint64(1), h.Count())
+
+	addRange(h, 0, 100)
+	addRange(h, 0, 100)
+	assert.Nil(t, h.registers)
+	assert.Equal(t, uint64(101), h.Count())
+}
+
+func TestCount(t *testing.T) {
+	for _, p := range []uint8{MinPrecision, 10, 14, MaxPrecision} {
+		h := New(p)
+		// allow for four standard errors
+		relative := 4 * 1.04 / math.Sqrt(float64(uint64(1)<<p))
+		total := 0
+		for _, n := range []int{10, 1000, 20000, 200000} {
+			addRange(h, total, n)
+			total = n
+			assertWithin(t, n, h.Count(), math.Max(relative, 0.01))
+		}
+		assert.NotNil(t, h.registers)
+	}
+}
+
+func TestSparseToDense(t *testing.T) {
+	h := New(10)
+	n := 0
+	for h.registers == nil {
+		h.Add([]byte(strconv.Itoa(n)))
+		n++
+	}
+
+	// entries are buffered before the sparse list is checked
+	assert.True(t, n > h.sparseLimit() && n <= h.sparseLimit()+h.tmpLimit())
+	assertWithin(t, n, h.Count(), 0.1)
+}
+
+func TestMerge(t *testing.T) {
+	sparse := New(12)
+	addRange(sparse, 0, 500)
+	dense := New(12)
+	addRange(dense, 250, 50000)
+
+	h := New(12)
+	assert.Nil(t, h.Merge(sparse))
+	assert.Nil(t, h.registers)
+	assertWithin(t, 500, h.Count(), 0.01)
+
+	assert.Nil(t, h.Merge(dense))
+	assertWithin(t, 50000, h.Count(), 0.1)
+
+	assert.Nil(t, dense.Merge(sparse))
+	assert.Equal(t, h.Count(), dense.Count())
+
+	assert.Nil(t, sparse.Merge(sparse))
+	assertWithin(t, 500, sparse.Count(), 0.01)
+
+	err := h.Merge(New(10))
+	assert.IsType(t, MismatchError{}, err)
+}
+
+func TestReset(t *testing.T) {
+	h := New(8)
+	addRange(h, 0, 1000)
+	h.Reset()
+
+	assert.Nil(t, h.registers)
+	assert.Equal(t, uint64(0), h.Count())
+	addRange(h, 0, 10)
+	assert.Equal(t, uint64(10), h.Count())
+}
+
+func TestNewPanics(t *testing.T) {
+	assert.Panics(t, func() { New(MinPrecision - 1) })
+	assert.Panics(t, func() { New(MaxPrecision + 1) })
+	assert.Equal(t, uint8(MaxPrecision), New(MaxPrecision).Precision())
+}
+
+func BenchmarkAdd(b *testing.B) {
+	h := New(14)
+	keys := make([][]byte, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, []byte(strconv.Itoa(i)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.Add(keys[i])
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	h := New(14)
+	addRange(h, 0, 100000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.Count()
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import "sort"
+
+// While a sketch is sparse each item is kept as an entry holding the
+// top sparsePrecision bits of its hash, the sparse index, followed by
+// the rank of the remaining bits in the low rankBits bits.  Entries
+// sort by sparse index and then rank.  An entry holds everything
+// needed to find the register and rank the item would have at any
+// lower precision.
+
+const (
+	sparsePrecision = 25
+	rankBits        = 6
+	rankMask        = 1<<rankBits - 1
+	// maxSparseRank is the largest rank of the bits after the sparse
+	// index.
+	maxSparseRank = 64 - sparsePrecision + 1
+)
+
+func encodeEntry(x uint64) uint32 {
+	index := uint32(x >> (64 - sparsePrecision))
+	return index<<rankBits | uint32(rank(x<<sparsePrecision, sparsePrecision))
+}
+
+func sparseIndex(e uint32) uint32 {
+	return e >> rankBits
+}
+
+// decodeEntry returns the register and rank of the provided entry in a
+// sketch of precision p.
+func decodeEntry(e uint32, p uint8) (uint32, uint8) {
+	index := sparseIndex(e)
+	extra := uint8(sparsePrecision - p)
+	low := index & (1<<extra - 1)
+	if low == 0 {
+		// the bits between the register and the end of the sparse
+		// index are all zeros, so they're added to the stored rank
+		return index >> extra, extra + uint8(e&rankMask)
+	}
+
+	// the rank falls within the sparse index
+	return index >> extra, uint8(leadingZeros(low, extra)) + 1
+}
+
+// leadingZeros returns the number of leading zeros in the low n bits
+// of x.
+func leadingZeros(x uint32, n uint8) int {
+	zeros := 0
+	for i := int(n) - 1; i >= 0 && x&(1<<uint(i)) == 0; i-- {
+		zeros++
+	}
+	return zeros
+}
+
+// mergeEntries returns the sorted entries of sparse and tmp with only
+// the highest rank for each sparse index.  sparse must already be
+// sorted and deduplicated; tmp is sorted in place.
+func mergeEntries(sparse, tmp []uint32) []uint32 {
+	sort.Slice(tmp, func(i, j int) bool { return tmp[i] < tmp[j] })
+
+	merged := make([]uint32, 0, len(sparse)+len(tmp))
+	push := func(e uint32) {
+		last := len(merged) - 1
+		if last >= 0 && sparseIndex(merged[last]) == sparseIndex(e) {
+			if e > merged[last] {
+				merged[last] = e
+			}
+			return
+		}
+		merged = append(merged, e)
+	}
+
+	i, j := 0, 0
+	for i < len(sparse) && j < len(tmp) {
+		if sparse[i] <= tmp[j] {
+			push(sparse[i])
+			i++
+		} else {
+			push(tmp[j])
+			j++
+		}
+	}
+	for ; i < len(sparse); i++ {
+		push(sparse[i])
+	}
+	for ; j < len(tmp); j++ {
+		push(tmp[j])
+	}
+
+	return merged
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hll
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeEntry(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	hashes := []uint64{0, 1, 1<<64 - 1, 1 << 63, 1 << 39, 1 << 38}
+	for i := 0; i < 1000; i++ {
+		hashes = append(hashes, r.Uint64())
+	}
+
+	for p := uint8(MinPrecision); p <= MaxPrecision; p++ {
+		for _, x := range hashes {
+			index, rnk := decodeEntry(encodeEntry(x), p)
+			assert.Equal(t, uint32(x>>(64-p)), index)
+			assert.Equal(t, rank(x<<p, p), rnk)
+		}
+	}
+}
+
+func TestMergeEntries(t *testing.T) {
+	sparse := []uint32{1<<rankBits | 2, 3<<rankBits | 1}
+	tmp := []uint32{5<<rankBits | 1, 1<<rankBits | 4, 3<<rankBits | 1, 0<<rankBits | 7}
+
+	merged := mergeEntries(sparse, tmp)
+	assert.Equal(t, []uint32{0<<rankBits | 7, 1<<rankBits | 4, 3<<rankBits | 1, 5<<rankBits | 1}, merged)
+	assert.Equal(t, []uint32{0<<rankBits | 7}, mergeEntries(nil, []uint32{0<<rankBits | 7}))
+}
+
+func TestLeadingZeros(t *testing.T) {
+	assert.Equal(t, 0, leadingZeros(4, 3))
+	assert.Equal(t, 2, leadingZeros(1, 3))
+	assert.Equal(t, 3, leadingZeros(0, 3))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedmap
+
+import "cmp"
+
+// Iterator walks the keys of a map in ascending order.  Because maps
+// are immutable, an iterator is unaffected by maps derived from the
+// one it was taken from.
+type Iterator[K cmp.Ordered, V any] struct {
+	stack   []*node[K, V]
+	current *node[K, V]
+}
+
+func (it *Iterator[K, V]) pushLeft(n *node[K, V]) {
+	for n != nil {
+		it.stack = append(it.stack, n)
+		n = n.left
+	}
+}
+
+// Next moves the iterator to the next key and returns false once
+// there are no more keys.  Next must be called before the first
+// key is read.
+func (it *Iterator[K, V]) Next() bool {
+	if len(it.stack) == 0 {
+		it.current = nil
+		return false
+	}
+
+	it.current = it.stack[len(it.stack)-1]
+	it.stack = it.stack[:len(it.stack)-1]
+	it.pushLeft(it.current.right)
+	return true
+}
+
+// Key returns the key the iterator is at.
+func (it *Iterator[K, V]) Key() K {
+	if it.current == nil {
+		var key K
+		return key
+	}
+	return it.current.key
+}
+
+// Value returns the value the iterator is at.
+func (it *Iterator[K, V]) Value() V {
+	if it.current == nil {
+		var value V
+		return value
+	}
+	return it.current.value
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIter(t *testing.T) {
+	m := New[int, int]()
+	for i := 9; i >= 0; i-- {
+		m = m.Set(i, i*2)
+	}
+
+	it := m.Iter()
+	for i := 0; i < 10; i++ {
+		assert.True(t, it.Next())
+		assert.Equal(t, i, it.Key())
+		assert.Equal(t, i*2, it.Value())
+	}
+	assert.False(t, it.Next())
+	assert.Equal(t, 0, it.Key())
+}
+
+func TestIterEmpty(t *testing.T) {
+	m := New[int, int]()
+	assert.False(t, m.Iter().Next())
+	assert.False(t, m.IterFrom(5).Next())
+}
+
+func TestIterFrom(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i += 10 {
+		m = m.Set(i, i)
+	}
+
+	keys := []int{}
+	it := m.IterFrom(35)
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	assert.Equal(t, []int{40, 50, 60, 70, 80, 90}, keys)
+
+	it = m.IterFrom(40)
+	assert.True(t, it.Next())
+	assert.Equal(t, 40, it.Key())
+
+	assert.False(t, m.IterFrom(91).Next())
+
+	it = m.IterFrom(-1)
+	assert.True(t, it.Next())
+	assert.Equal(t, 0, it.Key())
+}
+
+func TestAll(t *testing.T) {
+	m := New[string, int]().Set(`b`, 2).Set(`a`, 1).Set(`c`, 3)
+
+	var keys []string
+	var values []int
+	for key, value := range m.All() {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	assert.Equal(t, []string{`a`, `b`, `c`}, keys)
+	assert.Equal(t, []int{1, 2, 3}, values)
+
+	keys = keys[:0]
+	for key := range m.Keys() {
+		if key == `c` {
+			break
+		}
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{`a`, `b`}, keys)
+
+	values = values[:0]
+	for value := range m.Values() {
+		values = append(values, value)
+	}
+	assert.Equal(t, []int{1, 2, 3}, values)
+}
+
+func TestIterSnapshot(t *testing.T) {
+	m := New[int, int]().Set(1, 1).Set(2, 2).Set(3, 3)
+	it := m.Iter()
+	assert.True(t, it.Next())
+
+	m.Delete(2).Set(4, 4)
+
+	keys := []int{it.Key()}
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	assert.Equal(t, []int{1, 2, 3}, keys)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedmap
+
+import "cmp"
+
+// node is a node of a persistent AVL tree.  Nodes are never modified
+// once they are reachable from a map.
+type node[K cmp.Ordered, V any] struct {
+	key         K
+	value       V
+	left, right *node[K, V]
+	height      int8
+	size        int
+}
+
+func (n *node[K, V]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[K, V]) depth() int8 {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *node[K, V]) entry() (K, V, bool) {
+	if n == nil {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
+
+// newNode returns a node with the provided children, which must
+// already be balanced with respect to each other.
+func newNode[K cmp.Ordered, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	return &node[K, V]{
+		key:    key,
+		value:  value,
+		left:   left,
+		right:  right,
+		height: max(left.depth(), right.depth()) + 1,
+		size:   left.len() + right.len() + 1,
+	}
+}
+
+// balance returns a node with the provided children, rotating if
+// their heights differ by two.
+func balance[K cmp.Ordered, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	switch hl, hr := left.depth(), right.depth(); {
+	case hl > hr+1:
+		if left.left.depth() >= left.right.depth() {
+			return newNode(left.key, left.value, left.left,
+				newNode(key, value, left.right, right))
+		}
+		lr := left.right
+		return newNode(lr.key, lr.value,
+			newNode(left.key, left.value, left.left, lr.left),
+			newNode(key, value, lr.right, right))
+	case hr > hl+1:
+		if right.right.depth() >= right.left.depth() {
+			return newNode(right.key, right.value,
+				newNode(key, value, left, right.left), right.right)
+		}
+		rl := right.left
+		return newNode(rl.key, rl.value,
+			newNode(key, value, left, rl.left),
+			newNode(right.key, right.value, rl.right, right.right))
+	}
+
+	return newNode(key, value, left, right)
+}
+
+// insert returns a copy of the tree rooted at n in which key holds
+// value.
+func (n *node[K, V]) insert(key K, value V) *node[K, V] {
+	if n == nil {
+		return newNode[K, V](key, value, nil, nil)
+	}
+
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		return balance(n.key, n.value, n.left.insert(key, value), n.right)
+	case c > 0:
+		return balance(n.key, n.value, n.left, n.right.insert(key, value))
+	}
+
+	return newNode(key, value, n.left, n.right)
+}
+
+// delete returns a copy of the tree rooted at n without key.  The
+// second return value is false, and no nodes are copied, if key was
+// not found.
+func (n *node[K, V]) delete(key K) (*node[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	switch c := cmp.Compare(key, n.key); {
+	case c < 0:
+		left, ok := n.left.delete(key)
+		if !ok {
+			return n, false
+		}
+		return balance(n.key, n.value, left, n.right), true
+	case c > 0:
+		right, ok := n.right.delete(key)
+		if !ok {
+			return n, false
+		}
+		return balance(n.key, n.value, n.left, right), true
+	}
+
+	switch {
+	case n.left == nil:
+		return n.right, true
+	case n.right == nil:
+		return n.left, true
+	}
+
+	successor := n.right
+	for successor.left != nil {
+		successor = successor.left
+	}
+	return balance(successor.key, successor.value, n.left, n.right.deleteMin()), true
+}
+
+func (n *node[K, V]) deleteMin() *node[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+	return balance(n.key, n.value, n.left.deleteMin(), n.right)
+}
+
+func (n *node[K, V]) each(fn func(key K, value V) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.left.each(fn) && fn(n.key, n.value) && n.right.each(fn)
+}
+
+// build returns a perfectly balanced tree holding the provided
+// entries, which must be sorted.
+func build[K cmp.Ordered, V any](entries []Entry[K, V]) *node[K, V] {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	mid := len(entries) / 2
+	return newNode(entries[mid].Key, entries[mid].Value,
+		build(entries[:mid]), build(entries[mid+1:]))
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package sortedmap implements an immutable ordered map backed by a
+persistent AVL tree.  Set and Delete never modify a map; they return a
+new map that shares every node off the path to the changed key with
+the map it was derived from.  Any version can therefore be kept around
+as a snapshot for as long as it is needed, and maps are safe to read
+from many goroutines at once.
+
+Keys are ordered with cmp.Compare.  Every node records the size of its
+subtree so that Len is constant time.
+
+Performance characteristics:
+Get: O(log n)
+Set: O(log n) time and space
+Delete: O(log n) time and space
+Floor/Ceiling: O(log n)
+FromSorted: O(n)
+Iteration: O(log n) to start, O(1) amortized per key
+Space: O(n)
+*/
+package sortedmap
+
+import (
+	"cmp"
+	"errors"
+	"iter"
+)
+
+// ErrUnsorted is returned by FromSorted when the provided entries are
+// not in strictly ascending key order.
+var ErrUnsorted = errors.New(`Entries are not in strictly ascending order.`)
+
+// Entry is a key and its value.
+type Entry[K cmp.Ordered, V any] struct {
+	Key   K
+	Value V
+}
+
+// Map is an immutable ordered map.  The zero value is an empty map
+// ready to use.
+type Map[K cmp.Ordered, V any] struct {
+	root *node[K, V]
+}
+
+// Len returns the number of keys in the map.
+func (m *Map[K, V]) Len() int {
+	return m.root.len()
+}
+
+// Get returns the value stored under key and whether it was found.
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	n := m.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.value, true
+		}
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set returns a map in which key holds value.  This map is
+// unchanged.
+func (m *Map[K, V]) Set(key K, value V) *Map[K, V] {
+	return &Map[K, V]{root: m.root.insert(key, value)}
+}
+
+// Delete returns a map without key.  If key is not in this map, this
+// map is returned as is.
+func (m *Map[K, V]) Delete(key K) *Map[K, V] {
+	root, ok := m.root.delete(key)
+	if !ok {
+		return m
+	}
+
+	return &Map[K, V]{root: root}
+}
+
+// Floor returns the greatest key less than or equal to key along with
+// its value.  The final return value is false if there is no such
+// key.
+func (m *Map[K, V]) Floor(key K) (K, V, bool) {
+	var found *node[K, V]
+	n := m.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			found = n
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+
+	return found.entry()
+}
+
+// Ceiling returns the least key greater than or equal to key along
+// with its value.  The final return value is false if there is no
+// such key.
+func (m *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	var found *node[K, V]
+	n := m.root
+	for n != nil {
+		switch c := cmp.Compare(key, n.key); {
+		case c < 0:
+			found = n
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.key, n.value, true
+		}
+	}
+
+	return found.entry()
+}
+
+// Each calls fn for every key and value in ascending key order.
+// Iteration stops if fn returns false.
+func (m *Map[K, V]) Each(fn func(key K, value V) bool) {
+	m.root.each(fn)
+}
+
+// All returns a sequence of every key and value in ascending key
+// order for use with range.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.root.each(yield)
+	}
+}
+
+// Keys returns a sequence of every key in ascending order for use
+// with range.
+func (m *Map[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.root.each(func(key K, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns a sequence of every value in ascending key order for
+// use with range.
+func (m *Map[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.root.each(func(_ K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// Iter returns an iterator over every key in ascending order.
+func (m *Map[K, V]) Iter() *Iterator[K, V] {
+	it := &Iterator[K, V]{}
+	it.pushLeft(m.root)
+	return it
+}
+
+// IterFrom returns an iterator over every key greater than or equal
+// to start in ascending order.
+func (m *Map[K, V]) IterFrom(start K) *Iterator[K, V] {
+	it := &Iterator[K, V]{}
+	n := m.root
+	for n != nil {
+		if cmp.Compare(n.key, start) >= 0 {
+			it.stack = append(it.stack, n)
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+
+	return it
+}
+
+// Entries returns every key and value in ascending key order.
+func (m *Map[K, V]) Entries() []Entry[K, V] {
+	entries := make([]Entry[K, V], 0, m.Len())
+	m.Each(func(key K, value V) bool {
+		entries = append(entries, Entry[K, V]{Key: key, Value: value})
+		return true
+	})
+
+	return entries
+}
+
+// FromSorted builds a map from entries that are in strictly ascending
+// key order in linear time.  ErrUnsorted is returned otherwise.
+func FromSorted[K cmp.Ordered, V any](entries []Entry[K, V]) (*Map[K, V], error) {
+	for i := 1; i < len(entries); i++ {
+		if cmp.Compare(entries[i-1].Key, entries[i].Key) >= 0 {
+			return nil, ErrUnsorted
+		}
+	}
+
+	return &Map[K, V]{root: build(entries)}, nil
+}
+
+// New returns an empty map.
+func New[K cmp.Ordered, V any]() *Map[K, V] {
+	return &Map[K, V]{}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedmap
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkNode verifies the AVL and ordering invariants below n and
+// returns its height and size.
+func checkNode(t *testing.T, n *node[int, int]) (int8, int) {
+	if n == nil {
+		return 0, 0
+	}
+
+	if n.left != nil {
+		assert.True(t, n.left.key < n.key)
+	}
+	if n.right != nil {
+		assert.True(t, n.right.key > n.key)
+	}
+
+	hl, sl := checkNode(t, n.left)
+	hr, sr := checkNode(t, n.right)
+	assert.True(t, hl-hr <= 1 && hr-hl <= 1)
+	assert.Equal(t, max(hl, hr)+1, n.height)
+	assert.Equal(t, sl+sr+1, n.size)
+	return n.height, n.size
+}
+
+func checkMap(t *testing.T, m *Map[int, int], expected map[int]int) {
+	checkNode(t, m.root)
+	assert.Equal(t, len(expected), m.Len())
+
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	result := make([]int, 0, len(keys))
+	m.Each(func(key, value int) bool {
+		assert.Equal(t, expected[key], value)
+		result = append(result, key)
+		return true
+	})
+	assert.Equal(t, keys, result)
+}
+
+func TestZeroValue(t *testing.T) {
+	var m Map[string, int]
+	assert.Equal(t, 0, m.Len())
+
+	_, ok := m.Get(`a`)
+	assert.False(t, ok)
+
+	m2 := m.Set(`a`, 1)
+	assert.Equal(t, 1, m2.Len())
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestSetGet(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i*10)
+	}
+
+	for i := 0; i < 100; i++ {
+		value, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i*10, value)
+	}
+
+	_, ok := m.Get(100)
+	assert.False(t, ok)
+	checkNode(t, m.root)
+	assert.Equal(t, 100, m.Len())
+}
+
+func TestSetOverwrite(t *testing.T) {
+	m := New[int, int]().Set(1, 1)
+	m2 := m.Set(1, 2)
+
+	value, _ := m.Get(1)
+	assert.Equal(t, 1, value)
+	value, _ = m2.Get(1)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, m2.Len())
+}
+
+func TestPersistence(t *testing.T) {
+	versions := []*Map[int, int]{New[int, int]()}
+	expected := []map[int]int{{}}
+	r := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 200; i++ {
+		m := versions[len(versions)-1]
+		e := make(map[int]int, len(expected[len(expected)-1]))
+		for k, v := range expected[len(expected)-1] {
+			e[k] = v
+		}
+
+		key := r.Intn(50)
+		if r.Intn(3) == 0 {
+			m = m.Delete(key)
+			delete(e, key)
+		} else {
+			m = m.Set(key, i)
+			e[key] = i
+		}
+
+		versions = append(versions, m)
+		expected = append(expected, e)
+	}
+
+	for i, m := range versions {
+		checkMap(t, m, expected[i])
+	}
+}
+
+func TestDelete(t *testing.T) {
+	m := New[int, int]()
+	expected := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i)
+		expected[i] = i
+	}
+
+	for i := 0; i < 100; i += 3 {
+		m = m.Delete(i)
+		delete(expected, i)
+		checkMap(t, m, expected)
+	}
+
+	for i := 0; i < 100; i++ {
+		m = m.Delete(i)
+	}
+	assert.Equal(t, 0, m.Len())
+	assert.Nil(t, m.root)
+}
+
+func TestDeleteMissing(t *testing.T) {
+	m := New[int, int]().Set(1, 1).Set(3, 3)
+	assert.True(t, m == m.Delete(2))
+}
+
+func TestFloorCeiling(t *testing.T) {
+	m := New[int, string]()
+	for i := 10; i <= 50; i += 10 {
+		m = m.Set(i, `v`)
+	}
+
+	key, _, ok := m.Floor(35)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+
+	key, _, ok = m.Floor(30)
+	assert.True(t, ok)
+	assert.Equal(t, 30, key)
+
+	_, _, ok = m.Floor(9)
+	assert.False(t, ok)
+
+	key, _, ok = m.Ceiling(35)
+	assert.True(t, ok)
+	assert.Equal(t, 40, key)
+
+	key, value, ok := m.Ceiling(50)
+	assert.True(t, ok)
+	assert.Equal(t, 50, key)
+	assert.Equal(t, `v`, value)
+
+	_, _, ok = m.Ceiling(51)
+	assert.False(t, ok)
+}
+
+func TestFromSorted(t *testing.T) {
+	entries := make([]Entry[int, int], 0, 1000)
+	expected := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		entries = append(entries, Entry[int, int]{Key: i * 2, Value: i})
+		expected[i*2] = i
+	}
+
+	m, err := FromSorted(entries)
+	assert.Nil(t, err)
+	checkMap(t, m, expected)
+	assert.Equal(t, entries, m.Entries())
+
+	m = m.Set(1, 1).Delete(0)
+	expected[1] = 1
+	delete(expected, 0)
+	checkMap(t, m, expected)
+}
+
+func TestFromSortedEmpty(t *testing.T) {
+	m, err := FromSorted[int, int](nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestFromSortedUnsorted(t *testing.T) {
+	_, err := FromSorted([]Entry[int, int]{{Key: 1}, {Key: 1}})
+	assert.Equal(t, ErrUnsorted, err)
+
+	_, err = FromSorted([]Entry[int, int]{{Key: 2}, {Key: 1}})
+	assert.Equal(t, ErrUnsorted, err)
+}
+
+func TestEachStops(t *testing.T) {
+	m := New[int, int]()
+	for i := 0; i < 10; i++ {
+		m = m.Set(i, i)
+	}
+
+	keys := []int{}
+	m.Each(func(key, value int) bool {
+		keys = append(keys, key)
+		return key < 3
+	})
+	assert.Equal(t, []int{0, 1, 2, 3}, keys)
+}
+
+func TestRandom(t *testing.T) {
+	m := New[int, int]()
+	expected := make(map[int]int)
+	r := rand.New(rand.NewSource(7))
+
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(500)
+		if r.Intn(2) == 0 {
+			m = m.Delete(key)
+			delete(expected, key)
+		} else {
+			m = m.Set(key, i)
+			expected[key] = i
+		}
+	}
+
+	checkMap(t, m, expected)
+}
+
+func BenchmarkSet(b *testing.B) {
+	numItems := 1000
+	keys := rand.Perm(numItems)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m := New[int, int]()
+		for _, key := range keys {
+			m = m.Set(key, key)
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	numItems := 1000
+	keys := rand.Perm(numItems)
+	m := New[int, int]()
+	for _, key := range keys {
+		m = m.Set(key, key)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			m.Get(key)
+		}
+	}
+}
+
+func BenchmarkFromSorted(b *testing.B) {
+	numItems := 1000
+	entries := make([]Entry[int, int], 0, numItems)
+	for i := 0; i < numItems; i++ {
+		entries = append(entries, Entry[int, int]{Key: i, Value: i})
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		FromSorted(entries)
+	}
+}
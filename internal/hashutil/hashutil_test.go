@@ -0,0 +1,43 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hashutil
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMix(t *testing.T) {
+	assert.Equal(t, uint64(0), Mix(0))
+	assert.NotEqual(t, Mix(1), Mix(2))
+
+	// flipping one input bit flips about half the output bits
+	for i := 0; i < 64; i++ {
+		flipped := bits.OnesCount64(Mix(12345) ^ Mix(12345^1<<i))
+		assert.True(t, flipped > 16 && flipped < 48)
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	assert.Equal(t, uint64(1), RoundUp(1))
+	assert.Equal(t, uint64(2), RoundUp(2))
+	assert.Equal(t, uint64(8), RoundUp(5))
+	assert.Equal(t, uint64(1024), RoundUp(1024))
+	assert.Equal(t, uint64(1<<63), RoundUp(1<<62+1))
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package memtable implements the memtable of a log-structured merge
+tree: an ordered, in-memory buffer of writes with byte slice keys and
+values that is flushed to a sorted file once it grows large enough.
+The writes are kept in a skiplist from slice/skip.
+
+A delete is recorded as a tombstone rather than removing the key, so
+that when the memtable is flushed the delete shadows older values of
+the key in files flushed before it.  Size reports an estimate of the
+memory used, which is what a store checks to decide when to flush.
+
+The usual life of a memtable is to take writes until it is large
+enough, be frozen so it is read-only, have its entries written out in
+order with an Iterator while a new memtable takes over the writes, and
+then be discarded.  A memtable opened with Open also appends every
+write to a write-ahead log at the provided path and replays that log
+when opened again, so writes that were not yet flushed survive a
+restart.  Discard removes the log once its memtable has been flushed.
+
+Memtables are threadsafe.  Iterators may be used while the memtable
+is written to, visiting writes made ahead of them.
+
+Performance characteristics:
+Put/Delete: O(log n), plus a log append and, by default, an fsync
+Get: O(log n)
+Iterator.Next: O(1) amortized
+Size: O(1)
+Space: O(n)
+*/
+package memtable
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"unsafe"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// nodeOverhead is an estimate of the bytes used by a skiplist node
+// of average height to hold an entry.
+const nodeOverhead = 64
+
+// entryOverhead is an estimate of the bytes used by an entry not
+// including its key and value.
+const entryOverhead = uint64(unsafe.Sizeof(entry{})) + nodeOverhead
+
+// Entry is a key and its value or tombstone as held by a memtable.
+type Entry struct {
+	Key, Value []byte
+	// Tombstone indicates that the key was deleted, in which case
+	// Value is nil.
+	Tombstone bool
+}
+
+type entry Entry
+
+// Compare is required by the skip.Entry interface.
+func (e *entry) Compare(other skip.Entry) int {
+	return bytes.Compare(e.Key, other.(*entry).Key)
+}
+
+func (e *entry) size() uint64 {
+	return entryOverhead + uint64(len(e.Key)+len(e.Value))
+}
+
+// Memtable is an ordered, in-memory buffer of writes.
+type Memtable struct {
+	lock   sync.RWMutex
+	sl     *skip.SkipList
+	size   uint64
+	frozen bool
+	// wal, if not nil, records every write before it is applied.
+	wal *wal
+}
+
+func (m *Memtable) apply(e *entry) {
+	if old := m.sl.Insert(e)[0]; old != nil {
+		m.size -= old.(*entry).size()
+	}
+	m.size += e.size()
+}
+
+func (m *Memtable) write(e *entry) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.frozen {
+		return FrozenError{}
+	}
+
+	if m.wal != nil {
+		if err := m.wal.append(e); err != nil {
+			return err
+		}
+	}
+
+	m.apply(e)
+	return nil
+}
+
+// Put associates the value with the key.  The key and value are
+// copied, so the caller may reuse them once Put returns.
+func (m *Memtable) Put(key, value []byte) error {
+	return m.write(&entry{
+		Key:   bytes.Clone(key),
+		Value: append([]byte{}, value...),
+	})
+}
+
+// Delete records a tombstone for the key.  The key is copied.
+func (m *Memtable) Delete(key []byte) error {
+	return m.write(&entry{Key: bytes.Clone(key), Tombstone: true})
+}
+
+// Get returns the entry for the key and a bool indicating if there is
+// one.  An entry whose Tombstone is set means the key was deleted.
+// The entry's slices are shared with the memtable and must not be
+// modified.
+func (m *Memtable) Get(key []byte) (Entry, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	e := m.sl.GetOne(&entry{Key: key})
+	if e == nil {
+		return Entry{}, false
+	}
+
+	return Entry(*e.(*entry)), true
+}
+
+// Len returns the number of keys in the memtable, including those
+// holding tombstones.
+func (m *Memtable) Len() uint64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.sl.Len()
+}
+
+// Size returns an estimate of the number of bytes used by the entries
+// of the memtable, including their keys and values.
+func (m *Memtable) Size() uint64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.size
+}
+
+// Freeze makes the memtable read-only, so later writes return a
+// FrozenError, and flushes its log to stable storage.
+func (m *Memtable) Freeze() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.frozen = true
+	if m.wal != nil {
+		return m.wal.sync()
+	}
+
+	return nil
+}
+
+// Frozen returns a bool indicating if the memtable is read-only.
+func (m *Memtable) Frozen() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.frozen
+}
+
+// Sync flushes the memtable's log to stable storage.  This is only
+// needed with SyncNever.
+func (m *Memtable) Sync() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.wal == nil {
+		return nil
+	}
+
+	return m.wal.sync()
+}
+
+// Close freezes the memtable and closes its log, which stays on disk
+// to be replayed by the next Open of its path.  The memtable can still
+// be read, for instance to flush it.
+func (m *Memtable) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.frozen = true
+	if m.wal == nil {
+		return nil
+	}
+
+	err := m.wal.close()
+	m.wal = nil
+	return err
+}
+
+// Discard closes the memtable like Close and then removes its log.
+// Call it once the memtable's entries have been flushed elsewhere.
+func (m *Memtable) Discard() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.frozen = true
+	if m.wal == nil {
+		return nil
+	}
+
+	path := m.wal.path
+	err := m.wal.close()
+	m.wal = nil
+	if rerr := os.Remove(path); err == nil {
+		err = rerr
+	}
+
+	return err
+}
+
+// Iter returns an iterator over the entries with keys equal to or
+// greater than start in key order.  A nil start begins at the
+// smallest key.
+func (m *Memtable) Iter(start []byte) *Iterator {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return &Iterator{m: m, iter: m.sl.Iter(&entry{Key: start})}
+}
+
+// Iterator visits the entries of a memtable in key order, tombstones
+// included.
+type Iterator struct {
+	m       *Memtable
+	iter    skip.Iterator
+	current *entry
+}
+
+// Next moves the iterator to the next entry and returns a bool
+// indicating if there is one.
+func (iter *Iterator) Next() bool {
+	iter.m.lock.RLock()
+	defer iter.m.lock.RUnlock()
+
+	if !iter.iter.Next() {
+		iter.current = nil
+		return false
+	}
+
+	iter.current = iter.iter.Value().(*entry)
+	return true
+}
+
+// Entry returns the entry at the iterator's position, or the zero
+// Entry if Next hasn't been called or the iterator is exhausted.  The
+// entry's slices are shared with the memtable and must not be
+// modified.
+func (iter *Iterator) Entry() Entry {
+	if iter.current == nil {
+		return Entry{}
+	}
+
+	return Entry(*iter.current)
+}
+
+// New returns an empty memtable without a write-ahead log.
+func New() *Memtable {
+	return &Memtable{sl: skip.New(uint64(0))}
+}
+
+// Open returns a memtable whose writes are appended to the write-ahead
+// log at path, creating the log if needed.  Writes already in the log
+// are replayed into the memtable.  A record torn by a crash at the end
+// of the log is discarded.  Only one memtable may use a log at a time.
+func Open(path string, opts ...Option) (*Memtable, error) {
+	config := config{sync: SyncAlways}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	m := New()
+	w, err := openWAL(path, config.sync, m.apply)
+	if err != nil {
+		return nil, err
+	}
+
+	m.wal = w
+	return m, nil
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memtable
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func walPath(t *testing.T) string {
+	dir, err := os.MkdirTemp(``, `memtable`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filepath.Join(dir, `wal`)
+}
+
+func collect(iter *Iterator) []Entry {
+	var entries []Entry
+	for iter.Next() {
+		entries = append(entries, iter.Entry())
+	}
+
+	return entries
+}
+
+func TestPutGetDelete(t *testing.T) {
+	m := New()
+	key := []byte(`a`)
+	value := []byte(`1`)
+	assert.Nil(t, m.Put(key, value))
+	value[0] = '2' // the memtable holds its own copy
+
+	e, ok := m.Get([]byte(`a`))
+	assert.True(t, ok)
+	assert.Equal(t, Entry{Key: []byte(`a`), Value: []byte(`1`)}, e)
+
+	_, ok = m.Get([]byte(`b`))
+	assert.False(t, ok)
+
+	assert.Nil(t, m.Delete([]byte(`a`)))
+	e, ok = m.Get([]byte(`a`))
+	assert.True(t, ok)
+	assert.True(t, e.Tombstone)
+	assert.Nil(t, e.Value)
+	assert.Equal(t, uint64(1), m.Len())
+
+	assert.Nil(t, m.Put([]byte(`empty`), nil))
+	e, _ = m.Get([]byte(`empty`))
+	assert.False(t, e.Tombstone)
+	assert.Len(t, e.Value, 0)
+}
+
+func TestSize(t *testing.T) {
+	m := New()
+	assert.Equal(t, uint64(0), m.Size())
+
+	m.Put([]byte(`key`), make([]byte, 100))
+	assert.Equal(t, entryOverhead+103, m.Size())
+
+	m.Put([]byte(`key`), make([]byte, 10))
+	assert.Equal(t, entryOverhead+13, m.Size())
+
+	m.Delete([]byte(`key`))
+	assert.Equal(t, entryOverhead+3, m.Size())
+
+	m.Put([]byte(`other`), make([]byte, 5))
+	assert.Equal(t, 2*entryOverhead+13, m.Size())
+}
+
+func TestIterator(t *testing.T) {
+	m := New()
+	for _, k := range []string{`d`, `b`, `a`, `c`} {
+		m.Put([]byte(k), []byte(k+k))
+	}
+	m.Delete([]byte(`c`))
+
+	entries := collect(m.Iter(nil))
+	assert.Equal(t, []Entry{
+		{Key: []byte(`a`), Value: []byte(`aa`)},
+		{Key: []byte(`b`), Value: []byte(`bb`)},
+		{Key: []byte(`c`), Tombstone: true},
+		{Key: []byte(`d`), Value: []byte(`dd`)},
+	}, entries)
+
+	iter := m.Iter([]byte(`bb`))
+	assert.Equal(t, Entry{}, iter.Entry())
+	assert.True(t, iter.Next())
+	assert.Equal(t, []byte(`c`), iter.Entry().Key)
+
+	assert.Len(t, collect(m.Iter([]byte(`e`))), 0)
+}
+
+func TestIterateWhileWriting(t *testing.T) {
+	m := New()
+	for i := 0; i < 10; i += 2 {
+		m.Put([]byte{byte(i)}, nil)
+	}
+
+	var keys []byte
+	iter := m.Iter(nil)
+	for iter.Next() {
+		k := iter.Entry().Key[0]
+		keys = append(keys, k)
+		if k == 2 {
+			m.Put([]byte{5}, nil)
+			m.Put([]byte{1}, nil)
+		}
+	}
+
+	assert.Equal(t, []byte{0, 2, 4, 5, 6, 8}, keys)
+}
+
+func TestFreeze(t *testing.T) {
+	m := New()
+	m.Put([]byte(`a`), []byte(`1`))
+	assert.False(t, m.Frozen())
+
+	assert.Nil(t, m.Freeze())
+	assert.True(t, m.Frozen())
+	assert.Equal(t, FrozenError{}, m.Put([]byte(`b`), nil))
+	assert.Equal(t, FrozenError{}, m.Delete([]byte(`a`)))
+
+	e, ok := m.Get([]byte(`a`))
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`1`), e.Value)
+	assert.Len(t, collect(m.Iter(nil)), 1)
+}
+
+func TestWALReplay(t *testing.T) {
+	path := walPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	m, err := Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	m.Put([]byte(`a`), []byte(`1`))
+	m.Put([]byte(`b`), []byte(`2`))
+	m.Put([]byte(`a`), []byte(`3`))
+	m.Delete([]byte(`b`))
+	m.Put([]byte(``), []byte(`empty key`))
+	expected := collect(m.Iter(nil))
+	size := m.Size()
+	assert.Nil(t, m.Close())
+	assert.Equal(t, FrozenError{}, m.Put([]byte(`c`), nil))
+
+	m, err = Open(path, WithSyncPolicy(SyncNever))
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, expected, collect(m.Iter(nil)))
+	assert.Equal(t, size, m.Size())
+
+	assert.Nil(t, m.Put([]byte(`c`), []byte(`4`)))
+	assert.Nil(t, m.Sync())
+	assert.Nil(t, m.Close())
+
+	m, err = Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	e, ok := m.Get([]byte(`c`))
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`4`), e.Value)
+	assert.Nil(t, m.Close())
+}
+
+func TestWALTornRecord(t *testing.T) {
+	path := walPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	m, err := Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	m.Put([]byte(`a`), []byte(`1`))
+	m.Put([]byte(`b`), []byte(`2`))
+	m.Close()
+
+	info, _ := os.Stat(path)
+	assert.Nil(t, os.Truncate(path, info.Size()-1))
+
+	m, err = Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(1), m.Len())
+	_, ok := m.Get([]byte(`b`))
+	assert.False(t, ok)
+
+	// writes after the torn record replace it
+	m.Put([]byte(`c`), []byte(`3`))
+	m.Close()
+
+	m, err = Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), m.Len())
+	_, ok = m.Get([]byte(`c`))
+	assert.True(t, ok)
+	m.Close()
+}
+
+func TestDiscard(t *testing.T) {
+	path := walPath(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	m, err := Open(path)
+	if !assert.Nil(t, err) {
+		return
+	}
+	m.Put([]byte(`a`), []byte(`1`))
+
+	assert.Nil(t, m.Discard())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, m.Frozen())
+	assert.Equal(t, uint64(1), m.Len())
+	assert.Nil(t, m.Discard())
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	m := New()
+	numWriters, numKeys := 8, 500
+
+	var wg sync.WaitGroup
+	wg.Add(numWriters + 1)
+	for w := 0; w < numWriters; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < numKeys; i++ {
+				m.Put([]byte(fmt.Sprintf(`%d-%04d`, w, i)), []byte{byte(i)})
+			}
+		}(w)
+	}
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			collect(m.Iter(nil))
+			m.Get([]byte(`0-0000`))
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, uint64(numWriters*numKeys), m.Len())
+}
+
+func BenchmarkPut(b *testing.B) {
+	m := New()
+	value := make([]byte, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Put([]byte(fmt.Sprintf(`%016d`, i)), value)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package memtable
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+)
+
+// recordHeaderSize is the size of the length and checksum preceding
+// every record in the log.
+const recordHeaderSize = 8
+
+const (
+	recordPut byte = iota
+	recordDelete
+)
+
+// SyncPolicy determines when a memtable flushes its log to stable
+// storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs the log before every write returns, so no
+	// acknowledged write is lost if the machine crashes.
+	SyncAlways SyncPolicy = iota
+	// SyncNever leaves flushing to the operating system.  Writes
+	// survive the process crashing but may be lost if the machine
+	// does.  Call Sync to flush explicitly.
+	SyncNever
+)
+
+type config struct {
+	sync SyncPolicy
+}
+
+// Option configures a memtable opened with Open.
+type Option func(*config)
+
+// WithSyncPolicy sets when the memtable fsyncs its log.  The default
+// is SyncAlways.
+func WithSyncPolicy(policy SyncPolicy) Option {
+	return func(c *config) {
+		c.sync = policy
+	}
+}
+
+// wal is a write-ahead log of the writes to a memtable.  Each record
+// is a length and CRC-32 checksum followed by a payload of the kind
+// of write, the length of the key as a uvarint, the key and the
+// value.
+type wal struct {
+	path   string
+	file   *os.File
+	policy SyncPolicy
+	buf    []byte
+}
+
+// openWAL opens the log at path, creating it if needed, and passes
+// every write it holds to apply in order.
+func openWAL(path string, policy SyncPolicy, apply func(*entry)) (*wal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wal{path: path, file: file, policy: policy}
+	if err := w.replay(apply); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// replay passes every intact record to apply, truncates the log after
+// the last of them and leaves the file positioned at its end.
+func (w *wal) replay(apply func(*entry)) error {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	offset := 0
+	for offset < len(data) {
+		e, n, ok := decodeRecord(data[offset:])
+		if !ok {
+			break
+		}
+
+		apply(e)
+		offset += n
+	}
+
+	if offset < len(data) {
+		if err := w.file.Truncate(int64(offset)); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.file.Seek(int64(offset), 0)
+	return err
+}
+
+// decodeRecord returns the write in the record at the start of data,
+// the size of the record and a bool indicating if it is complete and
+// intact.  The entry copies its key and value out of data.
+func decodeRecord(data []byte) (*entry, int, bool) {
+	if len(data) < recordHeaderSize {
+		return nil, 0, false
+	}
+
+	length := binary.LittleEndian.Uint32(data)
+	if uint64(len(data)-recordHeaderSize) < uint64(length) || length == 0 {
+		return nil, 0, false
+	}
+
+	payload := data[recordHeaderSize : recordHeaderSize+int(length)]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(data[4:]) {
+		return nil, 0, false
+	}
+
+	keyLen, n := binary.Uvarint(payload[1:])
+	if n <= 0 || keyLen > uint64(len(payload)-1-n) {
+		return nil, 0, false
+	}
+
+	key := payload[1+n : 1+n+int(keyLen)]
+	e := &entry{Key: append([]byte{}, key...)}
+	if payload[0] == recordDelete {
+		e.Tombstone = true
+	} else {
+		e.Value = append([]byte{}, payload[1+n+int(keyLen):]...)
+	}
+
+	return e, recordHeaderSize + int(length), true
+}
+
+func encodeRecord(buf []byte, e *entry) []byte {
+	buf = append(buf, make([]byte, recordHeaderSize)...)
+	kind := recordPut
+	if e.Tombstone {
+		kind = recordDelete
+	}
+	buf = append(buf, kind)
+	buf = binary.AppendUvarint(buf, uint64(len(e.Key)))
+	buf = append(buf, e.Key...)
+	buf = append(buf, e.Value...)
+
+	payload := buf[recordHeaderSize:]
+	binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(payload))
+	return buf
+}
+
+// append writes the entry to the log, syncing it if the policy says
+// to.
+func (w *wal) append(e *entry) error {
+	w.buf = encodeRecord(w.buf[:0], e)
+	if _, err := w.file.Write(w.buf); err != nil {
+		return err
+	}
+
+	if w.policy == SyncAlways {
+		return w.file.Sync()
+	}
+
+	return nil
+}
+
+func (w *wal) sync() error {
+	return w.file.Sync()
+}
+
+func (w *wal) close() error {
+	err := w.file.Sync()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package merge implements a k-way merge of sorted iterators.  The
+smallest current value of every input is kept in a heap so the merged
+output is produced one value at a time without buffering the inputs.
+This is the piece needed to compact sorted runs, as in an LSM tree, or
+to read a single ordered stream from several sorted shards.
+
+Inputs may share values.  How duplicates are handled is decided by a
+Policy, or by a Resolver for anything more involved, such as dropping
+tombstones during compaction.  Inputs are ranked by their position in
+the list given to New, so with KeepFirst the first iterator wins a tie;
+pass the newest run first to get last-writer-wins semantics.
+
+Performance characteristics:
+Space: O(k)
+Next: O(log k), O(d log k) when d equal values are resolved together
+*/
+package merge
+
+// Entry defines items that can be merged.
+type Entry interface {
+	// Compare this entry to the provided entry.  Return a positive
+	// number if this entry is greater than, 0 if equal, negative
+	// number if less than.
+	Compare(Entry) int
+}
+
+// Entries is a typed list of interface Entry.
+type Entries []Entry
+
+// Iterator defines an interface that allows a consumer to iterate
+// a sorted sequence of entries.  Inputs to a merge must return their
+// values in ascending order.
+type Iterator interface {
+	// Next returns a bool indicating if there is future value
+	// in the iterator and moves the iterator to that value.
+	Next() bool
+	// Value returns the Entry at the iterator's current position.
+	Value() Entry
+}
+
+// Resolver is called with every equal entry drawn from the inputs and
+// returns the single entry to emit in their place.  Entries are given
+// in the order of the iterators that produced them.  Returning nil
+// drops the entries from the output entirely.  The provided list is
+// reused between calls and must not be retained.
+type Resolver func(equal Entries) Entry
+
+// Policy is one of the built-in strategies for handling equal entries.
+type Policy int
+
+const (
+	// KeepAll emits every entry, equal entries being emitted in
+	// the order of the iterators that produced them.
+	KeepAll Policy = iota
+	// KeepFirst emits only the entry from the earliest iterator.
+	KeepFirst
+	// KeepLast emits only the entry from the latest iterator.
+	KeepLast
+)
@@ -0,0 +1,237 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import "container/heap"
+
+type cursor struct {
+	iter  Iterator
+	value Entry
+	index int
+}
+
+type cursors []*cursor
+
+func (c cursors) Len() int {
+	return len(c)
+}
+
+func (c cursors) Less(i, j int) bool {
+	if cmp := c[i].value.Compare(c[j].value); cmp != 0 {
+		return cmp < 0
+	}
+
+	return c[i].index < c[j].index
+}
+
+func (c cursors) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func (c *cursors) Push(x interface{}) {
+	*c = append(*c, x.(*cursor))
+}
+
+func (c *cursors) Pop() interface{} {
+	old := *c
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*c = old[:n-1]
+	return item
+}
+
+// Merger merges a set of sorted iterators into one sorted stream.
+// It is itself an Iterator, so merges may be nested.  It is not
+// threadsafe and neither are the inputs while a merge is in progress.
+type Merger struct {
+	heap     cursors
+	pending  []*cursor
+	resolver Resolver
+	equal    Entries
+	value    Entry
+	started  bool
+}
+
+// advance moves the cursor forward and returns it to the heap if
+// its iterator has any values left.
+func (m *Merger) advance(c *cursor) {
+	if c.iter.Next() {
+		c.value = c.iter.Value()
+		heap.Push(&m.heap, c)
+	}
+}
+
+func (m *Merger) start() {
+	m.started = true
+	for _, c := range m.pending {
+		if c.iter.Next() {
+			c.value = c.iter.Value()
+			m.heap = append(m.heap, c)
+		}
+	}
+	m.pending = m.pending[:0]
+	heap.Init(&m.heap)
+}
+
+// Next moves the merge to the next entry and returns a bool
+// indicating if there is one.
+func (m *Merger) Next() bool {
+	if !m.started {
+		m.start()
+	}
+
+	for len(m.heap) > 0 {
+		c := heap.Pop(&m.heap).(*cursor)
+		if m.resolver == nil {
+			m.value = c.value
+			m.advance(c)
+			return true
+		}
+
+		key := c.value
+		m.equal = append(m.equal[:0], key)
+		m.pending = append(m.pending[:0], c)
+		// drain the heap of equal values before advancing anything
+		// so the resolver sees entries in iterator order
+		for len(m.heap) > 0 && m.heap[0].value.Compare(key) == 0 {
+			next := heap.Pop(&m.heap).(*cursor)
+			m.equal = append(m.equal, next.value)
+			m.pending = append(m.pending, next)
+		}
+
+		// an iterator may hold the same value more than once, these
+		// belong to the same group
+		for i := 0; i < len(m.pending); i++ {
+			p := m.pending[i]
+			if p.iter.Next() {
+				p.value = p.iter.Value()
+				if p.value.Compare(key) == 0 {
+					m.equal = append(m.equal, p.value)
+					m.pending = append(m.pending, p)
+					continue
+				}
+				heap.Push(&m.heap, p)
+			}
+		}
+
+		m.value = m.resolver(m.equal)
+		for i := range m.equal {
+			m.equal[i] = nil
+		}
+		if m.value != nil {
+			return true
+		}
+	}
+
+	m.value = nil
+	return false
+}
+
+// Value returns the entry at the merge's current position.  If there
+// is no value, this returns nil.
+func (m *Merger) Value() Entry {
+	return m.value
+}
+
+// Drain consumes the rest of the merge, calling fn with each entry
+// in order.  Iteration stops early if fn returns false.
+func (m *Merger) Drain(fn func(Entry) bool) {
+	for m.Next() {
+		if !fn(m.Value()) {
+			return
+		}
+	}
+}
+
+func keepFirst(equal Entries) Entry {
+	return equal[0]
+}
+
+// keepLast relies on equal values from a single iterator following
+// each other, the latest iterator's final value is the last of its run.
+func keepLast(equal Entries, pending []*cursor) Entry {
+	last := 0
+	for i, c := range pending {
+		if c.index >= pending[last].index {
+			last = i
+		}
+	}
+
+	return equal[last]
+}
+
+func newMerger(iters []Iterator) *Merger {
+	m := &Merger{
+		heap:    make(cursors, 0, len(iters)),
+		pending: make([]*cursor, 0, len(iters)),
+	}
+	for i, iter := range iters {
+		m.pending = append(m.pending, &cursor{iter: iter, index: i})
+	}
+
+	return m
+}
+
+// New returns a merge of the provided iterators, handling equal
+// entries according to policy.  Iterators are not advanced until
+// the first call to Next.
+func New(policy Policy, iters ...Iterator) *Merger {
+	m := newMerger(iters)
+	switch policy {
+	case KeepFirst:
+		m.resolver = keepFirst
+	case KeepLast:
+		m.resolver = func(equal Entries) Entry {
+			return keepLast(equal, m.pending)
+		}
+	}
+
+	return m
+}
+
+// NewWithResolver returns a merge of the provided iterators in which
+// every set of equal entries is replaced by the result of resolver.
+func NewWithResolver(resolver Resolver, iters ...Iterator) *Merger {
+	m := newMerger(iters)
+	m.resolver = resolver
+	return m
+}
+
+type sliceIterator struct {
+	entries Entries
+	index   int
+}
+
+func (si *sliceIterator) Next() bool {
+	si.index++
+	return si.index < len(si.entries)
+}
+
+func (si *sliceIterator) Value() Entry {
+	if si.index < 0 || si.index >= len(si.entries) {
+		return nil
+	}
+
+	return si.entries[si.index]
+}
+
+// FromSlice returns an Iterator over the provided entries, which
+// must already be sorted.  This is useful for merging in-memory runs.
+func FromSlice(entries Entries) Iterator {
+	return &sliceIterator{entries: entries, index: -1}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merge
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEntry struct {
+	key, source int
+}
+
+func (me mockEntry) Compare(other Entry) int {
+	o := other.(mockEntry)
+	switch {
+	case me.key < o.key:
+		return -1
+	case me.key > o.key:
+		return 1
+	}
+
+	return 0
+}
+
+func run(source int, keys ...int) Iterator {
+	entries := make(Entries, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, mockEntry{key, source})
+	}
+
+	return FromSlice(entries)
+}
+
+func exhaust(iter Iterator) []mockEntry {
+	result := []mockEntry{}
+	for iter.Next() {
+		result = append(result, iter.Value().(mockEntry))
+	}
+
+	return result
+}
+
+func TestMergeKeepAll(t *testing.T) {
+	m := New(KeepAll, run(0, 1, 4, 7), run(1, 2, 4, 8), run(2), run(3, 0, 9))
+	assert.Nil(t, m.Value())
+
+	assert.Equal(t, []mockEntry{
+		{0, 3}, {1, 0}, {2, 1}, {4, 0}, {4, 1}, {7, 0}, {8, 1}, {9, 3},
+	}, exhaust(m))
+	assert.Nil(t, m.Value())
+	assert.False(t, m.Next())
+}
+
+func TestMergeKeepFirst(t *testing.T) {
+	m := New(KeepFirst, run(0, 1, 3), run(1, 1, 2, 3, 3), run(2, 3))
+	assert.Equal(t, []mockEntry{{1, 0}, {2, 1}, {3, 0}}, exhaust(m))
+}
+
+func TestMergeKeepLast(t *testing.T) {
+	m := New(KeepLast, run(0, 1, 3), run(1, 1, 2, 3, 3), run(2, 3), run(3, 1))
+	assert.Equal(t, []mockEntry{{1, 3}, {2, 1}, {3, 2}}, exhaust(m))
+}
+
+func TestMergeResolver(t *testing.T) {
+	var sizes []int
+	// drop keys seen in more than one input, keep the rest
+	m := NewWithResolver(func(equal Entries) Entry {
+		sizes = append(sizes, len(equal))
+		if len(equal) > 1 {
+			return nil
+		}
+		return equal[0]
+	}, run(0, 1, 2, 2, 5), run(1, 2, 3), run(2, 5))
+
+	assert.Equal(t, []mockEntry{{1, 0}, {3, 1}}, exhaust(m))
+	assert.Equal(t, []int{1, 3, 1, 2}, sizes)
+}
+
+func TestMergeResolverOrder(t *testing.T) {
+	var sources []int
+	m := NewWithResolver(func(equal Entries) Entry {
+		for _, e := range equal {
+			sources = append(sources, e.(mockEntry).source)
+		}
+		return equal[0]
+	}, run(2, 4), run(0, 4), run(1, 4))
+
+	assert.Equal(t, []mockEntry{{4, 2}}, exhaust(m))
+	assert.Equal(t, []int{2, 0, 1}, sources)
+}
+
+func TestMergeEmpty(t *testing.T) {
+	assert.Equal(t, []mockEntry{}, exhaust(New(KeepAll)))
+	assert.Equal(t, []mockEntry{}, exhaust(New(KeepFirst, run(0), run(1))))
+}
+
+func TestMergeNested(t *testing.T) {
+	inner := New(KeepFirst, run(0, 1, 5), run(1, 1, 3))
+	m := New(KeepAll, inner, run(2, 2, 5))
+	assert.Equal(t, []mockEntry{{1, 0}, {2, 2}, {3, 1}, {5, 0}, {5, 2}}, exhaust(m))
+}
+
+func TestMergeRandom(t *testing.T) {
+	iters := make([]Iterator, 0, 10)
+	all := []int{}
+	unique := map[int]struct{}{}
+	for i := 0; i < 10; i++ {
+		keys := make([]int, rand.Intn(100))
+		for j := range keys {
+			keys[j] = rand.Intn(200)
+			unique[keys[j]] = struct{}{}
+		}
+		sort.Ints(keys)
+		all = append(all, keys...)
+		iters = append(iters, run(i, keys...))
+	}
+	sort.Ints(all)
+
+	result := exhaust(New(KeepAll, iters...))
+	if !assert.Len(t, result, len(all)) {
+		return
+	}
+	for i, e := range result {
+		assert.Equal(t, all[i], e.key)
+		if i > 0 && result[i-1].key == e.key {
+			assert.True(t, result[i-1].source <= e.source)
+		}
+	}
+
+	for i := range iters {
+		iters[i].(*sliceIterator).index = -1
+	}
+	assert.Len(t, exhaust(New(KeepFirst, iters...)), len(unique))
+}
+
+func TestDrain(t *testing.T) {
+	m := New(KeepAll, run(0, 1, 3), run(1, 2, 4))
+	keys := []int{}
+	m.Drain(func(e Entry) bool {
+		keys = append(keys, e.(mockEntry).key)
+		return len(keys) < 3
+	})
+	assert.Equal(t, []int{1, 2, 3}, keys)
+
+	assert.True(t, m.Next())
+	assert.Equal(t, mockEntry{4, 1}, m.Value())
+}
+
+func BenchmarkMerge(b *testing.B) {
+	numRuns, runSize := 16, 1000
+	runs := make([]Entries, 0, numRuns)
+	for i := 0; i < numRuns; i++ {
+		entries := make(Entries, 0, runSize)
+		for j := 0; j < runSize; j++ {
+			entries = append(entries, mockEntry{j*numRuns + i, i})
+		}
+		runs = append(runs, entries)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		iters := make([]Iterator, 0, numRuns)
+		for _, entries := range runs {
+			iters = append(iters, FromSlice(entries))
+		}
+		m := New(KeepFirst, iters...)
+		for m.Next() {
+		}
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package merkle implements an append-only Merkle tree, which summarizes
+a list of byte slices with a single root hash so that two parties can
+cheaply check that they hold the same data, and can prove that an item
+is in the list or that one version of the list is a prefix of another.
+
+The tree follows RFC 6962, which defines the logs of Certificate
+Transparency.  Leaves and interior nodes are hashed with different
+prefixes so that one can't be passed off as the other, and a tree of
+n leaves is split so that its left subtree holds the largest power of
+two leaves less than n.  Proofs produced here can therefore be checked
+by any other implementation of that RFC using the same hash function.
+
+An inclusion proof, also known as an audit proof, shows that a leaf is
+at a given index in a tree of a given size.  A consistency proof shows
+that the tree of one size is a prefix of a tree of a larger size, that
+is that nothing was changed or removed between the two.  Both proofs
+hold O(log n) hashes and are verified with a Hasher alone, so the
+verifier needs only the roots and not the tree.
+
+The tree keeps the hash of every complete subtree, so appends are
+O(1) amortized and roots and proofs, including those for earlier sizes
+of the tree, are computed in O(log n) hashes.  The tree is not
+threadsafe.
+*/
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"math/bits"
+)
+
+const (
+	// leafPrefix is prepended to the data of a leaf before hashing.
+	leafPrefix = 0
+	// nodePrefix is prepended to the hashes of two children before
+	// hashing.
+	nodePrefix = 1
+)
+
+// ErrOutOfRange is returned when an index or tree size is beyond the
+// leaves in a tree.
+var ErrOutOfRange = errors.New(`Index or size is out of range for this tree.`)
+
+// ErrInvalidProof is returned when a proof does not verify.
+var ErrInvalidProof = errors.New(`Proof is invalid.`)
+
+// Hasher computes the hashes of a tree with a particular hash
+// function.
+type Hasher struct {
+	newHash func() hash.Hash
+}
+
+// SHA256 is a Hasher using SHA-256, as RFC 6962 does.
+var SHA256 = NewHasher(sha256.New)
+
+// NewHasher returns a Hasher using the provided hash function.
+func NewHasher(newHash func() hash.Hash) Hasher {
+	return Hasher{newHash: newHash}
+}
+
+// EmptyRoot returns the root hash of a tree without leaves, which is
+// the hash of no data.
+func (h Hasher) EmptyRoot() []byte {
+	return h.newHash().Sum(nil)
+}
+
+// HashLeaf returns the hash of a leaf holding data.
+func (h Hasher) HashLeaf(data []byte) []byte {
+	hh := h.newHash()
+	hh.Write([]byte{leafPrefix})
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+// HashChildren returns the hash of an interior node with the provided
+// left and right children's hashes.
+func (h Hasher) HashChildren(left, right []byte) []byte {
+	hh := h.newHash()
+	hh.Write([]byte{nodePrefix})
+	hh.Write(left)
+	hh.Write(right)
+	return hh.Sum(nil)
+}
+
+// Tree is an append-only Merkle tree.
+type Tree struct {
+	hasher Hasher
+	// levels holds the hashes of complete subtrees.  levels[k][i] is
+	// the hash of the 2^k leaves starting at i*2^k, so levels[0]
+	// holds the leaf hashes.
+	levels [][][]byte
+}
+
+// Append adds leaves holding each of the provided byte slices to the
+// end of the tree.  The data is hashed and not retained.
+func (t *Tree) Append(data ...[]byte) {
+	for _, d := range data {
+		t.AppendHash(t.hasher.HashLeaf(d))
+	}
+}
+
+// AppendHash adds a leaf with the provided leaf hash, as returned by
+// the tree's Hasher, to the end of the tree.
+func (t *Tree) AppendHash(leafHash []byte) {
+	h := leafHash
+	for level := 0; ; level++ {
+		if level == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+		t.levels[level] = append(t.levels[level], h)
+
+		// an even count means the new hash completed a pair
+		n := len(t.levels[level])
+		if n%2 == 1 {
+			return
+		}
+		h = t.hasher.HashChildren(t.levels[level][n-2], h)
+	}
+}
+
+// Len returns the number of leaves in the tree.
+func (t *Tree) Len() uint64 {
+	if len(t.levels) == 0 {
+		return 0
+	}
+
+	return uint64(len(t.levels[0]))
+}
+
+// Hasher returns the Hasher the tree was built with.
+func (t *Tree) Hasher() Hasher {
+	return t.hasher
+}
+
+// LeafHash returns the hash of the leaf at the provided index.
+func (t *Tree) LeafHash(index uint64) ([]byte, error) {
+	if index >= t.Len() {
+		return nil, ErrOutOfRange
+	}
+
+	return t.levels[0][index], nil
+}
+
+// Root returns the root hash of the tree.
+func (t *Tree) Root() []byte {
+	root, _ := t.RootAt(t.Len())
+	return root
+}
+
+// RootAt returns the root hash the tree had when it held the provided
+// number of leaves.
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Len() {
+		return nil, ErrOutOfRange
+	}
+
+	if size == 0 {
+		return t.hasher.EmptyRoot(), nil
+	}
+
+	return t.hashRange(0, size), nil
+}
+
+// split returns the largest power of two less than n, which must be
+// greater than one.
+func split(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// hashRange returns the hash of the subtree holding the leaves
+// [lo, hi).  The range must be one that occurs in a tree rooted at
+// leaf zero, so lo is a multiple of the largest power of two not
+// greater than hi-lo.
+func (t *Tree) hashRange(lo, hi uint64) []byte {
+	n := hi - lo
+	if n&(n-1) == 0 {
+		level := bits.TrailingZeros64(n)
+		return t.levels[level][lo>>level]
+	}
+
+	k := split(n)
+	return t.hasher.HashChildren(t.hashRange(lo, lo+k), t.hashRange(lo+k, hi))
+}
+
+// New returns an empty tree that hashes with the provided Hasher.
+func New(hasher Hasher) *Tree {
+	return &Tree{hasher: hasher}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merkle
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcLeaves and rfcRoots are the test vectors used by Certificate
+// Transparency implementations, rfcRoots[i] being the root of the
+// tree holding the first i+1 leaves.
+var rfcLeaves = [][]byte{
+	{},
+	{0x00},
+	{0x10},
+	{0x20, 0x21},
+	{0x30, 0x31},
+	{0x40, 0x41, 0x42, 0x43},
+	{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+	{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+}
+
+var rfcRoots = []string{
+	`6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d`,
+	`fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125`,
+	`aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77`,
+	`d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7`,
+	`4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4`,
+	`76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef`,
+	`ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c`,
+	`5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328`,
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := New(SHA256)
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Equal(t, `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`,
+		hex.EncodeToString(tree.Root()))
+
+	_, err := tree.LeafHash(0)
+	assert.Equal(t, ErrOutOfRange, err)
+}
+
+func TestRFCRoots(t *testing.T) {
+	tree := New(SHA256)
+	for i, leaf := range rfcLeaves {
+		tree.Append(leaf)
+		assert.Equal(t, rfcRoots[i], hex.EncodeToString(tree.Root()))
+	}
+
+	// earlier roots can still be computed
+	for i := range rfcRoots {
+		root, err := tree.RootAt(uint64(i + 1))
+		assert.Nil(t, err)
+		assert.Equal(t, rfcRoots[i], hex.EncodeToString(root))
+	}
+
+	_, err := tree.RootAt(9)
+	assert.Equal(t, ErrOutOfRange, err)
+}
+
+// naiveRoot computes the root of a tree over the leaf hashes as
+// defined by RFC 6962.
+func naiveRoot(h Hasher, leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return h.EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+
+	k := split(uint64(len(leaves)))
+	return h.HashChildren(naiveRoot(h, leaves[:k]), naiveRoot(h, leaves[k:]))
+}
+
+func TestRoots(t *testing.T) {
+	h := NewHasher(sha512.New)
+	tree := New(h)
+	var leaves [][]byte
+	for i := 0; i < 300; i++ {
+		data := []byte{byte(i), byte(i >> 8)}
+		tree.Append(data)
+		leaves = append(leaves, h.HashLeaf(data))
+		assert.Equal(t, naiveRoot(h, leaves), tree.Root())
+	}
+
+	assert.Equal(t, uint64(300), tree.Len())
+	leaf, err := tree.LeafHash(17)
+	assert.Nil(t, err)
+	assert.Equal(t, leaves[17], leaf)
+}
+
+func TestAppendHash(t *testing.T) {
+	a, b := New(SHA256), New(SHA256)
+	for _, leaf := range rfcLeaves {
+		a.Append(leaf)
+		b.AppendHash(SHA256.HashLeaf(leaf))
+	}
+
+	assert.Equal(t, a.Root(), b.Root())
+	assert.Equal(t, SHA256.EmptyRoot(), b.Hasher().EmptyRoot())
+}
+
+func TestLeafAndNodeHashesDiffer(t *testing.T) {
+	left, right := SHA256.HashLeaf([]byte{1}), SHA256.HashLeaf([]byte{2})
+	data := append(append([]byte{}, left...), right...)
+	assert.NotEqual(t, SHA256.HashChildren(left, right), SHA256.HashLeaf(data))
+}
+
+func BenchmarkAppend(b *testing.B) {
+	tree := New(SHA256)
+	data := make([]byte, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Append(data)
+	}
+}
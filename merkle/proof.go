@@ -0,0 +1,183 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merkle
+
+import "bytes"
+
+// inclusion appends the inclusion proof of the leaf at index, which is
+// relative to lo, in the subtree holding the leaves [lo, hi).
+func (t *Tree) inclusion(proof [][]byte, index, lo, hi uint64) [][]byte {
+	if hi-lo == 1 {
+		return proof
+	}
+
+	k := split(hi - lo)
+	if index < k {
+		proof = t.inclusion(proof, index, lo, lo+k)
+		return append(proof, t.hashRange(lo+k, hi))
+	}
+
+	proof = t.inclusion(proof, index-k, lo+k, hi)
+	return append(proof, t.hashRange(lo, lo+k))
+}
+
+// InclusionProof returns the hashes proving that the leaf at index
+// was in the tree when it held size leaves, ordered from the leaf up
+// to the root.
+func (t *Tree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Len() || index >= size {
+		return nil, ErrOutOfRange
+	}
+
+	return t.inclusion(nil, index, 0, size), nil
+}
+
+// consistency appends the proof that the first m leaves of the
+// subtree holding the leaves [lo, hi) are unchanged.  whole is set if
+// the m leaves are the whole of the old tree, whose root the verifier
+// already has.
+func (t *Tree) consistency(proof [][]byte, m, lo, hi uint64, whole bool) [][]byte {
+	if m == hi-lo {
+		if whole {
+			return proof
+		}
+		return append(proof, t.hashRange(lo, hi))
+	}
+
+	k := split(hi - lo)
+	if m <= k {
+		proof = t.consistency(proof, m, lo, lo+k, whole)
+		return append(proof, t.hashRange(lo+k, hi))
+	}
+
+	proof = t.consistency(proof, m-k, lo+k, hi, false)
+	return append(proof, t.hashRange(lo, lo+k))
+}
+
+// ConsistencyProof returns the hashes proving that the tree as it was
+// when it held first leaves is a prefix of the tree when it held
+// second leaves.  The proof is empty if first is zero or equal to
+// second.
+func (t *Tree) ConsistencyProof(first, second uint64) ([][]byte, error) {
+	if second > t.Len() || first > second {
+		return nil, ErrOutOfRange
+	}
+
+	if first == 0 || first == second {
+		return [][]byte{}, nil
+	}
+
+	return t.consistency(nil, first, 0, second, true), nil
+}
+
+// VerifyInclusion checks that proof shows the leaf with the provided
+// leaf hash to be at index in the tree of the provided size with the
+// provided root.  Returns ErrInvalidProof if it does not.
+func (h Hasher) VerifyInclusion(index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	if index >= size {
+		return ErrInvalidProof
+	}
+
+	// fn and sn are the positions of the current node and of the
+	// tree's last node on each level as the path is walked up
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = h.HashChildren(p, r)
+			// skip the levels on which the node has no sibling
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = h.HashChildren(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// VerifyConsistency checks that proof shows the tree of size first
+// with root firstRoot to be a prefix of the tree of size second with
+// root secondRoot.  Returns ErrInvalidProof if it does not.
+func (h Hasher) VerifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case first > second:
+		return ErrInvalidProof
+	case first == second:
+		if len(proof) > 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case first == 0:
+		if len(proof) > 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	case len(proof) == 0:
+		return ErrInvalidProof
+	}
+
+	// a first tree that is a complete subtree is not in the proof
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	// fr and sr are rebuilt roots of the first and second trees
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = h.HashChildren(c, fr)
+			sr = h.HashChildren(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = h.HashChildren(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTree(n int) *Tree {
+	tree := New(SHA256)
+	for i := 0; i < n; i++ {
+		tree.Append([]byte{byte(i)})
+	}
+
+	return tree
+}
+
+// tamper returns a copy of the proof with one bit of one hash
+// flipped.
+func tamper(proof [][]byte, i int) [][]byte {
+	tampered := make([][]byte, len(proof))
+	copy(tampered, proof)
+	tampered[i] = append([]byte{}, proof[i]...)
+	tampered[i][0] ^= 1
+	return tampered
+}
+
+func TestInclusionProof(t *testing.T) {
+	tree := testTree(40)
+	for size := uint64(1); size <= 40; size++ {
+		root, _ := tree.RootAt(size)
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index, size)
+			if !assert.Nil(t, err) {
+				return
+			}
+			leaf, _ := tree.LeafHash(index)
+			if !assert.Nil(t, SHA256.VerifyInclusion(index, size, leaf, proof, root)) {
+				return
+			}
+
+			// the proof must not verify anything else
+			other, _ := tree.LeafHash((index + 1) % 40)
+			assert.Equal(t, ErrInvalidProof, SHA256.VerifyInclusion(index, size, other, proof, root))
+			if size > 1 {
+				assert.Equal(t, ErrInvalidProof,
+					SHA256.VerifyInclusion((index+1)%size, size, leaf, proof, root))
+				assert.Equal(t, ErrInvalidProof,
+					SHA256.VerifyInclusion(index, size, leaf, tamper(proof, 0), root))
+				assert.Equal(t, ErrInvalidProof,
+					SHA256.VerifyInclusion(index, size, leaf, proof[1:], root))
+			}
+			assert.Equal(t, ErrInvalidProof,
+				SHA256.VerifyInclusion(index, size, leaf, append(proof, leaf), root))
+		}
+	}
+}
+
+func TestInclusionProofOutOfRange(t *testing.T) {
+	tree := testTree(5)
+	_, err := tree.InclusionProof(5, 5)
+	assert.Equal(t, ErrOutOfRange, err)
+	_, err = tree.InclusionProof(0, 6)
+	assert.Equal(t, ErrOutOfRange, err)
+
+	leaf, _ := tree.LeafHash(0)
+	assert.Equal(t, ErrInvalidProof, SHA256.VerifyInclusion(5, 5, leaf, nil, tree.Root()))
+}
+
+func TestConsistencyProof(t *testing.T) {
+	tree := testTree(40)
+	for second := uint64(1); second <= 40; second++ {
+		secondRoot, _ := tree.RootAt(second)
+		for first := uint64(0); first <= second; first++ {
+			firstRoot, _ := tree.RootAt(first)
+			proof, err := tree.ConsistencyProof(first, second)
+			if !assert.Nil(t, err) {
+				return
+			}
+			if !assert.Nil(t, SHA256.VerifyConsistency(first, second, firstRoot, secondRoot, proof)) {
+				return
+			}
+
+			if first == 0 || first == second {
+				assert.Len(t, proof, 0)
+				continue
+			}
+
+			otherRoot, _ := tree.RootAt(first - 1)
+			assert.Equal(t, ErrInvalidProof,
+				SHA256.VerifyConsistency(first, second, otherRoot, secondRoot, proof))
+			assert.Equal(t, ErrInvalidProof,
+				SHA256.VerifyConsistency(first, second, firstRoot, otherRoot, proof))
+			for i := range proof {
+				assert.Equal(t, ErrInvalidProof,
+					SHA256.VerifyConsistency(first, second, firstRoot, secondRoot, tamper(proof, i)))
+			}
+		}
+	}
+}
+
+func TestConsistencyEdgeCases(t *testing.T) {
+	tree := testTree(8)
+	_, err := tree.ConsistencyProof(3, 9)
+	assert.Equal(t, ErrOutOfRange, err)
+	_, err = tree.ConsistencyProof(5, 4)
+	assert.Equal(t, ErrOutOfRange, err)
+
+	root4, _ := tree.RootAt(4)
+	root8 := tree.Root()
+	assert.Equal(t, ErrInvalidProof, SHA256.VerifyConsistency(4, 8, root4, root8, nil))
+	assert.Equal(t, ErrInvalidProof, SHA256.VerifyConsistency(8, 4, root8, root4, nil))
+	assert.Equal(t, ErrInvalidProof, SHA256.VerifyConsistency(8, 8, root8, root4, nil))
+	assert.Equal(t, ErrInvalidProof, SHA256.VerifyConsistency(0, 8, nil, root8, [][]byte{root4}))
+}
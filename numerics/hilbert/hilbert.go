@@ -0,0 +1,190 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+/*
+Package hilbert maps points in n-dimensional space to positions along
+a Hilbert curve and back.  The curve visits every cell of a grid once,
+moving to a neighboring cell at each step, so points that are close
+along the curve are close in space.  This makes Hilbert indices useful
+as sort keys for spatial data: sorting by index clusters nearby points
+and a range of indices covers a compact region.
+
+A Curve is created for a number of dimensions and a number of bits per
+coordinate, giving a grid of 2^bits cells in each dimension.  The index
+holds bits times dimensions bits, so at most 64 bits can be split
+between the dimensions: 32 bits each in 2D, 21 in 3D and so on.
+
+Encoding and decoding use Skilling's method from "Programming the
+Hilbert curve", which transforms the coordinates in place and then
+interleaves their bits.
+
+Performance characteristics:
+Encode: O(bits * dimensions)
+Decode: O(bits * dimensions)
+*/
+package hilbert
+
+// Curve is a Hilbert curve through a grid of a fixed number of
+// dimensions and bits per coordinate.
+type Curve struct {
+	dimensions int
+	bits       uint
+}
+
+// Dimensions returns the number of coordinates of every point.
+func (c *Curve) Dimensions() int {
+	return c.dimensions
+}
+
+// Bits returns the number of bits of every coordinate.
+func (c *Curve) Bits() uint {
+	return c.bits
+}
+
+// Encode returns the position along the curve of the point with the
+// provided coordinates.  There must be a coordinate for every
+// dimension and each must fit in the curve's bits.
+func (c *Curve) Encode(coords ...uint64) uint64 {
+	if len(coords) != c.dimensions {
+		panic(`Number of coordinates does not match the number of dimensions.`)
+	}
+
+	x := make([]uint64, c.dimensions)
+	for i, coord := range coords {
+		if c.bits < 64 && coord>>c.bits != 0 {
+			panic(`Coordinate does not fit in the curve's bits.`)
+		}
+		x[i] = coord
+	}
+
+	c.axesToTranspose(x)
+	return c.interleave(x)
+}
+
+// Decode returns the coordinates of the point at the provided position
+// along the curve.  The position must fit in the curve's bits.
+func (c *Curve) Decode(index uint64) []uint64 {
+	if total := c.bits * uint(c.dimensions); total < 64 && index>>total != 0 {
+		panic(`Index does not fit in the curve's bits.`)
+	}
+
+	x := c.deinterleave(index)
+	c.transposeToAxes(x)
+	return x
+}
+
+// axesToTranspose transforms coordinates into the transposed form of
+// their index, where the index's bits are read off by taking the same
+// bit of each coordinate in turn from the most significant.
+func (c *Curve) axesToTranspose(x []uint64) {
+	n := len(x)
+	m := uint64(1) << (c.bits - 1)
+
+	// inverse undo
+	for q := m; q > 1; q >>= 1 {
+		p := q - 1
+		for i := 0; i < n; i++ {
+			if x[i]&q != 0 {
+				x[0] ^= p
+			} else {
+				t := (x[0] ^ x[i]) & p
+				x[0] ^= t
+				x[i] ^= t
+			}
+		}
+	}
+
+	// gray encode
+	for i := 1; i < n; i++ {
+		x[i] ^= x[i-1]
+	}
+
+	t := uint64(0)
+	for q := m; q > 1; q >>= 1 {
+		if x[n-1]&q != 0 {
+			t ^= q - 1
+		}
+	}
+
+	for i := range x {
+		x[i] ^= t
+	}
+}
+
+// transposeToAxes reverses axesToTranspose.
+func (c *Curve) transposeToAxes(x []uint64) {
+	n := len(x)
+	// wraps to zero when bits is 64, which ends the loop below just
+	// the same
+	end := uint64(2) << (c.bits - 1)
+
+	// gray decode
+	t := x[n-1] >> 1
+	for i := n - 1; i > 0; i-- {
+		x[i] ^= x[i-1]
+	}
+	x[0] ^= t
+
+	// undo excess work
+	for q := uint64(2); q != end; q <<= 1 {
+		p := q - 1
+		for i := n - 1; i >= 0; i-- {
+			if x[i]&q != 0 {
+				x[0] ^= p
+			} else {
+				t := (x[0] ^ x[i]) & p
+				x[0] ^= t
+				x[i] ^= t
+			}
+		}
+	}
+}
+
+// interleave returns the index held by the provided transposed form.
+func (c *Curve) interleave(x []uint64) uint64 {
+	index := uint64(0)
+	for b := int(c.bits) - 1; b >= 0; b-- {
+		for i := range x {
+			index = index<<1 | (x[i]>>uint(b))&1
+		}
+	}
+
+	return index
+}
+
+// deinterleave returns the transposed form of the provided index.
+func (c *Curve) deinterleave(index uint64) []uint64 {
+	x := make([]uint64, c.dimensions)
+	shift := c.bits * uint(c.dimensions)
+	for b := int(c.bits) - 1; b >= 0; b-- {
+		for i := range x {
+			shift--
+			x[i] |= (index >> shift & 1) << uint(b)
+		}
+	}
+
+	return x
+}
+
+// New returns a curve through a grid of the provided number of
+// dimensions with the provided number of bits per coordinate.  Both
+// must be positive and bits times dimensions can be at most 64.
+func New(dimensions int, bits uint) *Curve {
+	if dimensions < 1 || bits < 1 || uint(dimensions)*bits > 64 {
+		panic(`Invalid number of dimensions or bits provided.`)
+	}
+
+	return &Curve{dimensions: dimensions, bits: bits}
+}
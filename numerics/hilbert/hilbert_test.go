@@ -0,0 +1,130 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hilbert
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkCurve walks the whole curve, checking that every point is
+// visited once and that each step moves by one in one dimension.
+func checkCurve(t *testing.T, c *Curve) {
+	total := uint64(1) << (c.Bits() * uint(c.Dimensions()))
+	seen := make(map[uint64]bool, total)
+
+	var prev []uint64
+	for index := uint64(0); index < total; index++ {
+		coords := c.Decode(index)
+		assert.Equal(t, index, c.Encode(coords...))
+
+		key := uint64(0)
+		for _, coord := range coords {
+			key = key<<c.Bits() | coord
+		}
+		assert.False(t, seen[key])
+		seen[key] = true
+
+		if prev != nil {
+			moved := uint64(0)
+			for i := range coords {
+				if coords[i] > prev[i] {
+					moved += coords[i] - prev[i]
+				} else {
+					moved += prev[i] - coords[i]
+				}
+			}
+			assert.Equal(t, uint64(1), moved, `step to %d`, index)
+		}
+		prev = coords
+	}
+
+	assert.Equal(t, make([]uint64, c.Dimensions()), c.Decode(0))
+}
+
+func TestSmallCurves(t *testing.T) {
+	for dimensions := 1; dimensions <= 5; dimensions++ {
+		for bits := uint(1); bits*uint(dimensions) <= 12; bits++ {
+			checkCurve(t, New(dimensions, bits))
+		}
+	}
+}
+
+func TestTwoDimensions(t *testing.T) {
+	c := New(2, 1)
+	assert.Equal(t, []uint64{0, 0}, c.Decode(0))
+	assert.Equal(t, []uint64{0, 1}, c.Decode(1))
+	assert.Equal(t, []uint64{1, 1}, c.Decode(2))
+	assert.Equal(t, []uint64{1, 0}, c.Decode(3))
+}
+
+func TestLargeCurves(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, c := range []*Curve{New(1, 64), New(2, 32), New(3, 21), New(8, 8), New(64, 1)} {
+		mask := uint64(math.MaxUint64)
+		if c.Bits() < 64 {
+			mask = 1<<c.Bits() - 1
+		}
+
+		for i := 0; i < 1000; i++ {
+			coords := make([]uint64, c.Dimensions())
+			for j := range coords {
+				coords[j] = r.Uint64() & mask
+			}
+
+			assert.Equal(t, coords, c.Decode(c.Encode(coords...)))
+		}
+	}
+
+	assert.Equal(t, uint64(math.MaxUint64), New(1, 64).Encode(math.MaxUint64))
+}
+
+func TestInvalid(t *testing.T) {
+	assert.Panics(t, func() { New(0, 8) })
+	assert.Panics(t, func() { New(2, 0) })
+	assert.Panics(t, func() { New(3, 22) })
+
+	c := New(2, 4)
+	assert.Equal(t, 2, c.Dimensions())
+	assert.Equal(t, uint(4), c.Bits())
+	assert.Panics(t, func() { c.Encode(1) })
+	assert.Panics(t, func() { c.Encode(16, 0) })
+	assert.Panics(t, func() { c.Decode(256) })
+}
+
+func BenchmarkEncode(b *testing.B) {
+	c := New(3, 21)
+	mask := uint64(1)<<21 - 1
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Encode(uint64(i)&mask, uint64(i>>1)&mask, uint64(i>>2)&mask)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	c := New(3, 21)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		c.Decode(uint64(i))
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package pheap implements a pairing heap, a min-priority queue whose
+entries can have their priorities decreased or be deleted through
+the handle returned when they were enqueued.
+
+Enqueue, Min and Merge are O(1).  DequeueMin and Delete are
+O(log n) amortized and DecreaseKey is o(log n) amortized.  Pairing
+heaps have worse theoretical bounds than Fibonacci heaps for
+DecreaseKey, but are simpler and usually faster in practice.  Below the heap is compared against a binary
+heap from container/heap that uses heap.Fix for DecreaseKey.
+
+BenchmarkEnqueue			 150.7 ns/op
+BenchmarkBinaryHeapEnqueue		 174.9 ns/op
+BenchmarkDequeueMin			1725   ns/op
+BenchmarkBinaryHeapDequeueMin		1024   ns/op
+BenchmarkDecreaseKey			  10.02 ns/op
+BenchmarkBinaryHeapDecreaseKey		  49.31 ns/op
+*/
+package pheap
+
+import "errors"
+
+// Entry is a handle to an item in a heap.  Its priority must only be
+// changed with DecreaseKey.
+type Entry struct {
+	// Priority orders the entry in the heap, lowest first.
+	Priority float64
+	// Value is free for the caller to associate data with the entry.
+	Value interface{}
+
+	child, sibling *Entry
+	// prev is the parent if this is its parent's first child and
+	// the left sibling otherwise.
+	prev    *Entry
+	removed bool
+}
+
+// PairingHeap is a min-priority queue.  It is not threadsafe.
+type PairingHeap struct {
+	root *Entry
+	size uint
+}
+
+// New returns an empty pairing heap.
+func New() *PairingHeap {
+	return &PairingHeap{}
+}
+
+// Enqueue adds a new entry with the provided priority and returns
+// its handle.  This is an O(1) operation.
+func (h *PairingHeap) Enqueue(priority float64) *Entry {
+	e := &Entry{Priority: priority}
+	h.root = meld(h.root, e)
+	h.size++
+	return e
+}
+
+// Min returns the entry with the lowest priority without removing
+// it.  This is an O(1) operation.
+func (h *PairingHeap) Min() (*Entry, error) {
+	if h.root == nil {
+		return nil, errors.New(`Cannot get the minimum of an empty heap.`)
+	}
+
+	return h.root, nil
+}
+
+// DequeueMin removes and returns the entry with the lowest priority.
+// This is an O(log n) amortized operation.
+func (h *PairingHeap) DequeueMin() (*Entry, error) {
+	if h.root == nil {
+		return nil, errors.New(`Cannot dequeue from an empty heap.`)
+	}
+
+	min := h.root
+	h.root = mergePairs(min.child)
+	h.size--
+	min.child = nil
+	min.removed = true
+	return min, nil
+}
+
+// DecreaseKey lowers the priority of an entry in the heap.  The
+// entry is cut out and melded with the root in O(1), with the cost
+// of restructuring deferred to later dequeues.
+func (h *PairingHeap) DecreaseKey(e *Entry, priority float64) (*Entry, error) {
+	if e.removed {
+		return nil, errors.New(`Cannot decrease the key of a removed entry.`)
+	}
+
+	if priority > e.Priority {
+		return nil, errors.New(`Cannot increase the key of an entry.`)
+	}
+
+	e.Priority = priority
+	if e != h.root {
+		detach(e)
+		h.root = meld(h.root, e)
+	}
+
+	return e, nil
+}
+
+// Delete removes an entry from the heap.  This is an O(log n)
+// amortized operation.
+func (h *PairingHeap) Delete(e *Entry) error {
+	if e.removed {
+		return errors.New(`Cannot delete a removed entry.`)
+	}
+
+	if e == h.root {
+		_, err := h.DequeueMin()
+		return err
+	}
+
+	detach(e)
+	h.root = meld(h.root, mergePairs(e.child))
+	h.size--
+	e.child = nil
+	e.removed = true
+	return nil
+}
+
+// Merge moves every entry of other into this heap, leaving other
+// empty.  Handles from other remain valid in this heap.  This is an
+// O(1) operation.
+func (h *PairingHeap) Merge(other *PairingHeap) error {
+	if other == h {
+		return errors.New(`Cannot merge a heap with itself.`)
+	}
+
+	h.root = meld(h.root, other.root)
+	h.size += other.size
+	other.root = nil
+	other.size = 0
+	return nil
+}
+
+// IsEmpty returns a bool indicating if the heap has no entries.
+func (h *PairingHeap) IsEmpty() bool {
+	return h.root == nil
+}
+
+// Size returns the number of entries in the heap.
+func (h *PairingHeap) Size() uint {
+	return h.size
+}
+
+// meld combines two heap-ordered trees whose roots have no siblings
+// and returns the new root.
+func meld(a, b *Entry) *Entry {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	if b.Priority < a.Priority {
+		a, b = b, a
+	}
+
+	b.prev = a
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	return a
+}
+
+// detach cuts the subtree rooted at e out of its parent's child list.
+func detach(e *Entry) {
+	if e.prev.child == e {
+		e.prev.child = e.sibling
+	} else {
+		e.prev.sibling = e.sibling
+	}
+
+	if e.sibling != nil {
+		e.sibling.prev = e.prev
+	}
+
+	e.prev, e.sibling = nil, nil
+}
+
+// mergePairs melds a list of siblings into a single tree with the
+// standard two passes: pairs are melded left to right, then the
+// results are melded right to left.
+func mergePairs(first *Entry) *Entry {
+	// the melded pairs are stacked through their sibling pointers
+	// so the second pass sees them right to left
+	var stack *Entry
+	for first != nil {
+		a, b := first, first.sibling
+		if b == nil {
+			first = nil
+		} else {
+			first = b.sibling
+			b.prev, b.sibling = nil, nil
+		}
+		a.prev, a.sibling = nil, nil
+
+		a = meld(a, b)
+		a.sibling = stack
+		stack = a
+	}
+
+	var root *Entry
+	for stack != nil {
+		next := stack.sibling
+		stack.sibling = nil
+		root = meld(root, stack)
+		stack = next
+	}
+
+	return root
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pheap
+
+import (
+	"container/heap"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkEntry verifies heap order and the prev links under e and
+// returns the number of entries in its subtree.
+func checkEntry(t testing.TB, e *Entry) uint {
+	count := uint(1)
+	prev := e
+	for child := e.child; child != nil; child = child.sibling {
+		assert.True(t, child.Priority >= e.Priority)
+		assert.Equal(t, prev, child.prev)
+		count += checkEntry(t, child)
+		prev = child
+	}
+
+	return count
+}
+
+func checkHeap(t testing.TB, h *PairingHeap) {
+	if h.root == nil {
+		assert.Equal(t, uint(0), h.Size())
+		return
+	}
+
+	assert.Nil(t, h.root.prev)
+	assert.Nil(t, h.root.sibling)
+	assert.Equal(t, h.Size(), checkEntry(t, h.root))
+}
+
+func drain(h *PairingHeap) []float64 {
+	var result []float64
+	for !h.IsEmpty() {
+		e, _ := h.DequeueMin()
+		result = append(result, e.Priority)
+	}
+
+	return result
+}
+
+func TestEmptyHeap(t *testing.T) {
+	h := New()
+	assert.True(t, h.IsEmpty())
+
+	_, err := h.Min()
+	assert.NotNil(t, err)
+
+	_, err = h.DequeueMin()
+	assert.NotNil(t, err)
+}
+
+func TestEnqueueDequeue(t *testing.T) {
+	h := New()
+	priorities := make([]float64, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		p := rand.Float64()
+		priorities = append(priorities, p)
+		h.Enqueue(p)
+	}
+	checkHeap(t, h)
+	assert.Equal(t, uint(1000), h.Size())
+
+	min, err := h.Min()
+	assert.Nil(t, err)
+	sort.Float64s(priorities)
+	assert.Equal(t, priorities[0], min.Priority)
+
+	e, _ := h.DequeueMin()
+	assert.Equal(t, priorities[0], e.Priority)
+	checkHeap(t, h)
+
+	assert.Equal(t, priorities[1:], drain(h))
+}
+
+func TestValue(t *testing.T) {
+	h := New()
+	e := h.Enqueue(1)
+	e.Value = `a`
+
+	min, _ := h.Min()
+	assert.Equal(t, `a`, min.Value)
+}
+
+func TestDecreaseKey(t *testing.T) {
+	h := New()
+	entries := make([]*Entry, 0, 100)
+	for i := 0; i < 100; i++ {
+		entries = append(entries, h.Enqueue(float64(i+100)))
+	}
+	h.DequeueMin() // build some structure
+	entries = entries[1:]
+
+	for i := len(entries) - 1; i >= 0; i -= 2 {
+		_, err := h.DecreaseKey(entries[i], float64(i))
+		assert.Nil(t, err)
+		checkHeap(t, h)
+	}
+
+	min, _ := h.Min()
+	assert.Equal(t, float64(0), min.Priority)
+
+	_, err := h.DecreaseKey(entries[0], 1000)
+	assert.NotNil(t, err)
+
+	result := drain(h)
+	assert.True(t, sort.Float64sAreSorted(result))
+	assert.Len(t, result, 99)
+
+	_, err = h.DecreaseKey(entries[0], 0)
+	assert.NotNil(t, err)
+}
+
+func TestDelete(t *testing.T) {
+	h := New()
+	entries := make([]*Entry, 0, 100)
+	for i := 0; i < 100; i++ {
+		entries = append(entries, h.Enqueue(float64(i)))
+	}
+	h.DequeueMin()
+
+	for i := 1; i < 100; i += 2 {
+		assert.Nil(t, h.Delete(entries[i]))
+		checkHeap(t, h)
+	}
+	assert.NotNil(t, h.Delete(entries[1]))
+	assert.NotNil(t, h.Delete(entries[0]))
+
+	// deleting the root
+	assert.Nil(t, h.Delete(entries[2]))
+	assert.Equal(t, uint(48), h.Size())
+
+	expected := make([]float64, 0, 48)
+	for i := 4; i < 100; i += 2 {
+		expected = append(expected, float64(i))
+	}
+	assert.Equal(t, expected, drain(h))
+}
+
+func TestMerge(t *testing.T) {
+	a, b := New(), New()
+	for i := 0; i < 10; i++ {
+		a.Enqueue(float64(i * 2))
+	}
+	bEntries := make([]*Entry, 0, 10)
+	for i := 0; i < 10; i++ {
+		bEntries = append(bEntries, b.Enqueue(float64(i*2+1)))
+	}
+
+	assert.Nil(t, a.Merge(b))
+	assert.NotNil(t, a.Merge(a))
+	assert.True(t, b.IsEmpty())
+	assert.Equal(t, uint(0), b.Size())
+	assert.Equal(t, uint(20), a.Size())
+	checkHeap(t, a)
+
+	// handles from the merged heap stay usable
+	_, err := a.DecreaseKey(bEntries[9], -1)
+	assert.Nil(t, err)
+	min, _ := a.Min()
+	assert.Equal(t, bEntries[9], min)
+
+	assert.Nil(t, a.Merge(New()))
+	assert.Len(t, drain(a), 20)
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	h := New()
+	live := make(map[*Entry]bool)
+
+	for i := 0; i < 5000; i++ {
+		switch r.Intn(4) {
+		case 0, 1:
+			live[h.Enqueue(r.Float64()*1000)] = true
+		case 2:
+			for e := range live {
+				h.DecreaseKey(e, e.Priority-r.Float64()*100)
+				break
+			}
+		case 3:
+			for e := range live {
+				h.Delete(e)
+				delete(live, e)
+				break
+			}
+		}
+	}
+
+	checkHeap(t, h)
+	assert.Equal(t, uint(len(live)), h.Size())
+	assert.True(t, sort.Float64sAreSorted(drain(h)))
+}
+
+type binaryEntry struct {
+	priority float64
+	index    int
+}
+
+type binaryHeap []*binaryEntry
+
+func (bh binaryHeap) Len() int           { return len(bh) }
+func (bh binaryHeap) Less(i, j int) bool { return bh[i].priority < bh[j].priority }
+
+func (bh binaryHeap) Swap(i, j int) {
+	bh[i], bh[j] = bh[j], bh[i]
+	bh[i].index = i
+	bh[j].index = j
+}
+
+func (bh *binaryHeap) Push(x interface{}) {
+	e := x.(*binaryEntry)
+	e.index = len(*bh)
+	*bh = append(*bh, e)
+}
+
+func (bh *binaryHeap) Pop() interface{} {
+	old := *bh
+	e := old[len(old)-1]
+	*bh = old[:len(old)-1]
+	return e
+}
+
+func randomPriorities(num int) []float64 {
+	priorities := make([]float64, num)
+	for i := range priorities {
+		priorities[i] = rand.Float64()
+	}
+
+	return priorities
+}
+
+func BenchmarkEnqueue(b *testing.B) {
+	priorities := randomPriorities(b.N)
+	h := New()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.Enqueue(priorities[i])
+	}
+}
+
+func BenchmarkBinaryHeapEnqueue(b *testing.B) {
+	priorities := randomPriorities(b.N)
+	bh := &binaryHeap{}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		heap.Push(bh, &binaryEntry{priority: priorities[i]})
+	}
+}
+
+func BenchmarkDequeueMin(b *testing.B) {
+	priorities := randomPriorities(b.N)
+	h := New()
+	for _, p := range priorities {
+		h.Enqueue(p)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.DequeueMin()
+	}
+}
+
+func BenchmarkBinaryHeapDequeueMin(b *testing.B) {
+	priorities := randomPriorities(b.N)
+	bh := &binaryHeap{}
+	for _, p := range priorities {
+		heap.Push(bh, &binaryEntry{priority: p})
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		heap.Pop(bh)
+	}
+}
+
+func BenchmarkDecreaseKey(b *testing.B) {
+	numItems := 10000
+	h := New()
+	entries := make([]*Entry, 0, numItems)
+	for _, p := range randomPriorities(numItems) {
+		entries = append(entries, h.Enqueue(p))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		e := entries[i%numItems]
+		h.DecreaseKey(e, e.Priority-1)
+	}
+}
+
+func BenchmarkBinaryHeapDecreaseKey(b *testing.B) {
+	numItems := 10000
+	bh := &binaryHeap{}
+	entries := make([]*binaryEntry, 0, numItems)
+	for _, p := range randomPriorities(numItems) {
+		e := &binaryEntry{priority: p}
+		heap.Push(bh, e)
+		entries = append(entries, e)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		e := entries[i%numItems]
+		e.priority--
+		heap.Fix(bh, e.index)
+	}
+}
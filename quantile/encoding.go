@@ -0,0 +1,202 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quantile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Sketches are serialized as an 8 byte header, holding a version byte,
+// three reserved zero bytes and the length of the payload as a
+// little-endian uint32, followed by the payload.  The payload holds
+// the relative accuracy, sum, min and max as little-endian float64
+// bits, then the maximum number of buckets, the count and the count of
+// zeros as uvarints, then the positive and negative stores.  A store
+// is written as the varint index of its first bucket, the uvarint
+// number of buckets and the uvarint count of each.
+
+const encodingVersion = 1
+
+const headerSize = 8
+
+// maxEncodedBuckets bounds the buckets a serialized sketch may claim
+// so a corrupt header can't cause a huge allocation.
+const maxEncodedBuckets = 1 << 20
+
+// Serialize writes this sketch to the provided writer.
+func (s *Sketch) Serialize(w io.Writer) error {
+	payload := make([]byte, 0, 64+
+		(len(s.positive.counts)+len(s.negative.counts))*binary.MaxVarintLen64)
+	for _, f := range []float64{s.alpha, s.sum, s.min, s.max} {
+		payload = binary.LittleEndian.AppendUint64(payload, math.Float64bits(f))
+	}
+	payload = binary.AppendUvarint(payload, uint64(s.maxBuckets))
+	payload = binary.AppendUvarint(payload, s.count)
+	payload = binary.AppendUvarint(payload, s.zero)
+	payload = encodeStore(payload, &s.positive)
+	payload = encodeStore(payload, &s.negative)
+
+	header := make([]byte, headerSize)
+	header[0] = encodingVersion
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := w.Write(payload)
+	return err
+}
+
+func encodeStore(payload []byte, s *store) []byte {
+	payload = binary.AppendVarint(payload, int64(s.offset))
+	payload = binary.AppendUvarint(payload, uint64(len(s.counts)))
+	for _, c := range s.counts {
+		payload = binary.AppendUvarint(payload, c)
+	}
+
+	return payload
+}
+
+// decoder reads the fields of a payload, remembering the first error.
+type decoder struct {
+	payload []byte
+	err     error
+}
+
+func (d *decoder) float() float64 {
+	if d.err != nil || len(d.payload) < 8 {
+		d.fail()
+		return 0
+	}
+
+	f := math.Float64frombits(binary.LittleEndian.Uint64(d.payload))
+	d.payload = d.payload[8:]
+	return f
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.payload)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.payload = d.payload[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(d.payload)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.payload = d.payload[n:]
+	return v
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf(`Sketch payload is truncated or corrupt.`)
+	}
+}
+
+func (d *decoder) store(s *store, maxBuckets int) {
+	offset := d.varint()
+	n := d.uvarint()
+	if d.err != nil {
+		return
+	}
+	if n > uint64(maxBuckets) || offset < math.MinInt32 || offset > math.MaxInt32 {
+		d.err = fmt.Errorf(`Store of %d buckets from %d is out of range.`, n, offset)
+		return
+	}
+
+	s.offset = int(offset)
+	s.counts = make([]uint64, n)
+	for i := range s.counts {
+		s.counts[i] = d.uvarint()
+		s.total += s.counts[i]
+	}
+}
+
+// Deserialize reads a sketch written by Sketch.Serialize.
+func Deserialize(r io.Reader) (*Sketch, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if header[0] != encodingVersion {
+		return nil, fmt.Errorf(`Unknown encoding version %d.`, header[0])
+	}
+
+	size := binary.LittleEndian.Uint32(header[4:])
+	if uint64(size) > 64+2*(maxEncodedBuckets+2)*binary.MaxVarintLen64 {
+		return nil, fmt.Errorf(`Sketch of %d bytes is too large.`, size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	d := &decoder{payload: payload}
+	alpha, sum, min, max := d.float(), d.float(), d.float(), d.float()
+	maxBuckets, count, zero := d.uvarint(), d.uvarint(), d.uvarint()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if !(alpha > 0 && alpha < 1) {
+		return nil, fmt.Errorf(`Invalid relative accuracy %g.`, alpha)
+	}
+	if maxBuckets == 0 || maxBuckets > maxEncodedBuckets {
+		return nil, fmt.Errorf(`Invalid maximum of %d buckets.`, maxBuckets)
+	}
+
+	s := NewWithMaxBuckets(alpha, int(maxBuckets))
+	d.store(&s.positive, s.maxBuckets)
+	d.store(&s.negative, s.maxBuckets)
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.payload) > 0 {
+		return nil, fmt.Errorf(`Sketch has %d bytes of trailing data.`, len(d.payload))
+	}
+	if s.positive.total+s.negative.total+zero != count {
+		return nil, fmt.Errorf(`Bucket counts don't add up to the count of %d.`, count)
+	}
+
+	s.zero, s.count = zero, count
+	s.sum, s.min, s.max = sum, min, max
+	return s, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quantile
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	s := NewWithMaxBuckets(0.01, 512)
+	for i := 0; i < 5000; i++ {
+		s.Add(r.NormFloat64() * 50)
+	}
+	s.AddN(0, 7)
+
+	var buf bytes.Buffer
+	assert.Nil(t, s.Serialize(&buf))
+
+	result, err := Deserialize(&buf)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, s, result)
+
+	// an empty sketch round trips too
+	buf.Reset()
+	assert.Nil(t, New(0.05).Serialize(&buf))
+	result, err = Deserialize(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), result.Count())
+	assert.Equal(t, 0.05, result.RelativeAccuracy())
+}
+
+func TestDeserializeCorrupt(t *testing.T) {
+	s := New(0.01)
+	for i := 1; i <= 100; i++ {
+		s.Add(float64(i))
+	}
+
+	var buf bytes.Buffer
+	s.Serialize(&buf)
+	encoded := buf.Bytes()
+
+	_, err := Deserialize(bytes.NewReader(nil))
+	assert.Equal(t, io.EOF, err)
+
+	_, err = Deserialize(bytes.NewReader(encoded[:len(encoded)-1]))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = Deserialize(bytes.NewReader(encoded[:headerSize]))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	corrupt := bytes.Clone(encoded)
+	corrupt[0] = 2
+	_, err = Deserialize(bytes.NewReader(corrupt))
+	assert.NotNil(t, err)
+
+	// an alpha of zero
+	corrupt = bytes.Clone(encoded)
+	copy(corrupt[headerSize:], make([]byte, 8))
+	_, err = Deserialize(bytes.NewReader(corrupt))
+	assert.NotNil(t, err)
+
+	// a bucket count that doesn't match the total
+	corrupt = bytes.Clone(encoded)
+	corrupt[len(corrupt)-3]++
+	_, err = Deserialize(bytes.NewReader(corrupt))
+	assert.NotNil(t, err)
+
+	// a payload length larger than any valid sketch
+	corrupt = bytes.Clone(encoded)
+	corrupt[7] = 0xff
+	_, err = Deserialize(bytes.NewReader(corrupt))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package quantile implements DDSketch, a sketch that estimates the
+quantiles of a stream of values, such as the 99th percentile of
+request latencies, in a fixed amount of memory.  Every estimate is
+within a chosen relative accuracy alpha of a value that truly has the
+requested rank: with alpha = 0.01, a reported p99 of 200ms means some
+value at the 99th percentile lies between 198ms and 202ms.
+
+Values are counted in buckets whose bounds grow geometrically by
+gamma = (1+alpha)/(1-alpha), so bucket i holds the values in
+(gamma^(i-1), gamma^i] and any value in it is estimated to within
+alpha by a single representative.  Covering a range of values from
+lo to hi takes log(hi/lo)/log(gamma) buckets, about 1,700 for
+nanoseconds through a week at alpha = 0.01.  Should the values span
+more buckets than the sketch allows, the lowest buckets are collapsed
+together, which keeps the guarantee for the upper quantiles that
+latency tracking cares about.  Negative values are counted in a mirror
+image of the positive buckets.
+
+Sketches of the same relative accuracy can be merged exactly, as if
+one sketch had seen both streams, for aggregating percentiles across
+machines or time windows, and can be serialized to be shipped between
+them.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Add: O(1), or O(b) when a value falls outside the buckets so far
+Quantile: O(b)
+Merge: O(b)
+Space: O(b)
+where b is the number of buckets, at most the sketch's maximum
+*/
+package quantile
+
+import "math"
+
+// DefaultMaxBuckets is the number of buckets a sketch created with
+// New keeps for each sign of value.
+const DefaultMaxBuckets = 2048
+
+// store counts values by bucket index.  Buckets are kept densely from
+// offset.
+type store struct {
+	counts []uint64
+	offset int
+	total  uint64
+}
+
+func (s *store) last() int {
+	return s.offset + len(s.counts) - 1
+}
+
+// resize makes the store cover the buckets [lo, hi], folding the
+// counts of any buckets below lo into lo.
+func (s *store) resize(lo, hi int) {
+	switch {
+	case len(s.counts) == 0:
+		s.counts, s.offset = make([]uint64, hi-lo+1), lo
+		return
+	case lo == s.offset:
+		for s.last() < hi {
+			s.counts = append(s.counts, 0)
+		}
+		return
+	}
+
+	counts := make([]uint64, hi-lo+1)
+	for i, c := range s.counts {
+		counts[max(s.offset+i, lo)-lo] += c
+	}
+	s.counts, s.offset = counts, lo
+}
+
+// cover makes the store cover the buckets [lo, hi] as well as those it
+// does, collapsing its lowest buckets to keep to maxBuckets, and
+// returns the lowest bucket it covers.
+func (s *store) cover(lo, hi, maxBuckets int) int {
+	if len(s.counts) > 0 {
+		lo, hi = min(lo, s.offset), max(hi, s.last())
+	}
+	lo = max(lo, hi-maxBuckets+1)
+
+	if len(s.counts) == 0 || lo != s.offset || hi != s.last() {
+		s.resize(lo, hi)
+	}
+
+	return lo
+}
+
+func (s *store) add(index int, count uint64, maxBuckets int) {
+	lo := s.cover(index, index, maxBuckets)
+	s.counts[max(index, lo)-s.offset] += count
+	s.total += count
+}
+
+func (s *store) merge(other *store, maxBuckets int) {
+	if other.total == 0 {
+		return
+	}
+
+	lo := s.cover(other.offset, other.last(), maxBuckets)
+	for i, c := range other.counts {
+		s.counts[max(other.offset+i, lo)-s.offset] += c
+	}
+	s.total += other.total
+}
+
+func (s *store) reset() {
+	s.counts, s.offset, s.total = s.counts[:0], 0, 0
+}
+
+// Sketch is a DDSketch.
+type Sketch struct {
+	alpha, gamma, logGamma float64
+	maxBuckets             int
+	// positive counts positive values by their bucket and negative
+	// counts negative values by the bucket of their magnitude.
+	positive, negative store
+	zero               uint64
+	count              uint64
+	sum, min, max      float64
+}
+
+// index returns the bucket holding the positive value v.
+func (s *Sketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / s.logGamma))
+}
+
+// value returns the representative of bucket i, which is within alpha
+// of every value in the bucket.
+func (s *Sketch) value(i int) float64 {
+	return 2 * math.Exp(float64(i)*s.logGamma) / (s.gamma + 1)
+}
+
+// Add adds the value to the sketch.  NaN and infinite values are
+// ignored.
+func (s *Sketch) Add(v float64) {
+	s.AddN(v, 1)
+}
+
+// AddN adds the value to the sketch count times.  NaN and infinite
+// values are ignored.
+func (s *Sketch) AddN(v float64, count uint64) {
+	if count == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+
+	switch {
+	case v > 0:
+		s.positive.add(s.index(v), count, s.maxBuckets)
+	case v < 0:
+		s.negative.add(s.index(-v), count, s.maxBuckets)
+	default:
+		s.zero += count
+	}
+
+	if s.count == 0 {
+		s.min, s.max = v, v
+	} else {
+		s.min, s.max = min(s.min, v), max(s.max, v)
+	}
+	s.count += count
+	s.sum += v * float64(count)
+}
+
+// Quantile returns an estimate of the value at quantile q, which must
+// be in [0, 1], and a bool indicating if there is one.  There is none
+// if the sketch is empty or q is out of range.  Quantile 0 is the
+// smallest value added and quantile 1 the largest.
+func (s *Sketch) Quantile(q float64) (float64, bool) {
+	if s.count == 0 || !(q >= 0 && q <= 1) {
+		return 0, false
+	}
+
+	switch q {
+	case 0:
+		return s.min, true
+	case 1:
+		return s.max, true
+	}
+
+	v := s.rankValue(q * float64(s.count-1))
+	return min(max(v, s.min), s.max), true
+}
+
+// rankValue returns the representative of the bucket holding the
+// value of the provided rank, counting from zero.
+func (s *Sketch) rankValue(rank float64) float64 {
+	var seen uint64
+	for i := len(s.negative.counts) - 1; i >= 0; i-- {
+		if seen += s.negative.counts[i]; float64(seen) > rank {
+			return -s.value(s.negative.offset + i)
+		}
+	}
+
+	if seen += s.zero; float64(seen) > rank {
+		return 0
+	}
+
+	for i, c := range s.positive.counts {
+		if seen += c; float64(seen) > rank {
+			return s.value(s.positive.offset + i)
+		}
+	}
+
+	return s.max
+}
+
+// Merge adds the values counted by the other sketch to this one.  The
+// sketches must have the same relative accuracy.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.alpha != other.alpha {
+		return MismatchError{alpha: s.alpha, otherAlpha: other.alpha}
+	}
+
+	if other.count == 0 {
+		return nil
+	}
+
+	s.positive.merge(&other.positive, s.maxBuckets)
+	s.negative.merge(&other.negative, s.maxBuckets)
+	s.zero += other.zero
+	if s.count == 0 {
+		s.min, s.max = other.min, other.max
+	} else {
+		s.min, s.max = min(s.min, other.min), max(s.max, other.max)
+	}
+	s.count += other.count
+	s.sum += other.sum
+	return nil
+}
+
+// Count returns the number of values added.
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Sum returns the sum of the values added.
+func (s *Sketch) Sum() float64 {
+	return s.sum
+}
+
+// Min returns the smallest value added, or zero if the sketch is
+// empty.
+func (s *Sketch) Min() float64 {
+	return s.min
+}
+
+// Max returns the largest value added, or zero if the sketch is
+// empty.
+func (s *Sketch) Max() float64 {
+	return s.max
+}
+
+// RelativeAccuracy returns the relative accuracy of the sketch's
+// estimates.
+func (s *Sketch) RelativeAccuracy() float64 {
+	return s.alpha
+}
+
+// Reset empties the sketch.
+func (s *Sketch) Reset() {
+	s.positive.reset()
+	s.negative.reset()
+	s.zero, s.count = 0, 0
+	s.sum, s.min, s.max = 0, 0, 0
+}
+
+// New returns an empty sketch whose estimates are within the provided
+// relative accuracy, which must be between 0 and 1 exclusive, keeping
+// up to DefaultMaxBuckets buckets for each sign of value.
+func New(relativeAccuracy float64) *Sketch {
+	return NewWithMaxBuckets(relativeAccuracy, DefaultMaxBuckets)
+}
+
+// NewWithMaxBuckets returns an empty sketch like New that keeps up to
+// maxBuckets buckets for each sign of value.
+func NewWithMaxBuckets(relativeAccuracy float64, maxBuckets int) *Sketch {
+	if !(relativeAccuracy > 0 && relativeAccuracy < 1) {
+		panic(`Relative accuracy must be between 0 and 1.`)
+	}
+
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &Sketch{
+		alpha:      relativeAccuracy,
+		gamma:      gamma,
+		logGamma:   math.Log(gamma),
+		maxBuckets: max(maxBuckets, 1),
+	}
+}
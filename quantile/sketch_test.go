@@ -0,0 +1,216 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quantile
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testQuantiles = []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999}
+
+// checkAccuracy asserts that every estimate of the sketch is within
+// its relative accuracy of the exact quantile of the sorted values.
+func checkAccuracy(t *testing.T, s *Sketch, sorted []float64, qs []float64) {
+	alpha := s.RelativeAccuracy()
+	for _, q := range qs {
+		v, ok := s.Quantile(q)
+		assert.True(t, ok)
+		exact := sorted[int(q*float64(len(sorted)-1))]
+		assert.True(t, math.Abs(v-exact) <= alpha*math.Abs(exact)+1e-12,
+			`q=%g: estimate %g, exact %g`, q, v, exact)
+	}
+}
+
+func TestQuantileAccuracy(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := New(0.01)
+	values := make([]float64, 0, 100000)
+	for i := 0; i < cap(values); i++ {
+		v := math.Exp(r.NormFloat64() * 3)
+		values = append(values, v)
+		s.Add(v)
+	}
+	sort.Float64s(values)
+
+	checkAccuracy(t, s, values, testQuantiles)
+	assert.Equal(t, uint64(len(values)), s.Count())
+	assert.Equal(t, values[0], s.Min())
+	assert.Equal(t, values[len(values)-1], s.Max())
+
+	v, _ := s.Quantile(0)
+	assert.Equal(t, values[0], v)
+	v, _ = s.Quantile(1)
+	assert.Equal(t, values[len(values)-1], v)
+}
+
+func TestNegativeAndZero(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	s := New(0.02)
+	values := make([]float64, 0, 10000)
+	for i := 0; i < cap(values); i++ {
+		v := r.NormFloat64() * 100
+		if i%10 == 0 {
+			v = 0
+		}
+		values = append(values, v)
+		s.Add(v)
+	}
+	sort.Float64s(values)
+
+	checkAccuracy(t, s, values, testQuantiles)
+	assert.Equal(t, values[0], s.Min())
+}
+
+func TestEmptyAndInvalid(t *testing.T) {
+	s := New(0.01)
+	_, ok := s.Quantile(0.5)
+	assert.False(t, ok)
+
+	s.Add(math.NaN())
+	s.Add(math.Inf(1))
+	s.AddN(3, 0)
+	assert.Equal(t, uint64(0), s.Count())
+
+	s.Add(3)
+	for _, q := range []float64{-0.1, 1.1, math.NaN()} {
+		_, ok = s.Quantile(q)
+		assert.False(t, ok)
+	}
+
+	v, ok := s.Quantile(0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, v) // clamped to min and max
+
+	assert.Panics(t, func() { New(0) })
+	assert.Panics(t, func() { New(1) })
+}
+
+func TestAddN(t *testing.T) {
+	s := New(0.01)
+	s.AddN(10, 3)
+	s.AddN(-2, 2)
+	assert.Equal(t, uint64(5), s.Count())
+	assert.Equal(t, 26.0, s.Sum())
+	assert.Equal(t, -2.0, s.Min())
+	assert.Equal(t, 10.0, s.Max())
+
+	v, _ := s.Quantile(0.5)
+	assert.True(t, math.Abs(v-10) <= 0.1)
+}
+
+func TestMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	a, b, all := New(0.01), New(0.01), New(0.01)
+	values := make([]float64, 0, 20000)
+	for i := 0; i < cap(values); i++ {
+		v := r.ExpFloat64() * 1000
+		if i%2 == 0 {
+			v = -v / 10
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+		all.Add(v)
+		values = append(values, v)
+	}
+	sort.Float64s(values)
+
+	assert.Nil(t, a.Merge(b))
+	assert.Nil(t, a.Merge(New(0.01)))
+	assert.Equal(t, all.Count(), a.Count())
+	assert.Equal(t, all.Min(), a.Min())
+	assert.Equal(t, all.Max(), a.Max())
+	for _, q := range testQuantiles {
+		expected, _ := all.Quantile(q)
+		actual, _ := a.Quantile(q)
+		assert.Equal(t, expected, actual)
+	}
+	checkAccuracy(t, a, values, testQuantiles)
+
+	empty := New(0.01)
+	assert.Nil(t, empty.Merge(b))
+	assert.Equal(t, b.Min(), empty.Min())
+
+	err := a.Merge(New(0.02))
+	assert.Equal(t, MismatchError{alpha: 0.01, otherAlpha: 0.02}, err)
+}
+
+func TestCollapse(t *testing.T) {
+	s := NewWithMaxBuckets(0.01, 100)
+	values := make([]float64, 0, 10000)
+	for i := 1; i <= cap(values); i++ {
+		v := math.Pow(1.002, float64(i))
+		values = append(values, v)
+		s.Add(v)
+	}
+
+	assert.Len(t, s.positive.counts, 100)
+	assert.Equal(t, uint64(len(values)), s.positive.total)
+	checkAccuracy(t, s, values, []float64{0.9, 0.95, 0.99, 0.999})
+
+	// the collapsed low quantiles are overestimated, never below min
+	v, _ := s.Quantile(0.01)
+	assert.True(t, v >= values[100])
+}
+
+func TestReset(t *testing.T) {
+	s := New(0.01)
+	s.Add(1)
+	s.Add(-1)
+	s.Add(0)
+	s.Reset()
+
+	assert.Equal(t, uint64(0), s.Count())
+	_, ok := s.Quantile(0.5)
+	assert.False(t, ok)
+
+	s.Add(5)
+	v, _ := s.Quantile(0.5)
+	assert.Equal(t, 5.0, v)
+}
+
+func BenchmarkAdd(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	values := make([]float64, 1024)
+	for i := range values {
+		values[i] = math.Exp(r.NormFloat64() * 3)
+	}
+	s := New(0.01)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Add(values[i%len(values)])
+	}
+}
+
+func BenchmarkQuantile(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	s := New(0.01)
+	for i := 0; i < 100000; i++ {
+		s.Add(math.Exp(r.NormFloat64() * 3))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Quantile(0.99)
+	}
+}
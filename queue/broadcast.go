@@ -0,0 +1,337 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// SlowPolicy determines what a put to a Broadcast does when a
+// subscriber already has as many items waiting to be gotten as the
+// broadcast can hold.
+type SlowPolicy int
+
+const (
+	// SlowBlock makes a put wait until every subscriber has room.
+	SlowBlock SlowPolicy = iota
+	// SlowDropOldest makes a full subscriber skip its oldest waiting
+	// item to make room.  Skipped items are counted by Dropped.
+	SlowDropOldest
+	// SlowDisconnect disconnects a full subscriber.  Its waiting
+	// items are discarded and its gets return a DisconnectedError.
+	SlowDisconnect
+)
+
+// Broadcast is a queue in which every subscriber gets every item put
+// after it subscribed, in the order they were put.  Items are held
+// once in a ring shared by the subscribers, each of which keeps its
+// own read cursor, so a put costs the same however many subscribers
+// there are.  An item is released once every subscriber has gotten
+// it, and items put while there are no subscribers are discarded.
+type Broadcast struct {
+	ring []interface{}
+	// head is the sequence number of the next item put and tail that
+	// of the oldest item held.  An item is held in the slot of its
+	// sequence number modulo the size of the ring.
+	head, tail  uint64
+	subscribers map[*Subscriber]struct{}
+	policy      SlowPolicy
+	// changed is closed and replaced whenever waiting puts or
+	// getters need to look at the queue again.
+	changed  chan struct{}
+	lock     sync.Mutex
+	disposed bool
+}
+
+// Subscriber gets the items put to a Broadcast after it subscribed.
+// Many goroutines may get from one subscriber, in which case each item
+// goes to only one of them.
+type Subscriber struct {
+	b *Broadcast
+	// cursor is the sequence number of the next item to get.
+	cursor       uint64
+	dropped      uint64
+	closed       bool
+	disconnected bool
+}
+
+// notify wakes any waiting puts and getters.  Must be called with the
+// lock held.
+func (b *Broadcast) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// release drops the items every subscriber has gotten.  Must be
+// called with the lock held.
+func (b *Broadcast) release() {
+	tail := b.head
+	for s := range b.subscribers {
+		tail = min(tail, s.cursor)
+	}
+
+	for ; b.tail < tail; b.tail++ {
+		b.ring[b.tail%uint64(len(b.ring))] = nil // for garbage collection
+	}
+}
+
+// makeRoom frees the oldest slot of a full ring as the slow policy
+// says and returns a bool indicating if it could.  Must be called with
+// the lock held.
+func (b *Broadcast) makeRoom() bool {
+	if b.policy == SlowBlock {
+		return false
+	}
+
+	for s := range b.subscribers {
+		if s.cursor != b.tail {
+			continue
+		}
+
+		if b.policy == SlowDropOldest {
+			s.cursor++
+			s.dropped++
+		} else {
+			s.disconnected = true
+			delete(b.subscribers, s)
+		}
+	}
+
+	b.release()
+	return true
+}
+
+// Subscribe returns a new subscriber that gets every item put from
+// now on.
+func (b *Broadcast) Subscribe() (*Subscriber, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return nil, DisposedError{}
+	}
+
+	s := &Subscriber{b: b, cursor: b.head}
+	b.subscribers[s] = struct{}{}
+	return s, nil
+}
+
+// Put adds the items to the queue for every subscriber.  If a
+// subscriber has no room, Put does what the queue's SlowPolicy says.
+func (b *Broadcast) Put(items ...interface{}) error {
+	return b.PutCtx(context.Background(), items...)
+}
+
+// PutCtx is like Put except that it stops waiting for room and
+// returns the context's error once the context is done.  Items added
+// before then remain in the queue.
+func (b *Broadcast) PutCtx(ctx context.Context, items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	for len(items) > 0 {
+		if b.disposed {
+			b.lock.Unlock()
+			return DisposedError{}
+		}
+
+		if len(b.subscribers) == 0 {
+			break
+		}
+
+		if b.head-b.tail == uint64(len(b.ring)) && !b.makeRoom() {
+			b.notify()
+			changed := b.changed
+			b.lock.Unlock()
+
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			b.lock.Lock()
+			continue
+		}
+
+		b.ring[b.head%uint64(len(b.ring))] = items[0]
+		b.head++
+		items = items[1:]
+	}
+
+	b.notify()
+	b.lock.Unlock()
+	return nil
+}
+
+// Subscribers returns the number of subscribers that have neither
+// been closed nor disconnected.
+func (b *Broadcast) Subscribers() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.subscribers)
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (b *Broadcast) Disposed() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.disposed
+}
+
+// Dispose will prevent any further reads/writes to this queue,
+// returning an error to any waiting puts and getters, and frees
+// available resources.
+func (b *Broadcast) Dispose() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return
+	}
+
+	b.disposed = true
+	b.ring = nil
+	b.subscribers = nil
+	b.tail = b.head
+	b.notify()
+}
+
+// Get retrieves up to number of the items put since this subscriber
+// last got.  If there are none, this call blocks until there are.
+func (s *Subscriber) Get(number int64) ([]interface{}, error) {
+	return s.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (s *Subscriber) GetCtx(ctx context.Context, number int64) ([]interface{}, error) {
+	if number < 1 {
+		return nil, nil
+	}
+
+	b := s.b
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		b.lock.Lock()
+		switch {
+		case s.disconnected:
+			b.lock.Unlock()
+			return nil, DisconnectedError{}
+		case s.closed || b.disposed:
+			b.lock.Unlock()
+			return nil, DisposedError{}
+		}
+
+		if s.cursor < b.head {
+			items := make([]interface{}, min(uint64(number), b.head-s.cursor))
+			for i := range items {
+				items[i] = b.ring[(s.cursor+uint64(i))%uint64(len(b.ring))]
+			}
+
+			oldest := s.cursor == b.tail
+			s.cursor += uint64(len(items))
+			if oldest {
+				b.release()
+				b.notify()
+			}
+			b.lock.Unlock()
+			return items, nil
+		}
+
+		changed := b.changed
+		b.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// Len returns the number of items waiting to be gotten by this
+// subscriber.
+func (s *Subscriber) Len() int {
+	s.b.lock.Lock()
+	defer s.b.lock.Unlock()
+
+	if s.closed || s.disconnected || s.b.disposed {
+		return 0
+	}
+
+	return int(s.b.head - s.cursor)
+}
+
+// Dropped returns the number of items this subscriber skipped to make
+// room under SlowDropOldest.
+func (s *Subscriber) Dropped() uint64 {
+	s.b.lock.Lock()
+	defer s.b.lock.Unlock()
+
+	return s.dropped
+}
+
+// Disconnected returns a bool indicating if this subscriber was
+// disconnected under SlowDisconnect.
+func (s *Subscriber) Disconnected() bool {
+	s.b.lock.Lock()
+	defer s.b.lock.Unlock()
+
+	return s.disconnected
+}
+
+// Close unsubscribes this subscriber, releasing the items waiting for
+// it.  Any waiting getters and subsequent calls to Get will return a
+// DisposedError.
+func (s *Subscriber) Close() {
+	b := s.b
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if s.closed || s.disconnected || b.disposed {
+		s.closed = true
+		return
+	}
+
+	s.closed = true
+	delete(b.subscribers, s)
+	b.release()
+	b.notify()
+}
+
+// NewBroadcast is the constructor for a broadcast queue that holds up
+// to size items not yet gotten by every subscriber.
+func NewBroadcast(size int, policy SlowPolicy) *Broadcast {
+	return &Broadcast{
+		ring:        make([]interface{}, max(size, 1)),
+		subscribers: make(map[*Subscriber]struct{}),
+		policy:      policy,
+		changed:     make(chan struct{}),
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subscribe(t *testing.T, b *Broadcast) *Subscriber {
+	s, err := b.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestBroadcastEverySubscriber(t *testing.T) {
+	b := NewBroadcast(10, SlowBlock)
+	s1 := subscribe(t, b)
+	s2 := subscribe(t, b)
+
+	assert.Nil(t, b.Put(1, 2, 3))
+	assert.Equal(t, 3, s1.Len())
+
+	items, err := s1.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1, 2, 3}, items)
+
+	items, err = s2.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1, 2}, items)
+	items, err = s2.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{3}, items)
+	assert.Equal(t, 0, s2.Len())
+}
+
+func TestBroadcastSubscribeLater(t *testing.T) {
+	b := NewBroadcast(10, SlowBlock)
+	assert.Nil(t, b.Put(1))
+
+	s1 := subscribe(t, b)
+	assert.Nil(t, b.Put(2))
+	s2 := subscribe(t, b)
+	assert.Nil(t, b.Put(3))
+
+	items, err := s1.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{2, 3}, items)
+
+	items, err = s2.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{3}, items)
+}
+
+func TestBroadcastGetWaits(t *testing.T) {
+	b := NewBroadcast(10, SlowBlock)
+	s := subscribe(t, b)
+
+	result := make(chan []interface{})
+	go func() {
+		items, _ := s.Get(1)
+		result <- items
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	b.Put(`a`)
+	assert.Equal(t, []interface{}{`a`}, <-result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.GetCtx(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBroadcastSlowBlock(t *testing.T) {
+	b := NewBroadcast(2, SlowBlock)
+	fast := subscribe(t, b)
+	slow := subscribe(t, b)
+	assert.Nil(t, b.Put(1, 2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, b.PutCtx(ctx, 3))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Nil(t, b.Put(3, 4))
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	items, _ := fast.Get(10)
+	assert.Equal(t, []interface{}{1, 2}, items)
+	items, _ = slow.Get(1)
+	assert.Equal(t, []interface{}{1}, items)
+	items, _ = slow.Get(1)
+	assert.Equal(t, []interface{}{2}, items)
+	wg.Wait()
+
+	items, _ = fast.Get(10)
+	assert.Equal(t, []interface{}{3, 4}, items)
+	items, _ = slow.Get(10)
+	assert.Equal(t, []interface{}{3, 4}, items)
+}
+
+func TestBroadcastSlowDropOldest(t *testing.T) {
+	b := NewBroadcast(2, SlowDropOldest)
+	fast := subscribe(t, b)
+	slow := subscribe(t, b)
+
+	for i := 1; i <= 5; i++ {
+		assert.Nil(t, b.Put(i))
+		items, _ := fast.Get(1)
+		assert.Equal(t, []interface{}{i}, items)
+	}
+
+	items, err := slow.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{4, 5}, items)
+	assert.Equal(t, uint64(3), slow.Dropped())
+	assert.Equal(t, uint64(0), fast.Dropped())
+}
+
+func TestBroadcastSlowDisconnect(t *testing.T) {
+	b := NewBroadcast(2, SlowDisconnect)
+	fast := subscribe(t, b)
+	slow := subscribe(t, b)
+
+	for i := 1; i <= 3; i++ {
+		assert.Nil(t, b.Put(i))
+		fast.Get(1)
+	}
+
+	assert.True(t, slow.Disconnected())
+	assert.False(t, fast.Disconnected())
+	assert.Equal(t, 1, b.Subscribers())
+	assert.Equal(t, 0, slow.Len())
+
+	_, err := slow.Get(1)
+	assert.Equal(t, DisconnectedError{}, err)
+
+	assert.Nil(t, b.Put(4))
+	items, _ := fast.Get(1)
+	assert.Equal(t, []interface{}{4}, items)
+}
+
+func TestBroadcastClose(t *testing.T) {
+	b := NewBroadcast(1, SlowBlock)
+	s1 := subscribe(t, b)
+	s2 := subscribe(t, b)
+	assert.Nil(t, b.Put(1))
+
+	done := make(chan error)
+	go func() {
+		done <- b.Put(2)
+	}()
+
+	s1.Get(1)
+	time.Sleep(10 * time.Millisecond)
+	s2.Close()
+	s2.Close()
+	assert.Nil(t, <-done)
+	assert.Equal(t, 1, b.Subscribers())
+
+	_, err := s2.Get(1)
+	assert.Equal(t, DisposedError{}, err)
+	items, _ := s1.Get(1)
+	assert.Equal(t, []interface{}{2}, items)
+}
+
+func TestBroadcastNoSubscribers(t *testing.T) {
+	b := NewBroadcast(1, SlowBlock)
+	assert.Nil(t, b.Put(1, 2, 3))
+
+	s := subscribe(t, b)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestBroadcastDispose(t *testing.T) {
+	b := NewBroadcast(1, SlowBlock)
+	s := subscribe(t, b)
+	assert.Nil(t, b.Put(1))
+
+	done := make(chan error)
+	go func() {
+		done <- b.Put(2)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	b.Dispose()
+	assert.Equal(t, DisposedError{}, <-done)
+	assert.Equal(t, DisposedError{}, b.Put(3))
+	_, err := b.Subscribe()
+	assert.Equal(t, DisposedError{}, err)
+	assert.Equal(t, 0, s.Len())
+	s.Close()
+}
+
+func BenchmarkBroadcast(b *testing.B) {
+	q := NewBroadcast(1024, SlowBlock)
+	subscribers := make([]*Subscriber, 8)
+	for i := range subscribers {
+		subscribers[i], _ = q.Subscribe()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(subscribers))
+	for _, s := range subscribers {
+		go func(s *Subscriber) {
+			defer wg.Done()
+			for n := 0; n < b.N; {
+				items, _ := s.Get(128)
+				n += len(items)
+			}
+		}(s)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.Put(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"math/bits"
+	"sync"
+)
+
+// MaxClasses is the largest number of priority classes a ClassQueue
+// can have.
+const MaxClasses = 64
+
+// ring is a growable circular buffer of items in FIFO order.
+type ring struct {
+	items      []interface{}
+	head, size int
+}
+
+func (r *ring) push(item interface{}) {
+	if r.size == len(r.items) {
+		capacity := len(r.items) * 2
+		if capacity == 0 {
+			capacity = 8
+		}
+
+		items := make([]interface{}, capacity)
+		n := copy(items, r.items[r.head:])
+		copy(items[n:], r.items[:r.head])
+		r.items, r.head = items, 0
+	}
+
+	r.items[(r.head+r.size)&(len(r.items)-1)] = item
+	r.size++
+}
+
+func (r *ring) pop() interface{} {
+	item := r.items[r.head]
+	r.items[r.head] = nil
+	r.head = (r.head + 1) & (len(r.items) - 1)
+	r.size--
+	return item
+}
+
+func (r *ring) peek() interface{} {
+	return r.items[r.head]
+}
+
+// ClassQueue is a priority queue with a small, fixed number of
+// strict priority classes.  Class 0 has the highest priority.  Items
+// of the same class are returned in the order they were put and no
+// item is returned while an item of a higher priority class waits.
+// Unlike PriorityQueue, items need not be comparable and Put and Get
+// are O(1).
+type ClassQueue struct {
+	waiters waiters
+	classes []ring
+	// nonEmpty has bit i set when class i holds any items.
+	nonEmpty    uint64
+	len         int64
+	lock        sync.Mutex
+	disposeLock sync.Mutex
+	disposed    bool
+}
+
+// get removes up to number items, highest priority first.  Must be
+// called with the lock held.
+func (cq *ClassQueue) get(number int64) []interface{} {
+	if number > cq.len {
+		number = cq.len
+	}
+
+	items := make([]interface{}, 0, number)
+	for int64(len(items)) < number {
+		class := bits.TrailingZeros64(cq.nonEmpty)
+		r := &cq.classes[class]
+		for r.size > 0 && int64(len(items)) < number {
+			items = append(items, r.pop())
+		}
+
+		if r.size == 0 {
+			cq.nonEmpty &^= 1 << uint(class)
+		}
+	}
+
+	cq.len -= number
+	return items
+}
+
+// Put adds items to the queue in the provided priority class.
+func (cq *ClassQueue) Put(class int, items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	cq.lock.Lock()
+	if cq.disposed {
+		cq.lock.Unlock()
+		return DisposedError{}
+	}
+
+	if class < 0 || class >= len(cq.classes) {
+		cq.lock.Unlock()
+		return InvalidClassError{Class: class, Classes: len(cq.classes)}
+	}
+
+	r := &cq.classes[class]
+	for _, item := range items {
+		r.push(item)
+	}
+	cq.nonEmpty |= 1 << uint(class)
+	cq.len += int64(len(items))
+
+	for {
+		sema := cq.waiters.get()
+		if sema == nil {
+			break
+		}
+		if !sema.claim() {
+			continue
+		}
+
+		sema.response.Add(1)
+		sema.signal()
+		sema.response.Wait()
+		if cq.len == 0 {
+			break
+		}
+	}
+
+	cq.lock.Unlock()
+	return nil
+}
+
+// Get retrieves up to number items from the queue, highest priority
+// class first.  If the queue is empty, this call blocks until the
+// next item is added to the queue.
+func (cq *ClassQueue) Get(number int64) ([]interface{}, error) {
+	return cq.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (cq *ClassQueue) GetCtx(ctx context.Context, number int64) ([]interface{}, error) {
+	if number < 1 {
+		return nil, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cq.lock.Lock()
+
+	if cq.disposed {
+		cq.lock.Unlock()
+		return nil, DisposedError{}
+	}
+
+	var items []interface{}
+
+	if cq.len == 0 {
+		sema := newSema()
+		cq.waiters.put(sema)
+		cq.lock.Unlock()
+
+		if !sema.wait(ctx) {
+			return nil, ctx.Err()
+		}
+		cq.disposeLock.Lock()
+		if cq.disposed {
+			cq.disposeLock.Unlock()
+			return nil, DisposedError{}
+		}
+		cq.disposeLock.Unlock()
+
+		items = cq.get(number)
+		sema.response.Done()
+		return items, nil
+	}
+
+	items = cq.get(number)
+	cq.lock.Unlock()
+	return items, nil
+}
+
+// Peek will look at the next item without removing it from the queue.
+func (cq *ClassQueue) Peek() interface{} {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	if cq.nonEmpty == 0 {
+		return nil
+	}
+
+	return cq.classes[bits.TrailingZeros64(cq.nonEmpty)].peek()
+}
+
+// Empty returns a bool indicating if there are any items left
+// in the queue.
+func (cq *ClassQueue) Empty() bool {
+	return cq.Len() == 0
+}
+
+// Len returns a number indicating how many items are in the queue.
+func (cq *ClassQueue) Len() int64 {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	return cq.len
+}
+
+// ClassLen returns a number indicating how many items are in the
+// provided priority class, or 0 if there is no such class.
+func (cq *ClassQueue) ClassLen(class int) int64 {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	if class < 0 || class >= len(cq.classes) {
+		return 0
+	}
+
+	return int64(cq.classes[class].size)
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (cq *ClassQueue) Disposed() bool {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	return cq.disposed
+}
+
+// Dispose will prevent any further reads/writes to this queue
+// and frees available resources.
+func (cq *ClassQueue) Dispose() {
+	cq.lock.Lock()
+	defer cq.lock.Unlock()
+
+	cq.disposeLock.Lock()
+	defer cq.disposeLock.Unlock()
+
+	if cq.disposed {
+		return
+	}
+
+	cq.disposed = true
+	for _, waiter := range cq.waiters {
+		if waiter.claim() {
+			waiter.response.Add(1)
+			waiter.signal()
+		}
+	}
+
+	cq.classes = nil
+	cq.nonEmpty = 0
+	cq.len = 0
+	cq.waiters = nil
+}
+
+// NewClassQueue is the constructor for a queue with the provided
+// number of priority classes, which must be in [1, MaxClasses].
+func NewClassQueue(classes int) *ClassQueue {
+	if classes < 1 || classes > MaxClasses {
+		panic(`Invalid number of classes provided.`)
+	}
+
+	return &ClassQueue{
+		classes: make([]ring, classes),
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := ring{}
+	for i := 0; i < 5; i++ {
+		r.push(i)
+	}
+	assert.Equal(t, 0, r.pop())
+	assert.Equal(t, 1, r.pop())
+
+	// wrap around and then grow
+	for i := 5; i < 20; i++ {
+		r.push(i)
+	}
+	assert.Equal(t, 18, r.size)
+	for i := 2; i < 20; i++ {
+		assert.Equal(t, i, r.peek())
+		assert.Equal(t, i, r.pop())
+	}
+	assert.Equal(t, 0, r.size)
+}
+
+func TestClassQueuePriority(t *testing.T) {
+	cq := NewClassQueue(3)
+
+	assert.Nil(t, cq.Put(2, `c1`, `c2`))
+	assert.Nil(t, cq.Put(0, `a1`))
+	assert.Nil(t, cq.Put(1, `b1`))
+	assert.Nil(t, cq.Put(0, `a2`))
+	assert.Equal(t, int64(5), cq.Len())
+	assert.Equal(t, int64(2), cq.ClassLen(0))
+	assert.Equal(t, `a1`, cq.Peek())
+
+	result, err := cq.Get(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a1`, `a2`, `b1`}, result)
+
+	assert.Nil(t, cq.Put(1, `b2`))
+	result, err = cq.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`b2`, `c1`, `c2`}, result)
+	assert.True(t, cq.Empty())
+	assert.Nil(t, cq.Peek())
+}
+
+func TestClassQueueInvalidClass(t *testing.T) {
+	cq := NewClassQueue(2)
+
+	assert.IsType(t, InvalidClassError{}, cq.Put(2, `a`))
+	assert.IsType(t, InvalidClassError{}, cq.Put(-1, `a`))
+	assert.Equal(t, int64(0), cq.ClassLen(5))
+	assert.Equal(t, int64(0), cq.Len())
+
+	assert.Panics(t, func() { NewClassQueue(0) })
+	assert.Panics(t, func() { NewClassQueue(MaxClasses + 1) })
+}
+
+func TestClassQueueGetNonPositiveNumber(t *testing.T) {
+	cq := NewClassQueue(1)
+	cq.Put(0, `a`)
+
+	result, err := cq.Get(0)
+	assert.Nil(t, err)
+	assert.Len(t, result, 0)
+}
+
+func TestClassQueueGetEmpty(t *testing.T) {
+	cq := NewClassQueue(2)
+
+	go func() {
+		cq.Put(1, `a`)
+	}()
+
+	result, err := cq.Get(2)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, []interface{}{`a`}, result)
+}
+
+func TestClassQueueMultipleGetEmpty(t *testing.T) {
+	cq := NewClassQueue(2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	results := make([][]interface{}, 2)
+
+	for i := 0; i < 2; i++ {
+		go func(i int) {
+			wg.Done()
+			local, err := cq.Get(1)
+			assert.Nil(t, err)
+			results[i] = local
+			wg.Done()
+		}(i)
+	}
+
+	wg.Wait()
+	wg.Add(2)
+
+	cq.Put(1, `b`)
+	cq.Put(0, `a`)
+	wg.Wait()
+
+	if assert.Len(t, results[0], 1) && assert.Len(t, results[1], 1) {
+		got := []interface{}{results[0][0], results[1][0]}
+		assert.Contains(t, got, `a`)
+		assert.Contains(t, got, `b`)
+	}
+}
+
+func TestClassQueueDispose(t *testing.T) {
+	cq := NewClassQueue(2)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	var err error
+	go func() {
+		wg.Done()
+		_, err = cq.Get(1)
+		wg.Done()
+	}()
+
+	wg.Wait()
+	wg.Add(1)
+	cq.Dispose()
+	wg.Wait()
+
+	assert.IsType(t, DisposedError{}, err)
+	assert.True(t, cq.Disposed())
+	assert.IsType(t, DisposedError{}, cq.Put(0, `a`))
+	_, err = cq.Get(1)
+	assert.IsType(t, DisposedError{}, err)
+}
+
+func BenchmarkClassQueue(b *testing.B) {
+	cq := NewClassQueue(4)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cq.Put(i%4, i)
+		cq.Get(1)
+	}
+}
+
+func TestClassQueueGetCtx(t *testing.T) {
+	q := NewClassQueue(2)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := q.GetCtx(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	q.Put(1, `a`)
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`}, result)
+
+	q.Dispose()
+	_, err = q.GetCtx(context.Background(), 1)
+	assert.IsType(t, DisposedError{}, err)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+type delayed struct {
+	deadline time.Time
+	// seq keeps items with equal deadlines in the order they
+	// were put.
+	seq  uint64
+	item interface{}
+}
+
+type delayHeap []*delayed
+
+func (dh delayHeap) Len() int {
+	return len(dh)
+}
+
+func (dh delayHeap) Less(i, j int) bool {
+	if dh[i].deadline.Equal(dh[j].deadline) {
+		return dh[i].seq < dh[j].seq
+	}
+
+	return dh[i].deadline.Before(dh[j].deadline)
+}
+
+func (dh delayHeap) Swap(i, j int) {
+	dh[i], dh[j] = dh[j], dh[i]
+}
+
+func (dh *delayHeap) Push(x interface{}) {
+	*dh = append(*dh, x.(*delayed))
+}
+
+func (dh *delayHeap) Pop() interface{} {
+	old := *dh
+	d := old[len(old)-1]
+	old[len(old)-1] = nil // for garbage collection
+	*dh = old[:len(old)-1]
+	return d
+}
+
+// DelayQueue holds items until their deadlines pass.  Get only
+// returns items whose deadlines have passed, earliest deadline
+// first, and blocks until one is due.  Items with the same deadline
+// are returned in the order they were put.
+type DelayQueue struct {
+	items delayHeap
+	seq   uint64
+	// changed is closed and replaced whenever getters need to
+	// look at the queue again.
+	changed  chan struct{}
+	lock     sync.Mutex
+	disposed bool
+}
+
+// notify wakes any waiting getters.  Must be called with the lock
+// held.
+func (dq *DelayQueue) notify() {
+	close(dq.changed)
+	dq.changed = make(chan struct{})
+}
+
+// Put adds items to the queue that become available at the
+// provided deadline.
+func (dq *DelayQueue) Put(deadline time.Time, items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return DisposedError{}
+	}
+
+	earliest := len(dq.items) == 0 || deadline.Before(dq.items[0].deadline)
+	for _, item := range items {
+		heap.Push(&dq.items, &delayed{deadline: deadline, seq: dq.seq, item: item})
+		dq.seq++
+	}
+
+	if earliest {
+		dq.notify()
+	}
+
+	return nil
+}
+
+// PutAfter adds items to the queue that become available once the
+// provided delay has elapsed.
+func (dq *DelayQueue) PutAfter(delay time.Duration, items ...interface{}) error {
+	return dq.Put(time.Now().Add(delay), items...)
+}
+
+// Get retrieves up to number items whose deadlines have passed.  If
+// no item is due, this call blocks until one is.
+func (dq *DelayQueue) Get(number int64) ([]interface{}, error) {
+	return dq.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (dq *DelayQueue) GetCtx(ctx context.Context, number int64) ([]interface{}, error) {
+	if number < 1 {
+		return nil, nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		dq.lock.Lock()
+		if dq.disposed {
+			dq.lock.Unlock()
+			return nil, DisposedError{}
+		}
+
+		now := time.Now()
+		if items := dq.due(now, number); len(items) > 0 {
+			dq.lock.Unlock()
+			return items, nil
+		}
+
+		var timer *time.Timer
+		var fired <-chan time.Time
+		if len(dq.items) > 0 {
+			timer = time.NewTimer(dq.items[0].deadline.Sub(now))
+			fired = timer.C
+		}
+		changed := dq.changed
+		dq.lock.Unlock()
+
+		select {
+		case <-fired:
+		case <-changed:
+		case <-ctx.Done():
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// due removes up to number items whose deadlines are not after now.
+// Must be called with the lock held.
+func (dq *DelayQueue) due(now time.Time, number int64) []interface{} {
+	var items []interface{}
+	for int64(len(items)) < number && len(dq.items) > 0 &&
+		!dq.items[0].deadline.After(now) {
+
+		items = append(items, heap.Pop(&dq.items).(*delayed).item)
+	}
+
+	return items
+}
+
+// Len returns the number of items in the queue, due or not.
+func (dq *DelayQueue) Len() int {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return len(dq.items)
+}
+
+// Empty returns a bool indicating if there are any items left in
+// the queue, due or not.
+func (dq *DelayQueue) Empty() bool {
+	return dq.Len() == 0
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (dq *DelayQueue) Disposed() bool {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return dq.disposed
+}
+
+// Dispose will prevent any further reads/writes to this queue,
+// returning an error to any waiting getters, and frees available
+// resources.
+func (dq *DelayQueue) Dispose() {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return
+	}
+
+	dq.disposed = true
+	dq.items = nil
+	dq.notify()
+}
+
+// NewDelayQueue is the constructor for a delay queue.
+func NewDelayQueue(hint int) *DelayQueue {
+	return &DelayQueue{
+		items:   make(delayHeap, 0, hint),
+		changed: make(chan struct{}),
+	}
+}
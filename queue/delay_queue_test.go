@@ -0,0 +1,145 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelayQueueOrder(t *testing.T) {
+	q := NewDelayQueue(10)
+	now := time.Now()
+
+	q.Put(now.Add(-time.Second), `c`)
+	q.Put(now.Add(-3*time.Second), `a`, `b`)
+	q.Put(now.Add(time.Hour), `later`)
+	assert.Equal(t, 4, q.Len())
+
+	result, err := q.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`, `b`, `c`}, result)
+	assert.Equal(t, 1, q.Len())
+	assert.False(t, q.Empty())
+}
+
+func TestDelayQueueGetWaitsForDeadline(t *testing.T) {
+	q := NewDelayQueue(10)
+	start := time.Now()
+	q.PutAfter(20*time.Millisecond, `a`)
+
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`}, result)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+}
+
+func TestDelayQueueEarlierPutWakesGetter(t *testing.T) {
+	q := NewDelayQueue(10)
+	q.PutAfter(time.Hour, `later`)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.PutAfter(0, `now`)
+	}()
+
+	result, err := q.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`now`}, result)
+}
+
+func TestDelayQueueGetCtx(t *testing.T) {
+	q := NewDelayQueue(10)
+	q.PutAfter(time.Hour, `later`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := q.GetCtx(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, q.Len())
+
+	result, err := q.GetCtx(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Len(t, result, 0)
+}
+
+func TestDelayQueueDispose(t *testing.T) {
+	q := NewDelayQueue(10)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	var err error
+	go func() {
+		_, err = q.Get(1)
+		wg.Done()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	q.Dispose()
+	wg.Wait()
+
+	assert.IsType(t, DisposedError{}, err)
+	assert.True(t, q.Disposed())
+	assert.IsType(t, DisposedError{}, q.PutAfter(0, `a`))
+	q.Dispose()
+}
+
+func TestDelayQueueMultipleGetters(t *testing.T) {
+	q := NewDelayQueue(10)
+	numItems := 100
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	results := make([][]interface{}, 4)
+	for i := 0; i < 4; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for {
+				items, err := q.Get(1)
+				if err != nil {
+					return
+				}
+				results[i] = append(results[i], items...)
+			}
+		}(i)
+	}
+
+	for i := 0; i < numItems; i++ {
+		q.PutAfter(time.Duration(i%5)*time.Millisecond, i)
+	}
+
+	for q.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	q.Dispose()
+	wg.Wait()
+
+	seen := make(map[interface{}]bool)
+	for _, result := range results {
+		for _, item := range result {
+			assert.False(t, seen[item])
+			seen[item] = true
+		}
+	}
+	assert.Len(t, seen, numItems)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+var (
+	_ common.Disposable = (*Queue)(nil)
+	_ common.Disposable = (*PriorityQueue)(nil)
+	_ common.Disposable = (*ClassQueue)(nil)
+	_ common.Disposable = (*DelayQueue)(nil)
+	_ common.Disposable = (*MPMC)(nil)
+	_ common.Disposable = (*PersistentQueue)(nil)
+	_ common.Disposable = (*Broadcast)(nil)
+)
+
+// checkGoroutines fails the test if the number of goroutines doesn't
+// return to expected shortly.
+func checkGoroutines(t *testing.T, expected int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > expected {
+		if time.Now().After(deadline) {
+			t.Errorf(`Leaked %d goroutines.`, runtime.NumGoroutine()-expected)
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// checkDisposeWakesWaiters starts several goroutines blocked in get,
+// disposes the queue and checks that every one of them returns an
+// error matching common.ErrDisposed.
+func checkDisposeWakesWaiters(t *testing.T, q common.Disposable, get func() error) {
+	numWaiters := 10
+	before := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	errs := make([]error, numWaiters)
+	wg.Add(numWaiters)
+	for i := 0; i < numWaiters; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = get()
+		}(i)
+	}
+
+	time.Sleep(10 * time.Millisecond) // give the getters time to block
+	q.Dispose()
+	q.Dispose()
+	wg.Wait()
+
+	assert.True(t, q.Disposed())
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, common.ErrDisposed))
+	}
+	assert.True(t, errors.Is(get(), common.ErrDisposed))
+	checkGoroutines(t, before)
+}
+
+func TestDisposedErrorIs(t *testing.T) {
+	assert.True(t, errors.Is(DisposedError{}, common.ErrDisposed))
+	assert.False(t, errors.Is(DisposedError{}, os.ErrClosed))
+}
+
+func TestQueueDisposeWakesWaiters(t *testing.T) {
+	q := New(10)
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get(1)
+		return err
+	})
+}
+
+func TestQueueAsChanDisposeStopsPump(t *testing.T) {
+	before := runtime.NumGoroutine()
+	q := New(10)
+	ch, _ := q.AsChan()
+
+	q.Dispose()
+	_, ok := <-ch
+	assert.False(t, ok)
+	checkGoroutines(t, before)
+}
+
+func TestPriorityQueueDisposeWakesWaiters(t *testing.T) {
+	q := NewPriorityQueue(10)
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get(1)
+		return err
+	})
+}
+
+func TestClassQueueDisposeWakesWaiters(t *testing.T) {
+	q := NewClassQueue(3)
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get(1)
+		return err
+	})
+}
+
+func TestDelayQueueDisposeWakesWaiters(t *testing.T) {
+	q := NewDelayQueue(10)
+	q.PutAfter(time.Hour, `later`)
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get(1)
+		return err
+	})
+}
+
+func TestMPMCDisposeWakesWaiters(t *testing.T) {
+	q := NewMPMC(2)
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get()
+		return err
+	})
+}
+
+func TestBroadcastDisposeWakesWaiters(t *testing.T) {
+	q := NewBroadcast(10, SlowBlock)
+	s, err := q.Subscribe()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := s.Get(1)
+		return err
+	})
+}
+
+func TestPersistentDisposeWakesWaiters(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	checkDisposeWakesWaiters(t, q, func() error {
+		_, err := q.Get(1)
+		return err
+	})
+}
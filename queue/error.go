@@ -16,8 +16,48 @@ limitations under the License.
 
 package queue
 
+import (
+	"fmt"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// DisposedError is returned by calls to a queue that has been
+// disposed, including calls that were waiting when it was.
 type DisposedError struct{}
 
 func (de DisposedError) Error() string {
 	return `Queue has been disposed.`
 }
+
+// Is reports whether target is common.ErrDisposed so that errors.Is
+// recognizes disposal the same way for every structure.
+func (de DisposedError) Is(target error) bool {
+	return target == common.ErrDisposed
+}
+
+// FullError is returned by a put to a priority queue at its capacity
+// whose full policy is FullError.
+type FullError struct{}
+
+func (fe FullError) Error() string {
+	return `Queue is full.`
+}
+
+// DisconnectedError is returned by gets from a broadcast subscriber
+// that was disconnected for falling behind.
+type DisconnectedError struct{}
+
+func (de DisconnectedError) Error() string {
+	return `Subscriber was disconnected for falling behind.`
+}
+
+// InvalidClassError is returned when putting items to a priority
+// class that a ClassQueue does not have.
+type InvalidClassError struct {
+	Class, Classes int
+}
+
+func (ice InvalidClassError) Error() string {
+	return fmt.Sprintf(`Class %d is out of range for a queue of %d classes.`, ice.Class, ice.Classes)
+}
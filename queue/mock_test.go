@@ -16,6 +16,8 @@ limitations under the License.
 
 package queue
 
+import "sync"
+
 type mockItem int
 
 func (mi mockItem) Compare(other Item) int {
@@ -27,3 +29,28 @@ func (mi mockItem) Compare(other Item) int {
 	}
 	return -1
 }
+
+type mockMetrics struct {
+	lock         sync.Mutex
+	counts       map[string]int64
+	observations map[string][]float64
+}
+
+func (mm *mockMetrics) Add(name string, delta int64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.counts[name] += delta
+}
+
+func (mm *mockMetrics) Observe(name string, value float64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.observations[name] = append(mm.observations[name], value)
+}
+
+func newMockMetrics() *mockMetrics {
+	return &mockMetrics{
+		counts:       make(map[string]int64),
+		observations: make(map[string][]float64),
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Workiva/go-datastructures/common"
+	"github.com/Workiva/go-datastructures/internal/hashutil"
+)
+
+// mpmcSpins is the number of times Put and Get yield the processor
+// and retry before parking.
+const mpmcSpins = 64
+
+// cacheLinePad keeps the hot counters of an MPMC on separate cache
+// lines so producers and consumers don't contend on the same line.
+type cacheLinePad [8]uint64
+
+type mpmcSlot struct {
+	// sequence tells producers and consumers whose turn it is to
+	// use this slot.
+	sequence uint64
+	item     interface{}
+}
+
+// MPMC is a bounded, lock-free, multi-producer multi-consumer queue
+// based on Dmitry Vyukov's design.  Every slot carries its own
+// sequence number.  A producer or consumer claims a position with a
+// single compare-and-swap and then only touches that slot's sequence,
+// so there is no global spin on a shared head/tail pair.  Items are
+// returned in FIFO order.
+//
+// Put and Get on a full or empty queue first yield and retry a
+// bounded number of times, which keeps hand-offs fast while the other
+// side is busy, and then park until an item moves so an idle queue
+// doesn't burn a processor.  The price is an atomic load in every
+// successful Offer and Poll, plus a lock and channel close while any
+// goroutine is parked.  Every parked goroutine is woken to retry,
+// not just one.
+type MPMC struct {
+	_        cacheLinePad
+	enqueue  uint64
+	_        cacheLinePad
+	dequeue  uint64
+	_        cacheLinePad
+	disposed uint64
+	mask     uint64
+	slots    []mpmcSlot
+	// metrics, if not nil, receives counts of items and of failed
+	// compare-and-swaps.
+	metrics common.Metrics
+	// parked counts goroutines blocked in Put or Get.  They are
+	// woken by closing changed, which is replaced under lock.
+	parked  int64
+	lock    sync.Mutex
+	changed chan struct{}
+}
+
+// Offer adds the item to the queue without blocking.  Returns false
+// if the queue is full or has been disposed.
+func (q *MPMC) Offer(item interface{}) bool {
+	if atomic.LoadUint64(&q.disposed) == 1 {
+		return false
+	}
+
+	pos := atomic.LoadUint64(&q.enqueue)
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.sequence)
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&q.enqueue, pos, pos+1) {
+				slot.item = item
+				atomic.StoreUint64(&slot.sequence, pos+1)
+				q.report(common.MetricInserts)
+				q.wake()
+				return true
+			}
+			q.report(common.MetricContention)
+		case dif < 0:
+			// the consumer a lap behind hasn't emptied this slot
+			return false
+		}
+
+		pos = atomic.LoadUint64(&q.enqueue)
+	}
+}
+
+// Poll removes and returns the next item without blocking.  The
+// returned bool is false if the queue is empty or has been disposed.
+func (q *MPMC) Poll() (interface{}, bool) {
+	if atomic.LoadUint64(&q.disposed) == 1 {
+		return nil, false
+	}
+
+	pos := atomic.LoadUint64(&q.dequeue)
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.sequence)
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint64(&q.dequeue, pos, pos+1) {
+				item := slot.item
+				slot.item = nil
+				atomic.StoreUint64(&slot.sequence, pos+q.mask+1)
+				q.report(common.MetricDeletes)
+				q.wake()
+				return item, true
+			}
+			q.report(common.MetricContention)
+		case dif < 0:
+			// no producer has filled this slot yet
+			return nil, false
+		}
+
+		pos = atomic.LoadUint64(&q.dequeue)
+	}
+}
+
+// Put adds the item to the queue, waiting while the queue is full.
+// Returns an error if the queue is disposed.
+func (q *MPMC) Put(item interface{}) error {
+	if q.Offer(item) {
+		return nil
+	}
+
+	return q.wait(func() bool {
+		return q.Offer(item)
+	})
+}
+
+// Get removes and returns the next item, waiting while the queue is
+// empty.  Returns an error if the queue is disposed.
+func (q *MPMC) Get() (interface{}, error) {
+	if item, ok := q.Poll(); ok {
+		return item, nil
+	}
+
+	var item interface{}
+	err := q.wait(func() bool {
+		var ok bool
+		item, ok = q.Poll()
+		return ok
+	})
+	return item, err
+}
+
+// wait retries try until it succeeds or the queue is disposed,
+// yielding for the first mpmcSpins attempts and then parking until an
+// item moves.
+func (q *MPMC) wait(try func() bool) error {
+	for spins := 0; ; spins++ {
+		if try() {
+			return nil
+		}
+
+		if q.Disposed() {
+			return DisposedError{}
+		}
+
+		if spins < mpmcSpins {
+			runtime.Gosched()
+			continue
+		}
+
+		q.lock.Lock()
+		changed := q.changed
+		atomic.AddInt64(&q.parked, 1)
+		q.lock.Unlock()
+
+		// an item that moved before parked was raised didn't wake
+		// us, so check again before sleeping
+		if try() {
+			atomic.AddInt64(&q.parked, -1)
+			return nil
+		}
+
+		if !q.Disposed() {
+			<-changed
+		}
+		atomic.AddInt64(&q.parked, -1)
+	}
+}
+
+// wake wakes any parked goroutines so they retry.
+func (q *MPMC) wake() {
+	if atomic.LoadInt64(&q.parked) == 0 {
+		return
+	}
+
+	q.lock.Lock()
+	close(q.changed)
+	q.changed = make(chan struct{})
+	q.lock.Unlock()
+}
+
+// Len returns the number of items in the queue.  The result is only
+// a snapshot while other goroutines are using the queue.
+func (q *MPMC) Len() uint64 {
+	dequeue := atomic.LoadUint64(&q.dequeue)
+	enqueue := atomic.LoadUint64(&q.enqueue)
+	if enqueue < dequeue {
+		return 0
+	}
+
+	return enqueue - dequeue
+}
+
+// Cap returns the number of items the queue can hold.
+func (q *MPMC) Cap() uint64 {
+	return uint64(len(q.slots))
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (q *MPMC) Disposed() bool {
+	return atomic.LoadUint64(&q.disposed) == 1
+}
+
+// Dispose will release any goroutines blocked in Put or Get, which
+// return an error, and cause subsequent calls to fail.
+func (q *MPMC) Dispose() {
+	atomic.StoreUint64(&q.disposed, 1)
+
+	q.lock.Lock()
+	close(q.changed)
+	q.changed = make(chan struct{})
+	q.lock.Unlock()
+}
+
+// SetMetrics makes this queue report to the provided metrics, which
+// must be safe for concurrent use.  Reported are common.MetricInserts
+// and MetricDeletes for every item offered and polled and
+// MetricContention each time a producer or consumer loses a race for a
+// slot and has to retry.  This must be called before the queue is
+// shared.
+func (q *MPMC) SetMetrics(metrics common.Metrics) {
+	q.metrics = metrics
+}
+
+// report adds one to the named metric if metrics are set.
+func (q *MPMC) report(name string) {
+	if q.metrics != nil {
+		q.metrics.Add(name, 1)
+	}
+}
+
+// NewMPMC returns an empty MPMC queue.  The size is rounded up to
+// the next power of two, and to at least 2, and must be greater
+// than 0.
+func NewMPMC(size uint64) *MPMC {
+	if size == 0 {
+		panic(`MPMC size must be greater than 0.`)
+	}
+
+	// with a single slot its sequence reads the same when full as
+	// when empty a lap later, so a full queue would accept items
+	if size < 2 {
+		size = 2
+	}
+	size = hashutil.RoundUp(size)
+	q := &MPMC{
+		mask:    size - 1,
+		slots:   make([]mpmcSlot, size),
+		changed: make(chan struct{}),
+	}
+	for i := range q.slots {
+		q.slots[i].sequence = uint64(i)
+	}
+
+	return q
+}
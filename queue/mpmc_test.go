@@ -0,0 +1,358 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+func TestMPMCOfferPoll(t *testing.T) {
+	q := NewMPMC(3)
+	assert.Equal(t, uint64(4), q.Cap())
+
+	item, ok := q.Poll()
+	assert.Nil(t, item)
+	assert.False(t, ok)
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, q.Offer(i))
+	}
+	assert.False(t, q.Offer(4))
+	assert.Equal(t, uint64(4), q.Len())
+
+	for i := 0; i < 4; i++ {
+		item, ok = q.Poll()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	_, ok = q.Poll()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), q.Len())
+
+	// wrap around the ring several times
+	for i := 0; i < 20; i++ {
+		assert.True(t, q.Offer(i))
+		item, _ = q.Poll()
+		assert.Equal(t, i, item)
+	}
+}
+
+func TestMPMCPanicsOnZeroSize(t *testing.T) {
+	assert.Panics(t, func() { NewMPMC(0) })
+}
+
+func TestMPMCSingleSlot(t *testing.T) {
+	q := NewMPMC(1)
+	assert.Equal(t, uint64(2), q.Cap())
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, q.Offer(i))
+		assert.True(t, q.Offer(i))
+		assert.False(t, q.Offer(i))
+		q.Poll()
+		q.Poll()
+	}
+}
+
+func TestMPMCDispose(t *testing.T) {
+	q := NewMPMC(2)
+	q.Put(1)
+	q.Put(2)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var err error
+	go func() {
+		err = q.Put(3)
+		wg.Done()
+	}()
+
+	q.Dispose()
+	wg.Wait()
+	assert.IsType(t, DisposedError{}, err)
+	assert.True(t, q.Disposed())
+	assert.False(t, q.Offer(4))
+
+	_, err = q.Get()
+	assert.IsType(t, DisposedError{}, err)
+}
+
+// waitParked waits for the provided number of goroutines to park
+// on the queue.
+func waitParked(t *testing.T, q *MPMC, number int64) {
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&q.parked) != number {
+		if time.Now().After(deadline) {
+			t.Fatalf(`expected %d parked, got %d`, number, atomic.LoadInt64(&q.parked))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMPMCParks(t *testing.T) {
+	q := NewMPMC(1)
+
+	result := make(chan interface{})
+	go func() {
+		item, _ := q.Get()
+		result <- item
+	}()
+
+	// an empty queue parks the getter rather than spinning
+	waitParked(t, q, 1)
+	assert.Nil(t, q.Put(1))
+	assert.Equal(t, 1, <-result)
+	waitParked(t, q, 0)
+
+	assert.Nil(t, q.Put(2))
+	assert.Nil(t, q.Put(3))
+	done := make(chan error)
+	go func() {
+		done <- q.Put(4)
+	}()
+
+	// as does a full one the putter
+	waitParked(t, q, 1)
+	item, err := q.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, item)
+	assert.Nil(t, <-done)
+	item, _ = q.Get()
+	assert.Equal(t, 3, item)
+	item, _ = q.Get()
+	assert.Equal(t, 4, item)
+}
+
+func TestMPMCDisposeWakesParked(t *testing.T) {
+	q := NewMPMC(1)
+
+	done := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := q.Get()
+			done <- err
+		}()
+	}
+
+	waitParked(t, q, 2)
+	q.Dispose()
+	for i := 0; i < 2; i++ {
+		assert.IsType(t, DisposedError{}, <-done)
+	}
+}
+
+func TestMPMCConcurrent(t *testing.T) {
+	numProducers, numConsumers, numItems := 4, 4, 10000
+	q := NewMPMC(64)
+
+	var producers, consumers sync.WaitGroup
+	producers.Add(numProducers)
+	for p := 0; p < numProducers; p++ {
+		go func(p int) {
+			for i := 0; i < numItems; i++ {
+				q.Put(p*numItems + i)
+			}
+			producers.Done()
+		}(p)
+	}
+
+	results := make([][]int, numConsumers)
+	var received sync.WaitGroup
+	received.Add(numProducers * numItems)
+	consumers.Add(numConsumers)
+	for c := 0; c < numConsumers; c++ {
+		go func(c int) {
+			defer consumers.Done()
+			for {
+				item, err := q.Get()
+				if err != nil {
+					return
+				}
+				results[c] = append(results[c], item.(int))
+				received.Done()
+			}
+		}(c)
+	}
+
+	producers.Wait()
+	received.Wait()
+	q.Dispose()
+	consumers.Wait()
+
+	seen := make([]bool, numProducers*numItems)
+	for _, result := range results {
+		// each consumer sees a producer's items in the order they
+		// were put
+		last := make(map[int]int)
+		for _, item := range result {
+			p := item / numItems
+			if prev, ok := last[p]; ok {
+				assert.True(t, prev < item)
+			}
+			last[p] = item
+			assert.False(t, seen[item])
+			seen[item] = true
+		}
+	}
+
+	for _, ok := range seen {
+		assert.True(t, ok)
+	}
+}
+
+func TestMPMCMetrics(t *testing.T) {
+	q := NewMPMC(4)
+	metrics := newMockMetrics()
+	q.SetMetrics(metrics)
+
+	q.Offer(1)
+	q.Offer(2)
+	q.Offer(3)
+	q.Poll()
+	q.Poll()
+	assert.Equal(t, int64(3), metrics.counts[common.MetricInserts])
+	assert.Equal(t, int64(2), metrics.counts[common.MetricDeletes])
+	assert.Equal(t, int64(0), metrics.counts[common.MetricContention])
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	for i := 0; i < 8; i++ {
+		go func() {
+			for j := 0; j < 1000; j++ {
+				q.Put(j)
+				q.Get()
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(8003), metrics.counts[common.MetricInserts])
+	assert.Equal(t, int64(8002), metrics.counts[common.MetricDeletes])
+	assert.True(t, metrics.counts[common.MetricContention] >= 0)
+}
+
+func benchmarkMPMC(b *testing.B, producers int) {
+	q := NewMPMC(1024)
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	perProducer := b.N/producers + 1
+
+	b.ResetTimer()
+
+	for p := 0; p < producers; p++ {
+		go func() {
+			for i := 0; i < perProducer; i++ {
+				q.Put(i)
+			}
+			wg.Done()
+		}()
+	}
+
+	for i := 0; i < perProducer*producers; i++ {
+		q.Get()
+	}
+	wg.Wait()
+}
+
+func benchmarkLockedQueue(b *testing.B, producers int) {
+	q := New(1024)
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	perProducer := b.N/producers + 1
+
+	b.ResetTimer()
+
+	for p := 0; p < producers; p++ {
+		go func() {
+			for i := 0; i < perProducer; i++ {
+				q.Put(i)
+			}
+			wg.Done()
+		}()
+	}
+
+	for i := 0; i < perProducer*producers; i++ {
+		q.Get(1)
+	}
+	wg.Wait()
+}
+
+func benchmarkChannel(b *testing.B, producers int) {
+	ch := make(chan interface{}, 1024)
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	perProducer := b.N/producers + 1
+
+	b.ResetTimer()
+
+	for p := 0; p < producers; p++ {
+		go func() {
+			for i := 0; i < perProducer; i++ {
+				ch <- i
+			}
+			wg.Done()
+		}()
+	}
+
+	for i := 0; i < perProducer*producers; i++ {
+		<-ch
+	}
+	wg.Wait()
+}
+
+func BenchmarkMPMC1Producer(b *testing.B) {
+	benchmarkMPMC(b, 1)
+}
+
+func BenchmarkMPMC4Producers(b *testing.B) {
+	benchmarkMPMC(b, 4)
+}
+
+func BenchmarkMPMC16Producers(b *testing.B) {
+	benchmarkMPMC(b, 16)
+}
+
+func BenchmarkLockedQueue1Producer(b *testing.B) {
+	benchmarkLockedQueue(b, 1)
+}
+
+func BenchmarkLockedQueue4Producers(b *testing.B) {
+	benchmarkLockedQueue(b, 4)
+}
+
+func BenchmarkLockedQueue16Producers(b *testing.B) {
+	benchmarkLockedQueue(b, 16)
+}
+
+func BenchmarkChannel1Producer(b *testing.B) {
+	benchmarkChannel(b, 1)
+}
+
+func BenchmarkChannel4Producers(b *testing.B) {
+	benchmarkChannel(b, 4)
+}
+
+func BenchmarkChannel16Producers(b *testing.B) {
+	benchmarkChannel(b, 16)
+}
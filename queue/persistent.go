@@ -0,0 +1,569 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentSuffix     = `.seg`
+	cursorFile        = `cursor`
+	recordHeaderSize  = 8
+	cursorSize        = 12
+	defaultSegmentMax = 64 << 20
+)
+
+// SyncPolicy determines when a PersistentQueue flushes its files to
+// stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways fsyncs before every Put and Get returns, so no
+	// acknowledged operation is lost if the machine crashes.
+	SyncAlways SyncPolicy = iota
+	// SyncNever leaves flushing to the operating system.  Writes
+	// survive the process crashing but may be lost if the machine
+	// does.  Call Sync to flush explicitly.
+	SyncNever
+)
+
+type persistentConfig struct {
+	sync        SyncPolicy
+	segmentSize int64
+}
+
+// PersistentOption configures a PersistentQueue.
+type PersistentOption func(*persistentConfig)
+
+// WithSyncPolicy sets when the queue fsyncs its files.  The default
+// is SyncAlways.
+func WithSyncPolicy(policy SyncPolicy) PersistentOption {
+	return func(c *persistentConfig) {
+		c.sync = policy
+	}
+}
+
+// WithSegmentSize sets the size in bytes past which the log moves
+// on to a new segment file.  Segments are deleted once every item in
+// them has been retrieved.  The default is 64MB.
+func WithSegmentSize(size int64) PersistentOption {
+	return func(c *persistentConfig) {
+		c.segmentSize = size
+	}
+}
+
+// segmentFile is the part of *os.File the queue uses to append to
+// a segment.
+type segmentFile interface {
+	Write([]byte) (int, error)
+	Sync() error
+	Truncate(int64) error
+	Close() error
+}
+
+func openSegment(path string, flag int) (segmentFile, error) {
+	return os.OpenFile(path, flag, 0644)
+}
+
+type segment struct {
+	first, count uint64
+	path         string
+}
+
+func segmentPath(dir string, first uint64) string {
+	return filepath.Join(dir, fmt.Sprintf(`%020d%s`, first, segmentSuffix))
+}
+
+// PersistentQueue is a FIFO queue of byte slices backed by a
+// write-ahead log so its items survive process restarts.  Puts are
+// appended to segment files in the queue's directory and the
+// position of the next item to get is kept in a cursor file.  Items
+// are delivered at least once: an item whose retrieval was not yet
+// recorded when the process stopped is returned again after
+// recovery.  Only one PersistentQueue may use a directory at a time.
+type PersistentQueue struct {
+	dir    string
+	config persistentConfig
+	// items holds every item not yet retrieved, starting at the
+	// sequence number head.
+	items      [][]byte
+	head, tail uint64
+	segments   []*segment
+	active     segmentFile
+	activeSize int64
+	cursor     *os.File
+	// open opens segment files and is replaced in tests to inject
+	// failures.
+	open func(path string, flag int) (segmentFile, error)
+	// broken is set when a failed Put could not be cut back out of
+	// the active segment, after which no more items may be appended.
+	broken error
+	// changed is closed and replaced whenever getters need to
+	// look at the queue again.
+	changed  chan struct{}
+	lock     sync.Mutex
+	disposed bool
+}
+
+// NewPersistent opens the queue stored in dir, creating it if
+// needed, and recovers any items that were not retrieved.  A record
+// torn by a crash at the end of the log is discarded.
+func NewPersistent(dir string, opts ...PersistentOption) (*PersistentQueue, error) {
+	pq := &PersistentQueue{
+		dir: dir,
+		config: persistentConfig{
+			sync:        SyncAlways,
+			segmentSize: defaultSegmentMax,
+		},
+		changed: make(chan struct{}),
+		open:    openSegment,
+	}
+	for _, opt := range opts {
+		opt(&pq.config)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := pq.recover(); err != nil {
+		pq.closeFiles()
+		return nil, err
+	}
+
+	return pq, nil
+}
+
+func (pq *PersistentQueue) recover() error {
+	cursor, err := os.OpenFile(filepath.Join(pq.dir, cursorFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	pq.cursor = cursor
+
+	head, headOK := readCursor(cursor)
+
+	names, err := filepath.Glob(filepath.Join(pq.dir, `*`+segmentSuffix))
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), segmentSuffix), 10, 64)
+		if err != nil {
+			return fmt.Errorf(`Segment %s has an invalid name.`, name)
+		}
+
+		if i == 0 {
+			pq.tail = first
+			// without a valid cursor, redeliver everything left
+			if !headOK || head < first {
+				head = first
+			}
+		} else if first != pq.tail {
+			return fmt.Errorf(`Segment %s does not follow the previous segment.`, name)
+		}
+
+		items, size, err := readSegment(name, i == len(names)-1)
+		if err != nil {
+			return err
+		}
+
+		seg := &segment{first: first, count: uint64(len(items)), path: name}
+		for j, item := range items {
+			if first+uint64(j) >= head {
+				pq.items = append(pq.items, item)
+			}
+		}
+		pq.segments = append(pq.segments, seg)
+		pq.tail += seg.count
+		pq.activeSize = size
+	}
+
+	if head > pq.tail || len(names) == 0 {
+		head = pq.tail
+	}
+	pq.head = head
+
+	if len(pq.segments) == 0 {
+		return pq.rotate()
+	}
+
+	active, err := pq.open(pq.segments[len(pq.segments)-1].path, os.O_WRONLY|os.O_APPEND)
+	if err != nil {
+		return err
+	}
+	pq.active = active
+	pq.trim()
+	return nil
+}
+
+// readSegment returns the records in the named segment and the size
+// of the valid part of the file.  A bad record ends the final
+// segment, which is truncated to remove it, and is an error in any
+// other segment.
+func readSegment(name string, last bool) ([][]byte, int64, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items [][]byte
+	offset := 0
+	for offset < len(data) {
+		item, ok := decodeRecord(data[offset:])
+		if !ok {
+			if !last {
+				return nil, 0, fmt.Errorf(`Segment %s is corrupt at offset %d.`, name, offset)
+			}
+
+			if err := os.Truncate(name, int64(offset)); err != nil {
+				return nil, 0, err
+			}
+			break
+		}
+
+		items = append(items, item)
+		offset += recordHeaderSize + len(item)
+	}
+
+	return items, int64(offset), nil
+}
+
+// decodeRecord returns the record at the start of data and a bool
+// indicating if it is complete and intact.
+func decodeRecord(data []byte) ([]byte, bool) {
+	if len(data) < recordHeaderSize {
+		return nil, false
+	}
+
+	length := binary.LittleEndian.Uint32(data)
+	if uint64(len(data)-recordHeaderSize) < uint64(length) {
+		return nil, false
+	}
+
+	item := data[recordHeaderSize : recordHeaderSize+int(length)]
+	if crc32.ChecksumIEEE(item) != binary.LittleEndian.Uint32(data[4:]) {
+		return nil, false
+	}
+
+	return item, true
+}
+
+func encodeRecord(buf, item []byte) []byte {
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(len(item)))
+	binary.LittleEndian.PutUint32(header[4:], crc32.ChecksumIEEE(item))
+	buf = append(buf, header[:]...)
+	return append(buf, item...)
+}
+
+// readCursor returns the sequence number stored in the cursor file
+// and a bool indicating if it was intact.
+func readCursor(f *os.File) (uint64, bool) {
+	var buf [cursorSize]byte
+	if _, err := f.ReadAt(buf[:], 0); err != nil {
+		return 0, false
+	}
+
+	if crc32.ChecksumIEEE(buf[:8]) != binary.LittleEndian.Uint32(buf[8:]) {
+		return 0, false
+	}
+
+	return binary.LittleEndian.Uint64(buf[:]), true
+}
+
+func (pq *PersistentQueue) writeCursor() error {
+	var buf [cursorSize]byte
+	binary.LittleEndian.PutUint64(buf[:], pq.head)
+	binary.LittleEndian.PutUint32(buf[8:], crc32.ChecksumIEEE(buf[:8]))
+	if _, err := pq.cursor.WriteAt(buf[:], 0); err != nil {
+		return err
+	}
+
+	if pq.config.sync == SyncAlways {
+		return pq.cursor.Sync()
+	}
+
+	return nil
+}
+
+// rotate starts a new segment at the tail and closes the previous
+// one.  If the new segment cannot be started, the previous one stays
+// active.  Must be called with the lock held.
+func (pq *PersistentQueue) rotate() error {
+	path := segmentPath(pq.dir, pq.tail)
+	active, err := pq.open(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	if pq.active != nil {
+		if err := pq.active.Sync(); err != nil {
+			active.Close()
+			os.Remove(path)
+			return err
+		}
+		// the old segment is already on disk, so failing to close
+		// it only leaks the descriptor
+		pq.active.Close()
+	}
+
+	pq.active = active
+	pq.activeSize = 0
+	pq.segments = append(pq.segments, &segment{first: pq.tail, path: path})
+	return nil
+}
+
+// trim deletes segments whose items have all been retrieved.  The
+// active segment is always kept.  Must be called with the lock held.
+func (pq *PersistentQueue) trim() {
+	for len(pq.segments) > 1 && pq.segments[0].first+pq.segments[0].count <= pq.head {
+		// a segment that fails to delete is retried on the next
+		// trim or skipped again on recovery
+		if err := os.Remove(pq.segments[0].path); err != nil {
+			return
+		}
+		pq.segments[0] = nil
+		pq.segments = pq.segments[1:]
+	}
+}
+
+// notify wakes any waiting getters.  Must be called with the lock
+// held.
+func (pq *PersistentQueue) notify() {
+	close(pq.changed)
+	pq.changed = make(chan struct{})
+}
+
+// Put appends items to the queue.  The items are copied, so the
+// caller may reuse them once Put returns.  The items of one Put are
+// written to the same segment, so a segment may grow past the
+// configured size.  If Put returns an error none of the items were
+// added.
+func (pq *PersistentQueue) Put(items ...[]byte) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return DisposedError{}
+	}
+
+	if pq.broken != nil {
+		return pq.broken
+	}
+
+	if pq.activeSize >= pq.config.segmentSize &&
+		pq.segments[len(pq.segments)-1].count > 0 {
+
+		if err := pq.rotate(); err != nil {
+			return err
+		}
+	}
+
+	var buf []byte
+	added := make([][]byte, 0, len(items))
+	for _, item := range items {
+		buf = encodeRecord(buf, item)
+		cp := make([]byte, len(item))
+		copy(cp, item)
+		added = append(added, cp)
+	}
+
+	_, err := pq.active.Write(buf)
+	if err == nil && pq.config.sync == SyncAlways {
+		err = pq.active.Sync()
+	}
+	if err != nil {
+		// cut off whatever part of the batch reached the file so
+		// recovery does not bring back items the caller was told
+		// failed
+		if terr := pq.active.Truncate(pq.activeSize); terr != nil {
+			pq.broken = terr
+		}
+		return err
+	}
+
+	pq.activeSize += int64(len(buf))
+	pq.segments[len(pq.segments)-1].count += uint64(len(added))
+	pq.tail += uint64(len(added))
+	pq.items = append(pq.items, added...)
+	pq.notify()
+	return nil
+}
+
+// Get retrieves up to number items from the queue.  If the queue is
+// empty, this call blocks until the next item is added.
+func (pq *PersistentQueue) Get(number int64) ([][]byte, error) {
+	return pq.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (pq *PersistentQueue) GetCtx(ctx context.Context, number int64) ([][]byte, error) {
+	if number < 1 {
+		return nil, nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		pq.lock.Lock()
+		if pq.disposed {
+			pq.lock.Unlock()
+			return nil, DisposedError{}
+		}
+
+		if len(pq.items) > 0 {
+			items, err := pq.get(number)
+			pq.lock.Unlock()
+			return items, err
+		}
+
+		changed := pq.changed
+		pq.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// get removes up to number items and records their retrieval.  Must
+// be called with the lock held.
+func (pq *PersistentQueue) get(number int64) ([][]byte, error) {
+	if number > int64(len(pq.items)) {
+		number = int64(len(pq.items))
+	}
+
+	pq.head += uint64(number)
+	if err := pq.writeCursor(); err != nil {
+		pq.head -= uint64(number)
+		return nil, err
+	}
+
+	items := make([][]byte, number)
+	copy(items, pq.items)
+	for i := int64(0); i < number; i++ {
+		pq.items[i] = nil // for garbage collection
+	}
+	pq.items = pq.items[number:]
+	pq.trim()
+	return items, nil
+}
+
+// Len returns the number of items in the queue.
+func (pq *PersistentQueue) Len() int64 {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	return int64(len(pq.items))
+}
+
+// Empty returns a bool indicating if this queue is empty.
+func (pq *PersistentQueue) Empty() bool {
+	return pq.Len() == 0
+}
+
+// Sync flushes the queue's files to stable storage.  This is only
+// needed with SyncNever.
+func (pq *PersistentQueue) Sync() error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return DisposedError{}
+	}
+
+	if err := pq.active.Sync(); err != nil {
+		return err
+	}
+
+	return pq.cursor.Sync()
+}
+
+// Disposed returns a bool indicating if this queue has been closed.
+func (pq *PersistentQueue) Disposed() bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	return pq.disposed
+}
+
+// Dispose closes the queue like Close, discarding any error from
+// closing its files.  Use Close to see that error.
+func (pq *PersistentQueue) Dispose() {
+	pq.Close()
+}
+
+// Close flushes and closes the queue's files.  Any waiting getters
+// and subsequent calls return an error.  The items remain on disk to
+// be recovered by the next NewPersistent on the same directory.
+func (pq *PersistentQueue) Close() error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return nil
+	}
+
+	pq.disposed = true
+	pq.items = nil
+	pq.notify()
+	return pq.closeFiles()
+}
+
+func (pq *PersistentQueue) closeFiles() error {
+	var result error
+	if pq.active != nil {
+		result = closeFile(pq.active)
+	}
+	if pq.cursor != nil {
+		if err := closeFile(pq.cursor); err != nil && result == nil {
+			result = err
+		}
+	}
+
+	return result
+}
+
+func closeFile(f segmentFile) error {
+	err := f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func persistentDir(t *testing.T) string {
+	dir, err := os.MkdirTemp(``, `persistent`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func byteItems(items ...string) [][]byte {
+	result := make([][]byte, 0, len(items))
+	for _, item := range items {
+		result = append(result, []byte(item))
+	}
+
+	return result
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	names, err := filepath.Glob(filepath.Join(dir, `*`+segmentSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return names
+}
+
+var errInjected = errors.New(`injected failure`)
+
+// faultyFile fails the operations it is told to.  A failing write
+// still writes half of its buffer, like a disk filling up.
+type faultyFile struct {
+	segmentFile
+	failWrite, failSync, failTruncate bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if !f.failWrite {
+		return f.segmentFile.Write(p)
+	}
+
+	n, _ := f.segmentFile.Write(p[:len(p)/2])
+	return n, errInjected
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		return errInjected
+	}
+
+	return f.segmentFile.Sync()
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return errInjected
+	}
+
+	return f.segmentFile.Truncate(size)
+}
+
+func TestPersistentPutGet(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	item := []byte(`a`)
+	assert.Nil(t, q.Put(item, []byte(`b`), []byte(`c`)))
+	item[0] = 'z' // the queue keeps its own copy
+	assert.Equal(t, int64(3), q.Len())
+
+	result, err := q.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, byteItems(`a`, `b`), result)
+
+	result, err = q.Get(5)
+	assert.Nil(t, err)
+	assert.Equal(t, byteItems(`c`), result)
+	assert.True(t, q.Empty())
+
+	result, err = q.Get(0)
+	assert.Nil(t, err)
+	assert.Len(t, result, 0)
+}
+
+func TestPersistentRecovery(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	q.Put(byteItems(`a`, `b`, `c`, `d`)...)
+	q.Get(1)
+	assert.Nil(t, q.Close())
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, int64(3), q.Len())
+
+	q.Put([]byte(`e`))
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`b`, `c`, `d`, `e`), result)
+	assert.Nil(t, q.Close())
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+	assert.True(t, q.Empty())
+}
+
+func TestPersistentRecoveryWithoutClose(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	// a queue that is never closed stands in for a crashed process
+	crashed, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	crashed.Put(byteItems(`a`, `b`)...)
+	crashed.Get(1)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+	defer crashed.closeFiles()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`b`), result)
+}
+
+func TestPersistentTornRecord(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	q.Put(byteItems(`a`, `b`)...)
+	q.Close()
+
+	// simulate a crash in the middle of writing a record
+	names := segmentFiles(t, dir)
+	f, err := os.OpenFile(names[len(names)-1], os.O_WRONLY|os.O_APPEND, 0644)
+	if !assert.Nil(t, err) {
+		return
+	}
+	f.Write(encodeRecord(nil, []byte(`torn`))[:6])
+	f.Close()
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	q.Put([]byte(`c`))
+	q.Close()
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`a`, `b`, `c`), result)
+}
+
+func TestPersistentCorruptRecord(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	q.Put(byteItems(`a`, `b`)...)
+	q.Close()
+
+	// flip a payload byte in the second record
+	names := segmentFiles(t, dir)
+	data, _ := os.ReadFile(names[0])
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(names[0], data, 0644)
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`a`), result)
+}
+
+func TestPersistentCorruptCursorRedelivers(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	q.Put(byteItems(`a`, `b`)...)
+	q.Get(1)
+	q.Close()
+
+	os.WriteFile(filepath.Join(dir, cursorFile), []byte(`garbage`), 0644)
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`a`, `b`), result)
+}
+
+func TestPersistentSegments(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir, WithSegmentSize(64), WithSyncPolicy(SyncNever))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	items := make([][]byte, 0, 100)
+	for i := 0; i < 100; i++ {
+		items = append(items, []byte{byte(i), 1, 2, 3, 4, 5, 6, 7})
+	}
+	for _, item := range items[:50] {
+		q.Put(item)
+	}
+	q.Put(items[50:]...)
+	assert.Nil(t, q.Sync())
+	assert.True(t, len(segmentFiles(t, dir)) > 10)
+
+	result, _ := q.Get(90)
+	assert.Equal(t, items[:90], result)
+	assert.True(t, len(segmentFiles(t, dir)) <= 3)
+	q.Close()
+
+	q, err = NewPersistent(dir, WithSegmentSize(64))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ = q.Get(100)
+	assert.Equal(t, items[90:], result)
+}
+
+func TestPersistentGetBlocks(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Put([]byte(`a`))
+	}()
+
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, byteItems(`a`), result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.GetCtx(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		_, err = q.Get(1)
+		wg.Done()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(t, q.Close())
+	wg.Wait()
+	assert.IsType(t, DisposedError{}, err)
+	assert.True(t, q.Disposed())
+	assert.IsType(t, DisposedError{}, q.Put([]byte(`b`)))
+	assert.Nil(t, q.Close())
+}
+
+func TestPersistentPutWriteFailure(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Nil(t, q.Put([]byte(`a`)))
+	file := &faultyFile{segmentFile: q.active, failWrite: true}
+	q.active = file
+	assert.Equal(t, errInjected, q.Put(byteItems(`b`, `c`)...))
+
+	file.failWrite = false
+	file.failSync = true
+	assert.Equal(t, errInjected, q.Put([]byte(`d`)))
+
+	file.failSync = false
+	assert.Nil(t, q.Put([]byte(`e`)))
+	assert.Equal(t, uint64(2), q.tail)
+	assert.Nil(t, q.Close())
+
+	// the failed writes were cut out of the log
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`a`, `e`), result)
+}
+
+func TestPersistentPutTruncateFailure(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	file := &faultyFile{segmentFile: q.active, failWrite: true, failTruncate: true}
+	q.active = file
+	assert.Equal(t, errInjected, q.Put([]byte(`a`)))
+
+	// the segment ends in a partial record, so nothing more may be
+	// appended after it
+	file.failWrite = false
+	assert.Equal(t, errInjected, q.Put([]byte(`b`)))
+	assert.Equal(t, uint64(0), q.tail)
+}
+
+func TestPersistentRotateFailure(t *testing.T) {
+	dir := persistentDir(t)
+	defer os.RemoveAll(dir)
+
+	q, err := NewPersistent(dir, WithSegmentSize(1))
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Nil(t, q.Put([]byte(`a`)))
+	q.open = func(string, int) (segmentFile, error) {
+		return nil, errInjected
+	}
+	assert.Equal(t, errInjected, q.Put([]byte(`b`)))
+	assert.Equal(t, errInjected, q.Put([]byte(`b`)))
+	assert.Len(t, segmentFiles(t, dir), 1)
+
+	q.open = openSegment
+	assert.Nil(t, q.Put([]byte(`c`)))
+	assert.Len(t, segmentFiles(t, dir), 2)
+	assert.Nil(t, q.Close())
+
+	q, err = NewPersistent(dir)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer q.Close()
+
+	result, _ := q.Get(10)
+	assert.Equal(t, byteItems(`a`, `c`), result)
+}
@@ -24,8 +24,11 @@ would be easier to solve.
 package queue
 
 import (
+	"context"
+	"fmt"
 	"sort"
 	"sync"
+	"unsafe"
 )
 
 // Item is an item that can be added to the priority queue.
@@ -58,7 +61,10 @@ func (items *priorityItems) get(number int) []Item {
 	return returnItems
 }
 
-func (items *priorityItems) insert(item Item) {
+// insert adds item in the order given by compare.  If duplicates
+// are allowed the item goes after any equal items, otherwise an
+// equal item means item is dropped.
+func (items *priorityItems) insert(item Item, compare func(a, b Item) int, allowDuplicates bool) {
 	if len(*items) == 0 {
 		*items = append(*items, item)
 		return
@@ -66,14 +72,15 @@ func (items *priorityItems) insert(item Item) {
 
 	equalFound := false
 	i := sort.Search(len(*items), func(i int) bool {
-		result := (*items)[i].Compare(item)
+		result := compare((*items)[i], item)
 		if result == 0 {
 			equalFound = true
+			return !allowDuplicates
 		}
-		return result >= 0
+		return result > 0
 	})
 
-	if equalFound {
+	if equalFound && !allowDuplicates {
 		return
 	}
 
@@ -87,6 +94,41 @@ func (items *priorityItems) insert(item Item) {
 	(*items)[i] = item
 }
 
+// remove deletes the item that is identical to, rather than equal
+// to, the provided item and returns a bool indicating if it was
+// found.
+func (items *priorityItems) remove(item Item) bool {
+	for i, existing := range *items {
+		if existing != item {
+			continue
+		}
+
+		copy((*items)[i:], (*items)[i+1:])
+		(*items)[len(*items)-1] = nil // for garbage collection
+		*items = (*items)[:len(*items)-1]
+		return true
+	}
+
+	return false
+}
+
+// FullPolicy determines what a put does when a priority queue is at
+// its capacity.
+type FullPolicy int
+
+const (
+	// FullBlock makes a put wait for room for every item.
+	FullBlock FullPolicy = iota
+	// FullReject makes a put return a FullError, adding none of its
+	// items, if they don't all fit.
+	FullReject
+	// FullDropLowest adds every item and then drops the lowest
+	// priority items, those that would be gotten last, until the
+	// queue is back at its capacity.  The dropped items may be ones
+	// that were just put.
+	FullDropLowest
+)
+
 // PriorityQueue is similar to queue except that it takes
 // items that implement the Item interface and adds them
 // to the queue in priority order.
@@ -96,50 +138,153 @@ type PriorityQueue struct {
 	lock        sync.Mutex
 	disposeLock sync.Mutex
 	disposed    bool
+	// compare orders the items in place of Item.Compare if set.
+	compare         func(a, b Item) int
+	allowDuplicates bool
+	// capacity, if greater than 0, bounds the number of items with
+	// full deciding what a put does when there are that many.
+	capacity int
+	full     FullPolicy
+	// room, if not nil, is closed when items are removed to wake
+	// puts waiting for room.
+	room chan struct{}
 }
 
-// Put adds items to the queue.
-func (pq *PriorityQueue) Put(items ...Item) error {
-	if len(items) == 0 {
-		return nil
+func (pq *PriorityQueue) compareItems(a, b Item) int {
+	if pq.compare != nil {
+		return pq.compare(a, b)
 	}
 
-	pq.lock.Lock()
-	if pq.disposed {
+	return a.Compare(b)
+}
+
+// Put adds items to the queue.  If the queue has a capacity and is
+// full, Put does what its FullPolicy says.
+func (pq *PriorityQueue) Put(items ...Item) error {
+	return pq.PutCtx(context.Background(), items...)
+}
+
+// PutCtx is like Put except that a put waiting for room stops and
+// returns the context's error once the context is done.  Items that
+// were added before then stay in the queue.
+func (pq *PriorityQueue) PutCtx(ctx context.Context, items ...Item) error {
+	for len(items) > 0 {
+		pq.lock.Lock()
+		if pq.disposed {
+			pq.lock.Unlock()
+			return DisposedError{}
+		}
+
+		n := len(items)
+		if pq.capacity > 0 {
+			switch pq.full {
+			case FullBlock:
+				n = min(n, pq.capacity-len(pq.items))
+			case FullReject:
+				if len(pq.items)+n > pq.capacity {
+					pq.lock.Unlock()
+					return FullError{}
+				}
+			}
+		}
+
+		if n <= 0 {
+			if pq.room == nil {
+				pq.room = make(chan struct{})
+			}
+			room := pq.room
+			pq.lock.Unlock()
+
+			select {
+			case <-room:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			continue
+		}
+
+		for _, item := range items[:n] {
+			pq.items.insert(item, pq.compareItems, pq.allowDuplicates)
+		}
+		items = items[n:]
+
+		if pq.capacity > 0 && pq.full == FullDropLowest {
+			for i := pq.capacity; i < len(pq.items); i++ {
+				pq.items[i] = nil // for garbage collection
+			}
+			pq.items = pq.items[:min(len(pq.items), pq.capacity)]
+		}
+
+		pq.signal()
 		pq.lock.Unlock()
-		return DisposedError{}
 	}
 
-	for _, item := range items {
-		pq.items.insert(item)
-	}
+	return nil
+}
 
+// signal hands items to waiting gets.  This must be called with the
+// lock held.
+func (pq *PriorityQueue) signal() {
 	for {
 		sema := pq.waiters.get()
 		if sema == nil {
 			break
 		}
+		if !sema.claim() {
+			continue
+		}
 
 		sema.response.Add(1)
-		sema.wg.Done()
+		sema.signal()
 		sema.response.Wait()
 		if len(pq.items) == 0 {
 			break
 		}
 	}
+}
 
-	pq.lock.Unlock()
-	return nil
+// freed wakes any puts waiting for room after items were removed.
+// This must be called with the lock held.
+func (pq *PriorityQueue) freed() {
+	if pq.room != nil {
+		close(pq.room)
+		pq.room = nil
+	}
+}
+
+// SetCapacity bounds the number of items in the queue, with policy
+// deciding what a put does once the queue is full.  A capacity less
+// than 1 leaves the queue unbounded, which is the default.  Items
+// already in the queue are kept even if there are more than the new
+// capacity.
+func (pq *PriorityQueue) SetCapacity(capacity int, policy FullPolicy) {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	pq.capacity = max(capacity, 0)
+	pq.full = policy
+	// waiting puts check again against the new capacity
+	pq.freed()
 }
 
 // Get retrieves items from the queue.  If the queue is empty,
 // this call blocks until the next item is added to the queue.  This
 // will attempt to retrieve number of items.
 func (pq *PriorityQueue) Get(number int) ([]Item, error) {
+	return pq.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (pq *PriorityQueue) GetCtx(ctx context.Context, number int) ([]Item, error) {
 	if number < 1 {
 		return nil, nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	pq.lock.Lock()
 
 	if pq.disposed {
@@ -152,10 +297,11 @@ func (pq *PriorityQueue) Get(number int) ([]Item, error) {
 	if len(pq.items) == 0 {
 		sema := newSema()
 		pq.waiters.put(sema)
-		sema.wg.Add(1)
 		pq.lock.Unlock()
 
-		sema.wg.Wait()
+		if !sema.wait(ctx) {
+			return nil, ctx.Err()
+		}
 		pq.disposeLock.Lock()
 		if pq.disposed {
 			pq.disposeLock.Unlock()
@@ -163,16 +309,80 @@ func (pq *PriorityQueue) Get(number int) ([]Item, error) {
 		}
 		pq.disposeLock.Unlock()
 
+		// the put handing over the items holds the lock
 		items = pq.items.get(number)
+		pq.freed()
 		sema.response.Done()
 		return items, nil
 	}
 
 	items = pq.items.get(number)
+	pq.freed()
 	pq.lock.Unlock()
 	return items, nil
 }
 
+// Drain removes and returns up to max items from the queue in
+// priority order without blocking.  A max less than 1 drains every
+// item.  Nil is returned if the queue is empty or disposed.
+func (pq *PriorityQueue) Drain(max int) []Item {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed || len(pq.items) == 0 {
+		return nil
+	}
+
+	if max < 1 || max > len(pq.items) {
+		max = len(pq.items)
+	}
+
+	pq.freed()
+	return pq.items.get(max)
+}
+
+// Snapshot returns a copy of the items in the queue in priority
+// order without removing them.
+func (pq *PriorityQueue) Snapshot() []Item {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	items := make([]Item, len(pq.items))
+	copy(items, pq.items)
+	return items
+}
+
+// Update moves an item already in the queue to its new position
+// after its priority has changed.  The item is matched by identity,
+// so items whose priority can change should be pointers.  Returns a
+// bool indicating if the item was found.  This is an O(n) operation.
+func (pq *PriorityQueue) Update(item Item) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed || !pq.items.remove(item) {
+		return false
+	}
+
+	pq.items.insert(item, pq.compareItems, pq.allowDuplicates)
+	return true
+}
+
+// Remove deletes an item from the queue.  The item is matched by
+// identity rather than by comparison.  Returns a bool indicating if
+// the item was found.  This is an O(n) operation.
+func (pq *PriorityQueue) Remove(item Item) bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed || !pq.items.remove(item) {
+		return false
+	}
+
+	pq.freed()
+	return true
+}
+
 // Peek will look at the next item without removing it from the queue.
 func (pq *PriorityQueue) Peek() Item {
 	pq.lock.Lock()
@@ -200,6 +410,40 @@ func (pq *PriorityQueue) Len() int {
 	return len(pq.items)
 }
 
+// SizeOf returns an estimate of the number of bytes used by this
+// queue, not including the memory referenced by its items.
+func (pq *PriorityQueue) SizeOf() uint64 {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	return uint64(unsafe.Sizeof(*pq)) + uint64(cap(pq.items))*itemSize
+}
+
+// Validate returns an error if the items in the queue are not held
+// in ascending priority order, or hold equal items when duplicates
+// aren't allowed.  This is intended for tests and fuzzing.
+func (pq *PriorityQueue) Validate() error {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	for i, item := range pq.items {
+		if item == nil {
+			return fmt.Errorf(`Priority queue has a nil item at %d.`, i)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		result := pq.compareItems(pq.items[i-1], item)
+		if result > 0 || (result == 0 && !pq.allowDuplicates) {
+			return fmt.Errorf(`Priority queue items out of order at %d.`, i)
+		}
+	}
+
+	return nil
+}
+
 // Disposed returns a bool indicating if this queue has been disposed.
 func (pq *PriorityQueue) Disposed() bool {
 	pq.lock.Lock()
@@ -217,14 +461,21 @@ func (pq *PriorityQueue) Dispose() {
 	pq.disposeLock.Lock()
 	defer pq.disposeLock.Unlock()
 
+	if pq.disposed {
+		return
+	}
+
 	pq.disposed = true
 	for _, waiter := range pq.waiters {
-		waiter.response.Add(1)
-		waiter.wg.Done()
+		if waiter.claim() {
+			waiter.response.Add(1)
+			waiter.signal()
+		}
 	}
 
 	pq.items = nil
 	pq.waiters = nil
+	pq.freed()
 }
 
 // NewPriorityQueue is the constructor for a priority queue.
@@ -233,3 +484,16 @@ func NewPriorityQueue(hint int) *PriorityQueue {
 		items: make(priorityItems, 0, hint),
 	}
 }
+
+// NewPriorityQueueFunc returns a priority queue that orders items
+// with compare instead of their Compare methods.  compare follows
+// the same convention as Item.Compare.  If allowDuplicates is true,
+// items that compare as equal are all kept and returned in the
+// order they were put; otherwise only the first is kept.
+func NewPriorityQueueFunc(hint int, compare func(a, b Item) int, allowDuplicates bool) *PriorityQueue {
+	return &PriorityQueue{
+		items:           make(priorityItems, 0, hint),
+		compare:         compare,
+		allowDuplicates: allowDuplicates,
+	}
+}
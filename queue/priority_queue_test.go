@@ -17,8 +17,10 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -245,3 +247,292 @@ func TestInsertDuplicate(t *testing.T) {
 
 	assert.Equal(t, 1, q.Len())
 }
+
+func TestPriorityValidate(t *testing.T) {
+	q := NewPriorityQueue(1)
+	assert.Nil(t, q.Validate())
+
+	q.Put(mockItem(5), mockItem(1), mockItem(3), mockItem(1), mockItem(4))
+	assert.Nil(t, q.Validate())
+
+	q.Get(2)
+	assert.Nil(t, q.Validate())
+
+	q.items[0], q.items[1] = q.items[1], q.items[0]
+	assert.NotNil(t, q.Validate())
+
+	q.items[0] = nil
+	assert.NotNil(t, q.Validate())
+}
+
+func TestPrioritySizeOf(t *testing.T) {
+	q := NewPriorityQueue(10)
+	empty := q.SizeOf()
+	assert.True(t, empty >= 10*itemSize)
+
+	for i := 0; i < 100; i++ {
+		q.Put(mockItem(i))
+	}
+	assert.True(t, q.SizeOf() >= empty+90*itemSize)
+}
+
+func TestPriorityDrain(t *testing.T) {
+	q := NewPriorityQueue(10)
+	assert.Nil(t, q.Drain(5))
+
+	q.Put(mockItem(3), mockItem(1), mockItem(2), mockItem(4))
+
+	result := q.Drain(2)
+	assert.Equal(t, []Item{mockItem(1), mockItem(2)}, result)
+	assert.Equal(t, 2, q.Len())
+
+	result = q.Drain(0)
+	assert.Equal(t, []Item{mockItem(3), mockItem(4)}, result)
+	assert.True(t, q.Empty())
+
+	q.Put(mockItem(1))
+	q.Dispose()
+	assert.Nil(t, q.Drain(1))
+}
+
+func TestPrioritySnapshot(t *testing.T) {
+	q := NewPriorityQueue(10)
+	assert.Len(t, q.Snapshot(), 0)
+
+	q.Put(mockItem(2), mockItem(1))
+
+	snapshot := q.Snapshot()
+	assert.Equal(t, []Item{mockItem(1), mockItem(2)}, snapshot)
+
+	q.Get(1)
+	assert.Equal(t, []Item{mockItem(1), mockItem(2)}, snapshot)
+	assert.Equal(t, []Item{mockItem(2)}, q.Snapshot())
+}
+
+func TestPriorityGetCtx(t *testing.T) {
+	q := NewPriorityQueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := q.GetCtx(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	q.Put(mockItem(1))
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(1)}, result)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Put(mockItem(2))
+	}()
+
+	result, err = q.GetCtx(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(2)}, result)
+}
+
+type taskItem struct {
+	name     string
+	priority int
+}
+
+func (ti *taskItem) Compare(other Item) int {
+	o := other.(*taskItem)
+	if ti.priority > o.priority {
+		return 1
+	} else if ti.priority == o.priority {
+		return 0
+	}
+	return -1
+}
+
+func TestPriorityQueueFunc(t *testing.T) {
+	// reverse the natural order
+	q := NewPriorityQueueFunc(10, func(a, b Item) int {
+		return b.Compare(a)
+	}, false)
+
+	q.Put(mockItem(1), mockItem(3), mockItem(2), mockItem(3))
+	assert.Equal(t, 3, q.Len())
+	assert.Nil(t, q.Validate())
+
+	result, err := q.Get(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(3), mockItem(2), mockItem(1)}, result)
+}
+
+func TestPriorityQueueAllowDuplicates(t *testing.T) {
+	q := NewPriorityQueueFunc(10, func(a, b Item) int {
+		return a.Compare(b)
+	}, true)
+
+	a := &taskItem{name: `a`, priority: 1}
+	b := &taskItem{name: `b`, priority: 1}
+	c := &taskItem{name: `c`, priority: 0}
+	q.Put(a, b, c)
+	assert.Equal(t, 3, q.Len())
+	assert.Nil(t, q.Validate())
+
+	result, _ := q.Get(3)
+	assert.Equal(t, []Item{c, a, b}, result)
+}
+
+func TestPriorityUpdate(t *testing.T) {
+	q := NewPriorityQueueFunc(10, func(a, b Item) int {
+		return a.Compare(b)
+	}, true)
+
+	tasks := []*taskItem{
+		{name: `a`, priority: 5},
+		{name: `b`, priority: 3},
+		{name: `c`, priority: 8},
+	}
+	for _, task := range tasks {
+		q.Put(task)
+	}
+	assert.Equal(t, tasks[1], q.Peek())
+
+	tasks[2].priority = 1
+	assert.True(t, q.Update(tasks[2]))
+	assert.Equal(t, tasks[2], q.Peek())
+	assert.Nil(t, q.Validate())
+
+	tasks[2].priority = 10
+	assert.True(t, q.Update(tasks[2]))
+	assert.Equal(t, []Item{tasks[1], tasks[0], tasks[2]}, q.Snapshot())
+
+	assert.False(t, q.Update(&taskItem{name: `a`, priority: 5}))
+	assert.Equal(t, 3, q.Len())
+
+	q.Dispose()
+	assert.False(t, q.Update(tasks[0]))
+}
+
+func TestPriorityRemove(t *testing.T) {
+	q := NewPriorityQueue(10)
+	q.Put(mockItem(1), mockItem(2), mockItem(3))
+
+	assert.True(t, q.Remove(mockItem(2)))
+	assert.False(t, q.Remove(mockItem(2)))
+	assert.Equal(t, []Item{mockItem(1), mockItem(3)}, q.Snapshot())
+
+	q.Dispose()
+	assert.False(t, q.Remove(mockItem(1)))
+}
+
+func TestPriorityCapacityReject(t *testing.T) {
+	q := NewPriorityQueue(4)
+	q.SetCapacity(3, FullReject)
+
+	assert.Nil(t, q.Put(mockItem(3), mockItem(1)))
+	// the two items don't both fit so neither is added
+	assert.Equal(t, FullError{}, q.Put(mockItem(4), mockItem(2)))
+	assert.Equal(t, 2, q.Len())
+
+	assert.Nil(t, q.Put(mockItem(2)))
+	assert.Equal(t, FullError{}, q.Put(mockItem(0)))
+
+	assert.Equal(t, []Item{mockItem(1)}, q.Drain(1))
+	assert.Nil(t, q.Put(mockItem(0)))
+	assert.Equal(t, []Item{mockItem(0), mockItem(2), mockItem(3)}, q.Snapshot())
+}
+
+func TestPriorityCapacityDropLowest(t *testing.T) {
+	q := NewPriorityQueue(4)
+	q.SetCapacity(3, FullDropLowest)
+
+	assert.Nil(t, q.Put(mockItem(5), mockItem(2), mockItem(4), mockItem(1)))
+	assert.Equal(t, []Item{mockItem(1), mockItem(2), mockItem(4)}, q.Snapshot())
+
+	// an item of lower priority than any in the queue is itself dropped
+	assert.Nil(t, q.Put(mockItem(9)))
+	assert.Equal(t, []Item{mockItem(1), mockItem(2), mockItem(4)}, q.Snapshot())
+
+	assert.Nil(t, q.Put(mockItem(0)))
+	assert.Equal(t, mockItem(0), q.Peek())
+	assert.Equal(t, 3, q.Len())
+	assert.Nil(t, q.Validate())
+}
+
+func TestPriorityCapacityBlock(t *testing.T) {
+	q := NewPriorityQueue(2)
+	q.SetCapacity(2, FullBlock)
+	assert.Nil(t, q.Put(mockItem(1), mockItem(2)))
+
+	done := make(chan error)
+	go func() {
+		done <- q.Put(mockItem(3), mockItem(4))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal(`put did not block on a full queue`)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// each item gotten makes room for one of the waiting put's
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(1)}, result)
+	result, err = q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(2)}, result)
+
+	assert.Nil(t, <-done)
+	assert.Equal(t, []Item{mockItem(3), mockItem(4)}, q.Drain(0))
+}
+
+func TestPriorityCapacityBlockCtx(t *testing.T) {
+	q := NewPriorityQueue(1)
+	q.SetCapacity(1, FullBlock)
+	assert.Nil(t, q.Put(mockItem(1)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, q.PutCtx(ctx, mockItem(2)))
+	assert.Equal(t, []Item{mockItem(1)}, q.Snapshot())
+
+	done := make(chan error)
+	go func() {
+		done <- q.Put(mockItem(2))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Dispose()
+	assert.Equal(t, DisposedError{}, <-done)
+}
+
+func TestPriorityCapacityBlockHandsOff(t *testing.T) {
+	// a full queue still hands items to waiting gets
+	q := NewPriorityQueue(1)
+	q.SetCapacity(1, FullBlock)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, err := q.Get(1)
+			assert.Nil(t, err)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, q.Put(mockItem(i)))
+	}
+	wg.Wait()
+	assert.True(t, q.Empty())
+
+	// raising the capacity releases a waiting put
+	q.Put(mockItem(1))
+	done := make(chan error)
+	go func() {
+		done <- q.Put(mockItem(2))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.SetCapacity(0, FullBlock)
+	assert.Nil(t, <-done)
+	assert.Equal(t, 2, q.Len())
+}
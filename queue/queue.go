@@ -15,27 +15,66 @@ limitations under the License.
 */
 
 /*
-Package queue includes a regular queue and a priority queue.
+Package queue includes a regular queue, a priority queue, a queue
+of strict priority classes, a delay queue whose items only become
+available once their deadlines pass and a persistent queue backed by
+a write-ahead log.
 These queues rely on waitgroups to pause listening threads
 on empty queues until a message is received.  If any thread
 calls Dispose on the queue, any listeners are immediately returned
-with an error.  Any subsequent put to the queue will return an error
-as opposed to panicking as with channels.  Queues will grow with unbounded
+with a DisposedError, which matches common.ErrDisposed with
+errors.Is, and every queue satisfies common.Disposable.  A single
+listener can instead be released by cancelling the context passed
+to GetCtx.  Any subsequent put to
+the queue will return an error as opposed to panicking as with
+channels.  Queues will grow with unbounded
 behavior as opposed to channels which can be buffered but will pause
-while a thread attempts to put to a full channel.
+while a thread attempts to put to a full channel.  A priority queue
+can be given a capacity with SetCapacity, in which case a put to a
+full queue waits, is rejected or drops the lowest priority items, so
+it can serve as an admission queue.
+
+Broadcast fans items out rather than sharing them: every subscriber
+gets every item put after it subscribed, each reading the items from
+one shared ring at its own pace.  A SlowPolicy decides whether a put
+waits for, skips items of or disconnects a subscriber that has fallen
+a full ring behind.
+
+MPMC is a bounded, lock-free alternative for workloads with many
+producers and consumers.  Against the locked queue and a buffered
+channel of the same size, with one consumer and GOMAXPROCS=1:
+
+BenchmarkMPMC1Producer			18923762	    58.37 ns/op
+BenchmarkMPMC4Producers			20893358	    66.18 ns/op
+BenchmarkMPMC16Producers		13699617	    86.03 ns/op
+BenchmarkLockedQueue1Producer		 5244020	   261.8 ns/op
+BenchmarkLockedQueue4Producers		 4748875	   214.3 ns/op
+BenchmarkLockedQueue16Producers		 6338383	   221.1 ns/op
+BenchmarkChannel1Producer		14444128	    81.46 ns/op
+BenchmarkChannel4Producers		15594612	    82.39 ns/op
+BenchmarkChannel16Producers		15171070	    77.89 ns/op
+
+WorkStealing is an unbounded, lock-free Chase-Lev deque for building
+task schedulers.  Its owner pushes and pops at one end without
+contention while other goroutines steal from the other.
 
 TODO: Unify the two types of queue to the same interface.
-TODO: Implement an even faster lockless circular buffer.
 */
 
 package queue
 
 import (
+	"context"
 	"runtime"
 	"sync"
 	"sync/atomic"
+	"unsafe"
 )
 
+// itemSize is the number of bytes used to hold a single item in a
+// queue, not including the memory the item itself references.
+const itemSize = uint64(unsafe.Sizeof(interface{}(nil)))
+
 type waiters []*sema
 
 func (w *waiters) get() *sema {
@@ -97,18 +136,56 @@ func (items *items) getUntil(checker func(item interface{}) bool) []interface{}
 	return returnItems
 }
 
+const (
+	semaWaiting int32 = iota
+	semaClaimed
+	semaCancelled
+)
+
 type sema struct {
-	wg       *sync.WaitGroup
+	ready    chan struct{}
 	response *sync.WaitGroup
+	// state moves from semaWaiting to semaClaimed when a put or
+	// dispose takes this waiter, or to semaCancelled when the
+	// getter gives up first.
+	state int32
 }
 
 func newSema() *sema {
 	return &sema{
-		wg:       &sync.WaitGroup{},
+		ready:    make(chan struct{}),
 		response: &sync.WaitGroup{},
 	}
 }
 
+// claim returns a bool indicating if the waiter is still waiting and
+// has now been claimed.  Must be called with the queue's lock held.
+func (s *sema) claim() bool {
+	return atomic.CompareAndSwapInt32(&s.state, semaWaiting, semaClaimed)
+}
+
+// signal wakes a claimed waiter.
+func (s *sema) signal() {
+	close(s.ready)
+}
+
+// wait blocks until the waiter is signaled or the context is done
+// and returns a bool indicating if it was signaled.  A waiter that
+// was claimed before the context finished is always signaled.
+func (s *sema) wait(ctx context.Context) bool {
+	select {
+	case <-s.ready:
+		return true
+	case <-ctx.Done():
+		if atomic.CompareAndSwapInt32(&s.state, semaWaiting, semaCancelled) {
+			return false
+		}
+
+		<-s.ready
+		return true
+	}
+}
+
 // Queue is the struct responsible for tracking the state
 // of the queue.
 type Queue struct {
@@ -116,6 +193,28 @@ type Queue struct {
 	items    items
 	lock     sync.Mutex
 	disposed bool
+	// shared indicates that items is also referenced by a
+	// snapshot and must be copied before it is mutated.
+	shared bool
+	// pump is the channel returned by AsChan while its goroutine
+	// runs, which cancelPump stops.  stopPump is the stop func
+	// handed out with pump.
+	pump       chan interface{}
+	cancelPump context.CancelFunc
+	stopPump   func()
+}
+
+// unshare copies this queue's items if they are referenced by
+// a snapshot.  Must be called with the lock held.
+func (q *Queue) unshare() {
+	if !q.shared {
+		return
+	}
+
+	items := make(items, len(q.items), cap(q.items))
+	copy(items, q.items)
+	q.items = items
+	q.shared = false
 }
 
 // Put will add the specified items to the queue.
@@ -131,14 +230,18 @@ func (q *Queue) Put(items ...interface{}) error {
 		return DisposedError{}
 	}
 
+	q.unshare()
 	q.items = append(q.items, items...)
 	for {
 		sema := q.waiters.get()
 		if sema == nil {
 			break
 		}
+		if !sema.claim() {
+			continue
+		}
 		sema.response.Add(1)
-		sema.wg.Done()
+		sema.signal()
 		sema.response.Wait()
 		if len(q.items) == 0 {
 			break
@@ -154,11 +257,21 @@ func (q *Queue) Put(items ...interface{}) error {
 // parameter.  If no items are in the queue, this method will pause
 // until items are added to the queue.
 func (q *Queue) Get(number int64) ([]interface{}, error) {
+	return q.GetCtx(context.Background(), number)
+}
+
+// GetCtx is like Get except that it stops waiting for items and
+// returns the context's error once the context is done.
+func (q *Queue) GetCtx(ctx context.Context, number int64) ([]interface{}, error) {
 	if number < 1 {
 		// thanks again go
 		return []interface{}{}, nil
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	q.lock.Lock()
 
 	if q.disposed {
@@ -171,19 +284,22 @@ func (q *Queue) Get(number int64) ([]interface{}, error) {
 	if len(q.items) == 0 {
 		sema := newSema()
 		q.waiters.put(sema)
-		sema.wg.Add(1)
 		q.lock.Unlock()
 
-		sema.wg.Wait()
+		if !sema.wait(ctx) {
+			return nil, ctx.Err()
+		}
 		// we are now inside the put's lock
 		if q.disposed {
 			return nil, DisposedError{}
 		}
+		q.unshare()
 		items = q.items.get(number)
 		sema.response.Done()
 		return items, nil
 	}
 
+	q.unshare()
 	items = q.items.get(number)
 	q.lock.Unlock()
 	return items, nil
@@ -225,6 +341,125 @@ func (q *Queue) Len() int64 {
 	return int64(len(q.items))
 }
 
+// SizeOf returns an estimate of the number of bytes used by this
+// queue, not including the memory referenced by its items.
+func (q *Queue) SizeOf() uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return uint64(unsafe.Sizeof(*q)) + uint64(cap(q.items))*itemSize
+}
+
+// Snapshot returns a point-in-time copy of this queue.  The copy
+// shares storage with this queue until either is modified, so
+// taking a snapshot is an O(1) operation.  The returned queue is
+// independent of this one and is never disposed, even if this
+// queue has been.
+func (q *Queue) Snapshot() *Queue {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.shared = true
+	return &Queue{
+		items:  q.items,
+		shared: true,
+	}
+}
+
+// AsChan returns a channel that receives the items of this queue in
+// order, so the queue can be used in select statements, and a func
+// that stops feeding it.  The channel is fed by a single goroutine
+// that waits for an item and only removes it from the queue once a
+// reader has received it, so an item is never lost when the channel
+// is stopped or the queue disposed.  Calls made while the goroutine
+// runs share its channel and stop func.  The channel is closed once
+// it is stopped or the queue is disposed, and the goroutine runs
+// until then.  Items must not be taken with Get or TakeUntil while
+// the channel is in use, as an item removed that way may also be
+// delivered on the channel.
+func (q *Queue) AsChan() (<-chan interface{}, func()) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.pump == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		pump := make(chan interface{})
+		q.pump, q.cancelPump = pump, cancel
+		q.stopPump = func() {
+			q.lock.Lock()
+			// a later call starts a new goroutine, unless the queue
+			// was disposed
+			if q.pump == pump && !q.disposed {
+				q.pump, q.cancelPump, q.stopPump = nil, nil, nil
+			}
+			q.lock.Unlock()
+			cancel()
+		}
+		if q.disposed {
+			cancel()
+		}
+		go q.runPump(ctx, pump)
+	}
+
+	return q.pump, q.stopPump
+}
+
+func (q *Queue) runPump(ctx context.Context, pump chan interface{}) {
+	defer close(pump)
+
+	for {
+		item, err := q.peekCtx(ctx)
+		if err != nil {
+			return
+		}
+
+		select {
+		case pump <- item:
+		case <-ctx.Done():
+			return
+		}
+
+		q.lock.Lock()
+		if !q.disposed && len(q.items) > 0 {
+			q.unshare()
+			q.items.get(1)
+		}
+		q.lock.Unlock()
+	}
+}
+
+// peekCtx waits like GetCtx for the queue to hold an item and
+// returns the first one without removing it.
+func (q *Queue) peekCtx(ctx context.Context) (interface{}, error) {
+	q.lock.Lock()
+
+	if q.disposed {
+		q.lock.Unlock()
+		return nil, DisposedError{}
+	}
+
+	if len(q.items) > 0 {
+		item := q.items[0]
+		q.lock.Unlock()
+		return item, nil
+	}
+
+	sema := newSema()
+	q.waiters.put(sema)
+	q.lock.Unlock()
+
+	if !sema.wait(ctx) {
+		return nil, ctx.Err()
+	}
+	// we are now inside the put's lock
+	defer sema.response.Done()
+	if q.disposed {
+		return nil, DisposedError{}
+	}
+
+	return q.items[0], nil
+}
+
 // Disposed returns a bool indicating if this queue
 // has had disposed called on it.
 func (q *Queue) Disposed() bool {
@@ -234,16 +469,26 @@ func (q *Queue) Disposed() bool {
 	return q.disposed
 }
 
-// Dispose will dispose of this queue.  Any subsequent
-// calls to Get or Put will return an error.
+// Dispose will dispose of this queue.  Any waiting getters
+// and subsequent calls to Get or Put will return an error.
 func (q *Queue) Dispose() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
+	// woken getters read disposed without the lock
+	if q.disposed {
+		return
+	}
+
 	q.disposed = true
+	if q.cancelPump != nil {
+		q.cancelPump()
+	}
 	for _, waiter := range q.waiters {
-		waiter.response.Add(1)
-		waiter.wg.Done()
+		if waiter.claim() {
+			waiter.response.Add(1)
+			waiter.signal()
+		}
 	}
 
 	q.items = nil
@@ -281,6 +526,7 @@ func ExecuteInParallel(q *Queue, fn func(interface{})) {
 
 	var wg sync.WaitGroup
 	wg.Add(numCPU)
+	q.unshare()
 	items := q.items
 
 	for i := 0; i < numCPU; i++ {
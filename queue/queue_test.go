@@ -17,9 +17,12 @@ limitations under the License.
 package queue
 
 import (
+	"context"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -292,6 +295,39 @@ func TestTakeUntilOnDisposedQueue(t *testing.T) {
 	assert.IsType(t, DisposedError{}, err)
 }
 
+func TestSnapshot(t *testing.T) {
+	q := New(10)
+	q.Put(`1`, `2`, `3`)
+
+	snapshot := q.Snapshot()
+	result, err := q.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`1`, `2`}, result)
+	q.Put(`4`)
+
+	assert.Equal(t, int64(3), snapshot.Len())
+	result, err = snapshot.Get(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`1`, `2`, `3`}, result)
+
+	result, err = q.Get(2)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`3`, `4`}, result)
+}
+
+func TestSnapshotDisposed(t *testing.T) {
+	q := New(10)
+	q.Put(`1`)
+
+	snapshot := q.Snapshot()
+	q.Dispose()
+
+	assert.False(t, snapshot.Disposed())
+	result, err := snapshot.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`1`}, result)
+}
+
 func TestExecuteInParallel(t *testing.T) {
 	q := New(10)
 	for i := 0; i < 10; i++ {
@@ -385,3 +421,140 @@ func BenchmarkExecuteInParallel(b *testing.B) {
 		ExecuteInParallel(q, fn)
 	}
 }
+
+func TestSizeOf(t *testing.T) {
+	q := New(10)
+	empty := q.SizeOf()
+	assert.True(t, empty >= 10*itemSize)
+
+	for i := 0; i < 100; i++ {
+		q.Put(i)
+	}
+	assert.True(t, q.SizeOf() >= empty+90*itemSize)
+}
+
+func TestGetCtxCancelled(t *testing.T) {
+	q := New(10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var err error
+	go func() {
+		_, err = q.GetCtx(ctx, 1)
+		wg.Done()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	wg.Wait()
+	assert.Equal(t, context.Canceled, err)
+
+	// the cancelled getter must not swallow the next put
+	q.Put(`a`)
+	result, err := q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`}, result)
+
+	_, err = q.GetCtx(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestGetCtxReceivesItems(t *testing.T) {
+	q := New(10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		q.Put(`a`)
+	}()
+
+	result, err := q.GetCtx(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`a`}, result)
+}
+
+func TestGetCtxTimeoutRace(t *testing.T) {
+	q := New(10)
+	var wg sync.WaitGroup
+	received := 0
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+		wg.Add(1)
+		go func(i int) {
+			q.Put(i)
+			wg.Done()
+		}(i)
+
+		result, err := q.GetCtx(ctx, 1)
+		cancel()
+		received += len(result)
+		if err == nil {
+			assert.Len(t, result, 1)
+		}
+	}
+
+	// every item was either returned or is still queued
+	wg.Wait()
+	assert.Equal(t, int64(100), int64(received)+q.Len())
+}
+
+func TestAsChan(t *testing.T) {
+	q := New(10)
+	ch, stop := q.AsChan()
+	defer stop()
+	shared, _ := q.AsChan()
+	assert.Equal(t, ch, shared)
+
+	q.Put(1, 2, 3)
+	for i := 1; i <= 3; i++ {
+		select {
+		case item := <-ch:
+			assert.Equal(t, i, item)
+		case <-time.After(time.Second):
+			t.Errorf(`Timed out waiting for item %d.`, i)
+			return
+		}
+	}
+
+	select {
+	case item := <-ch:
+		t.Errorf(`Received unexpected item %v.`, item)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	q.Dispose()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestAsChanStopKeepsItems(t *testing.T) {
+	before := runtime.NumGoroutine()
+	q := New(10)
+	ch, stop := q.AsChan()
+	q.Put(1, 2)
+	assert.Equal(t, 1, <-ch)
+
+	// the goroutine is waiting to hand over 2, which stays queued
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	_, ok := <-ch
+	assert.False(t, ok)
+	checkGoroutines(t, before)
+	assert.Equal(t, int64(1), q.Len())
+
+	ch, stop = q.AsChan()
+	defer stop()
+	assert.Equal(t, 2, <-ch)
+	assert.Equal(t, int64(0), q.Len())
+}
+
+func TestAsChanDisposedQueue(t *testing.T) {
+	q := New(10)
+	q.Dispose()
+
+	ch, _ := q.AsChan()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import "sync/atomic"
+
+// wsInitialSize is the number of slots a work-stealing deque starts
+// with.  It must be a power of two.
+const wsInitialSize = 32
+
+// wsArray is the circular array behind a work-stealing deque.  Slots
+// are indexed by the deque's ever increasing top and bottom counters
+// modulo the array's size.  Slots are accessed atomically as a thief
+// may read a slot that the owner is overwriting or clearing, in which
+// case the thief's compare-and-swap of top fails and the value is
+// discarded.  A slot that was emptied or never filled holds nil.
+type wsArray struct {
+	mask  int64
+	slots []atomic.Pointer[interface{}]
+}
+
+func (a *wsArray) load(i int64) *interface{} {
+	return a.slots[i&a.mask].Load()
+}
+
+func (a *wsArray) put(i int64, item interface{}) {
+	a.slots[i&a.mask].Store(&item)
+}
+
+// clear empties the slot at i so its item can be garbage collected.
+func (a *wsArray) clear(i int64) {
+	a.slots[i&a.mask].Store(nil)
+}
+
+// grow returns an array of twice the size holding the items in
+// [top, bottom).
+func (a *wsArray) grow(top, bottom int64) *wsArray {
+	grown := newWSArray(2 * int64(len(a.slots)))
+	for i := top; i < bottom; i++ {
+		grown.slots[i&grown.mask].Store(a.slots[i&a.mask].Load())
+	}
+
+	return grown
+}
+
+func newWSArray(size int64) *wsArray {
+	return &wsArray{
+		mask:  size - 1,
+		slots: make([]atomic.Pointer[interface{}], size),
+	}
+}
+
+// WorkStealing is an unbounded Chase-Lev work-stealing deque.  A
+// single goroutine owns the deque and pushes and pops items at the
+// bottom, using it as a stack, while any number of other goroutines
+// take the oldest items from the top with Steal.  The owner only
+// contends with thieves when a single item is left, so its
+// operations are nearly as cheap as those of a slice, and thieves
+// contend with each other through a single compare-and-swap.
+//
+// This is the building block of work-stealing schedulers, where each
+// worker keeps a deque of its own tasks and steals from the others
+// once it runs out.  PushBottom and PopBottom must only ever be
+// called by the owner.
+type WorkStealing struct {
+	_      cacheLinePad
+	top    int64
+	_      cacheLinePad
+	bottom int64
+	_      cacheLinePad
+	array  atomic.Pointer[wsArray]
+}
+
+// PushBottom adds the item to the bottom of the deque, growing it if
+// it is full.  This must only be called by the owner.
+func (ws *WorkStealing) PushBottom(item interface{}) {
+	bottom := atomic.LoadInt64(&ws.bottom)
+	top := atomic.LoadInt64(&ws.top)
+	array := ws.array.Load()
+	if bottom-top > array.mask {
+		array = array.grow(top, bottom)
+		ws.array.Store(array)
+	}
+
+	array.put(bottom, item)
+	atomic.StoreInt64(&ws.bottom, bottom+1)
+}
+
+// PopBottom removes and returns the item most recently pushed.  The
+// returned bool is false if the deque is empty or the last item was
+// taken by a thief at the same time.  This must only be called by
+// the owner.
+func (ws *WorkStealing) PopBottom() (interface{}, bool) {
+	bottom := atomic.LoadInt64(&ws.bottom) - 1
+	array := ws.array.Load()
+	// claim the bottom item before looking at top so that a thief
+	// that hasn't yet taken it will see it is gone
+	atomic.StoreInt64(&ws.bottom, bottom)
+	top := atomic.LoadInt64(&ws.top)
+
+	if top > bottom {
+		atomic.StoreInt64(&ws.bottom, bottom+1)
+		return nil, false
+	}
+
+	// a thief that read top and bottom before bottom was claimed may
+	// already have taken the last item and cleared its slot
+	slot := array.load(bottom)
+	if slot == nil {
+		atomic.StoreInt64(&ws.bottom, bottom+1)
+		return nil, false
+	}
+
+	if top == bottom {
+		// the last item, which a thief may be taking as well
+		ok := atomic.CompareAndSwapInt64(&ws.top, top, top+1)
+		atomic.StoreInt64(&ws.bottom, bottom+1)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	array.clear(bottom)
+	return *slot, true
+}
+
+// Steal removes and returns the oldest item in the deque.  The
+// returned bool is false if the deque is empty.  Steal may be called
+// by any goroutine.
+func (ws *WorkStealing) Steal() (interface{}, bool) {
+	for {
+		// the array is loaded before top so that top is at least
+		// the top it was grown at, and every item from top on was
+		// copied into it
+		array := ws.array.Load()
+		top := atomic.LoadInt64(&ws.top)
+		bottom := atomic.LoadInt64(&ws.bottom)
+		if top >= bottom {
+			return nil, false
+		}
+
+		slot := array.load(top)
+		// a nil slot was taken by the owner or another thief, and
+		// an array grown since it was loaded may be missing items
+		// pushed after the growth, so either is a lost race
+		if slot == nil || ws.array.Load() != array {
+			continue
+		}
+
+		if atomic.CompareAndSwapInt64(&ws.top, top, top+1) {
+			array.slots[top&array.mask].CompareAndSwap(slot, nil)
+			return *slot, true
+		}
+		// another thief or the owner took the item, try the next
+	}
+}
+
+// Len returns the number of items in the deque.  The result is only
+// a snapshot if other goroutines are using the deque.
+func (ws *WorkStealing) Len() uint64 {
+	bottom := atomic.LoadInt64(&ws.bottom)
+	top := atomic.LoadInt64(&ws.top)
+	if bottom < top {
+		return 0
+	}
+
+	return uint64(bottom - top)
+}
+
+// Empty returns a bool indicating if the deque is empty.  The result
+// is only a snapshot if other goroutines are using the deque.
+func (ws *WorkStealing) Empty() bool {
+	return ws.Len() == 0
+}
+
+// NewWorkStealing returns an empty work-stealing deque.  The goroutine
+// that pushes to it becomes its owner.
+func NewWorkStealing() *WorkStealing {
+	ws := &WorkStealing{}
+	ws.array.Store(newWSArray(wsInitialSize))
+	return ws
+}
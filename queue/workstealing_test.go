@@ -0,0 +1,214 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkStealingPopBottom(t *testing.T) {
+	ws := NewWorkStealing()
+	item, ok := ws.PopBottom()
+	assert.Nil(t, item)
+	assert.False(t, ok)
+	assert.True(t, ws.Empty())
+
+	for i := 0; i < 10; i++ {
+		ws.PushBottom(i)
+	}
+	assert.Equal(t, uint64(10), ws.Len())
+
+	for i := 9; i >= 0; i-- {
+		item, ok = ws.PopBottom()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	_, ok = ws.PopBottom()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), ws.Len())
+}
+
+func TestWorkStealingSteal(t *testing.T) {
+	ws := NewWorkStealing()
+	item, ok := ws.Steal()
+	assert.Nil(t, item)
+	assert.False(t, ok)
+
+	for i := 0; i < 10; i++ {
+		ws.PushBottom(i)
+	}
+
+	for i := 0; i < 5; i++ {
+		item, ok = ws.Steal()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+
+	item, ok = ws.PopBottom()
+	assert.True(t, ok)
+	assert.Equal(t, 9, item)
+	assert.Equal(t, uint64(4), ws.Len())
+}
+
+func TestWorkStealingGrow(t *testing.T) {
+	ws := NewWorkStealing()
+	// steal some first so the items wrap around the array as it grows
+	for i := 0; i < 10; i++ {
+		ws.PushBottom(i)
+		ws.Steal()
+	}
+
+	for i := 0; i < 1000; i++ {
+		ws.PushBottom(i)
+	}
+	assert.Equal(t, uint64(1000), ws.Len())
+	assert.Equal(t, int64(1023), ws.array.Load().mask)
+
+	for i := 0; i < 500; i++ {
+		item, ok := ws.Steal()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	for i := 999; i >= 500; i-- {
+		item, ok := ws.PopBottom()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	assert.True(t, ws.Empty())
+}
+
+func TestWorkStealingConcurrent(t *testing.T) {
+	ws := NewWorkStealing()
+	const items, thieves = 100000, 4
+
+	var taken [items]int32
+	var wg sync.WaitGroup
+	var done int32
+	take := func(item interface{}) {
+		atomic.AddInt32(&taken[item.(int)], 1)
+	}
+
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if item, ok := ws.Steal(); ok {
+					take(item)
+				} else if atomic.LoadInt32(&done) == 1 && ws.Empty() {
+					return
+				}
+			}
+		}()
+	}
+
+	// the owner pushes and pops its own work while the thieves steal
+	for i := 0; i < items; i++ {
+		ws.PushBottom(i)
+		if i%3 == 0 {
+			if item, ok := ws.PopBottom(); ok {
+				take(item)
+			}
+		}
+	}
+	for {
+		item, ok := ws.PopBottom()
+		if !ok {
+			break
+		}
+		take(item)
+	}
+	atomic.StoreInt32(&done, 1)
+	wg.Wait()
+
+	for i := range taken {
+		if !assert.Equal(t, int32(1), taken[i]) {
+			return
+		}
+	}
+}
+
+func TestWorkStealingLastItemRace(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	ws := NewWorkStealing()
+	const items, thieves = 50000, 4
+
+	var taken [items]int32
+	var wg sync.WaitGroup
+	var done int32
+	for i := 0; i < thieves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&done) == 0 {
+				if item, ok := ws.Steal(); ok {
+					atomic.AddInt32(&taken[item.(int)], 1)
+				}
+			}
+		}()
+	}
+
+	// every item is the only one in the deque, so the owner races
+	// all the thieves for it
+	for i := 0; i < items; i++ {
+		ws.PushBottom(i)
+		if item, ok := ws.PopBottom(); ok {
+			atomic.AddInt32(&taken[item.(int)], 1)
+		}
+	}
+	for !ws.Empty() {
+		runtime.Gosched()
+	}
+	atomic.StoreInt32(&done, 1)
+	wg.Wait()
+
+	for i := range taken {
+		if !assert.Equal(t, int32(1), taken[i]) {
+			return
+		}
+	}
+}
+
+func BenchmarkWorkStealingPushPop(b *testing.B) {
+	ws := NewWorkStealing()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ws.PushBottom(i)
+		ws.PopBottom()
+	}
+}
+
+func BenchmarkWorkStealingSteal(b *testing.B) {
+	ws := NewWorkStealing()
+	for i := 0; i < b.N; i++ {
+		ws.PushBottom(i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ws.Steal()
+		}
+	})
+}
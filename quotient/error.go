@@ -0,0 +1,38 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quotient
+
+import "fmt"
+
+// FullError is returned when a fingerprint is added to a filter
+// with no free slots that cannot be grown any further.
+type FullError struct{}
+
+func (fe FullError) Error() string {
+	return `Quotient filter is full.`
+}
+
+// MismatchError is returned when merging filters that store
+// fingerprints of different sizes.
+type MismatchError struct {
+	bits, otherBits uint
+}
+
+func (me MismatchError) Error() string {
+	return fmt.Sprintf(`Cannot merge %d bit fingerprints into a filter of %d bit fingerprints.`,
+		me.otherBits, me.bits)
+}
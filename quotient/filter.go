@@ -0,0 +1,468 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package quotient implements a quotient filter, an approximate set
+membership structure in the same family as Bloom and cuckoo filters.
+Every item is hashed to a p bit fingerprint which is split into a q bit
+quotient, the index of the item's canonical slot, and an r bit
+remainder, which is what the slot stores.  Remainders that collide on a
+quotient are kept sorted in a contiguous run and runs are shifted
+right, in quotient order, as needed.  Lookups therefore scan a few
+adjacent slots, which keeps them cache friendly.
+
+Because the full fingerprint of every item can be recovered from the
+table, a quotient filter supports deletion, can be iterated, can be
+merged with another filter of the same fingerprint size and can grow
+in place: when the table passes its maximum load, it is rebuilt with
+one more quotient bit and one fewer remainder bit, which keeps every
+fingerprint, and so the false positive rate, unchanged.
+
+The false positive rate of a filter is roughly 2^-r multiplied by
+its load.  Items are counted, so adding an item twice requires it to
+be deleted twice.  Deleting an item that was never added may remove a
+different item sharing its fingerprint.
+
+This is *NOT* a threadsafe package.
+
+Performance characteristics:
+Space: 2^q slots of (r + 3) bits, stored as one uint64 per slot
+Add: O(1) expected
+Contains: O(1) expected
+Delete: O(1) expected
+Grow: O(2^q)
+*/
+package quotient
+
+import (
+	"hash/fnv"
+	"unsafe"
+)
+
+// maxLoad is the fraction of slots that may be filled before the
+// filter grows.  Clusters lengthen quickly past this point.
+const maxLoad = 0.75
+
+const (
+	filterSize = uint64(unsafe.Sizeof(Filter{}))
+	slotSize   = uint64(unsafe.Sizeof(slot(0)))
+)
+
+// Filter is a quotient filter.
+type Filter struct {
+	slots        []slot
+	qbits, rbits uint
+	mask, rmask  uint64
+	len          uint64
+}
+
+func (f *Filter) incr(i uint64) uint64 {
+	return (i + 1) & f.mask
+}
+
+func (f *Filter) decr(i uint64) uint64 {
+	return (i - 1) & f.mask
+}
+
+// split returns the quotient and remainder of the provided fingerprint.
+func (f *Filter) split(fingerprint uint64) (uint64, uint64) {
+	return (fingerprint >> f.rbits) & f.mask, fingerprint & f.rmask
+}
+
+// findRunStart returns the index of the slot holding the first
+// remainder for the provided quotient, or where that remainder
+// would go if the quotient has no run.
+func (f *Filter) findRunStart(fq uint64) uint64 {
+	// walk back to the start of the cluster
+	b := fq
+	for f.slots[b].shifted() {
+		b = f.decr(b)
+	}
+
+	// then forward, run by run, until we reach this quotient's run
+	s := b
+	for b != fq {
+		for {
+			s = f.incr(s)
+			if !f.slots[s].continuation() {
+				break
+			}
+		}
+
+		for {
+			b = f.incr(b)
+			if f.slots[b].occupied() {
+				break
+			}
+		}
+	}
+
+	return s
+}
+
+// insertInto stores the provided remainder at s, shifting everything
+// from s to the end of the cluster one slot to the right.  Occupied
+// bits stay with their slots.
+func (f *Filter) insertInto(s uint64, entry slot) {
+	curr := entry
+	for {
+		prev := f.slots[s]
+		f.slots[s] = curr&^occupiedBit | prev&occupiedBit
+		if prev.empty() {
+			return
+		}
+
+		curr = prev&^occupiedBit | shiftedBit
+		s = f.incr(s)
+	}
+}
+
+func (f *Filter) insert(fq, fr uint64) {
+	entry := slot(fr << metaBits)
+	if f.slots[fq].empty() {
+		f.slots[fq] = entry | occupiedBit
+		f.len++
+		return
+	}
+
+	wasOccupied := f.slots[fq].occupied()
+	f.slots[fq] |= occupiedBit
+
+	start := f.findRunStart(fq)
+	s := start
+	if wasOccupied {
+		// keep the run sorted by remainder
+		for f.slots[s].remainder() <= fr {
+			s = f.incr(s)
+			if !f.slots[s].continuation() {
+				break
+			}
+		}
+
+		if s == start {
+			// the old start of the run will be shifted after this
+			f.slots[start] |= continuationBit
+		} else {
+			entry |= continuationBit
+		}
+	}
+
+	if s != fq {
+		entry |= shiftedBit
+	}
+
+	f.insertInto(s, entry)
+	f.len++
+}
+
+// find returns the index of the slot holding the provided remainder
+// and the start of its run.
+func (f *Filter) find(fq, fr uint64) (uint64, uint64, bool) {
+	if !f.slots[fq].occupied() {
+		return 0, 0, false
+	}
+
+	start := f.findRunStart(fq)
+	s := start
+	for {
+		rem := f.slots[s].remainder()
+		if rem == fr {
+			return s, start, true
+		}
+		if rem > fr {
+			return 0, 0, false
+		}
+
+		s = f.incr(s)
+		if !f.slots[s].continuation() {
+			return 0, 0, false
+		}
+	}
+}
+
+// deleteEntry removes the remainder at s, shifting the rest of the
+// cluster one slot to the left.  Remainders that land back in their
+// canonical slot are no longer shifted.
+func (f *Filter) deleteEntry(s, quot uint64) {
+	orig := s
+	curr := f.slots[s]
+	sp := f.incr(s)
+	for {
+		next := f.slots[sp]
+		if next.empty() || next.clusterStart() || sp == orig {
+			f.slots[s] = 0
+			return
+		}
+
+		updated := next
+		if next.runStart() {
+			// find the quotient this run belongs to
+			for {
+				quot = f.incr(quot)
+				if f.slots[quot].occupied() {
+					break
+				}
+			}
+
+			if quot == s {
+				updated &^= shiftedBit
+			}
+		}
+
+		updated = updated&^occupiedBit | curr&occupiedBit
+		f.slots[s] = updated
+		s, sp, curr = sp, f.incr(sp), next
+	}
+}
+
+func (f *Filter) delete(fq, fr uint64) bool {
+	s, start, ok := f.find(fq, fr)
+	if !ok {
+		return false
+	}
+
+	runStart := s == start
+	runRemains := f.slots[f.incr(s)].continuation()
+	if runStart && !runRemains {
+		f.slots[fq] &^= occupiedBit
+	}
+
+	f.deleteEntry(s, fq)
+
+	if runStart && runRemains {
+		// the next remainder in the run is now its start
+		f.slots[s] &^= continuationBit
+		if s == fq {
+			f.slots[s] &^= shiftedBit
+		}
+	}
+
+	f.len--
+	return true
+}
+
+// walk calls fn with every stored fingerprint in slot order, starting
+// from the first cluster in the table, until fn returns false.  The
+// last cluster may wrap around the end of the table, in which case its
+// quotients wrap too and fn is told the fingerprint has wrapped.
+func (f *Filter) walk(fn func(fingerprint uint64, wrapped bool) bool) {
+	if f.len == 0 {
+		return
+	}
+
+	// slots before the first cluster start can only hold the tail
+	// of a cluster that wrapped around the end of the table
+	first := uint64(0)
+	for !f.slots[first].clusterStart() {
+		first++
+	}
+
+	quot := first
+	wrapped := false
+	i := first
+	for n := uint64(0); n < uint64(len(f.slots)); n++ {
+		s := f.slots[i]
+		if !s.empty() {
+			if s.clusterStart() {
+				quot = i
+			} else if !s.continuation() {
+				for {
+					quot = f.incr(quot)
+					if quot == 0 {
+						wrapped = true
+					}
+					if f.slots[quot].occupied() {
+						break
+					}
+				}
+			}
+
+			if !fn(quot<<f.rbits|s.remainder(), wrapped) {
+				return
+			}
+		}
+
+		i = f.incr(i)
+	}
+}
+
+// each calls fn with every stored fingerprint in ascending order
+// until fn returns false.
+func (f *Filter) each(fn func(fingerprint uint64) bool) {
+	// wrapped fingerprints have the smallest quotients so they are
+	// visited first, which takes a second walk of the table
+	stopped := false
+	f.walk(func(fingerprint uint64, wrapped bool) bool {
+		if wrapped && !fn(fingerprint) {
+			stopped = true
+			return false
+		}
+		return true
+	})
+	if stopped {
+		return
+	}
+
+	f.walk(func(fingerprint uint64, wrapped bool) bool {
+		return !wrapped && fn(fingerprint)
+	})
+}
+
+// grow rebuilds the filter with twice as many slots, moving one bit
+// of every fingerprint from its remainder to its quotient.
+func (f *Filter) grow() {
+	other := New(f.qbits+1, f.rbits-1)
+	f.each(func(fingerprint uint64) bool {
+		other.insert(other.split(fingerprint))
+		return true
+	})
+
+	*f = *other
+}
+
+func (f *Filter) add(fingerprint uint64) error {
+	if float64(f.len+1) > maxLoad*float64(len(f.slots)) && f.rbits > 1 {
+		f.grow()
+	}
+
+	if f.len == uint64(len(f.slots)) {
+		return FullError{}
+	}
+
+	f.insert(f.split(fingerprint))
+	return nil
+}
+
+// fingerprint hashes the provided data to a fingerprint of the
+// size stored by this filter.
+func (f *Filter) fingerprint(data []byte) uint64 {
+	hash := fnv.New64a()
+	hash.Write(data)
+	h := hash.Sum64()
+
+	// fnv leaves the high bits poorly mixed, so finish with the
+	// murmur3 finalizer before truncating
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+
+	return h & f.fingerprintMask()
+}
+
+func (f *Filter) fingerprintMask() uint64 {
+	bits := f.qbits + f.rbits
+	if bits == 64 {
+		return ^uint64(0)
+	}
+
+	return 1<<bits - 1
+}
+
+// Add adds the provided item to the filter, growing the filter if
+// it is too full.  An error is returned if the filter is full and
+// cannot grow because its remainders are down to a single bit.
+func (f *Filter) Add(data []byte) error {
+	return f.add(f.fingerprint(data))
+}
+
+// Contains returns a bool indicating if the provided item may have
+// been added to this filter.  False positives are possible, false
+// negatives are not.
+func (f *Filter) Contains(data []byte) bool {
+	fq, fr := f.split(f.fingerprint(data))
+	_, _, ok := f.find(fq, fr)
+	return ok
+}
+
+// Delete removes one occurrence of the provided item from the filter
+// and returns a bool indicating if it was found.
+func (f *Filter) Delete(data []byte) bool {
+	return f.delete(f.split(f.fingerprint(data)))
+}
+
+// Each calls fn with every fingerprint stored in the filter in
+// ascending order, stopping early if fn returns false.  Fingerprints
+// added more than once are visited once per addition.  The filter
+// must not be modified during iteration.
+func (f *Filter) Each(fn func(fingerprint uint64) bool) {
+	f.each(fn)
+}
+
+// Merge adds every fingerprint stored in other to this filter.  Both
+// filters must store fingerprints of the same size, though their
+// number of slots may differ.
+func (f *Filter) Merge(other *Filter) error {
+	if other.FingerprintBits() != f.FingerprintBits() {
+		return MismatchError{bits: f.FingerprintBits(), otherBits: other.FingerprintBits()}
+	}
+
+	if other == f {
+		cp := *f
+		cp.slots = make([]slot, len(f.slots))
+		copy(cp.slots, f.slots)
+		other = &cp
+	}
+
+	var err error
+	other.each(func(fingerprint uint64) bool {
+		err = f.add(fingerprint)
+		return err == nil
+	})
+
+	return err
+}
+
+// Len returns the number of items in the filter.
+func (f *Filter) Len() uint64 {
+	return f.len
+}
+
+// Cap returns the number of slots in the filter.
+func (f *Filter) Cap() uint64 {
+	return uint64(len(f.slots))
+}
+
+// FingerprintBits returns the size of the fingerprints stored by
+// this filter, which is fixed for the life of the filter.
+func (f *Filter) FingerprintBits() uint {
+	return f.qbits + f.rbits
+}
+
+// SizeOf returns an estimate of the number of bytes used by this
+// filter.
+func (f *Filter) SizeOf() uint64 {
+	return filterSize + uint64(cap(f.slots))*slotSize
+}
+
+// New returns a filter with 2^q slots storing r bit remainders.
+// The filter grows as needed, so q need only be large enough for the
+// expected number of items while r determines the false positive
+// rate.  q and r must both be at least 1 and q + r at most 64.
+func New(q, r uint) *Filter {
+	if q < 1 || r < 1 || q+r > 64 || r > 64-metaBits {
+		panic(`Invalid quotient or remainder size provided.`)
+	}
+
+	return &Filter{
+		slots: make([]slot, 1<<q),
+		qbits: q,
+		rbits: r,
+		mask:  1<<q - 1,
+		rmask: 1<<r - 1,
+	}
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quotient
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fingerprints(f *Filter) []uint64 {
+	result := []uint64{}
+	f.Each(func(fingerprint uint64) bool {
+		result = append(result, fingerprint)
+		return true
+	})
+
+	return result
+}
+
+func sorted(fps []uint64) []uint64 {
+	result := append([]uint64{}, fps...)
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func TestAddContains(t *testing.T) {
+	f := New(8, 16)
+
+	assert.False(t, f.Contains([]byte(`a`)))
+	assert.Nil(t, f.Add([]byte(`a`)))
+	assert.Nil(t, f.Add([]byte(`b`)))
+
+	assert.True(t, f.Contains([]byte(`a`)))
+	assert.True(t, f.Contains([]byte(`b`)))
+	assert.False(t, f.Contains([]byte(`c`)))
+	assert.Equal(t, uint64(2), f.Len())
+	assert.Equal(t, uint(24), f.FingerprintBits())
+}
+
+func TestDelete(t *testing.T) {
+	f := New(8, 16)
+	f.Add([]byte(`a`))
+	f.Add([]byte(`a`))
+	f.Add([]byte(`b`))
+
+	assert.True(t, f.Delete([]byte(`a`)))
+	assert.True(t, f.Contains([]byte(`a`)))
+	assert.True(t, f.Delete([]byte(`a`)))
+	assert.False(t, f.Contains([]byte(`a`)))
+	assert.False(t, f.Delete([]byte(`a`)))
+	assert.True(t, f.Contains([]byte(`b`)))
+	assert.Equal(t, uint64(1), f.Len())
+}
+
+// TestCollisions uses a tiny table so runs and clusters are long
+// and wrap around the end of the table.
+func TestCollisions(t *testing.T) {
+	for _, q := range []uint{1, 2, 3, 4} {
+		f := New(q, 6)
+		size := uint64(1) << q
+		expected := []uint64{}
+		for i := uint64(0); i < size; i++ {
+			fp := uint64(rand.Int63n(int64(size) << 6))
+			assert.Nil(t, f.add(fp))
+			expected = append(expected, fp)
+		}
+		// table is full but the filter only grows past maxLoad
+		// when a fingerprint is added
+		assert.Equal(t, sorted(expected), fingerprints(f))
+
+		for _, i := range rand.Perm(len(expected)) {
+			assert.True(t, f.delete(f.split(expected[i])))
+		}
+		assert.Equal(t, uint64(0), f.Len())
+		for _, s := range f.slots {
+			assert.Equal(t, slot(0), s)
+		}
+	}
+}
+
+func TestRandomOperations(t *testing.T) {
+	for _, q := range []uint{2, 4, 6} {
+		f := New(q, 10)
+		expected := map[uint64]int{}
+		total := 0
+		for i := 0; i < 5000; i++ {
+			fp := uint64(rand.Int63n(1 << 12))
+			if expected[fp] > 0 && rand.Intn(2) == 0 {
+				fq, fr := f.split(fp)
+				if !assert.True(t, f.delete(fq, fr)) {
+					return
+				}
+				expected[fp]--
+				total--
+				continue
+			}
+
+			// keep the table from growing so the quotient stays small
+			if total < int(f.Cap())-1 {
+				f.insert(f.split(fp))
+				expected[fp]++
+				total++
+			}
+		}
+
+		fps := []uint64{}
+		for fp, count := range expected {
+			for i := 0; i < count; i++ {
+				fps = append(fps, fp)
+			}
+		}
+		if !assert.Equal(t, sorted(fps), fingerprints(f)) {
+			return
+		}
+		assert.Equal(t, uint64(total), f.Len())
+	}
+}
+
+func TestGrow(t *testing.T) {
+	f := New(2, 20)
+	bits := f.FingerprintBits()
+	for i := 0; i < 1000; i++ {
+		assert.Nil(t, f.Add([]byte(strconv.Itoa(i))))
+	}
+
+	assert.Equal(t, uint64(1000), f.Len())
+	assert.Equal(t, uint64(2048), f.Cap())
+	assert.Equal(t, bits, f.FingerprintBits())
+	for i := 0; i < 1000; i++ {
+		assert.True(t, f.Contains([]byte(strconv.Itoa(i))))
+	}
+}
+
+func TestFull(t *testing.T) {
+	f := New(2, 1)
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, f.add(uint64(i)))
+	}
+
+	assert.IsType(t, FullError{}, f.add(0))
+	assert.Equal(t, uint64(4), f.Len())
+}
+
+func TestMerge(t *testing.T) {
+	f := New(4, 12)
+	other := New(6, 10)
+	for i := 0; i < 20; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+		other.Add([]byte(strconv.Itoa(i + 100)))
+	}
+
+	assert.Nil(t, f.Merge(other))
+	assert.Equal(t, uint64(40), f.Len())
+	for i := 0; i < 20; i++ {
+		assert.True(t, f.Contains([]byte(strconv.Itoa(i))))
+		assert.True(t, f.Contains([]byte(strconv.Itoa(i+100))))
+	}
+
+	assert.Nil(t, f.Merge(f))
+	assert.Equal(t, uint64(80), f.Len())
+
+	assert.IsType(t, MismatchError{}, f.Merge(New(4, 4)))
+}
+
+func TestEachStops(t *testing.T) {
+	f := New(4, 8)
+	for i := 0; i < 10; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+
+	count := 0
+	f.Each(func(uint64) bool {
+		count++
+		return count < 3
+	})
+	assert.Equal(t, 3, count)
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	f := New(10, 10)
+	for i := 0; i < 700; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if f.Contains([]byte(`miss` + strconv.Itoa(i))) {
+			falsePositives++
+		}
+	}
+
+	// expected rate is about 700/1024 * 2^-10
+	assert.True(t, falsePositives < 50, `%d false positives`, falsePositives)
+}
+
+func TestSizeOf(t *testing.T) {
+	f := New(4, 8)
+	assert.Equal(t, filterSize+16*slotSize, f.SizeOf())
+}
+
+func BenchmarkAdd(b *testing.B) {
+	f := New(20, 12)
+	keys := make([][]byte, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, []byte(strconv.Itoa(i)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.Add(keys[i])
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	numItems := 100000
+	f := New(18, 12)
+	for i := 0; i < numItems; i++ {
+		f.Add([]byte(strconv.Itoa(i)))
+	}
+	key := []byte(`5000`)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.Contains(key)
+	}
+}
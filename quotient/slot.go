@@ -0,0 +1,65 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quotient
+
+const (
+	occupiedBit slot = 1 << iota
+	continuationBit
+	shiftedBit
+
+	metaBits = 3
+)
+
+// slot holds a remainder along with three bits of metadata.  The
+// occupied bit describes the slot itself: some stored fingerprint has
+// this slot's index as its quotient.  The continuation and shifted
+// bits describe the remainder stored in the slot: whether it follows
+// another remainder of the same quotient and whether it is stored
+// somewhere other than its canonical slot.
+type slot uint64
+
+func (s slot) occupied() bool {
+	return s&occupiedBit != 0
+}
+
+func (s slot) continuation() bool {
+	return s&continuationBit != 0
+}
+
+func (s slot) shifted() bool {
+	return s&shiftedBit != 0
+}
+
+func (s slot) empty() bool {
+	return s&(occupiedBit|continuationBit|shiftedBit) == 0
+}
+
+// clusterStart returns true if this slot holds the first remainder
+// of a cluster, which is always stored in its canonical slot.
+func (s slot) clusterStart() bool {
+	return s.occupied() && !s.continuation() && !s.shifted()
+}
+
+// runStart returns true if this slot holds the first remainder for
+// its quotient.
+func (s slot) runStart() bool {
+	return !s.continuation() && (s.occupied() || s.shifted())
+}
+
+func (s slot) remainder() uint64 {
+	return uint64(s >> metaBits)
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package rangetree
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/Workiva/go-datastructures/common"
+)
 
 // NoEntriesError is returned from an operation that requires
 // existing entries when none are found.
@@ -27,14 +31,22 @@ func (nee NoEntriesError) Error() string {
 }
 
 // OutOfDimensionError is returned when a requested operation
-// doesn't meet dimensional requirements.
+// doesn't meet dimensional requirements.  It matches
+// common.ErrDimensionMismatch with errors.Is.
 type OutOfDimensionError struct {
-	provided, max uint64
+	// Provided is the dimension asked for and Dimensions the number
+	// of dimensions the tree has.
+	Provided, Dimensions uint64
 }
 
 func (oode OutOfDimensionError) Error() string {
-	return fmt.Sprintf(`Provided dimension: %d is 
-		greater than max dimension: %d`,
-		oode.provided, oode.max,
+	return fmt.Sprintf(`Provided dimension: %d is out of range for a tree of %d dimensions.`,
+		oode.Provided, oode.Dimensions,
 	)
 }
+
+// Is reports whether target is common.ErrDimensionMismatch so that
+// errors.Is recognizes the error the same way for every structure.
+func (oode OutOfDimensionError) Is(target error) bool {
+	return target == common.ErrDimensionMismatch
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+import (
+	"iter"
+	"math"
+)
+
+// A float rangetree is an ordered tree holding each float64 coordinate
+// as an int64 key.  The bits of a float already sort like its value
+// for positive numbers; flipping all but the sign bit of negative ones
+// makes them sort in reverse, below every positive number.  Keys hold
+// every bit of the value so no precision is lost, and because keys
+// sort exactly like values, ranges and nearest neighbors are found in
+// key space with distances measured in value space.  NaN is not
+// supported.
+
+// FloatEntries is a typed list of FloatEntry.
+type FloatEntries []FloatEntry
+
+// floatKey returns the key holding the provided value.  Negative zero
+// is held as zero since they're equal.
+func floatKey(value float64) int64 {
+	if value == 0 {
+		value = 0
+	}
+
+	key := int64(math.Float64bits(value))
+	if key < 0 {
+		key ^= math.MaxInt64
+	}
+
+	return key
+}
+
+// floatValue returns the value held by the provided key.
+func floatValue(key int64) float64 {
+	if key < 0 {
+		key ^= math.MaxInt64
+	}
+
+	return math.Float64frombits(uint64(key))
+}
+
+func floatSquare(a, b int64) float64 {
+	d := floatValue(a) - floatValue(b)
+	return d * d
+}
+
+// keyedEntry adapts a FloatEntry to an Entry.
+type keyedEntry struct {
+	entry FloatEntry
+}
+
+func (ke keyedEntry) ValueAtDimension(dimension uint64) int64 {
+	return floatKey(ke.entry.ValueAtDimension(dimension))
+}
+
+// keyedInterval adapts a FloatInterval to an Interval.
+type keyedInterval struct {
+	interval FloatInterval
+}
+
+func (ki keyedInterval) LowAtDimension(dimension uint64) int64 {
+	return floatKey(ki.interval.LowAtDimension(dimension))
+}
+
+func (ki keyedInterval) HighAtDimension(dimension uint64) int64 {
+	return floatKey(ki.interval.HighAtDimension(dimension))
+}
+
+func unkey(entry Entry) FloatEntry {
+	if entry == nil {
+		return nil
+	}
+
+	return entry.(keyedEntry).entry
+}
+
+func unkeyAll(entries Entries) FloatEntries {
+	result := make(FloatEntries, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, unkey(entry))
+	}
+
+	return result
+}
+
+type floatIterator struct {
+	iter Iterator
+}
+
+func (fi floatIterator) Next() bool {
+	return fi.iter.Next()
+}
+
+func (fi floatIterator) Value() FloatEntry {
+	return unkey(fi.iter.Value())
+}
+
+type floatTree struct {
+	tree *orderedTree
+}
+
+// Add will add the provided entries to the tree.  This method returns
+// a list of entries that were overwritten in the order in which
+// entries were received.  If an entry doesn't overwrite anything, a
+// nil will be returned for it in the returned slice.
+func (ft *floatTree) Add(entries ...FloatEntry) FloatEntries {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	overwrittens := make(FloatEntries, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		var overwritten FloatEntry
+		if n := ft.tree.add(keyedEntry{entry}); n != nil {
+			overwritten = unkey(n.entry)
+		}
+		overwrittens = append(overwrittens, overwritten)
+	}
+
+	return overwrittens
+}
+
+// Len returns the number of items in the tree.
+func (ft *floatTree) Len() uint64 {
+	return ft.tree.Len()
+}
+
+// Delete will remove the entries from the tree.
+func (ft *floatTree) Delete(entries ...FloatEntry) {
+	for _, entry := range entries {
+		ft.tree.delete(keyedEntry{entry})
+	}
+}
+
+// Query will return an ordered list of results in the given
+// interval.
+func (ft *floatTree) Query(interval FloatInterval) FloatEntries {
+	entries := FloatEntries{}
+	ft.Apply(interval, func(entry FloatEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+
+	return entries
+}
+
+// Apply will call (in order) the provided function to every
+// entry that falls within the provided interval.
+func (ft *floatTree) Apply(interval FloatInterval, fn func(FloatEntry) bool) {
+	ft.tree.Apply(keyedInterval{interval}, func(entry Entry) bool {
+		return fn(unkey(entry))
+	})
+}
+
+// All returns a sequence of every entry in the tree, in order, for
+// use with range.
+func (ft *floatTree) All() iter.Seq[FloatEntry] {
+	return func(yield func(FloatEntry) bool) {
+		ft.Each(yield)
+	}
+}
+
+// Each will call (in order) the provided function with every
+// entry in the tree until false is returned.
+func (ft *floatTree) Each(fn func(FloatEntry) bool) {
+	ft.tree.Each(func(entry Entry) bool {
+		return fn(unkey(entry))
+	})
+}
+
+// QueryIter returns an iterator over the entries in the given
+// interval, in order.
+func (ft *floatTree) QueryIter(interval FloatInterval) FloatIterator {
+	return floatIterator{iter: ft.tree.QueryIter(keyedInterval{interval})}
+}
+
+// KNearest returns up to k entries nearest to the provided point,
+// nearest first.
+func (ft *floatTree) KNearest(point FloatEntry, k int) FloatEntries {
+	return unkeyAll(ft.tree.kNearest(keyedEntry{point}, k, floatSquare))
+}
+
+// NewFloat is the constructor to create a new rangetree with float64
+// coordinates in the provided number of dimensions.
+func NewFloat(dimensions uint64) FloatRangeTree {
+	return &floatTree{tree: newOrderedTree(dimensions)}
+}
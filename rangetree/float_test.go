@@ -0,0 +1,147 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFloatEntry struct {
+	values []float64
+}
+
+func (mfe *mockFloatEntry) ValueAtDimension(dimension uint64) float64 {
+	return mfe.values[dimension-1]
+}
+
+func constructMockFloatEntry(values ...float64) *mockFloatEntry {
+	return &mockFloatEntry{values: values}
+}
+
+type mockFloatInterval struct {
+	lows, highs []float64
+}
+
+func (mfi *mockFloatInterval) LowAtDimension(dimension uint64) float64 {
+	return mfi.lows[dimension-1]
+}
+
+func (mfi *mockFloatInterval) HighAtDimension(dimension uint64) float64 {
+	return mfi.highs[dimension-1]
+}
+
+func TestFloatKey(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	values := []float64{
+		0, math.Copysign(0, -1), 1, -1, math.SmallestNonzeroFloat64,
+		-math.SmallestNonzeroFloat64, math.MaxFloat64, -math.MaxFloat64,
+		math.Inf(1), math.Inf(-1), 0.1, -0.1,
+	}
+	for i := 0; i < 1000; i++ {
+		values = append(values, r.NormFloat64()*math.Pow(10, float64(r.Intn(40)-20)))
+	}
+
+	for _, value := range values {
+		assert.Equal(t, value, floatValue(floatKey(value)))
+	}
+	assert.Equal(t, floatKey(0), floatKey(math.Copysign(0, -1)))
+
+	sort.Float64s(values)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] == values[i] {
+			assert.Equal(t, floatKey(values[i-1]), floatKey(values[i]))
+		} else {
+			assert.True(t, floatKey(values[i-1]) < floatKey(values[i]), `%v, %v`, values[i-1], values[i])
+		}
+	}
+}
+
+func TestFloatAddQuery(t *testing.T) {
+	tree := NewFloat(2)
+	london := constructMockFloatEntry(51.5072, -0.1276)
+	paris := constructMockFloatEntry(48.8566, 2.3522)
+	sydney := constructMockFloatEntry(-33.8688, 151.2093)
+	nearLondon := constructMockFloatEntry(51.5072, -0.12759)
+
+	assert.Equal(t, FloatEntries{nil, nil, nil, nil}, tree.Add(london, paris, sydney, nearLondon))
+	assert.Equal(t, uint64(4), tree.Len())
+
+	europe := &mockFloatInterval{lows: []float64{35, -10}, highs: []float64{70, 40}}
+	assert.Equal(t, FloatEntries{paris, london, nearLondon}, tree.Query(europe))
+
+	result := FloatEntries{}
+	for iter := tree.QueryIter(europe); iter.Next(); {
+		result = append(result, iter.Value())
+	}
+	assert.Equal(t, FloatEntries{paris, london, nearLondon}, result)
+
+	world := &mockFloatInterval{lows: []float64{-90, -180}, highs: []float64{90, 180}}
+	result = FloatEntries{}
+	tree.Each(func(entry FloatEntry) bool {
+		result = append(result, entry)
+		return true
+	})
+	assert.Equal(t, tree.Query(world), result)
+
+	result = FloatEntries{}
+	for entry := range tree.All() {
+		result = append(result, entry)
+	}
+	assert.Equal(t, tree.Query(world), result)
+
+	replacement := constructMockFloatEntry(51.5072, -0.1276)
+	assert.Equal(t, FloatEntries{london}, tree.Add(replacement))
+	assert.Nil(t, tree.Add())
+
+	tree.Delete(replacement, paris)
+	assert.Equal(t, uint64(2), tree.Len())
+	assert.Equal(t, FloatEntries{sydney, nearLondon}, tree.Query(world))
+}
+
+func TestFloatKNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	tree := NewFloat(2)
+	entries := make(FloatEntries, 0, 500)
+	for i := 0; i < 500; i++ {
+		entry := constructMockFloatEntry(r.Float64()*180-90, r.Float64()*360-180)
+		tree.Add(entry)
+		entries = append(entries, entry)
+	}
+
+	distance := func(a, b FloatEntry) float64 {
+		d1 := a.ValueAtDimension(1) - b.ValueAtDimension(1)
+		d2 := a.ValueAtDimension(2) - b.ValueAtDimension(2)
+		return d1*d1 + d2*d2
+	}
+
+	for i := 0; i < 20; i++ {
+		point := constructMockFloatEntry(r.Float64()*180-90, r.Float64()*360-180)
+		sort.Slice(entries, func(i, j int) bool {
+			return distance(point, entries[i]) < distance(point, entries[j])
+		})
+
+		for _, k := range []int{1, 5, 50} {
+			assert.Equal(t, entries[:k], tree.KNearest(point, k))
+		}
+	}
+
+	assert.Equal(t, FloatEntries{}, tree.KNearest(constructMockFloatEntry(0, 0), 0))
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package rangetree
 
-import "github.com/Workiva/go-datastructures/slice"
+import (
+	"iter"
+
+	"github.com/Workiva/go-datastructures/slice"
+)
 
 type immutableRangeTree struct {
 	number     uint64
@@ -119,6 +123,20 @@ func (irt *immutableRangeTree) InsertAtDimension(dimension uint64,
 	return tree, modified, deleted
 }
 
+// InsertAtDimensionE is InsertAtDimension except that it returns an
+// OutOfDimensionError, along with this tree, if the tree doesn't have
+// the provided dimension.  Dimensions are numbered from 1.
+func (irt *immutableRangeTree) InsertAtDimensionE(dimension uint64,
+	index, number int64) (*immutableRangeTree, Entries, Entries, error) {
+
+	if dimension < 1 || dimension > irt.dimensions {
+		return irt, nil, nil, OutOfDimensionError{Provided: dimension, Dimensions: irt.dimensions}
+	}
+
+	tree, modified, deleted := irt.InsertAtDimension(dimension, index, number)
+	return tree, modified, deleted, nil
+}
+
 type immutableNodeBundle struct {
 	list         *orderedNodes
 	index        int
@@ -234,6 +252,20 @@ func (irt *immutableRangeTree) Query(interval Interval) Entries {
 	return entries
 }
 
+// Each will call (in order) the provided function with every
+// entry in the tree until false is returned.
+func (irt *immutableRangeTree) Each(fn func(Entry) bool) {
+	irt.top.each(fn)
+}
+
+// All returns a sequence of every entry in the tree, in order, for
+// use with range.
+func (irt *immutableRangeTree) All() iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		irt.top.each(yield)
+	}
+}
+
 // Len returns the number of items in this tree.
 func (irt *immutableRangeTree) Len() uint64 {
 	return irt.number
@@ -514,6 +514,37 @@ func TestImmutableInsertInvalidNumber(t *testing.T) {
 	assert.Equal(t, tree, tree1)
 }
 
+func TestImmutableEach(t *testing.T) {
+	tree, entries := constructMultiDimensionalImmutableTree(10)
+
+	result := make(Entries, 0, len(entries))
+	tree.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, entries, result)
+
+	result = result[:0]
+	tree.Each(func(e Entry) bool {
+		result = append(result, e)
+		return len(result) < 5
+	})
+	assert.Equal(t, entries[:5], result)
+
+	fn := func(Entry) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { tree.Each(fn) }))
+}
+
+func TestImmutableAll(t *testing.T) {
+	tree, entries := constructMultiDimensionalImmutableTree(10)
+
+	result := make(Entries, 0, len(entries))
+	for e := range tree.All() {
+		result = append(result, e)
+	}
+	assert.Equal(t, entries, result)
+}
+
 func BenchmarkImmutableInsertFirstDimension(b *testing.B) {
 	numItems := int64(100000)
 
@@ -18,7 +18,9 @@ limitations under the License.
 Package rangetree is designed to store n-dimensional data in an easy-to-query
 way.  Given this package's primary use as representing cartesian data, this
 information is represented by int64s at n-dimensions.  This implementation
-is not actually a tree but a sparse n-dimensional list.  This package also
+is not actually a tree but a sparse n-dimensional list.  A variant created
+with NewFloat holds float64 coordinates, such as latitudes and longitudes,
+without any loss of precision.  This package also
 includes two implementations of this sparse list, one mutable (and not threadsafe)
 and another that is immutable copy-on-write which is threadsafe.  The mutable
 version is obviously faster but will likely have write contention for any
@@ -29,6 +31,8 @@ TODO: unify both implementations with the same interface.
 
 package rangetree
 
+import "iter"
+
 // Entry defines items that can be added to the rangetree.
 type Entry interface {
 	// ValueAtDimension returns the value of this entry
@@ -46,6 +50,18 @@ type Interval interface {
 	HighAtDimension(dimension uint64) int64
 }
 
+// Iterator yields the results of a query one at a time so they
+// needn't all be collected first.  Altering the tree invalidates any
+// iterator over it.
+type Iterator interface {
+	// Next moves the iterator to the next entry and returns a bool
+	// indicating if there is one.
+	Next() bool
+	// Value returns the entry at the iterator's current position, or
+	// nil if the iterator is exhausted or has never been nexted.
+	Value() Entry
+}
+
 // RangeTree describes the methods available to the rangetree.
 type RangeTree interface {
 	// Add will add the provided entries to the tree.  Any entries that
@@ -65,10 +81,94 @@ type RangeTree interface {
 	// cancel iteration.  Altering the entry in such a way that its location
 	// changes will result in undefined behavior.
 	Apply(interval Interval, fn func(Entry) bool)
+	// Each will call the provided function with every entry in the
+	// tree, in order, until false is returned.  Unlike Query, no
+	// intermediate list of entries is allocated.
+	Each(fn func(Entry) bool)
+	// All returns a sequence of every entry in the tree, in order,
+	// for use with range.
+	All() iter.Seq[Entry]
+	// QueryIter returns an iterator over the entries that fall within
+	// the provided interval, in order.  Entries are found as the
+	// iterator advances, so stopping early skips the rest of the work.
+	QueryIter(interval Interval) Iterator
+	// KNearest returns up to k entries nearest to the provided point
+	// by euclidean distance, nearest first.  Entries at the same
+	// distance are ordered by their values in each dimension in turn.
+	KNearest(point Entry, k int) Entries
 	// InsertAtDimension will increment items at and above the given index
 	// by the number provided.  Provide a negative number to to decrement.
 	// Returned are two lists.  The first list is a list of entries that
 	// were moved.  The second is a list entries that were deleted.  These
 	// lists are exclusive.
 	InsertAtDimension(dimension uint64, index, number int64) (Entries, Entries)
+	// InsertAtDimensionE is InsertAtDimension except that it returns
+	// an OutOfDimensionError, rather than doing nothing, if the tree
+	// doesn't have the provided dimension.
+	InsertAtDimensionE(dimension uint64, index, number int64) (Entries, Entries, error)
+}
+
+// FloatEntry defines items that can be added to a float rangetree.
+type FloatEntry interface {
+	// ValueAtDimension returns the value of this entry
+	// at the specified dimension.
+	ValueAtDimension(dimension uint64) float64
+}
+
+// FloatInterval describes the methods required to query a float
+// rangetree.
+type FloatInterval interface {
+	// LowAtDimension returns a float representing the lower bound
+	// at the requested dimension.
+	LowAtDimension(dimension uint64) float64
+	// HighAtDimension returns a float representing the higher bound
+	// at the request dimension.
+	HighAtDimension(dimension uint64) float64
+}
+
+// FloatIterator yields the results of a query of a float rangetree
+// one at a time.  Altering the tree invalidates any iterator over it.
+type FloatIterator interface {
+	// Next moves the iterator to the next entry and returns a bool
+	// indicating if there is one.
+	Next() bool
+	// Value returns the entry at the iterator's current position, or
+	// nil if the iterator is exhausted or has never been nexted.
+	Value() FloatEntry
+}
+
+// FloatRangeTree describes the methods available to a rangetree with
+// float64 coordinates.  These match those of RangeTree except that
+// entries can't be shifted with InsertAtDimension.
+type FloatRangeTree interface {
+	// Add will add the provided entries to the tree.  Any entries that
+	// were overwritten will be returned in the order in which they
+	// were overwritten.  If a cell's addition does not overwrite, a nil
+	// is returned for that cell for its index in the provided cells.
+	Add(entries ...FloatEntry) FloatEntries
+	// Len returns the number of entries in the tree.
+	Len() uint64
+	// Delete will remove the provided entries from the tree.
+	Delete(entries ...FloatEntry)
+	// Query will return a list of entries that fall within
+	// the provided interval.
+	Query(interval FloatInterval) FloatEntries
+	// Apply will call the provided function with each entry that exists
+	// within the provided range, in order.  Return false at any time to
+	// cancel iteration.  Altering the entry in such a way that its location
+	// changes will result in undefined behavior.
+	Apply(interval FloatInterval, fn func(FloatEntry) bool)
+	// Each will call the provided function with every entry in the
+	// tree, in order, until false is returned.
+	Each(fn func(FloatEntry) bool)
+	// All returns a sequence of every entry in the tree, in order,
+	// for use with range.
+	All() iter.Seq[FloatEntry]
+	// QueryIter returns an iterator over the entries that fall within
+	// the provided interval, in order.
+	QueryIter(interval FloatInterval) FloatIterator
+	// KNearest returns up to k entries nearest to the provided point
+	// by euclidean distance, nearest first.  Entries at the same
+	// distance are ordered by their values in each dimension in turn.
+	KNearest(point FloatEntry, k int) FloatEntries
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+// iterFrame is the position of an iterator within the list of nodes at
+// one dimension, along with the exclusive bound of the query there.
+type iterFrame struct {
+	list  orderedNodes
+	index int
+	high  int64
+}
+
+// orderedIterator iterates over the results of a query of an ordered
+// tree by keeping its position at every dimension on a stack.
+type orderedIterator struct {
+	interval   Interval
+	dimensions uint64
+	stack      []iterFrame
+	entry      Entry
+}
+
+func (iter *orderedIterator) push(list orderedNodes, dimension uint64) {
+	low := iter.interval.LowAtDimension(dimension)
+	iter.stack = append(iter.stack, iterFrame{
+		list:  list,
+		index: list.search(low),
+		high:  iter.interval.HighAtDimension(dimension),
+	})
+}
+
+// Next moves the iterator to the next entry and returns a bool
+// indicating if there is one.
+func (iter *orderedIterator) Next() bool {
+	for len(iter.stack) > 0 {
+		top := &iter.stack[len(iter.stack)-1]
+		if top.index >= len(top.list) || top.list[top.index].value >= top.high {
+			iter.stack = iter.stack[:len(iter.stack)-1]
+			continue
+		}
+
+		n := top.list[top.index]
+		top.index++
+		dimension := uint64(len(iter.stack))
+		if isLastDimension(iter.dimensions, dimension) {
+			iter.entry = n.entry
+			return true
+		}
+
+		iter.push(n.orderedNodes, dimension+1)
+	}
+
+	iter.entry = nil
+	return false
+}
+
+// Value returns the entry at the iterator's current position, or nil
+// if the iterator is exhausted or has never been nexted.
+func (iter *orderedIterator) Value() Entry {
+	return iter.entry
+}
+
+// QueryIter returns an iterator over the entries in the given
+// interval, in order.
+func (ot *orderedTree) QueryIter(interval Interval) Iterator {
+	iter := &orderedIterator{
+		interval:   interval,
+		dimensions: ot.dimensions,
+		stack:      make([]iterFrame, 0, ot.dimensions),
+	}
+	iter.push(ot.top, 1)
+
+	return iter
+}
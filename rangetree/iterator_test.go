@@ -0,0 +1,84 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exhaust(iter Iterator) Entries {
+	entries := Entries{}
+	for iter.Next() {
+		entries = append(entries, iter.Value())
+	}
+
+	return entries
+}
+
+func TestQueryIter(t *testing.T) {
+	tree, _ := constructMultiDimensionalOrderedTree(100)
+	tree.Add(constructMockEntry(100, 5, 7), constructMockEntry(101, 5, 3))
+
+	intervals := []*mockInterval{
+		constructMockInterval(dimension{0, 100}, dimension{0, 100}),
+		constructMockInterval(dimension{5, 6}, dimension{0, 100}),
+		constructMockInterval(dimension{3, 20}, dimension{4, 8}),
+		constructMockInterval(dimension{50, 40}, dimension{0, 100}),
+		constructMockInterval(dimension{200, 300}, dimension{0, 100}),
+	}
+
+	for _, interval := range intervals {
+		assert.Equal(t, append(Entries{}, tree.Query(interval)...), exhaust(tree.QueryIter(interval)))
+	}
+}
+
+func TestQueryIterStop(t *testing.T) {
+	tree, entries := constructMultiDimensionalOrderedTree(100)
+
+	iter := tree.QueryIter(constructMockInterval(dimension{10, 100}, dimension{0, 100}))
+	assert.Nil(t, iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, entries[10], iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, entries[11], iter.Value())
+
+	iter = newOrderedTree(2).QueryIter(constructMockInterval(dimension{0, 1}, dimension{0, 1}))
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+}
+
+func TestQueryIterSingleDimension(t *testing.T) {
+	tree := newOrderedTree(1)
+	e1, e2 := constructMockEntry(0, 1), constructMockEntry(1, 3)
+	tree.Add(e1, e2)
+
+	assert.Equal(t, Entries{e1, e2}, exhaust(tree.QueryIter(constructMockInterval(dimension{0, 4}))))
+	assert.Equal(t, Entries{e2}, exhaust(tree.QueryIter(constructMockInterval(dimension{2, 4}))))
+}
+
+func BenchmarkQueryIterFirst(b *testing.B) {
+	tree, _ := constructMultiDimensionalOrderedTree(100000)
+	interval := constructMockInterval(dimension{0, 100000}, dimension{0, 100000})
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		iter := tree.QueryIter(interval)
+		iter.Next()
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// square returns the squared difference of two values.  Floats are
+// used so distances between far apart values can't overflow.
+func square(a, b int64) float64 {
+	d := float64(a) - float64(b)
+	return d * d
+}
+
+// neighbor is an entry found by a nearest neighbor search and its
+// squared distance from the point searched for.
+type neighbor struct {
+	entry    Entry
+	distance float64
+}
+
+// neighbors keeps the k nearest entries found so far with the farthest
+// at the root so it can be replaced.
+type neighbors struct {
+	items      []neighbor
+	k          int
+	dimensions uint64
+	// square returns the squared distance between two values in one
+	// dimension.
+	square func(a, b int64) float64
+}
+
+// closer returns a bool indicating if a is nearer than b, comparing
+// values in each dimension in turn when the distances are equal.
+func (nb *neighbors) closer(a, b neighbor) bool {
+	if a.distance != b.distance {
+		return a.distance < b.distance
+	}
+
+	for d := uint64(1); d <= nb.dimensions; d++ {
+		av, bv := a.entry.ValueAtDimension(d), b.entry.ValueAtDimension(d)
+		if av != bv {
+			return av < bv
+		}
+	}
+
+	return false
+}
+
+func (nb *neighbors) Len() int           { return len(nb.items) }
+func (nb *neighbors) Less(i, j int) bool { return nb.closer(nb.items[j], nb.items[i]) }
+func (nb *neighbors) Swap(i, j int)      { nb.items[i], nb.items[j] = nb.items[j], nb.items[i] }
+
+func (nb *neighbors) Push(x interface{}) {
+	nb.items = append(nb.items, x.(neighbor))
+}
+
+func (nb *neighbors) Pop() interface{} {
+	n := len(nb.items)
+	item := nb.items[n-1]
+	nb.items = nb.items[:n-1]
+	return item
+}
+
+// accepts returns a bool indicating if an entry at the provided
+// distance could be among the k nearest.
+func (nb *neighbors) accepts(distance float64) bool {
+	return len(nb.items) < nb.k || distance <= nb.items[0].distance
+}
+
+func (nb *neighbors) offer(entry Entry, distance float64) {
+	n := neighbor{entry: entry, distance: distance}
+	if len(nb.items) < nb.k {
+		heap.Push(nb, n)
+		return
+	}
+
+	if nb.closer(n, nb.items[0]) {
+		nb.items[0] = n
+		heap.Fix(nb, 0)
+	}
+}
+
+// entries returns the entries found, nearest first.
+func (nb *neighbors) entries() Entries {
+	sort.Slice(nb.items, func(i, j int) bool {
+		return nb.closer(nb.items[i], nb.items[j])
+	})
+
+	entries := make(Entries, 0, len(nb.items))
+	for _, n := range nb.items {
+		entries = append(entries, n.entry)
+	}
+
+	return entries
+}
+
+// nearest offers the entries at or below the provided list to nb.  The
+// list is walked outward from the point's value in both directions,
+// stopping each way once the distance in this dimension alone, added
+// to the distance in the dimensions before it, rules out the rest.
+func (ot *orderedTree) nearest(list orderedNodes, point Entry,
+	dimension uint64, partial float64, nb *neighbors) {
+
+	value := point.ValueAtDimension(dimension)
+	visit := func(n *node) bool {
+		distance := partial + nb.square(n.value, value)
+		if !nb.accepts(distance) {
+			return false
+		}
+
+		if isLastDimension(ot.dimensions, dimension) {
+			nb.offer(n.entry, distance)
+		} else {
+			ot.nearest(n.orderedNodes, point, dimension+1, distance, nb)
+		}
+		return true
+	}
+
+	i := list.search(value)
+	for j := i; j < len(list) && visit(list[j]); j++ {
+	}
+	for j := i - 1; j >= 0 && visit(list[j]); j-- {
+	}
+}
+
+func (ot *orderedTree) kNearest(point Entry, k int, square func(a, b int64) float64) Entries {
+	if k < 1 {
+		return Entries{}
+	}
+
+	nb := &neighbors{k: k, dimensions: ot.dimensions, square: square}
+	ot.nearest(ot.top, point, 1, 0, nb)
+	return nb.entries()
+}
+
+// KNearest returns up to k entries nearest to the provided point,
+// nearest first.
+func (ot *orderedTree) KNearest(point Entry, k int) Entries {
+	return ot.kNearest(point, k, square)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rangetree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bruteNearest returns the k entries nearest to point by checking every
+// entry.
+func bruteNearest(entries Entries, point Entry, k int, dimensions uint64) Entries {
+	nb := &neighbors{dimensions: dimensions}
+	for _, e := range entries {
+		distance := 0.0
+		for d := uint64(1); d <= dimensions; d++ {
+			distance += square(e.ValueAtDimension(d), point.ValueAtDimension(d))
+		}
+		nb.items = append(nb.items, neighbor{entry: e, distance: distance})
+	}
+
+	sort.Slice(nb.items, func(i, j int) bool {
+		return nb.closer(nb.items[i], nb.items[j])
+	})
+	if len(nb.items) > k {
+		nb.items = nb.items[:k]
+	}
+
+	return nb.entries()
+}
+
+func TestKNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, dimensions := range []uint64{1, 2, 3} {
+		tree := newOrderedTree(dimensions)
+		for i := 0; i < 500; i++ {
+			values := make([]int64, dimensions)
+			for d := range values {
+				values[d] = r.Int63n(200) - 100
+			}
+			tree.Add(constructMockEntry(uint64(i), values...))
+		}
+
+		entries := tree.Query(constructMockInterval(
+			dimension{-100, 100}, dimension{-100, 100}, dimension{-100, 100},
+		))
+		assert.Equal(t, tree.Len(), uint64(len(entries)))
+
+		for i := 0; i < 20; i++ {
+			values := make([]int64, dimensions)
+			for d := range values {
+				values[d] = r.Int63n(240) - 120
+			}
+			point := constructMockEntry(0, values...)
+
+			for _, k := range []int{1, 5, 50} {
+				assert.Equal(t, bruteNearest(entries, point, k, dimensions), tree.KNearest(point, k))
+			}
+		}
+	}
+}
+
+func TestKNearestTies(t *testing.T) {
+	tree := newOrderedTree(2)
+	e1 := constructMockEntry(0, 0, 1)
+	e2 := constructMockEntry(1, 1, 0)
+	e3 := constructMockEntry(2, -1, 0)
+	e4 := constructMockEntry(3, 5, 5)
+	tree.Add(e1, e2, e3, e4)
+
+	point := constructMockEntry(0, 0, 0)
+	assert.Equal(t, Entries{e3, e1}, tree.KNearest(point, 2))
+	assert.Equal(t, Entries{e3, e1, e2, e4}, tree.KNearest(point, 10))
+	assert.Equal(t, Entries{}, tree.KNearest(point, 0))
+	assert.Equal(t, Entries{}, newOrderedTree(2).KNearest(point, 3))
+}
+
+func BenchmarkKNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	tree := newOrderedTree(2)
+	for i := 0; i < 100000; i++ {
+		tree.Add(constructMockEntry(uint64(i), r.Int63n(10000), r.Int63n(10000)))
+	}
+	point := constructMockEntry(0, 5000, 5000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.KNearest(point, 10)
+	}
+}
@@ -132,6 +132,25 @@ func (nodes *orderedNodes) getOrAdd(entry Entry,
 	return node, true
 }
 
+// each calls fn with every entry found at or below this list of
+// nodes.  Returns false if fn returned false.
+func (nodes orderedNodes) each(fn func(Entry) bool) bool {
+	for _, node := range nodes {
+		if node.orderedNodes != nil {
+			if !node.orderedNodes.each(fn) {
+				return false
+			}
+			continue
+		}
+
+		if !fn(node.entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (nodes orderedNodes) flatten(entries *Entries) {
 	for _, node := range nodes {
 		if node.orderedNodes != nil {
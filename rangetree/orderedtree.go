@@ -16,6 +16,8 @@ limitations under the License.
 
 package rangetree
 
+import "iter"
+
 func isLastDimension(value, test uint64) bool {
 	return test >= value
 }
@@ -166,6 +168,20 @@ func (ot *orderedTree) Apply(interval Interval, fn func(Entry) bool) {
 	})
 }
 
+// Each will call (in order) the provided function with every
+// entry in the tree until false is returned.
+func (ot *orderedTree) Each(fn func(Entry) bool) {
+	ot.top.each(fn)
+}
+
+// All returns a sequence of every entry in the tree, in order, for
+// use with range.
+func (ot *orderedTree) All() iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		ot.top.each(yield)
+	}
+}
+
 // Query will return an ordered list of results in the given
 // interval.
 func (ot *orderedTree) Query(interval Interval) Entries {
@@ -187,7 +203,6 @@ func (ot *orderedTree) Query(interval Interval) Entries {
 func (ot *orderedTree) InsertAtDimension(dimension uint64,
 	index, number int64) (Entries, Entries) {
 
-	// TODO: perhaps return an error here?
 	if dimension > ot.dimensions || number == 0 {
 		return nil, nil
 	}
@@ -204,6 +219,20 @@ func (ot *orderedTree) InsertAtDimension(dimension uint64,
 	return modified, deleted
 }
 
+// InsertAtDimensionE is InsertAtDimension except that it returns an
+// OutOfDimensionError if the tree doesn't have the provided
+// dimension.  Dimensions are numbered from 1.
+func (ot *orderedTree) InsertAtDimensionE(dimension uint64,
+	index, number int64) (Entries, Entries, error) {
+
+	if dimension < 1 || dimension > ot.dimensions {
+		return nil, nil, OutOfDimensionError{Provided: dimension, Dimensions: ot.dimensions}
+	}
+
+	modified, deleted := ot.InsertAtDimension(dimension, index, number)
+	return modified, deleted, nil
+}
+
 func newOrderedTree(dimensions uint64) *orderedTree {
 	return &orderedTree{
 		dimensions: dimensions,
@@ -17,10 +17,13 @@ limitations under the License.
 package rangetree
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 func constructMultiDimensionalOrderedTree(number uint64) (
@@ -327,6 +330,52 @@ func TestApplyWithBail(t *testing.T) {
 	assert.Equal(t, entries[:1], result)
 }
 
+func TestOTEach(t *testing.T) {
+	tree, entries := constructMultiDimensionalOrderedTree(10)
+
+	result := make(Entries, 0, len(entries))
+	tree.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, entries, result)
+
+	result = result[:0]
+	tree.Each(func(e Entry) bool {
+		result = append(result, e)
+		return false
+	})
+	assert.Equal(t, entries[:1], result)
+
+	fn := func(Entry) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { tree.Each(fn) }))
+}
+
+func TestOTAll(t *testing.T) {
+	tree, entries := constructMultiDimensionalOrderedTree(10)
+
+	result := make(Entries, 0, len(entries))
+	for e := range tree.All() {
+		result = append(result, e)
+		if len(result) == 5 {
+			break
+		}
+	}
+	assert.Equal(t, entries[:5], result)
+}
+
+func BenchmarkEach(b *testing.B) {
+	tree, _ := constructMultiDimensionalOrderedTree(1000)
+	fn := func(Entry) bool { return true }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Each(fn)
+	}
+}
+
 func BenchmarkApply(b *testing.B) {
 	numItems := 1000
 
@@ -355,6 +404,24 @@ func TestInsertPositiveIndexFirstDimension(t *testing.T) {
 	assert.Equal(t, entries[1:], result)
 }
 
+func TestInsertAtDimensionE(t *testing.T) {
+	tree, entries := constructMultiDimensionalOrderedTree(2)
+
+	modified, deleted, err := tree.InsertAtDimensionE(3, 1, 1)
+	assert.Len(t, modified, 0)
+	assert.Len(t, deleted, 0)
+	assert.Equal(t, OutOfDimensionError{Provided: 3, Dimensions: 2}, err)
+	assert.True(t, errors.Is(err, common.ErrDimensionMismatch))
+
+	_, _, err = tree.InsertAtDimensionE(0, 1, 1)
+	assert.True(t, errors.Is(err, common.ErrDimensionMismatch))
+
+	modified, deleted, err = tree.InsertAtDimensionE(1, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, deleted, 0)
+	assert.Equal(t, entries[1:], modified)
+}
+
 func TestInsertPositiveIndexSecondDimension(t *testing.T) {
 	tree, entries := constructMultiDimensionalOrderedTree(3)
 
@@ -0,0 +1,88 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package skiplist
+
+import (
+	"github.com/Workiva/go-datastructures/rangetree"
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// iterFrame is the position of an iterator within the skip list at
+// one dimension, along with the exclusive bound of the query there.
+type iterFrame struct {
+	iter skip.Iterator
+	high int64
+}
+
+// iterator iterates over the results of a query by keeping a skip list
+// iterator for every dimension on a stack.
+type iterator struct {
+	rt       *skipListRT
+	interval rangetree.Interval
+	stack    []iterFrame
+	entry    rangetree.Entry
+}
+
+func (iter *iterator) push(sl *skip.SkipList, dimension uint64) {
+	low := iter.interval.LowAtDimension(dimension)
+	iter.stack = append(iter.stack, iterFrame{
+		iter: sl.Iter(skipEntry(low)),
+		high: iter.interval.HighAtDimension(dimension),
+	})
+}
+
+// Next moves the iterator to the next entry and returns a bool
+// indicating if there is one.
+func (iter *iterator) Next() bool {
+	for len(iter.stack) > 0 {
+		top := iter.stack[len(iter.stack)-1]
+		if !top.iter.Next() || int64(top.iter.Value().(keyed).key()) >= top.high {
+			iter.stack = iter.stack[:len(iter.stack)-1]
+			continue
+		}
+
+		e := top.iter.Value()
+		dimension := uint64(len(iter.stack)) - 1
+		if isLastDimension(dimension, iter.rt.dimensions) {
+			iter.entry = e.(*lastBundle).entry
+			return true
+		}
+
+		iter.push(e.(*dimensionalBundle).sl, dimension+1)
+	}
+
+	iter.entry = nil
+	return false
+}
+
+// Value returns the entry at the iterator's current position, or nil
+// if the iterator is exhausted or has never been nexted.
+func (iter *iterator) Value() rangetree.Entry {
+	return iter.entry
+}
+
+// QueryIter returns an iterator over the entries that fall within the
+// provided interval, in the same order as Query.
+func (rt *skipListRT) QueryIter(interval rangetree.Interval) rangetree.Iterator {
+	iter := &iterator{
+		rt:       rt,
+		interval: interval,
+		stack:    make([]iterFrame, 0, rt.dimensions),
+	}
+	iter.push(rt.top, 0)
+
+	return iter
+}
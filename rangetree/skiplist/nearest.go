@@ -0,0 +1,198 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package skiplist
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/Workiva/go-datastructures/rangetree"
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// square returns the squared difference of two values.  Floats are
+// used so distances between far apart values can't overflow.
+func square(a, b int64) float64 {
+	d := float64(a) - float64(b)
+	return d * d
+}
+
+// walkUp calls fn with the entries of the provided skip list whose
+// values are at least value, in ascending order of value, until fn
+// returns false.  Values are stored as unsigned keys so negative
+// values sort after every other and are visited first.
+func walkUp(sl *skip.SkipList, value int64, fn func(skip.Entry) bool) {
+	if value < 0 {
+		for iter := sl.Iter(skipEntry(value)); iter.Next(); {
+			if !fn(iter.Value()) {
+				return
+			}
+		}
+		value = 0
+	}
+
+	for iter := sl.Iter(skipEntry(value)); iter.Next(); {
+		if int64(iter.Value().(keyed).key()) < 0 || !fn(iter.Value()) {
+			return
+		}
+	}
+}
+
+// walkDown calls fn with the entries of the provided skip list whose
+// values are less than value, in descending order of value, until fn
+// returns false.
+func walkDown(sl *skip.SkipList, value int64, fn func(skip.Entry) bool) {
+	if value == math.MinInt64 {
+		return
+	}
+
+	value--
+	if value >= 0 {
+		for iter := sl.IterReverse(skipEntry(value)); iter.Next(); {
+			if !fn(iter.Value()) {
+				return
+			}
+		}
+		value = -1
+	}
+
+	for iter := sl.IterReverse(skipEntry(value)); iter.Next(); {
+		if int64(iter.Value().(keyed).key()) >= 0 || !fn(iter.Value()) {
+			return
+		}
+	}
+}
+
+// neighbor is an entry found by a nearest neighbor search and its
+// squared distance from the point searched for.
+type neighbor struct {
+	entry    rangetree.Entry
+	distance float64
+}
+
+// neighbors keeps the k nearest entries found so far with the farthest
+// at the root so it can be replaced.
+type neighbors struct {
+	items      []neighbor
+	k          int
+	dimensions uint64
+}
+
+// closer returns a bool indicating if a is nearer than b, comparing
+// values in each dimension in turn when the distances are equal.
+func (nb *neighbors) closer(a, b neighbor) bool {
+	if a.distance != b.distance {
+		return a.distance < b.distance
+	}
+
+	for d := uint64(0); d < nb.dimensions; d++ {
+		av, bv := a.entry.ValueAtDimension(d), b.entry.ValueAtDimension(d)
+		if av != bv {
+			return av < bv
+		}
+	}
+
+	return false
+}
+
+func (nb *neighbors) Len() int           { return len(nb.items) }
+func (nb *neighbors) Less(i, j int) bool { return nb.closer(nb.items[j], nb.items[i]) }
+func (nb *neighbors) Swap(i, j int)      { nb.items[i], nb.items[j] = nb.items[j], nb.items[i] }
+
+func (nb *neighbors) Push(x interface{}) {
+	nb.items = append(nb.items, x.(neighbor))
+}
+
+func (nb *neighbors) Pop() interface{} {
+	n := len(nb.items)
+	item := nb.items[n-1]
+	nb.items = nb.items[:n-1]
+	return item
+}
+
+// accepts returns a bool indicating if an entry at the provided
+// distance could be among the k nearest.
+func (nb *neighbors) accepts(distance float64) bool {
+	return len(nb.items) < nb.k || distance <= nb.items[0].distance
+}
+
+func (nb *neighbors) offer(entry rangetree.Entry, distance float64) {
+	n := neighbor{entry: entry, distance: distance}
+	if len(nb.items) < nb.k {
+		heap.Push(nb, n)
+		return
+	}
+
+	if nb.closer(n, nb.items[0]) {
+		nb.items[0] = n
+		heap.Fix(nb, 0)
+	}
+}
+
+// entries returns the entries found, nearest first.
+func (nb *neighbors) entries() rangetree.Entries {
+	sort.Slice(nb.items, func(i, j int) bool {
+		return nb.closer(nb.items[i], nb.items[j])
+	})
+
+	entries := make(rangetree.Entries, 0, len(nb.items))
+	for _, n := range nb.items {
+		entries = append(entries, n.entry)
+	}
+
+	return entries
+}
+
+// nearest offers the entries at or below the provided skip list to
+// nb.  The list is walked outward from the point's value in both
+// directions, stopping each way once the distance in this dimension
+// alone, added to the distance in the dimensions before it, rules out
+// the rest.
+func (rt *skipListRT) nearest(sl *skip.SkipList, point rangetree.Entry,
+	dimension uint64, partial float64, nb *neighbors) {
+
+	value := point.ValueAtDimension(dimension)
+	visit := func(e skip.Entry) bool {
+		distance := partial + square(int64(e.(keyed).key()), value)
+		if !nb.accepts(distance) {
+			return false
+		}
+
+		if isLastDimension(dimension, rt.dimensions) {
+			nb.offer(e.(*lastBundle).entry, distance)
+		} else {
+			rt.nearest(e.(*dimensionalBundle).sl, point, dimension+1, distance, nb)
+		}
+		return true
+	}
+
+	walkUp(sl, value, visit)
+	walkDown(sl, value, visit)
+}
+
+// KNearest returns up to k entries nearest to the provided point by
+// euclidean distance, nearest first.  Entries at the same distance are
+// ordered by their values in each dimension in turn.
+func (rt *skipListRT) KNearest(point rangetree.Entry, k int) rangetree.Entries {
+	if k < 1 {
+		return rangetree.Entries{}
+	}
+
+	nb := &neighbors{k: k, dimensions: rt.dimensions}
+	rt.nearest(rt.top, point, 0, 0, nb)
+	return nb.entries()
+}
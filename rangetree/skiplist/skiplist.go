@@ -33,6 +33,8 @@ Delete: O(log N1 + log N2 + ...log Nn) = O(log N1*N2*...Nn)
 package skiplist
 
 import (
+	"iter"
+
 	"github.com/Workiva/go-datastructures/rangetree"
 	"github.com/Workiva/go-datastructures/slice/skip"
 )
@@ -325,6 +327,37 @@ func (rt *skipListRT) Apply(interval rangetree.Interval, fn func(rangetree.Entry
 	rt.apply(rt.top, 0, interval, fn)
 }
 
+func (rt *skipListRT) each(sl *skip.SkipList, dimension uint64, fn func(rangetree.Entry) bool) bool {
+	lastDimension := isLastDimension(dimension, rt.dimensions)
+	ok := true
+	sl.Each(func(e skip.Entry) bool {
+		if lastDimension {
+			ok = fn(e.(*lastBundle).entry)
+		} else {
+			ok = rt.each(e.(*dimensionalBundle).sl, dimension+1, fn)
+		}
+
+		return ok
+	})
+
+	return ok
+}
+
+// Each will call the provided function with every entry in the tree,
+// in order, without allocating.  Return false at any time to cancel
+// iteration.
+func (rt *skipListRT) Each(fn func(rangetree.Entry) bool) {
+	rt.each(rt.top, 0, fn)
+}
+
+// All returns a sequence of every entry in the tree, in order, for
+// use with range.
+func (rt *skipListRT) All() iter.Seq[rangetree.Entry] {
+	return func(yield func(rangetree.Entry) bool) {
+		rt.each(rt.top, 0, yield)
+	}
+}
+
 // Query will return a list of entries that fall within
 // the provided interval.
 func (rt *skipListRT) Query(interval rangetree.Interval) rangetree.Entries {
@@ -424,6 +457,20 @@ func (rt *skipListRT) InsertAtDimension(dimension uint64,
 	return affected, deleted
 }
 
+// InsertAtDimensionE is InsertAtDimension except that it returns a
+// rangetree.OutOfDimensionError if the tree doesn't have the provided
+// dimension.  Dimensions are numbered from 0.
+func (rt *skipListRT) InsertAtDimensionE(dimension uint64,
+	index, number int64) (rangetree.Entries, rangetree.Entries, error) {
+
+	if dimension >= rt.dimensions {
+		return nil, nil, rangetree.OutOfDimensionError{Provided: dimension, Dimensions: rt.dimensions}
+	}
+
+	affected, deleted := rt.InsertAtDimension(dimension, index, number)
+	return affected, deleted, nil
+}
+
 func new(dimensions uint64) *skipListRT {
 	sl := &skipListRT{}
 	sl.init(dimensions)
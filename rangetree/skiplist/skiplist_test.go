@@ -17,12 +17,15 @@ limitations under the License.
 package skiplist
 
 import (
+	"errors"
 	"math"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/Workiva/go-datastructures/common"
 	"github.com/Workiva/go-datastructures/rangetree"
 )
 
@@ -293,6 +296,23 @@ func TestRTInsertBeyondDimension(t *testing.T) {
 	assert.Equal(t, rangetree.Entries{m1}, rt.Get(m1))
 }
 
+func TestRTInsertAtDimensionE(t *testing.T) {
+	rt := new(2)
+	m1 := newMockEntry(3, 3)
+	rt.Add(m1)
+
+	affected, deleted, err := rt.InsertAtDimensionE(2, 0, 1)
+	assert.Len(t, affected, 0)
+	assert.Len(t, deleted, 0)
+	assert.Equal(t, rangetree.OutOfDimensionError{Provided: 2, Dimensions: 2}, err)
+	assert.True(t, errors.Is(err, common.ErrDimensionMismatch))
+
+	affected, deleted, err = rt.InsertAtDimensionE(1, 0, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, rangetree.Entries{m1}, affected)
+	assert.Len(t, deleted, 0)
+}
+
 func TestRTInsertZero(t *testing.T) {
 	rt := new(2)
 	m1 := newMockEntry(3, 3)
@@ -304,6 +324,125 @@ func TestRTInsertZero(t *testing.T) {
 	assert.Equal(t, rangetree.Entries{m1}, rt.Get(m1))
 }
 
+func TestRTEach(t *testing.T) {
+	rt := new(2)
+	m1 := newMockEntry(3, 5)
+	m2 := newMockEntry(3, 1)
+	m3 := newMockEntry(1, 8)
+	rt.Add(m1, m2, m3)
+
+	result := make(rangetree.Entries, 0, 3)
+	rt.Each(func(e rangetree.Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, rangetree.Entries{m3, m2, m1}, result)
+
+	result = result[:0]
+	rt.Each(func(e rangetree.Entry) bool {
+		result = append(result, e)
+		return len(result) < 2
+	})
+	assert.Equal(t, rangetree.Entries{m3, m2}, result)
+
+	result = result[:0]
+	for e := range rt.All() {
+		result = append(result, e)
+	}
+	assert.Equal(t, rangetree.Entries{m3, m2, m1}, result)
+}
+
+func TestRTQueryIter(t *testing.T) {
+	rt := new(2)
+	m1 := newMockEntry(3, 5)
+	m2 := newMockEntry(3, 1)
+	m3 := newMockEntry(1, 8)
+	m4 := newMockEntry(6, 2)
+	rt.Add(m1, m2, m3, m4)
+
+	intervals := []*mockInterval{
+		newMockInterval([]int64{0, 0}, []int64{10, 10}),
+		newMockInterval([]int64{2, 2}, []int64{10, 10}),
+		newMockInterval([]int64{3, 0}, []int64{4, 3}),
+		newMockInterval([]int64{7, 0}, []int64{10, 10}),
+	}
+
+	for _, interval := range intervals {
+		result := rangetree.Entries{}
+		for iter := rt.QueryIter(interval); iter.Next(); {
+			result = append(result, iter.Value())
+		}
+		assert.Equal(t, append(rangetree.Entries{}, rt.Query(interval)...), result)
+	}
+
+	iter := rt.QueryIter(intervals[0])
+	assert.Nil(t, iter.Value())
+	assert.True(t, iter.Next())
+	assert.Equal(t, m3, iter.Value())
+
+	iter = new(2).QueryIter(intervals[0])
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+}
+
+func TestRTKNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	rt := new(2)
+	entries := make(rangetree.Entries, 0, 500)
+	for i := 0; i < 500; i++ {
+		// negative values are stored after positive ones
+		e := newMockEntry(r.Int63n(200)-100, r.Int63n(200)-100)
+		if rt.Add(e)[0] == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		point := newMockEntry(r.Int63n(240)-120, r.Int63n(240)-120)
+		sort.Slice(entries, func(i, j int) bool {
+			return closerTo(point, entries[i], entries[j])
+		})
+
+		for _, k := range []int{1, 5, 50} {
+			assert.Equal(t, entries[:k], rt.KNearest(point, k))
+		}
+	}
+
+	assert.Equal(t, rangetree.Entries{}, rt.KNearest(newMockEntry(0, 0), 0))
+	assert.Equal(t, rangetree.Entries{}, new(2).KNearest(newMockEntry(0, 0), 3))
+}
+
+func TestRTKNearestExtremes(t *testing.T) {
+	rt := new(1)
+	m1 := newMockEntry(math.MinInt64)
+	m2 := newMockEntry(-1)
+	m3 := newMockEntry(math.MaxInt64)
+	rt.Add(m1, m2, m3)
+
+	// both extremes are 2^63 away once distances are floats, so the
+	// lower value comes first
+	assert.Equal(t, rangetree.Entries{m2, m1, m3}, rt.KNearest(newMockEntry(0), 3))
+	assert.Equal(t, rangetree.Entries{m1, m2}, rt.KNearest(newMockEntry(math.MinInt64), 2))
+	assert.Equal(t, rangetree.Entries{m3}, rt.KNearest(newMockEntry(math.MaxInt64), 1))
+}
+
+// closerTo returns a bool indicating if a is nearer to point than b,
+// comparing values when the distances are equal.
+func closerTo(point, a, b rangetree.Entry) bool {
+	da := square(a.ValueAtDimension(0), point.ValueAtDimension(0)) +
+		square(a.ValueAtDimension(1), point.ValueAtDimension(1))
+	db := square(b.ValueAtDimension(0), point.ValueAtDimension(0)) +
+		square(b.ValueAtDimension(1), point.ValueAtDimension(1))
+	if da != db {
+		return da < db
+	}
+
+	if a.ValueAtDimension(0) != b.ValueAtDimension(0) {
+		return a.ValueAtDimension(0) < b.ValueAtDimension(0)
+	}
+	return a.ValueAtDimension(1) < b.ValueAtDimension(1)
+}
+
 func BenchmarkMultiDimensionInsert(b *testing.B) {
 	numItems := b.N
 	rt := new(2)
@@ -0,0 +1,299 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package rope implements a rope, a sequence of bytes such as the text
+of an editor's buffer that can be edited in the middle without copying
+the rest of it.  Inserting into or deleting from a string of n bytes
+copies O(n) bytes, while a rope only rebuilds O(log n) nodes.
+
+A rope is a binary tree whose leaves hold chunks of the bytes in order
+and whose interior nodes record the length of their subtree, so a byte
+offset is found by descending the tree much as the skiplist finds a
+position by its widths.  Ropes are kept balanced as AVL trees, which
+can be joined and split in O(log n).  Every other edit is made of
+these two: an insert splits the rope and joins the pieces back around
+the new bytes.
+
+Ropes are immutable.  Every edit returns a new rope sharing all but
+O(log n) of its nodes with the old one, so old versions remain valid
+and cheap to keep, for instance as an undo history, and a rope may be
+read by many goroutines without locking.  The nil *Rope is the empty
+rope.
+
+Offsets count bytes, as they do for Go strings, and a rope makes no
+attempt to keep the runes of UTF-8 text whole.  Callers editing text
+should use offsets that fall on rune boundaries.
+
+Performance characteristics:
+New: O(n)
+Insert: O(log n + m) where m is the number of bytes inserted
+Delete: O(log n)
+Slice: O(log n)
+Concat: O(log n)
+At: O(log n)
+String: O(n)
+Space: O(n)
+*/
+package rope
+
+import (
+	"io"
+	"iter"
+	"strings"
+)
+
+// maxLeaf is the most bytes joined into a single leaf.  Longer leaves
+// make for shallower trees, but short pieces joined into a leaf are
+// copied.
+const maxLeaf = 512
+
+// Rope is an immutable sequence of bytes.
+type Rope struct {
+	// left and right are nil in a leaf.
+	left, right *Rope
+	// leaf holds the bytes of a leaf and is never empty.
+	leaf   string
+	length int
+	height int
+}
+
+func (r *Rope) isLeaf() bool {
+	return r.left == nil
+}
+
+func newLeaf(s string) *Rope {
+	if s == `` {
+		return nil
+	}
+
+	return &Rope{leaf: s, length: len(s)}
+}
+
+func newNode(left, right *Rope) *Rope {
+	return &Rope{
+		left:   left,
+		right:  right,
+		length: left.length + right.length,
+		height: max(left.height, right.height) + 1,
+	}
+}
+
+// balance returns a node with the provided children, which must
+// differ in height by no more than two, rotating it so that they
+// differ by no more than one.
+func balance(left, right *Rope) *Rope {
+	switch {
+	case left.height > right.height+1:
+		if left.left.height < left.right.height {
+			left = rotateLeft(left.left, left.right)
+		}
+		return newNode(left.left, newNode(left.right, right))
+	case right.height > left.height+1:
+		if right.right.height < right.left.height {
+			right = rotateRight(right.left, right.right)
+		}
+		return newNode(newNode(left, right.left), right.right)
+	}
+
+	return newNode(left, right)
+}
+
+func rotateLeft(left, right *Rope) *Rope {
+	return newNode(newNode(left, right.left), right.right)
+}
+
+func rotateRight(left, right *Rope) *Rope {
+	return newNode(left.left, newNode(left.right, right))
+}
+
+// join returns a rope holding the bytes of left followed by those of
+// right.  This is O(1 + |h(left) - h(right)|).
+func join(left, right *Rope) *Rope {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.isLeaf() && right.isLeaf() && left.length+right.length <= maxLeaf:
+		return newLeaf(left.leaf + right.leaf)
+	case left.height > right.height+1:
+		return balance(left.left, join(left.right, right))
+	case right.height > left.height+1:
+		return balance(join(left, right.left), right.right)
+	}
+
+	return newNode(left, right)
+}
+
+// split returns a rope holding the first i bytes of r and a rope
+// holding the rest.
+func split(r *Rope, i int) (*Rope, *Rope) {
+	switch {
+	case i <= 0:
+		return nil, r
+	case i >= r.Len():
+		return r, nil
+	case r.isLeaf():
+		return newLeaf(r.leaf[:i]), newLeaf(r.leaf[i:])
+	case i < r.left.length:
+		left, right := split(r.left, i)
+		return left, join(right, r.right)
+	}
+
+	left, right := split(r.right, i-r.left.length)
+	return join(r.left, left), right
+}
+
+// build returns a balanced rope of the provided chunks.
+func build(chunks []string) *Rope {
+	switch len(chunks) {
+	case 0:
+		return nil
+	case 1:
+		return newLeaf(chunks[0])
+	}
+
+	mid := len(chunks) / 2
+	return newNode(build(chunks[:mid]), build(chunks[mid:]))
+}
+
+// checkRange panics if [i, j) is not a range of r.
+func (r *Rope) checkRange(i, j int) {
+	if i < 0 || j < i || j > r.Len() {
+		panic(`Rope range out of bounds.`)
+	}
+}
+
+// Len returns the number of bytes in the rope.
+func (r *Rope) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	return r.length
+}
+
+// At returns the byte at offset i, which must be less than the
+// length of the rope.
+func (r *Rope) At(i int) byte {
+	r.checkRange(i, i+1)
+	for !r.isLeaf() {
+		if i < r.left.length {
+			r = r.left
+		} else {
+			i -= r.left.length
+			r = r.right
+		}
+	}
+
+	return r.leaf[i]
+}
+
+// Concat returns a rope holding the bytes of this rope followed by
+// those of the other.  Neither rope is changed.
+func (r *Rope) Concat(other *Rope) *Rope {
+	return join(r, other)
+}
+
+// Split returns a rope holding the first i bytes of this rope and a
+// rope holding the rest.
+func (r *Rope) Split(i int) (*Rope, *Rope) {
+	r.checkRange(i, i)
+	return split(r, i)
+}
+
+// Slice returns a rope holding the bytes in [i, j).
+func (r *Rope) Slice(i, j int) *Rope {
+	r.checkRange(i, j)
+	r, _ = split(r, j)
+	_, r = split(r, i)
+	return r
+}
+
+// Insert returns a rope with s inserted before the byte at offset i.
+// An offset equal to the length of the rope appends s.
+func (r *Rope) Insert(i int, s string) *Rope {
+	r.checkRange(i, i)
+	left, right := split(r, i)
+	return join(join(left, New(s)), right)
+}
+
+// Delete returns a rope without the bytes in [i, j).
+func (r *Rope) Delete(i, j int) *Rope {
+	r.checkRange(i, j)
+	left, rest := split(r, i)
+	_, right := split(rest, j-i)
+	return join(left, right)
+}
+
+// Chunks returns a sequence of the pieces of the rope in order for use
+// with range.  Concatenated they make up the rope, and none is empty.
+func (r *Rope) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		r.each(yield)
+	}
+}
+
+func (r *Rope) each(fn func(string) bool) bool {
+	if r == nil {
+		return true
+	}
+
+	if r.isLeaf() {
+		return fn(r.leaf)
+	}
+
+	return r.left.each(fn) && r.right.each(fn)
+}
+
+// WriteTo writes the bytes of the rope to the provided writer without
+// building them into a single string.
+func (r *Rope) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	var err error
+	r.each(func(chunk string) bool {
+		var n int
+		n, err = io.WriteString(w, chunk)
+		written += int64(n)
+		return err == nil
+	})
+
+	return written, err
+}
+
+// String returns the bytes of the rope as a string.
+func (r *Rope) String() string {
+	var sb strings.Builder
+	sb.Grow(r.Len())
+	r.WriteTo(&sb)
+	return sb.String()
+}
+
+// New returns a rope holding the bytes of s.  The rope shares memory
+// with s rather than copying it.
+func New(s string) *Rope {
+	chunks := make([]string, 0, (len(s)+maxLeaf-1)/maxLeaf)
+	for len(s) > maxLeaf {
+		chunks = append(chunks, s[:maxLeaf])
+		s = s[maxLeaf:]
+	}
+	if s != `` {
+		chunks = append(chunks, s)
+	}
+
+	return build(chunks)
+}
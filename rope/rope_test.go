@@ -0,0 +1,204 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rope
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validate checks that every node of the rope has the correct length
+// and height and is balanced, and that no leaf is empty.
+func validate(r *Rope) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.isLeaf() {
+		if r.leaf == `` || r.length != len(r.leaf) || r.height != 0 || r.right != nil {
+			return fmt.Errorf(`bad leaf %q`, r.leaf)
+		}
+		return nil
+	}
+
+	if r.right == nil {
+		return fmt.Errorf(`node missing right child`)
+	}
+	if err := validate(r.left); err != nil {
+		return err
+	}
+	if err := validate(r.right); err != nil {
+		return err
+	}
+
+	if r.length != r.left.length+r.right.length {
+		return fmt.Errorf(`bad length %d`, r.length)
+	}
+	if r.height != max(r.left.height, r.right.height)+1 {
+		return fmt.Errorf(`bad height %d`, r.height)
+	}
+	if d := r.left.height - r.right.height; d < -1 || d > 1 {
+		return fmt.Errorf(`unbalanced node, heights differ by %d`, d)
+	}
+
+	return nil
+}
+
+func randomString(r *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + r.Intn(26))
+	}
+	return string(b)
+}
+
+func TestEmpty(t *testing.T) {
+	var r *Rope
+	assert.Equal(t, 0, r.Len())
+	assert.Equal(t, ``, r.String())
+	assert.Nil(t, New(``))
+	assert.Equal(t, `abc`, r.Insert(0, `abc`).String())
+	assert.Equal(t, `abc`, r.Concat(New(`abc`)).String())
+	assert.Equal(t, `abc`, New(`abc`).Concat(nil).String())
+	assert.Nil(t, New(`abc`).Delete(0, 3))
+	assert.Nil(t, New(`abc`).Slice(1, 1))
+}
+
+func TestNew(t *testing.T) {
+	s := randomString(rand.New(rand.NewSource(0)), 10*maxLeaf+7)
+	r := New(s)
+	assert.Nil(t, validate(r))
+	assert.Equal(t, len(s), r.Len())
+	assert.Equal(t, s, r.String())
+	for i := 0; i < len(s); i += 97 {
+		assert.Equal(t, s[i], r.At(i))
+	}
+
+	var chunks []string
+	for chunk := range r.Chunks() {
+		chunks = append(chunks, chunk)
+	}
+	assert.Len(t, chunks, 11)
+	assert.Equal(t, s, strings.Join(chunks, ``))
+}
+
+func TestEdits(t *testing.T) {
+	r := New(`hello world`)
+	inserted := r.Insert(5, `,`)
+	assert.Equal(t, `hello, world`, inserted.String())
+	assert.Equal(t, `world`, inserted.Slice(7, 12).String())
+	assert.Equal(t, `hello`, inserted.Delete(5, 12).String())
+	assert.Equal(t, `hello world!`, r.Insert(r.Len(), `!`).String())
+
+	left, right := r.Split(6)
+	assert.Equal(t, `hello `, left.String())
+	assert.Equal(t, `world`, right.String())
+	assert.Equal(t, `worldhello `, right.Concat(left).String())
+
+	// edits leave the original untouched
+	assert.Equal(t, `hello world`, r.String())
+}
+
+func TestOutOfRange(t *testing.T) {
+	r := New(`abc`)
+	assert.Panics(t, func() { r.At(3) })
+	assert.Panics(t, func() { r.At(-1) })
+	assert.Panics(t, func() { r.Insert(4, `d`) })
+	assert.Panics(t, func() { r.Delete(2, 1) })
+	assert.Panics(t, func() { r.Slice(0, 4) })
+	assert.Panics(t, func() { r.Split(-1) })
+}
+
+func TestWriteTo(t *testing.T) {
+	s := randomString(rand.New(rand.NewSource(1)), 3*maxLeaf)
+	var sb strings.Builder
+	n, err := New(s).WriteTo(&sb)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(s)), n)
+	assert.Equal(t, s, sb.String())
+}
+
+func TestRandomEdits(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	var r *Rope
+	model := ``
+	for i := 0; i < 2000; i++ {
+		switch rnd.Intn(4) {
+		case 0, 1:
+			s := randomString(rnd, rnd.Intn(2*maxLeaf))
+			at := rnd.Intn(len(model) + 1)
+			r = r.Insert(at, s)
+			model = model[:at] + s + model[at:]
+		case 2:
+			i := rnd.Intn(len(model) + 1)
+			j := i + rnd.Intn(len(model)-i+1)
+			r = r.Delete(i, j)
+			model = model[:i] + model[j:]
+		case 3:
+			i := rnd.Intn(len(model) + 1)
+			j := i + rnd.Intn(len(model)-i+1)
+			slice := r.Slice(i, j)
+			if !assert.Nil(t, validate(slice)) {
+				return
+			}
+			assert.Equal(t, model[i:j], slice.String())
+			// rejoining the pieces gives back the whole
+			r = r.Slice(0, i).Concat(slice).Concat(r.Slice(j, r.Len()))
+		}
+
+		if !assert.Nil(t, validate(r)) || !assert.Equal(t, len(model), r.Len()) {
+			return
+		}
+	}
+
+	assert.Equal(t, model, r.String())
+}
+
+func TestConcatUnevenHeights(t *testing.T) {
+	var r *Rope
+	model := ``
+	for i := 0; i < 1000; i++ {
+		// small appends are packed into leaves rather than each
+		// becoming its own
+		s := fmt.Sprintf(`%d,`, i)
+		r = r.Concat(New(s))
+		model += s
+	}
+	assert.Nil(t, validate(r))
+	assert.Equal(t, model, r.String())
+	assert.True(t, r.height < 6)
+
+	big := New(strings.Repeat(model, 20))
+	for _, pair := range [][2]*Rope{{big, r}, {r, big}, {New(`x`), big}} {
+		joined := pair[0].Concat(pair[1])
+		assert.Nil(t, validate(joined))
+		assert.Equal(t, pair[0].String()+pair[1].String(), joined.String())
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	r := New(strings.Repeat(`a`, 1<<20))
+	rnd := rand.New(rand.NewSource(0))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r = r.Insert(rnd.Intn(r.Len()+1), `bcd`)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package sampler implements weighted random sampling over a set of items
+whose weights change, as when spreading load across servers by their
+spare capacity or picking events in a simulation by their rates.  An
+item is sampled with probability equal to its share of the total
+weight.
+
+Weights are kept in a fenwick.Tree, so changing a weight updates
+O(log n) sums of runs of weights, and a sample searches the tree for
+the item covering a random point of the total weight.  The alias
+method samples in O(1) but must be rebuilt in O(n) whenever a weight
+changes, so it only suits fixed weights.
+
+Floating point sums drift as weights are updated, so the tree is
+rebuilt from the weights once it has seen as many updates as it holds
+items, which keeps updates O(log n) amortized.
+
+This structure is not threadsafe.
+
+Performance characteristics:
+Add: O(log n) amortized
+UpdateWeight: O(log n) amortized
+Remove: O(log n) amortized
+Sample: O(log n)
+SampleWithoutReplacement: O(k log n)
+Total: O(log n)
+Space: O(n)
+*/
+package sampler
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Workiva/go-datastructures/fenwick"
+)
+
+// Sampler draws items at random in proportion to their weights.
+type Sampler[T comparable] struct {
+	items   []T
+	weights []float64
+	tree    *fenwick.Tree[float64]
+	index   map[T]int
+	updates int
+	rand    *rand.Rand
+}
+
+func checkWeight(weight float64) {
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		panic(`Weight must be finite and non-negative.`)
+	}
+}
+
+// set sets the weight of the item at index i, rebuilding the tree if
+// it has seen enough updates to have drifted.
+func (s *Sampler[T]) set(i int, weight float64) {
+	delta := weight - s.weights[i]
+	s.weights[i] = weight
+	s.updates++
+	if s.updates > len(s.weights) {
+		s.updates = 0
+		s.tree = fenwick.NewFromSlice(s.weights)
+		return
+	}
+
+	s.tree.Add(i, delta)
+}
+
+// search returns the index of the item whose run of the total weight
+// covers target.
+func (s *Sampler[T]) search(target float64) int {
+	i := s.tree.Search(target)
+
+	// rounding can carry the target past the last item or onto
+	// one of no weight, so settle on the nearest one with weight
+	for j := min(i, len(s.weights)-1); j >= 0; j-- {
+		if s.weights[j] > 0 {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// Add adds the item with the provided weight, replacing its weight if
+// it has already been added.  Items of zero weight are never sampled.
+// The weight must be finite and non-negative.
+func (s *Sampler[T]) Add(item T, weight float64) {
+	checkWeight(weight)
+	if i, ok := s.index[item]; ok {
+		s.set(i, weight)
+		return
+	}
+
+	s.index[item] = len(s.items)
+	s.items = append(s.items, item)
+	s.weights = append(s.weights, weight)
+	s.tree.Append(weight)
+}
+
+// UpdateWeight sets the weight of the item and returns a bool
+// indicating if the item had been added.  The weight must be finite
+// and non-negative.
+func (s *Sampler[T]) UpdateWeight(item T, weight float64) bool {
+	checkWeight(weight)
+	i, ok := s.index[item]
+	if !ok {
+		return false
+	}
+
+	s.set(i, weight)
+	return true
+}
+
+// Remove removes the item and returns a bool indicating if it had been
+// added.
+func (s *Sampler[T]) Remove(item T) bool {
+	i, ok := s.index[item]
+	if !ok {
+		return false
+	}
+
+	last := len(s.items) - 1
+	if i != last {
+		s.set(i, s.weights[last])
+		s.items[i] = s.items[last]
+		s.index[s.items[i]] = i
+	}
+	s.set(last, 0)
+
+	var zero T
+	s.items[last] = zero // for garbage collection
+	s.items = s.items[:last]
+	s.weights = s.weights[:last]
+	s.tree.Truncate(last)
+	delete(s.index, item)
+	return true
+}
+
+// Weight returns the weight of the item and a bool indicating if it
+// has been added.
+func (s *Sampler[T]) Weight(item T) (float64, bool) {
+	i, ok := s.index[item]
+	if !ok {
+		return 0, false
+	}
+
+	return s.weights[i], true
+}
+
+// Len returns the number of items added, including those of zero
+// weight.
+func (s *Sampler[T]) Len() int {
+	return len(s.items)
+}
+
+// Total returns the sum of the weights of every item.
+func (s *Sampler[T]) Total() float64 {
+	return s.tree.PrefixSum(len(s.weights))
+}
+
+// Sample returns an item drawn with probability proportional to its
+// weight and a bool indicating if there was any item of positive
+// weight to draw.
+func (s *Sampler[T]) Sample() (T, bool) {
+	var zero T
+	total := s.Total()
+	if total <= 0 {
+		return zero, false
+	}
+
+	i := s.search(s.rand.Float64() * total)
+	if i < 0 {
+		return zero, false
+	}
+
+	return s.items[i], true
+}
+
+// SampleWithoutReplacement returns up to k distinct items, each drawn
+// in proportion to its weight from the items not yet drawn, in the
+// order they were drawn.  Fewer than k items are returned if fewer
+// than k have positive weight.  The weights are left as they were.
+func (s *Sampler[T]) SampleWithoutReplacement(k int) []T {
+	var drawn []int
+	var weights []float64
+	for len(drawn) < k {
+		total := s.Total()
+		if total <= 0 {
+			break
+		}
+
+		i := s.search(s.rand.Float64() * total)
+		if i < 0 {
+			break
+		}
+
+		// zero the weight so it can't be drawn again, bypassing set
+		// so this doesn't count towards a rebuild
+		drawn = append(drawn, i)
+		weights = append(weights, s.weights[i])
+		s.tree.Add(i, -s.weights[i])
+		s.weights[i] = 0
+	}
+
+	result := make([]T, len(drawn))
+	for j, i := range drawn {
+		result[j] = s.items[i]
+		s.weights[i] = weights[j]
+		s.tree.Add(i, weights[j])
+	}
+
+	return result
+}
+
+// New returns an empty sampler that draws from the provided source of
+// randomness, or from one seeded by the time if r is nil.
+func New[T comparable](r *rand.Rand) *Sampler[T] {
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return &Sampler[T]{
+		tree:  fenwick.New[float64](0),
+		index: make(map[T]int),
+		rand:  r,
+	}
+}
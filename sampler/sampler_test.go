@@ -0,0 +1,209 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sampler
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSampler() *Sampler[string] {
+	return New[string](rand.New(rand.NewSource(1)))
+}
+
+// checkTree checks that every prefix sum of the tree matches the
+// weights.
+func checkTree(t *testing.T, s *Sampler[string]) {
+	sum := 0.0
+	for i, w := range s.weights {
+		sum += w
+		assert.True(t, math.Abs(sum-s.tree.PrefixSum(i+1)) < 1e-9)
+		assert.Equal(t, i, s.index[s.items[i]])
+	}
+	assert.Len(t, s.index, len(s.items))
+	assert.Equal(t, len(s.items), s.tree.Len())
+}
+
+func TestSampleEmpty(t *testing.T) {
+	s := newTestSampler()
+	_, ok := s.Sample()
+	assert.False(t, ok)
+	assert.Len(t, s.SampleWithoutReplacement(3), 0)
+
+	s.Add(`a`, 0)
+	_, ok = s.Sample()
+	assert.False(t, ok)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestSampleDistribution(t *testing.T) {
+	s := newTestSampler()
+	s.Add(`a`, 1)
+	s.Add(`b`, 3)
+	s.Add(`c`, 0)
+	s.Add(`d`, 6)
+	assert.Equal(t, 10.0, s.Total())
+
+	counts := map[string]int{}
+	n := 100000
+	for i := 0; i < n; i++ {
+		item, ok := s.Sample()
+		assert.True(t, ok)
+		counts[item]++
+	}
+
+	assert.Equal(t, 0, counts[`c`])
+	for item, expected := range map[string]float64{`a`: .1, `b`: .3, `d`: .6} {
+		actual := float64(counts[item]) / float64(n)
+		assert.True(t, math.Abs(actual-expected) < .01, item)
+	}
+}
+
+func TestUpdateWeight(t *testing.T) {
+	s := newTestSampler()
+	s.Add(`a`, 1)
+	s.Add(`b`, 1)
+
+	assert.True(t, s.UpdateWeight(`a`, 0))
+	assert.False(t, s.UpdateWeight(`c`, 1))
+	for i := 0; i < 100; i++ {
+		item, _ := s.Sample()
+		assert.Equal(t, `b`, item)
+	}
+
+	s.Add(`b`, 5)
+	w, ok := s.Weight(`b`)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, w)
+	_, ok = s.Weight(`c`)
+	assert.False(t, ok)
+	assert.Equal(t, 2, s.Len())
+}
+
+func TestRemove(t *testing.T) {
+	s := newTestSampler()
+	s.Add(`a`, 1)
+	s.Add(`b`, 2)
+	s.Add(`c`, 4)
+
+	assert.True(t, s.Remove(`a`))
+	assert.False(t, s.Remove(`a`))
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, 6.0, s.Total())
+	checkTree(t, s)
+
+	for i := 0; i < 100; i++ {
+		item, _ := s.Sample()
+		assert.NotEqual(t, `a`, item)
+	}
+
+	assert.True(t, s.Remove(`c`))
+	assert.True(t, s.Remove(`b`))
+	assert.Equal(t, 0.0, s.Total())
+	_, ok := s.Sample()
+	assert.False(t, ok)
+}
+
+func TestSampleWithoutReplacement(t *testing.T) {
+	s := newTestSampler()
+	s.Add(`a`, 1)
+	s.Add(`b`, 100)
+	s.Add(`c`, 10)
+	s.Add(`d`, 0)
+
+	first := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		items := s.SampleWithoutReplacement(5)
+		first[items[0]]++
+		slices.Sort(items)
+		assert.Equal(t, []string{`a`, `b`, `c`}, items)
+	}
+	assert.True(t, first[`b`] > first[`c`])
+	assert.True(t, first[`c`] > first[`a`])
+
+	assert.Len(t, s.SampleWithoutReplacement(2), 2)
+	assert.Equal(t, 111.0, s.Total())
+	w, _ := s.Weight(`b`)
+	assert.Equal(t, 100.0, w)
+	checkTree(t, s)
+}
+
+func TestRandomOperations(t *testing.T) {
+	s := newTestSampler()
+	r := rand.New(rand.NewSource(2))
+	model := map[string]float64{}
+	keys := []string{`a`, `b`, `c`, `d`, `e`, `f`, `g`, `h`, `i`, `j`, `k`}
+
+	for i := 0; i < 5000; i++ {
+		key := keys[r.Intn(len(keys))]
+		switch r.Intn(3) {
+		case 0:
+			w := r.Float64() * 10
+			s.Add(key, w)
+			model[key] = w
+		case 1:
+			w := r.Float64() * 10
+			_, ok := model[key]
+			assert.Equal(t, ok, s.UpdateWeight(key, w))
+			if ok {
+				model[key] = w
+			}
+		case 2:
+			_, ok := model[key]
+			assert.Equal(t, ok, s.Remove(key))
+			delete(model, key)
+		}
+
+		total := 0.0
+		for _, w := range model {
+			total += w
+		}
+		assert.Equal(t, len(model), s.Len())
+		assert.True(t, math.Abs(total-s.Total()) < 1e-9)
+		if item, ok := s.Sample(); ok {
+			assert.True(t, model[item] > 0)
+		}
+	}
+
+	checkTree(t, s)
+}
+
+func TestInvalidWeight(t *testing.T) {
+	s := newTestSampler()
+	assert.Panics(t, func() { s.Add(`a`, -1) })
+	assert.Panics(t, func() { s.Add(`a`, math.NaN()) })
+	assert.Panics(t, func() { s.Add(`a`, math.Inf(1)) })
+	assert.Equal(t, 0, s.Len())
+}
+
+func BenchmarkUpdateAndSample(b *testing.B) {
+	numItems := 100000
+	s := New[int](rand.New(rand.NewSource(1)))
+	for i := 0; i < numItems; i++ {
+		s.Add(i, float64(i%100+1))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.UpdateWeight(i%numItems, float64(i%50+1))
+		s.Sample()
+	}
+}
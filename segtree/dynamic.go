@@ -0,0 +1,213 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package segtree
+
+// dnode is a node of a dynamic tree.  A missing child covers a range
+// that has never been updated and so holds only zeros.
+type dnode[T Number] struct {
+	summary[T]
+	left, right *dnode[T]
+}
+
+// Dynamic is a segment tree over the int64 positions [lo, hi) that
+// only allocates nodes for the parts of the range that have been
+// updated.
+type Dynamic[T Number] struct {
+	lo, hi int64
+	root   *dnode[T]
+	nodes  int
+}
+
+func (d *Dynamic[T]) newNode() *dnode[T] {
+	d.nodes++
+	return &dnode[T]{}
+}
+
+// push passes the pending update of n, which covers [lo, hi), on to
+// its children, creating them if need be.
+func (d *Dynamic[T]) push(n *dnode[T], lo, mid, hi int64) {
+	if !n.tag.pending() {
+		return
+	}
+
+	if n.left == nil {
+		n.left = d.newNode()
+	}
+	if n.right == nil {
+		n.right = d.newNode()
+	}
+	n.left.apply(n.tag, T(mid-lo))
+	n.right.apply(n.tag, T(hi-mid))
+	n.tag = tag[T]{}
+}
+
+// agg returns the aggregate of n, which is all zeros if n is
+// missing.
+func (d *Dynamic[T]) agg(n *dnode[T]) Aggregate[T] {
+	if n == nil {
+		return Aggregate[T]{}
+	}
+
+	return n.agg
+}
+
+func (d *Dynamic[T]) update(n *dnode[T], lo, hi, l, r int64, u update[T]) *dnode[T] {
+	if n == nil {
+		n = d.newNode()
+	}
+
+	if l <= lo && hi <= r {
+		u.applyTo(&n.summary, T(hi-lo))
+		if u.assign {
+			// the assignment replaces everything below
+			d.nodes -= count(n.left) + count(n.right)
+			n.left, n.right = nil, nil
+		}
+		return n
+	}
+
+	mid := lo + (hi-lo)/2
+	d.push(n, lo, mid, hi)
+	if l < mid {
+		n.left = d.update(n.left, lo, mid, l, r, u)
+	}
+	if r > mid {
+		n.right = d.update(n.right, mid, hi, l, r, u)
+	}
+	n.agg = d.agg(n.left).combine(d.agg(n.right))
+	return n
+}
+
+// count returns the number of nodes in the subtree rooted at n.
+func count[T Number](n *dnode[T]) int {
+	if n == nil {
+		return 0
+	}
+
+	return 1 + count(n.left) + count(n.right)
+}
+
+func (d *Dynamic[T]) query(n *dnode[T], lo, hi, l, r int64) Aggregate[T] {
+	if n == nil {
+		return Aggregate[T]{}
+	}
+
+	if l <= lo && hi <= r {
+		return n.agg
+	}
+
+	mid := lo + (hi-lo)/2
+	d.push(n, lo, mid, hi)
+	switch {
+	case r <= mid:
+		return d.query(n.left, lo, mid, l, r)
+	case l >= mid:
+		return d.query(n.right, mid, hi, l, r)
+	}
+
+	return d.query(n.left, lo, mid, l, r).combine(d.query(n.right, mid, hi, l, r))
+}
+
+// clamp limits [l, r) to the positions in the tree and returns false
+// if the result is empty.
+func (d *Dynamic[T]) clamp(l, r int64) (int64, int64, bool) {
+	l, r = max(l, d.lo), min(r, d.hi)
+	return l, r, l < r
+}
+
+func (d *Dynamic[T]) modify(l, r int64, u update[T]) {
+	if l, r, ok := d.clamp(l, r); ok {
+		d.root = d.update(d.root, d.lo, d.hi, l, r, u)
+	}
+}
+
+// Assign sets every position in [l, r) to v.  Positions outside the
+// tree are ignored.
+func (d *Dynamic[T]) Assign(l, r int64, v T) {
+	d.modify(l, r, update[T]{assign: true, value: v})
+}
+
+// Add adds v to every position in [l, r).  Positions outside the
+// tree are ignored.
+func (d *Dynamic[T]) Add(l, r int64, v T) {
+	d.modify(l, r, update[T]{value: v})
+}
+
+// Set sets the value at position i.
+func (d *Dynamic[T]) Set(i int64, v T) {
+	d.Assign(i, i+1, v)
+}
+
+// Query returns the sum, minimum and maximum of the values in [l, r).
+// Positions outside the tree are ignored and false is returned if no
+// position remains.
+func (d *Dynamic[T]) Query(l, r int64) (Aggregate[T], bool) {
+	l, r, ok := d.clamp(l, r)
+	if !ok {
+		return Aggregate[T]{}, false
+	}
+
+	return d.query(d.root, d.lo, d.hi, l, r), true
+}
+
+// Sum returns the sum of the values in [l, r), which is zero if the
+// range is empty.
+func (d *Dynamic[T]) Sum(l, r int64) T {
+	agg, _ := d.Query(l, r)
+	return agg.Sum
+}
+
+// Min returns the smallest value in [l, r), or zero if the range is
+// empty.
+func (d *Dynamic[T]) Min(l, r int64) T {
+	agg, _ := d.Query(l, r)
+	return agg.Min
+}
+
+// Max returns the largest value in [l, r), or zero if the range is
+// empty.
+func (d *Dynamic[T]) Max(l, r int64) T {
+	agg, _ := d.Query(l, r)
+	return agg.Max
+}
+
+// Get returns the value at position i, or zero if i is out of
+// bounds.
+func (d *Dynamic[T]) Get(i int64) T {
+	return d.Sum(i, i+1)
+}
+
+// Bounds returns the range of positions the tree covers.
+func (d *Dynamic[T]) Bounds() (int64, int64) {
+	return d.lo, d.hi
+}
+
+// Nodes returns the number of nodes the tree has allocated.
+func (d *Dynamic[T]) Nodes() int {
+	return d.nodes
+}
+
+// NewDynamic returns a segment tree over the positions [lo, hi), each
+// holding zero.  hi must be greater than lo and hi-lo must not
+// overflow an int64.
+func NewDynamic[T Number](lo, hi int64) *Dynamic[T] {
+	if hi <= lo || hi-lo < 0 {
+		panic(`Dynamic segment tree range must be non-empty and fit in an int64.`)
+	}
+
+	return &Dynamic[T]{lo: lo, hi: hi}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package segtree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicLargeRange(t *testing.T) {
+	d := NewDynamic[int64](math.MinInt64/2, math.MaxInt64/2)
+	assert.Equal(t, int64(0), d.Sum(math.MinInt64, math.MaxInt64))
+
+	d.Assign(1e15, 1e15+1000, 2)
+	d.Add(-1e12, 1e15+500, 1)
+	assert.Equal(t, int64(3), d.Get(1e15+499))
+	assert.Equal(t, int64(2), d.Get(1e15+500))
+	assert.Equal(t, int64(1), d.Get(0))
+	assert.Equal(t, int64(0), d.Get(-1e12-1))
+	assert.Equal(t, int64(3), d.Max(math.MinInt64, math.MaxInt64))
+	assert.Equal(t, int64(0), d.Min(math.MinInt64, math.MaxInt64))
+	assert.Equal(t, int64(1e15+1e12+500+1000*2), d.Sum(math.MinInt64, math.MaxInt64))
+
+	// nodes are only created along the edges of updated ranges
+	assert.True(t, d.Nodes() < 500)
+}
+
+func TestDynamicAssignReleasesNodes(t *testing.T) {
+	d := NewDynamic[int](0, 1<<20)
+	for i := int64(0); i < 100; i++ {
+		d.Set(i*1000, 1)
+	}
+	assert.True(t, d.Nodes() > 100)
+
+	d.Assign(0, 1<<20, 7)
+	assert.Equal(t, 1, d.Nodes())
+	assert.Equal(t, 7<<20, d.Sum(0, 1<<20))
+}
+
+func TestDynamicBounds(t *testing.T) {
+	d := NewDynamic[int](-5, 5)
+	lo, hi := d.Bounds()
+	assert.Equal(t, int64(-5), lo)
+	assert.Equal(t, int64(5), hi)
+
+	d.Add(-100, 100, 1)
+	assert.Equal(t, 10, d.Sum(-100, 100))
+	_, ok := d.Query(5, 10)
+	assert.False(t, ok)
+}
+
+func TestDynamicPanics(t *testing.T) {
+	assert.Panics(t, func() { NewDynamic[int](5, 5) })
+	assert.Panics(t, func() { NewDynamic[int](math.MinInt64, math.MaxInt64) })
+}
+
+func TestDynamicRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	const lo, n = -50, 100
+	d, values := NewDynamic[int](lo, lo+n), make(naive, n)
+	for i := 0; i < 5000; i++ {
+		l := r.Intn(n)
+		r := l + 1 + r.Intn(n-l)
+		v := rand.Intn(21) - 10
+		switch i % 3 {
+		case 0:
+			d.Assign(int64(lo+l), int64(lo+r), v)
+			values.assign(l, r, v)
+		case 1:
+			d.Add(int64(lo+l), int64(lo+r), v)
+			values.add(l, r, v)
+		case 2:
+			agg, ok := d.Query(int64(lo+l), int64(lo+r))
+			assert.True(t, ok)
+			if !assert.Equal(t, values.query(l, r), agg) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package segtree implements segment trees, which hold a number at every
+position of a range and support updating and aggregating whole ranges
+of positions at once.  A range can be assigned a value or have a value
+added to it, and the sum, minimum and maximum of any range can be
+queried.  Updates are applied lazily: a node covering an updated range
+records the update and only passes it on to its children when a later
+operation needs to look inside it, so both updates and queries touch
+O(log n) nodes.
+
+Tree covers the positions [0, n) and is kept in a single slice.
+Dynamic covers a range of int64 positions that may be far too large
+to allocate, such as timestamps, and creates nodes only where updates
+land.  Positions that have never been updated hold zero, so there is
+no need to compress coordinates beforehand.  A Dynamic whose ranges
+are only ever assigned doubles as an interval map.
+
+Ranges are half open, so [l, r) covers l through r-1.  Neither tree is
+threadsafe.
+
+Performance characteristics:
+Assign/Add: O(log n)
+Query/Sum/Min/Max/Get: O(log n)
+Space: O(n) for Tree, O(u log n) for Dynamic after u updates
+*/
+package segtree
+
+// Number is the set of types a segment tree can hold.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Aggregate summarizes the values in a range.
+type Aggregate[T Number] struct {
+	Sum, Min, Max T
+}
+
+// combine returns the aggregate of two adjacent ranges.
+func (a Aggregate[T]) combine(other Aggregate[T]) Aggregate[T] {
+	return Aggregate[T]{
+		Sum: a.Sum + other.Sum,
+		Min: min(a.Min, other.Min),
+		Max: max(a.Max, other.Max),
+	}
+}
+
+// tag is an update that has been applied to a node but not yet to
+// its children.  If assigned is set every value in the node was set
+// to value, which already includes any later additions, and add is
+// zero.
+type tag[T Number] struct {
+	assigned bool
+	value    T
+	add      T
+}
+
+func (t tag[T]) pending() bool {
+	return t.assigned || t.add != 0
+}
+
+// summary is the state of a node that covers a range of positions.
+type summary[T Number] struct {
+	agg Aggregate[T]
+	tag tag[T]
+}
+
+// assign sets every value in the node, which covers length positions,
+// to v.
+func (s *summary[T]) assign(v, length T) {
+	s.agg = Aggregate[T]{Sum: v * length, Min: v, Max: v}
+	s.tag = tag[T]{assigned: true, value: v}
+}
+
+// add adds v to every value in the node, which covers length
+// positions.
+func (s *summary[T]) add(v, length T) {
+	s.agg.Sum += v * length
+	s.agg.Min += v
+	s.agg.Max += v
+	if s.tag.assigned {
+		s.tag.value += v
+	} else {
+		s.tag.add += v
+	}
+}
+
+// apply applies a parent's pending update to the node.
+func (s *summary[T]) apply(t tag[T], length T) {
+	if t.assigned {
+		s.assign(t.value, length)
+	} else if t.add != 0 {
+		s.add(t.add, length)
+	}
+}
+
+// update describes an update to a range: an assignment of value if
+// assign is set and an addition of value otherwise.
+type update[T Number] struct {
+	assign bool
+	value  T
+}
+
+func (u update[T]) applyTo(s *summary[T], length T) {
+	if u.assign {
+		s.assign(u.value, length)
+	} else {
+		s.add(u.value, length)
+	}
+}
+
+// Tree is a segment tree over the positions [0, n).
+type Tree[T Number] struct {
+	n int
+	// nodes holds the tree in heap order, the children of node i
+	// being 2i+1 and 2i+2.
+	nodes []summary[T]
+}
+
+func (t *Tree[T]) build(i, lo, hi int, values []T) {
+	if hi-lo == 1 {
+		v := values[lo]
+		t.nodes[i].agg = Aggregate[T]{Sum: v, Min: v, Max: v}
+		return
+	}
+
+	mid := lo + (hi-lo)/2
+	t.build(2*i+1, lo, mid, values)
+	t.build(2*i+2, mid, hi, values)
+	t.nodes[i].agg = t.nodes[2*i+1].agg.combine(t.nodes[2*i+2].agg)
+}
+
+// push passes the pending update of node i, which covers [lo, hi),
+// on to its children.
+func (t *Tree[T]) push(i, lo, mid, hi int) {
+	s := &t.nodes[i]
+	if !s.tag.pending() {
+		return
+	}
+
+	t.nodes[2*i+1].apply(s.tag, T(mid-lo))
+	t.nodes[2*i+2].apply(s.tag, T(hi-mid))
+	s.tag = tag[T]{}
+}
+
+func (t *Tree[T]) update(i, lo, hi, l, r int, u update[T]) {
+	if l <= lo && hi <= r {
+		u.applyTo(&t.nodes[i], T(hi-lo))
+		return
+	}
+
+	mid := lo + (hi-lo)/2
+	t.push(i, lo, mid, hi)
+	if l < mid {
+		t.update(2*i+1, lo, mid, l, r, u)
+	}
+	if r > mid {
+		t.update(2*i+2, mid, hi, l, r, u)
+	}
+	t.nodes[i].agg = t.nodes[2*i+1].agg.combine(t.nodes[2*i+2].agg)
+}
+
+func (t *Tree[T]) query(i, lo, hi, l, r int) Aggregate[T] {
+	if l <= lo && hi <= r {
+		return t.nodes[i].agg
+	}
+
+	mid := lo + (hi-lo)/2
+	t.push(i, lo, mid, hi)
+	switch {
+	case r <= mid:
+		return t.query(2*i+1, lo, mid, l, r)
+	case l >= mid:
+		return t.query(2*i+2, mid, hi, l, r)
+	}
+
+	return t.query(2*i+1, lo, mid, l, r).combine(t.query(2*i+2, mid, hi, l, r))
+}
+
+// clamp limits [l, r) to the positions in the tree and returns false
+// if the result is empty.
+func (t *Tree[T]) clamp(l, r int) (int, int, bool) {
+	l, r = max(l, 0), min(r, t.n)
+	return l, r, l < r
+}
+
+func (t *Tree[T]) modify(l, r int, u update[T]) {
+	if l, r, ok := t.clamp(l, r); ok {
+		t.update(0, 0, t.n, l, r, u)
+	}
+}
+
+// Assign sets every position in [l, r) to v.  Positions outside the
+// tree are ignored.
+func (t *Tree[T]) Assign(l, r int, v T) {
+	t.modify(l, r, update[T]{assign: true, value: v})
+}
+
+// Add adds v to every position in [l, r).  Positions outside the
+// tree are ignored.
+func (t *Tree[T]) Add(l, r int, v T) {
+	t.modify(l, r, update[T]{value: v})
+}
+
+// Set sets the value at position i.
+func (t *Tree[T]) Set(i int, v T) {
+	t.Assign(i, i+1, v)
+}
+
+// Query returns the sum, minimum and maximum of the values in [l, r).
+// Positions outside the tree are ignored and false is returned if no
+// position remains.
+func (t *Tree[T]) Query(l, r int) (Aggregate[T], bool) {
+	l, r, ok := t.clamp(l, r)
+	if !ok {
+		return Aggregate[T]{}, false
+	}
+
+	return t.query(0, 0, t.n, l, r), true
+}
+
+// Sum returns the sum of the values in [l, r), which is zero if the
+// range is empty.
+func (t *Tree[T]) Sum(l, r int) T {
+	agg, _ := t.Query(l, r)
+	return agg.Sum
+}
+
+// Min returns the smallest value in [l, r), or zero if the range is
+// empty.
+func (t *Tree[T]) Min(l, r int) T {
+	agg, _ := t.Query(l, r)
+	return agg.Min
+}
+
+// Max returns the largest value in [l, r), or zero if the range is
+// empty.
+func (t *Tree[T]) Max(l, r int) T {
+	agg, _ := t.Query(l, r)
+	return agg.Max
+}
+
+// Get returns the value at position i, or zero if i is out of
+// bounds.
+func (t *Tree[T]) Get(i int) T {
+	return t.Sum(i, i+1)
+}
+
+// Len returns the number of positions in the tree.
+func (t *Tree[T]) Len() int {
+	return t.n
+}
+
+// New returns a segment tree over the positions [0, n), each holding
+// zero.
+func New[T Number](n int) *Tree[T] {
+	return NewFromSlice(make([]T, max(n, 0)))
+}
+
+// NewFromSlice returns a segment tree holding the provided values at
+// the positions [0, len(values)).  This is an O(n) operation.
+func NewFromSlice[T Number](values []T) *Tree[T] {
+	t := &Tree[T]{n: len(values)}
+	if t.n == 0 {
+		return t
+	}
+
+	size := 1
+	for size < t.n {
+		size *= 2
+	}
+	t.nodes = make([]summary[T], 2*size-1)
+	t.build(0, 0, t.n, values)
+	return t
+}
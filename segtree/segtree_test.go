@@ -0,0 +1,173 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package segtree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naive is a reference implementation that updates and scans every
+// position.
+type naive []int
+
+func (values naive) assign(l, r, v int) {
+	for i := l; i < r; i++ {
+		values[i] = v
+	}
+}
+
+func (values naive) add(l, r, v int) {
+	for i := l; i < r; i++ {
+		values[i] += v
+	}
+}
+
+func (values naive) query(l, r int) Aggregate[int] {
+	agg := Aggregate[int]{Min: values[l], Max: values[l]}
+	for _, v := range values[l:r] {
+		agg.Sum += v
+		agg.Min = min(agg.Min, v)
+		agg.Max = max(agg.Max, v)
+	}
+
+	return agg
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := New[int](0)
+	assert.Equal(t, 0, tree.Len())
+
+	tree.Assign(0, 10, 5)
+	tree.Add(0, 10, 5)
+	_, ok := tree.Query(0, 10)
+	assert.False(t, ok)
+	assert.Equal(t, 0, tree.Sum(0, 10))
+}
+
+func TestNewFromSlice(t *testing.T) {
+	tree := NewFromSlice([]int{5, 3, 8, 1, 9, 2, 7})
+	assert.Equal(t, 7, tree.Len())
+
+	agg, ok := tree.Query(0, 7)
+	assert.True(t, ok)
+	assert.Equal(t, Aggregate[int]{Sum: 35, Min: 1, Max: 9}, agg)
+
+	agg, ok = tree.Query(1, 3)
+	assert.True(t, ok)
+	assert.Equal(t, Aggregate[int]{Sum: 11, Min: 3, Max: 8}, agg)
+
+	for i, v := range []int{5, 3, 8, 1, 9, 2, 7} {
+		assert.Equal(t, v, tree.Get(i))
+	}
+}
+
+func TestAssignAdd(t *testing.T) {
+	tree := New[int](10)
+	tree.Assign(2, 8, 3)
+	tree.Add(4, 10, 2)
+
+	assert.Equal(t, 3, tree.Get(3))
+	assert.Equal(t, 5, tree.Get(4))
+	assert.Equal(t, 2, tree.Get(9))
+	assert.Equal(t, 0, tree.Get(0))
+	assert.Equal(t, 0, tree.Min(0, 10))
+	assert.Equal(t, 5, tree.Max(0, 10))
+	assert.Equal(t, 3*2+5*4+2*2, tree.Sum(0, 10))
+
+	// an assignment replaces earlier additions
+	tree.Assign(0, 10, 1)
+	assert.Equal(t, 10, tree.Sum(0, 10))
+	tree.Set(5, -4)
+	assert.Equal(t, -4, tree.Min(0, 10))
+	assert.Equal(t, 5, tree.Sum(0, 10))
+}
+
+func TestClamp(t *testing.T) {
+	tree := New[int](5)
+	tree.Add(-10, 100, 1)
+	assert.Equal(t, 5, tree.Sum(-1, 6))
+
+	_, ok := tree.Query(3, 3)
+	assert.False(t, ok)
+	_, ok = tree.Query(5, 10)
+	assert.False(t, ok)
+	assert.Equal(t, 0, tree.Get(-1))
+}
+
+func TestFloat(t *testing.T) {
+	tree := NewFromSlice([]float64{0.5, 1.5, 2.5})
+	tree.Add(0, 3, 0.25)
+	assert.Equal(t, 5.25, tree.Sum(0, 3))
+	assert.Equal(t, 0.75, tree.Min(0, 3))
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, n := range []int{1, 2, 7, 64, 100} {
+		tree, values := New[int](n), make(naive, n)
+		for i := 0; i < 2000; i++ {
+			l := r.Intn(n)
+			r := l + 1 + r.Intn(n-l)
+			v := rand.Intn(21) - 10
+			switch i % 3 {
+			case 0:
+				tree.Assign(l, r, v)
+				values.assign(l, r, v)
+			case 1:
+				tree.Add(l, r, v)
+				values.add(l, r, v)
+			case 2:
+				agg, ok := tree.Query(l, r)
+				assert.True(t, ok)
+				if !assert.Equal(t, values.query(l, r), agg) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	const n = 1 << 20
+	tree := New[int](n)
+	r := rand.New(rand.NewSource(0))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := r.Intn(n)
+		tree.Add(l, l+r.Intn(n-l)+1, 1)
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	const n = 1 << 20
+	tree := New[int](n)
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		l := r.Intn(n)
+		tree.Add(l, l+r.Intn(n-l)+1, 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := r.Intn(n)
+		tree.Query(l, l+r.Intn(n-l)+1)
+	}
+}
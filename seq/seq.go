@@ -0,0 +1,109 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package seq bridges the iterators found throughout this library and
+Go's range-over-func sequences, and provides adapters that compose
+sequences lazily.  Structures expose their contents as an iter.Seq
+through an All method, and any iterator with the library's Next/Value
+shape can be turned into one with FromIterator, so results from
+different structures can be filtered, mapped and collected the same
+way:
+
+	evens := seq.Collect(seq.Filter(tree.All(), isEven))
+
+No adapter buffers more than a single value.
+*/
+package seq
+
+import "iter"
+
+// Iterator is the shape shared by the iterators in this library, such
+// as skip.Iterator, plus.IteratorG and rangetree.Iterator.  Next moves
+// to the next value and returns false once there are no more, and
+// Value returns the value at the current position.
+type Iterator[T any] interface {
+	Next() bool
+	Value() T
+}
+
+// FromIterator returns a sequence of the values remaining in the
+// provided iterator.  The iterator is consumed as the sequence is
+// ranged over, so the sequence can only be used once.
+func FromIterator[T any](it Iterator[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			if !yield(it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a sequence of the values in s for which keep returns
+// true.
+func Filter[T any](s iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range s {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns a sequence of the results of calling fn with every
+// value in s.
+func Map[T, U any](s iter.Seq[T], fn func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range s {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Take returns a sequence of at most the first n values in s.  s is
+// not advanced past the nth value.
+func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		i := 0
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i >= n {
+				return
+			}
+		}
+	}
+}
+
+// Collect returns the values in s as a slice.
+func Collect[T any](s iter.Seq[T]) []T {
+	var values []T
+	for v := range s {
+		values = append(values, v)
+	}
+
+	return values
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package seq
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/btree/plus"
+	"github.com/Workiva/go-datastructures/rangetree"
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// the library's iterators all share the Iterator shape
+var (
+	_ Iterator[skip.Entry]      = skip.Iterator(nil)
+	_ Iterator[int]             = (*plus.IteratorG[int])(nil)
+	_ Iterator[rangetree.Entry] = rangetree.Iterator(nil)
+)
+
+type sliceIterator struct {
+	values []int
+	index  int
+	nexts  int
+}
+
+func (si *sliceIterator) Next() bool {
+	si.nexts++
+	if si.index >= len(si.values) {
+		return false
+	}
+	si.index++
+	return true
+}
+
+func (si *sliceIterator) Value() int {
+	return si.values[si.index-1]
+}
+
+func count(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestFromIterator(t *testing.T) {
+	it := &sliceIterator{values: []int{1, 2, 3}}
+	assert.Equal(t, []int{1, 2, 3}, Collect(FromIterator[int](it)))
+
+	it = &sliceIterator{values: []int{1, 2, 3}}
+	for v := range FromIterator[int](it) {
+		if v == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, it.nexts)
+}
+
+func TestFilter(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, Collect(Filter(count(10), even)))
+	assert.Nil(t, Collect(Filter(count(0), even)))
+}
+
+func TestMap(t *testing.T) {
+	letter := func(v int) string { return string(rune('a' + v)) }
+	assert.Equal(t, []string{`a`, `b`, `c`}, Collect(Map(count(3), letter)))
+}
+
+func TestTake(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 2}, Collect(Take(count(10), 3)))
+	assert.Equal(t, []int{0, 1}, Collect(Take(count(2), 3)))
+	assert.Nil(t, Collect(Take(count(10), 0)))
+
+	it := &sliceIterator{values: []int{1, 2, 3, 4}}
+	assert.Equal(t, []int{1, 2}, Collect(Take(FromIterator[int](it), 2)))
+	assert.Equal(t, 2, it.nexts)
+}
+
+func TestCompose(t *testing.T) {
+	tree := plus.NewG(func(a, b int) bool { return a < b }, 4)
+	for i := 0; i < 100; i++ {
+		tree.Insert(i)
+	}
+
+	odd := func(v int) bool { return v%2 == 1 }
+	square := func(v int) int { return v * v }
+	assert.Equal(t, []int{1, 9, 25, 49}, Collect(Take(Map(Filter(tree.All(), odd), square), 4)))
+	assert.Equal(t, []int{91, 93}, Collect(Take(Filter(FromIterator[int](tree.Iter(90)), odd), 2)))
+}
+
+func TestEarlyStop(t *testing.T) {
+	s := Map(Filter(count(100), func(int) bool { return true }), func(v int) int { return v })
+	seen := 0
+	for range s {
+		seen++
+		if seen == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, seen)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import "iter"
+
+// Copy returns a new set holding the items of this set.
+func (set *TypedSet[T]) Copy() *TypedSet[T] {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	result := &TypedSet[T]{items: make(map[T]struct{}, len(set.items))}
+	for item := range set.items {
+		result.items[item] = struct{}{}
+	}
+
+	return result
+}
+
+// Union returns a new set holding the items in either this set or
+// the other.
+func (set *TypedSet[T]) Union(other *TypedSet[T]) *TypedSet[T] {
+	items := other.Flatten()
+	result := set.Copy()
+	for _, item := range items {
+		result.items[item] = struct{}{}
+	}
+
+	return result
+}
+
+// Intersection returns a new set holding the items in both this set
+// and the other.
+func (set *TypedSet[T]) Intersection(other *TypedSet[T]) *TypedSet[T] {
+	items := other.Flatten()
+
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	result := NewOf[T]()
+	for _, item := range items {
+		if _, ok := set.items[item]; ok {
+			result.items[item] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// Difference returns a new set holding the items in this set that
+// are not in the other.
+func (set *TypedSet[T]) Difference(other *TypedSet[T]) *TypedSet[T] {
+	items := other.Flatten()
+	result := set.Copy()
+	for _, item := range items {
+		delete(result.items, item)
+	}
+
+	return result
+}
+
+// SymmetricDifference returns a new set holding the items in exactly
+// one of this set and the other.
+func (set *TypedSet[T]) SymmetricDifference(other *TypedSet[T]) *TypedSet[T] {
+	items := other.Flatten()
+	result := set.Copy()
+	for _, item := range items {
+		if _, ok := result.items[item]; ok {
+			delete(result.items, item)
+		} else {
+			result.items[item] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// IsSubset returns a bool indicating if every item in this set is
+// also in the other.
+func (set *TypedSet[T]) IsSubset(other *TypedSet[T]) bool {
+	return other.All(set.Flatten()...)
+}
+
+// IsSuperset returns a bool indicating if every item in the other
+// set is also in this one.
+func (set *TypedSet[T]) IsSuperset(other *TypedSet[T]) bool {
+	return set.All(other.Flatten()...)
+}
+
+// Equal returns a bool indicating if this set and the other hold the
+// same items.
+func (set *TypedSet[T]) Equal(other *TypedSet[T]) bool {
+	items := set.Flatten()
+
+	other.lock.RLock()
+	defer other.lock.RUnlock()
+
+	if len(items) != len(other.items) {
+		return false
+	}
+
+	for _, item := range items {
+		if _, ok := other.items[item]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Filter returns a new set holding the items of this set for which
+// the provided predicate returns true.
+func (set *TypedSet[T]) Filter(fn func(T) bool) *TypedSet[T] {
+	result := NewOf[T]()
+	for _, item := range set.Flatten() {
+		if fn(item) {
+			result.items[item] = struct{}{}
+		}
+	}
+
+	return result
+}
+
+// Each calls the provided function with every item in the set, in no
+// particular order, until the function returns false.  The items
+// visited are those in the set when Each was called, so the function
+// may safely modify the set.
+func (set *TypedSet[T]) Each(fn func(T) bool) {
+	for _, item := range set.Flatten() {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// Values returns a sequence of the items in the set, in no particular
+// order, for use with range.  Like Each, the items visited are those
+// in the set when ranging began.  The name All is already taken by
+// the existence check.
+func (set *TypedSet[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		set.Each(yield)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func sorted(set *TypedSet[int]) []int {
+	items := append([]int{}, set.Flatten()...)
+	sort.Ints(items)
+	return items
+}
+
+func TestNewOf(t *testing.T) {
+	set := NewOf(3, 1, 2, 1)
+
+	if set.Len() != 3 {
+		t.Errorf(`Expected len: %d, received: %d`, 3, set.Len())
+	}
+
+	if !set.Exists(2) || set.Exists(4) {
+		t.Errorf(`Correct existence not determined.`)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	result := NewOf(1, 2, 3).Union(NewOf(3, 4))
+
+	if !reflect.DeepEqual([]int{1, 2, 3, 4}, sorted(result)) {
+		t.Errorf(`Incorrect result returned: %+v`, sorted(result))
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	result := NewOf(1, 2, 3).Intersection(NewOf(2, 3, 4))
+
+	if !reflect.DeepEqual([]int{2, 3}, sorted(result)) {
+		t.Errorf(`Incorrect result returned: %+v`, sorted(result))
+	}
+
+	result = NewOf(1, 2).Intersection(NewOf(3))
+	if result.Len() != 0 {
+		t.Errorf(`Expected len: %d, received: %d`, 0, result.Len())
+	}
+}
+
+func TestDifference(t *testing.T) {
+	result := NewOf(1, 2, 3).Difference(NewOf(2, 4))
+
+	if !reflect.DeepEqual([]int{1, 3}, sorted(result)) {
+		t.Errorf(`Incorrect result returned: %+v`, sorted(result))
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	result := NewOf(1, 2, 3).SymmetricDifference(NewOf(2, 3, 4))
+
+	if !reflect.DeepEqual([]int{1, 4}, sorted(result)) {
+		t.Errorf(`Incorrect result returned: %+v`, sorted(result))
+	}
+}
+
+func TestAlgebraLeavesOperands(t *testing.T) {
+	a, b := NewOf(1, 2), NewOf(2, 3)
+	a.Union(b)
+	a.Intersection(b)
+	a.Difference(b)
+	a.SymmetricDifference(b)
+
+	if !reflect.DeepEqual([]int{1, 2}, sorted(a)) || !reflect.DeepEqual([]int{2, 3}, sorted(b)) {
+		t.Errorf(`Operands modified: %+v, %+v`, sorted(a), sorted(b))
+	}
+}
+
+func TestAlgebraWithSelf(t *testing.T) {
+	set := NewOf(1, 2)
+
+	if !reflect.DeepEqual([]int{1, 2}, sorted(set.Union(set))) {
+		t.Errorf(`Incorrect union with self.`)
+	}
+
+	if set.Difference(set).Len() != 0 || set.SymmetricDifference(set).Len() != 0 {
+		t.Errorf(`Expected empty difference with self.`)
+	}
+
+	if !set.IsSubset(set) || !set.IsSuperset(set) {
+		t.Errorf(`Expected set to be a subset and superset of itself.`)
+	}
+}
+
+func TestSubsetSuperset(t *testing.T) {
+	small, large := NewOf(1, 2), NewOf(1, 2, 3)
+
+	if !small.IsSubset(large) || small.IsSuperset(large) {
+		t.Errorf(`Incorrect subset relation determined.`)
+	}
+
+	if !large.IsSuperset(small) || large.IsSubset(small) {
+		t.Errorf(`Incorrect superset relation determined.`)
+	}
+
+	if !NewOf[int]().IsSubset(small) {
+		t.Errorf(`Expected empty set to be a subset.`)
+	}
+
+	if NewOf(1, 4).IsSubset(large) {
+		t.Errorf(`Expected disjoint item to prevent subset.`)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	set := NewOf(1, 2, 3)
+
+	if !set.Equal(NewOf(3, 2, 1)) || !set.Equal(set) {
+		t.Errorf(`Expected equal sets.`)
+	}
+
+	if set.Equal(NewOf(1, 2)) || set.Equal(NewOf(1, 2, 4)) || NewOf(1, 2).Equal(set) {
+		t.Errorf(`Expected unequal sets.`)
+	}
+
+	if !NewOf[int]().Equal(NewOf[int]()) {
+		t.Errorf(`Expected empty sets to be equal.`)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	result := NewOf(1, 2, 3, 4, 5).Filter(func(i int) bool {
+		return i%2 == 1
+	})
+
+	if !reflect.DeepEqual([]int{1, 3, 5}, sorted(result)) {
+		t.Errorf(`Incorrect result returned: %+v`, sorted(result))
+	}
+}
+
+func TestEach(t *testing.T) {
+	set := NewOf(1, 2, 3)
+	var seen []int
+	set.Each(func(i int) bool {
+		seen = append(seen, i)
+		// modifying the set while visiting must not deadlock
+		set.Add(i + 10)
+		return true
+	})
+
+	sort.Ints(seen)
+	if !reflect.DeepEqual([]int{1, 2, 3}, seen) {
+		t.Errorf(`Incorrect items visited: %+v`, seen)
+	}
+
+	count := 0
+	set.Each(func(int) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Errorf(`Expected iteration to stop after %d items, visited %d`, 2, count)
+	}
+}
+
+func TestValues(t *testing.T) {
+	set := NewOf(1, 2, 3)
+	var seen []int
+	for i := range set.Values() {
+		seen = append(seen, i)
+		set.Remove(i)
+	}
+
+	sort.Ints(seen)
+	if !reflect.DeepEqual([]int{1, 2, 3}, seen) {
+		t.Errorf(`Incorrect items visited: %+v`, seen)
+	}
+
+	set.Add(1, 2, 3)
+	count := 0
+	for range set.Values() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf(`Expected iteration to stop after %d items, visited %d`, 2, count)
+	}
+}
+
+func TestIter(t *testing.T) {
+	set := NewOf(1, 2, 3)
+	iter := set.Iter()
+	if iter.Value() != 0 {
+		t.Errorf(`Expected zero value before Next.`)
+	}
+
+	set.Add(4)
+	var seen []int
+	for iter.Next() {
+		seen = append(seen, iter.Value())
+	}
+
+	sort.Ints(seen)
+	if !reflect.DeepEqual([]int{1, 2, 3}, seen) {
+		t.Errorf(`Incorrect items visited: %+v`, seen)
+	}
+
+	if iter.Next() || iter.Value() != 0 {
+		t.Errorf(`Expected exhausted iterator.`)
+	}
+}
+
+func TestClearResetsCache(t *testing.T) {
+	set := NewOf(1)
+	set.Flatten()
+	set.Clear()
+
+	if len(set.Flatten()) != 0 {
+		t.Errorf(`Expected len: %d, received: %d`, 0, len(set.Flatten()))
+	}
+}
+
+func TestConcurrentAlgebra(t *testing.T) {
+	a, b := NewOf(1, 2, 3), NewOf(2, 3, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			a.Union(b)
+		}()
+		go func() {
+			defer wg.Done()
+			b.Intersection(a)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			a.Add(i)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			b.Add(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkUnion(b *testing.B) {
+	x, y := NewOf[int](), NewOf[int]()
+	for i := 0; i < 1000; i++ {
+		x.Add(i)
+		y.Add(i + 500)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Union(y)
+	}
+}
@@ -18,6 +18,12 @@ limitations under the License.
 Package set is a simple unordered set implemented with a map.  This set
 is threadsafe which decreases performance.
 
+TypedSet is a set of items of a single comparable type, with set
+algebra, filtering and iteration.  Set holds interface{} items and is
+a thin wrapper over a TypedSet[interface{}], drawn from a reusable
+pool.  Operations combining two sets never hold both locks at once,
+so they are safe to call concurrently in any order.
+
 TODO: Actually write custom hashmap using the hash/fnv hasher.
 */
 
@@ -29,103 +35,54 @@ var pool = sync.Pool{}
 
 // Set is an implementation of ISet using the builtin map type. Set is threadsafe.
 type Set struct {
-	items     map[interface{}]bool
-	lock      sync.RWMutex
-	flattened []interface{}
+	typed TypedSet[interface{}]
 }
 
 // Add will add the provided items to the set.
 func (set *Set) Add(items ...interface{}) {
-	set.lock.Lock()
-	defer set.lock.Unlock()
-
-	set.flattened = nil
-	for _, item := range items {
-		set.items[item] = true
-	}
+	set.typed.Add(items...)
 }
 
 // Remove will remove the given items from the set.
 func (set *Set) Remove(items ...interface{}) {
-	set.lock.Lock()
-	defer set.lock.Unlock()
-
-	set.flattened = nil
-	for _, item := range items {
-		delete(set.items, item)
-	}
+	set.typed.Remove(items...)
 }
 
 // Exists returns a bool indicating if the given item exists in the set.
 func (set *Set) Exists(item interface{}) bool {
-	set.lock.RLock()
-	defer set.lock.RUnlock()
-
-	_, ok := set.items[item]
-	return ok
+	return set.typed.Exists(item)
 }
 
 // Flatten will return a list of the items in the set.
 func (set *Set) Flatten() []interface{} {
-	set.lock.Lock()
-	defer set.lock.Unlock()
-
-	if set.flattened != nil {
-		return set.flattened
-	}
-
-	set.flattened = make([]interface{}, 0, len(set.items))
-	for item := range set.items {
-		set.flattened = append(set.flattened, item)
-	}
-	return set.flattened
+	return set.typed.Flatten()
 }
 
 // Len returns the number of items in the set.
 func (set *Set) Len() int64 {
-	set.lock.RLock()
-	defer set.lock.RUnlock()
+	return set.typed.Len()
+}
 
-	return int64(len(set.items))
+// RandomSample returns up to k distinct items chosen uniformly at
+// random from the set.  If k is greater than the number of items in
+// the set every item is returned.
+func (set *Set) RandomSample(k int) []interface{} {
+	return set.typed.RandomSample(k)
 }
 
 // Clear will remove all items from the set.
 func (set *Set) Clear() {
-	set.lock.Lock()
-	defer set.lock.Unlock()
-
-	set.items = map[interface{}]bool{}
+	set.typed.Clear()
 }
 
 // All returns a bool indicating if all of the supplied items exist in the set.
 func (set *Set) All(items ...interface{}) bool {
-	set.lock.RLock()
-	defer set.lock.RUnlock()
-
-	for _, item := range items {
-		if _, ok := set.items[item]; !ok {
-			return false
-		}
-	}
-
-	return true
+	return set.typed.All(items...)
 }
 
 // Dispose will add this set back into the pool.
 func (set *Set) Dispose() {
-	set.lock.Lock()
-	defer set.lock.Unlock()
-
-	for k := range set.items {
-		delete(set.items, k)
-	}
-
-	//this is so we don't hang onto any references
-	for i := 0; i < len(set.flattened); i++ {
-		set.flattened[i] = nil
-	}
-
-	set.flattened = set.flattened[:0]
+	set.typed.Dispose()
 	pool.Put(set)
 }
 
@@ -134,7 +91,7 @@ func (set *Set) Dispose() {
 func New(items ...interface{}) *Set {
 	set := pool.Get().(*Set)
 	for _, item := range items {
-		set.items[item] = true
+		set.typed.items[item] = struct{}{}
 	}
 
 	return set
@@ -143,7 +100,9 @@ func New(items ...interface{}) *Set {
 func init() {
 	pool.New = func() interface{} {
 		return &Set{
-			items: make(map[interface{}]bool, 10),
+			typed: TypedSet[interface{}]{
+				items: make(map[interface{}]struct{}, 10),
+			},
 		}
 	}
 }
@@ -97,8 +97,8 @@ func TestFlattenCaches(t *testing.T) {
 
 	set.Flatten()
 
-	if len(set.flattened) != 1 {
-		t.Errorf(`Expected len: %d, received: %d`, 1, len(set.flattened))
+	if len(set.typed.flattened) != 1 {
+		t.Errorf(`Expected len: %d, received: %d`, 1, len(set.typed.flattened))
 	}
 }
 
@@ -110,14 +110,14 @@ func TestAddClearsCache(t *testing.T) {
 
 	set.Add(item)
 
-	if len(set.flattened) != 0 {
-		t.Errorf(`Expected len: %d, received: %d`, 0, len(set.flattened))
+	if len(set.typed.flattened) != 0 {
+		t.Errorf(`Expected len: %d, received: %d`, 0, len(set.typed.flattened))
 	}
 
 	item = `test2`
 	set.Add(item)
 
-	if set.flattened != nil {
+	if set.typed.flattened != nil {
 		t.Errorf(`Cache not cleared.`)
 	}
 }
@@ -130,7 +130,7 @@ func TestDeleteClearsCache(t *testing.T) {
 
 	set.Remove(item)
 
-	if set.flattened != nil {
+	if set.typed.flattened != nil {
 		t.Errorf(`Cache not cleared.`)
 	}
 }
@@ -164,6 +164,57 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestRandomSample(t *testing.T) {
+	set := New()
+	if sample := set.RandomSample(3); len(sample) != 0 {
+		t.Errorf(`Expected empty sample, received: %v`, sample)
+	}
+
+	for i := 0; i < 10; i++ {
+		set.Add(i)
+	}
+
+	sample := set.RandomSample(3)
+	if len(sample) != 3 {
+		t.Errorf(`Expected len: %d, received: %d`, 3, len(sample))
+	}
+
+	seen := map[interface{}]bool{}
+	for _, item := range sample {
+		if !set.Exists(item) {
+			t.Errorf(`Sampled item %v not in set`, item)
+		}
+		if seen[item] {
+			t.Errorf(`Item %v sampled twice`, item)
+		}
+		seen[item] = true
+	}
+
+	if sample := set.RandomSample(20); len(sample) != 10 {
+		t.Errorf(`Expected len: %d, received: %d`, 10, len(sample))
+	}
+}
+
+func TestRandomSampleUniform(t *testing.T) {
+	set := New()
+	for i := 0; i < 4; i++ {
+		set.Add(i)
+	}
+
+	counts := make([]int, 4)
+	for i := 0; i < 4000; i++ {
+		for _, item := range set.RandomSample(1) {
+			counts[item.(int)]++
+		}
+	}
+
+	for i, count := range counts {
+		if count < 800 || count > 1200 {
+			t.Errorf(`Item %d sampled %d times out of 4000`, i, count)
+		}
+	}
+}
+
 func BenchmarkFlatten(b *testing.B) {
 	set := New()
 	for i := 0; i < 50; i++ {
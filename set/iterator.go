@@ -0,0 +1,50 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+// Iterator visits the items of a set in no particular order.
+type Iterator[T comparable] struct {
+	items []T
+	index int
+}
+
+// Next moves the iterator to the next item and returns a bool
+// indicating if one exists.
+func (iter *Iterator[T]) Next() bool {
+	if iter.index < len(iter.items) {
+		iter.index++
+	}
+
+	return iter.index < len(iter.items)
+}
+
+// Value returns the item at the iterator's current position or the
+// zero value if the iterator is exhausted.
+func (iter *Iterator[T]) Value() T {
+	if iter.index < 0 || iter.index >= len(iter.items) {
+		var zero T
+		return zero
+	}
+
+	return iter.items[iter.index]
+}
+
+// Iter returns an iterator over the items in the set when Iter was
+// called.  Later changes to the set are not visited.
+func (set *TypedSet[T]) Iter() *Iterator[T] {
+	return &Iterator[T]{items: set.Flatten(), index: -1}
+}
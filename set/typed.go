@@ -0,0 +1,168 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// TypedSet is a set of items of a single comparable type using the
+// builtin map type.  TypedSet is threadsafe.
+type TypedSet[T comparable] struct {
+	items     map[T]struct{}
+	lock      sync.RWMutex
+	flattened []T
+}
+
+// Add will add the provided items to the set.
+func (set *TypedSet[T]) Add(items ...T) {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	set.flattened = nil
+	for _, item := range items {
+		set.items[item] = struct{}{}
+	}
+}
+
+// Remove will remove the given items from the set.
+func (set *TypedSet[T]) Remove(items ...T) {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	set.flattened = nil
+	for _, item := range items {
+		delete(set.items, item)
+	}
+}
+
+// Exists returns a bool indicating if the given item exists in the set.
+func (set *TypedSet[T]) Exists(item T) bool {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	_, ok := set.items[item]
+	return ok
+}
+
+// Flatten will return a list of the items in the set.
+func (set *TypedSet[T]) Flatten() []T {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	if set.flattened != nil {
+		return set.flattened
+	}
+
+	set.flattened = make([]T, 0, len(set.items))
+	for item := range set.items {
+		set.flattened = append(set.flattened, item)
+	}
+	return set.flattened
+}
+
+// Len returns the number of items in the set.
+func (set *TypedSet[T]) Len() int64 {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return int64(len(set.items))
+}
+
+// RandomSample returns up to k distinct items chosen uniformly at
+// random from the set.  If k is greater than the number of items in
+// the set every item is returned.
+func (set *TypedSet[T]) RandomSample(k int) []T {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	if k < 1 || len(set.items) == 0 {
+		return nil
+	}
+
+	if k > len(set.items) {
+		k = len(set.items)
+	}
+
+	// map iteration order is not uniformly random so this
+	// reservoir samples the items instead
+	sample := make([]T, 0, k)
+	i := 0
+	for item := range set.items {
+		if i < k {
+			sample = append(sample, item)
+		} else if j := rand.Intn(i + 1); j < k {
+			sample[j] = item
+		}
+		i++
+	}
+
+	return sample
+}
+
+// Clear will remove all items from the set.
+func (set *TypedSet[T]) Clear() {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	set.items = map[T]struct{}{}
+	set.flattened = nil
+}
+
+// All returns a bool indicating if all of the supplied items exist in the set.
+func (set *TypedSet[T]) All(items ...T) bool {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	for _, item := range items {
+		if _, ok := set.items[item]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Dispose will empty the set and drop its references to its items.
+func (set *TypedSet[T]) Dispose() {
+	set.lock.Lock()
+	defer set.lock.Unlock()
+
+	for k := range set.items {
+		delete(set.items, k)
+	}
+
+	//this is so we don't hang onto any references
+	var zero T
+	for i := 0; i < len(set.flattened); i++ {
+		set.flattened[i] = zero
+	}
+
+	set.flattened = set.flattened[:0]
+}
+
+// NewOf is the constructor for sets of a single item type.  Takes a list of
+// items to initialize the set with.
+func NewOf[T comparable](items ...T) *TypedSet[T] {
+	set := &TypedSet[T]{items: make(map[T]struct{}, len(items))}
+	for _, item := range items {
+		set.items[item] = struct{}{}
+	}
+
+	return set
+}
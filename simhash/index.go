@@ -0,0 +1,182 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simhash
+
+import "sort"
+
+// Match is a fingerprint found by a query.
+type Match struct {
+	ID          uint64
+	Fingerprint uint64
+	Distance    int
+}
+
+// Matches is a typed list of Match.
+type Matches []Match
+
+type entry struct {
+	id, fingerprint uint64
+}
+
+// block is a contiguous range of bits of a fingerprint.
+type block struct {
+	shift uint
+	mask  uint64
+}
+
+func (b block) of(fingerprint uint64) uint64 {
+	return (fingerprint >> b.shift) & b.mask
+}
+
+// Index finds stored fingerprints within a fixed Hamming distance of
+// a query.  It is not threadsafe.
+type Index struct {
+	k      int
+	blocks []block
+	tables []map[uint64][]entry
+	len    uint64
+}
+
+// Add stores the provided fingerprint under the provided ID.  The
+// same ID may be stored with several fingerprints.
+func (ix *Index) Add(id, fingerprint uint64) {
+	e := entry{id: id, fingerprint: fingerprint}
+	for i, b := range ix.blocks {
+		key := b.of(fingerprint)
+		ix.tables[i][key] = append(ix.tables[i][key], e)
+	}
+
+	ix.len++
+}
+
+// Remove removes the provided ID and fingerprint pair from the index
+// and returns a bool indicating if it was found.
+func (ix *Index) Remove(id, fingerprint uint64) bool {
+	e := entry{id: id, fingerprint: fingerprint}
+	found := false
+	for i, b := range ix.blocks {
+		key := b.of(fingerprint)
+		entries := ix.tables[i][key]
+		for j := range entries {
+			if entries[j] != e {
+				continue
+			}
+
+			found = true
+			entries[j] = entries[len(entries)-1]
+			entries = entries[:len(entries)-1]
+			if len(entries) == 0 {
+				delete(ix.tables[i], key)
+			} else {
+				ix.tables[i][key] = entries
+			}
+			break
+		}
+	}
+
+	if found {
+		ix.len--
+	}
+	return found
+}
+
+// Query returns every stored fingerprint within the index's distance
+// of the provided fingerprint, nearest first.
+func (ix *Index) Query(fingerprint uint64) Matches {
+	matches := Matches{}
+	for i, b := range ix.blocks {
+		for _, e := range ix.tables[i][b.of(fingerprint)] {
+			if ix.matchedEarlier(i, e.fingerprint, fingerprint) {
+				continue
+			}
+
+			if d := Distance(e.fingerprint, fingerprint); d <= ix.k {
+				matches = append(matches, Match{
+					ID: e.id, Fingerprint: e.fingerprint, Distance: d,
+				})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// matchedEarlier returns true if a and b share one of the blocks
+// before i, in which case the pair was already considered when that
+// block's table was searched.
+func (ix *Index) matchedEarlier(i int, a, b uint64) bool {
+	for _, earlier := range ix.blocks[:i] {
+		if earlier.of(a) == earlier.of(b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of fingerprints in the index.
+func (ix *Index) Len() uint64 {
+	return ix.len
+}
+
+// Distance returns the maximum distance of a match.
+func (ix *Index) Distance() int {
+	return ix.k
+}
+
+// NewIndex returns an index finding fingerprints within Hamming
+// distance k of a query.  k must be in [0, 63].  Larger distances
+// mean smaller blocks, which are less selective, so queries slow down
+// as k grows.
+func NewIndex(k int) *Index {
+	if k < 0 || k > 63 {
+		panic(`Invalid distance provided.`)
+	}
+
+	numBlocks := k + 1
+	ix := &Index{
+		k:      k,
+		blocks: make([]block, 0, numBlocks),
+		tables: make([]map[uint64][]entry, 0, numBlocks),
+	}
+
+	// spread 64 bits as evenly as possible across the blocks
+	shift := uint(0)
+	for i := 0; i < numBlocks; i++ {
+		width := uint(64 / numBlocks)
+		if i < 64%numBlocks {
+			width++
+		}
+
+		mask := ^uint64(0)
+		if width < 64 {
+			mask = 1<<width - 1
+		}
+
+		ix.blocks = append(ix.blocks, block{shift: shift, mask: mask})
+		ix.tables = append(ix.tables, make(map[uint64][]entry))
+		shift += width
+	}
+
+	return ix
+}
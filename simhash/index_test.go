@@ -0,0 +1,137 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simhash
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flip returns fingerprint with n distinct random bits flipped.
+func flip(fingerprint uint64, n int) uint64 {
+	for _, i := range rand.Perm(64)[:n] {
+		fingerprint ^= 1 << uint(i)
+	}
+
+	return fingerprint
+}
+
+func TestIndexBlocks(t *testing.T) {
+	for k := 0; k < 64; k++ {
+		ix := NewIndex(k)
+		assert.Len(t, ix.blocks, k+1)
+
+		var covered uint64
+		for _, b := range ix.blocks {
+			assert.Equal(t, uint64(0), covered&(b.mask<<b.shift))
+			covered |= b.mask << b.shift
+		}
+		assert.Equal(t, ^uint64(0), covered)
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	ix := NewIndex(3)
+	base := rand.Uint64()
+
+	ix.Add(1, base)
+	ix.Add(2, flip(base, 2))
+	ix.Add(3, flip(base, 3))
+	ix.Add(4, flip(base, 4))
+	ix.Add(5, ^base)
+
+	matches := ix.Query(base)
+	if assert.Len(t, matches, 3) {
+		assert.Equal(t, Match{ID: 1, Fingerprint: base, Distance: 0}, matches[0])
+		assert.Equal(t, uint64(2), matches[1].ID)
+		assert.Equal(t, 2, matches[1].Distance)
+		assert.Equal(t, uint64(3), matches[2].ID)
+	}
+
+	assert.Len(t, ix.Query(^base), 1)
+	assert.Equal(t, uint64(5), ix.Len())
+	assert.Equal(t, 3, ix.Distance())
+}
+
+func TestIndexRemove(t *testing.T) {
+	ix := NewIndex(2)
+	ix.Add(1, 10)
+	ix.Add(2, 10)
+	ix.Add(2, 10)
+
+	assert.True(t, ix.Remove(2, 10))
+	assert.Equal(t, uint64(2), ix.Len())
+	assert.Len(t, ix.Query(10), 2)
+
+	assert.True(t, ix.Remove(2, 10))
+	assert.False(t, ix.Remove(2, 10))
+	assert.False(t, ix.Remove(1, 11))
+	assert.Equal(t, Matches{{ID: 1, Fingerprint: 10}}, ix.Query(10))
+
+	assert.True(t, ix.Remove(1, 10))
+	assert.Len(t, ix.Query(10), 0)
+	for _, table := range ix.tables {
+		assert.Len(t, table, 0)
+	}
+}
+
+func TestIndexAgainstBruteForce(t *testing.T) {
+	for _, k := range []int{0, 1, 4, 7} {
+		ix := NewIndex(k)
+		fingerprints := make([]uint64, 0, 2000)
+		base := rand.Uint64()
+		for i := 0; i < 2000; i++ {
+			fp := flip(base, rand.Intn(12))
+			fingerprints = append(fingerprints, fp)
+			ix.Add(uint64(i), fp)
+		}
+
+		for q := 0; q < 20; q++ {
+			query := flip(base, rand.Intn(6))
+			expected := 0
+			for _, fp := range fingerprints {
+				if Distance(fp, query) <= k {
+					expected++
+				}
+			}
+
+			matches := ix.Query(query)
+			assert.Len(t, matches, expected)
+			for _, m := range matches {
+				assert.True(t, m.Distance <= k)
+				assert.Equal(t, fingerprints[m.ID], m.Fingerprint)
+			}
+		}
+	}
+}
+
+func BenchmarkIndexQuery(b *testing.B) {
+	numItems := 100000
+	ix := NewIndex(3)
+	for i := 0; i < numItems; i++ {
+		ix.Add(uint64(i), rand.Uint64())
+	}
+	query := rand.Uint64()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ix.Query(query)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package simhash implements SimHash fingerprinting and an index for
+finding fingerprints within a small Hamming distance of one another.
+
+A SimHash fingerprint summarizes a document's features, such as its
+words or shingles, in 64 bits.  Similar documents produce fingerprints
+differing in only a few bits, so near-duplicates can be found by
+looking for fingerprints within some Hamming distance k.
+
+The index splits fingerprints into k+1 blocks of bits.  Two
+fingerprints within distance k differ in at most k bits, so at least
+one block must match exactly.  Each block has its own table, which is
+equivalent to a table of fingerprints permuted so that block comes
+first and searched by prefix, and candidates found in any table are
+then checked against the full distance.
+
+Performance characteristics:
+Space: O(n * k)
+Add: O(k)
+Remove: O(k * c) where c is the number of fingerprints sharing a block
+Query: O(k * c)
+*/
+package simhash
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// Feature is a weighted piece of a document, such as a word.
+type Feature struct {
+	Value  []byte
+	Weight int
+}
+
+// hash hashes the provided value to 64 well mixed bits.
+func hash(value []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(value)
+	x := h.Sum64()
+
+	// fnv does not avalanche well, finish with the murmur3 finalizer
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+type weights [64]int
+
+// add adds weight to every bit set in h and subtracts it from every
+// bit that is not.
+func (w *weights) add(h uint64, weight int) {
+	for i := uint(0); i < 64; i++ {
+		if h&(1<<i) != 0 {
+			w[i] += weight
+		} else {
+			w[i] -= weight
+		}
+	}
+}
+
+func (w *weights) fingerprint() uint64 {
+	var fingerprint uint64
+	for i, weight := range w {
+		if weight > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+
+	return fingerprint
+}
+
+// WeightedFingerprint returns the SimHash fingerprint of the provided
+// features.  Each bit of the fingerprint is set if the total weight of
+// the features whose hash has that bit set outweighs those that don't.
+func WeightedFingerprint(features []Feature) uint64 {
+	var w weights
+	for _, feature := range features {
+		w.add(hash(feature.Value), feature.Weight)
+	}
+
+	return w.fingerprint()
+}
+
+// Fingerprint returns the SimHash fingerprint of the provided
+// features, each having a weight of one.  Repeated features count
+// once per occurrence.
+func Fingerprint(features [][]byte) uint64 {
+	var w weights
+	for _, feature := range features {
+		w.add(hash(feature), 1)
+	}
+
+	return w.fingerprint()
+}
+
+// Distance returns the Hamming distance between two fingerprints.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
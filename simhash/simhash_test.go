@@ -0,0 +1,79 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func words(text string) [][]byte {
+	fields := strings.Fields(text)
+	result := make([][]byte, 0, len(fields))
+	for _, field := range fields {
+		result = append(result, []byte(field))
+	}
+
+	return result
+}
+
+const document = `the quick brown fox jumps over the lazy dog while the cat
+sleeps in the warm afternoon sun and the birds sing in the tall trees
+near the river bank where children play games until the evening comes`
+
+func TestFingerprintSimilarity(t *testing.T) {
+	a := Fingerprint(words(document))
+	b := Fingerprint(words(strings.Replace(document, `lazy`, `sleepy`, 1)))
+	c := Fingerprint(words(`an entirely unrelated sentence about compilers,
+		type systems, register allocation and instruction scheduling`))
+
+	assert.Equal(t, a, Fingerprint(words(document)))
+	assert.True(t, Distance(a, b) < Distance(a, c))
+	assert.True(t, Distance(a, b) <= 10, `distance %d`, Distance(a, b))
+}
+
+func TestWeightedFingerprint(t *testing.T) {
+	features := []Feature{}
+	for _, word := range words(document) {
+		features = append(features, Feature{Value: word, Weight: 1})
+	}
+	assert.Equal(t, Fingerprint(words(document)), WeightedFingerprint(features))
+
+	// a single heavy feature dominates
+	features = append(features, Feature{Value: []byte(`heavy`), Weight: 1000})
+	assert.Equal(t, hash([]byte(`heavy`)), WeightedFingerprint(features))
+
+	assert.Equal(t, uint64(0), WeightedFingerprint(nil))
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, Distance(5, 5))
+	assert.Equal(t, 1, Distance(4, 5))
+	assert.Equal(t, 64, Distance(0, ^uint64(0)))
+}
+
+func BenchmarkFingerprint(b *testing.B) {
+	features := words(document)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Fingerprint(features)
+	}
+}
@@ -19,6 +19,11 @@ Package Int64 simply adds an Int64-typed version of the standard library's
 sort/IntSlice implementation.
 
 Also added is an Insert method.
+
+For slices of other types, Search, Insert, Delete, Merge and Dedup do
+the same for any sorted slice given a less function, so that a small
+ordered collection can be kept in a plain slice rather than a tree or
+skiplist.  Items are equal when neither is less than the other.
 */
 package slice
 
@@ -0,0 +1,53 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concurrent
+
+import "github.com/Workiva/go-datastructures/slice/skip"
+
+// Iterator visits the entries of a SkipList in order.  An iterator
+// is not itself safe for use by multiple goroutines.
+type Iterator struct {
+	first bool
+	n     *node
+}
+
+// Next returns a bool indicating if there is a further value in
+// this iterator and moves the iterator to that value.  Deleted
+// entries are skipped.
+func (iter *Iterator) Next() bool {
+	if iter.first {
+		iter.first = false
+	} else if iter.n != nil {
+		iter.n = iter.n.next[0].Load()
+	}
+
+	for iter.n != nil && (iter.n.marked.Load() || !iter.n.fullyLinked.Load()) {
+		iter.n = iter.n.next[0].Load()
+	}
+
+	return iter.n != nil
+}
+
+// Value returns the entry at the iterator's present position or nil
+// if no values remain to iterate.
+func (iter *Iterator) Value() skip.Entry {
+	if iter.n == nil {
+		return nil
+	}
+
+	return iter.n.entry()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// entryBox lets an entry be swapped atomically regardless of its
+// concrete type.
+type entryBox struct {
+	entry skip.Entry
+}
+
+type node struct {
+	// next holds the forward pointers for each level this node
+	// is linked at.
+	next []atomic.Pointer[node]
+	// box holds the entry, which is replaced when a duplicate key
+	// is inserted.
+	box atomic.Pointer[entryBox]
+	// marked is set once this node has been logically deleted.
+	marked atomic.Bool
+	// fullyLinked is set once this node is linked at every level.
+	fullyLinked atomic.Bool
+	lock        sync.Mutex
+}
+
+func (n *node) entry() skip.Entry {
+	return n.box.Load().entry
+}
+
+// swap replaces this node's entry and returns the old one.
+func (n *node) swap(e skip.Entry) skip.Entry {
+	return n.box.Swap(&entryBox{entry: e}).entry
+}
+
+func newNode(e skip.Entry, levels int) *node {
+	n := &node{
+		next: make([]atomic.Pointer[node], levels),
+	}
+	n.box.Store(&entryBox{entry: e})
+	return n
+}
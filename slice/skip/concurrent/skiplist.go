@@ -0,0 +1,345 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package concurrent defines a skiplist that is safe for use by multiple
+goroutines without an external lock.  This is the lazy skiplist described
+by Herlihy, Lev, Luchangco and Shavit: searches take no locks at all, while
+inserts and deletes lock only the predecessors of the node being linked or
+unlinked and validate them before making any change.  Deletion is split
+into a logical step, marking the node, and a physical step, unlinking it,
+so readers never observe a partially removed node.
+
+Unlike skip.SkipList this list does not track widths so it cannot be
+addressed by position.
+
+More information here: https://people.csail.mit.edu/shanir/publications/LazySkipList.pdf
+*/
+package concurrent
+
+import (
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// maxLevels is the largest max level a list can be constructed with.
+const maxLevels = 64
+
+// SkipList is a skiplist that can be read and modified by multiple
+// goroutines concurrently.
+type SkipList struct {
+	maxLevel uint8
+	// level is the highest level any node has been linked at.  It
+	// only ever grows so searches can skip the empty upper levels.
+	level atomic.Int32
+	head  *node
+	num   atomic.Int64
+}
+
+// randomLevel returns a level between 1 and the list's max level with
+// each additional level half as likely as the last.
+func (sl *SkipList) randomLevel() int {
+	level := bits.TrailingZeros64(rand.Uint64()) + 1
+	if level > int(sl.maxLevel) {
+		level = int(sl.maxLevel)
+	}
+
+	return level
+}
+
+// raiseLevel ensures searches begin at or above the provided level.
+func (sl *SkipList) raiseLevel(level int) {
+	for {
+		current := sl.level.Load()
+		if int(current) >= level || sl.level.CompareAndSwap(current, int32(level)) {
+			return
+		}
+	}
+}
+
+// find fills preds and succs with the nodes on either side of the
+// provided entry at every level and returns the highest level at
+// which a node equal to the entry was found, or -1.
+func (sl *SkipList) find(e skip.Entry, preds, succs *[maxLevels]*node) int {
+	found := -1
+	pred := sl.head
+	for level := int(sl.level.Load()) - 1; level >= 0; level-- {
+		curr := pred.next[level].Load()
+		for curr != nil && curr.entry().Compare(e) < 0 {
+			pred = curr
+			curr = pred.next[level].Load()
+		}
+
+		if found == -1 && curr != nil && curr.entry().Compare(e) == 0 {
+			found = level
+		}
+		preds[level] = pred
+		succs[level] = curr
+	}
+
+	return found
+}
+
+// unlockPreds unlocks each distinct predecessor up to and including
+// the provided level.
+func unlockPreds(preds *[maxLevels]*node, highestLocked int) {
+	var prev *node
+	for level := 0; level <= highestLocked; level++ {
+		if preds[level] != prev {
+			preds[level].lock.Unlock()
+			prev = preds[level]
+		}
+	}
+}
+
+func (sl *SkipList) insert(e skip.Entry) skip.Entry {
+	var preds, succs [maxLevels]*node
+	topLevel := sl.randomLevel()
+	sl.raiseLevel(topLevel)
+
+	for {
+		found := sl.find(e, &preds, &succs)
+		if found != -1 {
+			n := succs[found]
+			if !n.marked.Load() {
+				// wait for the insert that created this node to
+				// finish linking it before overwriting
+				for !n.fullyLinked.Load() {
+					runtime.Gosched()
+				}
+				return n.swap(e)
+			}
+			// the node is being deleted, retry once it's gone
+			runtime.Gosched()
+			continue
+		}
+
+		highestLocked := -1
+		valid := true
+		var prev *node
+		for level := 0; valid && level < topLevel; level++ {
+			pred, succ := preds[level], succs[level]
+			if pred != prev {
+				pred.lock.Lock()
+				highestLocked = level
+				prev = pred
+			}
+			valid = !pred.marked.Load() && (succ == nil || !succ.marked.Load()) &&
+				pred.next[level].Load() == succ
+		}
+
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+
+		n := newNode(e, topLevel)
+		for level := 0; level < topLevel; level++ {
+			n.next[level].Store(succs[level])
+		}
+		for level := 0; level < topLevel; level++ {
+			preds[level].next[level].Store(n)
+		}
+		n.fullyLinked.Store(true)
+		unlockPreds(&preds, highestLocked)
+		sl.num.Add(1)
+		return nil
+	}
+}
+
+// Insert will insert the provided entries into the list.  Returned
+// is a list of entries that were overwritten, with nil in place of
+// entries that were newly added.  Each entry is inserted atomically
+// but the batch as a whole is not.
+func (sl *SkipList) Insert(entries ...skip.Entry) skip.Entries {
+	overwritten := make(skip.Entries, 0, len(entries))
+	for _, e := range entries {
+		overwritten = append(overwritten, sl.insert(e))
+	}
+
+	return overwritten
+}
+
+// GetOne will retrieve the value associated with the provided key or
+// nil if it could not be found.  This never blocks and performs no
+// allocations.
+func (sl *SkipList) GetOne(e skip.Entry) skip.Entry {
+	var preds, succs [maxLevels]*node
+	found := sl.find(e, &preds, &succs)
+	if found == -1 {
+		return nil
+	}
+
+	n := succs[found]
+	if !n.fullyLinked.Load() || n.marked.Load() {
+		return nil
+	}
+
+	return n.entry()
+}
+
+// Get will retrieve values associated with the keys provided.  If an
+// associated value could not be found, a nil is returned in its place.
+// Gets never block.
+func (sl *SkipList) Get(entries ...skip.Entry) skip.Entries {
+	result := make(skip.Entries, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, sl.GetOne(e))
+	}
+
+	return result
+}
+
+// Contains returns a bool indicating if a value associated with the
+// provided key exists in this list.  This never blocks.
+func (sl *SkipList) Contains(e skip.Entry) bool {
+	return sl.GetOne(e) != nil
+}
+
+func (sl *SkipList) delete(e skip.Entry) skip.Entry {
+	var preds, succs [maxLevels]*node
+	var victim *node
+	marked := false
+	topLevel := 0
+
+	for {
+		found := sl.find(e, &preds, &succs)
+		if !marked {
+			if found == -1 {
+				return nil
+			}
+
+			victim = succs[found]
+			// a node is only deleted once it is fully linked and
+			// found at its top level, otherwise it's still being
+			// inserted
+			if !victim.fullyLinked.Load() || len(victim.next)-1 != found ||
+				victim.marked.Load() {
+
+				return nil
+			}
+
+			topLevel = len(victim.next)
+			victim.lock.Lock()
+			if victim.marked.Load() {
+				victim.lock.Unlock()
+				return nil
+			}
+			victim.marked.Store(true)
+			marked = true
+		}
+
+		highestLocked := -1
+		valid := true
+		var prev *node
+		for level := 0; valid && level < topLevel; level++ {
+			pred := preds[level]
+			if pred != prev {
+				pred.lock.Lock()
+				highestLocked = level
+				prev = pred
+			}
+			valid = !pred.marked.Load() && pred.next[level].Load() == victim
+		}
+
+		if !valid {
+			unlockPreds(&preds, highestLocked)
+			continue
+		}
+
+		for level := topLevel - 1; level >= 0; level-- {
+			preds[level].next[level].Store(victim.next[level].Load())
+		}
+		victim.lock.Unlock()
+		unlockPreds(&preds, highestLocked)
+		sl.num.Add(-1)
+		return victim.entry()
+	}
+}
+
+// Delete will remove the provided keys from the list and return a
+// list of the entries that were deleted, with nil in place of keys
+// that could not be found.
+func (sl *SkipList) Delete(entries ...skip.Entry) skip.Entries {
+	deleted := make(skip.Entries, 0, len(entries))
+	for _, e := range entries {
+		deleted = append(deleted, sl.delete(e))
+	}
+
+	return deleted
+}
+
+// Len returns the number of items in this list.  Under concurrent
+// modification this is only a snapshot.
+func (sl *SkipList) Len() uint64 {
+	return uint64(sl.num.Load())
+}
+
+// Each will call the provided function with every entry in the list
+// in order until the function returns false.  Entries inserted or
+// deleted while this runs may or may not be visited.
+func (sl *SkipList) Each(fn func(skip.Entry) bool) {
+	for n := sl.head.next[0].Load(); n != nil; n = n.next[0].Load() {
+		if n.marked.Load() || !n.fullyLinked.Load() {
+			continue
+		}
+
+		if !fn(n.entry()) {
+			return
+		}
+	}
+}
+
+// Iter will return an iterator that can be used to iterate over
+// all the values with a key equal to or greater than the key
+// provided.  Like Each, the iterator is weakly consistent.
+func (sl *SkipList) Iter(e skip.Entry) *Iterator {
+	var preds, succs [maxLevels]*node
+	sl.find(e, &preds, &succs)
+	return &Iterator{
+		first: true,
+		n:     succs[0],
+	}
+}
+
+// New will allocate, initialize, and return a new skiplist.  The
+// provided parameter should be of type uint and will determine the
+// maximum possible level, as with skip.New.
+func New(ifc interface{}) *SkipList {
+	var maxLevel uint8
+	switch ifc.(type) {
+	case uint8:
+		maxLevel = 8
+	case uint16:
+		maxLevel = 16
+	case uint32:
+		maxLevel = 32
+	case uint64, uint:
+		maxLevel = 64
+	}
+
+	sl := &SkipList{
+		maxLevel: maxLevel,
+		head:     newNode(nil, maxLevels),
+	}
+	sl.level.Store(1)
+	sl.head.fullyLinked.Store(true)
+	return sl
+}
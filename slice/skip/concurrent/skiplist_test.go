@@ -0,0 +1,255 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concurrent
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+type mockEntry uint64
+
+func (me mockEntry) Compare(other skip.Entry) int {
+	otherU := other.(mockEntry)
+	if me == otherU {
+		return 0
+	}
+
+	if me > otherU {
+		return 1
+	}
+
+	return -1
+}
+
+// keyedEntry compares by key only so a reinsert can be told apart
+// from the entry it overwrote.
+type keyedEntry struct {
+	key, value uint64
+}
+
+func (ke keyedEntry) Compare(other skip.Entry) int {
+	return mockEntry(ke.key).Compare(mockEntry(other.(keyedEntry).key))
+}
+
+func entries(sl *SkipList) skip.Entries {
+	result := skip.Entries{}
+	sl.Each(func(e skip.Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	return result
+}
+
+func TestInsertGet(t *testing.T) {
+	sl := New(uint64(0))
+	assert.Equal(t, skip.Entries{nil}, sl.Get(mockEntry(1)))
+
+	assert.Equal(t, skip.Entries{nil, nil, nil},
+		sl.Insert(mockEntry(3), mockEntry(1), mockEntry(2)))
+	assert.Equal(t, uint64(3), sl.Len())
+	assert.Equal(t, skip.Entries{mockEntry(1), nil}, sl.Get(mockEntry(1), mockEntry(4)))
+	assert.Equal(t, skip.Entries{mockEntry(1), mockEntry(2), mockEntry(3)}, entries(sl))
+}
+
+func TestGetOneContains(t *testing.T) {
+	sl := New(uint64(0))
+	assert.Nil(t, sl.GetOne(mockEntry(1)))
+	assert.False(t, sl.Contains(mockEntry(1)))
+
+	sl.Insert(mockEntry(1), mockEntry(3))
+	assert.Equal(t, mockEntry(3), sl.GetOne(mockEntry(3)))
+	assert.True(t, sl.Contains(mockEntry(1)))
+	assert.False(t, sl.Contains(mockEntry(2)))
+
+	var e skip.Entry = mockEntry(3)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		sl.GetOne(e)
+		sl.Contains(e)
+	}))
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(keyedEntry{1, 1})
+
+	overwritten := sl.Insert(keyedEntry{1, 2})
+	assert.Equal(t, skip.Entries{keyedEntry{1, 1}}, overwritten)
+	assert.Equal(t, uint64(1), sl.Len())
+	assert.Equal(t, skip.Entries{keyedEntry{1, 2}}, sl.Get(keyedEntry{key: 1}))
+}
+
+func TestDelete(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntry(1), mockEntry(2), mockEntry(3))
+
+	assert.Equal(t, skip.Entries{mockEntry(2), nil}, sl.Delete(mockEntry(2), mockEntry(4)))
+	assert.Equal(t, uint64(2), sl.Len())
+	assert.Equal(t, skip.Entries{nil}, sl.Get(mockEntry(2)))
+	assert.Equal(t, skip.Entries{mockEntry(1), mockEntry(3)}, entries(sl))
+
+	assert.Equal(t, skip.Entries{nil}, sl.Delete(mockEntry(2)))
+}
+
+func TestIter(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntry(5), mockEntry(10))
+
+	iter := sl.Iter(mockEntry(6))
+	assert.True(t, iter.Next())
+	assert.Equal(t, mockEntry(10), iter.Value())
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+
+	iter = sl.Iter(mockEntry(0))
+	assert.True(t, iter.Next())
+	assert.Equal(t, mockEntry(5), iter.Value())
+
+	iter = sl.Iter(mockEntry(11))
+	assert.False(t, iter.Next())
+}
+
+func TestEachStops(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntry(1), mockEntry(2), mockEntry(3))
+
+	count := 0
+	sl.Each(func(e skip.Entry) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+}
+
+func TestConcurrentInsertDelete(t *testing.T) {
+	sl := New(uint64(0))
+	numRoutines, numItems := 8, 1000
+
+	var wg sync.WaitGroup
+	wg.Add(numRoutines)
+	for i := 0; i < numRoutines; i++ {
+		go func(offset int) {
+			defer wg.Done()
+			for j := 0; j < numItems; j++ {
+				sl.Insert(mockEntry(j*numRoutines + offset))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(numRoutines*numItems), sl.Len())
+	all := entries(sl)
+	assert.Len(t, all, numRoutines*numItems)
+	for i, e := range all {
+		assert.Equal(t, mockEntry(i), e)
+	}
+
+	// delete the odd entries while concurrently reading the even ones
+	wg.Add(numRoutines * 2)
+	for i := 0; i < numRoutines; i++ {
+		go func(offset int) {
+			defer wg.Done()
+			for j := offset; j < numRoutines*numItems; j += numRoutines {
+				if j%2 == 1 {
+					sl.Delete(mockEntry(j))
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < numItems; j++ {
+				key := mockEntry(rand.Intn(numRoutines*numItems/2) * 2)
+				if sl.Get(key)[0] != key {
+					t.Errorf(`Expected to find %d.`, key)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(numRoutines*numItems/2), sl.Len())
+	for i, e := range entries(sl) {
+		assert.Equal(t, mockEntry(i*2), e)
+	}
+}
+
+func TestConcurrentContention(t *testing.T) {
+	sl := New(uint16(0))
+	numRoutines, numKeys := 8, 50
+
+	var wg sync.WaitGroup
+	wg.Add(numRoutines)
+	for i := 0; i < numRoutines; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				key := mockEntry(rand.Intn(numKeys))
+				if rand.Intn(2) == 0 {
+					sl.Insert(key)
+				} else {
+					sl.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	all := entries(sl)
+	assert.Equal(t, uint64(len(all)), sl.Len())
+	for i := 1; i < len(all); i++ {
+		assert.True(t, all[i-1].Compare(all[i]) < 0)
+	}
+}
+
+func BenchmarkConcurrentInsert(b *testing.B) {
+	sl := New(uint64(0))
+	var counter uint64
+	var lock sync.Mutex
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		lock.Lock()
+		counter++
+		offset := counter << 40
+		lock.Unlock()
+
+		for i := uint64(0); pb.Next(); i++ {
+			sl.Insert(mockEntry(offset + i))
+		}
+	})
+}
+
+func BenchmarkConcurrentGet(b *testing.B) {
+	numItems := 10000
+	sl := New(uint64(0))
+	for i := 0; i < numItems; i++ {
+		sl.Insert(mockEntry(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			sl.Get(mockEntry(i % numItems))
+		}
+	})
+}
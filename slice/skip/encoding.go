@@ -0,0 +1,172 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+const encodingVersion = 1
+
+// maxEncodedEntrySize bounds the length prefix accepted by Decode so
+// corrupt input can't cause a huge allocation.
+const maxEncodedEntrySize = 1 << 30
+
+// Encode writes every entry in this list, in list order, to the
+// provided writer.  Every entry must implement
+// encoding.BinaryMarshaler.  The list's shape is not written; Decode
+// rebuilds a balanced list from the entries alone.
+func (sl *SkipList) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	if _, err := bw.Write([]byte{encodingVersion, sl.maxLevel}); err != nil {
+		return err
+	}
+
+	n := binary.PutUvarint(buf, sl.num)
+	if _, err := bw.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	var err error
+	var position uint64
+	sl.each(func(e Entry) bool {
+		m, ok := e.(encoding.BinaryMarshaler)
+		if !ok {
+			err = fmt.Errorf(`Entry at position %d does not implement encoding.BinaryMarshaler.`, position)
+			return false
+		}
+
+		var data []byte
+		data, err = m.MarshalBinary()
+		if err != nil {
+			return false
+		}
+
+		n := binary.PutUvarint(buf, uint64(len(data)))
+		if _, err = bw.Write(buf[:n]); err != nil {
+			return false
+		}
+		if _, err = bw.Write(data); err != nil {
+			return false
+		}
+
+		position++
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// decodedLevel returns the level of the node at the provided 1-based
+// position such that every other node reaches level 2, every fourth
+// level 3 and so on, giving a perfectly balanced list.
+func decodedLevel(position uint64, maxLevel uint8) uint8 {
+	level := uint8(bits.TrailingZeros64(position)) + 1
+	if level > maxLevel-1 {
+		level = maxLevel - 1
+	}
+
+	return level
+}
+
+// Decode reads a list written by Encode, calling factory to turn each
+// encoded entry back into an Entry.  Each call to factory receives its
+// own slice which it may retain.  Entries are linked in the order they
+// were written without comparisons, so this is an O(n) operation.
+func Decode(r io.Reader, factory func([]byte) Entry) (*SkipList, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+
+	if header[0] != encodingVersion {
+		return nil, fmt.Errorf(`Unknown encoding version %d.`, header[0])
+	}
+
+	sl := &SkipList{rng: newRand()}
+	switch maxLevel := header[1]; maxLevel {
+	case 8:
+		sl.init(uint8(0))
+	case 16:
+		sl.init(uint16(0))
+	case 32:
+		sl.init(uint32(0))
+	case 64:
+		sl.init(uint64(0))
+	default:
+		return nil, fmt.Errorf(`Invalid max level %d.`, maxLevel)
+	}
+
+	num, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	last := make(nodes, sl.maxLevel)
+	lastPos := make(widths, sl.maxLevel)
+	for i := range last {
+		last[i] = sl.head
+	}
+
+	for position := uint64(1); position <= num; position++ {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > maxEncodedEntrySize {
+			return nil, fmt.Errorf(`Entry at position %d has invalid size %d.`, position-1, size)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, err
+		}
+
+		level := decodedLevel(position, sl.maxLevel)
+		n := newNode(factory(data), level)
+		if last[0] != sl.head {
+			n.backward = last[0]
+		}
+
+		for i := uint8(0); i < level; i++ {
+			last[i].forward[i] = n
+			last[i].widths[i] = position - lastPos[i]
+			last[i] = n
+			lastPos[i] = position
+		}
+
+		if level > sl.level {
+			sl.level = level
+		}
+	}
+
+	sl.num = num
+	return sl, nil
+}
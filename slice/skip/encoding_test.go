@@ -0,0 +1,140 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type binaryEntry uint64
+
+func (be binaryEntry) Compare(other Entry) int {
+	return mockEntry(be).Compare(mockEntry(other.(binaryEntry)))
+}
+
+func (be binaryEntry) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(be))
+	return data, nil
+}
+
+func decodeBinaryEntry(data []byte) Entry {
+	return binaryEntry(binary.BigEndian.Uint64(data))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	sl := New(uint32(0))
+	for i := uint64(0); i < 1000; i++ {
+		sl.Insert(binaryEntry(i * 3))
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, sl.Encode(&buf))
+
+	decoded, err := Decode(&buf, decodeBinaryEntry)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Nil(t, decoded.Validate())
+	assert.Equal(t, sl.Len(), decoded.Len())
+	assert.Equal(t, sl.maxLevel, decoded.maxLevel)
+	for i := uint64(0); i < 1000; i++ {
+		assert.Equal(t, binaryEntry(i*3), decoded.ByPosition(i))
+	}
+
+	assert.Equal(t, Entries{binaryEntry(300), nil}, decoded.Get(binaryEntry(300), binaryEntry(301)))
+	decoded.Insert(binaryEntry(301))
+	decoded.Delete(binaryEntry(0))
+	assert.Nil(t, decoded.Validate())
+	assert.Equal(t, binaryEntry(2997), decoded.Last())
+}
+
+func TestEncodeDecodeEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, New(uint8(0)).Encode(&buf))
+
+	decoded, err := Decode(&buf, decodeBinaryEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), decoded.Len())
+	assert.Nil(t, decoded.Validate())
+
+	decoded.Insert(binaryEntry(1))
+	assert.Nil(t, decoded.Validate())
+}
+
+func TestDecodeLevelsBalanced(t *testing.T) {
+	sl := New(uint8(0))
+	for i := uint64(0); i < 16; i++ {
+		sl.Insert(binaryEntry(i))
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, sl.Encode(&buf))
+	decoded, err := Decode(&buf, decodeBinaryEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 1, 3, 1, 2, 1, 4, 1, 2, 1, 3, 1, 2, 1, 5}, nodeLevels(decoded))
+}
+
+func TestEncodeNotMarshaler(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntry(1))
+
+	var buf bytes.Buffer
+	assert.NotNil(t, sl.Encode(&buf))
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(binaryEntry(1), binaryEntry(2))
+
+	var buf bytes.Buffer
+	assert.Nil(t, sl.Encode(&buf))
+	data := buf.Bytes()
+
+	_, err := Decode(bytes.NewReader(data[:len(data)-1]), decodeBinaryEntry)
+	assert.NotNil(t, err)
+
+	_, err = Decode(bytes.NewReader([]byte{2, 8, 0}), decodeBinaryEntry)
+	assert.NotNil(t, err)
+
+	_, err = Decode(bytes.NewReader([]byte{encodingVersion, 9, 0}), decodeBinaryEntry)
+	assert.NotNil(t, err)
+
+	_, err = Decode(bytes.NewReader(nil), decodeBinaryEntry)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkDecode(b *testing.B) {
+	sl := New(uint64(0))
+	for i := uint64(0); i < 100000; i++ {
+		sl.Insert(binaryEntry(i))
+	}
+
+	var buf bytes.Buffer
+	sl.Encode(&buf)
+	data := buf.Bytes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Decode(bytes.NewReader(data), decodeBinaryEntry)
+	}
+}
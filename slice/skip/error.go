@@ -0,0 +1,40 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"fmt"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// OutOfRangeError is returned by the E variants of the positional
+// operations when the position is not in the list.  It matches
+// common.ErrOutOfRange with errors.Is.
+type OutOfRangeError struct {
+	Position, Len uint64
+}
+
+func (oore OutOfRangeError) Error() string {
+	return fmt.Sprintf(`Position %d is out of range for a list of %d entries.`, oore.Position, oore.Len)
+}
+
+// Is reports whether target is common.ErrOutOfRange so that errors.Is
+// recognizes the error the same way for every structure.
+func (oore OutOfRangeError) Is(target error) bool {
+	return target == common.ErrOutOfRange
+}
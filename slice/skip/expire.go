@@ -0,0 +1,120 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"time"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// expiredAt returns a bool indicating if the provided entry is an
+// ExpiringEntry that has expired by t.
+func expiredAt(e Entry, t time.Time) bool {
+	ee, ok := e.(ExpiringEntry)
+	if !ok {
+		return false
+	}
+
+	expiry := ee.Expiry()
+	return !expiry.IsZero() && !expiry.After(t)
+}
+
+// expired returns a bool indicating if expiry is enabled and the
+// provided entry has expired.
+func (sl *SkipList) expired(e Entry) bool {
+	return sl.now != nil && expiredAt(e, sl.now())
+}
+
+// EnableExpiry turns on expiration for this list.  Reads that find
+// an ExpiringEntry past its expiry, as told by the provided clock,
+// delete it and carry on as if it were not there: Get, GetWithPosition,
+// Floor, Ceiling, Min, Max and Last purge the expired entries they
+// find, while iterators and Each skip over them.  Len and the
+// positional methods count expired entries until they are purged,
+// either by such a read or by ExpireBefore.  A nil clock uses
+// time.Now.
+//
+// As reads may now delete, they must not be run concurrently with
+// each other.  The wrapper in threadsafe/skip accounts for this.
+func (sl *SkipList) EnableExpiry(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	sl.now = now
+}
+
+// ExpiryEnabled returns a bool indicating if EnableExpiry has been
+// called on this list.
+func (sl *SkipList) ExpiryEnabled() bool {
+	return sl.now != nil
+}
+
+// ExpireBefore deletes every ExpiringEntry whose expiry is at or
+// before t and returns the deleted entries in order.  This works
+// whether or not expiry has been enabled.  The list is swept in a
+// single pass, relinking the nodes that remain as it goes, so this is
+// an O(n) operation regardless of how many entries are deleted.
+func (sl *SkipList) ExpireBefore(t time.Time) Entries {
+	if sl.num == 0 {
+		return nil
+	}
+
+	sl.unshare()
+	for i := uint8(0); i < sl.level; i++ {
+		sl.cache[i] = sl.head
+		sl.posCache[i] = 0
+	}
+
+	var deleted Entries
+	var pos uint64
+	var previous, next *node
+	for n := sl.head.forward[0]; n != nil; n = next {
+		next = n.forward[0]
+		if expiredAt(n.entry, t) {
+			deleted = append(deleted, n.entry)
+			// tell any iterator standing on n that it was removed
+			n.version++
+			sl.free.put(n)
+			continue
+		}
+
+		pos++
+		n.backward = previous
+		previous = n
+		for i := range n.forward {
+			sl.cache[i].forward[i] = n
+			sl.cache[i].widths[i] = pos - sl.posCache[i]
+			sl.cache[i] = n
+			sl.posCache[i] = pos
+		}
+	}
+
+	for i := uint8(0); i < sl.level; i++ {
+		sl.cache[i].forward[i] = nil
+		sl.cache[i].widths[i] = 0
+	}
+
+	sl.report(common.MetricDeletes, int64(sl.num-pos))
+	sl.report(common.MetricNodes, -int64(sl.num-pos))
+	sl.num = pos
+	for sl.level > 1 && sl.head.forward[sl.level-1] == nil {
+		sl.level--
+	}
+
+	return deleted
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type expiringEntry struct {
+	key    uint64
+	expiry time.Time
+}
+
+func (ee expiringEntry) Compare(other Entry) int {
+	o := other.(expiringEntry)
+	switch {
+	case ee.key < o.key:
+		return -1
+	case ee.key > o.key:
+		return 1
+	}
+
+	return 0
+}
+
+func (ee expiringEntry) Expiry() time.Time {
+	return ee.expiry
+}
+
+var epoch = time.Unix(1000, 0)
+
+func key(k uint64) expiringEntry {
+	return expiringEntry{key: k}
+}
+
+func expiringAt(k uint64, seconds int) expiringEntry {
+	return expiringEntry{key: k, expiry: epoch.Add(time.Duration(seconds) * time.Second)}
+}
+
+func keys(entries Entries) []uint64 {
+	result := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.(expiringEntry).key)
+	}
+	return result
+}
+
+// clock returns a list with expiry enabled along with a pointer to the
+// time its clock reports.
+func clock(sl *SkipList) *time.Time {
+	now := epoch
+	sl.EnableExpiry(func() time.Time { return now })
+	return &now
+}
+
+func TestExpireBefore(t *testing.T) {
+	sl := NewWithSource(uint8(0), newXorshift(1))
+	for i := uint64(0); i < 200; i++ {
+		if i%7 == 0 {
+			sl.Insert(key(i)) // never expires
+			continue
+		}
+		sl.Insert(expiringAt(i, int(i%10)))
+	}
+
+	deleted := sl.ExpireBefore(epoch.Add(4 * time.Second))
+	assert.Nil(t, sl.Validate())
+
+	var expected, remaining []uint64
+	for i := uint64(0); i < 200; i++ {
+		if i%7 != 0 && i%10 <= 4 {
+			expected = append(expected, i)
+		} else {
+			remaining = append(remaining, i)
+		}
+	}
+	assert.Equal(t, expected, keys(deleted))
+	assert.Equal(t, uint64(len(remaining)), sl.Len())
+
+	var result Entries
+	sl.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, remaining, keys(result))
+	for i, k := range remaining {
+		assert.Equal(t, k, sl.ByPosition(uint64(i)).(expiringEntry).key)
+	}
+
+	sl.Insert(expiringAt(3, 100))
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, uint64(3), sl.Get(key(3))[0].(expiringEntry).key)
+}
+
+func TestExpireBeforeAll(t *testing.T) {
+	sl := New(uint64(0))
+	for i := uint64(0); i < 50; i++ {
+		sl.Insert(expiringAt(i, 1))
+	}
+
+	assert.Len(t, sl.ExpireBefore(epoch.Add(time.Second)), 50)
+	assert.Equal(t, uint64(0), sl.Len())
+	assert.Nil(t, sl.Validate())
+	assert.Nil(t, sl.Min())
+	assert.Nil(t, sl.ExpireBefore(epoch))
+
+	sl.Insert(key(1), key(2))
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, uint64(2), sl.Len())
+}
+
+func TestExpireBeforeNone(t *testing.T) {
+	sl := New(uint64(0))
+	for i := uint64(0); i < 50; i++ {
+		sl.Insert(expiringAt(i, 10))
+	}
+
+	assert.Len(t, sl.ExpireBefore(epoch), 0)
+	assert.Equal(t, uint64(50), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
+func TestExpireBeforeLiveIterator(t *testing.T) {
+	for _, sl := range []*SkipList{New(uint8(0)), NewWithFreeList(uint8(0), 10)} {
+		sl.Insert(key(1), expiringAt(2, 1), expiringAt(3, 1), key(4))
+
+		iter := sl.Iter(key(0))
+		assert.True(t, iter.Next())
+		assert.True(t, iter.Next())
+		assert.Equal(t, uint64(2), iter.Value().(expiringEntry).key)
+
+		assert.Equal(t, []uint64{2, 3}, keys(sl.ExpireBefore(epoch.Add(time.Second))))
+		assert.Equal(t, []uint64{4}, keys(iter.exhaust()))
+
+		// the removed nodes may be reused by later inserts
+		iter = sl.Iter(key(0))
+		assert.True(t, iter.Next())
+		sl.Insert(expiringAt(5, 1), expiringAt(6, 1))
+		sl.ExpireBefore(epoch.Add(time.Second))
+		sl.Insert(key(2), key(7))
+		assert.Equal(t, []uint64{2, 4, 7}, keys(iter.exhaust()))
+	}
+}
+
+func TestExpireBeforeSnapshot(t *testing.T) {
+	sl := New(uint64(0))
+	for i := uint64(0); i < 20; i++ {
+		sl.Insert(expiringAt(i, int(i)))
+	}
+
+	snapshot := sl.Snapshot()
+	sl.ExpireBefore(epoch.Add(9 * time.Second))
+
+	assert.Equal(t, uint64(10), sl.Len())
+	assert.Equal(t, uint64(20), snapshot.Len())
+	assert.Nil(t, sl.Validate())
+	assert.Nil(t, snapshot.Validate())
+}
+
+func TestExpiryGet(t *testing.T) {
+	sl := New(uint64(0))
+	now := clock(sl)
+	sl.Insert(expiringAt(1, 5), key(2), expiringAt(3, 10))
+
+	assert.Equal(t, Entries{expiringAt(1, 5), key(2), expiringAt(3, 10)},
+		sl.Get(key(1), key(2), key(3)))
+
+	*now = epoch.Add(5 * time.Second)
+	assert.Equal(t, Entries{nil, key(2), expiringAt(3, 10)},
+		sl.Get(key(1), key(2), key(3)))
+	assert.Equal(t, uint64(2), sl.Len())
+	assert.Nil(t, sl.Validate())
+
+	e, pos := sl.GetWithPosition(key(3))
+	assert.Equal(t, expiringAt(3, 10), e)
+	assert.Equal(t, uint64(1), pos)
+
+	*now = epoch.Add(time.Hour)
+	e, pos = sl.GetWithPosition(key(3))
+	assert.Nil(t, e)
+	assert.Equal(t, uint64(0), pos)
+	assert.Equal(t, uint64(1), sl.Len())
+}
+
+func TestExpiryMulti(t *testing.T) {
+	sl := NewMulti(uint64(0))
+	now := clock(sl)
+	sl.Insert(expiringAt(1, 1), expiringAt(1, 3), expiringAt(1, 2))
+
+	*now = epoch.Add(2 * time.Second)
+	assert.Equal(t, expiringAt(1, 3), sl.Get(key(1))[0])
+	// only expired entries ahead of the first live one are purged
+	assert.Equal(t, uint64(2), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
+func TestExpiryFloorCeiling(t *testing.T) {
+	sl := New(uint64(0))
+	now := clock(sl)
+	sl.Insert(key(10), expiringAt(20, 1), expiringAt(30, 1), key(40), expiringAt(50, 1))
+	*now = epoch.Add(time.Second)
+
+	assert.Equal(t, key(10), sl.Floor(key(35)))
+	assert.Equal(t, uint64(3), sl.Len())
+	assert.Equal(t, key(40), sl.Ceiling(key(15)))
+	assert.Equal(t, uint64(3), sl.Len())
+	assert.Nil(t, sl.Ceiling(key(45)))
+	assert.Equal(t, key(40), sl.Floor(key(60)))
+	assert.Equal(t, uint64(2), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
+func TestExpiryMinMax(t *testing.T) {
+	sl := New(uint64(0))
+	now := clock(sl)
+	sl.Insert(expiringAt(1, 1), expiringAt(2, 1), key(3), expiringAt(4, 1))
+	*now = epoch.Add(time.Second)
+
+	assert.Equal(t, key(3), sl.Min())
+	assert.Equal(t, key(3), sl.Max())
+	assert.Equal(t, key(3), sl.Last())
+	assert.Equal(t, uint64(1), sl.Len())
+
+	sl.Delete(key(3))
+	sl.Insert(expiringAt(5, 1))
+	assert.Nil(t, sl.Min())
+	assert.Equal(t, uint64(0), sl.Len())
+}
+
+func TestExpiryIteration(t *testing.T) {
+	sl := New(uint64(0))
+	now := clock(sl)
+	for i := uint64(0); i < 10; i++ {
+		sl.Insert(expiringAt(i, int(i%3)+1))
+	}
+	*now = epoch.Add(time.Second) // keys divisible by three expire
+
+	live := []uint64{1, 2, 4, 5, 7, 8}
+	assert.Equal(t, live, keys(sl.Iter(key(0)).exhaust()))
+	assert.Equal(t, []uint64{8, 7, 5, 4, 2, 1}, keys(sl.IterReverse(key(9)).exhaust()))
+	assert.Equal(t, []uint64{4, 5}, keys(sl.IterRange(key(3), key(7)).exhaust()))
+
+	var result Entries
+	for e := range sl.All() {
+		result = append(result, e)
+	}
+	assert.Equal(t, live, keys(result))
+
+	iter := sl.Iter(key(3))
+	assert.True(t, iter.Next())
+	assert.Equal(t, key(4).key, iter.Value().(expiringEntry).key)
+	assert.True(t, iter.Prev())
+	assert.Equal(t, key(2).key, iter.Value().(expiringEntry).key)
+
+	// iteration never purges
+	assert.Equal(t, uint64(10), sl.Len())
+}
+
+func TestExpiryPropagates(t *testing.T) {
+	sl := New(uint64(0))
+	now := clock(sl)
+	sl.Insert(expiringAt(1, 1), key(2))
+	*now = epoch.Add(time.Second)
+
+	assert.True(t, sl.Snapshot().ExpiryEnabled())
+	assert.Equal(t, Entries{nil}, sl.Snapshot().Get(key(1)))
+	assert.Equal(t, Entries{nil}, sl.Clone().Get(key(1)))
+	_, right := sl.SplitAt(0)
+	assert.True(t, right.ExpiryEnabled())
+	assert.False(t, New(uint64(0)).ExpiryEnabled())
+}
+
+func TestExpiryDisabled(t *testing.T) {
+	sl := New(uint64(0))
+	sl.Insert(expiringAt(1, -1))
+
+	assert.Equal(t, Entries{expiringAt(1, -1)}, sl.Get(key(1)))
+	assert.Equal(t, uint64(1), sl.Len())
+}
+
+func BenchmarkExpireBefore(b *testing.B) {
+	numItems := uint64(10000)
+	entries := make(Entries, 0, numItems)
+	for i := uint64(0); i < numItems; i++ {
+		entries = append(entries, expiringAt(i, int(i)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		sl := New(uint64(0))
+		sl.Insert(entries...)
+		b.StartTimer()
+		// expire the oldest tenth
+		sl.ExpireBefore(epoch.Add(time.Duration(numItems/10) * time.Second))
+	}
+}
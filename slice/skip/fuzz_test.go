@@ -0,0 +1,189 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/fuzz"
+)
+
+func fuzzSeeds(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 1, 2, 4, 0, 7, 1})
+	f.Add([]byte("the quick brown fox jumps over the lazy dog"))
+	seed := make([]byte, 512)
+	rand.New(rand.NewSource(0)).Read(seed)
+	f.Add(seed)
+}
+
+func fuzzList(ops *fuzz.Ops) *SkipList {
+	if ops.Bool() {
+		sl := NewWithFreeList(uint8(0), 8)
+		sl.rng = rand.New(rand.NewSource(int64(ops.Uint64n(256))))
+		return sl
+	}
+
+	return NewWithSource(uint8(0), rand.NewSource(int64(ops.Uint64n(256))))
+}
+
+func fuzzValues(sl *SkipList) []uint64 {
+	values := make([]uint64, 0, sl.Len())
+	sl.Each(func(e Entry) bool {
+		values = append(values, uint64(e.(mockEntry)))
+		return true
+	})
+
+	return values
+}
+
+// FuzzSkipList checks lookups, deletes, and rank and position queries
+// against a sorted set.
+func FuzzSkipList(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := fuzz.NewOps(data)
+		sl, model := fuzzList(ops), &fuzz.Sorted[uint64]{}
+		for op, ok := ops.Next(8); ok; op, ok = ops.Next(8) {
+			key := ops.Uint64n(64)
+			e := mockEntry(key)
+			switch op {
+			case 0:
+				assert.Equal(t, model.Insert(key), sl.Insert(e)[0] == nil)
+			case 1:
+				assert.Equal(t, model.Delete(key), sl.Delete(e)[0] != nil)
+			case 2:
+				assert.Equal(t, model.Contains(key), sl.Contains(e))
+			case 3:
+				assert.Equal(t, uint64(model.Rank(key)), sl.Rank(e))
+			case 4:
+				floor, ok := model.Floor(key)
+				if ok {
+					assert.Equal(t, mockEntry(floor), sl.Floor(e))
+				} else {
+					assert.Nil(t, sl.Floor(e))
+				}
+				ceiling, ok := model.Ceiling(key)
+				if ok {
+					assert.Equal(t, mockEntry(ceiling), sl.Ceiling(e))
+				} else {
+					assert.Nil(t, sl.Ceiling(e))
+				}
+			case 5:
+				if model.Len() > 0 {
+					i := int(key) % model.Len()
+					assert.Equal(t, mockEntry(model.At(i)), sl.ByPosition(uint64(i)))
+				}
+			case 6:
+				if model.Len() > 0 {
+					i := int(key) % model.Len()
+					assert.True(t, model.Delete(model.At(i)))
+					assert.NotNil(t, sl.DeleteAtPosition(uint64(i)))
+				}
+			case 7:
+				left, right := sl.SplitAt(key)
+				if right != nil {
+					if !assert.Nil(t, right.Validate()) {
+						return
+					}
+					joined, err := left.Join(right)
+					assert.Nil(t, err)
+					sl = joined
+				}
+			}
+
+			if !assert.Nil(t, sl.Validate()) || !assert.Equal(t, uint64(model.Len()), sl.Len()) {
+				return
+			}
+		}
+
+		if model.Len() > 0 {
+			assert.Equal(t, model.Values(), fuzzValues(sl))
+		}
+	})
+}
+
+// FuzzSkipListPositions checks the positional operations, whose width
+// bookkeeping is the subtlest part of the list, against a plain list.
+func FuzzSkipListPositions(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := fuzz.NewOps(data)
+		sl, model := fuzzList(ops), &fuzz.List[uint64]{}
+		for op, ok := ops.Next(6); ok; op, ok = ops.Next(6) {
+			pos, value := ops.Intn(model.Len()+2), ops.Uint64n(256)
+			switch op {
+			case 0:
+				model.InsertAt(pos, value)
+				sl.InsertAtPosition(uint64(pos), mockEntry(value))
+			case 1:
+				expected, ok := model.DeleteAt(pos)
+				if ok {
+					assert.Equal(t, mockEntry(expected), sl.DeleteAtPosition(uint64(pos)))
+				} else {
+					assert.Nil(t, sl.DeleteAtPosition(uint64(pos)))
+				}
+			case 2:
+				model.ReplaceAt(pos, value)
+				sl.ReplaceAtPosition(uint64(pos), mockEntry(value))
+			case 3:
+				expected, ok := model.At(pos)
+				if ok {
+					assert.Equal(t, mockEntry(expected), sl.ByPosition(uint64(pos)))
+				} else {
+					assert.Nil(t, sl.ByPosition(uint64(pos)))
+				}
+			case 4:
+				right := model.SplitAt(pos + 1)
+				left, split := sl.SplitAt(uint64(pos))
+				if split == nil {
+					assert.Equal(t, 0, right.Len())
+				} else {
+					if !assert.Nil(t, split.Validate()) {
+						return
+					}
+					assert.Equal(t, right.Values(), fuzzValues(split))
+					if !assert.Nil(t, left.Validate()) {
+						return
+					}
+					left.Merge(split)
+					assert.Equal(t, uint64(0), split.Len())
+				}
+				model.Append(right)
+			case 5:
+				stop := pos + ops.Intn(model.Len()+1)
+				deleted := sl.DeleteRange(uint64(pos), uint64(stop))
+				expected := model.DeleteRange(pos, stop)
+				assert.Len(t, deleted, len(expected))
+				for i := range expected {
+					assert.Equal(t, mockEntry(expected[i]), deleted[i])
+				}
+			}
+
+			if !assert.Nil(t, sl.Validate()) || !assert.Equal(t, uint64(model.Len()), sl.Len()) {
+				return
+			}
+		}
+
+		if model.Len() > 0 {
+			assert.Equal(t, model.Values(), fuzzValues(sl))
+		}
+	})
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package skip
 
+import "time"
+
 // Entry defines items that can be inserted into the skip list.
 // This will also be the type returned from a query.
 type Entry interface {
@@ -25,15 +27,40 @@ type Entry interface {
 	Compare(Entry) int
 }
 
+// WeightedEntry is an entry that exposes a weight used by
+// WeightedSample.  An entry's chance of being sampled is proportional
+// to its weight.
+type WeightedEntry interface {
+	Entry
+	// Weight returns the relative weight of this entry.  Entries
+	// with a weight of zero or less are never sampled.
+	Weight() float64
+}
+
+// ExpiringEntry is an entry that stops being visible once its expiry
+// passes.  See SkipList.EnableExpiry and SkipList.ExpireBefore.
+type ExpiringEntry interface {
+	Entry
+	// Expiry returns the time at which this entry expires.  The
+	// zero time means the entry never expires.
+	Expiry() time.Time
+}
+
 // Entries is a typed list of interface Entry.
 type Entries []Entry
 
 // Iterator defines an interface that allows a consumer to iterate
-// all results of a query.  All values will be visited in-order.
+// all results of a query.  All values will be visited in-order.  The
+// list may be modified between calls; entries inserted ahead of the
+// iterator are visited, deleted ones are not, and deleting the entry
+// the iterator is at makes it carry on from the next greater entry.
 type Iterator interface {
 	// Next returns a bool indicating if there is future value
 	// in the iterator and moves the iterator to that value.
 	Next() bool
+	// Prev returns a bool indicating if there is a value before the
+	// current one in iteration order and moves the iterator to it.
+	Prev() bool
 	// Value returns an Entry representing the iterator's current
 	// position.  If there is no value, this returns nil.
 	Value() Entry
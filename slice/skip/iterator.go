@@ -16,14 +16,104 @@ limitations under the License.
 
 package skip
 
+import "time"
+
 const iteratorExhausted = -2
 
 // iterator represents an object that can be iterated.  It will
 // return false on Next and nil on Value if there are no further
 // values to be iterated.
+//
+// An iterator may be used while its list is modified.  Entries
+// inserted ahead of the iterator are visited and entries deleted
+// before it reaches them are not.  If the entry the iterator is at is
+// deleted, or the list is split, merged or copied because a snapshot
+// shares it, the iterator carries on from the first entry beyond the
+// last one it visited, found by comparison.  In a list holding
+// duplicates that may pass over entries equal to the deleted one.
 type iterator struct {
 	first bool
 	n     *node
+	// sl is the list iterated, and version and epoch are those of
+	// n and the list when the iterator reached n.  entry is n's
+	// entry then, which survives n being removed.
+	sl      *SkipList
+	version uint64
+	epoch   uint64
+	entry   Entry
+	// reverse indicates that Next walks toward lesser keys.
+	reverse bool
+	// stop, if not nil, ends a forward iteration at the first
+	// value equal to or greater than it.
+	stop Entry
+	// expiring indicates that entries which had expired by now,
+	// when the iterator was created, are skipped.
+	expiring bool
+	now      time.Time
+}
+
+// land moves the iterator to the provided node, which may be nil.
+func (iter *iterator) land(n *node) {
+	iter.n = n
+	if n != nil {
+		iter.version, iter.entry = n.version, n.entry
+	}
+	if iter.sl != nil {
+		iter.epoch = iter.sl.epoch
+	}
+}
+
+// stale returns a bool indicating if the iterator's node has been
+// removed from the list or moved by a split or merge since the
+// iterator reached it.
+func (iter *iterator) stale() bool {
+	return iter.sl != nil && iter.n != nil &&
+		(iter.n.version != iter.version || iter.sl.epoch != iter.epoch)
+}
+
+func (iter *iterator) step(backward bool) {
+	if iter.stale() {
+		iter.resume(backward, false)
+		return
+	}
+
+	if backward {
+		iter.land(iter.n.backward)
+	} else {
+		iter.land(iter.n.forward[0])
+	}
+}
+
+// resume moves a stale iterator to the first node beyond its entry in
+// the provided direction, or to the node holding its entry if
+// inclusive is set.
+func (iter *iterator) resume(backward, inclusive bool) {
+	sl, e := iter.sl, iter.entry
+	switch {
+	case !backward && inclusive:
+		n, _ := sl.search(e, nil, nil)
+		iter.land(n)
+	case !backward:
+		n, _ := sl.searchAfter(e, nil, nil)
+		iter.land(n)
+	case inclusive:
+		iter.land(sl.floorNode(e))
+	default:
+		n, _ := sl.search(e, nil, nil)
+		if n == nil {
+			iter.land(sl.lastNode())
+		} else {
+			iter.land(n.backward)
+		}
+	}
+}
+
+// skipExpired moves the iterator past any expired entries in the
+// provided direction.
+func (iter *iterator) skipExpired(backward bool) {
+	for iter.expiring && iter.n != nil && expiredAt(iter.n.entry, iter.now) {
+		iter.step(backward)
+	}
 }
 
 // Next returns a bool indicating if there are any further values
@@ -31,24 +121,47 @@ type iterator struct {
 func (iter *iterator) Next() bool {
 	if iter.first {
 		iter.first = false
-		return iter.n != nil
+		if iter.stale() {
+			iter.resume(iter.reverse, true)
+		}
+	} else if iter.n != nil {
+		iter.step(iter.reverse)
 	}
+	iter.skipExpired(iter.reverse)
 
+	if iter.n != nil && iter.stop != nil && iter.n.Compare(iter.stop) >= 0 {
+		iter.n = nil
+	}
+
+	return iter.n != nil
+}
+
+// Prev moves the iterator back to the value visited before the
+// current one, that is, in the opposite direction to Next.  Returns
+// a bool indicating if there is such a value.  Once the iterator
+// has moved past either end of the list it cannot be moved back.
+func (iter *iterator) Prev() bool {
+	iter.first = false
 	if iter.n == nil {
 		return false
 	}
 
-	iter.n = iter.n.forward[0]
+	iter.step(!iter.reverse)
+	iter.skipExpired(!iter.reverse)
 	return iter.n != nil
 }
 
 // Value returns an Entry representing the iterator's present
 // position in the query.  Returns nil if no values remain to iterate.
+// If the entry has since been deleted it is still returned.
 func (iter *iterator) Value() Entry {
 	if iter.n == nil {
 		return nil
 	}
 
+	if iter.stale() {
+		return iter.entry
+	}
 	return iter.n.entry
 }
 
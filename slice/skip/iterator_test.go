@@ -55,3 +55,133 @@ func TestIterate(t *testing.T) {
 	assert.False(t, iter.Next())
 	assert.Nil(t, iter.Value())
 }
+
+func mockEntries(keys ...uint64) Entries {
+	entries := make(Entries, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, newMockEntry(key))
+	}
+
+	return entries
+}
+
+func TestIterateWhileDeleting(t *testing.T) {
+	// a free list reuses deleted nodes, which must not derail the
+	// iterator
+	sl := NewWithFreeList(uint8(0), 16)
+	sl.Insert(mockEntries(1, 2, 3, 4, 5, 6)...)
+
+	var visited Entries
+	iter := sl.Iter(newMockEntry(0))
+	for iter.Next() {
+		visited = append(visited, iter.Value())
+		switch iter.Value() {
+		case newMockEntry(2):
+			// the current entry and the one after it
+			sl.Delete(newMockEntry(2), newMockEntry(3))
+			sl.Insert(newMockEntry(10))
+		case newMockEntry(4):
+			sl.Insert(newMockEntry(7), newMockEntry(0))
+		}
+	}
+
+	assert.Equal(t, mockEntries(1, 2, 4, 5, 6, 7, 10), visited)
+	assert.Nil(t, sl.Validate())
+}
+
+func TestIterateReverseWhileDeleting(t *testing.T) {
+	sl := NewWithFreeList(uint8(0), 16)
+	sl.Insert(mockEntries(1, 2, 3, 4, 5)...)
+
+	var visited Entries
+	iter := sl.IterReverse(newMockEntry(10))
+	for iter.Next() {
+		visited = append(visited, iter.Value())
+		if iter.Value() == newMockEntry(4) {
+			sl.Delete(newMockEntry(4), newMockEntry(3))
+		}
+	}
+	assert.Equal(t, mockEntries(5, 4, 2, 1), visited)
+
+	// Prev returns toward greater entries from a deleted one
+	iter = sl.Iter(newMockEntry(2))
+	assert.True(t, iter.Next())
+	assert.True(t, iter.Next())
+	assert.Equal(t, newMockEntry(5), iter.Value())
+	sl.Delete(newMockEntry(5))
+	assert.Equal(t, newMockEntry(5), iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, newMockEntry(2), iter.Value())
+}
+
+func TestIterateFromDeletedStart(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntries(1, 3, 5)...)
+
+	iter := sl.Iter(newMockEntry(2))
+	sl.Delete(newMockEntry(3))
+	sl.Insert(newMockEntry(4))
+
+	assert.Equal(t, mockEntries(4, 5), iter.(*iterator).exhaust())
+}
+
+func TestIterateWhileSplitting(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntries(1, 2, 3, 4, 5, 6)...)
+
+	var visited Entries
+	iter := sl.Iter(newMockEntry(0))
+	for iter.Next() {
+		visited = append(visited, iter.Value())
+		if iter.Value() == newMockEntry(4) {
+			// keeps 1 through 3, so 4 and beyond leave the list
+			sl.SplitAt(2)
+		}
+	}
+	assert.Equal(t, mockEntries(1, 2, 3, 4), visited)
+
+	sl = New(uint8(0))
+	sl.Insert(mockEntries(1, 2, 3, 4, 5, 6)...)
+	visited = nil
+	iter = sl.Iter(newMockEntry(0))
+	for iter.Next() {
+		visited = append(visited, iter.Value())
+		if iter.Value() == newMockEntry(2) {
+			sl.DeleteRange(1, 4)
+		}
+	}
+	assert.Equal(t, mockEntries(1, 2, 5, 6), visited)
+}
+
+func TestIterateAcrossSnapshot(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(mockEntries(1, 2, 3, 4)...)
+
+	iter := sl.Iter(newMockEntry(0))
+	assert.True(t, iter.Next())
+	snapshot := sl.Snapshot()
+	// the write copies the list's nodes away from the snapshot's
+	sl.Insert(newMockEntry(5))
+	sl.Delete(newMockEntry(2))
+
+	assert.Equal(t, mockEntries(3, 4, 5), iter.(*iterator).exhaust())
+	assert.Equal(t, mockEntries(1, 2, 3, 4), snapshot.Iter(newMockEntry(0)).(*iterator).exhaust())
+}
+
+func TestEachWhileDeleting(t *testing.T) {
+	sl := NewWithFreeList(uint8(0), 16)
+	sl.Insert(mockEntries(1, 2, 3, 4, 5, 6)...)
+
+	var visited Entries
+	sl.Each(func(e Entry) bool {
+		visited = append(visited, e)
+		sl.Delete(e)
+		if e == newMockEntry(3) {
+			sl.Delete(newMockEntry(4))
+		}
+		return true
+	})
+
+	assert.Equal(t, mockEntries(1, 2, 3, 5, 6), visited)
+	assert.Equal(t, uint64(0), sl.Len())
+}
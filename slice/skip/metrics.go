@@ -0,0 +1,38 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import "github.com/Workiva/go-datastructures/common"
+
+// SetMetrics makes this list report to the provided metrics, or stop
+// reporting if nil.  Reported are common.MetricInserts,
+// MetricDeletes, MetricSearches, MetricNodes, which counts entries as
+// each has its own node, and MetricLevel, which observes the level of
+// every new node.  The nodes gauge is raised by the length of the list
+// when metrics are set.  Lists derived from this one by Snapshot,
+// Clone, or SplitAt do not report to these metrics.
+func (sl *SkipList) SetMetrics(metrics common.Metrics) {
+	sl.metrics = metrics
+	sl.report(common.MetricNodes, int64(sl.num))
+}
+
+// report adds delta to the named metric if metrics are set.
+func (sl *SkipList) report(name string, delta int64) {
+	if sl.metrics != nil {
+		sl.metrics.Add(name, delta)
+	}
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package skip
 
-import "github.com/stretchr/testify/mock"
+import (
+	"sync"
+
+	"github.com/stretchr/testify/mock"
+)
 
 type mockEntry uint64
 
@@ -46,6 +50,11 @@ func (mi *mockIterator) Next() bool {
 	return args.Bool(0)
 }
 
+func (mi *mockIterator) Prev() bool {
+	args := mi.Called()
+	return args.Bool(0)
+}
+
 func (mi *mockIterator) Value() Entry {
 	args := mi.Called()
 	result, ok := args.Get(0).(Entry)
@@ -59,3 +68,28 @@ func (mi *mockIterator) Value() Entry {
 func (mi *mockIterator) exhaust() Entries {
 	return nil
 }
+
+type mockMetrics struct {
+	lock         sync.Mutex
+	counts       map[string]int64
+	observations map[string][]float64
+}
+
+func (mm *mockMetrics) Add(name string, delta int64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.counts[name] += delta
+}
+
+func (mm *mockMetrics) Observe(name string, value float64) {
+	mm.lock.Lock()
+	defer mm.lock.Unlock()
+	mm.observations[name] = append(mm.observations[name], value)
+}
+
+func newMockMetrics() *mockMetrics {
+	return &mockMetrics{
+		counts:       make(map[string]int64),
+		observations: make(map[string][]float64),
+	}
+}
@@ -16,6 +16,16 @@ limitations under the License.
 
 package skip
 
+import "unsafe"
+
+// Per-list, per-node and per-level byte counts for SizeOf.
+const (
+	listSize    = uint64(unsafe.Sizeof(SkipList{}))
+	nodeSize    = uint64(unsafe.Sizeof(node{}))
+	pointerSize = uint64(unsafe.Sizeof((*node)(nil)))
+	widthSize   = uint64(unsafe.Sizeof(uint64(0)))
+)
+
 type widths []uint64
 
 type nodes []*node
@@ -28,8 +38,21 @@ type node struct {
 	// and the forward pointers so we can access skip list
 	// values by position in logarithmic time.
 	widths widths
+	// backward points to the previous node at the bottom level
+	// and is nil for the first node in the list.
+	backward *node
 	// entry is the associated value with this node.
 	entry Entry
+	// version counts the times this node has been removed from a
+	// list, so an iterator standing on it can tell that it was even
+	// if the node has since been reused.
+	version uint64
+}
+
+// sizeOf returns the estimated number of bytes used by this node, not
+// including its entry.
+func (n *node) sizeOf() uint64 {
+	return nodeSize + uint64(cap(n.forward))*pointerSize + uint64(cap(n.widths))*widthSize
 }
 
 func (n *node) Compare(e Entry) int {
@@ -46,3 +69,64 @@ func newNode(entry Entry, maxLevels uint8) *node {
 		widths:  make(widths, maxLevels),
 	}
 }
+
+// freeList holds nodes that have been removed from a list so that
+// later inserts can reuse them, and their forward and width lists,
+// rather than allocating.  Nodes are kept by level as a node can only
+// be reused at the level it was created with.  A nil freeList recycles
+// nothing and allocates every node.
+type freeList struct {
+	levels   []nodes
+	num, max int
+}
+
+// get returns a node with the provided entry and level, reusing a
+// free node if one of that level is available.
+func (fl *freeList) get(entry Entry, level uint8) *node {
+	if fl == nil || int(level) > len(fl.levels) || len(fl.levels[level-1]) == 0 {
+		return newNode(entry, level)
+	}
+
+	free := fl.levels[level-1]
+	n := free[len(free)-1]
+	free[len(free)-1] = nil
+	fl.levels[level-1] = free[:len(free)-1]
+	fl.num--
+	n.entry = entry
+	// an iterator that stood on the node before it was freed must see
+	// that it has moved, however it was removed
+	n.version++
+	return n
+}
+
+// put clears the provided node and keeps it for reuse unless this list
+// is already full.  The node must no longer be reachable from a list.
+func (fl *freeList) put(n *node) {
+	if fl == nil || fl.num >= fl.max {
+		return
+	}
+
+	clear(n.forward)
+	clear(n.widths)
+	n.backward = nil
+	n.entry = nil
+	for len(fl.levels) < len(n.forward) {
+		fl.levels = append(fl.levels, nil)
+	}
+	fl.levels[len(n.forward)-1] = append(fl.levels[len(n.forward)-1], n)
+	fl.num++
+}
+
+// fork returns an empty freeList with the same capacity as this one
+// for use by a list derived from this one, or nil if this is nil.
+func (fl *freeList) fork() *freeList {
+	if fl == nil {
+		return nil
+	}
+
+	return newFreeList(fl.max)
+}
+
+func newFreeList(max int) *freeList {
+	return &freeList{max: max}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+type orderedNode[K cmp.Ordered, V any] struct {
+	// forward denotes the forward pointing pointers in this
+	// node.
+	forward []*orderedNode[K, V]
+	// widths keeps track of the distance between this pointer
+	// and the forward pointers so values can be accessed by
+	// position in logarithmic time.
+	widths widths
+	key    K
+	value  V
+}
+
+func newOrderedNode[K cmp.Ordered, V any](key K, value V, maxLevels uint8) *orderedNode[K, V] {
+	return &orderedNode[K, V]{
+		key:     key,
+		value:   value,
+		forward: make([]*orderedNode[K, V], maxLevels),
+		widths:  make(widths, maxLevels),
+	}
+}
+
+// OrderedSkipList is a skiplist keyed by an ordered type.  It shares
+// the algorithms of SkipList but stores typed keys and values
+// directly, avoiding the interface boxing and dynamic Compare calls
+// that dominate the cost of SkipList operations.
+type OrderedSkipList[K cmp.Ordered, V any] struct {
+	maxLevel, level uint8
+	head            *orderedNode[K, V]
+	num             uint64
+	// a list of nodes that can be reused, should reduce
+	// the number of allocations in the insert/delete case.
+	cache    []*orderedNode[K, V]
+	posCache widths
+	rng      *rand.Rand
+}
+
+func (sl *OrderedSkipList[K, V]) search(key K, update []*orderedNode[K, V], widths widths) (*orderedNode[K, V], uint64) {
+	if sl.num == 0 { // nothing in the list
+		return nil, 1
+	}
+
+	var pos uint64 = 0
+	var offset uint8
+	n := sl.head
+	for i := uint8(0); i <= sl.level; i++ {
+		offset = sl.level - i
+		for n.forward[offset] != nil && n.forward[offset].key < key {
+			pos += n.widths[offset]
+			n = n.forward[offset]
+		}
+
+		if update != nil {
+			update[offset] = n
+			widths[offset] = pos
+		}
+	}
+
+	return n.forward[0], pos + 1
+}
+
+func (sl *OrderedSkipList[K, V]) searchByPosition(position uint64) *orderedNode[K, V] {
+	if sl.num == 0 || position > sl.num {
+		return nil
+	}
+
+	var pos uint64 = 0
+	var offset uint8
+	n := sl.head
+	for i := uint8(0); i <= sl.level; i++ {
+		offset = sl.level - i
+		for n.widths[offset] != 0 && pos+n.widths[offset] <= position {
+			pos += n.widths[offset]
+			n = n.forward[offset]
+		}
+	}
+
+	return n
+}
+
+// Get returns the value associated with the provided key and a bool
+// indicating if the key was found.  This is an O(log n) operation.
+func (sl *OrderedSkipList[K, V]) Get(key K) (V, bool) {
+	n, _ := sl.search(key, nil, nil)
+	if n == nil || n.key != key {
+		var zero V
+		return zero, false
+	}
+
+	return n.value, true
+}
+
+// GetWithPosition returns the value associated with the provided key,
+// the position of that key within the list and a bool indicating if
+// the key was found.
+func (sl *OrderedSkipList[K, V]) GetWithPosition(key K) (V, uint64, bool) {
+	n, pos := sl.search(key, nil, nil)
+	if n == nil || n.key != key {
+		var zero V
+		return zero, 0, false
+	}
+
+	return n.value, pos - 1, true
+}
+
+// ByPosition returns the key and value at the given position and a
+// bool indicating if the position exists.
+func (sl *OrderedSkipList[K, V]) ByPosition(position uint64) (K, V, bool) {
+	n := sl.searchByPosition(position + 1)
+	if n == nil {
+		var key K
+		var value V
+		return key, value, false
+	}
+
+	return n.key, n.value, true
+}
+
+// Insert will associate the provided value with the provided key.
+// If the key already existed, the overwritten value is returned
+// along with true.  This is an O(log n) operation.
+func (sl *OrderedSkipList[K, V]) Insert(key K, value V) (V, bool) {
+	cache, posCache := sl.cache, sl.posCache
+	n, pos := sl.search(key, cache, posCache)
+	if n != nil && n.key == key { // a simple update in this case
+		old := n.value
+		n.value = value
+		return old, true
+	}
+	sl.num++
+
+	nodeLevel := generateLevel(sl.rng, nil, sl.maxLevel)
+	if nodeLevel > sl.level {
+		for i := sl.level; i < nodeLevel; i++ {
+			cache[i] = sl.head
+		}
+		sl.level = nodeLevel
+	}
+
+	nn := newOrderedNode(key, value, nodeLevel)
+	for i := uint8(0); i < nodeLevel; i++ {
+		nn.forward[i] = cache[i].forward[i]
+		cache[i].forward[i] = nn
+		formerWidth := cache[i].widths[i]
+		if formerWidth == 0 {
+			nn.widths[i] = 0
+		} else {
+			nn.widths[i] = posCache[i] + formerWidth + 1 - pos
+		}
+
+		if cache[i].forward[i] != nil {
+			cache[i].widths[i] = pos - posCache[i]
+		}
+	}
+
+	for i := nodeLevel; i < sl.level; i++ {
+		if cache[i].forward[i] == nil {
+			continue
+		}
+		cache[i].widths[i]++
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Delete removes the provided key from the list and returns the value
+// that was associated with it along with a bool indicating if the key
+// was found.  This is an O(log n) operation.
+func (sl *OrderedSkipList[K, V]) Delete(key K) (V, bool) {
+	n, _ := sl.search(key, sl.cache, sl.posCache)
+
+	if n == nil || n.key != key {
+		var zero V
+		return zero, false
+	}
+
+	sl.num--
+
+	for i := uint8(0); i <= sl.level; i++ {
+		if sl.cache[i].forward[i] != n {
+			if sl.cache[i].forward[i] != nil {
+				sl.cache[i].widths[i]--
+			}
+			continue
+		}
+
+		if n.forward[i] == nil { // n was the last node at this level
+			sl.cache[i].widths[i] = 0
+		} else {
+			sl.cache[i].widths[i] += n.widths[i] - 1
+		}
+		sl.cache[i].forward[i] = n.forward[i]
+	}
+
+	for sl.level > 1 && sl.head.forward[sl.level-1] == nil {
+		sl.head.widths[sl.level] = 0
+		sl.level = sl.level - 1
+	}
+
+	return n.value, true
+}
+
+// Len returns the number of items in this skiplist.
+func (sl *OrderedSkipList[K, V]) Len() uint64 {
+	return sl.num
+}
+
+// Iter will return an iterator that can be used to iterate over all
+// the keys and values with a key equal to or greater than the key
+// provided.
+func (sl *OrderedSkipList[K, V]) Iter(key K) *OrderedIterator[K, V] {
+	n, _ := sl.search(key, nil, nil)
+	return &OrderedIterator[K, V]{
+		first: true,
+		n:     n,
+	}
+}
+
+// Each will call the provided function with every key and value in
+// the list in order until the function returns false.
+func (sl *OrderedSkipList[K, V]) Each(fn func(K, V) bool) {
+	for n := sl.head.forward[0]; n != nil; n = n.forward[0] {
+		if !fn(n.key, n.value) {
+			return
+		}
+	}
+}
+
+// OrderedIterator iterates the keys and values of an OrderedSkipList
+// in order.
+type OrderedIterator[K cmp.Ordered, V any] struct {
+	first bool
+	n     *orderedNode[K, V]
+}
+
+// Next returns a bool indicating if there are any further values
+// in this iterator and moves the iterator to that value.
+func (iter *OrderedIterator[K, V]) Next() bool {
+	if iter.first {
+		iter.first = false
+		return iter.n != nil
+	}
+
+	if iter.n == nil {
+		return false
+	}
+
+	iter.n = iter.n.forward[0]
+	return iter.n != nil
+}
+
+// Key returns the key at the iterator's present position.  Returns
+// the zero value if no values remain to iterate.
+func (iter *OrderedIterator[K, V]) Key() K {
+	if iter.n == nil {
+		var zero K
+		return zero
+	}
+
+	return iter.n.key
+}
+
+// Value returns the value at the iterator's present position.
+// Returns the zero value if no values remain to iterate.
+func (iter *OrderedIterator[K, V]) Value() V {
+	if iter.n == nil {
+		var zero V
+		return zero
+	}
+
+	return iter.n.value
+}
+
+// NewOrdered will allocate, initialize, and return a new skiplist
+// keyed by an ordered type.  The list is sized for up to 2^64 items.
+func NewOrdered[K cmp.Ordered, V any]() *OrderedSkipList[K, V] {
+	const maxLevel = 64
+	var key K
+	var value V
+	return &OrderedSkipList[K, V]{
+		maxLevel: maxLevel,
+		cache:    make([]*orderedNode[K, V], maxLevel),
+		posCache: make(widths, maxLevel),
+		head:     newOrderedNode(key, value, maxLevel),
+		rng:      newRand(),
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedInsertGet(t *testing.T) {
+	sl := NewOrdered[int, string]()
+
+	old, ok := sl.Insert(2, `b`)
+	assert.False(t, ok)
+	assert.Equal(t, ``, old)
+	sl.Insert(1, `a`)
+
+	old, ok = sl.Insert(2, `c`)
+	assert.True(t, ok)
+	assert.Equal(t, `b`, old)
+	assert.Equal(t, uint64(2), sl.Len())
+
+	value, ok := sl.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, `c`, value)
+
+	_, ok = sl.Get(3)
+	assert.False(t, ok)
+
+	value, pos, ok := sl.GetWithPosition(2)
+	assert.True(t, ok)
+	assert.Equal(t, `c`, value)
+	assert.Equal(t, uint64(1), pos)
+}
+
+func TestOrderedDelete(t *testing.T) {
+	sl := NewOrdered[int, int]()
+	for i := 0; i < 10; i++ {
+		sl.Insert(i, i*10)
+	}
+
+	value, ok := sl.Delete(5)
+	assert.True(t, ok)
+	assert.Equal(t, 50, value)
+	assert.Equal(t, uint64(9), sl.Len())
+
+	_, ok = sl.Delete(5)
+	assert.False(t, ok)
+
+	_, ok = sl.Get(5)
+	assert.False(t, ok)
+
+	key, value, ok := sl.ByPosition(5)
+	assert.True(t, ok)
+	assert.Equal(t, 6, key)
+	assert.Equal(t, 60, value)
+}
+
+func TestOrderedByPosition(t *testing.T) {
+	sl := NewOrdered[string, int]()
+	_, _, ok := sl.ByPosition(0)
+	assert.False(t, ok)
+
+	sl.Insert(`c`, 3)
+	sl.Insert(`a`, 1)
+	sl.Insert(`b`, 2)
+
+	key, value, ok := sl.ByPosition(0)
+	assert.True(t, ok)
+	assert.Equal(t, `a`, key)
+	assert.Equal(t, 1, value)
+
+	key, _, _ = sl.ByPosition(2)
+	assert.Equal(t, `c`, key)
+
+	_, _, ok = sl.ByPosition(3)
+	assert.False(t, ok)
+}
+
+func TestOrderedIter(t *testing.T) {
+	sl := NewOrdered[int, int]()
+	for i := 0; i < 10; i += 2 {
+		sl.Insert(i, i)
+	}
+
+	iter := sl.Iter(3)
+	keys := []int{}
+	for iter.Next() {
+		keys = append(keys, iter.Key())
+		assert.Equal(t, iter.Key(), iter.Value())
+	}
+	assert.Equal(t, []int{4, 6, 8}, keys)
+	assert.Equal(t, 0, iter.Key())
+
+	iter = sl.Iter(9)
+	assert.False(t, iter.Next())
+}
+
+func TestOrderedEach(t *testing.T) {
+	sl := NewOrdered[int, int]()
+	for i := 0; i < 5; i++ {
+		sl.Insert(i, i)
+	}
+
+	keys := []int{}
+	sl.Each(func(k, v int) bool {
+		keys = append(keys, k)
+		return k < 2
+	})
+	assert.Equal(t, []int{0, 1, 2}, keys)
+}
+
+func TestOrderedRandomOperations(t *testing.T) {
+	sl := NewOrdered[int, int]()
+	expected := map[int]int{}
+
+	for i := 0; i < 2000; i++ {
+		key := rand.Intn(500)
+		if rand.Intn(3) == 0 {
+			_, ok := sl.Delete(key)
+			_, exists := expected[key]
+			assert.Equal(t, exists, ok)
+			delete(expected, key)
+			continue
+		}
+
+		sl.Insert(key, i)
+		expected[key] = i
+	}
+
+	keys := make([]int, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	assert.Equal(t, uint64(len(keys)), sl.Len())
+	for i, key := range keys {
+		k, v, ok := sl.ByPosition(uint64(i))
+		assert.True(t, ok)
+		assert.Equal(t, key, k)
+		assert.Equal(t, expected[key], v)
+	}
+}
+
+func BenchmarkOrderedInsert(b *testing.B) {
+	numItems := b.N
+	sl := NewOrdered[uint64, uint64]()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Insert(uint64(i%numItems), uint64(i))
+	}
+}
+
+func BenchmarkOrderedGet(b *testing.B) {
+	numItems := b.N
+	sl := NewOrdered[uint64, uint64]()
+
+	for i := 0; i < numItems; i++ {
+		sl.Insert(uint64(i), uint64(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Get(uint64(i % numItems))
+	}
+}
+
+func BenchmarkOrderedDelete(b *testing.B) {
+	numItems := b.N
+	sl := NewOrdered[uint64, uint64]()
+
+	for i := 0; i < numItems; i++ {
+		sl.Insert(uint64(i), uint64(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Delete(uint64(i))
+	}
+}
+
+func BenchmarkOrderedByPosition(b *testing.B) {
+	numItems := b.N
+	sl := NewOrdered[uint64, uint64]()
+
+	for i := 0; i < numItems; i++ {
+		sl.Insert(uint64(i), uint64(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.ByPosition(uint64(i))
+	}
+}
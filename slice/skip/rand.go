@@ -0,0 +1,91 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import "math/rand"
+
+// LevelGenerator returns the number of levels a newly inserted node
+// should be linked at given the list's max level.  Results are clamped
+// to between 1 and maxLevel-1.  Supplying a deterministic generator
+// makes the shape of a list reproducible.
+type LevelGenerator func(maxLevel uint8) uint8
+
+// xorshift is a small, fast and not threadsafe random source.  Each
+// list owns one so inserts never contend on a shared generator.
+type xorshift struct {
+	state uint64
+}
+
+// Uint64 returns the next pseudo-random value using xorshift64*.
+func (x *xorshift) Uint64() uint64 {
+	x.state ^= x.state >> 12
+	x.state ^= x.state << 25
+	x.state ^= x.state >> 27
+	return x.state * 2685821657736338717
+}
+
+// Int63 is required by the rand.Source interface.
+func (x *xorshift) Int63() int64 {
+	return int64(x.Uint64() >> 1)
+}
+
+// Seed is required by the rand.Source interface.  A zero state would
+// only ever produce zeros so it is replaced.
+func (x *xorshift) Seed(seed int64) {
+	x.state = uint64(seed)
+	if x.state == 0 {
+		x.state = 0x9e3779b97f4a7c15
+	}
+}
+
+// newXorshift returns a xorshift source seeded with the provided seed.
+func newXorshift(seed uint64) *xorshift {
+	x := &xorshift{}
+	x.Seed(int64(seed))
+	return x
+}
+
+// newRand returns a generator backed by a fresh xorshift source.  The
+// seed is taken from the standard library's threadsafe top level
+// source so no lock is held here.
+func newRand() *rand.Rand {
+	return rand.New(newXorshift(rand.Uint64()))
+}
+
+// generateLevel returns the number of levels for a new node.  Each
+// additional level is included with probability p.
+func generateLevel(rng *rand.Rand, levels LevelGenerator, maxLevel uint8) uint8 {
+	if levels != nil {
+		level := levels(maxLevel)
+		switch {
+		case level < 1:
+			return 1
+		case level > maxLevel-1:
+			return maxLevel - 1
+		}
+		return level
+	}
+
+	var level uint8
+	for level = uint8(1); level < maxLevel-1; level++ {
+		if rng.Float64() >= p {
+			return level
+		}
+	}
+
+	return level
+}
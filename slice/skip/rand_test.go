@@ -0,0 +1,93 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func nodeLevels(sl *SkipList) []int {
+	levels := make([]int, 0, sl.num)
+	for n := sl.head.forward[0]; n != nil; n = n.forward[0] {
+		levels = append(levels, len(n.forward))
+	}
+	return levels
+}
+
+func TestXorshiftSeed(t *testing.T) {
+	x := newXorshift(0)
+	assert.NotEqual(t, uint64(0), x.Uint64())
+
+	a, b := newXorshift(42), newXorshift(42)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.Uint64(), b.Uint64())
+	}
+
+	assert.True(t, a.Int63() >= 0)
+}
+
+func TestNewWithSourceDeterministic(t *testing.T) {
+	entries := generateRandomMockEntries(200)
+	sl1 := NewWithSource(uint64(0), rand.NewSource(42))
+	sl2 := NewWithSource(uint64(0), rand.NewSource(42))
+	sl1.Insert(entries...)
+	sl2.Insert(entries...)
+
+	assert.Equal(t, nodeLevels(sl1), nodeLevels(sl2))
+	assert.Equal(t, sl1.RandomSample(5), sl2.RandomSample(5))
+	assert.Nil(t, sl1.Validate())
+
+	s1, s2 := sl1.Snapshot(), sl2.Snapshot()
+	s1.Insert(newMockEntry(1), newMockEntry(2), newMockEntry(3))
+	s2.Insert(newMockEntry(1), newMockEntry(2), newMockEntry(3))
+	assert.Equal(t, nodeLevels(s1), nodeLevels(s2))
+}
+
+func TestNewWithLevelGenerator(t *testing.T) {
+	sl := NewWithLevelGenerator(uint8(0), func(maxLevel uint8) uint8 {
+		return 1
+	})
+	sl.Insert(generateMockEntries(50)...)
+	for _, level := range nodeLevels(sl) {
+		assert.Equal(t, 1, level)
+	}
+	assert.Nil(t, sl.Validate())
+
+	_, right := sl.SplitAt(20)
+	right.Insert(newMockEntry(100))
+	for _, level := range nodeLevels(right) {
+		assert.Equal(t, 1, level)
+	}
+}
+
+func TestLevelGeneratorClamped(t *testing.T) {
+	sl := NewWithLevelGenerator(uint8(0), func(maxLevel uint8) uint8 {
+		return 0
+	})
+	sl.Insert(newMockEntry(1))
+	assert.Equal(t, []int{1}, nodeLevels(sl))
+
+	sl = NewWithLevelGenerator(uint8(0), func(maxLevel uint8) uint8 {
+		return 255
+	})
+	sl.Insert(newMockEntry(1), newMockEntry(2))
+	assert.Equal(t, []int{7, 7}, nodeLevels(sl))
+	assert.Nil(t, sl.Validate())
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// randomPositions returns k distinct positions chosen uniformly from
+// [0, n) in ascending order using Floyd's algorithm.  k must not be
+// greater than n.
+func randomPositions(rng *rand.Rand, n, k uint64) []uint64 {
+	chosen := make(map[uint64]struct{}, k)
+	positions := make([]uint64, 0, k)
+
+	for j := n - k; j < n; j++ {
+		t := uint64(rng.Int63n(int64(j + 1)))
+		if _, ok := chosen[t]; ok {
+			t = j
+		}
+		chosen[t] = struct{}{}
+		positions = append(positions, t)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i] < positions[j]
+	})
+	return positions
+}
+
+// RandomSample returns up to k distinct entries chosen uniformly at
+// random from this list.  The entries are returned in list order.  If
+// k is greater than the length of the list every entry is returned.
+func (sl *SkipList) RandomSample(k int) Entries {
+	if k < 1 || sl.num == 0 {
+		return nil
+	}
+
+	if uint64(k) >= sl.num {
+		result := make(Entries, 0, sl.num)
+		sl.each(func(e Entry) bool {
+			result = append(result, e)
+			return true
+		})
+		return result
+	}
+
+	result := make(Entries, 0, k)
+	for _, position := range randomPositions(sl.rng, sl.num, uint64(k)) {
+		result = append(result, sl.ByPosition(position))
+	}
+
+	return result
+}
+
+type weightedCandidate struct {
+	entry    Entry
+	position uint64
+	key      float64
+}
+
+// reservoir is a min heap of candidates ordered by key so the
+// candidate with the smallest key can be evicted.
+type reservoir []weightedCandidate
+
+func (r reservoir) Len() int { return len(r) }
+
+func (r reservoir) Less(i, j int) bool { return r[i].key < r[j].key }
+
+func (r reservoir) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+func (r *reservoir) Push(x interface{}) {
+	*r = append(*r, x.(weightedCandidate))
+}
+
+func (r *reservoir) Pop() interface{} {
+	old := *r
+	c := old[len(old)-1]
+	*r = old[:len(old)-1]
+	return c
+}
+
+// WeightedSample returns up to k distinct entries chosen at random
+// without replacement, where the chance of an entry being chosen is
+// proportional to its weight.  Only entries implementing WeightedEntry
+// with a positive weight are considered.  This is a single pass
+// reservoir sample (Efraimidis-Spirakis A-Res) and the entries are
+// returned in list order.
+func (sl *SkipList) WeightedSample(k int) Entries {
+	if k < 1 || sl.num == 0 {
+		return nil
+	}
+
+	r := make(reservoir, 0, k)
+	var position uint64
+
+	sl.each(func(e Entry) bool {
+		pos := position
+		position++
+
+		we, ok := e.(WeightedEntry)
+		if !ok {
+			return true
+		}
+
+		weight := we.Weight()
+		if weight <= 0 || math.IsNaN(weight) {
+			return true
+		}
+
+		// log(u^(1/w)) orders candidates identically to u^(1/w)
+		// without underflowing for small weights.
+		key := math.Log(1-sl.rng.Float64()) / weight
+		if len(r) < k {
+			heap.Push(&r, weightedCandidate{entry: e, position: pos, key: key})
+		} else if key > r[0].key {
+			r[0] = weightedCandidate{entry: e, position: pos, key: key}
+			heap.Fix(&r, 0)
+		}
+		return true
+	})
+
+	if len(r) == 0 {
+		return nil
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].position < r[j].position
+	})
+
+	result := make(Entries, 0, len(r))
+	for _, c := range r {
+		result = append(result, c.entry)
+	}
+
+	return result
+}
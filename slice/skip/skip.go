@@ -37,6 +37,7 @@ below.
 
 SearchByPosition: O(log n)
 InsertByPosition: O(log n)
+InsertManyAtPosition: O(log n + k) for k entries
 
 More information here: http://cglab.ca/~morin/teaching/5408/refs/p90b.pdf
 
@@ -51,16 +52,23 @@ BenchmarkInsertAtPosition-8	 3000000	       485 ns/op
 CPU profiling has shown that the most expensive thing we do here
 is call Compare.  A potential optimization for gets only is to
 do a binary search in the forward/width lists instead of visiting
-every value.  We could also use generics if Golang had them and
-let the consumer specify primitive types, which would speed up
-these operation dramatically.
+every value.  OrderedSkipList, created with NewOrdered, uses generics
+to store keys of an ordered type directly and avoids the Compare calls
+entirely.
+
+BenchmarkOrderedInsert-8	 3000000	       492 ns/op
+BenchmarkOrderedGet-8	 	10000000	       159 ns/op
+BenchmarkOrderedDelete-8	10000000	       111 ns/op
 */
 package skip
 
 import (
+	"fmt"
+	"iter"
 	"math/rand"
-	"sync"
 	"time"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 const p = .5 // the p level defines the probability that a node
@@ -71,37 +79,21 @@ const p = .5 // the p level defines the probability that a node
 // items in the universe.  If p = .5 then maxlevel = 32 is appropriate
 // for uint32.
 
-// generator will be the common generator to create random numbers. It
-// is seeded with unix nanosecond when this line is executed at runtime,
-// and only executed once ensuring all random numbers come from the same
-// randomly seeded generator.
-var generator = rand.New(rand.NewSource(time.Now().UnixNano()))
-
-// rnLock protects the RNG as the generator is not threadsafe.
-var rnLock sync.Mutex
-
-func generateLevel(maxLevel uint8) uint8 {
-	var level uint8
-	rnLock.Lock()
-	defer rnLock.Unlock()
-	for level = uint8(1); level < maxLevel-1; level++ {
-		if generator.Float64() >= p {
-			return level
-		}
-	}
-
-	return level
-}
-
 func insertNode(sl *SkipList, n *node, entry Entry, pos uint64, cache nodes, posCache widths, allowDuplicate bool) Entry {
 	if !allowDuplicate && n != nil && n.Compare(entry) == 0 { // a simple update in this case
 		oldEntry := n.entry
 		n.entry = entry
+		sl.report(common.MetricInserts, 1)
 		return oldEntry
 	}
 	sl.num++
 
-	nodeLevel := generateLevel(sl.maxLevel)
+	nodeLevel := generateLevel(sl.rng, sl.levels, sl.maxLevel)
+	if sl.metrics != nil {
+		sl.metrics.Add(common.MetricInserts, 1)
+		sl.metrics.Add(common.MetricNodes, 1)
+		sl.metrics.Observe(common.MetricLevel, float64(nodeLevel))
+	}
 	if nodeLevel > sl.level {
 		for i := sl.level; i < nodeLevel; i++ {
 			cache[i] = sl.head
@@ -109,7 +101,7 @@ func insertNode(sl *SkipList, n *node, entry Entry, pos uint64, cache nodes, pos
 		sl.level = nodeLevel
 	}
 
-	nn := newNode(entry, nodeLevel)
+	nn := sl.free.get(entry, nodeLevel)
 	for i := uint8(0); i < nodeLevel; i++ {
 		nn.forward[i] = cache[i].forward[i]
 		cache[i].forward[i] = nn
@@ -132,11 +124,25 @@ func insertNode(sl *SkipList, n *node, entry Entry, pos uint64, cache nodes, pos
 		}
 		cache[i].widths[i]++
 	}
+
+	if cache[0] != sl.head {
+		nn.backward = cache[0]
+	}
+	if nn.forward[0] != nil {
+		nn.forward[0].backward = nn
+	}
 	return nil
 }
 
 func splitAt(sl *SkipList, index uint64) (*SkipList, *SkipList) {
+	sl.unshare()
+	sl.epoch++
 	right := &SkipList{}
+	right.rng = rand.New(newXorshift(sl.rng.Uint64()))
+	right.levels = sl.levels
+	right.multi = sl.multi
+	right.now = sl.now
+	right.free = sl.free.fork()
 	right.maxLevel = sl.maxLevel
 	right.level = sl.level
 	right.cache = make(nodes, sl.maxLevel)
@@ -153,8 +159,13 @@ func splitAt(sl *SkipList, index uint64) (*SkipList, *SkipList) {
 		sl.cache[i].forward[i] = nil
 	}
 
+	if right.head.forward[0] != nil {
+		right.head.forward[0].backward = nil
+	}
+
 	right.num = sl.num - index
 	sl.num = sl.num - right.num
+	sl.report(common.MetricNodes, -int64(right.num))
 
 	sl.resetMaxLevel()
 	right.resetMaxLevel()
@@ -174,6 +185,29 @@ type SkipList struct {
 	// the number of allocations in the insert/delete case.
 	cache    nodes
 	posCache widths
+	// shared indicates that this list's nodes are also referenced
+	// by a snapshot and must be copied before they are mutated.
+	shared bool
+	// rng is owned by this list and is used to choose node levels
+	// and to sample.
+	rng *rand.Rand
+	// levels, if not nil, overrides how node levels are chosen.
+	levels LevelGenerator
+	// multi indicates that entries comparing equal are all kept
+	// rather than replaced.
+	multi bool
+	// now, if not nil, is the clock used to purge expired entries
+	// as they are read.
+	now func() time.Time
+	// free, if not nil, keeps deleted nodes so inserts can reuse them.
+	free *freeList
+	// metrics, if not nil, receives counts of the operations
+	// performed on this list.
+	metrics common.Metrics
+	// epoch counts the writes that move many nodes at once, which
+	// are splits, merges and copying nodes shared with a snapshot.
+	// Iterators compare it to tell if their position is stale.
+	epoch uint64
 }
 
 // init will initialize this skiplist.  The parameter is expected
@@ -219,6 +253,33 @@ func (sl *SkipList) search(e Entry, update nodes, widths widths) (*node, uint64)
 	return n.forward[0], pos + 1
 }
 
+// searchAfter is like search except that it passes over entries
+// equal to the provided entry, returning the position just after
+// the last of them.  This is used to append duplicates.
+func (sl *SkipList) searchAfter(e Entry, update nodes, widths widths) (*node, uint64) {
+	if sl.num == 0 { // nothing in the list
+		return nil, 1
+	}
+
+	var pos uint64 = 0
+	var offset uint8
+	n := sl.head
+	for i := uint8(0); i <= sl.level; i++ {
+		offset = sl.level - i
+		for n.forward[offset] != nil && n.forward[offset].Compare(e) <= 0 {
+			pos += n.widths[offset]
+			n = n.forward[offset]
+		}
+
+		if update != nil {
+			update[offset] = n
+			widths[offset] = pos
+		}
+	}
+
+	return n.forward[0], pos + 1
+}
+
 func (sl *SkipList) resetMaxLevel() {
 	if sl.level < 1 {
 		sl.level = 1
@@ -260,33 +321,58 @@ func (sl *SkipList) searchByPosition(position uint64, update nodes, widths width
 
 // Get will retrieve values associated with the keys provided.  If an
 // associated value could not be found, a nil is returned in its place.
-// This is an O(log n) operation.
+// This is an O(log n) operation.  GetOne avoids allocating the result
+// when looking up a single key.
 func (sl *SkipList) Get(entries ...Entry) Entries {
 	result := make(Entries, 0, len(entries))
-
-	var n *node
 	for _, e := range entries {
-		n, _ = sl.search(e, nil, nil)
-		if n != nil && n.Compare(e) == 0 {
-			result = append(result, n.entry)
-		} else {
-			result = append(result, nil)
-		}
+		result = append(result, sl.GetOne(e))
 	}
 
 	return result
 }
 
+// GetOne will retrieve the value associated with the provided key or
+// nil if it could not be found.  This is an O(log n) operation and
+// performs no allocations.
+func (sl *SkipList) GetOne(e Entry) Entry {
+	sl.report(common.MetricSearches, 1)
+	for {
+		n, pos := sl.search(e, nil, nil)
+		if n == nil || n.Compare(e) != 0 {
+			return nil
+		}
+
+		if !sl.expired(n.entry) {
+			return n.entry
+		}
+		sl.DeleteAtPosition(pos - 1)
+	}
+}
+
+// Contains returns a bool indicating if a value associated with the
+// provided key exists in this list.  This is an O(log n) operation
+// and performs no allocations.
+func (sl *SkipList) Contains(e Entry) bool {
+	return sl.GetOne(e) != nil
+}
+
 // GetWithPosition will retrieve the value with the provided key and
 // return the position of that value within the list.  Returns nil, 0
 // if an associated value could not be found.
 func (sl *SkipList) GetWithPosition(e Entry) (Entry, uint64) {
-	n, pos := sl.search(e, nil, nil)
-	if n == nil {
-		return nil, 0
-	}
+	sl.report(common.MetricSearches, 1)
+	for {
+		n, pos := sl.search(e, nil, nil)
+		if n == nil {
+			return nil, 0
+		}
 
-	return n.entry, pos - 1
+		if !sl.expired(n.entry) {
+			return n.entry, pos - 1
+		}
+		sl.DeleteAtPosition(pos - 1)
+	}
 }
 
 // ByPosition returns the entry at the given position.
@@ -299,14 +385,45 @@ func (sl *SkipList) ByPosition(position uint64) Entry {
 	return n.entry
 }
 
+// ByPositionE is ByPosition except that it returns an OutOfRangeError
+// if the position does not exist.
+func (sl *SkipList) ByPositionE(position uint64) (Entry, error) {
+	if position >= sl.num {
+		return nil, OutOfRangeError{Position: position, Len: sl.num}
+	}
+
+	return sl.ByPosition(position), nil
+}
+
+// Rank returns the number of entries less than the provided entry,
+// which is the position the entry has, or would have, in the list.
+// This is an O(log n) operation.
+func (sl *SkipList) Rank(e Entry) uint64 {
+	_, pos := sl.search(e, nil, nil)
+	return pos - 1
+}
+
+// Select is an alias for ByPosition and returns the entry with the
+// provided rank.
+func (sl *SkipList) Select(rank uint64) Entry {
+	return sl.ByPosition(rank)
+}
+
 func (sl *SkipList) insert(entry Entry) Entry {
+	sl.unshare()
+	if sl.multi {
+		n, pos := sl.searchAfter(entry, sl.cache, sl.posCache)
+		return insertNode(sl, n, entry, pos, sl.cache, sl.posCache, true)
+	}
+
 	n, pos := sl.search(entry, sl.cache, sl.posCache)
 	return insertNode(sl, n, entry, pos, sl.cache, sl.posCache, false)
 }
 
 // Insert will insert the provided entries into the list.  Returned
-// is a list of entries that were overwritten.  This is expected to
-// be an O(log n) operation.
+// is a list of entries that were overwritten.  Lists created with
+// NewMulti never overwrite and instead place an entry after any equal
+// entries.  This is expected to be an O(log n) operation.
 func (sl *SkipList) Insert(entries ...Entry) Entries {
 	overwritten := make(Entries, 0, len(entries))
 	for _, e := range entries {
@@ -317,6 +434,7 @@ func (sl *SkipList) Insert(entries ...Entry) Entries {
 }
 
 func (sl *SkipList) insertAtPosition(position uint64, entry Entry) {
+	sl.unshare()
 	if position > sl.num {
 		position = sl.num
 	}
@@ -332,7 +450,89 @@ func (sl *SkipList) InsertAtPosition(position uint64, entry Entry) {
 	sl.insertAtPosition(position, entry)
 }
 
+func (sl *SkipList) insertManyAtPosition(position uint64, entries Entries) {
+	if len(entries) == 0 {
+		return
+	}
+
+	sl.unshare()
+	if position > sl.num {
+		position = sl.num
+	}
+	cache, posCache := sl.cache, sl.posCache
+	if sl.num == 0 {
+		for i := uint8(0); i <= sl.level; i++ {
+			cache[i], posCache[i] = sl.head, 0
+		}
+	} else {
+		sl.searchByPosition(position, cache, posCache)
+	}
+
+	// ends holds the position of the node each level of the cache
+	// pointed at before the run was linked in.
+	ends := make(widths, sl.maxLevel)
+	for i := uint8(0); i < sl.level; i++ {
+		ends[i] = posCache[i] + cache[i].widths[i]
+	}
+
+	pos := position
+	for _, entry := range entries {
+		pos++
+		nodeLevel := generateLevel(sl.rng, sl.levels, sl.maxLevel)
+		if sl.metrics != nil {
+			sl.metrics.Add(common.MetricInserts, 1)
+			sl.metrics.Add(common.MetricNodes, 1)
+			sl.metrics.Observe(common.MetricLevel, float64(nodeLevel))
+		}
+		for ; sl.level < nodeLevel; sl.level++ {
+			cache[sl.level], posCache[sl.level] = sl.head, 0
+		}
+
+		// link the node in after the cache, which then moves on to
+		// it so the next entry is linked in after it without a
+		// search.  Its widths are fixed once the run is done.
+		nn := sl.free.get(entry, nodeLevel)
+		if cache[0] != sl.head {
+			nn.backward = cache[0]
+		}
+		for i := uint8(0); i < nodeLevel; i++ {
+			nn.forward[i] = cache[i].forward[i]
+			cache[i].forward[i] = nn
+			cache[i].widths[i] = pos - posCache[i]
+			cache[i], posCache[i] = nn, pos
+		}
+	}
+
+	// every level now ends the run at the cache, whose forward node,
+	// if any, has moved along by the length of the run
+	run := uint64(len(entries))
+	for i := uint8(0); i < sl.level; i++ {
+		if cache[i].forward[i] == nil {
+			cache[i].widths[i] = 0
+		} else {
+			cache[i].widths[i] = ends[i] + run - posCache[i]
+		}
+	}
+	if next := cache[0].forward[0]; next != nil {
+		next.backward = cache[0]
+	}
+	sl.num += run
+}
+
+// InsertManyAtPosition inserts the provided entries in order starting
+// at the provided position, so the first entry ends up at position and
+// the rest follow it.  If position is greater than the length of the
+// skiplist, the entries are appended.  The position is searched for
+// once and the widths spanning the run are fixed once, making this
+// cheaper than calling InsertAtPosition for each entry.  Like
+// InsertAtPosition, this bypasses order checks and checks for
+// duplicates so use with caution.
+func (sl *SkipList) InsertManyAtPosition(position uint64, entries ...Entry) {
+	sl.insertManyAtPosition(position, entries)
+}
+
 func (sl *SkipList) replaceAtPosition(position uint64, entry Entry) {
+	sl.unshare()
 	n, _ := sl.searchByPosition(position+1, nil, nil)
 	if n == nil {
 		return
@@ -348,14 +548,39 @@ func (sl *SkipList) ReplaceAtPosition(position uint64, entry Entry) {
 	sl.replaceAtPosition(position, entry)
 }
 
+// ReplaceAtPositionE is ReplaceAtPosition except that it returns an
+// OutOfRangeError, rather than doing nothing, if the position does
+// not exist.
+func (sl *SkipList) ReplaceAtPositionE(position uint64, entry Entry) error {
+	if position >= sl.num {
+		return OutOfRangeError{Position: position, Len: sl.num}
+	}
+
+	sl.replaceAtPosition(position, entry)
+	return nil
+}
+
 func (sl *SkipList) delete(e Entry) Entry {
+	sl.unshare()
 	n, _ := sl.search(e, sl.cache, sl.posCache)
 
 	if n == nil || n.Compare(e) != 0 {
 		return nil
 	}
 
+	sl.unlink(n)
+	entry := n.entry
+	sl.free.put(n)
+	return entry
+}
+
+// unlink removes the provided node from the list.  The cache must
+// hold the node's predecessor at every level.
+func (sl *SkipList) unlink(n *node) {
+	n.version++
 	sl.num--
+	sl.report(common.MetricDeletes, 1)
+	sl.report(common.MetricNodes, -1)
 
 	for i := uint8(0); i <= sl.level; i++ {
 		if sl.cache[i].forward[i] != n {
@@ -365,16 +590,22 @@ func (sl *SkipList) delete(e Entry) Entry {
 			continue
 		}
 
-		sl.cache[i].widths[i] += n.widths[i] - 1
+		if n.forward[i] == nil { // n was the last node at this level
+			sl.cache[i].widths[i] = 0
+		} else {
+			sl.cache[i].widths[i] += n.widths[i] - 1
+		}
 		sl.cache[i].forward[i] = n.forward[i]
 	}
 
+	if n.forward[0] != nil {
+		n.forward[0].backward = n.backward
+	}
+
 	for sl.level > 1 && sl.head.forward[sl.level-1] == nil {
 		sl.head.widths[sl.level] = 0
 		sl.level = sl.level - 1
 	}
-
-	return n.entry
 }
 
 // Delete will remove the provided keys from the skiplist and return
@@ -390,20 +621,141 @@ func (sl *SkipList) Delete(entries ...Entry) Entries {
 	return deleted
 }
 
+// DeleteAtPosition removes and returns the entry at the provided
+// position.  Returns nil if the position does not exist.  Unlike
+// Delete this removes exactly one entry even if duplicates were
+// inserted with InsertAtPosition.  This is an O(log n) operation.
+func (sl *SkipList) DeleteAtPosition(position uint64) Entry {
+	if position >= sl.num {
+		return nil
+	}
+
+	sl.unshare()
+	// the node at position lies just beyond the cached predecessors
+	sl.searchByPosition(position, sl.cache, sl.posCache)
+	n := sl.cache[0].forward[0]
+	sl.unlink(n)
+	entry := n.entry
+	sl.free.put(n)
+	return entry
+}
+
+// DeleteAtPositionE is DeleteAtPosition except that it returns an
+// OutOfRangeError if the position does not exist.
+func (sl *SkipList) DeleteAtPositionE(position uint64) (Entry, error) {
+	if position >= sl.num {
+		return nil, OutOfRangeError{Position: position, Len: sl.num}
+	}
+
+	return sl.DeleteAtPosition(position), nil
+}
+
+// DeleteRange removes the entries from position start up to, but not
+// including, position stop and returns them in order.  Positions past
+// the end of the list are ignored.  This splits out the range and
+// merges the remainder so it is an O(log n + k) operation where k is
+// the number of entries removed.
+func (sl *SkipList) DeleteRange(start, stop uint64) Entries {
+	if stop > sl.num {
+		stop = sl.num
+	}
+
+	if start >= stop {
+		return nil
+	}
+
+	num := sl.num
+	_, removed := splitAt(sl, start)
+	if stop < num {
+		_, right := splitAt(removed, stop-start)
+		sl.Merge(right)
+	}
+
+	deleted := make(Entries, 0, removed.num)
+	for n := removed.head.forward[0]; n != nil; {
+		next := n.forward[0]
+		deleted = append(deleted, n.entry)
+		sl.free.put(n)
+		n = next
+	}
+	sl.report(common.MetricDeletes, int64(len(deleted)))
+
+	return deleted
+}
+
+// GetAll returns every entry equal to the provided entry in the order
+// they were inserted.  This is most useful for lists created with
+// NewMulti.  This is an O(log n + k) operation.
+func (sl *SkipList) GetAll(e Entry) Entries {
+	var result Entries
+	for n, _ := sl.search(e, nil, nil); n != nil && n.Compare(e) == 0; n = n.forward[0] {
+		result = append(result, n.entry)
+	}
+
+	return result
+}
+
+// DeleteAll removes every entry equal to the provided entry and
+// returns them in the order they were inserted.  This is an
+// O(k log n) operation.
+func (sl *SkipList) DeleteAll(e Entry) Entries {
+	var deleted Entries
+	for {
+		d := sl.delete(e)
+		if d == nil {
+			return deleted
+		}
+		deleted = append(deleted, d)
+	}
+}
+
 // Len returns the number of items in this skiplist.
 func (sl *SkipList) Len() uint64 {
 	return sl.num
 }
 
+// SizeOf returns an estimate of the number of bytes used by this list,
+// including its nodes, any nodes kept for reuse, and level bookkeeping
+// but not the entries themselves.  Nodes shared with a snapshot are counted in both lists.
+// This is an O(n) operation.
+func (sl *SkipList) SizeOf() uint64 {
+	size := listSize + uint64(cap(sl.cache))*pointerSize + uint64(cap(sl.posCache))*widthSize
+	for n := sl.head; n != nil; n = n.forward[0] {
+		size += n.sizeOf()
+	}
+
+	if sl.free != nil {
+		for _, free := range sl.free.levels {
+			for _, n := range free {
+				size += n.sizeOf()
+			}
+		}
+	}
+
+	return size
+}
+
 func (sl *SkipList) iter(e Entry) *iterator {
 	n, _ := sl.search(e, nil, nil)
 	if n == nil {
 		return nilIterator()
 	}
 
-	return &iterator{
+	iter := &iterator{
 		first: true,
-		n:     n,
+		sl:    sl,
+	}
+	iter.land(n)
+	sl.expireIter(iter)
+	return iter
+}
+
+// expireIter makes the provided iterator skip entries that have
+// expired if expiry is enabled.
+func (sl *SkipList) expireIter(iter *iterator) {
+	if sl.now != nil {
+		iter.expiring = true
+		iter.now = sl.now()
 	}
 }
 
@@ -414,6 +766,207 @@ func (sl *SkipList) Iter(e Entry) Iterator {
 	return sl.iter(e)
 }
 
+// IterRange will return an iterator that visits all the values
+// with a key equal to or greater than start and less than stop.
+// A nil stop leaves the range unbounded above.
+func (sl *SkipList) IterRange(start, stop Entry) Iterator {
+	iter := sl.iter(start)
+	iter.stop = stop
+	return iter
+}
+
+func (sl *SkipList) lastNode() *node {
+	if sl.num == 0 {
+		return nil
+	}
+
+	n := sl.head
+	for i := int(sl.level); i >= 0; i-- {
+		for n.forward[i] != nil {
+			n = n.forward[i]
+		}
+	}
+
+	return n
+}
+
+// Last returns the greatest entry in the list or nil if the list
+// is empty.  This is an O(log n) operation.
+func (sl *SkipList) Last() Entry {
+	for {
+		n := sl.lastNode()
+		if n == nil {
+			return nil
+		}
+
+		if !sl.expired(n.entry) {
+			return n.entry
+		}
+		sl.DeleteAtPosition(sl.num - 1)
+	}
+}
+
+// Max is an alias for Last.
+func (sl *SkipList) Max() Entry {
+	return sl.Last()
+}
+
+// Min returns the least entry in the list or nil if the list is
+// empty.  This is an O(1) operation.
+func (sl *SkipList) Min() Entry {
+	for {
+		n := sl.head.forward[0]
+		if n == nil {
+			return nil
+		}
+
+		if !sl.expired(n.entry) {
+			return n.entry
+		}
+		sl.DeleteAtPosition(0)
+	}
+}
+
+// floorNode returns the node holding the greatest entry equal to or
+// less than the provided entry.
+func (sl *SkipList) floorNode(e Entry) *node {
+	n, _ := sl.search(e, nil, nil)
+	switch {
+	case n == nil:
+		return sl.lastNode()
+	case n.Compare(e) != 0:
+		return n.backward
+	}
+
+	return n
+}
+
+// Floor returns the greatest entry equal to or less than the provided
+// entry or nil if there is none.  This is an O(log n) operation.
+func (sl *SkipList) Floor(e Entry) Entry {
+	for {
+		n, pos := sl.search(e, nil, nil)
+		switch {
+		case n == nil:
+			n, pos = sl.lastNode(), pos-1
+		case n.Compare(e) != 0:
+			n, pos = n.backward, pos-1
+		}
+
+		if n == nil {
+			return nil
+		}
+
+		if !sl.expired(n.entry) {
+			return n.entry
+		}
+		sl.DeleteAtPosition(pos - 1)
+	}
+}
+
+// Ceiling returns the least entry equal to or greater than the
+// provided entry or nil if there is none.  This is an O(log n)
+// operation.
+func (sl *SkipList) Ceiling(e Entry) Entry {
+	n, _ := sl.GetWithPosition(e)
+	return n
+}
+
+// IterReverse will return an iterator that visits all the values
+// with a key equal to or less than the key provided in descending
+// order.  Calling Prev on the returned iterator moves back toward
+// greater keys.
+func (sl *SkipList) IterReverse(e Entry) Iterator {
+	iter := &iterator{
+		first:   true,
+		reverse: true,
+		sl:      sl,
+	}
+	iter.land(sl.floorNode(e))
+	sl.expireIter(iter)
+	return iter
+}
+
+// each calls fn with every entry, including expired ones, in order
+// until fn returns false.
+func (sl *SkipList) each(fn func(Entry) bool) {
+	sl.walk(sl.head.forward[0], func(n *node) bool {
+		return fn(n.entry)
+	})
+}
+
+// walk calls fn with every node from n onward in order until fn
+// returns false.  If fn removes the node it is given or splits or
+// merges the list, the walk resumes after that node's entry as an
+// iterator does.
+func (sl *SkipList) walk(n *node, fn func(*node) bool) {
+	epoch := sl.epoch
+	for n != nil {
+		version, entry := n.version, n.entry
+		if !fn(n) {
+			return
+		}
+
+		if n.version != version || sl.epoch != epoch {
+			epoch = sl.epoch
+			n, _ = sl.searchAfter(entry, nil, nil)
+			continue
+		}
+		n = n.forward[0]
+	}
+}
+
+// Each will call the provided function with every entry in the
+// list in order until the function returns false.  This walks the
+// bottom level of the list directly and performs no allocations,
+// making it cheaper than Iter for full scans.  Expired entries are
+// skipped if expiry is enabled.
+func (sl *SkipList) Each(fn func(Entry) bool) {
+	if sl.now == nil {
+		sl.each(fn)
+		return
+	}
+
+	now := sl.now()
+	sl.walk(sl.head.forward[0], func(n *node) bool {
+		return expiredAt(n.entry, now) || fn(n.entry)
+	})
+}
+
+// Range will call the provided function with every entry equal to or
+// greater than start and less than stop in order until the function
+// returns false.  A nil start begins at the least entry and a nil stop
+// leaves the range unbounded above.  Like Each, this walks the bottom
+// level of the list directly and performs no allocations, making it
+// cheaper than IterRange for tight scans.  Expired entries are skipped
+// if expiry is enabled.
+func (sl *SkipList) Range(start, stop Entry, fn func(Entry) bool) {
+	n := sl.head.forward[0]
+	if start != nil {
+		n, _ = sl.search(start, nil, nil)
+	}
+
+	var now time.Time
+	if sl.now != nil {
+		now = sl.now()
+	}
+	sl.walk(n, func(n *node) bool {
+		if stop != nil && n.Compare(stop) >= 0 {
+			return false
+		}
+
+		return (sl.now != nil && expiredAt(n.entry, now)) || fn(n.entry)
+	})
+}
+
+// All returns a sequence of every entry in the list in order for
+// use with range.
+func (sl *SkipList) All() iter.Seq[Entry] {
+	return func(yield func(Entry) bool) {
+		sl.Each(yield)
+	}
+}
+
 // SplitAt will split the current skiplist into two lists.  The first
 // skiplist returned is the "left" list and the second is the "right."
 // The index defines the last item in the left list.  If index is greater
@@ -428,13 +981,332 @@ func (sl *SkipList) SplitAt(index uint64) (*SkipList, *SkipList) {
 	return splitAt(sl, index)
 }
 
+// SplitEvery will split the current skiplist into lists of n entries
+// each, in order, the last of which holds whatever remains and may be
+// shorter.  The first list returned is this list.  An n of 0, or one
+// at least the length of this list, leaves the list whole.  Each list
+// after the first costs a split, which is logarithmic in the length
+// of what remains.  This is a mutable operation and modifies the
+// content of this list.
+func (sl *SkipList) SplitEvery(n uint64) []*SkipList {
+	if n == 0 {
+		return []*SkipList{sl}
+	}
+
+	lists := make([]*SkipList, 0, sl.num/n+1)
+	for right := sl; right != nil; {
+		var left *SkipList
+		left, right = right.SplitAt(n - 1)
+		lists = append(lists, left)
+	}
+
+	return lists
+}
+
+// grow raises this list's max level, extending the head and caches
+// so nodes from a list with a greater max level can be linked in.
+func (sl *SkipList) grow(maxLevel uint8) {
+	if maxLevel <= sl.maxLevel {
+		return
+	}
+
+	extra := int(maxLevel - sl.maxLevel)
+	sl.head.forward = append(sl.head.forward, make(nodes, extra)...)
+	sl.head.widths = append(sl.head.widths, make(widths, extra)...)
+	sl.cache = make(nodes, maxLevel)
+	sl.posCache = make(widths, maxLevel)
+	sl.maxLevel = maxLevel
+}
+
+// Merge appends every entry of the provided list to the end of this
+// list and returns this list, leaving other empty.  This is the
+// inverse of SplitAt and is an O(log n) operation as only the last
+// node at each level is relinked.  Like InsertAtPosition this does
+// not check ordering so use Join if the lists may overlap.
+func (sl *SkipList) Merge(other *SkipList) *SkipList {
+	if other == nil || other == sl || other.num == 0 {
+		return sl
+	}
+
+	sl.unshare()
+	other.unshare()
+	sl.grow(other.maxLevel)
+
+	// find the last node at every level this list uses along with
+	// its position
+	var pos uint64
+	n := sl.head
+	for i := int(sl.level); i >= 0; i-- {
+		for n.forward[i] != nil {
+			pos += n.widths[i]
+			n = n.forward[i]
+		}
+		sl.cache[i] = n
+		sl.posCache[i] = pos
+	}
+
+	for i := sl.level + 1; i < other.level; i++ {
+		sl.cache[i] = sl.head
+		sl.posCache[i] = 0
+	}
+
+	for i := uint8(0); i < other.level; i++ {
+		first := other.head.forward[i]
+		if first == nil {
+			continue
+		}
+
+		sl.cache[i].forward[i] = first
+		sl.cache[i].widths[i] = sl.num - sl.posCache[i] + other.head.widths[i]
+	}
+
+	if sl.cache[0] != sl.head {
+		other.head.forward[0].backward = sl.cache[0]
+	}
+
+	if other.level > sl.level {
+		sl.level = other.level
+	}
+	sl.num += other.num
+	sl.report(common.MetricNodes, int64(other.num))
+	other.report(common.MetricNodes, -int64(other.num))
+
+	other.head = newNode(nil, other.maxLevel)
+	other.level = 0
+	other.num = 0
+	other.epoch++
+	return sl
+}
+
+// Join appends every entry of the provided list to the end of this
+// list as Merge does, but first checks that every entry in other is
+// greater than every entry in this list.  An error is returned, and
+// neither list is modified, if they overlap.
+func (sl *SkipList) Join(other *SkipList) (*SkipList, error) {
+	if other == nil || other.num == 0 || sl.num == 0 {
+		return sl.Merge(other), nil
+	}
+
+	if other == sl {
+		return nil, fmt.Errorf(`Cannot join a list to itself.`)
+	}
+
+	last, first := sl.lastNode().entry, other.head.forward[0].entry
+	if last.Compare(first) >= 0 {
+		return nil, fmt.Errorf(`Cannot join lists as the first entry of the other list is not greater than the last entry of this list.`)
+	}
+
+	return sl.Merge(other), nil
+}
+
+// copy returns a new skiplist with the same shape as this one.  Nodes
+// are copied along the bottom level and relinked at every level they
+// occupy, so no comparisons are required and widths carry over as-is.
+// This is an O(n) operation.
+func (sl *SkipList) copy() *SkipList {
+	cp := &SkipList{
+		maxLevel: sl.maxLevel,
+		level:    sl.level,
+		num:      sl.num,
+		cache:    make(nodes, sl.maxLevel),
+		posCache: make(widths, sl.maxLevel),
+		head:     newNode(nil, sl.maxLevel),
+	}
+	copy(cp.head.widths, sl.head.widths)
+
+	last := make(nodes, sl.maxLevel)
+	for i := range last {
+		last[i] = cp.head
+	}
+
+	for n := sl.head.forward[0]; n != nil; n = n.forward[0] {
+		nn := newNode(n.entry, uint8(len(n.forward)))
+		copy(nn.widths, n.widths)
+		if last[0] != cp.head {
+			nn.backward = last[0]
+		}
+		for i := range n.forward {
+			last[i].forward[i] = nn
+			last[i] = nn
+		}
+	}
+
+	return cp
+}
+
+// unshare copies this list's nodes if they are referenced by
+// a snapshot.  Must be called before any mutation.
+func (sl *SkipList) unshare() {
+	if !sl.shared {
+		return
+	}
+
+	sl.head = sl.copy().head
+	sl.shared = false
+	sl.epoch++
+}
+
+// Snapshot returns a point-in-time copy of this skiplist.  This is an
+// O(1) operation; the two lists share nodes until one of them is mutated,
+// at which point the mutated list copies its nodes in O(n).  After taking
+// a snapshot, the snapshot can be read from a different goroutine while
+// this list continues to be modified.  Snapshot itself must not be called
+// concurrently with writes to this list.
+func (sl *SkipList) Snapshot() *SkipList {
+	sl.shared = true
+	return &SkipList{
+		rng:      rand.New(newXorshift(sl.rng.Uint64())),
+		levels:   sl.levels,
+		multi:    sl.multi,
+		now:      sl.now,
+		free:     sl.free.fork(),
+		maxLevel: sl.maxLevel,
+		level:    sl.level,
+		num:      sl.num,
+		head:     sl.head,
+		cache:    make(nodes, sl.maxLevel),
+		posCache: make(widths, sl.maxLevel),
+		shared:   true,
+	}
+}
+
+// Clone returns an independent copy of this skiplist.  Unlike
+// Snapshot, which defers copying until the first write, Clone copies
+// every node immediately so neither list pays for a copy later.
+// The entries themselves are not copied.  This is an O(n) operation.
+func (sl *SkipList) Clone() *SkipList {
+	cp := sl.copy()
+	cp.rng = rand.New(newXorshift(sl.rng.Uint64()))
+	cp.levels = sl.levels
+	cp.multi = sl.multi
+	cp.now = sl.now
+	cp.free = sl.free.fork()
+	return cp
+}
+
+// Validate walks the entire list and returns an error describing the
+// first structural inconsistency it finds, such as a width that does
+// not match the distance to the next node at that level or a length
+// that does not match the number of nodes.  Ordering is not checked as
+// InsertAtPosition allows entries to be placed anywhere.  This is an
+// O(n log n) operation intended for tests and fuzzing.
+func (sl *SkipList) Validate() error {
+	if sl.level >= sl.maxLevel {
+		return fmt.Errorf(`Level %d is out of range for max level %d.`, sl.level, sl.maxLevel)
+	}
+
+	positions := make(map[*node]uint64, sl.num)
+	positions[sl.head] = 0
+	counts := make([]uint64, sl.level)
+	var num uint64
+	var previous *node
+	for n := sl.head.forward[0]; n != nil; n = n.forward[0] {
+		num++
+		if n.backward != previous {
+			return fmt.Errorf(`Node at position %d has an incorrect backward link.`, num)
+		}
+		previous = n
+		if len(n.forward) < 1 || len(n.forward) > int(sl.level) {
+			return fmt.Errorf(`Node at position %d has %d levels, list has %d.`,
+				num, len(n.forward), sl.level)
+		}
+		positions[n] = num
+		for i := range n.forward {
+			counts[i]++
+		}
+	}
+
+	if num != sl.num {
+		return fmt.Errorf(`Found %d nodes, expected %d.`, num, sl.num)
+	}
+
+	for i := 0; i < int(sl.maxLevel); i++ {
+		if i >= int(sl.level) {
+			if sl.head.forward[i] != nil {
+				return fmt.Errorf(`Head has a node at level %d above list level %d.`, i, sl.level)
+			}
+			continue
+		}
+
+		var count uint64
+		for n := sl.head; n != nil; n = n.forward[i] {
+			next := n.forward[i]
+			if next == nil {
+				if n.widths[i] != 0 {
+					return fmt.Errorf(`Node at position %d has width %d at level %d but no forward node.`,
+						positions[n], n.widths[i], i)
+				}
+				break
+			}
+
+			pos, ok := positions[next]
+			if !ok || len(next.forward) <= i {
+				return fmt.Errorf(`Node at position %d links to an unknown node at level %d.`,
+					positions[n], i)
+			}
+
+			if pos <= positions[n] || n.widths[i] != pos-positions[n] {
+				return fmt.Errorf(`Node at position %d has width %d at level %d, expected %d.`,
+					positions[n], n.widths[i], i, pos-positions[n])
+			}
+			count++
+		}
+
+		if count != counts[i] {
+			return fmt.Errorf(`Found %d nodes at level %d, expected %d.`, count, i, counts[i])
+		}
+	}
+
+	return nil
+}
+
 // New will allocate, initialize, and return a new skiplist.
 // The provided parameter should be of type uint and will determine
 // the maximum possible level that will be created to ensure
 // a random and quick distribution of levels.  Parameter must
 // be a uint type.
 func New(ifc interface{}) *SkipList {
-	sl := &SkipList{}
+	sl := &SkipList{rng: newRand()}
+	sl.init(ifc)
+	return sl
+}
+
+// NewMulti will allocate, initialize, and return a new skiplist that
+// keeps every entry inserted, even those comparing equal.  Equal
+// entries are kept in insertion order; Get and Delete act on the
+// oldest of them and GetAll and DeleteAll act on all of them.
+func NewMulti(ifc interface{}) *SkipList {
+	sl := New(ifc)
+	sl.multi = true
+	return sl
+}
+
+// NewWithSource will allocate, initialize, and return a new skiplist
+// that draws its random numbers from the provided source.  Using a
+// source with a fixed seed makes the list deterministic.  The source
+// is used only by this list and needs no locking.
+func NewWithSource(ifc interface{}, source rand.Source) *SkipList {
+	sl := &SkipList{rng: rand.New(source)}
+	sl.init(ifc)
+	return sl
+}
+
+// NewWithFreeList will allocate, initialize, and return a new skiplist
+// that keeps up to size deleted nodes and reuses them for later
+// inserts.  This greatly reduces garbage in workloads that insert and
+// delete heavily.  Iterators stay valid as nodes are reused: one
+// standing on a node that was deleted and reused carries on from the
+// entry it last visited.
+func NewWithFreeList(ifc interface{}, size int) *SkipList {
+	sl := New(ifc)
+	sl.free = newFreeList(size)
+	return sl
+}
+
+// NewWithLevelGenerator will allocate, initialize, and return a new
+// skiplist whose node levels are chosen by the provided generator.
+func NewWithLevelGenerator(ifc interface{}, levels LevelGenerator) *SkipList {
+	sl := &SkipList{rng: newRand(), levels: levels}
 	sl.init(ifc)
 	return sl
 }
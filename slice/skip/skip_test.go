@@ -17,10 +17,13 @@ limitations under the License.
 package skip
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
 )
 
 func generateMockEntries(num int) Entries {
@@ -86,6 +89,98 @@ func TestSplitAt(t *testing.T) {
 	assert.Equal(t, nil, right.ByPosition(1))
 }
 
+func TestMerge(t *testing.T) {
+	entries := generateMockEntries(100)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	left, right := sl.SplitAt(39)
+	merged := left.Merge(right)
+	assert.Equal(t, left, merged)
+	assert.Nil(t, merged.Validate())
+	assert.Equal(t, uint64(100), merged.Len())
+	assert.Equal(t, uint64(0), right.Len())
+	assert.Nil(t, right.Validate())
+	for i, e := range entries {
+		assert.Equal(t, e, merged.ByPosition(uint64(i)))
+	}
+
+	right.Insert(newMockEntry(200))
+	assert.Equal(t, Entries{newMockEntry(200)}, right.Get(newMockEntry(200)))
+	assert.Equal(t, Entries{nil}, merged.Get(newMockEntry(200)))
+}
+
+func TestMergeRandomSplits(t *testing.T) {
+	entries := generateMockEntries(500)
+	sl := New(uint16(0))
+	sl.Insert(entries...)
+
+	for i := 0; i < 50; i++ {
+		left, right := sl.SplitAt(uint64(rand.Intn(499)))
+		sl = left.Merge(right)
+		if !assert.Nil(t, sl.Validate()) {
+			return
+		}
+	}
+	assert.Equal(t, entries, sl.Iter(newMockEntry(0)).exhaust())
+}
+
+func TestMergeEmpty(t *testing.T) {
+	sl := New(uint8(0))
+	other := New(uint64(0))
+	other.Insert(generateMockEntries(50)...)
+
+	sl.Merge(other)
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, uint64(50), sl.Len())
+	assert.Equal(t, newMockEntry(49), sl.Last())
+	assert.Equal(t, Entries{newMockEntry(10)}, sl.Get(newMockEntry(10)))
+
+	sl.Merge(New(uint8(0)))
+	assert.Equal(t, uint64(50), sl.Len())
+	sl.Insert(newMockEntry(100))
+	assert.Nil(t, sl.Validate())
+}
+
+func TestMergeSnapshot(t *testing.T) {
+	left := New(uint8(0))
+	left.Insert(newMockEntry(1), newMockEntry(2))
+	right := New(uint8(0))
+	right.Insert(newMockEntry(3), newMockEntry(4))
+	leftSnapshot, rightSnapshot := left.Snapshot(), right.Snapshot()
+
+	left.Merge(right)
+	assert.Nil(t, left.Validate())
+	assert.Equal(t, uint64(4), left.Len())
+	assert.Equal(t, uint64(2), leftSnapshot.Len())
+	assert.Nil(t, leftSnapshot.Validate())
+	assert.Equal(t, Entries{newMockEntry(3), newMockEntry(4)}, rightSnapshot.Iter(newMockEntry(0)).exhaust())
+}
+
+func TestJoin(t *testing.T) {
+	left := New(uint8(0))
+	left.Insert(newMockEntry(1), newMockEntry(5))
+	right := New(uint8(0))
+	right.Insert(newMockEntry(5), newMockEntry(7))
+
+	result, err := left.Join(right)
+	assert.NotNil(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, uint64(2), left.Len())
+	assert.Equal(t, uint64(2), right.Len())
+
+	_, err = left.Join(left)
+	assert.NotNil(t, err)
+
+	right.Delete(newMockEntry(5))
+	result, err = left.Join(right)
+	assert.Nil(t, err)
+	assert.Equal(t, left, result)
+	assert.Equal(t, Entries{newMockEntry(1), newMockEntry(5), newMockEntry(7)},
+		left.Iter(newMockEntry(0)).exhaust())
+	assert.Nil(t, left.Validate())
+}
+
 func TestSplitLargeSkipList(t *testing.T) {
 	entries := generateMockEntries(100)
 	leftEntries := entries[:50]
@@ -142,6 +237,60 @@ func TestSplitAtSkipListLength(t *testing.T) {
 	assert.Nil(t, right)
 }
 
+func TestSplitEvery(t *testing.T) {
+	entries := generateMockEntries(25)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	lists := sl.SplitEvery(10)
+	assert.Len(t, lists, 3)
+	assert.Equal(t, sl, lists[0])
+	for i, list := range lists {
+		assert.Nil(t, list.Validate())
+		end := min((i+1)*10, len(entries))
+		assert.Equal(t, entries[i*10:end], list.Iter(mockEntry(0)).exhaust())
+		assert.Equal(t, uint64(end-i*10), list.Len())
+	}
+
+	sl = New(uint64(0))
+	sl.Insert(entries...)
+	lists = sl.SplitEvery(5)
+	assert.Len(t, lists, 5)
+	assert.Equal(t, uint64(5), lists[4].Len())
+
+	assert.Equal(t, []*SkipList{sl}, sl.SplitEvery(0))
+	assert.Equal(t, []*SkipList{lists[0]}, lists[0].SplitEvery(100))
+
+	empty := New(uint64(0))
+	assert.Equal(t, []*SkipList{empty}, empty.SplitEvery(3))
+}
+
+func TestGetOneContains(t *testing.T) {
+	m1 := newMockEntry(5)
+	m2 := newMockEntry(6)
+	sl := New(uint8(0))
+	assert.Nil(t, sl.GetOne(m1))
+	assert.False(t, sl.Contains(m1))
+
+	sl.Insert(m1)
+	assert.Equal(t, m1, sl.GetOne(m1))
+	assert.True(t, sl.Contains(m1))
+	assert.Nil(t, sl.GetOne(m2))
+	assert.False(t, sl.Contains(m2))
+}
+
+func TestGetOneNoAllocations(t *testing.T) {
+	sl := New(uint64(0))
+	entries := generateMockEntries(1000)
+	sl.Insert(entries...)
+
+	var e Entry = entries[500]
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, func() {
+		sl.GetOne(e)
+		sl.Contains(e)
+	}))
+}
+
 func TestGetWithPosition(t *testing.T) {
 	m1 := newMockEntry(5)
 	m2 := newMockEntry(6)
@@ -293,6 +442,162 @@ func TestDeleteAll(t *testing.T) {
 	assert.Equal(t, Entries{nil, nil}, sl.Get(m1, m2))
 }
 
+func TestDeleteAtPosition(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Nil(t, sl.DeleteAtPosition(0))
+
+	m1 := newMockEntry(5)
+	m2 := newMockEntry(6)
+	sl.Insert(m1, m2)
+	sl.InsertAtPosition(1, m1)
+
+	assert.Equal(t, m1, sl.DeleteAtPosition(1))
+	assert.Equal(t, uint64(2), sl.Len())
+	assert.Equal(t, Entries{m1, m2}, sl.Iter(mockEntry(0)).exhaust())
+	assert.Nil(t, sl.Validate())
+
+	assert.Nil(t, sl.DeleteAtPosition(2))
+	assert.Equal(t, m2, sl.DeleteAtPosition(1))
+	assert.Equal(t, m1, sl.DeleteAtPosition(0))
+	assert.Equal(t, uint64(0), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
+func TestPositionErrors(t *testing.T) {
+	sl := New(uint8(0))
+	m1 := newMockEntry(5)
+	m2 := newMockEntry(6)
+	sl.Insert(m1)
+
+	e, err := sl.ByPositionE(1)
+	assert.Nil(t, e)
+	assert.Equal(t, OutOfRangeError{Position: 1, Len: 1}, err)
+	assert.True(t, errors.Is(err, common.ErrOutOfRange))
+
+	err = sl.ReplaceAtPositionE(1, m2)
+	assert.True(t, errors.Is(err, common.ErrOutOfRange))
+	assert.Equal(t, uint64(1), sl.Len())
+
+	e, err = sl.DeleteAtPositionE(1)
+	assert.Nil(t, e)
+	assert.True(t, errors.Is(err, common.ErrOutOfRange))
+	assert.Equal(t, uint64(1), sl.Len())
+
+	assert.Nil(t, sl.ReplaceAtPositionE(0, m2))
+	e, err = sl.ByPositionE(0)
+	assert.Nil(t, err)
+	assert.Equal(t, m2, e)
+
+	e, err = sl.DeleteAtPositionE(0)
+	assert.Nil(t, err)
+	assert.Equal(t, m2, e)
+	assert.Equal(t, uint64(0), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
+func TestDeleteAtPositionRandom(t *testing.T) {
+	entries := generateMockEntries(300)
+	sl := New(uint16(0))
+	sl.Insert(entries...)
+
+	for len(entries) > 0 {
+		i := rand.Intn(len(entries))
+		assert.Equal(t, entries[i], sl.DeleteAtPosition(uint64(i)))
+		entries = append(entries[:i], entries[i+1:]...)
+		if !assert.Nil(t, sl.Validate()) {
+			return
+		}
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	entries := generateMockEntries(100)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	assert.Nil(t, sl.DeleteRange(10, 10))
+	assert.Nil(t, sl.DeleteRange(200, 300))
+
+	assert.Equal(t, entries[10:20], sl.DeleteRange(10, 20))
+	assert.Equal(t, uint64(90), sl.Len())
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, entries[20], sl.ByPosition(10))
+
+	assert.Equal(t, entries[:5], sl.DeleteRange(0, 5))
+	assert.Nil(t, sl.Validate())
+
+	assert.Equal(t, entries[95:], sl.DeleteRange(80, 1000))
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, uint64(80), sl.Len())
+	assert.Equal(t, entries[94], sl.Last())
+
+	expected := append(Entries{}, entries[5:10]...)
+	expected = append(expected, entries[20:95]...)
+	assert.Equal(t, expected, sl.Iter(mockEntry(0)).exhaust())
+
+	assert.Len(t, sl.DeleteRange(0, 80), 80)
+	assert.Equal(t, uint64(0), sl.Len())
+	assert.Nil(t, sl.Validate())
+	sl.Insert(newMockEntry(1))
+	assert.Nil(t, sl.Validate())
+}
+
+type scoredEntry struct {
+	score uint64
+	name  string
+}
+
+func (se scoredEntry) Compare(other Entry) int {
+	return mockEntry(se.score).Compare(mockEntry(other.(scoredEntry).score))
+}
+
+func TestMulti(t *testing.T) {
+	sl := NewMulti(uint8(0))
+	a := scoredEntry{10, `a`}
+	b := scoredEntry{10, `b`}
+	c := scoredEntry{5, `c`}
+	d := scoredEntry{10, `d`}
+	e := scoredEntry{20, `e`}
+
+	assert.Equal(t, Entries{nil, nil, nil, nil, nil}, sl.Insert(a, b, c, d, e))
+	assert.Equal(t, uint64(5), sl.Len())
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, Entries{c, a, b, d, e}, sl.Iter(scoredEntry{}).exhaust())
+
+	assert.Equal(t, Entries{a, b, d}, sl.GetAll(scoredEntry{score: 10}))
+	assert.Nil(t, sl.GetAll(scoredEntry{score: 15}))
+	assert.Equal(t, Entries{a}, sl.Get(scoredEntry{score: 10}))
+
+	assert.Equal(t, Entries{a}, sl.Delete(scoredEntry{score: 10}))
+	assert.Equal(t, Entries{b, d}, sl.DeleteAll(scoredEntry{score: 10}))
+	assert.Nil(t, sl.DeleteAll(scoredEntry{score: 10}))
+	assert.Equal(t, Entries{c, e}, sl.Iter(scoredEntry{}).exhaust())
+	assert.Nil(t, sl.Validate())
+
+	clone := sl.Clone()
+	clone.Insert(scoredEntry{5, `f`})
+	assert.Equal(t, uint64(3), clone.Len())
+}
+
+func TestMultiRandom(t *testing.T) {
+	sl := NewMulti(uint16(0))
+	counts := map[uint64]int{}
+	for i := 0; i < 1000; i++ {
+		score := uint64(rand.Intn(20))
+		sl.Insert(scoredEntry{score, ``})
+		counts[score]++
+	}
+	assert.Nil(t, sl.Validate())
+
+	for score, count := range counts {
+		assert.Len(t, sl.GetAll(scoredEntry{score: score}), count)
+	}
+
+	assert.Len(t, sl.DeleteAll(scoredEntry{score: 3}), counts[3])
+	assert.Equal(t, uint64(1000-counts[3]), sl.Len())
+	assert.Nil(t, sl.Validate())
+}
+
 func TestIter(t *testing.T) {
 	sl := New(uint8(0))
 	m1 := newMockEntry(5)
@@ -316,6 +621,142 @@ func TestIter(t *testing.T) {
 	assert.Equal(t, Entries{}, iter.exhaust())
 }
 
+func TestIterRange(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Equal(t, Entries{}, sl.IterRange(mockEntry(0), mockEntry(5)).exhaust())
+
+	entries := generateMockEntries(20)
+	sl.Insert(entries...)
+
+	assert.Equal(t, entries[5:10], sl.IterRange(mockEntry(5), mockEntry(10)).exhaust())
+	assert.Equal(t, entries[15:], sl.IterRange(mockEntry(15), nil).exhaust())
+	assert.Equal(t, entries[:3], sl.IterRange(mockEntry(0), mockEntry(3)).exhaust())
+	assert.Equal(t, Entries{}, sl.IterRange(mockEntry(5), mockEntry(5)).exhaust())
+	assert.Equal(t, Entries{}, sl.IterRange(mockEntry(25), mockEntry(30)).exhaust())
+}
+
+func TestFloorCeiling(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Nil(t, sl.Floor(mockEntry(5)))
+	assert.Nil(t, sl.Ceiling(mockEntry(5)))
+	assert.Nil(t, sl.Min())
+	assert.Nil(t, sl.Max())
+
+	for i := uint64(1); i <= 10; i++ {
+		sl.Insert(newMockEntry(i * 10))
+	}
+
+	assert.Equal(t, mockEntry(10), sl.Min())
+	assert.Equal(t, mockEntry(100), sl.Max())
+
+	assert.Nil(t, sl.Floor(mockEntry(5)))
+	assert.Equal(t, mockEntry(10), sl.Floor(mockEntry(10)))
+	assert.Equal(t, mockEntry(50), sl.Floor(mockEntry(55)))
+	assert.Equal(t, mockEntry(100), sl.Floor(mockEntry(1000)))
+
+	assert.Equal(t, mockEntry(10), sl.Ceiling(mockEntry(5)))
+	assert.Equal(t, mockEntry(50), sl.Ceiling(mockEntry(50)))
+	assert.Equal(t, mockEntry(60), sl.Ceiling(mockEntry(55)))
+	assert.Nil(t, sl.Ceiling(mockEntry(101)))
+}
+
+func TestRankSelect(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Equal(t, uint64(0), sl.Rank(mockEntry(5)))
+
+	for i := uint64(1); i <= 10; i++ {
+		sl.Insert(newMockEntry(i * 10))
+	}
+
+	assert.Equal(t, uint64(0), sl.Rank(mockEntry(5)))
+	assert.Equal(t, uint64(0), sl.Rank(mockEntry(10)))
+	assert.Equal(t, uint64(5), sl.Rank(mockEntry(55)))
+	assert.Equal(t, uint64(10), sl.Rank(mockEntry(1000)))
+
+	for i := uint64(0); i < 10; i++ {
+		e := sl.Select(i)
+		assert.Equal(t, i, sl.Rank(e))
+	}
+	assert.Nil(t, sl.Select(10))
+}
+
+func TestIterReverse(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Nil(t, sl.Last())
+	assert.False(t, sl.IterReverse(mockEntry(5)).Next())
+
+	m1 := newMockEntry(5)
+	m2 := newMockEntry(10)
+	m3 := newMockEntry(15)
+	sl.Insert(m2, m3, m1)
+	assert.Equal(t, m3, sl.Last())
+
+	iter := sl.IterReverse(mockEntry(20))
+	assert.Equal(t, Entries{m3, m2, m1}, iter.exhaust())
+
+	iter = sl.IterReverse(mockEntry(10))
+	assert.Equal(t, Entries{m2, m1}, iter.exhaust())
+
+	iter = sl.IterReverse(mockEntry(12))
+	assert.Equal(t, Entries{m2, m1}, iter.exhaust())
+
+	iter = sl.IterReverse(mockEntry(4))
+	assert.Equal(t, Entries{}, iter.exhaust())
+}
+
+func TestIterPrev(t *testing.T) {
+	sl := New(uint8(0))
+	m1 := newMockEntry(5)
+	m2 := newMockEntry(10)
+	m3 := newMockEntry(15)
+	sl.Insert(m1, m2, m3)
+
+	iter := sl.Iter(mockEntry(10))
+	assert.True(t, iter.Next())
+	assert.True(t, iter.Next())
+	assert.Equal(t, m3, iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, m2, iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, m1, iter.Value())
+	assert.False(t, iter.Prev())
+	assert.Nil(t, iter.Value())
+
+	iter = sl.IterReverse(mockEntry(10))
+	assert.True(t, iter.Next())
+	assert.True(t, iter.Next())
+	assert.Equal(t, m1, iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, m2, iter.Value())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, m3, iter.Value())
+}
+
+func TestBackwardLinksMaintained(t *testing.T) {
+	sl := New(uint64(0))
+	entries := generateRandomMockEntries(200)
+	sl.Insert(entries...)
+	assert.Nil(t, sl.Validate())
+
+	sl.Delete(entries[:100]...)
+	assert.Nil(t, sl.Validate())
+
+	snapshot := sl.Snapshot()
+	sl.Insert(newMockEntry(1))
+	assert.Nil(t, sl.Validate())
+	assert.Nil(t, snapshot.Validate())
+
+	left, right := sl.SplitAt(sl.Len() / 2)
+	assert.Nil(t, left.Validate())
+	assert.Nil(t, right.Validate())
+
+	reversed := Entries{}
+	for iter := right.IterReverse(right.Last()); iter.Next(); {
+		reversed = append(reversed, iter.Value())
+	}
+	assert.Equal(t, int(right.Len()), len(reversed))
+}
+
 func BenchmarkInsert(b *testing.B) {
 	numItems := b.N
 	sl := New(uint64(0))
@@ -343,6 +784,20 @@ func BenchmarkGet(b *testing.B) {
 	}
 }
 
+func BenchmarkGetOne(b *testing.B) {
+	numItems := b.N
+	sl := New(uint64(0))
+
+	entries := generateMockEntries(numItems)
+	sl.Insert(entries...)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.GetOne(entries[i%numItems])
+	}
+}
+
 func BenchmarkDelete(b *testing.B) {
 	numItems := b.N
 	sl := New(uint64(0))
@@ -357,6 +812,34 @@ func BenchmarkDelete(b *testing.B) {
 	}
 }
 
+func BenchmarkChurn(b *testing.B) {
+	sl := New(uint64(0))
+	entries := generateMockEntries(1000)
+	sl.Insert(entries...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Delete(entries[i%len(entries)])
+		sl.Insert(entries[i%len(entries)])
+	}
+}
+
+func BenchmarkChurnFreeList(b *testing.B) {
+	sl := NewWithFreeList(uint64(0), 100)
+	entries := generateMockEntries(1000)
+	sl.Insert(entries...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Delete(entries[i%len(entries)])
+		sl.Insert(entries[i%len(entries)])
+	}
+}
+
 func BenchmarkPrepend(b *testing.B) {
 	numItems := b.N
 	sl := New(uint64(0))
@@ -399,3 +882,590 @@ func BenchmarkInsertAtPosition(b *testing.B) {
 		sl.InsertAtPosition(0, entries[i%numItems])
 	}
 }
+
+func TestInsertManyAtPosition(t *testing.T) {
+	sl := New(uint64(0))
+	sl.InsertManyAtPosition(0)
+	assert.Equal(t, uint64(0), sl.Len())
+
+	entries := generateMockEntries(100)
+	sl.InsertManyAtPosition(5, entries[40:60]...)
+	assert.Nil(t, sl.Validate())
+	sl.InsertManyAtPosition(0, entries[:40]...)
+	assert.Nil(t, sl.Validate())
+	sl.InsertManyAtPosition(1000, entries[80:]...)
+	assert.Nil(t, sl.Validate())
+	sl.InsertManyAtPosition(60, entries[60:80]...)
+	assert.Nil(t, sl.Validate())
+
+	assert.Equal(t, uint64(100), sl.Len())
+	assert.Equal(t, entries, sl.Iter(mockEntry(0)).exhaust())
+	for i, e := range entries {
+		assert.Equal(t, e, sl.ByPosition(uint64(i)))
+	}
+	reversed := sl.IterReverse(mockEntry(99)).exhaust()
+	assert.Equal(t, entries[99], reversed[0])
+	assert.Equal(t, entries[0], reversed[99])
+}
+
+func TestInsertManyAtPositionMatchesLoop(t *testing.T) {
+	sl := NewWithFreeList(uint8(0), 100)
+	expected := Entries{}
+	for i := 0; i < 50; i++ {
+		run := generateRandomMockEntries(rand.Intn(20))
+		pos := uint64(rand.Intn(len(expected) + 1))
+		sl.InsertManyAtPosition(pos, run...)
+		expected = append(expected[:pos], append(run, expected[pos:]...)...)
+
+		if i%10 == 9 {
+			sl.DeleteRange(0, 20)
+			expected = expected[min(20, len(expected)):]
+		}
+		if !assert.Nil(t, sl.Validate()) {
+			return
+		}
+	}
+
+	assert.Equal(t, uint64(len(expected)), sl.Len())
+	for i, e := range expected {
+		assert.Equal(t, e, sl.ByPosition(uint64(i)))
+	}
+}
+
+func BenchmarkInsertManyAtPosition(b *testing.B) {
+	sl := New(uint64(0))
+	sl.Insert(generateMockEntries(10000)...)
+	run := generateRandomMockEntries(100)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.InsertManyAtPosition(5000, run...)
+		sl.DeleteRange(5000, 5100)
+	}
+}
+
+func BenchmarkInsertAtPositionLoop(b *testing.B) {
+	sl := New(uint64(0))
+	sl.Insert(generateMockEntries(10000)...)
+	run := generateRandomMockEntries(100)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for j, e := range run {
+			sl.InsertAtPosition(uint64(5000+j), e)
+		}
+		sl.DeleteRange(5000, 5100)
+	}
+}
+
+func TestClone(t *testing.T) {
+	entries := generateMockEntries(100)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	clone := sl.Clone()
+	assert.Nil(t, clone.Validate())
+	assert.Equal(t, nodeLevels(sl), nodeLevels(clone))
+
+	sl.Delete(entries[:50]...)
+	clone.Insert(mockEntry(500))
+
+	assert.Equal(t, uint64(101), clone.Len())
+	assert.Equal(t, entries, clone.IterRange(mockEntry(0), mockEntry(100)).exhaust())
+	assert.Equal(t, uint64(50), sl.Len())
+	assert.Equal(t, Entries{nil}, sl.Get(mockEntry(500)))
+	assert.Nil(t, sl.Validate())
+	assert.Nil(t, clone.Validate())
+}
+
+func TestSnapshot(t *testing.T) {
+	entries := generateMockEntries(100)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	snapshot := sl.Snapshot()
+	sl.Delete(entries[:50]...)
+	sl.Insert(mockEntry(500))
+	sl.ReplaceAtPosition(0, mockEntry(49))
+
+	assert.Equal(t, uint64(100), snapshot.Len())
+	assert.Equal(t, entries, snapshot.Iter(mockEntry(0)).exhaust())
+	for i, e := range entries {
+		assert.Equal(t, e, snapshot.ByPosition(uint64(i)))
+	}
+
+	assert.Equal(t, uint64(51), sl.Len())
+	assert.Equal(t, mockEntry(49), sl.ByPosition(0))
+	assert.Equal(t, mockEntry(500), sl.ByPosition(50))
+}
+
+func TestSnapshotMutation(t *testing.T) {
+	entries := generateMockEntries(10)
+	sl := New(uint8(0))
+	sl.Insert(entries...)
+
+	snapshot := sl.Snapshot()
+	snapshot.Delete(entries[0])
+	snapshot.InsertAtPosition(0, mockEntry(20))
+
+	assert.Equal(t, entries, sl.Iter(mockEntry(0)).exhaust())
+	assert.Equal(t, mockEntry(20), snapshot.ByPosition(0))
+	assert.Equal(t, entries[1], snapshot.ByPosition(1))
+	assert.Equal(t, uint64(10), snapshot.Len())
+
+	left, right := snapshot.SplitAt(4)
+	assert.Equal(t, uint64(5), left.Len())
+	assert.Equal(t, uint64(5), right.Len())
+	assert.Equal(t, uint64(10), sl.Len())
+	assert.Equal(t, entries, sl.Iter(mockEntry(0)).exhaust())
+}
+
+func TestSnapshotOfSnapshot(t *testing.T) {
+	entries := generateMockEntries(10)
+	sl := New(uint8(0))
+	sl.Insert(entries...)
+
+	first := sl.Snapshot()
+	second := first.Snapshot()
+	first.Delete(entries...)
+	sl.Insert(mockEntry(11))
+
+	assert.Equal(t, uint64(0), first.Len())
+	assert.Equal(t, uint64(11), sl.Len())
+	assert.Equal(t, entries, second.Iter(mockEntry(0)).exhaust())
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	numItems := 1000
+	sl := New(uint64(0))
+	entries := generateMockEntries(numItems)
+	sl.Insert(entries...)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Snapshot()
+		sl.Insert(entries[i%numItems])
+	}
+}
+
+func TestEach(t *testing.T) {
+	entries := generateMockEntries(20)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	result := make(Entries, 0, len(entries))
+	sl.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, entries, result)
+
+	result = result[:0]
+	sl.Each(func(e Entry) bool {
+		result = append(result, e)
+		return len(result) < 5
+	})
+	assert.Equal(t, entries[:5], result)
+
+	count := 0
+	fn := func(Entry) bool {
+		count++
+		return true
+	}
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { sl.Each(fn) }))
+}
+
+func TestRange(t *testing.T) {
+	sl := New(uint64(0))
+	collect := func(start, stop Entry) Entries {
+		result := Entries{}
+		sl.Range(start, stop, func(e Entry) bool {
+			result = append(result, e)
+			return true
+		})
+		return result
+	}
+	assert.Equal(t, Entries{}, collect(mockEntry(0), mockEntry(5)))
+
+	entries := generateMockEntries(20)
+	sl.Insert(entries...)
+
+	assert.Equal(t, entries[5:10], collect(mockEntry(5), mockEntry(10)))
+	assert.Equal(t, entries[15:], collect(mockEntry(15), nil))
+	assert.Equal(t, entries[:3], collect(nil, mockEntry(3)))
+	assert.Equal(t, entries, collect(nil, nil))
+	assert.Equal(t, Entries{}, collect(mockEntry(5), mockEntry(5)))
+	assert.Equal(t, Entries{}, collect(mockEntry(25), mockEntry(30)))
+
+	result := Entries{}
+	sl.Range(mockEntry(5), nil, func(e Entry) bool {
+		result = append(result, e)
+		return len(result) < 3
+	})
+	assert.Equal(t, entries[5:8], result)
+
+	// deleting the entry visited resumes after it
+	result = result[:0]
+	sl.Range(mockEntry(5), mockEntry(10), func(e Entry) bool {
+		result = append(result, e)
+		sl.Delete(e)
+		return true
+	})
+	assert.Equal(t, entries[5:10], result)
+	assert.Equal(t, uint64(15), sl.Len())
+
+	fn := func(Entry) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() {
+		sl.Range(mockEntry(2), mockEntry(15), fn)
+	}))
+}
+
+func TestAll(t *testing.T) {
+	entries := generateMockEntries(20)
+	sl := New(uint64(0))
+	sl.Insert(entries...)
+
+	result := make(Entries, 0, len(entries))
+	for e := range sl.All() {
+		result = append(result, e)
+	}
+	assert.Equal(t, entries, result)
+
+	result = result[:0]
+	for e := range sl.All() {
+		if len(result) == 5 {
+			break
+		}
+		result = append(result, e)
+	}
+	assert.Equal(t, entries[:5], result)
+}
+
+func BenchmarkEach(b *testing.B) {
+	numItems := 1000
+	sl := New(uint64(0))
+	sl.Insert(generateMockEntries(numItems)...)
+	fn := func(Entry) bool { return true }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Each(fn)
+	}
+}
+
+func BenchmarkRange(b *testing.B) {
+	numItems := 1000
+	sl := New(uint64(0))
+	sl.Insert(generateMockEntries(numItems)...)
+	fn := func(Entry) bool { return true }
+	var start, stop Entry = mockEntry(numItems / 4), mockEntry(numItems * 3 / 4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Range(start, stop, fn)
+	}
+}
+
+func BenchmarkIterRange(b *testing.B) {
+	numItems := 1000
+	sl := New(uint64(0))
+	sl.Insert(generateMockEntries(numItems)...)
+	var start, stop Entry = mockEntry(numItems / 4), mockEntry(numItems * 3 / 4)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for iter := sl.IterRange(start, stop); iter.Next(); {
+			iter.Value()
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sl := New(uint64(0))
+	assert.Nil(t, sl.Validate())
+
+	entries := generateRandomMockEntries(1000)
+	sl.Insert(entries...)
+	assert.Nil(t, sl.Validate())
+
+	for i := 0; i < 100; i++ {
+		sl.InsertAtPosition(uint64(rand.Intn(int(sl.Len()))), newMockEntry(uint64(i)))
+	}
+	assert.Nil(t, sl.Validate())
+
+	sl.Delete(entries[:500]...)
+	assert.Nil(t, sl.Validate())
+
+	left, right := sl.SplitAt(sl.Len() / 2)
+	assert.Nil(t, left.Validate())
+	assert.Nil(t, right.Validate())
+
+	left.Delete(entries[500:]...)
+	right.Delete(entries[500:]...)
+	assert.Nil(t, left.Validate())
+	assert.Nil(t, right.Validate())
+}
+
+func TestFreeList(t *testing.T) {
+	sl := NewWithFreeList(uint64(0), 1000)
+	entries := generateRandomMockEntries(1000)
+	sl.Insert(entries...)
+
+	sl.Delete(entries[:500]...)
+	assert.Equal(t, 500, sl.free.num)
+	assert.Nil(t, sl.Validate())
+	for _, free := range sl.free.levels {
+		for _, n := range free {
+			assert.Nil(t, n.entry)
+			assert.Nil(t, n.backward)
+		}
+	}
+
+	sl.Insert(entries[:500]...)
+	assert.Equal(t, uint64(1000), sl.Len())
+	assert.Nil(t, sl.Validate())
+	assert.Equal(t, entries, sl.Get(entries...))
+
+	sl.DeleteRange(100, 300)
+	sl.DeleteAtPosition(0)
+	assert.Equal(t, uint64(799), sl.Len())
+	assert.Nil(t, sl.Validate())
+	assert.True(t, sl.free.num > 0)
+
+	left, right := sl.SplitAt(400)
+	assert.Equal(t, 0, right.free.num)
+	assert.Equal(t, sl.free.max, right.free.max)
+	right.Delete(entries...)
+	assert.Equal(t, uint64(0), right.Len())
+	assert.Nil(t, left.Validate())
+}
+
+func TestFreeListLimit(t *testing.T) {
+	level := uint8(1)
+	sl := NewWithLevelGenerator(uint64(0), func(uint8) uint8 { return level })
+	sl.free = newFreeList(10)
+	entries := generateMockEntries(100)
+	sl.Insert(entries...)
+	sl.Delete(entries...)
+	assert.Equal(t, 10, sl.free.num)
+
+	// a free node is only reused for a new node of the same level
+	level = 2
+	sl.Insert(entries[:5]...)
+	assert.Equal(t, 10, sl.free.num)
+	level = 1
+	sl.Insert(entries[5:]...)
+	assert.Equal(t, 0, sl.free.num)
+	assert.Equal(t, entries, sl.Get(entries...))
+	assert.Nil(t, sl.Validate())
+}
+
+func TestFreeListIterator(t *testing.T) {
+	sl := NewWithFreeList(uint64(0), 10)
+	entries := generateMockEntries(10)
+	sl.Insert(entries...)
+
+	iter := sl.Iter(mockEntry(0))
+	for i := 0; i < 3; i++ {
+		iter.Next()
+	}
+	assert.Equal(t, mockEntry(2), iter.Value())
+
+	// the node the iterator stands on is deleted and reused
+	sl.Delete(mockEntry(2))
+	sl.Insert(mockEntry(100))
+	assert.Equal(t, Entries{mockEntry(3), mockEntry(4), mockEntry(5), mockEntry(6),
+		mockEntry(7), mockEntry(8), mockEntry(9), mockEntry(100)}, iter.exhaust())
+}
+
+func TestFreeListSnapshot(t *testing.T) {
+	sl := NewWithFreeList(uint64(0), 100)
+	entries := generateMockEntries(10)
+	sl.Insert(entries...)
+
+	snap := sl.Snapshot()
+	sl.Delete(entries...)
+	assert.Equal(t, uint64(10), snap.Len())
+	assert.Equal(t, entries, snap.Get(entries...))
+	assert.True(t, snap.free != sl.free)
+	assert.Nil(t, New(uint8(0)).free)
+}
+
+func TestMetrics(t *testing.T) {
+	sl := New(uint64(0))
+	entries := generateMockEntries(100)
+	sl.Insert(entries[:10]...)
+	metrics := newMockMetrics()
+	sl.SetMetrics(metrics)
+	assert.Equal(t, int64(10), metrics.counts[common.MetricNodes])
+
+	sl.Insert(entries...)
+	assert.Equal(t, int64(100), metrics.counts[common.MetricInserts])
+	assert.Equal(t, int64(100), metrics.counts[common.MetricNodes])
+	assert.Len(t, metrics.observations[common.MetricLevel], 90)
+	for _, level := range metrics.observations[common.MetricLevel] {
+		assert.True(t, level >= 1 && level <= 64)
+	}
+
+	sl.Get(entries[0], entries[1])
+	sl.Contains(entries[2])
+	sl.GetWithPosition(entries[3])
+	assert.Equal(t, int64(4), metrics.counts[common.MetricSearches])
+
+	sl.Delete(entries[:10]...)
+	sl.DeleteAtPosition(0)
+	sl.DeleteRange(0, 9)
+	assert.Equal(t, int64(20), metrics.counts[common.MetricDeletes])
+	assert.Equal(t, int64(80), metrics.counts[common.MetricNodes])
+
+	_, right := sl.SplitAt(39)
+	assert.Equal(t, int64(40), metrics.counts[common.MetricNodes])
+	assert.Nil(t, right.metrics)
+	sl.Merge(right)
+	assert.Equal(t, int64(80), metrics.counts[common.MetricNodes])
+	assert.Nil(t, sl.Validate())
+
+	sl.SetMetrics(nil)
+	sl.Insert(newMockEntry(1000))
+	assert.Equal(t, int64(80), metrics.counts[common.MetricNodes])
+}
+
+func TestValidateCorruption(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(generateMockEntries(10)...)
+	if !assert.Nil(t, sl.Validate()) {
+		return
+	}
+
+	sl.head.widths[0]++
+	assert.NotNil(t, sl.Validate())
+	sl.head.widths[0]--
+
+	sl.num++
+	assert.NotNil(t, sl.Validate())
+	sl.num--
+
+	sl.head.forward[0] = sl.head.forward[0].forward[0]
+	assert.NotNil(t, sl.Validate())
+}
+
+func TestSizeOf(t *testing.T) {
+	sl := New(uint8(0))
+	empty := listSize + 8*pointerSize + 8*widthSize + nodeSize + 8*(pointerSize+widthSize)
+	assert.Equal(t, empty, sl.SizeOf())
+
+	entries := generateMockEntries(100)
+	sl.Insert(entries...)
+
+	expected := empty
+	for n := sl.head.forward[0]; n != nil; n = n.forward[0] {
+		expected += nodeSize + uint64(len(n.forward))*(pointerSize+widthSize)
+	}
+	assert.Equal(t, expected, sl.SizeOf())
+	assert.True(t, sl.SizeOf() >= empty+100*(nodeSize+pointerSize+widthSize))
+
+	sl.Delete(entries...)
+	assert.Equal(t, empty, sl.SizeOf())
+}
+
+type weightedMockEntry struct {
+	key    uint64
+	weight float64
+}
+
+func (wme weightedMockEntry) Compare(other Entry) int {
+	otherKey := other.(weightedMockEntry).key
+	if wme.key == otherKey {
+		return 0
+	}
+
+	if wme.key > otherKey {
+		return 1
+	}
+
+	return -1
+}
+
+func (wme weightedMockEntry) Weight() float64 {
+	return wme.weight
+}
+
+func TestRandomSample(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Nil(t, sl.RandomSample(3))
+
+	for i := uint64(0); i < 20; i++ {
+		sl.Insert(newMockEntry(i))
+	}
+
+	result := sl.RandomSample(5)
+	assert.Len(t, result, 5)
+	for i := 1; i < len(result); i++ {
+		assert.True(t, result[i-1].Compare(result[i]) < 0)
+	}
+
+	assert.Len(t, sl.RandomSample(30), 20)
+	assert.Nil(t, sl.RandomSample(0))
+}
+
+func TestRandomSampleUniform(t *testing.T) {
+	sl := New(uint8(0))
+	for i := uint64(0); i < 4; i++ {
+		sl.Insert(newMockEntry(i))
+	}
+
+	counts := make([]int, 4)
+	for i := 0; i < 4000; i++ {
+		for _, e := range sl.RandomSample(1) {
+			counts[e.(mockEntry)]++
+		}
+	}
+
+	for _, count := range counts {
+		assert.True(t, count > 800 && count < 1200)
+	}
+}
+
+func TestWeightedSample(t *testing.T) {
+	sl := New(uint8(0))
+	assert.Nil(t, sl.WeightedSample(1))
+
+	sl.Insert(
+		weightedMockEntry{key: 0, weight: 1},
+		weightedMockEntry{key: 1, weight: 3},
+		weightedMockEntry{key: 2, weight: 0},
+		weightedMockEntry{key: 3, weight: -1},
+	)
+
+	counts := make([]int, 4)
+	for i := 0; i < 4000; i++ {
+		result := sl.WeightedSample(1)
+		assert.Len(t, result, 1)
+		counts[result[0].(weightedMockEntry).key]++
+	}
+
+	assert.Equal(t, 0, counts[2])
+	assert.Equal(t, 0, counts[3])
+	assert.True(t, counts[1] > 2700 && counts[1] < 3300)
+
+	result := sl.WeightedSample(4)
+	assert.Equal(t, Entries{
+		weightedMockEntry{key: 0, weight: 1},
+		weightedMockEntry{key: 1, weight: 3},
+	}, result)
+}
+
+func TestWeightedSampleIgnoresUnweighted(t *testing.T) {
+	sl := New(uint8(0))
+	sl.Insert(newMockEntry(1), newMockEntry(2))
+	assert.Nil(t, sl.WeightedSample(2))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"slices"
+	"sort"
+)
+
+// Search returns the lowest position in the sorted slice at which x
+// could be inserted while keeping it sorted, and a bool indicating if
+// the item at that position equals x.  The behavior is undefined if
+// the slice is not sorted by less.
+func Search[T any](s []T, x T, less func(a, b T) bool) (int, bool) {
+	i := sort.Search(len(s), func(i int) bool {
+		return !less(s[i], x)
+	})
+
+	return i, i < len(s) && !less(x, s[i])
+}
+
+// Insert will insert x into its sorted position in the slice and
+// return the slice with the value added.  As with Int64Slice, the
+// slice is returned unchanged if it already holds an item equal to x.
+func Insert[T any](s []T, x T, less func(a, b T) bool) []T {
+	i, ok := Search(s, x, less)
+	if ok {
+		return s
+	}
+
+	return slices.Insert(s, i, x)
+}
+
+// Delete removes the item equal to x from the sorted slice, if there
+// is one, and returns the shortened slice.  The vacated element at the
+// end is zeroed so that it doesn't keep its value alive.
+func Delete[T any](s []T, x T, less func(a, b T) bool) []T {
+	i, ok := Search(s, x, less)
+	if !ok {
+		return s
+	}
+
+	return slices.Delete(s, i, i+1)
+}
+
+// Merge returns a new sorted slice holding the items of both sorted
+// slices.  Equal items are all kept, those from a before those from
+// b, so the merge is stable.  Use Dedup to drop them.
+func Merge[T any](a, b []T, less func(a, b T) bool) []T {
+	result := make([]T, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if less(b[0], a[0]) {
+			result = append(result, b[0])
+			b = b[1:]
+		} else {
+			result = append(result, a[0])
+			a = a[1:]
+		}
+	}
+
+	result = append(result, a...)
+	return append(result, b...)
+}
+
+// Dedup removes all but the first of every run of equal items in the
+// sorted slice, in place, and returns the shortened slice.  The
+// vacated elements at the end are zeroed.
+func Dedup[T any](s []T, less func(a, b T) bool) []T {
+	return slices.CompactFunc(s, func(a, b T) bool {
+		return !less(a, b) && !less(b, a)
+	})
+}
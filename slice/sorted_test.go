@@ -0,0 +1,102 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type keyed struct {
+	key   string
+	value int
+}
+
+func byKey(a, b keyed) bool {
+	return a.key < b.key
+}
+
+func lessInt(a, b int) bool {
+	return a < b
+}
+
+func TestGenericSearch(t *testing.T) {
+	s := []int{1, 3, 3, 6}
+
+	i, ok := Search(s, 3, lessInt)
+	assert.Equal(t, 1, i)
+	assert.True(t, ok)
+
+	i, ok = Search(s, 4, lessInt)
+	assert.Equal(t, 3, i)
+	assert.False(t, ok)
+
+	i, ok = Search(s, 7, lessInt)
+	assert.Equal(t, 4, i)
+	assert.False(t, ok)
+
+	i, ok = Search(nil, 7, lessInt)
+	assert.Equal(t, 0, i)
+	assert.False(t, ok)
+}
+
+func TestGenericInsert(t *testing.T) {
+	var s []keyed
+	for _, key := range []string{`c`, `a`, `d`, `b`} {
+		s = Insert(s, keyed{key: key}, byKey)
+	}
+	s = Insert(s, keyed{key: `a`, value: 1}, byKey)
+
+	assert.Equal(t, []keyed{{key: `a`}, {key: `b`}, {key: `c`}, {key: `d`}}, s)
+}
+
+func TestGenericDelete(t *testing.T) {
+	s := []int{1, 3, 6}
+	backing := s
+
+	s = Delete(s, 4, lessInt)
+	assert.Equal(t, []int{1, 3, 6}, s)
+
+	s = Delete(s, 1, lessInt)
+	assert.Equal(t, []int{3, 6}, s)
+	assert.Equal(t, []int{3, 6, 0}, backing)
+
+	s = Delete(Delete(s, 3, lessInt), 6, lessInt)
+	assert.Len(t, s, 0)
+}
+
+func TestMerge(t *testing.T) {
+	a := []keyed{{`a`, 0}, {`c`, 0}, {`e`, 0}}
+	b := []keyed{{`b`, 1}, {`c`, 1}, {`f`, 1}, {`g`, 1}}
+
+	assert.Equal(t, []keyed{
+		{`a`, 0}, {`b`, 1}, {`c`, 0}, {`c`, 1}, {`e`, 0}, {`f`, 1}, {`g`, 1},
+	}, Merge(a, b, byKey))
+	assert.Equal(t, a, Merge(a, nil, byKey))
+	assert.Len(t, Merge[int](nil, nil, lessInt), 0)
+}
+
+func TestDedup(t *testing.T) {
+	s := []keyed{{`a`, 0}, {`a`, 1}, {`b`, 0}, {`c`, 0}, {`c`, 1}, {`c`, 2}}
+	backing := s
+
+	s = Dedup(s, byKey)
+	assert.Equal(t, []keyed{{`a`, 0}, {`b`, 0}, {`c`, 0}}, s)
+	assert.Equal(t, keyed{}, backing[5])
+	assert.Len(t, Dedup(Merge([]int{1, 2}, []int{2, 3}, lessInt), lessInt), 3)
+}
@@ -0,0 +1,188 @@
+package merge
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// minParallel is the fewest items per worker for which sorting or
+// merging in parallel is worth the goroutines.
+const minParallel = 1 << 12
+
+// Sort sorts the slice in place by the provided less function using
+// up to workers goroutines.  A workers value less than one uses
+// GOMAXPROCS goroutines.  The slice is split into one run per
+// worker, the runs are sorted concurrently and then merged pairwise,
+// each merge itself being split between the workers.  The merges
+// need a buffer as large as the slice.  Equal items may be
+// reordered; use SortStable to keep them in their original order.
+func Sort[T any](s []T, less func(a, b T) bool, workers int) {
+	parallelSort(s, less, workers, false)
+}
+
+// SortStable sorts the slice in place as Sort does while keeping
+// equal items in their original order.
+func SortStable[T any](s []T, less func(a, b T) bool, workers int) {
+	parallelSort(s, less, workers, true)
+}
+
+// MultithreadedSortComparatorsWithWorkers returns a sorted copy of
+// the list of comparators as MultithreadedSortComparators does using
+// up to workers goroutines.
+func MultithreadedSortComparatorsWithWorkers(comparators Comparators, workers int) Comparators {
+	return sortComparators(comparators, workers, false)
+}
+
+// MultithreadedStableSortComparators returns a sorted copy of the
+// list of comparators using up to workers goroutines, keeping
+// comparators that compare equal in their original order.
+func MultithreadedStableSortComparators(comparators Comparators, workers int) Comparators {
+	return sortComparators(comparators, workers, true)
+}
+
+func sortComparators(comparators Comparators, workers int, stable bool) Comparators {
+	toBeSorted := make(Comparators, len(comparators))
+	copy(toBeSorted, comparators)
+	parallelSort(toBeSorted, lessComparator, workers, stable)
+	return toBeSorted
+}
+
+func lessComparator(a, b Comparator) bool {
+	return a.Compare(b) < 0
+}
+
+func parallelSort[T any](s []T, less func(a, b T) bool, workers int, stable bool) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(min(workers, len(s)/minParallel), 1)
+
+	cmp := func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	}
+
+	// bounds[i] is the start of the i-th run
+	bounds := make([]int, workers+1)
+	for i := range bounds {
+		bounds[i] = i * len(s) / workers
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func(run []T) {
+			defer wg.Done()
+			if stable {
+				slices.SortStableFunc(run, cmp)
+			} else {
+				slices.SortFunc(run, cmp)
+			}
+		}(s[bounds[i]:bounds[i+1]])
+	}
+	wg.Wait()
+
+	if workers == 1 {
+		return
+	}
+
+	src, dst := s, make([]T, len(s))
+	for len(bounds) > 2 {
+		pairs := (len(bounds) - 1) / 2
+		next := make([]int, 0, pairs+2)
+		wg.Add(pairs)
+		for i := 0; i+2 < len(bounds); i += 2 {
+			lo, mid, hi := bounds[i], bounds[i+1], bounds[i+2]
+			go func() {
+				defer wg.Done()
+				parallelMerge(dst[lo:hi], src[lo:mid], src[mid:hi], less, max(workers/pairs, 1))
+			}()
+			next = append(next, lo)
+		}
+		if len(bounds)%2 == 0 {
+			// an odd run out carries over to the next round
+			lo := bounds[len(bounds)-2]
+			copy(dst[lo:], src[lo:])
+			next = append(next, lo)
+		}
+		wg.Wait()
+
+		bounds = append(next, len(s))
+		src, dst = dst, src
+	}
+
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// parallelMerge merges the sorted slices a and b into dst, which must
+// be as long as both, using up to workers goroutines.  Items of a
+// come before equal items of b, so the merge is stable.
+func parallelMerge[T any](dst, a, b []T, less func(a, b T) bool, workers int) {
+	if workers < 2 || len(dst) < 2*minParallel {
+		merge(dst, a, b, less)
+		return
+	}
+
+	// split the longer slice in half and the other where the middle
+	// item would go, so everything on the left goes before the right
+	var i, j int
+	if len(a) >= len(b) {
+		i = len(a) / 2
+		j = searchFunc(b, func(x T) bool { return !less(x, a[i]) })
+	} else {
+		j = len(b) / 2
+		i = searchFunc(a, func(x T) bool { return less(b[j], x) })
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		parallelMerge(dst[:i+j], a[:i], b[:j], less, workers/2)
+	}()
+	parallelMerge(dst[i+j:], a[i:], b[j:], less, workers-workers/2)
+	wg.Wait()
+}
+
+// searchFunc returns the first index in s for which the predicate,
+// which must be false and then true, holds.
+func searchFunc[T any](s []T, pred func(T) bool) int {
+	lo, hi := 0, len(s)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if pred(s[mid]) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return lo
+}
+
+// merge merges the sorted slices a and b into dst, taking from a
+// first when items are equal.
+func merge[T any](dst, a, b []T, less func(a, b T) bool) {
+	k := 0
+	for len(a) > 0 && len(b) > 0 {
+		if less(b[0], a[0]) {
+			dst[k] = b[0]
+			b = b[1:]
+		} else {
+			dst[k] = a[0]
+			a = a[1:]
+		}
+		k++
+	}
+
+	k += copy(dst[k:], a)
+	copy(dst[k:], b)
+}
@@ -0,0 +1,87 @@
+package merge
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pair struct {
+	key, order int
+}
+
+func lessPair(a, b pair) bool {
+	return a.key < b.key
+}
+
+func randomPairs(n, keys int) []pair {
+	r := rand.New(rand.NewSource(int64(n)))
+	pairs := make([]pair, n)
+	for i := range pairs {
+		pairs[i] = pair{key: r.Intn(keys), order: i}
+	}
+
+	return pairs
+}
+
+func TestSortWorkers(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 3*minParallel + 7, 20 * minParallel} {
+		for _, workers := range []int{0, 1, 2, 3, 5, 8} {
+			pairs := randomPairs(n, n/3+1)
+			expected := slices.Clone(pairs)
+			slices.SortStableFunc(expected, func(a, b pair) int { return a.key - b.key })
+
+			stable := slices.Clone(pairs)
+			SortStable(stable, lessPair, workers)
+			assert.Equal(t, expected, stable)
+
+			Sort(pairs, lessPair, workers)
+			assert.True(t, slices.IsSortedFunc(pairs, func(a, b pair) int { return a.key - b.key }))
+		}
+	}
+}
+
+func TestParallelMergeStable(t *testing.T) {
+	a := make([]pair, 5*minParallel)
+	b := make([]pair, 3*minParallel)
+	for i := range a {
+		a[i] = pair{key: i / 7, order: i}
+	}
+	for i := range b {
+		b[i] = pair{key: i / 3, order: len(a) + i}
+	}
+
+	dst := make([]pair, len(a)+len(b))
+	parallelMerge(dst, a, b, lessPair, 8)
+
+	expected := append(slices.Clone(a), b...)
+	slices.SortStableFunc(expected, func(a, b pair) int { return a.key - b.key })
+	assert.Equal(t, expected, dst)
+}
+
+func TestMultithreadedSortComparatorsWorkers(t *testing.T) {
+	comparators := constructOrderedMockComparators(3*minParallel + 1)
+	expected := slices.Clone(comparators)
+	slices.Reverse(comparators)
+
+	for _, workers := range []int{1, 3, 4} {
+		assert.Equal(t, expected, MultithreadedSortComparatorsWithWorkers(comparators, workers))
+		assert.Equal(t, expected, MultithreadedStableSortComparators(comparators, workers))
+	}
+
+	// the input is left unsorted
+	assert.Equal(t, mockComparator(3*minParallel), comparators[0])
+}
+
+func BenchmarkSortStable(b *testing.B) {
+	pairs := randomPairs(1000000, 1000)
+	s := make([]pair, len(pairs))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(s, pairs)
+		SortStable(s, lessPair, 0)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedkv
+
+import "github.com/Workiva/go-datastructures/btree/plus"
+
+// nodeSize is the node size of the underlying B+ tree.
+const nodeSize = 64
+
+type plusEntry entry
+
+// Compare is required by the plus.Key interface, which expects 1 when
+// the provided key is the greater of the two.
+func (pe *plusEntry) Compare(other plus.Key) int {
+	return (*entry)(other.(*plusEntry)).compare((*entry)(pe))
+}
+
+type bPlusTreeMap struct {
+	tree *plus.BTree
+}
+
+func (m *bPlusTreeMap) Get(key Key) (interface{}, bool) {
+	k := m.tree.Get(&plusEntry{key: key})[0]
+	if k == nil {
+		return nil, false
+	}
+
+	return k.(*plusEntry).value, true
+}
+
+func (m *bPlusTreeMap) Put(key Key, value interface{}) (interface{}, bool) {
+	old, ok := m.Get(key)
+	m.tree.Insert(&plusEntry{key: key, value: value})
+	return old, ok
+}
+
+func (m *bPlusTreeMap) Delete(key Key) (interface{}, bool) {
+	k := m.tree.Delete(&plusEntry{key: key})[0]
+	if k == nil {
+		return nil, false
+	}
+
+	return k.(*plusEntry).value, true
+}
+
+func (m *bPlusTreeMap) RangeIter(start, stop Key) Iterator {
+	iter := m.tree.Iter(&plusEntry{key: start})
+	return &iterator{
+		next: func() (*entry, bool) {
+			if !iter.Next() {
+				return nil, false
+			}
+			return (*entry)(iter.Value().(*plusEntry)), true
+		},
+		stop: stop,
+	}
+}
+
+func (m *bPlusTreeMap) Floor(key Key) (Key, interface{}, bool) {
+	k := m.tree.Floor(&plusEntry{key: key})
+	if k == nil {
+		return nil, nil, false
+	}
+
+	return k.(*plusEntry).key, k.(*plusEntry).value, true
+}
+
+func (m *bPlusTreeMap) Ceiling(key Key) (Key, interface{}, bool) {
+	iter := m.tree.Iter(&plusEntry{key: key})
+	if !iter.Next() {
+		return nil, nil, false
+	}
+
+	e := iter.Value().(*plusEntry)
+	return e.key, e.value, true
+}
+
+func (m *bPlusTreeMap) Len() uint64 {
+	return m.tree.Len()
+}
+
+func newBPlusTreeMap() *bPlusTreeMap {
+	return &bPlusTreeMap{tree: plus.New(nodeSize)}
+}
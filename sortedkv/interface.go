@@ -0,0 +1,87 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package sortedkv provides a single ordered key-value map API on top of
+the ordered structures in this library.  The backing structure is chosen
+when the map is constructed, allowing applications to benchmark and
+switch engines without changing call sites.
+
+Available backends:
+SkipList: slice/skip, O(log n) expected for all operations
+BPlusTree: btree/plus, O(log n) for all operations
+
+Maps are not threadsafe.
+*/
+package sortedkv
+
+// Key is a key in the map.
+type Key interface {
+	// Compare returns a negative number if this key is less than the
+	// provided key, 0 if they are equal, and a positive number if this
+	// key is greater.
+	Compare(Key) int
+}
+
+// Iterator iterates over a range of entries in key order.
+type Iterator interface {
+	// Next moves the iterator to the next entry and returns a bool
+	// indicating if there is one.
+	Next() bool
+	// Key returns the key at the current position, or nil if Next
+	// hasn't been called or the iterator is exhausted.
+	Key() Key
+	// Value returns the value at the current position, or nil if Next
+	// hasn't been called or the iterator is exhausted.
+	Value() interface{}
+}
+
+// Map is an ordered map from keys to values.
+type Map interface {
+	// Get returns the value associated with the key and a bool
+	// indicating if it was found.
+	Get(key Key) (interface{}, bool)
+	// Put associates the value with the key and returns the value
+	// it replaced and a bool indicating if there was one.
+	Put(key Key, value interface{}) (interface{}, bool)
+	// Delete removes the key and returns its value and a bool
+	// indicating if it was found.
+	Delete(key Key) (interface{}, bool)
+	// RangeIter returns an iterator over the keys in [start, stop).
+	// A nil start begins at the smallest key and a nil stop continues
+	// through the largest.
+	RangeIter(start, stop Key) Iterator
+	// Floor returns the greatest key less than or equal to the
+	// provided key along with its value.  Returns false if there
+	// is no such key.
+	Floor(key Key) (Key, interface{}, bool)
+	// Ceiling returns the smallest key greater than or equal to the
+	// provided key along with its value.  Returns false if there is
+	// no such key.
+	Ceiling(key Key) (Key, interface{}, bool)
+	// Len returns the number of keys in the map.
+	Len() uint64
+}
+
+// Backend selects the structure used to store a map.
+type Backend int
+
+const (
+	// SkipList stores the map in a skip list.
+	SkipList Backend = iota
+	// BPlusTree stores the map in a B+ tree.
+	BPlusTree
+)
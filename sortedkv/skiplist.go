@@ -0,0 +1,105 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedkv
+
+import "github.com/Workiva/go-datastructures/slice/skip"
+
+type skipEntry entry
+
+// Compare is required by the skip.Entry interface.
+func (se *skipEntry) Compare(other skip.Entry) int {
+	return (*entry)(se).compare((*entry)(other.(*skipEntry)))
+}
+
+type skipListMap struct {
+	sl *skip.SkipList
+}
+
+func (m *skipListMap) Get(key Key) (interface{}, bool) {
+	e := m.sl.Get(&skipEntry{key: key})[0]
+	if e == nil {
+		return nil, false
+	}
+
+	return e.(*skipEntry).value, true
+}
+
+func (m *skipListMap) Put(key Key, value interface{}) (interface{}, bool) {
+	old := m.sl.Insert(&skipEntry{key: key, value: value})[0]
+	if old == nil {
+		return nil, false
+	}
+
+	return old.(*skipEntry).value, true
+}
+
+func (m *skipListMap) Delete(key Key) (interface{}, bool) {
+	e := m.sl.Delete(&skipEntry{key: key})[0]
+	if e == nil {
+		return nil, false
+	}
+
+	return e.(*skipEntry).value, true
+}
+
+func (m *skipListMap) RangeIter(start, stop Key) Iterator {
+	iter := m.sl.Iter(&skipEntry{key: start})
+	return &iterator{
+		next: func() (*entry, bool) {
+			if !iter.Next() {
+				return nil, false
+			}
+			return (*entry)(iter.Value().(*skipEntry)), true
+		},
+		stop: stop,
+	}
+}
+
+func (m *skipListMap) Floor(key Key) (Key, interface{}, bool) {
+	e, i := m.sl.GetWithPosition(&skipEntry{key: key})
+	switch {
+	case e == nil: // every key is less than the provided key
+		i = m.sl.Len()
+	case e.(*skipEntry).key.Compare(key) == 0:
+		return e.(*skipEntry).key, e.(*skipEntry).value, true
+	}
+
+	if i == 0 {
+		return nil, nil, false
+	}
+
+	e = m.sl.ByPosition(i - 1)
+	return e.(*skipEntry).key, e.(*skipEntry).value, true
+}
+
+func (m *skipListMap) Ceiling(key Key) (Key, interface{}, bool) {
+	iter := m.sl.Iter(&skipEntry{key: key})
+	if !iter.Next() {
+		return nil, nil, false
+	}
+
+	e := iter.Value().(*skipEntry)
+	return e.key, e.value, true
+}
+
+func (m *skipListMap) Len() uint64 {
+	return m.sl.Len()
+}
+
+func newSkipListMap() *skipListMap {
+	return &skipListMap{sl: skip.New(uint64(0))}
+}
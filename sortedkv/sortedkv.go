@@ -0,0 +1,87 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedkv
+
+// entry is a key and value as held by every backend.  A nil key is
+// used when searching and sorts before every other key.
+type entry struct {
+	key   Key
+	value interface{}
+}
+
+func (e *entry) compare(other *entry) int {
+	switch {
+	case e.key == nil && other.key == nil:
+		return 0
+	case e.key == nil:
+		return -1
+	case other.key == nil:
+		return 1
+	}
+
+	return e.key.Compare(other.key)
+}
+
+// iterator adapts a backend's iterator over entries to Iterator,
+// stopping at the first key not less than stop.
+type iterator struct {
+	next    func() (*entry, bool)
+	stop    Key
+	current *entry
+	done    bool
+}
+
+func (iter *iterator) Next() bool {
+	if iter.done {
+		return false
+	}
+
+	e, ok := iter.next()
+	if !ok || (iter.stop != nil && e.key.Compare(iter.stop) >= 0) {
+		iter.current, iter.done = nil, true
+		return false
+	}
+
+	iter.current = e
+	return true
+}
+
+func (iter *iterator) Key() Key {
+	if iter.current == nil {
+		return nil
+	}
+
+	return iter.current.key
+}
+
+func (iter *iterator) Value() interface{} {
+	if iter.current == nil {
+		return nil
+	}
+
+	return iter.current.value
+}
+
+// New returns an empty map stored in the provided backend.
+func New(backend Backend) Map {
+	switch backend {
+	case BPlusTree:
+		return newBPlusTreeMap()
+	default:
+		return newSkipListMap()
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sortedkv
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockKey int
+
+func (mk mockKey) Compare(other Key) int {
+	o := other.(mockKey)
+	switch {
+	case mk < o:
+		return -1
+	case mk > o:
+		return 1
+	}
+
+	return 0
+}
+
+var backends = []Backend{SkipList, BPlusTree}
+
+func exhaust(iter Iterator) []mockKey {
+	keys := []mockKey{}
+	for iter.Next() {
+		keys = append(keys, iter.Key().(mockKey))
+		if iter.Value() != int(iter.Key().(mockKey))*10 {
+			panic(`Value does not match key.`)
+		}
+	}
+
+	return keys
+}
+
+func TestPutGetDelete(t *testing.T) {
+	for _, backend := range backends {
+		m := New(backend)
+
+		old, ok := m.Put(mockKey(1), 10)
+		assert.False(t, ok)
+		assert.Nil(t, old)
+
+		old, ok = m.Put(mockKey(1), 20)
+		assert.True(t, ok)
+		assert.Equal(t, 10, old)
+		assert.Equal(t, uint64(1), m.Len())
+
+		value, ok := m.Get(mockKey(1))
+		assert.True(t, ok)
+		assert.Equal(t, 20, value)
+
+		_, ok = m.Get(mockKey(2))
+		assert.False(t, ok)
+
+		value, ok = m.Delete(mockKey(1))
+		assert.True(t, ok)
+		assert.Equal(t, 20, value)
+		assert.Equal(t, uint64(0), m.Len())
+
+		_, ok = m.Delete(mockKey(1))
+		assert.False(t, ok)
+	}
+}
+
+func TestRangeIter(t *testing.T) {
+	for _, backend := range backends {
+		m := New(backend)
+		assert.Equal(t, []mockKey{}, exhaust(m.RangeIter(nil, nil)))
+
+		for _, i := range rand.Perm(100) {
+			m.Put(mockKey(i*2), i*20)
+		}
+
+		keys := exhaust(m.RangeIter(nil, nil))
+		assert.Len(t, keys, 100)
+		assert.Equal(t, mockKey(0), keys[0])
+		assert.Equal(t, mockKey(198), keys[99])
+
+		assert.Equal(t, []mockKey{10, 12, 14}, exhaust(m.RangeIter(mockKey(9), mockKey(16))))
+		assert.Equal(t, []mockKey{0, 2}, exhaust(m.RangeIter(nil, mockKey(3))))
+		assert.Equal(t, []mockKey{196, 198}, exhaust(m.RangeIter(mockKey(196), nil)))
+		assert.Equal(t, []mockKey{}, exhaust(m.RangeIter(mockKey(200), nil)))
+
+		iter := m.RangeIter(mockKey(5), mockKey(6))
+		assert.Nil(t, iter.Key())
+		assert.False(t, iter.Next())
+		assert.Nil(t, iter.Key())
+		assert.Nil(t, iter.Value())
+	}
+}
+
+func TestFloorCeiling(t *testing.T) {
+	for _, backend := range backends {
+		m := New(backend)
+		_, _, ok := m.Floor(mockKey(1))
+		assert.False(t, ok)
+		_, _, ok = m.Ceiling(mockKey(1))
+		assert.False(t, ok)
+
+		for i := 1; i <= 100; i++ {
+			m.Put(mockKey(i*10), i*100)
+		}
+
+		key, value, ok := m.Floor(mockKey(55))
+		assert.True(t, ok)
+		assert.Equal(t, mockKey(50), key)
+		assert.Equal(t, 500, value)
+
+		key, _, _ = m.Floor(mockKey(60))
+		assert.Equal(t, mockKey(60), key)
+		key, _, _ = m.Floor(mockKey(5000))
+		assert.Equal(t, mockKey(1000), key)
+		_, _, ok = m.Floor(mockKey(9))
+		assert.False(t, ok)
+
+		key, value, ok = m.Ceiling(mockKey(55))
+		assert.True(t, ok)
+		assert.Equal(t, mockKey(60), key)
+		assert.Equal(t, 600, value)
+
+		key, _, _ = m.Ceiling(mockKey(60))
+		assert.Equal(t, mockKey(60), key)
+		key, _, _ = m.Ceiling(mockKey(-5))
+		assert.Equal(t, mockKey(10), key)
+		_, _, ok = m.Ceiling(mockKey(1001))
+		assert.False(t, ok)
+	}
+}
+
+func TestBackendsAgree(t *testing.T) {
+	maps := make([]Map, 0, len(backends))
+	for _, backend := range backends {
+		maps = append(maps, New(backend))
+	}
+
+	for i := 0; i < 2000; i++ {
+		key := mockKey(rand.Intn(500))
+		if rand.Intn(3) == 0 {
+			for _, m := range maps {
+				m.Delete(key)
+			}
+		} else {
+			for _, m := range maps {
+				m.Put(key, int(key)*10)
+			}
+		}
+	}
+
+	expected := exhaust(maps[0].RangeIter(nil, nil))
+	for _, m := range maps[1:] {
+		assert.Equal(t, maps[0].Len(), m.Len())
+		assert.Equal(t, expected, exhaust(m.RangeIter(nil, nil)))
+	}
+}
+
+func benchmarkPut(b *testing.B, backend Backend) {
+	keys := rand.Perm(b.N)
+	m := New(backend)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Put(mockKey(keys[i]), i)
+	}
+}
+
+func BenchmarkSkipListPut(b *testing.B) {
+	benchmarkPut(b, SkipList)
+}
+
+func BenchmarkBPlusTreePut(b *testing.B) {
+	benchmarkPut(b, BPlusTree)
+}
+
+func benchmarkGet(b *testing.B, backend Backend) {
+	numItems := 10000
+	m := New(backend)
+	for i := 0; i < numItems; i++ {
+		m.Put(mockKey(i), i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.Get(mockKey(i % numItems))
+	}
+}
+
+func BenchmarkSkipListGet(b *testing.B) {
+	benchmarkGet(b, SkipList)
+}
+
+func BenchmarkBPlusTreeGet(b *testing.B) {
+	benchmarkGet(b, BPlusTree)
+}
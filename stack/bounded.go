@@ -0,0 +1,221 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// Bounded is a threadsafe stack that holds at most a fixed number of
+// items.  Offer and Poll never block, while Push waits for room and
+// Pop waits for an item until the provided context is done or the
+// stack is disposed.
+type Bounded[T any] struct {
+	lock     sync.Mutex
+	items    []T
+	disposed bool
+	// changed, if not nil, is closed to wake every waiter the next
+	// time an item is added or removed.
+	changed chan struct{}
+}
+
+// signal wakes any goroutines waiting on a change.  It must be called
+// with the lock held.
+func (b *Bounded[T]) signal() {
+	if b.changed != nil {
+		close(b.changed)
+		b.changed = nil
+	}
+}
+
+// wait blocks until ready returns true, which it is called with the
+// lock held to check, and returns with the lock still held.  Returns
+// an error, without the lock, if the stack is disposed or the context
+// is done first.
+func (b *Bounded[T]) wait(ctx context.Context, ready func() bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b.lock.Lock()
+		if b.disposed {
+			b.lock.Unlock()
+			return common.ErrDisposed
+		}
+
+		if ready() {
+			return nil
+		}
+
+		if b.changed == nil {
+			b.changed = make(chan struct{})
+		}
+		changed := b.changed
+		b.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Bounded[T]) full() bool {
+	return len(b.items) == cap(b.items)
+}
+
+func (b *Bounded[T]) empty() bool {
+	return len(b.items) == 0
+}
+
+// push adds the item to the stack.  It must be called with the lock
+// held and room in the stack.
+func (b *Bounded[T]) push(item T) {
+	b.items = append(b.items, item)
+	b.signal()
+}
+
+// pop removes the top item from the stack.  It must be called with
+// the lock held and an item in the stack.
+func (b *Bounded[T]) pop() T {
+	var zero T
+	i := len(b.items) - 1
+	item := b.items[i]
+	b.items[i] = zero
+	b.items = b.items[:i]
+	b.signal()
+	return item
+}
+
+// Offer adds the item to the top of the stack without blocking.
+// Returns false if the stack is full or has been disposed.
+func (b *Bounded[T]) Offer(item T) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed || b.full() {
+		return false
+	}
+
+	b.push(item)
+	return true
+}
+
+// Poll removes and returns the item at the top of the stack without
+// blocking.  Returns false if the stack is empty or has been disposed.
+func (b *Bounded[T]) Poll() (T, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed || b.empty() {
+		var zero T
+		return zero, false
+	}
+
+	return b.pop(), true
+}
+
+// Push adds the item to the top of the stack, waiting for room if it
+// is full.  Returns the context's error if it is done first and an
+// error matching common.ErrDisposed if the stack is disposed.
+func (b *Bounded[T]) Push(ctx context.Context, item T) error {
+	if err := b.wait(ctx, func() bool { return !b.full() }); err != nil {
+		return err
+	}
+
+	b.push(item)
+	b.lock.Unlock()
+	return nil
+}
+
+// Pop removes and returns the item at the top of the stack, waiting
+// for one if it is empty.  Returns the context's error if it is done
+// first and an error matching common.ErrDisposed if the stack is
+// disposed.
+func (b *Bounded[T]) Pop(ctx context.Context) (T, error) {
+	if err := b.wait(ctx, func() bool { return !b.empty() }); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	item := b.pop()
+	b.lock.Unlock()
+	return item, nil
+}
+
+// Peek returns the item at the top of the stack without removing it.
+// Returns false if the stack is empty or has been disposed.
+func (b *Bounded[T]) Peek() (T, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed || b.empty() {
+		var zero T
+		return zero, false
+	}
+
+	return b.items[len(b.items)-1], true
+}
+
+// Len returns the number of items in the stack.
+func (b *Bounded[T]) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.items)
+}
+
+// Cap returns the number of items the stack can hold.
+func (b *Bounded[T]) Cap() int {
+	return cap(b.items)
+}
+
+// Disposed returns a bool indicating if this stack has been disposed.
+func (b *Bounded[T]) Disposed() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.disposed
+}
+
+// Dispose releases the items in the stack and every goroutine waiting
+// in Push or Pop, which return an error matching common.ErrDisposed,
+// and causes subsequent calls to fail.
+func (b *Bounded[T]) Dispose() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.disposed = true
+	clear(b.items)
+	b.items = b.items[:0]
+	b.signal()
+}
+
+// NewBounded returns an empty threadsafe stack that holds at most
+// size items.  The size must be greater than 0.
+func NewBounded[T any](size int) *Bounded[T] {
+	if size <= 0 {
+		panic(`Bounded stack size must be greater than 0.`)
+	}
+
+	return &Bounded[T]{items: make([]T, 0, size)}
+}
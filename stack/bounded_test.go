@@ -0,0 +1,120 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+func TestBoundedOfferPoll(t *testing.T) {
+	b := NewBounded[int](2)
+	assert.Equal(t, 2, b.Cap())
+
+	assert.True(t, b.Offer(1))
+	assert.True(t, b.Offer(2))
+	assert.False(t, b.Offer(3))
+	assert.Equal(t, 2, b.Len())
+
+	item, ok := b.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+
+	item, ok = b.Poll()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	item, ok = b.Poll()
+	assert.True(t, ok)
+	assert.Equal(t, 1, item)
+	_, ok = b.Poll()
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { NewBounded[int](0) })
+}
+
+func TestBoundedPushWaitsForRoom(t *testing.T) {
+	b := NewBounded[int](1)
+	assert.Nil(t, b.Push(context.Background(), 1))
+
+	done := make(chan error)
+	go func() {
+		done <- b.Push(context.Background(), 2)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal(`push should wait while the stack is full`)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	item, err := b.Pop(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, item)
+	assert.Nil(t, <-done)
+
+	item, ok := b.Poll()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+}
+
+func TestBoundedPopWaitsForItem(t *testing.T) {
+	b := NewBounded[int](1)
+
+	done := make(chan int)
+	go func() {
+		item, _ := b.Pop(context.Background())
+		done <- item
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.True(t, b.Offer(5))
+	assert.Equal(t, 5, <-done)
+}
+
+func TestBoundedContext(t *testing.T) {
+	b := NewBounded[int](1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := b.Pop(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBoundedDispose(t *testing.T) {
+	b := NewBounded[int](1)
+
+	done := make(chan error)
+	go func() {
+		_, err := b.Pop(context.Background())
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	b.Dispose()
+	assert.True(t, errors.Is(<-done, common.ErrDisposed))
+	assert.True(t, b.Disposed())
+	assert.False(t, b.Offer(1))
+	assert.True(t, errors.Is(b.Push(context.Background(), 1), common.ErrDisposed))
+	_, ok := b.Peek()
+	assert.False(t, ok)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import "sync/atomic"
+
+type node[T any] struct {
+	item T
+	next *node[T]
+}
+
+// LockFree is a threadsafe stack that never takes a lock.  Its top is
+// replaced with compare-and-swap, so a goroutine whose swap loses a
+// race reads the new top and tries again.  Nodes are never reused, as
+// the garbage collector keeps a node alive while any goroutine still
+// holds it, so the ABA problem of Treiber stacks can't arise.  The
+// zero value is an empty stack ready to use.
+type LockFree[T any] struct {
+	top atomic.Pointer[node[T]]
+	len atomic.Int64
+}
+
+// Push adds the item to the top of the stack.
+func (s *LockFree[T]) Push(item T) {
+	n := &node[T]{item: item}
+	for {
+		n.next = s.top.Load()
+		if s.top.CompareAndSwap(n.next, n) {
+			s.len.Add(1)
+			return
+		}
+	}
+}
+
+// Pop removes and returns the item at the top of the stack.  Returns
+// false if the stack is empty.
+func (s *LockFree[T]) Pop() (T, bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			var zero T
+			return zero, false
+		}
+
+		if s.top.CompareAndSwap(top, top.next) {
+			s.len.Add(-1)
+			return top.item, true
+		}
+	}
+}
+
+// Peek returns the item at the top of the stack without removing it.
+// Returns false if the stack is empty.
+func (s *LockFree[T]) Peek() (T, bool) {
+	top := s.top.Load()
+	if top == nil {
+		var zero T
+		return zero, false
+	}
+
+	return top.item, true
+}
+
+// Len returns the number of items in the stack.  While other
+// goroutines push and pop it is only a snapshot, and it may briefly
+// lag a push or pop that has already changed the top.
+func (s *LockFree[T]) Len() int {
+	return int(max(s.len.Load(), 0))
+}
+
+// NewLockFree returns an empty lock-free stack.
+func NewLockFree[T any]() *LockFree[T] {
+	return &LockFree[T]{}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockFree(t *testing.T) {
+	s := NewLockFree[int]()
+	_, ok := s.Pop()
+	assert.False(t, ok)
+	_, ok = s.Peek()
+	assert.False(t, ok)
+
+	s.Push(1)
+	s.Push(2)
+	assert.Equal(t, 2, s.Len())
+
+	item, ok := s.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	item, _ = s.Pop()
+	assert.Equal(t, 2, item)
+	item, _ = s.Pop()
+	assert.Equal(t, 1, item)
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestLockFreeConcurrent(t *testing.T) {
+	var s LockFree[int]
+	numWorkers, numItems := 8, 1000
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	popped := make([][]int, numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < numItems; i++ {
+				s.Push(w*numItems + i)
+				if i%2 == 1 {
+					item, ok := s.Pop()
+					assert.True(t, ok)
+					popped[w] = append(popped[w], item)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	all := slices.Concat(popped...)
+	for item, ok := s.Pop(); ok; item, ok = s.Pop() {
+		all = append(all, item)
+	}
+
+	// every item pushed is popped exactly once
+	slices.Sort(all)
+	assert.Len(t, all, numWorkers*numItems)
+	for i, item := range all {
+		if item != i {
+			t.Fatalf(`expected %d, got %d`, i, item)
+		}
+	}
+	assert.Equal(t, 0, s.Len())
+}
+
+func BenchmarkLockFree(b *testing.B) {
+	var s LockFree[int]
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Push(1)
+			s.Pop()
+		}
+	})
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import "iter"
+
+// Persistent is an immutable stack.  Each version is a node holding
+// its top item and pointing at the version below it, so versions
+// share every item they have in common.  Persistent stacks are safe
+// to share between goroutines.
+type Persistent[T any] struct {
+	item T
+	// next is the stack below this one, nil for the empty stack.
+	next *Persistent[T]
+	len  int
+}
+
+// Push returns a stack with the item on top of this one.
+func (s *Persistent[T]) Push(item T) *Persistent[T] {
+	return &Persistent[T]{item: item, next: s, len: s.len + 1}
+}
+
+// Pop returns the stack below this one's top item.  Popping the empty
+// stack returns it unchanged.
+func (s *Persistent[T]) Pop() *Persistent[T] {
+	if s.len == 0 {
+		return s
+	}
+
+	return s.next
+}
+
+// Peek returns the item at the top of the stack.  Returns false if
+// the stack is empty.
+func (s *Persistent[T]) Peek() (T, bool) {
+	return s.item, s.len > 0
+}
+
+// Len returns the number of items in the stack.
+func (s *Persistent[T]) Len() int {
+	return s.len
+}
+
+// All returns a sequence of the items from top to bottom.
+func (s *Persistent[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for n := s; n.len > 0; n = n.next {
+			if !yield(n.item) {
+				return
+			}
+		}
+	}
+}
+
+// NewPersistent returns an empty persistent stack.
+func NewPersistent[T any]() *Persistent[T] {
+	return &Persistent[T]{}
+}
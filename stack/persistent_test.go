@@ -0,0 +1,60 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistent(t *testing.T) {
+	empty := NewPersistent[int]()
+	assert.Equal(t, 0, empty.Len())
+	_, ok := empty.Peek()
+	assert.False(t, ok)
+	assert.Equal(t, empty, empty.Pop())
+
+	one := empty.Push(1)
+	two := one.Push(2)
+	other := one.Push(3)
+
+	assert.Equal(t, []int{2, 1}, slices.Collect(two.All()))
+	assert.Equal(t, []int{3, 1}, slices.Collect(other.All()))
+	assert.Equal(t, []int{1}, slices.Collect(one.All()))
+	assert.Len(t, slices.Collect(empty.All()), 0)
+
+	item, ok := two.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 2, item)
+	assert.Equal(t, 2, two.Len())
+
+	// versions share the items below their tops
+	assert.True(t, two.Pop() == one)
+	assert.True(t, other.Pop() == one)
+	assert.Equal(t, 0, two.Pop().Pop().Len())
+}
+
+func TestPersistentZeroValue(t *testing.T) {
+	var s Persistent[string]
+	top := s.Push(`a`)
+	item, ok := top.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, `a`, item)
+	assert.Equal(t, 0, top.Pop().Len())
+}
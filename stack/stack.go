@@ -0,0 +1,119 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package stack implements last-in, first-out stacks in four variants.
+
+Stack keeps its items in a slice and is the one to use from a single
+goroutine: a push or pop is a slice append or truncation.  It is not
+threadsafe.
+
+Bounded wraps a stack of fixed capacity with a lock and adds pushes
+that wait for room and pops that wait for items, for use between
+producers and consumers.
+
+LockFree is a Treiber stack, a linked list whose top is swapped with
+compare-and-swap, so any number of goroutines can push and pop without
+a lock.  Every push allocates a node.
+
+Persistent is immutable: pushing or popping returns a new stack that
+shares every item below its top with the old one, so old versions stay
+valid and can be kept or handed to other goroutines at no cost.
+
+Performance characteristics:
+Stack Push/Pop: O(1) amortized
+Bounded Push/Pop: O(1)
+LockFree Push/Pop: O(1), retried under contention
+Persistent Push/Pop: O(1)
+Space: O(n)
+*/
+package stack
+
+import "iter"
+
+// Stack is a stack backed by a slice.  The zero value is an empty
+// stack ready to use.
+type Stack[T any] struct {
+	items []T
+}
+
+// Push adds the item to the top of the stack.
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+// Pop removes and returns the item at the top of the stack.  Returns
+// false if the stack is empty.
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+
+	i := len(s.items) - 1
+	item := s.items[i]
+	// clear the slot so the item can be garbage collected
+	s.items[i] = zero
+	s.items = s.items[:i]
+	if cap(s.items) > minCapacity && len(s.items) <= cap(s.items)/4 {
+		s.items = append(make([]T, 0, cap(s.items)/2), s.items...)
+	}
+
+	return item, true
+}
+
+// Peek returns the item at the top of the stack without removing it.
+// Returns false if the stack is empty.
+func (s *Stack[T]) Peek() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return s.items[len(s.items)-1], true
+}
+
+// Len returns the number of items in the stack.
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
+// Clear removes every item from the stack and releases its storage.
+func (s *Stack[T]) Clear() {
+	s.items = nil
+}
+
+// All returns a sequence of the items from top to bottom.  The stack
+// must not be modified during iteration.
+func (s *Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.items) - 1; i >= 0; i-- {
+			if !yield(s.items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// minCapacity is the capacity below which a stack doesn't shrink its
+// slice as it empties.
+const minCapacity = 16
+
+// New returns an empty stack with room for hint items before it
+// grows.
+func New[T any](hint int) *Stack[T] {
+	return &Stack[T]{items: make([]T, 0, max(hint, 0))}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stack
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmpty(t *testing.T) {
+	var s Stack[int]
+	assert.Equal(t, 0, s.Len())
+
+	_, ok := s.Pop()
+	assert.False(t, ok)
+	_, ok = s.Peek()
+	assert.False(t, ok)
+}
+
+func TestPushPop(t *testing.T) {
+	s := New[int](0)
+	for i := 0; i < 100; i++ {
+		s.Push(i)
+	}
+
+	assert.Equal(t, 100, s.Len())
+	item, ok := s.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 99, item)
+
+	for i := 99; i >= 0; i-- {
+		item, ok := s.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestShrink(t *testing.T) {
+	s := New[int](0)
+	for i := 0; i < 1000; i++ {
+		s.Push(i)
+	}
+	for i := 0; i < 990; i++ {
+		s.Pop()
+	}
+
+	assert.True(t, cap(s.items) < 100)
+	assert.Equal(t, []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}, slices.Collect(s.All()))
+}
+
+func TestClear(t *testing.T) {
+	s := New[string](4)
+	s.Push(`a`)
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+
+	s.Push(`b`)
+	item, _ := s.Pop()
+	assert.Equal(t, `b`, item)
+}
+
+func TestAllStopsEarly(t *testing.T) {
+	var s Stack[int]
+	for i := 0; i < 5; i++ {
+		s.Push(i)
+	}
+
+	var items []int
+	for item := range s.All() {
+		if item < 3 {
+			break
+		}
+		items = append(items, item)
+	}
+	assert.Equal(t, []int{4, 3}, items)
+}
+
+func BenchmarkStack(b *testing.B) {
+	s := New[int](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Push(i)
+		s.Pop()
+	}
+}
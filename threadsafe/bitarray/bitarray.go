@@ -0,0 +1,368 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package bitarray wraps the bit arrays of the bitarray package with a
+read/write lock so a single bit array can be shared by many goroutines.
+Reads such as GetBit take a read lock and may proceed in parallel while
+writes are serialized.
+
+The wrapper implements bitarray.BitArray so it can be passed anywhere a
+bit array is expected, including as the other operand of Or, And,
+AndNot, Equals and Intersects on another wrapped bit array.
+*/
+package bitarray
+
+import (
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+)
+
+// BitArray is a bitarray.BitArray that is safe for concurrent use.
+type BitArray struct {
+	lock sync.RWMutex
+	ba   bitarray.BitArray
+}
+
+// snapshot returns a private point-in-time copy of the wrapped bit
+// array that can be read without holding the lock.
+func (ba *BitArray) snapshot() bitarray.BitArray {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	return ba.ba.Snapshot()
+}
+
+// unwrap returns a bit array the wrapped implementation can operate
+// on.  Wrapped bit arrays are snapshotted before this bit array is
+// locked so two bit arrays operating on each other can't deadlock.
+func unwrap(other bitarray.BitArray) bitarray.BitArray {
+	if tba, ok := other.(*BitArray); ok {
+		return tba.snapshot()
+	}
+
+	return other
+}
+
+// SetBit sets the bit at the given position.
+func (ba *BitArray) SetBit(k uint64) error {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	return ba.ba.SetBit(k)
+}
+
+// GetBit gets the bit at the given position.
+func (ba *BitArray) GetBit(k uint64) (bool, error) {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.GetBit(k)
+}
+
+// ClearBit clears the bit at the given position.
+func (ba *BitArray) ClearBit(k uint64) error {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	return ba.ba.ClearBit(k)
+}
+
+// Reset sets all values to zero.
+func (ba *BitArray) Reset() {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.Reset()
+}
+
+// Blocks returns an iterator over a snapshot of this bit array so it
+// is unaffected by later writes.
+func (ba *BitArray) Blocks() bitarray.Iterator {
+	return ba.snapshot().Blocks()
+}
+
+// Equals returns a bool indicating equality between the two bit
+// arrays.
+func (ba *BitArray) Equals(other bitarray.BitArray) bool {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Equals(other)
+}
+
+// Hash returns a hash of the set bits that is the same for any equal
+// bit array.
+func (ba *BitArray) Hash() uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Hash()
+}
+
+// Intersects returns a bool indicating if the other bit array
+// intersects with this bit array.
+func (ba *BitArray) Intersects(other bitarray.BitArray) bool {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Intersects(other)
+}
+
+// Capacity returns the capacity of the wrapped bit array.
+func (ba *BitArray) Capacity() uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Capacity()
+}
+
+// Or will bitwise or the two bit arrays and return a new threadsafe
+// bit array representing the result.
+func (ba *BitArray) Or(other bitarray.BitArray) bitarray.BitArray {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return New(ba.ba.Or(other))
+}
+
+// And will bitwise and the two bit arrays and return a new threadsafe
+// bit array representing the result.
+func (ba *BitArray) And(other bitarray.BitArray) bitarray.BitArray {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return New(ba.ba.And(other))
+}
+
+// AndNot will clear every bit in this bit array that is set in the
+// other and return a new threadsafe bit array representing the
+// result.
+func (ba *BitArray) AndNot(other bitarray.BitArray) bitarray.BitArray {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return New(ba.ba.AndNot(other))
+}
+
+// OrInPlace will bitwise or the other bit array into this one.
+func (ba *BitArray) OrInPlace(other bitarray.BitArray) {
+	other = unwrap(other)
+
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.OrInPlace(other)
+}
+
+// AndInPlace will bitwise and the other bit array into this one.
+func (ba *BitArray) AndInPlace(other bitarray.BitArray) {
+	other = unwrap(other)
+
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.AndInPlace(other)
+}
+
+// AndNotInPlace will clear every bit in this bit array that is set in
+// the other.
+func (ba *BitArray) AndNotInPlace(other bitarray.BitArray) {
+	other = unwrap(other)
+
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.AndNotInPlace(other)
+}
+
+// XorInPlace will bitwise xor the other bit array into this one.
+func (ba *BitArray) XorInPlace(other bitarray.BitArray) {
+	other = unwrap(other)
+
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.XorInPlace(other)
+}
+
+// SetRange sets every bit in [start, stop).
+func (ba *BitArray) SetRange(start, stop uint64) error {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	return ba.ba.SetRange(start, stop)
+}
+
+// ClearRange clears every bit in [start, stop).
+func (ba *BitArray) ClearRange(start, stop uint64) error {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	return ba.ba.ClearRange(start, stop)
+}
+
+// ShiftLeft moves every bit n positions higher.
+func (ba *BitArray) ShiftLeft(n uint64) {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.ShiftLeft(n)
+}
+
+// ShiftRight moves every bit n positions lower.
+func (ba *BitArray) ShiftRight(n uint64) {
+	ba.lock.Lock()
+	defer ba.lock.Unlock()
+
+	ba.ba.ShiftRight(n)
+}
+
+// Not returns a new bitarray with every bit in [0, max) that is clear
+// in this one set.
+func (ba *BitArray) Not(max uint64) bitarray.BitArray {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return New(ba.ba.Not(max))
+}
+
+// Xor will bitwise xor the two bit arrays and return a new threadsafe
+// bit array representing the result.
+func (ba *BitArray) Xor(other bitarray.BitArray) bitarray.BitArray {
+	other = unwrap(other)
+
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return New(ba.ba.Xor(other))
+}
+
+// Count returns the number of set bits.
+func (ba *BitArray) Count() uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Count()
+}
+
+// ToNums converts this bit array to the list of numbers contained
+// within it.
+func (ba *BitArray) ToNums() []uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.ToNums()
+}
+
+// Snapshot returns a point-in-time copy of this bit array, itself
+// wrapped so it is safe for concurrent use.
+func (ba *BitArray) Snapshot() bitarray.BitArray {
+	return New(ba.snapshot())
+}
+
+// Validate checks the internal bookkeeping of the wrapped bit array.
+func (ba *BitArray) Validate() error {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Validate()
+}
+
+// SizeOf returns an estimate of the number of bytes used by this bit
+// array.
+func (ba *BitArray) SizeOf() uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.SizeOf()
+}
+
+// Rank returns the number of set bits at or before the given
+// position.
+func (ba *BitArray) Rank(k uint64) uint64 {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Rank(k)
+}
+
+// Select returns the position of the n-th set bit, counting from one.
+func (ba *BitArray) Select(n uint64) (uint64, error) {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Select(n)
+}
+
+// NextSet returns the first set bit at or after the given position.
+func (ba *BitArray) NextSet(k uint64) (uint64, bool) {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.NextSet(k)
+}
+
+// PrevSet returns the last set bit at or before the given position.
+func (ba *BitArray) PrevSet(k uint64) (uint64, bool) {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.PrevSet(k)
+}
+
+// Iterate calls fn with every set bit of a snapshot of this bit array
+// in ascending order until fn returns false.  The lock is not held
+// while fn runs, so fn may use this bit array.
+func (ba *BitArray) Iterate(fn func(uint64) bool) {
+	ba.snapshot().Iterate(fn)
+}
+
+// All returns a sequence of every set bit of a snapshot of this bit
+// array in ascending order for use with range.  The snapshot is taken
+// when ranging begins and the lock is not held while ranging.
+func (ba *BitArray) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		ba.Iterate(yield)
+	}
+}
+
+// Serialize writes the wrapped bit array to the provided writer.
+func (ba *BitArray) Serialize(w io.Writer) error {
+	ba.lock.RLock()
+	defer ba.lock.RUnlock()
+
+	return ba.ba.Serialize(w)
+}
+
+// New wraps the provided bit array.  The bit array must not be used
+// directly after it has been wrapped.
+func New(ba bitarray.BitArray) *BitArray {
+	return &BitArray{ba: ba}
+}
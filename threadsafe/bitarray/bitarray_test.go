@@ -0,0 +1,191 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bitarray
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/bitarray"
+)
+
+func TestImplementsBitArray(t *testing.T) {
+	var ba bitarray.BitArray = New(bitarray.NewSparseBitArray())
+	assert.Nil(t, ba.SetBit(5))
+
+	ok, err := ba.GetBit(5)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	assert.Nil(t, ba.ClearBit(5))
+	ok, _ = ba.GetBit(5)
+	assert.False(t, ok)
+}
+
+func TestOperationsOnWrapped(t *testing.T) {
+	ba1 := New(bitarray.NewBitArray(100))
+	ba2 := New(bitarray.NewBitArray(100))
+	ba1.SetBit(1)
+	ba1.SetBit(2)
+	ba2.SetBit(2)
+	ba2.SetBit(3)
+
+	assert.Equal(t, []uint64{1, 2, 3}, ba1.Or(ba2).ToNums())
+	assert.Equal(t, []uint64{2}, ba1.And(ba2).ToNums())
+	assert.Equal(t, []uint64{1}, ba1.AndNot(ba2).ToNums())
+	assert.Equal(t, []uint64{1, 3}, ba1.Xor(ba2).ToNums())
+	assert.Equal(t, uint64(2), ba1.Count())
+
+	var buf bytes.Buffer
+	assert.Nil(t, ba1.Serialize(&buf))
+	decoded, err := bitarray.FromBytes(buf.Bytes())
+	assert.Nil(t, err)
+	assert.True(t, ba1.Equals(decoded))
+
+	next, ok := ba1.NextSet(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), next)
+	prev, ok := ba1.PrevSet(0)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), prev)
+	var nums []uint64
+	ba1.Iterate(func(k uint64) bool {
+		// the lock is not held, so the wrapper may be used here
+		nums = append(nums, ba1.Rank(k))
+		return true
+	})
+	assert.Equal(t, []uint64{1, 2}, nums)
+	nums = nums[:0]
+	for k := range ba1.All() {
+		nums = append(nums, k)
+	}
+	assert.Equal(t, ba1.ToNums(), nums)
+	assert.False(t, ba1.Intersects(ba2))
+	subset := New(bitarray.NewBitArray(100))
+	subset.SetBit(2)
+	assert.True(t, ba1.Intersects(subset))
+	assert.False(t, ba1.Equals(ba2))
+	assert.NotEqual(t, ba1.Hash(), ba2.Hash())
+	assert.Equal(t, ba1.Hash(), ba1.Snapshot().Hash())
+
+	snapshot := ba1.Snapshot()
+	ba1.Reset()
+	assert.Equal(t, []uint64{1, 2}, snapshot.ToNums())
+	assert.Len(t, ba1.ToNums(), 0)
+	assert.Nil(t, snapshot.Validate())
+	assert.Equal(t, ba2.Capacity(), snapshot.Capacity())
+
+	count := 0
+	for iter := snapshot.Blocks(); iter.Next(); {
+		count++
+	}
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, uint64(2), ba2.Rank(99))
+	pos, err := ba2.Select(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), pos)
+}
+
+func TestInPlaceOnWrapped(t *testing.T) {
+	ba1 := New(bitarray.NewBitArray(100))
+	ba2 := New(bitarray.NewBitArray(100))
+	ba1.SetBit(1)
+	ba1.SetBit(2)
+	ba2.SetBit(2)
+	ba2.SetBit(3)
+
+	// operating on itself must not deadlock
+	ba1.OrInPlace(ba1)
+	ba1.XorInPlace(ba2)
+	assert.Equal(t, []uint64{1, 3}, ba1.ToNums())
+	ba1.AndNotInPlace(ba2)
+	ba1.AndInPlace(ba1)
+	assert.Equal(t, []uint64{1}, ba1.ToNums())
+
+	assert.Nil(t, ba1.SetRange(10, 20))
+	assert.Nil(t, ba1.ClearRange(11, 20))
+	ba1.ShiftLeft(2)
+	ba1.ShiftRight(1)
+	assert.Equal(t, []uint64{2, 11}, ba1.ToNums())
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ba := New(bitarray.NewSparseBitArray())
+	other := New(bitarray.NewSparseBitArray())
+	other.SetBit(7)
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	for i := 0; i < 4; i++ {
+		go func(offset uint64) {
+			defer wg.Done()
+			for j := uint64(0); j < 500; j++ {
+				ba.SetBit(j*4 + offset)
+			}
+		}(uint64(i))
+		go func() {
+			defer wg.Done()
+			for j := uint64(0); j < 500; j++ {
+				ba.GetBit(j)
+				ba.Or(other)
+				other.And(ba)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, ba.ToNums(), 2000)
+	assert.Nil(t, ba.Validate())
+}
+
+func BenchmarkParallelGetBit(b *testing.B) {
+	ba := New(bitarray.NewBitArray(1024))
+	for i := uint64(0); i < 1024; i += 3 {
+		ba.SetBit(i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := uint64(0); pb.Next(); i++ {
+			ba.GetBit(i % 1024)
+		}
+	})
+}
+
+func BenchmarkParallelSetBit(b *testing.B) {
+	ba := New(bitarray.NewSparseBitArray())
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := uint64(0); pb.Next(); i++ {
+			ba.SetBit(i % 100000)
+		}
+	})
+}
+
+func TestNotOnWrapped(t *testing.T) {
+	ba := New(bitarray.NewSparseBitArray())
+	ba.SetBit(1)
+
+	result := ba.Not(4)
+	assert.IsType(t, &BitArray{}, result)
+	assert.Equal(t, []uint64{0, 2, 3}, result.ToNums())
+}
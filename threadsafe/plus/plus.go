@@ -0,0 +1,125 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package plus wraps a plus.BTree with a read/write lock so a single tree
+can be shared by many goroutines.  Lookups take a read lock and may
+proceed in parallel while modifications are serialized.
+
+Iterators over the wrapped tree would be invalidated by concurrent writes
+so none are exposed.  Each and Range instead call a function under the
+read lock.
+*/
+package plus
+
+import (
+	"sync"
+
+	"github.com/Workiva/go-datastructures/btree/plus"
+)
+
+// BTree is a plus.BTree that is safe for concurrent use.
+type BTree struct {
+	lock sync.RWMutex
+	tree *plus.BTree
+}
+
+// Insert will insert the provided keys into the tree.
+func (tree *BTree) Insert(keys ...plus.Key) {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+
+	tree.tree.Insert(keys...)
+}
+
+// Delete will remove the provided keys from the tree and return the
+// keys that were deleted.
+func (tree *BTree) Delete(keys ...plus.Key) plus.Keys {
+	tree.lock.Lock()
+	defer tree.lock.Unlock()
+
+	return tree.tree.Delete(keys...)
+}
+
+// Get will retrieve the keys in the tree equal to the provided keys.
+func (tree *BTree) Get(keys ...plus.Key) plus.Keys {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	return tree.tree.Get(keys...)
+}
+
+// Floor returns the greatest key in the tree equal to or less than
+// the provided key.
+func (tree *BTree) Floor(key plus.Key) plus.Key {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	return tree.tree.Floor(key)
+}
+
+// Rank returns the number of keys in the tree less than the provided
+// key.
+func (tree *BTree) Rank(key plus.Key) uint64 {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	return tree.tree.Rank(key)
+}
+
+// ByPosition returns the key at the provided position in key order,
+// or nil if the position is out of bounds.
+func (tree *BTree) ByPosition(position uint64) plus.Key {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	return tree.tree.ByPosition(position)
+}
+
+// Len returns the number of items in this tree.
+func (tree *BTree) Len() uint64 {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	return tree.tree.Len()
+}
+
+// Each will call the provided function with every key in the tree
+// in order until the function returns false.  The read lock is held
+// throughout so the function must not modify this tree.
+func (tree *BTree) Each(fn func(plus.Key) bool) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	tree.tree.Each(fn)
+}
+
+// Range will call the provided function with every key equal to or
+// greater than start and less than stop in order until the function
+// returns false.  The read lock is held throughout so the function
+// must not modify this tree.
+func (tree *BTree) Range(start, stop plus.Key, fn func(plus.Key) bool) {
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+
+	tree.tree.Range(start, stop, fn)
+}
+
+// New wraps the provided tree.  The tree must not be used directly
+// after it has been wrapped.
+func New(tree *plus.BTree) *BTree {
+	return &BTree{tree: tree}
+}
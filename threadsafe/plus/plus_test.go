@@ -0,0 +1,126 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/btree/plus"
+)
+
+type mockKey int
+
+// Compare follows the plus.BTree convention of returning 1 when the
+// provided key is the greater of the two.
+func (mk mockKey) Compare(other plus.Key) int {
+	key := other.(mockKey)
+	if key == mk {
+		return 0
+	}
+	if key > mk {
+		return 1
+	}
+
+	return -1
+}
+
+func TestBTree(t *testing.T) {
+	tree := New(plus.New(8))
+	tree.Insert(mockKey(1), mockKey(5), mockKey(3))
+	assert.Equal(t, uint64(3), tree.Len())
+	assert.Equal(t, plus.Keys{mockKey(3), nil}, tree.Get(mockKey(3), mockKey(4)))
+	assert.Equal(t, mockKey(3), tree.Floor(mockKey(4)))
+	assert.Equal(t, uint64(2), tree.Rank(mockKey(4)))
+	assert.Equal(t, mockKey(3), tree.ByPosition(1))
+
+	var ranged plus.Keys
+	tree.Range(mockKey(2), mockKey(5), func(k plus.Key) bool {
+		ranged = append(ranged, k)
+		return true
+	})
+	assert.Equal(t, plus.Keys{mockKey(3)}, ranged)
+
+	assert.Equal(t, plus.Keys{mockKey(3)}, tree.Delete(mockKey(3)))
+	assert.Equal(t, uint64(2), tree.Len())
+
+	var all plus.Keys
+	tree.Each(func(k plus.Key) bool {
+		all = append(all, k)
+		return true
+	})
+	assert.Equal(t, plus.Keys{mockKey(1), mockKey(5)}, all)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	tree := New(plus.New(16))
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	for i := 0; i < 4; i++ {
+		go func(offset int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				tree.Insert(mockKey(j*4 + offset))
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				tree.Get(mockKey(j))
+				tree.Floor(mockKey(j))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(2000), tree.Len())
+	previous := -1
+	tree.Each(func(k plus.Key) bool {
+		assert.Equal(t, previous+1, int(k.(mockKey)))
+		previous++
+		return true
+	})
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	numItems := 10000
+	tree := New(plus.New(64))
+	for i := 0; i < numItems; i++ {
+		tree.Insert(mockKey(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			tree.Get(mockKey(i % numItems))
+		}
+	})
+}
+
+func BenchmarkParallelInsert(b *testing.B) {
+	tree := New(plus.New(64))
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			tree.Insert(mockKey(i))
+		}
+	})
+}
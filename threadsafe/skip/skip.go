@@ -0,0 +1,210 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package skip wraps a skip.SkipList with a read/write lock so a single
+list can be shared by many goroutines.  Lookups take a read lock and may
+proceed in parallel while modifications are serialized.  For a list that
+scales better under heavy concurrent writes see slice/skip/concurrent.
+
+Iterators over the wrapped list would be invalidated by concurrent writes
+so none are exposed.  Each and Range instead call a function under the
+read lock, and Snapshot returns a private copy that can be iterated at
+leisure.
+*/
+package skip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// SkipList is a skip.SkipList that is safe for concurrent use.
+type SkipList struct {
+	lock sync.RWMutex
+	sl   *skip.SkipList
+}
+
+// readLock takes the lock for a read that may purge expired entries
+// and returns the function that releases it.  That is the write lock
+// if expiry is enabled on the wrapped list and the read lock
+// otherwise.
+func (sl *SkipList) readLock() func() {
+	if sl.sl.ExpiryEnabled() {
+		sl.lock.Lock()
+		return sl.lock.Unlock
+	}
+
+	sl.lock.RLock()
+	return sl.lock.RUnlock
+}
+
+// Insert will insert the provided entries into the list.  Returned is
+// a list of entries that were overwritten.
+func (sl *SkipList) Insert(entries ...skip.Entry) skip.Entries {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.Insert(entries...)
+}
+
+// Delete will remove the provided keys from the list and return a
+// list of the entries that were deleted.
+func (sl *SkipList) Delete(entries ...skip.Entry) skip.Entries {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.Delete(entries...)
+}
+
+// DeleteAtPosition removes and returns the entry at the provided
+// position.
+func (sl *SkipList) DeleteAtPosition(position uint64) skip.Entry {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.DeleteAtPosition(position)
+}
+
+// DeleteAtPositionE is DeleteAtPosition except that it returns a
+// skip.OutOfRangeError if the position does not exist.
+func (sl *SkipList) DeleteAtPositionE(position uint64) (skip.Entry, error) {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.DeleteAtPositionE(position)
+}
+
+// ExpireBefore deletes every expiring entry whose expiry is at or
+// before t and returns the deleted entries in order.
+func (sl *SkipList) ExpireBefore(t time.Time) skip.Entries {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.ExpireBefore(t)
+}
+
+// Get will retrieve values associated with the keys provided.
+func (sl *SkipList) Get(entries ...skip.Entry) skip.Entries {
+	defer sl.readLock()()
+
+	return sl.sl.Get(entries...)
+}
+
+// GetOne will retrieve the value associated with the provided key or
+// nil if it could not be found.
+func (sl *SkipList) GetOne(e skip.Entry) skip.Entry {
+	defer sl.readLock()()
+
+	return sl.sl.GetOne(e)
+}
+
+// Contains returns a bool indicating if a value associated with the
+// provided key exists in this list.
+func (sl *SkipList) Contains(e skip.Entry) bool {
+	defer sl.readLock()()
+
+	return sl.sl.Contains(e)
+}
+
+// GetWithPosition will retrieve the value with the provided key and
+// return the position of that value within the list.
+func (sl *SkipList) GetWithPosition(e skip.Entry) (skip.Entry, uint64) {
+	defer sl.readLock()()
+
+	return sl.sl.GetWithPosition(e)
+}
+
+// ByPosition returns the entry at the given position.
+func (sl *SkipList) ByPosition(position uint64) skip.Entry {
+	sl.lock.RLock()
+	defer sl.lock.RUnlock()
+
+	return sl.sl.ByPosition(position)
+}
+
+// ByPositionE is ByPosition except that it returns a
+// skip.OutOfRangeError if the position does not exist.
+func (sl *SkipList) ByPositionE(position uint64) (skip.Entry, error) {
+	sl.lock.RLock()
+	defer sl.lock.RUnlock()
+
+	return sl.sl.ByPositionE(position)
+}
+
+// Floor returns the greatest entry equal to or less than the provided
+// entry.
+func (sl *SkipList) Floor(e skip.Entry) skip.Entry {
+	defer sl.readLock()()
+
+	return sl.sl.Floor(e)
+}
+
+// Ceiling returns the least entry equal to or greater than the
+// provided entry.
+func (sl *SkipList) Ceiling(e skip.Entry) skip.Entry {
+	defer sl.readLock()()
+
+	return sl.sl.Ceiling(e)
+}
+
+// Len returns the number of items in this list.
+func (sl *SkipList) Len() uint64 {
+	sl.lock.RLock()
+	defer sl.lock.RUnlock()
+
+	return sl.sl.Len()
+}
+
+// Each will call the provided function with every entry in the list
+// in order until the function returns false.  The read lock is held
+// throughout so the function must not modify this list.
+func (sl *SkipList) Each(fn func(skip.Entry) bool) {
+	sl.lock.RLock()
+	defer sl.lock.RUnlock()
+
+	sl.sl.Each(fn)
+}
+
+// Range will call the provided function with every entry equal to or
+// greater than start and less than stop in order until the function
+// returns false.  The read lock is held throughout so the function
+// must not modify this list.
+func (sl *SkipList) Range(start, stop skip.Entry, fn func(skip.Entry) bool) {
+	sl.lock.RLock()
+	defer sl.lock.RUnlock()
+
+	sl.sl.Range(start, stop, fn)
+}
+
+// Snapshot returns a point-in-time copy of this list.  The copy is
+// not shared with any other goroutine so it may be iterated or
+// modified without locking.
+func (sl *SkipList) Snapshot() *skip.SkipList {
+	sl.lock.Lock()
+	defer sl.lock.Unlock()
+
+	return sl.sl.Snapshot()
+}
+
+// New wraps the provided list.  The list must not be used directly
+// after it has been wrapped.  If expiry is enabled on the list, reads
+// that may purge expired entries take the write lock instead.
+func New(sl *skip.SkipList) *SkipList {
+	return &SkipList{sl: sl}
+}
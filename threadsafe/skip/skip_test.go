@@ -0,0 +1,185 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+type mockEntry uint64
+
+func (me mockEntry) Compare(other skip.Entry) int {
+	otherU := other.(mockEntry)
+	if me == otherU {
+		return 0
+	}
+
+	if me > otherU {
+		return 1
+	}
+
+	return -1
+}
+
+func TestSkipList(t *testing.T) {
+	sl := New(skip.New(uint8(0)))
+	assert.Equal(t, skip.Entries{nil, nil, nil}, sl.Insert(mockEntry(1), mockEntry(5), mockEntry(3)))
+	assert.Equal(t, uint64(3), sl.Len())
+	assert.Equal(t, skip.Entries{mockEntry(3), nil}, sl.Get(mockEntry(3), mockEntry(4)))
+
+	assert.Equal(t, mockEntry(3), sl.GetOne(mockEntry(3)))
+	assert.True(t, sl.Contains(mockEntry(1)))
+	assert.False(t, sl.Contains(mockEntry(4)))
+
+	e, pos := sl.GetWithPosition(mockEntry(5))
+	assert.Equal(t, mockEntry(5), e)
+	assert.Equal(t, uint64(2), pos)
+	assert.Equal(t, mockEntry(3), sl.ByPosition(1))
+	assert.Equal(t, mockEntry(3), sl.Floor(mockEntry(4)))
+	assert.Equal(t, mockEntry(5), sl.Ceiling(mockEntry(4)))
+
+	var ranged skip.Entries
+	sl.Range(mockEntry(2), mockEntry(5), func(e skip.Entry) bool {
+		ranged = append(ranged, e)
+		return true
+	})
+	assert.Equal(t, skip.Entries{mockEntry(3)}, ranged)
+
+	snapshot := sl.Snapshot()
+	assert.Equal(t, skip.Entries{mockEntry(3)}, sl.Delete(mockEntry(3)))
+	assert.Equal(t, mockEntry(1), sl.DeleteAtPosition(0))
+	assert.Equal(t, uint64(1), sl.Len())
+	assert.Equal(t, uint64(3), snapshot.Len())
+
+	count := 0
+	sl.Each(func(skip.Entry) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 1, count)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	sl := New(skip.New(uint64(0)))
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	for i := 0; i < 4; i++ {
+		go func(offset uint64) {
+			defer wg.Done()
+			for j := uint64(0); j < 500; j++ {
+				sl.Insert(mockEntry(j*4 + offset))
+			}
+		}(uint64(i))
+		go func() {
+			defer wg.Done()
+			for j := uint64(0); j < 500; j++ {
+				sl.Get(mockEntry(j))
+				sl.Floor(mockEntry(j))
+				sl.Snapshot().Len()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(2000), sl.Len())
+	previous := -1
+	sl.Each(func(e skip.Entry) bool {
+		assert.Equal(t, previous+1, int(e.(mockEntry)))
+		previous++
+		return true
+	})
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	numItems := 10000
+	sl := New(skip.New(uint64(0)))
+	for i := 0; i < numItems; i++ {
+		sl.Insert(mockEntry(i))
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			sl.Get(mockEntry(i % numItems))
+		}
+	})
+}
+
+func BenchmarkParallelInsert(b *testing.B) {
+	sl := New(skip.New(uint64(0)))
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			sl.Insert(mockEntry(i))
+		}
+	})
+}
+
+type expiringEntry struct {
+	mockEntry
+	expiry time.Time
+}
+
+func (ee expiringEntry) Compare(other skip.Entry) int {
+	return ee.mockEntry.Compare(other.(expiringEntry).mockEntry)
+}
+
+func (ee expiringEntry) Expiry() time.Time {
+	return ee.expiry
+}
+
+func TestConcurrentExpiry(t *testing.T) {
+	list := skip.New(uint64(0))
+	list.EnableExpiry(nil)
+	sl := New(list)
+
+	past := time.Now().Add(-time.Hour)
+	for i := uint64(0); i < 1000; i++ {
+		e := expiringEntry{mockEntry: mockEntry(i)}
+		if i%2 == 0 {
+			e.expiry = past
+		}
+		sl.Insert(e)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer wg.Done()
+			for j := uint64(0); j < 1000; j++ {
+				e := expiringEntry{mockEntry: mockEntry(j)}
+				sl.Get(e)
+				sl.Floor(e)
+				sl.Ceiling(e)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(500), sl.Len())
+	assert.Len(t, sl.ExpireBefore(time.Now()), 0)
+	assert.Nil(t, sl.Snapshot().Validate())
+}
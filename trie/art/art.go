@@ -0,0 +1,266 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package art implements an adaptive radix tree, a compressed trie over
+variable length byte string keys.  Runs of bytes without branches are
+collapsed into a single edge and each inner node picks the smallest
+of four layouts that fits its children, holding up to 4, 16, 48 or 256
+of them.  Nodes grow and shrink between layouts as children are added
+and removed, which keeps sparse nodes small while dense nodes are
+indexed directly by the next byte of the key.
+
+Keys are visited in lexicographic byte order, with a key visited before
+any key it is a prefix of, which makes the tree useful for prefix
+queries such as those against routing tables or namespaced keys.  Keys
+are copied on insert.  This tree is not threadsafe.
+
+Performance characteristics:
+Insert: O(k) where k is the length of the key
+Get: O(k)
+Delete: O(k)
+WalkPrefix: O(k + m) where m is the number of keys visited
+DeletePrefix: O(k + m) where m is the number of keys removed
+Space: O(n*k) in the worst case but usually far less as common
+prefixes are shared.
+*/
+package art
+
+import "bytes"
+
+// Tree is an adaptive radix tree mapping byte string keys to values.
+type Tree struct {
+	root   *node
+	number uint64
+}
+
+// Insert adds the key to the tree with the provided value, replacing
+// the value of an identical key.
+func (t *Tree) Insert(key []byte, value interface{}) {
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	n, depth := t.root, 0
+	for {
+		p, rem := n.prefix, k[depth:]
+		i := commonPrefix(p, rem)
+		if i < len(p) {
+			// the key leaves this node's prefix part way along, so
+			// split the prefix where they differ.
+			child := new(node)
+			*child = *n
+			child.prefix = p[i+1:]
+			*n = node{prefix: p[:i]}
+			n.addChild(p[i], child)
+			if i == len(rem) {
+				n.key, n.value, n.hasValue = k, value, true
+			} else {
+				n.addChild(rem[i], newLeaf(k, depth+i+1, value))
+			}
+			t.number++
+			return
+		}
+
+		depth += len(p)
+		if depth == len(k) {
+			if !n.hasValue {
+				n.key, n.hasValue = k, true
+				t.number++
+			}
+			n.value = value
+			return
+		}
+
+		child := n.child(k[depth])
+		if child == nil {
+			n.addChild(k[depth], newLeaf(k, depth+1, value))
+			t.number++
+			return
+		}
+		n, depth = child, depth+1
+	}
+}
+
+// Get returns the value of the provided key.  The returned bool is
+// false if the key isn't in the tree.
+func (t *Tree) Get(key []byte) (interface{}, bool) {
+	n, depth := t.root, 0
+	for {
+		if !bytes.HasPrefix(key[depth:], n.prefix) {
+			return nil, false
+		}
+
+		depth += len(n.prefix)
+		if depth == len(key) {
+			return n.value, n.hasValue
+		}
+
+		n = n.child(key[depth])
+		if n == nil {
+			return nil, false
+		}
+		depth++
+	}
+}
+
+// Delete removes the provided key from the tree and returns its
+// value.  The returned bool is false if the key wasn't in the tree.
+func (t *Tree) Delete(key []byte) (interface{}, bool) {
+	var parent *node
+	var b byte
+	n, depth := t.root, 0
+	for {
+		if !bytes.HasPrefix(key[depth:], n.prefix) {
+			return nil, false
+		}
+
+		depth += len(n.prefix)
+		if depth == len(key) {
+			break
+		}
+
+		parent, b = n, key[depth]
+		n = n.child(b)
+		if n == nil {
+			return nil, false
+		}
+		depth++
+	}
+
+	if !n.hasValue {
+		return nil, false
+	}
+
+	value := n.value
+	n.key, n.value, n.hasValue = nil, nil, false
+	t.number--
+
+	switch {
+	case n == t.root:
+	case n.size == 0:
+		t.unlink(parent, b)
+	case n.size == 1:
+		n.absorb()
+	}
+
+	return value, true
+}
+
+// unlink removes the child of parent branching on b, merging parent
+// with its remaining child if it no longer branches or holds a value.
+func (t *Tree) unlink(parent *node, b byte) {
+	parent.removeChild(b)
+	if parent != t.root && !parent.hasValue && parent.size == 1 {
+		parent.absorb()
+	}
+}
+
+// seekPrefix returns the node holding every key with the provided
+// prefix along with its parent and the byte the parent branches on
+// to reach it.  A nil node is returned if no key has the prefix.
+func (t *Tree) seekPrefix(prefix []byte) (*node, byte, *node) {
+	var parent *node
+	var b byte
+	n, depth := t.root, 0
+	for {
+		p, rem := n.prefix, prefix[depth:]
+		if len(rem) <= len(p) {
+			if !bytes.HasPrefix(p, rem) {
+				return nil, 0, nil
+			}
+			return parent, b, n
+		}
+
+		if !bytes.HasPrefix(rem, p) {
+			return nil, 0, nil
+		}
+
+		depth += len(p)
+		parent, b = n, prefix[depth]
+		n = n.child(b)
+		if n == nil {
+			return nil, 0, nil
+		}
+		depth++
+	}
+}
+
+// WalkPrefix calls the provided function with every key beginning
+// with the provided prefix, and its value, in ascending key order
+// until the function returns false.  The keys passed to the function
+// belong to the tree and must not be modified.
+func (t *Tree) WalkPrefix(prefix []byte, fn func(key []byte, value interface{}) bool) {
+	if _, _, n := t.seekPrefix(prefix); n != nil {
+		n.walk(fn)
+	}
+}
+
+func (n *node) walk(fn func([]byte, interface{}) bool) bool {
+	if n.hasValue && !fn(n.key, n.value) {
+		return false
+	}
+
+	for b, c := n.nextChild(0); c != nil; b, c = n.nextChild(b + 1) {
+		if !c.walk(fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DeletePrefix removes every key beginning with the provided prefix
+// from the tree and returns the number of keys removed.
+func (t *Tree) DeletePrefix(prefix []byte) uint64 {
+	parent, b, n := t.seekPrefix(prefix)
+	if n == nil {
+		return 0
+	}
+
+	var removed uint64
+	n.walk(func([]byte, interface{}) bool {
+		removed++
+		return true
+	})
+
+	if n == t.root {
+		t.root = new(node)
+	} else {
+		t.unlink(parent, b)
+	}
+	t.number -= removed
+	return removed
+}
+
+// Iter returns an iterator that visits every key equal to or greater
+// than the one provided in ascending order.  A nil or empty key
+// visits the entire tree.  The tree must not be modified while the
+// iterator is in use.
+func (t *Tree) Iter(start []byte) *Iterator {
+	iter := &Iterator{}
+	iter.seek(t.root, start)
+	return iter
+}
+
+// Len returns the number of keys in the tree.
+func (t *Tree) Len() uint64 {
+	return t.number
+}
+
+// New returns an empty tree.
+func New() *Tree {
+	return &Tree{root: new(node)}
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package art
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkTree verifies that every node is in the smallest layout its
+// size allows, that paths are compressed, and that every key is held
+// by the node its path leads to.
+func checkTree(t *testing.T, tree *Tree) {
+	assert.Len(t, tree.root.prefix, 0)
+	var number uint64
+	var check func(n *node, path []byte)
+	check = func(n *node, path []byte) {
+		path = append(path, n.prefix...)
+		if n.hasValue {
+			number++
+			assert.Equal(t, string(path), string(n.key))
+		}
+
+		if n != tree.root {
+			assert.True(t, n.hasValue || n.size >= 2)
+		}
+
+		var size uint16
+		for b, c := n.nextChild(0); c != nil; b, c = n.nextChild(b + 1) {
+			size++
+			check(c, append(path[:len(path):len(path)], byte(b)))
+		}
+		assert.Equal(t, n.size, size)
+
+		switch n.kind {
+		case node4:
+			assert.True(t, size <= 4)
+		case node16:
+			assert.True(t, size > shrink16 && size <= 16)
+		case node48:
+			assert.True(t, size > shrink48 && size <= 48)
+		case node256:
+			assert.True(t, size > shrink256)
+		}
+	}
+	check(tree.root, nil)
+	assert.Equal(t, tree.Len(), number)
+}
+
+func collect(tree *Tree, prefix []byte) []string {
+	keys := []string{}
+	tree.WalkPrefix(prefix, func(key []byte, value interface{}) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+
+	return keys
+}
+
+func TestInsertGet(t *testing.T) {
+	tree := New()
+	tree.Insert([]byte(`romane`), 1)
+	tree.Insert([]byte(`romanus`), 2)
+	tree.Insert([]byte(`romulus`), 3)
+	tree.Insert([]byte(`rubens`), 4)
+	tree.Insert([]byte(`rom`), 5)
+	checkTree(t, tree)
+
+	assert.Equal(t, uint64(5), tree.Len())
+	for key, expected := range map[string]int{
+		`romane`: 1, `romanus`: 2, `romulus`: 3, `rubens`: 4, `rom`: 5,
+	} {
+		value, ok := tree.Get([]byte(key))
+		assert.True(t, ok)
+		assert.Equal(t, expected, value)
+	}
+
+	for _, key := range []string{``, `r`, `ro`, `roman`, `romanes`, `rubicon`, `x`} {
+		value, ok := tree.Get([]byte(key))
+		assert.False(t, ok)
+		assert.Nil(t, value)
+	}
+}
+
+func TestInsertReplace(t *testing.T) {
+	tree := New()
+	tree.Insert([]byte(`key`), 1)
+	tree.Insert([]byte(`key`), 2)
+
+	assert.Equal(t, uint64(1), tree.Len())
+	value, _ := tree.Get([]byte(`key`))
+	assert.Equal(t, 2, value)
+}
+
+func TestInsertCopiesKey(t *testing.T) {
+	tree := New()
+	key := []byte(`key`)
+	tree.Insert(key, 1)
+	key[0] = 'x'
+
+	_, ok := tree.Get([]byte(`key`))
+	assert.True(t, ok)
+	_, ok = tree.Get(key)
+	assert.False(t, ok)
+}
+
+func TestEmptyKey(t *testing.T) {
+	tree := New()
+	tree.Insert(nil, 1)
+	tree.Insert([]byte(`a`), 2)
+
+	value, ok := tree.Get([]byte{})
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, []string{``, `a`}, collect(tree, nil))
+
+	value, ok = tree.Delete(nil)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = tree.Get(nil)
+	assert.False(t, ok)
+	checkTree(t, tree)
+}
+
+func TestDelete(t *testing.T) {
+	tree := New()
+	for _, key := range []string{`a`, `ab`, `abc`, `abd`, `b`} {
+		tree.Insert([]byte(key), key)
+	}
+
+	value, ok := tree.Delete([]byte(`ab`))
+	assert.True(t, ok)
+	assert.Equal(t, `ab`, value)
+	checkTree(t, tree)
+
+	_, ok = tree.Delete([]byte(`ab`))
+	assert.False(t, ok)
+	_, ok = tree.Delete([]byte(`abe`))
+	assert.False(t, ok)
+	_, ok = tree.Delete([]byte(`x`))
+	assert.False(t, ok)
+
+	tree.Delete([]byte(`abc`))
+	checkTree(t, tree)
+	tree.Delete([]byte(`a`))
+	checkTree(t, tree)
+	assert.Equal(t, []string{`abd`, `b`}, collect(tree, nil))
+
+	tree.Delete([]byte(`abd`))
+	tree.Delete([]byte(`b`))
+	checkTree(t, tree)
+	assert.Equal(t, uint64(0), tree.Len())
+	assert.Equal(t, uint16(0), tree.root.size)
+}
+
+func TestWalkPrefix(t *testing.T) {
+	tree := New()
+	for _, key := range []string{`/a/b`, `/a`, `/a/c/d`, `/ab`, `/b`, `/a/c`} {
+		tree.Insert([]byte(key), nil)
+	}
+
+	assert.Equal(t, []string{`/a`, `/a/b`, `/a/c`, `/a/c/d`, `/ab`}, collect(tree, []byte(`/a`)))
+	assert.Equal(t, []string{`/a/b`, `/a/c`, `/a/c/d`}, collect(tree, []byte(`/a/`)))
+	assert.Equal(t, []string{`/a/c`, `/a/c/d`}, collect(tree, []byte(`/a/c`)))
+	assert.Equal(t, []string{`/a/c/d`}, collect(tree, []byte(`/a/c/`)))
+	assert.Equal(t, []string{}, collect(tree, []byte(`/a/d`)))
+	assert.Equal(t, []string{}, collect(tree, []byte(`/a/c/d/e`)))
+	assert.Len(t, collect(tree, nil), 6)
+
+	var keys []string
+	tree.WalkPrefix([]byte(`/`), func(key []byte, value interface{}) bool {
+		keys = append(keys, string(key))
+		return len(keys) < 2
+	})
+	assert.Equal(t, []string{`/a`, `/a/b`}, keys)
+}
+
+func TestDeletePrefix(t *testing.T) {
+	tree := New()
+	for _, key := range []string{`/a/b`, `/a`, `/a/c/d`, `/ab`, `/b`, `/a/c`} {
+		tree.Insert([]byte(key), nil)
+	}
+
+	assert.Equal(t, uint64(0), tree.DeletePrefix([]byte(`/c`)))
+	assert.Equal(t, uint64(2), tree.DeletePrefix([]byte(`/a/c`)))
+	checkTree(t, tree)
+	assert.Equal(t, []string{`/a`, `/a/b`, `/ab`, `/b`}, collect(tree, nil))
+
+	assert.Equal(t, uint64(3), tree.DeletePrefix([]byte(`/a`)))
+	checkTree(t, tree)
+	assert.Equal(t, []string{`/b`}, collect(tree, nil))
+
+	tree.Insert([]byte(`/c`), nil)
+	assert.Equal(t, uint64(2), tree.DeletePrefix(nil))
+	checkTree(t, tree)
+	assert.Equal(t, uint64(0), tree.Len())
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := New()
+	expected := map[string]int{}
+	randomKey := func() []byte {
+		// short keys drawn mostly from a small alphabet make shared
+		// prefixes and keys that are prefixes of others common,
+		// while the rest are spread wide enough to fill a node256.
+		key := make([]byte, r.Intn(6))
+		for i := range key {
+			if r.Intn(2) == 0 {
+				key[i] = byte(r.Intn(4))
+			} else {
+				key[i] = byte(r.Intn(256))
+			}
+		}
+		return key
+	}
+
+	for i := 0; i < 20000; i++ {
+		key := randomKey()
+		switch op := r.Intn(8); {
+		case op == 0:
+			value, ok := tree.Delete(key)
+			e, eok := expected[string(key)]
+			assert.Equal(t, eok, ok)
+			if eok {
+				assert.Equal(t, e, value)
+			}
+			delete(expected, string(key))
+		case op == 1 && len(key) > 1:
+			prefix := key[:len(key)/2]
+			var removed uint64
+			for k := range expected {
+				if bytes.HasPrefix([]byte(k), prefix) {
+					delete(expected, k)
+					removed++
+				}
+			}
+			assert.Equal(t, removed, tree.DeletePrefix(prefix))
+		default:
+			tree.Insert(key, i)
+			expected[string(key)] = i
+		}
+
+		if i%1000 == 0 {
+			checkTree(t, tree)
+		}
+	}
+	checkTree(t, tree)
+
+	keys := make([]string, 0, len(expected))
+	for k, v := range expected {
+		keys = append(keys, k)
+		value, ok := tree.Get([]byte(k))
+		assert.True(t, ok)
+		assert.Equal(t, v, value)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, keys, collect(tree, nil))
+	assert.Equal(t, uint64(len(expected)), tree.Len())
+}
+
+func BenchmarkInsert(b *testing.B) {
+	keys := generateKeys(b.N)
+	tree := New()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Insert(keys[i], nil)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	numItems := 100000
+	keys := generateKeys(numItems)
+	tree := New()
+	for _, key := range keys {
+		tree.Insert(key, nil)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Get(keys[i%numItems])
+	}
+}
+
+// benchmarked against the native map
+func BenchmarkMapGet(b *testing.B) {
+	numItems := 100000
+	keys := generateKeys(numItems)
+	m := make(map[string]interface{}, numItems)
+	for _, key := range keys {
+		m[string(key)] = nil
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = m[string(keys[i%numItems])]
+	}
+}
+
+func generateKeys(num int) [][]byte {
+	r := rand.New(rand.NewSource(1))
+	keys := make([][]byte, 0, num)
+	for i := 0; i < num; i++ {
+		key := make([]byte, 8+r.Intn(24))
+		r.Read(key)
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package art
+
+// frame is a node on the iterator's path along with the next branch
+// byte to descend into.  A next of -1 means the node's own value has
+// yet to be visited.
+type frame struct {
+	n    *node
+	next int
+}
+
+// Iterator walks keys in ascending order.
+type Iterator struct {
+	stack []frame
+	n     *node
+}
+
+// seek pushes the path to the least key equal to or greater than
+// start, marking everything lesser as already visited.
+func (iter *Iterator) seek(n *node, start []byte) {
+	depth := 0
+	for {
+		p, rem := n.prefix, start[depth:]
+		i := commonPrefix(p, rem)
+		switch {
+		case i == len(rem):
+			// start ends within or right at the end of this prefix,
+			// so it is less than or equal to every key below.
+			iter.stack = append(iter.stack, frame{n, -1})
+			return
+		case i < len(p):
+			// the prefix and start differ at i, every key below is
+			// either greater than start or lesser.
+			if p[i] > rem[i] {
+				iter.stack = append(iter.stack, frame{n, -1})
+			}
+			return
+		}
+
+		// the key held here, if any, is a proper prefix of start
+		// and so is lesser.
+		depth += len(p)
+		b := start[depth]
+		iter.stack = append(iter.stack, frame{n, int(b) + 1})
+		n = n.child(b)
+		if n == nil {
+			return
+		}
+		depth++
+	}
+}
+
+// Next moves the iterator to the next key and returns a bool
+// indicating if one exists.
+func (iter *Iterator) Next() bool {
+	for len(iter.stack) > 0 {
+		f := &iter.stack[len(iter.stack)-1]
+		if f.next == -1 {
+			f.next = 0
+			if f.n.hasValue {
+				iter.n = f.n
+				return true
+			}
+		}
+
+		b, c := f.n.nextChild(f.next)
+		if c == nil {
+			iter.stack = iter.stack[:len(iter.stack)-1]
+			continue
+		}
+
+		f.next = b + 1
+		iter.stack = append(iter.stack, frame{c, -1})
+	}
+
+	iter.n = nil
+	return false
+}
+
+// Key returns the key at the iterator's current position or nil if
+// the iterator is exhausted.  The key belongs to the tree and must
+// not be modified.
+func (iter *Iterator) Key() []byte {
+	if iter.n == nil {
+		return nil
+	}
+
+	return iter.n.key
+}
+
+// Value returns the value at the iterator's current position or nil
+// if the iterator is exhausted.
+func (iter *Iterator) Value() interface{} {
+	if iter.n == nil {
+		return nil
+	}
+
+	return iter.n.value
+}
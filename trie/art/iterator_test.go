@@ -0,0 +1,102 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package art
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exhaust(iter *Iterator) []string {
+	keys := []string{}
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+
+	return keys
+}
+
+func TestIter(t *testing.T) {
+	tree := New()
+	assert.Equal(t, []string{}, exhaust(tree.Iter(nil)))
+
+	keys := []string{``, `a`, `ab`, `abc`, `abd`, `abdd`, `b`, `ba`, `bcdef`}
+	for i := len(keys) - 1; i >= 0; i-- {
+		tree.Insert([]byte(keys[i]), keys[i])
+	}
+
+	assert.Equal(t, keys, exhaust(tree.Iter(nil)))
+	assert.Equal(t, keys[1:], exhaust(tree.Iter([]byte(`a`))))
+	assert.Equal(t, keys[3:], exhaust(tree.Iter([]byte(`abc`))))
+	assert.Equal(t, keys[4:], exhaust(tree.Iter([]byte(`abca`))))
+	assert.Equal(t, keys[6:], exhaust(tree.Iter([]byte(`abe`))))
+	assert.Equal(t, keys[8:], exhaust(tree.Iter([]byte(`bc`))))
+	assert.Equal(t, keys[8:], exhaust(tree.Iter([]byte(`bcd`))))
+	assert.Equal(t, keys[8:], exhaust(tree.Iter([]byte(`bcdef`))))
+	assert.Equal(t, []string{}, exhaust(tree.Iter([]byte(`bcdefa`))))
+	assert.Equal(t, []string{}, exhaust(tree.Iter([]byte(`bce`))))
+	assert.Equal(t, []string{}, exhaust(tree.Iter([]byte(`c`))))
+}
+
+func TestIterValue(t *testing.T) {
+	tree := New()
+	tree.Insert([]byte(`a`), 1)
+
+	iter := tree.Iter(nil)
+	assert.Nil(t, iter.Key())
+	assert.Nil(t, iter.Value())
+
+	assert.True(t, iter.Next())
+	assert.Equal(t, []byte(`a`), iter.Key())
+	assert.Equal(t, 1, iter.Value())
+
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Key())
+	assert.Nil(t, iter.Value())
+	assert.False(t, iter.Next())
+}
+
+func TestIterDense(t *testing.T) {
+	tree := New()
+	expected := []string{}
+	for i := 0; i < 256; i++ {
+		key := string([]byte{'k', byte(i)})
+		tree.Insert([]byte(key), nil)
+		expected = append(expected, key)
+	}
+
+	assert.Equal(t, expected, exhaust(tree.Iter(nil)))
+	assert.Equal(t, expected[100:], exhaust(tree.Iter([]byte{'k', 100})))
+	assert.Equal(t, expected[101:], exhaust(tree.Iter([]byte{'k', 100, 0})))
+}
+
+func BenchmarkIter(b *testing.B) {
+	numItems := 10000
+	tree := New()
+	for _, key := range generateKeys(numItems) {
+		tree.Insert(key, nil)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for iter := tree.Iter(nil); iter.Next(); {
+			iter.Value()
+		}
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package art
+
+// The kinds of inner node, named for the number of children each
+// can hold before it must grow into the next.
+const (
+	node4 uint8 = iota
+	node16
+	node48
+	node256
+)
+
+// The sizes at which a node shrinks back into the next smaller kind.
+// These sit below the smaller kind's capacity so a node hovering
+// around a boundary doesn't repeatedly grow and shrink.
+const (
+	shrink16  = 3
+	shrink48  = 12
+	shrink256 = 36
+)
+
+// node is both an inner node and a leaf.  prefix holds the bytes
+// compressed into the edge leading to this node, that is, the bytes
+// following the one its parent branches on.  A node holds a value
+// if a key ends here, which is always the case for a node without
+// children.
+type node struct {
+	prefix   []byte
+	key      []byte
+	value    interface{}
+	hasValue bool
+	kind     uint8
+	size     uint16
+	// keys holds the sorted branch bytes of a node4 or node16, each
+	// matching the child in the same position.
+	keys []byte
+	// index maps a branch byte to one more than the position of its
+	// child in a node48, zero meaning no child.
+	index *[256]uint8
+	// children is indexed by position in a node4, node16 or node48
+	// and by branch byte in a node256.
+	children []*node
+}
+
+func newLeaf(key []byte, depth int, value interface{}) *node {
+	return &node{
+		prefix:   key[depth:],
+		key:      key,
+		value:    value,
+		hasValue: true,
+	}
+}
+
+// child returns the child branching on b or nil if there is none.
+func (n *node) child(b byte) *node {
+	switch n.kind {
+	case node4, node16:
+		for i, k := range n.keys {
+			if k == b {
+				return n.children[i]
+			}
+		}
+	case node48:
+		if i := n.index[b]; i > 0 {
+			return n.children[i-1]
+		}
+	case node256:
+		return n.children[b]
+	}
+
+	return nil
+}
+
+// nextChild returns the child with the least branch byte equal to
+// or greater than from along with that byte.  A nil child is
+// returned if there is none.
+func (n *node) nextChild(from int) (int, *node) {
+	switch n.kind {
+	case node4, node16:
+		for i, k := range n.keys {
+			if int(k) >= from {
+				return int(k), n.children[i]
+			}
+		}
+	case node48:
+		for b := from; b < 256; b++ {
+			if i := n.index[b]; i > 0 {
+				return b, n.children[i-1]
+			}
+		}
+	case node256:
+		for b := from; b < 256; b++ {
+			if n.children[b] != nil {
+				return b, n.children[b]
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// addChild adds c as the child branching on b, growing this node
+// into the next kind if it is full.  No child may already branch
+// on b.
+func (n *node) addChild(b byte, c *node) {
+	switch n.kind {
+	case node4, node16:
+		if n.keys == nil {
+			n.keys, n.children = make([]byte, 0, 4), make([]*node, 0, 4)
+		}
+
+		if int(n.size) == cap(n.keys) {
+			n.grow()
+			n.addChild(b, c)
+			return
+		}
+
+		i := 0
+		for i < len(n.keys) && n.keys[i] < b {
+			i++
+		}
+		n.keys = append(n.keys, 0)
+		copy(n.keys[i+1:], n.keys[i:])
+		n.keys[i] = b
+		n.children = append(n.children, nil)
+		copy(n.children[i+1:], n.children[i:])
+		n.children[i] = c
+	case node48:
+		if n.size == 48 {
+			n.grow()
+			n.addChild(b, c)
+			return
+		}
+
+		i := 0
+		for n.children[i] != nil {
+			i++
+		}
+		n.children[i] = c
+		n.index[b] = uint8(i + 1)
+	case node256:
+		n.children[b] = c
+	}
+
+	n.size++
+}
+
+// removeChild removes the child branching on b, shrinking this node
+// into the next smaller kind if it has become sparse enough.
+func (n *node) removeChild(b byte) {
+	switch n.kind {
+	case node4, node16:
+		i := 0
+		for n.keys[i] != b {
+			i++
+		}
+		copy(n.keys[i:], n.keys[i+1:])
+		n.keys = n.keys[:len(n.keys)-1]
+		copy(n.children[i:], n.children[i+1:])
+		n.children[len(n.children)-1] = nil
+		n.children = n.children[:len(n.children)-1]
+	case node48:
+		n.children[n.index[b]-1] = nil
+		n.index[b] = 0
+	case node256:
+		n.children[b] = nil
+	}
+
+	n.size--
+	n.shrink()
+}
+
+// grow turns a full node into the next larger kind.
+func (n *node) grow() {
+	switch n.kind {
+	case node4:
+		keys := make([]byte, n.size, 16)
+		copy(keys, n.keys)
+		children := make([]*node, n.size, 16)
+		copy(children, n.children)
+		n.keys, n.children, n.kind = keys, children, node16
+	case node16:
+		index := new([256]uint8)
+		children := make([]*node, 48)
+		for i, k := range n.keys {
+			index[k] = uint8(i + 1)
+			children[i] = n.children[i]
+		}
+		n.keys, n.index, n.children, n.kind = nil, index, children, node48
+	case node48:
+		children := make([]*node, 256)
+		for b, i := range n.index {
+			if i > 0 {
+				children[b] = n.children[i-1]
+			}
+		}
+		n.index, n.children, n.kind = nil, children, node256
+	}
+}
+
+// shrink turns a sparse node into the next smaller kind.
+func (n *node) shrink() {
+	switch {
+	case n.kind == node16 && n.size <= shrink16:
+		keys := make([]byte, n.size, 4)
+		copy(keys, n.keys)
+		children := make([]*node, n.size, 4)
+		copy(children, n.children)
+		n.keys, n.children, n.kind = keys, children, node4
+	case n.kind == node48 && n.size <= shrink48:
+		keys := make([]byte, 0, 16)
+		children := make([]*node, 0, 16)
+		for b, i := range n.index {
+			if i > 0 {
+				keys = append(keys, byte(b))
+				children = append(children, n.children[i-1])
+			}
+		}
+		n.keys, n.index, n.children, n.kind = keys, nil, children, node16
+	case n.kind == node256 && n.size <= shrink256:
+		index := new([256]uint8)
+		children := make([]*node, 48)
+		i := 0
+		for b, c := range n.children {
+			if c != nil {
+				children[i] = c
+				i++
+				index[b] = uint8(i)
+			}
+		}
+		n.index, n.children, n.kind = index, children, node48
+	}
+}
+
+// absorb merges the only child of a node without a value into it,
+// restoring path compression after a removal.
+func (n *node) absorb() {
+	b, c := n.nextChild(0)
+	prefix := make([]byte, 0, len(n.prefix)+1+len(c.prefix))
+	prefix = append(prefix, n.prefix...)
+	prefix = append(prefix, byte(b))
+	prefix = append(prefix, c.prefix...)
+	*n = *c
+	n.prefix = prefix
+}
+
+// commonPrefix returns the length of the longest common prefix of
+// a and b.
+func commonPrefix(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
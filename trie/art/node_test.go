@@ -0,0 +1,107 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package art
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeGrowShrink(t *testing.T) {
+	n := new(node)
+	leaves := make([]*node, 256)
+	for b := range leaves {
+		leaves[b] = newLeaf([]byte{byte(b)}, 1, b)
+	}
+
+	// add in an order that isn't sorted to exercise insertion into
+	// the middle of a node4 and node16.
+	kinds := map[int]uint8{4: node4, 16: node16, 48: node48, 256: node256}
+	for i := 0; i < 256; i++ {
+		b := byte(i * 7)
+		n.addChild(b, leaves[b])
+		if kind, ok := kinds[i+1]; ok {
+			assert.Equal(t, kind, n.kind)
+		}
+	}
+	assert.Equal(t, uint16(256), n.size)
+
+	for b := 0; b < 256; b++ {
+		assert.Equal(t, leaves[b], n.child(byte(b)))
+	}
+
+	kinds = map[int]uint8{
+		shrink256 + 1: node256, shrink256: node48,
+		shrink48 + 1: node48, shrink48: node16,
+		shrink16 + 1: node16, shrink16: node4,
+	}
+	for i := 255; i >= 0; i-- {
+		b := byte(i * 7)
+		n.removeChild(b)
+		assert.Nil(t, n.child(b))
+		if kind, ok := kinds[i]; ok {
+			assert.Equal(t, kind, n.kind)
+		}
+
+		var last int = -1
+		var size uint16
+		for cb, c := n.nextChild(0); c != nil; cb, c = n.nextChild(cb + 1) {
+			assert.True(t, cb > last)
+			assert.Equal(t, leaves[cb], c)
+			last = cb
+			size++
+		}
+		assert.Equal(t, n.size, size)
+	}
+	assert.Equal(t, uint16(0), n.size)
+}
+
+func TestNodeNextChild(t *testing.T) {
+	for _, count := range []int{3, 10, 40, 100} {
+		n := new(node)
+		for i := 0; i < count; i++ {
+			n.addChild(byte(i*2), newLeaf([]byte{byte(i * 2)}, 1, nil))
+		}
+
+		b, c := n.nextChild(0)
+		assert.Equal(t, 0, b)
+		assert.NotNil(t, c)
+
+		b, c = n.nextChild(3)
+		assert.Equal(t, 4, b)
+		assert.NotNil(t, c)
+
+		_, c = n.nextChild(count * 2)
+		assert.Nil(t, c)
+
+		_, c = n.nextChild(256)
+		assert.Nil(t, c)
+	}
+}
+
+func TestNodeAbsorb(t *testing.T) {
+	leaf := newLeaf([]byte(`abcdef`), 4, 1)
+	n := &node{prefix: []byte(`bc`)}
+	n.addChild('d', leaf)
+
+	n.absorb()
+	assert.Equal(t, []byte(`bcdef`), n.prefix)
+	assert.True(t, n.hasValue)
+	assert.Equal(t, []byte(`abcdef`), n.key)
+	assert.Equal(t, uint16(0), n.size)
+}
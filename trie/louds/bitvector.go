@@ -0,0 +1,122 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// bitVector is an immutable list of bits supporting rank in constant
+// time and select in time logarithmic in its size.
+type bitVector struct {
+	words []uint64
+	// ranks holds the number of set bits before each word.
+	ranks []uint64
+	size  uint64
+}
+
+func (bv *bitVector) get(i uint64) bool {
+	return bv.words[i/64]&(1<<(i%64)) != 0
+}
+
+// rank1 returns the number of set bits in [0, i).
+func (bv *bitVector) rank1(i uint64) uint64 {
+	word, offset := i/64, i%64
+	if offset == 0 {
+		return bv.ranks[word]
+	}
+
+	return bv.ranks[word] + uint64(bits.OnesCount64(bv.words[word]<<(64-offset)))
+}
+
+// rank0 returns the number of unset bits in [0, i).
+func (bv *bitVector) rank0(i uint64) uint64 {
+	return i - bv.rank1(i)
+}
+
+// select1 returns the position of the n-th set bit, counting from
+// one.  There must be at least n set bits.
+func (bv *bitVector) select1(n uint64) uint64 {
+	word := sort.Search(len(bv.words), func(i int) bool {
+		return bv.ranks[i+1] >= n
+	})
+
+	return uint64(word)*64 + selectInWord(bv.words[word], n-bv.ranks[word])
+}
+
+// select0 returns the position of the n-th unset bit, counting from
+// one.  There must be at least n unset bits.
+func (bv *bitVector) select0(n uint64) uint64 {
+	word := sort.Search(len(bv.words), func(i int) bool {
+		return uint64(i+1)*64-bv.ranks[i+1] >= n
+	})
+
+	return uint64(word)*64 + selectInWord(^bv.words[word], n-(uint64(word)*64-bv.ranks[word]))
+}
+
+// selectInWord returns the position of the n-th set bit in the
+// provided word, counting from one.
+func selectInWord(word, n uint64) uint64 {
+	for ; n > 1; n-- {
+		word &= word - 1
+	}
+
+	return uint64(bits.TrailingZeros64(word))
+}
+
+// count returns the number of set bits.
+func (bv *bitVector) count() uint64 {
+	return bv.ranks[len(bv.words)]
+}
+
+// index computes the ranks of the words.  Bits past the size must
+// be unset.
+func (bv *bitVector) index() {
+	bv.ranks = make([]uint64, len(bv.words)+1)
+	for i, word := range bv.words {
+		bv.ranks[i+1] = bv.ranks[i] + uint64(bits.OnesCount64(word))
+	}
+}
+
+// bitVectorBuilder appends bits to build a bitVector.
+type bitVectorBuilder struct {
+	words []uint64
+	size  uint64
+}
+
+func (b *bitVectorBuilder) push(bit bool) {
+	if b.size%64 == 0 {
+		b.words = append(b.words, 0)
+	}
+
+	if bit {
+		b.words[b.size/64] |= 1 << (b.size % 64)
+	}
+	b.size++
+}
+
+func (b *bitVectorBuilder) build() *bitVector {
+	bv := &bitVector{words: b.words, size: b.size}
+	bv.index()
+	return bv
+}
+
+// numWords returns the number of words needed to hold size bits.
+func numWords(size uint64) uint64 {
+	return (size + 63) / 64
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitVector(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var builder bitVectorBuilder
+	var expected []bool
+	for i := 0; i < 1000; i++ {
+		bit := r.Intn(3) == 0
+		builder.push(bit)
+		expected = append(expected, bit)
+	}
+	bv := builder.build()
+	assert.Equal(t, uint64(1000), bv.size)
+
+	var ones, zeros uint64
+	for i, bit := range expected {
+		assert.Equal(t, ones, bv.rank1(uint64(i)))
+		assert.Equal(t, zeros, bv.rank0(uint64(i)))
+		assert.Equal(t, bit, bv.get(uint64(i)))
+		if bit {
+			ones++
+			assert.Equal(t, uint64(i), bv.select1(ones))
+		} else {
+			zeros++
+			assert.Equal(t, uint64(i), bv.select0(zeros))
+		}
+	}
+	assert.Equal(t, ones, bv.count())
+	assert.Equal(t, ones, bv.rank1(1000))
+}
+
+func TestSelectInWord(t *testing.T) {
+	assert.Equal(t, uint64(0), selectInWord(1, 1))
+	assert.Equal(t, uint64(63), selectInWord(1<<63, 1))
+	assert.Equal(t, uint64(5), selectInWord(0x2a, 3))
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Tries are serialized as a 16 byte header, holding a version byte,
+// seven reserved zero bytes and the number of nodes n as a
+// little-endian uint64, followed by the 2n-1 bits of the LOUDS and
+// the n terminal bits, each as little-endian uint64 words, and
+// finally the n labels.  Ranks are rebuilt on load rather than
+// stored.
+
+// encodingVersion leads the bytes returned by Bytes.
+const encodingVersion = 1
+
+// headerSize is the number of bytes before the encoded bits.
+const headerSize = 16
+
+// Bytes returns this trie serialized to a single byte slice that can
+// be loaded with FromBytes.
+func (t *Trie) Bytes() []byte {
+	nodes := uint64(len(t.labels))
+	data := make([]byte, headerSize, encodedSize(nodes))
+	data[0] = encodingVersion
+	binary.LittleEndian.PutUint64(data[8:], nodes)
+	for _, word := range t.louds.words {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+	for _, word := range t.terminal.words {
+		data = binary.LittleEndian.AppendUint64(data, word)
+	}
+
+	return append(data, t.labels...)
+}
+
+func encodedSize(nodes uint64) uint64 {
+	return headerSize + 8*numWords(2*nodes-1) + 8*numWords(nodes) + nodes
+}
+
+func readBits(data []byte, size uint64) ([]byte, *bitVector) {
+	bv := &bitVector{words: make([]uint64, numWords(size)), size: size}
+	for i := range bv.words {
+		bv.words[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	bv.index()
+	return data[8*len(bv.words):], bv
+}
+
+// hasPadding returns a bool indicating if any bit past the size of
+// the provided vector is set.
+func hasPadding(bv *bitVector) bool {
+	if bv.size%64 == 0 {
+		return false
+	}
+
+	return bv.words[len(bv.words)-1]>>(bv.size%64) != 0
+}
+
+// FromBytes loads a trie written by Bytes.  The bytes are copied.
+// An error is returned if they aren't a valid trie.
+func FromBytes(data []byte) (*Trie, error) {
+	if len(data) < headerSize {
+		return nil, fmt.Errorf(`Trie of %d bytes is too short.`, len(data))
+	}
+
+	if data[0] != encodingVersion {
+		return nil, fmt.Errorf(`Unknown encoding version %d.`, data[0])
+	}
+
+	nodes := binary.LittleEndian.Uint64(data[8:])
+	if nodes == 0 || nodes > uint64(len(data)) || encodedSize(nodes) != uint64(len(data)) {
+		return nil, fmt.Errorf(`Trie of %d nodes does not match its %d bytes.`, nodes, len(data))
+	}
+
+	t := &Trie{}
+	data, t.louds = readBits(data[headerSize:], 2*nodes-1)
+	data, t.terminal = readBits(data, nodes)
+	t.labels = append([]byte(nil), data...)
+
+	if hasPadding(t.louds) || hasPadding(t.terminal) {
+		return nil, fmt.Errorf(`Trie has bits set past its end.`)
+	}
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// validate checks that the LOUDS describes a tree in level order
+// whose siblings are sorted by label.
+func (t *Trie) validate() error {
+	nodes := uint64(len(t.labels))
+	if t.louds.count() != nodes-1 {
+		return fmt.Errorf(`Trie of %d nodes has %d children.`, nodes, t.louds.count())
+	}
+
+	// walk the degrees, tracking the node whose children are being
+	// read and the id of the next child.
+	node, child := uint64(0), uint64(1)
+	first := true
+	for i := uint64(0); i < t.louds.size; i++ {
+		if !t.louds.get(i) {
+			node++
+			first = true
+			continue
+		}
+
+		// a node's children are listed after its own degree
+		if child <= node {
+			return fmt.Errorf(`Node %d is its own ancestor.`, child)
+		}
+
+		if !first && t.labels[child] <= t.labels[child-1] {
+			return fmt.Errorf(`Children of node %d are not sorted.`, node)
+		}
+		first = false
+		child++
+	}
+
+	return nil
+}
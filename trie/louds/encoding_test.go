@@ -0,0 +1,79 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	keys := generateKeys(5000)
+	trie, err := New(keys)
+	assert.Nil(t, err)
+
+	data := trie.Bytes()
+	assert.Len(t, data, int(encodedSize(uint64(len(trie.labels)))))
+
+	result, err := FromBytes(data)
+	assert.Nil(t, err)
+	assert.Equal(t, trie, result)
+	checkTrie(t, result, keys)
+}
+
+func TestBytesEmpty(t *testing.T) {
+	trie, err := New(nil)
+	assert.Nil(t, err)
+
+	result, err := FromBytes(trie.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), result.Len())
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	trie, err := New(toBytes(`a`, `ab`, `b`))
+	assert.Nil(t, err)
+	data := trie.Bytes()
+
+	_, err = FromBytes(data[:headerSize-1])
+	assert.NotNil(t, err)
+
+	_, err = FromBytes(data[:len(data)-1])
+	assert.NotNil(t, err)
+
+	corrupt := func(fn func([]byte)) error {
+		d := append([]byte(nil), data...)
+		fn(d)
+		_, err := FromBytes(d)
+		return err
+	}
+
+	assert.NotNil(t, corrupt(func(d []byte) { d[0] = encodingVersion + 1 }))
+	assert.NotNil(t, corrupt(func(d []byte) { d[8] = 0 }))
+	assert.NotNil(t, corrupt(func(d []byte) { d[8] = 0xff }))
+	// a bit set past the end of the LOUDS
+	assert.NotNil(t, corrupt(func(d []byte) { d[headerSize+7] = 0x80 }))
+	// swap the labels of the root's children
+	assert.NotNil(t, corrupt(func(d []byte) { d[len(d)-3], d[len(d)-2] = d[len(d)-2], d[len(d)-3] }))
+	// the LOUDS of a, ab, b is 110 0 10 0 0 with four nodes; clearing
+	// the root's first child leaves too few children
+	assert.NotNil(t, corrupt(func(d []byte) { d[headerSize] &^= 1 }))
+	// 0 111 ... lists every child after the root's degree, making
+	// node 1 its own parent
+	assert.NotNil(t, corrupt(func(d []byte) { d[headerSize] = 0x0e }))
+}
@@ -0,0 +1,28 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import "fmt"
+
+// UnsortedError is returned when the keys a trie is built from are
+// not in strictly ascending order.  It holds the index of the first
+// key that is not greater than the one before it.
+type UnsortedError int
+
+func (ue UnsortedError) Error() string {
+	return fmt.Sprintf(`Key %d is not greater than the key before it.`, int(ue))
+}
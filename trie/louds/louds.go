@@ -0,0 +1,214 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package louds implements a static, memory compact trie in the style of
+marisa-trie for large read-only dictionaries of byte strings.  The
+trie is built once from a sorted list of keys and stores its shape as
+a level-order unary degree sequence (LOUDS): every node, visited
+breadth first, contributes a set bit for each child followed by an
+unset bit.  Along with one label byte and one terminal bit per node,
+this takes a little over 10 bits per node, regardless of how many
+keys share a prefix.
+
+Every key is given an id in [0, Len()), allowing the dictionary to map
+strings to dense integers and back.  Ids follow the breadth first
+order of the trie, so shorter keys generally have lower ids, but they
+are not in lexicographic order.
+
+The trie serializes to a single byte slice which can be stored and
+loaded without rebuilding.  A built trie is immutable and so is safe
+for concurrent use.
+
+Performance characteristics:
+Build: O(n*k) where n is the number of keys and k their length
+Lookup: O(k log n)
+ReverseLookup: O(k log n)
+CommonPrefixSearch: O(k log n)
+Space: about 10 bits per node
+*/
+package louds
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Trie is a static trie of byte string keys.
+type Trie struct {
+	// louds holds the unary degree of each node in level order.
+	louds *bitVector
+	// terminal has a set bit for each node at which a key ends.
+	terminal *bitVector
+	// labels holds the byte leading into each node, that of the
+	// root being unused.
+	labels []byte
+}
+
+// children returns the id of the first child of the provided node
+// and the number of children it has.  The children of a node have
+// consecutive ids.
+func (t *Trie) children(node uint64) (uint64, uint64) {
+	start := uint64(0)
+	if node > 0 {
+		start = t.louds.select0(node) + 1
+	}
+	end := t.louds.select0(node + 1)
+
+	// the root is no node's child, so the child at position p has
+	// the id of the number of set bits up to and including p.
+	return t.louds.rank1(start) + 1, end - start
+}
+
+// child returns the child of the provided node labeled b.  The
+// returned bool is false if there is none.
+func (t *Trie) child(node uint64, b byte) (uint64, bool) {
+	first, count := t.children(node)
+	labels := t.labels[first : first+count]
+	i := sort.Search(len(labels), func(i int) bool {
+		return labels[i] >= b
+	})
+
+	if i == len(labels) || labels[i] != b {
+		return 0, false
+	}
+
+	return first + uint64(i), true
+}
+
+// parent returns the parent of the provided node, which must not be
+// the root.
+func (t *Trie) parent(node uint64) uint64 {
+	return t.louds.rank0(t.louds.select1(node))
+}
+
+// Lookup returns the id of the provided key.  The returned bool is
+// false if the key isn't in the trie.
+func (t *Trie) Lookup(key []byte) (uint64, bool) {
+	node := uint64(0)
+	for _, b := range key {
+		var ok bool
+		node, ok = t.child(node, b)
+		if !ok {
+			return 0, false
+		}
+	}
+
+	if !t.terminal.get(node) {
+		return 0, false
+	}
+
+	return t.terminal.rank1(node), true
+}
+
+// ReverseLookup returns the key with the provided id.  The returned
+// bool is false if there is no such id.
+func (t *Trie) ReverseLookup(id uint64) ([]byte, bool) {
+	if id >= t.Len() {
+		return nil, false
+	}
+
+	var key []byte
+	for node := t.terminal.select1(id + 1); node > 0; node = t.parent(node) {
+		key = append(key, t.labels[node])
+	}
+
+	for i, j := 0, len(key)-1; i < j; i, j = i+1, j-1 {
+		key[i], key[j] = key[j], key[i]
+	}
+
+	return key, true
+}
+
+// CommonPrefixSearch calls the provided function with every key that
+// is a prefix of the query, and its id, from shortest to longest until
+// the function returns false.  The keys passed to the function are
+// slices of the query.
+func (t *Trie) CommonPrefixSearch(query []byte, fn func(key []byte, id uint64) bool) {
+	node := uint64(0)
+	for i := 0; ; i++ {
+		if t.terminal.get(node) && !fn(query[:i], t.terminal.rank1(node)) {
+			return
+		}
+
+		if i == len(query) {
+			return
+		}
+
+		var ok bool
+		node, ok = t.child(node, query[i])
+		if !ok {
+			return
+		}
+	}
+}
+
+// Len returns the number of keys in the trie.
+func (t *Trie) Len() uint64 {
+	return t.terminal.count()
+}
+
+// nodeRange is a node being built along with the keys below it, all
+// of which share their first depth bytes.
+type nodeRange struct {
+	lo, hi, depth int
+}
+
+// New builds a trie holding the provided keys, which must be in
+// strictly ascending order.  An UnsortedError is returned if they
+// aren't.  The keys are not retained.
+func New(keys [][]byte) (*Trie, error) {
+	for i := 1; i < len(keys); i++ {
+		if bytes.Compare(keys[i-1], keys[i]) >= 0 {
+			return nil, UnsortedError(i)
+		}
+	}
+
+	var louds, terminal bitVectorBuilder
+	labels := []byte{0}
+	queue := []nodeRange{{0, len(keys), 0}}
+	for head := 0; head < len(queue); head++ {
+		r := queue[head]
+
+		// sorting puts a key ending at this node before any it is a
+		// prefix of.
+		isTerminal := r.lo < r.hi && len(keys[r.lo]) == r.depth
+		terminal.push(isTerminal)
+		if isTerminal {
+			r.lo++
+		}
+
+		for lo := r.lo; lo < r.hi; {
+			b := keys[lo][r.depth]
+			hi := lo + 1
+			for hi < r.hi && keys[hi][r.depth] == b {
+				hi++
+			}
+
+			louds.push(true)
+			labels = append(labels, b)
+			queue = append(queue, nodeRange{lo, hi, r.depth + 1})
+			lo = hi
+		}
+		louds.push(false)
+	}
+
+	return &Trie{
+		louds:    louds.build(),
+		terminal: terminal.build(),
+		labels:   labels,
+	}, nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package louds
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func toBytes(keys ...string) [][]byte {
+	result := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, []byte(key))
+	}
+
+	return result
+}
+
+func generateKeys(num int) [][]byte {
+	r := rand.New(rand.NewSource(1))
+	set := make(map[string]struct{}, num)
+	for len(set) < num {
+		key := make([]byte, 1+r.Intn(12))
+		for i := range key {
+			key[i] = 'a' + byte(r.Intn(8))
+		}
+		set[string(key)] = struct{}{}
+	}
+
+	keys := make([]string, 0, num)
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return toBytes(keys...)
+}
+
+// checkTrie verifies every key round trips through its id and that
+// ids are dense.
+func checkTrie(t *testing.T, trie *Trie, keys [][]byte) {
+	assert.Equal(t, uint64(len(keys)), trie.Len())
+	seen := make(map[uint64]bool, len(keys))
+	for _, key := range keys {
+		id, ok := trie.Lookup(key)
+		assert.True(t, ok)
+		assert.True(t, id < trie.Len())
+		assert.False(t, seen[id])
+		seen[id] = true
+
+		result, ok := trie.ReverseLookup(id)
+		assert.True(t, ok)
+		assert.Equal(t, string(key), string(result))
+	}
+}
+
+func TestLookup(t *testing.T) {
+	keys := toBytes(`a`, `an`, `and`, `ant`, `bat`, `bath`, `bathe`, `cat`)
+	trie, err := New(keys)
+	assert.Nil(t, err)
+	checkTrie(t, trie, keys)
+
+	for _, key := range []string{``, `b`, `ba`, `ants`, `bathes`, `c`, `dog`} {
+		_, ok := trie.Lookup([]byte(key))
+		assert.False(t, ok, key)
+	}
+
+	// ids follow breadth first order
+	id, _ := trie.Lookup([]byte(`a`))
+	assert.Equal(t, uint64(0), id)
+	id, _ = trie.Lookup([]byte(`bathe`))
+	assert.Equal(t, uint64(7), id)
+
+	_, ok := trie.ReverseLookup(8)
+	assert.False(t, ok)
+}
+
+func TestEmptyKey(t *testing.T) {
+	keys := toBytes(``, `a`)
+	trie, err := New(keys)
+	assert.Nil(t, err)
+	checkTrie(t, trie, keys)
+
+	id, ok := trie.Lookup(nil)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), id)
+}
+
+func TestEmpty(t *testing.T) {
+	trie, err := New(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), trie.Len())
+
+	_, ok := trie.Lookup(nil)
+	assert.False(t, ok)
+	_, ok = trie.Lookup([]byte(`a`))
+	assert.False(t, ok)
+	_, ok = trie.ReverseLookup(0)
+	assert.False(t, ok)
+
+	trie.CommonPrefixSearch([]byte(`a`), func([]byte, uint64) bool {
+		t.Fail()
+		return true
+	})
+}
+
+func TestUnsorted(t *testing.T) {
+	_, err := New(toBytes(`a`, `c`, `b`))
+	assert.Equal(t, UnsortedError(2), err)
+
+	_, err = New(toBytes(`a`, `b`, `b`))
+	assert.Equal(t, UnsortedError(2), err)
+}
+
+func TestCommonPrefixSearch(t *testing.T) {
+	trie, err := New(toBytes(``, `a`, `an`, `and`, `android`, `ant`, `b`))
+	assert.Nil(t, err)
+
+	search := func(query string) []string {
+		result := []string{}
+		trie.CommonPrefixSearch([]byte(query), func(key []byte, id uint64) bool {
+			expected, _ := trie.Lookup(key)
+			assert.Equal(t, expected, id)
+			result = append(result, string(key))
+			return true
+		})
+		return result
+	}
+
+	assert.Equal(t, []string{``, `a`, `an`, `and`}, search(`andr`))
+	assert.Equal(t, []string{``, `a`, `an`, `and`, `android`}, search(`androids`))
+	assert.Equal(t, []string{``, `a`, `an`, `ant`}, search(`ant`))
+	assert.Equal(t, []string{``}, search(`c`))
+	assert.Equal(t, []string{``}, search(``))
+
+	var result []string
+	trie.CommonPrefixSearch([]byte(`android`), func(key []byte, id uint64) bool {
+		result = append(result, string(key))
+		return len(result) < 2
+	})
+	assert.Equal(t, []string{``, `a`}, result)
+}
+
+func TestLarge(t *testing.T) {
+	keys := generateKeys(20000)
+	trie, err := New(keys)
+	assert.Nil(t, err)
+	checkTrie(t, trie, keys)
+
+	for i := uint64(0); i < trie.Len(); i++ {
+		key, ok := trie.ReverseLookup(i)
+		assert.True(t, ok)
+		id, ok := trie.Lookup(key)
+		assert.True(t, ok)
+		assert.Equal(t, i, id)
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	keys := generateKeys(100000)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		New(keys)
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	numItems := 100000
+	keys := generateKeys(numItems)
+	trie, _ := New(keys)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		trie.Lookup(keys[i%numItems])
+	}
+}
+
+func BenchmarkReverseLookup(b *testing.B) {
+	numItems := 100000
+	trie, _ := New(generateKeys(numItems))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		trie.ReverseLookup(uint64(i % numItems))
+	}
+}
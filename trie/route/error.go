@@ -0,0 +1,38 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package route
+
+import "fmt"
+
+// InvalidRouteError is returned when a route can't be parsed.
+type InvalidRouteError struct {
+	route, reason string
+}
+
+func (ire InvalidRouteError) Error() string {
+	return fmt.Sprintf(`Invalid route %s: %s.`, ire.route, ire.reason)
+}
+
+// ConflictError is returned when a route names a parameter or wildcard
+// differently than an existing route at the same position.
+type ConflictError struct {
+	route, existing string
+}
+
+func (ce ConflictError) Error() string {
+	return fmt.Sprintf(`Route %s conflicts with existing name %s.`, ce.route, ce.existing)
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package route implements a trie over '/' separated paths for use in
+request routing.  Each segment of a route is one of:
+
+	static    matches the segment exactly, ie, users
+	:name     matches any single segment and captures it as name
+	*name     matches one or more remaining segments and captures
+	          them, joined by '/', as name.  Must be the last segment.
+
+When more than one route could match a path, static segments are
+preferred over parameters, which are preferred over wildcards,
+segment by segment from the left.  If a preferred branch fails to
+match further along the path, the next branch is tried.
+
+Leading and trailing slashes are ignored, so "/users/" and "users"
+are the same route.  This trie is not threadsafe.
+
+Performance characteristics:
+Insert: O(k) where k is the number of segments
+Match: O(k) without backtracking
+Space: O(n*k)
+*/
+package route
+
+import "strings"
+
+// Param is a single named value captured from a path.
+type Param struct {
+	Key, Value string
+}
+
+// Params is a typed list of Param in the order they appear in the route.
+type Params []Param
+
+// Get returns the value of the parameter with the provided name and
+// a bool indicating if it was found.
+func (ps Params) Get(name string) (string, bool) {
+	for _, p := range ps {
+		if p.Key == name {
+			return p.Value, true
+		}
+	}
+
+	return ``, false
+}
+
+type node struct {
+	static map[string]*node
+	// param and wildcard hold the children reached through a
+	// parameter or wildcard segment along with the captured name.
+	param, wildcard         *node
+	paramName, wildcardName string
+	value                   interface{}
+	hasValue                bool
+}
+
+func newNode() *node {
+	return &node{}
+}
+
+// Trie maps routes to values.
+type Trie struct {
+	root   *node
+	number uint64
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, `/`)
+	if path == `` {
+		return nil
+	}
+
+	return strings.Split(path, `/`)
+}
+
+// Insert adds the route to the trie with the provided value, replacing
+// the value of an identical route.  An error is returned if the route
+// contains an empty segment, a wildcard that isn't the final segment,
+// or a parameter or wildcard whose name differs from one already
+// registered in the same position.
+func (t *Trie) Insert(route string, value interface{}) error {
+	segments := split(route)
+	n := t.root
+	for i, seg := range segments {
+		if seg == `` {
+			return InvalidRouteError{route, `empty segment`}
+		}
+
+		switch seg[0] {
+		case ':':
+			name := seg[1:]
+			if name == `` {
+				return InvalidRouteError{route, `unnamed parameter`}
+			}
+
+			if n.param == nil {
+				n.param, n.paramName = newNode(), name
+			} else if n.paramName != name {
+				return ConflictError{route, n.paramName}
+			}
+			n = n.param
+		case '*':
+			name := seg[1:]
+			if name == `` {
+				return InvalidRouteError{route, `unnamed wildcard`}
+			}
+
+			if i != len(segments)-1 {
+				return InvalidRouteError{route, `wildcard must be the last segment`}
+			}
+
+			if n.wildcard == nil {
+				n.wildcard, n.wildcardName = newNode(), name
+			} else if n.wildcardName != name {
+				return ConflictError{route, n.wildcardName}
+			}
+			n = n.wildcard
+		default:
+			if n.static == nil {
+				n.static = make(map[string]*node)
+			}
+
+			child, ok := n.static[seg]
+			if !ok {
+				child = newNode()
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+
+	if !n.hasValue {
+		t.number++
+	}
+	n.value, n.hasValue = value, true
+	return nil
+}
+
+func (n *node) match(segments []string, params *Params) (interface{}, bool) {
+	if len(segments) == 0 {
+		return n.value, n.hasValue
+	}
+
+	seg := segments[0]
+	if child, ok := n.static[seg]; ok {
+		if value, ok := child.match(segments[1:], params); ok {
+			return value, true
+		}
+	}
+
+	if n.param != nil {
+		*params = append(*params, Param{n.paramName, seg})
+		if value, ok := n.param.match(segments[1:], params); ok {
+			return value, true
+		}
+		*params = (*params)[:len(*params)-1]
+	}
+
+	if n.wildcard != nil && n.wildcard.hasValue {
+		*params = append(*params, Param{n.wildcardName, strings.Join(segments, `/`)})
+		return n.wildcard.value, true
+	}
+
+	return nil, false
+}
+
+// Match returns the value of the best route matching the provided
+// path along with any captured parameters.  The returned bool is
+// false if no route matches.
+func (t *Trie) Match(path string) (interface{}, Params, bool) {
+	params := Params{}
+	value, ok := t.root.match(split(path), &params)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return value, params, true
+}
+
+// Len returns the number of routes in this trie.
+func (t *Trie) Len() uint64 {
+	return t.number
+}
+
+// New returns an empty route trie.
+func New() *Trie {
+	return &Trie{root: newNode()}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package route
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticMatch(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/`, 0))
+	assert.Nil(t, trie.Insert(`/users`, 1))
+	assert.Nil(t, trie.Insert(`/users/all`, 2))
+
+	value, params, ok := trie.Match(`/`)
+	assert.True(t, ok)
+	assert.Equal(t, 0, value)
+	assert.Len(t, params, 0)
+
+	value, _, ok = trie.Match(`users/`)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	value, _, ok = trie.Match(`/users/all`)
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+
+	_, _, ok = trie.Match(`/users/none`)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(3), trie.Len())
+}
+
+func TestParamMatch(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/users/:id/posts/:post`, 1))
+
+	value, params, ok := trie.Match(`/users/5/posts/10`)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, Params{{`id`, `5`}, {`post`, `10`}}, params)
+
+	id, ok := params.Get(`id`)
+	assert.True(t, ok)
+	assert.Equal(t, `5`, id)
+	_, ok = params.Get(`missing`)
+	assert.False(t, ok)
+
+	_, _, ok = trie.Match(`/users/5/posts`)
+	assert.False(t, ok)
+}
+
+func TestWildcardMatch(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/static/*file`, 1))
+
+	value, params, ok := trie.Match(`/static/css/site.css`)
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, Params{{`file`, `css/site.css`}}, params)
+
+	_, _, ok = trie.Match(`/static`)
+	assert.False(t, ok)
+}
+
+func TestPriority(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/users/new`, `static`))
+	assert.Nil(t, trie.Insert(`/users/:id`, `param`))
+	assert.Nil(t, trie.Insert(`/users/*rest`, `wildcard`))
+
+	value, params, _ := trie.Match(`/users/new`)
+	assert.Equal(t, `static`, value)
+	assert.Len(t, params, 0)
+
+	value, params, _ = trie.Match(`/users/5`)
+	assert.Equal(t, `param`, value)
+	assert.Equal(t, Params{{`id`, `5`}}, params)
+
+	value, params, _ = trie.Match(`/users/5/edit`)
+	assert.Equal(t, `wildcard`, value)
+	assert.Equal(t, Params{{`rest`, `5/edit`}}, params)
+}
+
+func TestBacktrack(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/users/new/form`, 1))
+	assert.Nil(t, trie.Insert(`/users/:id/edit`, 2))
+
+	value, params, ok := trie.Match(`/users/new/edit`)
+	assert.True(t, ok)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, Params{{`id`, `new`}}, params)
+}
+
+func TestInsertOverwrite(t *testing.T) {
+	trie := New()
+	assert.Nil(t, trie.Insert(`/a`, 1))
+	assert.Nil(t, trie.Insert(`/a/`, 2))
+
+	value, _, _ := trie.Match(`/a`)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, uint64(1), trie.Len())
+}
+
+func TestInsertErrors(t *testing.T) {
+	trie := New()
+	assert.IsType(t, InvalidRouteError{}, trie.Insert(`/a//b`, 1))
+	assert.IsType(t, InvalidRouteError{}, trie.Insert(`/a/:`, 1))
+	assert.IsType(t, InvalidRouteError{}, trie.Insert(`/a/*`, 1))
+	assert.IsType(t, InvalidRouteError{}, trie.Insert(`/a/*rest/b`, 1))
+
+	assert.Nil(t, trie.Insert(`/a/:id`, 1))
+	assert.IsType(t, ConflictError{}, trie.Insert(`/a/:name/b`, 1))
+	assert.Nil(t, trie.Insert(`/a/*rest`, 1))
+	assert.IsType(t, ConflictError{}, trie.Insert(`/a/*other`, 1))
+	assert.Equal(t, uint64(2), trie.Len())
+}
+
+func BenchmarkMatch(b *testing.B) {
+	trie := New()
+	trie.Insert(`/users/:id`, 1)
+	trie.Insert(`/users/:id/posts/:post`, 2)
+	trie.Insert(`/static/*file`, 3)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		trie.Match(`/users/5/posts/10`)
+	}
+}
@@ -23,6 +23,10 @@ type Entries []Entry
 type Iterator struct {
 	n     *node
 	first bool
+	// stop, if bounded, ends iteration at the first key equal to or
+	// greater than it.
+	stop    uint64
+	bounded bool
 }
 
 // Next will return a bool indicating if another value exists
@@ -30,10 +34,14 @@ type Iterator struct {
 func (iter *Iterator) Next() bool {
 	if iter.first {
 		iter.first = false
-		return iter.n != nil
+	} else if iter.n != nil {
+		iter.n = iter.n.children[1]
+	}
+
+	if iter.n != nil && iter.bounded && iter.n.entry.Key() >= iter.stop {
+		iter.n = nil
 	}
 
-	iter.n = iter.n.children[1]
 	return iter.n != nil
 }
 
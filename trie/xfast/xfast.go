@@ -605,6 +605,28 @@ func (xft *XFastTrie) Iter(key uint64) *Iterator {
 	}
 }
 
+// IterRange will return an iterator that will iterate over all values
+// with keys equal to or greater than start and less than stop, in
+// ascending order.  Finding the first value is an O(log log M)
+// operation and each step after is O(1).
+func (xft *XFastTrie) IterRange(start, stop uint64) *Iterator {
+	iter := xft.Iter(start)
+	iter.stop, iter.bounded = stop, true
+	return iter
+}
+
+// Each will call the provided function with every entry in the
+// trie in ascending key order until the function returns false.
+// Unlike Iter, this walks the leaves directly and performs no
+// allocations.
+func (xft *XFastTrie) Each(fn func(Entry) bool) {
+	for n := xft.min; n != nil; n = n.children[1] {
+		if !fn(n.entry) {
+			return
+		}
+	}
+}
+
 // Get will return a value in the trie associated with the provided
 // key if it exists.  Returns nil if the key does not exist.  This
 // is expected to take O(1) time.
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/Workiva/go-datastructures/slice"
+	"github.com/Workiva/go-datastructures/slice/skip"
 )
 
 func checkTrie(t *testing.T, xft *XFastTrie) {
@@ -243,6 +244,37 @@ func TestInsertBetween(t *testing.T) {
 	assert.Equal(t, Entries{e2}, entries)
 }
 
+func TestIterRange(t *testing.T) {
+	xft := New(uint8(0))
+
+	iter := xft.IterRange(0, 10)
+	assert.False(t, iter.Next())
+
+	e1 := newMockEntry(3)
+	e2 := newMockEntry(5)
+	e3 := newMockEntry(8)
+	xft.Insert(e1, e2, e3)
+
+	iter = xft.IterRange(4, 8)
+	assert.Equal(t, Entries{e2}, iter.exhaust())
+	assert.False(t, iter.Next())
+
+	iter = xft.IterRange(3, 9)
+	assert.Equal(t, Entries{e1, e2, e3}, iter.exhaust())
+
+	iter = xft.IterRange(0, math.MaxUint8)
+	assert.Equal(t, Entries{e1, e2, e3}, iter.exhaust())
+
+	iter = xft.IterRange(6, 8)
+	assert.Equal(t, Entries{}, iter.exhaust())
+
+	iter = xft.IterRange(5, 5)
+	assert.Equal(t, Entries{}, iter.exhaust())
+
+	iter = xft.IterRange(9, math.MaxUint8)
+	assert.Equal(t, Entries{}, iter.exhaust())
+}
+
 func TestSuccessorDoesNotExist(t *testing.T) {
 	xft := New(uint8(0))
 	e1 := newMockEntry(5)
@@ -522,6 +554,36 @@ func TestDeleteNotFound(t *testing.T) {
 	checkTrie(t, xft)
 }
 
+func TestEach(t *testing.T) {
+	xft := New(uint8(0))
+	xft.Each(func(Entry) bool {
+		t.Fail()
+		return true
+	})
+
+	e1 := newMockEntry(8)
+	e2 := newMockEntry(3)
+	e3 := newMockEntry(50)
+	xft.Insert(e1, e2, e3)
+
+	result := make(Entries, 0, 3)
+	xft.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, Entries{e2, e1, e3}, result)
+
+	result = result[:0]
+	xft.Each(func(e Entry) bool {
+		result = append(result, e)
+		return false
+	})
+	assert.Equal(t, Entries{e2}, result)
+
+	fn := func(Entry) bool { return true }
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { xft.Each(fn) }))
+}
+
 func BenchmarkSuccessor(b *testing.B) {
 	numItems := 10000
 	xft := New(uint64(0))
@@ -593,3 +655,122 @@ func BenchmarkListSearch(b *testing.B) {
 		s.Search(int64(i))
 	}
 }
+
+// denseEntry avoids the overhead of the mock so the trie and skip
+// list can be compared on the same dense set of integer keys.
+type denseEntry uint64
+
+func (de denseEntry) Key() uint64 {
+	return uint64(de)
+}
+
+func (de denseEntry) Compare(other skip.Entry) int {
+	o := other.(denseEntry)
+	if de == o {
+		return 0
+	}
+
+	if de > o {
+		return 1
+	}
+
+	return -1
+}
+
+func BenchmarkDenseSuccessor(b *testing.B) {
+	numItems := 100000
+	xft := New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		xft.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		xft.Successor(uint64(i % numItems))
+	}
+}
+
+func BenchmarkDensePredecessor(b *testing.B) {
+	numItems := 100000
+	xft := New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		xft.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		xft.Predecessor(uint64(i % numItems))
+	}
+}
+
+func BenchmarkIterRange(b *testing.B) {
+	numItems := 100000
+	xft := New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		xft.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := uint64(i % (numItems - 100))
+		for iter := xft.IterRange(start, start+100); iter.Next(); {
+			iter.Value()
+		}
+	}
+}
+
+// benchmarked against a skip list
+func BenchmarkSkipListSuccessor(b *testing.B) {
+	numItems := 100000
+	sl := skip.New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Ceiling(denseEntry(i % numItems))
+	}
+}
+
+func BenchmarkSkipListPredecessor(b *testing.B) {
+	numItems := 100000
+	sl := skip.New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Floor(denseEntry(i % numItems))
+	}
+}
+
+func BenchmarkSkipListIterRange(b *testing.B) {
+	numItems := 100000
+	sl := skip.New(uint64(0))
+
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(denseEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := uint64(i % (numItems - 100))
+		iter := sl.IterRange(denseEntry(start), denseEntry(start+100))
+		for iter.Next() {
+			iter.Value()
+		}
+	}
+}
@@ -33,6 +33,10 @@ type Iterator struct {
 	xfastIterator *xfast.Iterator
 	index         int
 	entries       *entriesWrapper
+	// stop, if bounded, ends iteration at the first key equal to or
+	// greater than it.
+	stop    uint64
+	bounded bool
 }
 
 // Next will return a bool indicating if another value exists
@@ -57,6 +61,11 @@ func (iter *Iterator) Next() bool {
 		iter.index = 0
 	}
 
+	if iter.bounded && iter.entries.entries[iter.index].Key() >= iter.stop {
+		iter.index = iteratorExhausted
+		return false
+	}
+
 	return true
 }
 
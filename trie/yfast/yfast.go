@@ -271,6 +271,30 @@ func (yfast *YFastTrie) Iter(key uint64) *Iterator {
 	return yfast.iter(key)
 }
 
+// IterRange will return an iterator that will iterate across all
+// values with keys equal to or greater than start and less than stop,
+// in ascending order.
+func (yfast *YFastTrie) IterRange(start, stop uint64) *Iterator {
+	iter := yfast.iter(start)
+	iter.stop, iter.bounded = stop, true
+	return iter
+}
+
+// Each will call the provided function with every entry in the
+// trie in ascending key order until the function returns false.
+// No allocations are performed per visited entry.
+func (yfast *YFastTrie) Each(fn func(Entry) bool) {
+	yfast.xfast.Each(func(bundle xfast.Entry) bool {
+		for _, entry := range bundle.(*entriesWrapper).entries {
+			if !fn(entry) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
 // New constructs, initializes, and returns a new y-fast trie.
 // Provided should be a uint type that specifies the number
 // of bits in the desired universe.  This will affect the time
@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
 )
 
 func generateEntries(num int) Entries {
@@ -191,6 +193,80 @@ func TestTrieIterator(t *testing.T) {
 	assert.Equal(t, Entries{}, iter.exhaust())
 }
 
+func TestTrieIterRange(t *testing.T) {
+	yfast := New(uint8(0))
+
+	iter := yfast.IterRange(0, 10)
+	assert.Equal(t, Entries{}, iter.exhaust())
+
+	e1 := newMockEntry(3)
+	e2 := newMockEntry(5)
+	e3 := newMockEntry(8)
+	yfast.Insert(e1, e2, e3)
+
+	iter = yfast.IterRange(4, 8)
+	assert.Equal(t, Entries{e2}, iter.exhaust())
+	assert.False(t, iter.Next())
+	assert.Nil(t, iter.Value())
+
+	iter = yfast.IterRange(3, 9)
+	assert.Equal(t, Entries{e1, e2, e3}, iter.exhaust())
+
+	iter = yfast.IterRange(0, 100)
+	assert.Equal(t, Entries{e1, e2, e3}, iter.exhaust())
+
+	iter = yfast.IterRange(6, 8)
+	assert.Equal(t, Entries{}, iter.exhaust())
+
+	iter = yfast.IterRange(5, 5)
+	assert.Equal(t, Entries{}, iter.exhaust())
+
+	iter = yfast.IterRange(9, 100)
+	assert.Equal(t, Entries{}, iter.exhaust())
+}
+
+func TestTrieIterRangeAcrossBuckets(t *testing.T) {
+	yfast := New(uint16(0))
+	entries := generateEntries(1000)
+	yfast.Insert(entries...)
+
+	iter := yfast.IterRange(100, 900)
+	assert.Equal(t, entries[100:900], iter.exhaust())
+}
+
+func TestTrieEach(t *testing.T) {
+	yfast := New(uint8(0))
+	entries := generateEntries(100)
+	yfast.Insert(entries...)
+
+	result := make(Entries, 0, len(entries))
+	yfast.Each(func(e Entry) bool {
+		result = append(result, e)
+		return true
+	})
+	assert.Equal(t, entries, result)
+
+	result = result[:0]
+	yfast.Each(func(e Entry) bool {
+		result = append(result, e)
+		return len(result) < 10
+	})
+	assert.Equal(t, entries[:10], result)
+}
+
+func BenchmarkEach(b *testing.B) {
+	yfast := New(uint64(0))
+	yfast.Insert(generateEntries(1000)...)
+	fn := func(Entry) bool { return true }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		yfast.Each(fn)
+	}
+}
+
 func BenchmarkInsert(b *testing.B) {
 	yfast := New(uint64(0))
 	entries := generateEntries(b.N)
@@ -280,3 +356,85 @@ func BenchmarkIterator(b *testing.B) {
 		}
 	}
 }
+
+// skipEntry lets the skip list be benchmarked against the trie
+// on the same dense set of integer keys.
+type skipEntry uint64
+
+func (se skipEntry) Compare(other skip.Entry) int {
+	o := other.(skipEntry)
+	if se == o {
+		return 0
+	}
+
+	if se > o {
+		return 1
+	}
+
+	return -1
+}
+
+func BenchmarkIterRange(b *testing.B) {
+	numItems := 100000
+
+	yfast := New(uint64(0))
+	yfast.Insert(generateEntries(numItems)...)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := uint64(i % (numItems - 100))
+		for iter := yfast.IterRange(start, start+100); iter.Next(); {
+			iter.Value()
+		}
+	}
+}
+
+func BenchmarkSkipListSuccessor(b *testing.B) {
+	numItems := 100000
+
+	sl := skip.New(uint64(0))
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(skipEntry(i + uint64(b.N/2)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Ceiling(skipEntry(i))
+	}
+}
+
+func BenchmarkSkipListPredecessor(b *testing.B) {
+	numItems := 100000
+
+	sl := skip.New(uint64(0))
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(skipEntry(i + uint64(b.N/2)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sl.Floor(skipEntry(i))
+	}
+}
+
+func BenchmarkSkipListIterRange(b *testing.B) {
+	numItems := 100000
+
+	sl := skip.New(uint64(0))
+	for i := uint64(0); i < uint64(numItems); i++ {
+		sl.Insert(skipEntry(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		start := uint64(i % (numItems - 100))
+		iter := sl.IterRange(skipEntry(start), skipEntry(start+100))
+		for iter.Next() {
+			iter.Value()
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package zset implements a sorted set in the style of a Redis ZSET.
+Every member has a float64 score, and members are ordered by score
+with ties broken by ordering the members themselves.  A map from
+member to score sits on top of a skip list ordered by score, so
+members can be looked up directly while the skip list's gap widths
+make queries by rank as cheap as queries by score.
+
+Scores may not be NaN.  This set is not threadsafe.
+
+Performance characteristics:
+Add: O(log n)
+Remove: O(log n)
+Score: O(1)
+Rank: O(log n)
+IncrBy: O(log n)
+RangeByScore: O(log n + m) where m is the number of members returned
+RangeByRank: O(log n + m)
+Space: O(n)
+*/
+package zset
+
+import (
+	"cmp"
+	"math"
+
+	"github.com/Workiva/go-datastructures/slice/skip"
+)
+
+// Item is a member of the set along with its score.
+type Item[M cmp.Ordered] struct {
+	Member M
+	Score  float64
+}
+
+// entry is an item as held by the skip list.  bound places an entry
+// used for searching before, when negative, or after, when positive,
+// every member with its score.
+type entry[M cmp.Ordered] struct {
+	member M
+	score  float64
+	bound  int8
+}
+
+func (e entry[M]) Compare(other skip.Entry) int {
+	o := other.(entry[M])
+	switch {
+	case e.score < o.score:
+		return -1
+	case e.score > o.score:
+		return 1
+	case e.bound != o.bound:
+		return int(e.bound) - int(o.bound)
+	}
+
+	return cmp.Compare(e.member, o.member)
+}
+
+// ZSet is a set of members ordered by score.
+type ZSet[M cmp.Ordered] struct {
+	list   *skip.SkipList
+	scores map[M]float64
+}
+
+func checkScore(score float64) {
+	if math.IsNaN(score) {
+		panic(`Invalid score provided.`)
+	}
+}
+
+// Add sets the score of the provided member, adding it to the set if
+// it isn't already a member.  Returns a bool indicating if the member
+// was added.  Panics if the score is NaN.
+func (zs *ZSet[M]) Add(member M, score float64) bool {
+	checkScore(score)
+	old, ok := zs.scores[member]
+	if ok {
+		if old == score {
+			return false
+		}
+		zs.list.Delete(entry[M]{member: member, score: old})
+	}
+
+	zs.scores[member] = score
+	zs.list.Insert(entry[M]{member: member, score: score})
+	return !ok
+}
+
+// Remove removes the provided member from the set.  Returns a bool
+// indicating if it was a member.
+func (zs *ZSet[M]) Remove(member M) bool {
+	score, ok := zs.scores[member]
+	if !ok {
+		return false
+	}
+
+	delete(zs.scores, member)
+	zs.list.Delete(entry[M]{member: member, score: score})
+	return true
+}
+
+// Score returns the score of the provided member.  The returned bool
+// is false if it isn't a member.
+func (zs *ZSet[M]) Score(member M) (float64, bool) {
+	score, ok := zs.scores[member]
+	return score, ok
+}
+
+// Rank returns the number of members ordered before the provided
+// member.  The returned bool is false if it isn't a member.
+func (zs *ZSet[M]) Rank(member M) (uint64, bool) {
+	score, ok := zs.scores[member]
+	if !ok {
+		return 0, false
+	}
+
+	return zs.list.Rank(entry[M]{member: member, score: score}), true
+}
+
+// IncrBy adds delta to the score of the provided member and returns
+// the new score.  A member that isn't in the set is added with a
+// score of delta.  Panics if the new score would be NaN, which
+// leaves the set unchanged.
+func (zs *ZSet[M]) IncrBy(member M, delta float64) float64 {
+	score := zs.scores[member] + delta
+	checkScore(score)
+	zs.Add(member, score)
+	return score
+}
+
+// RangeByScore returns the members with a score between lo and hi,
+// inclusive, in order.
+func (zs *ZSet[M]) RangeByScore(lo, hi float64) []Item[M] {
+	if lo > hi {
+		return nil
+	}
+
+	return collect[M](zs.list.IterRange(
+		entry[M]{score: lo, bound: -1},
+		entry[M]{score: hi, bound: 1},
+	), math.MaxInt)
+}
+
+// RangeByRank returns the members ranked from start up to but not
+// including stop in order.
+func (zs *ZSet[M]) RangeByRank(start, stop uint64) []Item[M] {
+	if stop > zs.list.Len() {
+		stop = zs.list.Len()
+	}
+
+	if start >= stop {
+		return nil
+	}
+
+	return collect[M](zs.list.Iter(zs.list.ByPosition(start)), int(stop-start))
+}
+
+func collect[M cmp.Ordered](iter skip.Iterator, limit int) []Item[M] {
+	var items []Item[M]
+	for len(items) < limit && iter.Next() {
+		e := iter.Value().(entry[M])
+		items = append(items, Item[M]{Member: e.member, Score: e.score})
+	}
+
+	return items
+}
+
+// Len returns the number of members in the set.
+func (zs *ZSet[M]) Len() uint64 {
+	return uint64(len(zs.scores))
+}
+
+// New returns an empty sorted set.
+func New[M cmp.Ordered]() *ZSet[M] {
+	return &ZSet[M]{
+		list:   skip.New(uint64(0)),
+		scores: make(map[M]float64),
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zset
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func members(items []Item[string]) []string {
+	result := []string{}
+	for _, item := range items {
+		result = append(result, item.Member)
+	}
+
+	return result
+}
+
+func TestAdd(t *testing.T) {
+	zs := New[string]()
+	assert.True(t, zs.Add(`a`, 1))
+	assert.True(t, zs.Add(`b`, 2))
+	assert.False(t, zs.Add(`a`, 3))
+	assert.False(t, zs.Add(`a`, 3))
+
+	assert.Equal(t, uint64(2), zs.Len())
+	score, ok := zs.Score(`a`)
+	assert.True(t, ok)
+	assert.Equal(t, float64(3), score)
+
+	_, ok = zs.Score(`c`)
+	assert.False(t, ok)
+	assert.Equal(t, []string{`b`, `a`}, members(zs.RangeByRank(0, 10)))
+}
+
+func TestAddNaN(t *testing.T) {
+	zs := New[string]()
+	assert.Panics(t, func() {
+		zs.Add(`a`, math.NaN())
+	})
+	assert.Equal(t, uint64(0), zs.Len())
+}
+
+func TestRemove(t *testing.T) {
+	zs := New[string]()
+	zs.Add(`a`, 1)
+	zs.Add(`b`, 1)
+
+	assert.True(t, zs.Remove(`a`))
+	assert.False(t, zs.Remove(`a`))
+	assert.Equal(t, uint64(1), zs.Len())
+	assert.Equal(t, []string{`b`}, members(zs.RangeByRank(0, 10)))
+}
+
+func TestRank(t *testing.T) {
+	zs := New[string]()
+	zs.Add(`c`, 2)
+	zs.Add(`b`, 2)
+	zs.Add(`a`, 5)
+	zs.Add(`d`, -1)
+
+	for expected, member := range []string{`d`, `b`, `c`, `a`} {
+		rank, ok := zs.Rank(member)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(expected), rank)
+	}
+
+	_, ok := zs.Rank(`e`)
+	assert.False(t, ok)
+}
+
+func TestIncrBy(t *testing.T) {
+	zs := New[string]()
+	assert.Equal(t, float64(2), zs.IncrBy(`a`, 2))
+	assert.Equal(t, float64(-1), zs.IncrBy(`a`, -3))
+	zs.Add(`b`, 0)
+
+	assert.Equal(t, []string{`a`, `b`}, members(zs.RangeByRank(0, 2)))
+	zs.IncrBy(`a`, 5)
+	assert.Equal(t, []string{`b`, `a`}, members(zs.RangeByRank(0, 2)))
+
+	zs.Add(`c`, math.Inf(1))
+	assert.Panics(t, func() {
+		zs.IncrBy(`c`, math.Inf(-1))
+	})
+	score, _ := zs.Score(`c`)
+	assert.Equal(t, math.Inf(1), score)
+}
+
+func TestRangeByScore(t *testing.T) {
+	zs := New[string]()
+	zs.Add(`a`, 1)
+	zs.Add(`b`, 2)
+	zs.Add(`c`, 2)
+	zs.Add(`d`, 3)
+	zs.Add(`e`, math.Inf(1))
+
+	assert.Equal(t, []string{`b`, `c`}, members(zs.RangeByScore(2, 2)))
+	assert.Equal(t, []string{`a`, `b`, `c`}, members(zs.RangeByScore(0, 2.5)))
+	assert.Equal(t, []string{`b`, `c`, `d`}, members(zs.RangeByScore(1.5, 3)))
+	assert.Equal(t, []string{`d`, `e`}, members(zs.RangeByScore(3, math.Inf(1))))
+	assert.Equal(t, []string{}, members(zs.RangeByScore(4, 5)))
+	assert.Equal(t, []string{}, members(zs.RangeByScore(3, 2)))
+	assert.Equal(t, []Item[string]{{`a`, 1}}, zs.RangeByScore(math.Inf(-1), 1))
+}
+
+func TestRangeByRank(t *testing.T) {
+	zs := New[string]()
+	zs.Add(`a`, 1)
+	zs.Add(`b`, 2)
+	zs.Add(`c`, 3)
+
+	assert.Equal(t, []string{`a`, `b`, `c`}, members(zs.RangeByRank(0, 3)))
+	assert.Equal(t, []string{`b`}, members(zs.RangeByRank(1, 2)))
+	assert.Equal(t, []string{`b`, `c`}, members(zs.RangeByRank(1, 100)))
+	assert.Equal(t, []string{}, members(zs.RangeByRank(2, 2)))
+	assert.Equal(t, []string{}, members(zs.RangeByRank(3, 5)))
+	assert.Equal(t, []Item[string]{{`c`, 3}}, zs.RangeByRank(2, 3))
+}
+
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	zs := New[int]()
+	expected := map[int]float64{}
+	for i := 0; i < 5000; i++ {
+		member := r.Intn(500)
+		switch r.Intn(4) {
+		case 0:
+			delete(expected, member)
+			zs.Remove(member)
+		case 1:
+			expected[member] += 1
+			zs.IncrBy(member, 1)
+		default:
+			score := float64(r.Intn(50))
+			expected[member] = score
+			zs.Add(member, score)
+		}
+	}
+
+	items := make([]Item[int], 0, len(expected))
+	for member, score := range expected {
+		items = append(items, Item[int]{member, score})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score < items[j].Score
+		}
+		return items[i].Member < items[j].Member
+	})
+
+	assert.Equal(t, uint64(len(items)), zs.Len())
+	assert.Equal(t, items, zs.RangeByRank(0, zs.Len()))
+	for i, item := range items {
+		rank, ok := zs.Rank(item.Member)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(i), rank)
+	}
+
+	var inRange []Item[int]
+	for _, item := range items {
+		if item.Score >= 10 && item.Score <= 20 {
+			inRange = append(inRange, item)
+		}
+	}
+	assert.Equal(t, inRange, zs.RangeByScore(10, 20))
+}
+
+func BenchmarkAdd(b *testing.B) {
+	zs := New[int]()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		zs.Add(i%100000, float64(i))
+	}
+}
+
+func BenchmarkRank(b *testing.B) {
+	numItems := 100000
+	zs := New[int]()
+	for i := 0; i < numItems; i++ {
+		zs.Add(i, float64(i%1000))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		zs.Rank(i % numItems)
+	}
+}